@@ -85,6 +85,24 @@ func main() {
 	}
 	rootCmd.AddCommand(lspCmd)
 
+	schemaCmd := cmd.NewSchemaCommand(rootCmd.Use)
+	cmd.RegisterSchemaRootFlags(schemaCmd)
+	rootCmd.AddCommand(schemaCmd)
+
+	validateCmd := cmd.NewValidateCommand(rootCmd.Use)
+	cmd.RegisterValidateFlags(validateCmd)
+	rootCmd.AddCommand(validateCmd)
+
+	var benchConfig cmd.BenchConfig
+	benchCmd := cmd.NewBenchCommand(rootCmd.Use, &benchConfig)
+	cmd.RegisterBenchFlags(benchCmd, &benchConfig)
+	rootCmd.AddCommand(benchCmd)
+
+	var clientConfig cmd.ClientConnectionConfig
+	clientCmd := cmd.NewClientCommand(rootCmd.Use, &clientConfig)
+	cmd.RegisterClientFlags(clientCmd, &clientConfig)
+	rootCmd.AddCommand(clientCmd)
+
 	var testServerConfig testserver.Config
 	testingCmd := cmd.NewTestingCommand(rootCmd.Use, &testServerConfig)
 	cmd.RegisterTestingFlags(testingCmd, &testServerConfig)
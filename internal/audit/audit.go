@@ -0,0 +1,118 @@
+// Package audit implements a structured audit log of the changes made through the API and,
+// optionally, of permission check decisions. Audit records are written to dedicated sinks,
+// separately from the debug logs.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// Kind is the kind of an audited operation.
+type Kind string
+
+const (
+	// KindWriteRelationships records a WriteRelationships call.
+	KindWriteRelationships Kind = "write_relationships"
+
+	// KindDeleteRelationships records a DeleteRelationships call.
+	KindDeleteRelationships Kind = "delete_relationships"
+
+	// KindImportRelationships records a BulkImportRelationships call.
+	KindImportRelationships Kind = "import_relationships"
+
+	// KindWriteSchema records a WriteSchema call, which changes namespaces and caveats.
+	KindWriteSchema Kind = "write_schema"
+
+	// KindCheckPermission records a CheckPermission decision, or that of an item of a bulk check.
+	KindCheckPermission Kind = "check_permission"
+)
+
+// Record is a single entry of the audit log.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Kind      Kind      `json:"kind"`
+	Method    string    `json:"method"`
+	Code      string    `json:"code"`
+	Error     string    `json:"error,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+	Peer      string    `json:"peer,omitempty"`
+
+	// Caller is the identity of the caller, when authenticated with a JWT.
+	Caller       string `json:"caller,omitempty"`
+	CallerIssuer string `json:"callerIssuer,omitempty"`
+
+	// Revision is the ZedToken at which the operation was applied or evaluated.
+	Revision string `json:"revision,omitempty"`
+
+	// Updates are the relationship updates of a write, formatted as `OPERATION relationship`.
+	Updates []string `json:"updates,omitempty"`
+
+	// Filter is the relationship filter of a delete.
+	Filter json.RawMessage `json:"filter,omitempty"`
+
+	// RelationshipCount is the number of relationships imported or deleted.
+	RelationshipCount uint64 `json:"relationshipCount,omitempty"`
+
+	// Schema is the schema written.
+	Schema string `json:"schema,omitempty"`
+
+	// Check holds the decision of a permission check.
+	Check *CheckDecision `json:"check,omitempty"`
+}
+
+// CheckDecision is the decision of a permission check.
+type CheckDecision struct {
+	Resource       string `json:"resource"`
+	Permission     string `json:"permission"`
+	Subject        string `json:"subject"`
+	Permissionship string `json:"permissionship,omitempty"`
+}
+
+// Sink is a destination of audit records.
+type Sink interface {
+	// Write writes the record to the sink.
+	Write(ctx context.Context, record Record) error
+
+	// Close flushes and releases the resources of the sink.
+	Close() error
+}
+
+// Logger writes audit records to its sinks.
+type Logger struct {
+	sinks          []Sink
+	checkDecisions bool
+}
+
+// NewLogger returns a logger writing to all the given sinks. If checkDecisions is true, the
+// decisions of permission checks are recorded in addition to writes.
+func NewLogger(checkDecisions bool, sinks ...Sink) *Logger {
+	return &Logger{sinks: sinks, checkDecisions: checkDecisions}
+}
+
+// Log writes the record to every sink. Sink failures are reported in the debug log, and never
+// fail the audited request.
+func (l *Logger) Log(ctx context.Context, record Record) {
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+
+	for _, sink := range l.sinks {
+		if err := sink.Write(ctx, record); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("kind", string(record.Kind)).Msg("failed to write audit record")
+		}
+	}
+}
+
+// Close closes every sink.
+func (l *Logger) Close() error {
+	var errs []error
+	for _, sink := range l.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
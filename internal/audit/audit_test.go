@@ -0,0 +1,269 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type fakeSink struct {
+	sync.Mutex
+	records []Record
+}
+
+func (fs *fakeSink) Write(_ context.Context, record Record) error {
+	fs.Lock()
+	defer fs.Unlock()
+	fs.records = append(fs.records, record)
+	return nil
+}
+
+func (fs *fakeSink) Close() error { return nil }
+
+func callUnary(t *testing.T, logger *Logger, ctx context.Context, req, resp interface{}, err error) {
+	interceptor := UnaryServerInterceptor(logger)
+	_, handlerErr := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/test"}, func(context.Context, interface{}) (interface{}, error) {
+		return resp, err
+	})
+	require.Equal(t, err, handlerErr)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	sink := &fakeSink{}
+	logger := NewLogger(false, sink)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(string(requestmeta.RequestIDKey), "some-request"))
+	callUnary(t, logger, ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.UpdateToRelationshipUpdate(tuple.Create(tuple.MustParse("document:first#viewer@user:tom")))},
+	}, &v1.WriteRelationshipsResponse{WrittenAt: &v1.ZedToken{Token: "sometoken"}}, nil)
+
+	callUnary(t, logger, ctx, &v1.DeleteRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+	}, nil, status.Error(codes.PermissionDenied, "denied"))
+
+	callUnary(t, logger, ctx, &v1.WriteSchemaRequest{Schema: "definition user {}"}, &v1.WriteSchemaResponse{}, nil)
+
+	// Checks are not audited unless enabled.
+	callUnary(t, logger, ctx, &v1.CheckPermissionRequest{}, &v1.CheckPermissionResponse{}, nil)
+	callUnary(t, logger, ctx, &v1.ReadSchemaRequest{}, &v1.ReadSchemaResponse{}, nil)
+
+	require.Len(t, sink.records, 3)
+
+	write := sink.records[0]
+	require.Equal(t, KindWriteRelationships, write.Kind)
+	require.Equal(t, "/test", write.Method)
+	require.Equal(t, "OK", write.Code)
+	require.Equal(t, "some-request", write.RequestID)
+	require.Equal(t, "sometoken", write.Revision)
+	require.Equal(t, []string{"OPERATION_CREATE document:first#viewer@user:tom"}, write.Updates)
+	require.False(t, write.Time.IsZero())
+
+	deletion := sink.records[1]
+	require.Equal(t, KindDeleteRelationships, deletion.Kind)
+	require.Equal(t, "PermissionDenied", deletion.Code)
+	require.Contains(t, deletion.Error, "denied")
+	require.JSONEq(t, `{"resourceType":"document"}`, string(deletion.Filter))
+
+	require.Equal(t, KindWriteSchema, sink.records[2].Kind)
+	require.Equal(t, "definition user {}", sink.records[2].Schema)
+}
+
+func TestUnaryServerInterceptorCheckDecisions(t *testing.T) {
+	sink := &fakeSink{}
+	logger := NewLogger(true, sink)
+
+	callUnary(t, logger, context.Background(), &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "group", ObjectId: "eng"}, OptionalRelation: "member"},
+	}, &v1.CheckPermissionResponse{
+		CheckedAt:      &v1.ZedToken{Token: "sometoken"},
+		Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+	}, nil)
+
+	require.Len(t, sink.records, 1)
+	require.Equal(t, KindCheckPermission, sink.records[0].Kind)
+	require.Equal(t, &CheckDecision{
+		Resource:       "document:first",
+		Permission:     "view",
+		Subject:        "group:eng#member",
+		Permissionship: "PERMISSIONSHIP_HAS_PERMISSION",
+	}, sink.records[0].Check)
+}
+
+func TestUnaryServerInterceptorBulkCheckDecisions(t *testing.T) {
+	sink := &fakeSink{}
+	logger := NewLogger(true, sink)
+
+	first := &v1.CheckBulkPermissionsRequestItem{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	}
+	second := &v1.CheckBulkPermissionsRequestItem{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "second"},
+		Permission: "unknown",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	}
+	callUnary(t, logger, context.Background(), &v1.CheckBulkPermissionsRequest{
+		Items: []*v1.CheckBulkPermissionsRequestItem{first, second},
+	}, &v1.CheckBulkPermissionsResponse{
+		CheckedAt: &v1.ZedToken{Token: "sometoken"},
+		Pairs: []*v1.CheckBulkPermissionsPair{
+			{Request: first, Response: &v1.CheckBulkPermissionsPair_Item{Item: &v1.CheckBulkPermissionsResponseItem{
+				Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+			}}},
+			{Request: second, Response: &v1.CheckBulkPermissionsPair_Error{Error: status.New(codes.FailedPrecondition, "unknown permission").Proto()}},
+		},
+	}, nil)
+
+	// The deprecated experimental bulk check is audited the same way.
+	callUnary(t, logger, context.Background(), &v1.BulkCheckPermissionRequest{
+		Items: []*v1.BulkCheckPermissionRequestItem{{
+			Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "third"},
+			Permission: "view",
+			Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+		}},
+	}, nil, status.Error(codes.Unavailable, "unavailable"))
+
+	require.Len(t, sink.records, 3)
+
+	require.Equal(t, KindCheckPermission, sink.records[0].Kind)
+	require.Equal(t, "OK", sink.records[0].Code)
+	require.Equal(t, "sometoken", sink.records[0].Revision)
+	require.Equal(t, &CheckDecision{
+		Resource:       "document:first",
+		Permission:     "view",
+		Subject:        "user:tom",
+		Permissionship: "PERMISSIONSHIP_HAS_PERMISSION",
+	}, sink.records[0].Check)
+
+	require.Equal(t, "FailedPrecondition", sink.records[1].Code)
+	require.Equal(t, "unknown permission", sink.records[1].Error)
+	require.Equal(t, "document:second", sink.records[1].Check.Resource)
+	require.Empty(t, sink.records[1].Check.Permissionship)
+
+	require.Equal(t, "Unavailable", sink.records[2].Code)
+	require.Equal(t, "document:third", sink.records[2].Check.Resource)
+	require.Empty(t, sink.records[2].Revision)
+}
+
+func TestNilLoggerPassesThrough(t *testing.T) {
+	callUnary(t, nil, context.Background(), &v1.WriteSchemaRequest{}, &v1.WriteSchemaResponse{}, nil)
+}
+
+type fakeImportStream struct {
+	grpc.ServerStream
+	requests []*v1.BulkImportRelationshipsRequest
+}
+
+func (fis *fakeImportStream) Context() context.Context { return context.Background() }
+
+func (fis *fakeImportStream) RecvMsg(m interface{}) error {
+	if len(fis.requests) == 0 {
+		return io.EOF
+	}
+	m.(*v1.BulkImportRelationshipsRequest).Relationships = fis.requests[0].Relationships
+	fis.requests = fis.requests[1:]
+	return nil
+}
+
+func (fis *fakeImportStream) SendMsg(interface{}) error { return nil }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	sink := &fakeSink{}
+	interceptor := StreamServerInterceptor(NewLogger(false, sink))
+
+	stream := &fakeImportStream{requests: []*v1.BulkImportRelationshipsRequest{
+		{Relationships: make([]*v1.Relationship, 2)},
+		{Relationships: make([]*v1.Relationship, 3)},
+	}}
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: v1.ExperimentalService_BulkImportRelationships_FullMethodName}, func(_ interface{}, stream grpc.ServerStream) error {
+		for {
+			var req v1.BulkImportRelationshipsRequest
+			if err := stream.RecvMsg(&req); errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sink.records, 1)
+	require.Equal(t, KindImportRelationships, sink.records[0].Kind)
+	require.Equal(t, uint64(5), sink.records[0].RelationshipCount)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	logger := NewLogger(false, sink)
+	logger.Log(context.Background(), Record{Kind: KindWriteSchema, Schema: "first"})
+	logger.Log(context.Background(), Record{Kind: KindWriteSchema, Schema: "second"})
+	require.NoError(t, logger.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var schemas []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		schemas = append(schemas, record.Schema)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []string{"first", "second"}, schemas)
+}
+
+func TestHTTPSink(t *testing.T) {
+	var lock sync.Mutex
+	var schemas []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+
+		decoder := json.NewDecoder(r.Body)
+		lock.Lock()
+		defer lock.Unlock()
+		for decoder.More() {
+			var record Record
+			require.NoError(t, decoder.Decode(&record))
+			schemas = append(schemas, record.Schema)
+		}
+	}))
+	defer server.Close()
+
+	logger := NewLogger(false, NewHTTPSink(server.URL))
+	for _, schema := range []string{"first", "second", "third"} {
+		logger.Log(context.Background(), Record{Kind: KindWriteSchema, Schema: schema})
+	}
+
+	// Closing sends the buffered records.
+	require.NoError(t, logger.Close())
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, []string{"first", "second", "third"}, schemas)
+}
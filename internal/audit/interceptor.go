@@ -0,0 +1,237 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/authzed/authzed-go/pkg/requestmeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that records the audited
+// requests to the logger. A nil logger disables auditing.
+func UnaryServerInterceptor(logger *Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if logger == nil {
+			return handler(ctx, req)
+		}
+
+		resp, err := handler(ctx, req)
+		for _, record := range logger.recordsFor(req, resp) {
+			logger.Log(ctx, withRequestInfo(ctx, record, info.FullMethod, err))
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that records the audited
+// requests to the logger. A nil logger disables auditing.
+func StreamServerInterceptor(logger *Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if logger == nil || info.FullMethod != v1.ExperimentalService_BulkImportRelationships_FullMethodName {
+			return handler(srv, stream)
+		}
+
+		wrapped := &importStream{ServerStream: stream}
+		err := handler(srv, wrapped)
+
+		count := wrapped.received
+		if wrapped.loaded != nil {
+			count = *wrapped.loaded
+		}
+
+		logger.Log(stream.Context(), withRequestInfo(stream.Context(), Record{
+			Kind:              KindImportRelationships,
+			RelationshipCount: count,
+		}, info.FullMethod, err))
+		return err
+	}
+}
+
+// importStream counts the relationships received by a bulk import.
+type importStream struct {
+	grpc.ServerStream
+	received uint64
+	loaded   *uint64
+}
+
+func (is *importStream) RecvMsg(m interface{}) error {
+	err := is.ServerStream.RecvMsg(m)
+	if req, ok := m.(*v1.BulkImportRelationshipsRequest); ok && err == nil {
+		is.received += uint64(len(req.Relationships))
+	}
+	return err
+}
+
+func (is *importStream) SendMsg(m interface{}) error {
+	if resp, ok := m.(*v1.BulkImportRelationshipsResponse); ok {
+		is.loaded = &resp.NumLoaded
+	}
+	return is.ServerStream.SendMsg(m)
+}
+
+// recordsFor returns the records of the request, if it is audited. Bulk checks have a record
+// for each of their items.
+func (l *Logger) recordsFor(req, resp interface{}) []Record {
+	switch req := req.(type) {
+	case *v1.WriteRelationshipsRequest:
+		record := Record{Kind: KindWriteRelationships}
+		for _, update := range req.Updates {
+			record.Updates = append(record.Updates, update.Operation.String()+" "+formatRelationship(update.Relationship))
+		}
+		if resp, ok := resp.(*v1.WriteRelationshipsResponse); ok {
+			record.Revision = resp.GetWrittenAt().GetToken()
+		}
+		return []Record{record}
+
+	case *v1.DeleteRelationshipsRequest:
+		record := Record{Kind: KindDeleteRelationships}
+		if filter, err := protojson.Marshal(req.RelationshipFilter); err == nil {
+			record.Filter = filter
+		}
+		if resp, ok := resp.(*v1.DeleteRelationshipsResponse); ok {
+			record.Revision = resp.GetDeletedAt().GetToken()
+		}
+		return []Record{record}
+
+	case *v1.WriteSchemaRequest:
+		record := Record{Kind: KindWriteSchema, Schema: req.Schema}
+		if resp, ok := resp.(*v1.WriteSchemaResponse); ok {
+			record.Revision = resp.GetWrittenAt().GetToken()
+		}
+		return []Record{record}
+
+	case *v1.CheckPermissionRequest:
+		if !l.checkDecisions {
+			return nil
+		}
+
+		record := checkRecord(req.GetResource(), req.GetPermission(), req.GetSubject())
+		if resp, ok := resp.(*v1.CheckPermissionResponse); ok && resp != nil {
+			record.Revision = resp.GetCheckedAt().GetToken()
+			record.Check.Permissionship = resp.Permissionship.String()
+		}
+		return []Record{record}
+
+	case *v1.CheckBulkPermissionsRequest:
+		if !l.checkDecisions {
+			return nil
+		}
+
+		resp, _ := resp.(*v1.CheckBulkPermissionsResponse)
+		records := make([]Record, 0, len(req.GetItems()))
+		for i, item := range req.GetItems() {
+			record := checkRecord(item.GetResource(), item.GetPermission(), item.GetSubject())
+			record.Revision = resp.GetCheckedAt().GetToken()
+
+			// Pairs are returned in the order of the items.
+			if pairs := resp.GetPairs(); i < len(pairs) {
+				if result := pairs[i].GetItem(); result != nil {
+					record.Check.Permissionship = result.GetPermissionship().String()
+				}
+				withItemError(&record, pairs[i].GetError())
+			}
+			records = append(records, record)
+		}
+		return records
+
+	case *v1.BulkCheckPermissionRequest:
+		if !l.checkDecisions {
+			return nil
+		}
+
+		resp, _ := resp.(*v1.BulkCheckPermissionResponse)
+		records := make([]Record, 0, len(req.GetItems()))
+		for i, item := range req.GetItems() {
+			record := checkRecord(item.GetResource(), item.GetPermission(), item.GetSubject())
+			record.Revision = resp.GetCheckedAt().GetToken()
+
+			// Pairs are returned in the order of the items.
+			if pairs := resp.GetPairs(); i < len(pairs) {
+				if result := pairs[i].GetItem(); result != nil {
+					record.Check.Permissionship = result.GetPermissionship().String()
+				}
+				withItemError(&record, pairs[i].GetError())
+			}
+			records = append(records, record)
+		}
+		return records
+
+	default:
+		return nil
+	}
+}
+
+func checkRecord(resource *v1.ObjectReference, permission string, subject *v1.SubjectReference) Record {
+	return Record{Kind: KindCheckPermission, Check: &CheckDecision{
+		Resource:   resource.GetObjectType() + ":" + resource.GetObjectId(),
+		Permission: permission,
+		Subject:    formatSubject(subject),
+	}}
+}
+
+// withItemError records the error of an item of a bulk request which failed on its own.
+func withItemError(record *Record, itemErr *rpcstatus.Status) {
+	if itemErr != nil {
+		record.Code = codes.Code(itemErr.GetCode()).String()
+		record.Error = itemErr.GetMessage()
+	}
+}
+
+// withRequestInfo fills in the details of the request common to all records.
+func withRequestInfo(ctx context.Context, record Record, method string, err error) Record {
+	record.Method = method
+
+	// The records of the items of a bulk request which failed on their own already hold their
+	// error.
+	if err != nil || record.Code == "" {
+		record.Code = status.Code(err).String()
+		if err != nil {
+			record.Error = err.Error()
+		}
+	}
+
+	if caller, ok := auth.CallerFromContext(ctx); ok {
+		record.Caller = caller.Identity
+		record.CallerIssuer = caller.Issuer
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		record.Peer = p.Addr.String()
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if requestIDs := md.Get(string(requestmeta.RequestIDKey)); len(requestIDs) > 0 {
+			record.RequestID = requestIDs[0]
+		}
+	}
+
+	return record
+}
+
+func formatRelationship(rel *v1.Relationship) string {
+	if formatted, err := tuple.StringRelationship(rel); err == nil {
+		return formatted
+	}
+
+	// Invalid relationships are still recorded, as they were requested.
+	encoded, _ := protojson.Marshal(rel)
+	return string(encoded)
+}
+
+func formatSubject(subject *v1.SubjectReference) string {
+	formatted := subject.GetObject().GetObjectType() + ":" + subject.GetObject().GetObjectId()
+	if subject.GetOptionalRelation() != "" {
+		formatted += "#" + subject.GetOptionalRelation()
+	}
+	return formatted
+}
@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// NewFileSink returns a sink appending records as JSON lines to the file at the given path,
+// which is created if it does not exist.
+func NewFileSink(path string) (Sink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	return &writerSink{writer: file}, nil
+}
+
+type writerSink struct {
+	sync.Mutex
+	writer io.WriteCloser
+}
+
+func (ws *writerSink) Write(_ context.Context, record Record) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ws.Lock()
+	defer ws.Unlock()
+	_, err = ws.writer.Write(append(encoded, '\n'))
+	return err
+}
+
+func (ws *writerSink) Close() error {
+	ws.Lock()
+	defer ws.Unlock()
+	return ws.writer.Close()
+}
+
+const (
+	httpSinkBufferSize = 1024
+	httpSinkBatchSize  = 100
+)
+
+// NewHTTPSink returns a sink POSTing records as JSON lines to the given URL. Records are
+// buffered and sent in batches in the background, so slow endpoints do not slow down requests;
+// records are dropped, with an error logged, when the buffer is full.
+func NewHTTPSink(url string) Sink {
+	sink := &httpSink{
+		url:     url,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		records: make(chan Record, httpSinkBufferSize),
+		done:    make(chan struct{}),
+	}
+	go sink.run()
+	return sink
+}
+
+type httpSink struct {
+	url     string
+	client  *http.Client
+	records chan Record
+	done    chan struct{}
+	closed  sync.Once
+}
+
+func (hs *httpSink) Write(ctx context.Context, record Record) error {
+	select {
+	case hs.records <- record:
+		return nil
+	default:
+		return fmt.Errorf("audit HTTP sink buffer is full; dropping record")
+	}
+}
+
+func (hs *httpSink) run() {
+	defer close(hs.done)
+
+	for record := range hs.records {
+		batch := []Record{record}
+	gather:
+		for len(batch) < httpSinkBatchSize {
+			select {
+			case record, ok := <-hs.records:
+				if !ok {
+					break gather
+				}
+				batch = append(batch, record)
+			default:
+				break gather
+			}
+		}
+
+		if err := hs.post(batch); err != nil {
+			log.Error().Err(err).Str("url", hs.url).Int("records", len(batch)).Msg("failed to send audit records")
+		}
+	}
+}
+
+func (hs *httpSink) post(batch []Record) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, record := range batch {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	resp, err := hs.client.Post(hs.url, "application/x-ndjson", &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected audit endpoint response: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close sends the buffered records before returning.
+func (hs *httpSink) Close() error {
+	hs.closed.Do(func() {
+		close(hs.records)
+	})
+	<-hs.done
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !windows && !wasm && !plan9
+// +build !windows,!wasm,!plan9
+
+package audit
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// NewSyslogSink returns a sink writing each record as a JSON syslog message. If network and
+// addr are empty, the local syslog server is used.
+func NewSyslogSink(network, addr, tag string) (Sink, error) {
+	writer, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &writerSink{writer: writer}, nil
+}
@@ -0,0 +1,11 @@
+//go:build windows || wasm || plan9
+// +build windows wasm plan9
+
+package audit
+
+import "errors"
+
+// NewSyslogSink is not supported on this platform.
+func NewSyslogSink(_, _, _ string) (Sink, error) {
+	return nil, errors.New("syslog audit sink is not supported on this platform")
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v4"
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	errInvalidJWT = "invalid token: %s"
+	errMissingJWT = "missing token"
+
+	// defaultIdentityClaim is the claim used as the caller identity if none is configured.
+	defaultIdentityClaim = "sub"
+)
+
+// JWTConfig configures the validation of JWT bearer tokens.
+type JWTConfig struct {
+	// Issuer is the required value of the `iss` claim. If JWKSURL is empty, the key set is
+	// discovered from the issuer's OpenID Connect discovery document when the verifier is
+	// created.
+	Issuer string
+
+	// Audience, if non-empty, must be found in the `aud` claim.
+	Audience string
+
+	// JWKSURL is the URL of the JSON Web Key Set used to verify token signatures.
+	JWKSURL string
+
+	// IdentityClaim is the claim holding the caller identity. Defaults to `sub`.
+	IdentityClaim string
+
+	// HTTPClient is the client used to fetch the discovery document and key set. Defaults to
+	// a client with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// Caller is the identity of an authenticated caller.
+type Caller struct {
+	// Identity is the value of the configured identity claim.
+	Identity string
+
+	// Issuer is the issuer of the token presented by the caller.
+	Issuer string
+
+	// Claims are all the claims found in the token presented by the caller.
+	Claims map[string]any
+}
+
+type callerKeyType struct{}
+
+var callerKey callerKeyType = struct{}{}
+
+type callerHandle struct {
+	caller *Caller
+}
+
+// ContextWithCallerHandle adds a placeholder to a context that will later be filled by the
+// authenticated caller, so that middleware running before authentication can read it. A caller
+// already attached to the context is kept.
+func ContextWithCallerHandle(ctx context.Context) context.Context {
+	caller, _ := CallerFromContext(ctx)
+	return context.WithValue(ctx, callerKey, &callerHandle{caller})
+}
+
+// ContextWithCaller returns a context with the given caller attached. If the context has a
+// placeholder added by ContextWithCallerHandle, the caller is stored in it.
+func ContextWithCaller(ctx context.Context, caller *Caller) context.Context {
+	if handle, ok := ctx.Value(callerKey).(*callerHandle); ok {
+		handle.caller = caller
+		return ctx
+	}
+	return context.WithValue(ctx, callerKey, &callerHandle{caller})
+}
+
+// CallerFromContext returns the authenticated caller attached to the context, if any.
+func CallerFromContext(ctx context.Context) (*Caller, bool) {
+	handle, ok := ctx.Value(callerKey).(*callerHandle)
+	if !ok || handle.caller == nil {
+		return nil, false
+	}
+	return handle.caller, true
+}
+
+// RequireJWT requires that gRPC requests have a Bearer Token that is a JWT signed by one of
+// the keys of the configured issuer. The caller identity found in the token is attached to the
+// request context and can be retrieved with CallerFromContext.
+func RequireJWT(config JWTConfig) (grpcauth.AuthFunc, error) {
+	verifier, err := NewJWTVerifier(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) (context.Context, error) {
+		token, err := grpcauth.AuthFromMD(ctx, "bearer")
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, errInvalidJWT, err.Error())
+		}
+
+		if token == "" {
+			return nil, status.Errorf(codes.Unauthenticated, errMissingJWT)
+		}
+
+		caller, err := verifier.Verify(ctx, token)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, errInvalidJWT, err.Error())
+		}
+
+		return ContextWithCaller(ctx, caller), nil
+	}, nil
+}
+
+// RequireAnyOf returns an AuthFunc that accepts a request if any of the given AuthFuncs accepts
+// it. If none do, the error of the last AuthFunc is returned.
+func RequireAnyOf(authFuncs ...grpcauth.AuthFunc) grpcauth.AuthFunc {
+	if len(authFuncs) == 0 {
+		panic("RequireAnyOf was given no auth functions")
+	}
+
+	return func(ctx context.Context) (context.Context, error) {
+		var lastErr error
+		for _, authFunc := range authFuncs {
+			newCtx, err := authFunc(ctx)
+			if err == nil {
+				return newCtx, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
+
+// signingAlgorithms are the algorithms tokens can be signed with. Symmetric algorithms are
+// excluded, so that a key of the key set can never be used as an HMAC secret.
+var signingAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// JWTVerifier verifies JWTs against the configured issuer.
+type JWTVerifier struct {
+	config   JWTConfig
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewJWTVerifier returns a verifier for JWTs issued by the configured issuer.
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	if config.Issuer == "" {
+		return nil, errors.New("a JWT issuer must be provided")
+	}
+
+	if config.IdentityClaim == "" {
+		config.IdentityClaim = defaultIdentityClaim
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	algorithms := make([]string, 0, len(signingAlgorithms))
+	for _, algorithm := range signingAlgorithms {
+		algorithms = append(algorithms, string(algorithm))
+	}
+
+	// The key set is fetched in the background of requests, so it is not bound to the context
+	// of any of them.
+	ctx := oidc.ClientContext(context.Background(), config.HTTPClient)
+
+	jwksURL := config.JWKSURL
+	if jwksURL == "" {
+		provider, err := oidc.NewProvider(ctx, config.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("unable to discover the OpenID Connect provider: %w", err)
+		}
+
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := provider.Claims(&discovery); err != nil {
+			return nil, fmt.Errorf("invalid OpenID Connect discovery document: %w", err)
+		}
+
+		if discovery.JWKSURI == "" {
+			return nil, errors.New("discovery document does not specify a `jwks_uri`")
+		}
+		jwksURL = discovery.JWKSURI
+	}
+
+	return &JWTVerifier{
+		config: config,
+		verifier: oidc.NewVerifier(config.Issuer, oidc.NewRemoteKeySet(ctx, jwksURL), &oidc.Config{
+			ClientID:             config.Audience,
+			SkipClientIDCheck:    config.Audience == "",
+			SupportedSigningAlgs: algorithms,
+		}),
+	}, nil
+}
+
+// Verify checks the signature and claims of the token, returning the caller it identifies.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Caller, error) {
+	idToken, err := v.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	identity, _ := claims[v.config.IdentityClaim].(string)
+	if identity == "" {
+		return nil, fmt.Errorf("missing `%s` claim", v.config.IdentityClaim)
+	}
+
+	return &Caller{
+		Identity: identity,
+		Issuer:   idToken.Issuer,
+		Claims:   claims,
+	}, nil
+}
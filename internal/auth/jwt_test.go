@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+type testSigner struct {
+	kid string
+	alg string
+	key crypto.Signer
+}
+
+func (s testSigner) jwk() map[string]string {
+	switch pub := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"kid": s.kid,
+			"alg": s.alg,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		return map[string]string{
+			"kty": "EC",
+			"kid": s.kid,
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+		}
+	case ed25519.PublicKey:
+		return map[string]string{
+			"kty": "OKP",
+			"kid": s.kid,
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(pub),
+		}
+	default:
+		panic("unsupported key")
+	}
+}
+
+func (s testSigner) sign(t *testing.T, claims map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": s.alg, "kid": s.kid, "typ": "JWT"})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch key := s.key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(signed))
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	default:
+		digest := sha256.Sum256([]byte(signed))
+		signature, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+		require.NoError(t, err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestRequireJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	rsaSigner := testSigner{"rsa", "RS256", rsaKey}
+	ecSigner := testSigner{"ec", "ES256", ecKey}
+	edSigner := testSigner{"ed", "EdDSA", edKey}
+	unknownSigner := testSigner{"unknown", "RS256", otherKey}
+	impostorSigner := testSigner{"rsa", "RS256", otherKey}
+
+	var issuer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":   issuer,
+				"jwks_uri": issuer + "/keys",
+			})
+		case "/keys":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"keys": []map[string]string{rsaSigner.jwk(), ecSigner.jwk(), edSigner.jwk()},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	issuer = server.URL
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss": issuer,
+			"sub": "some-service",
+			"aud": []string{"spicedb"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"nbf": time.Now().Add(-time.Minute).Unix(),
+		}
+	}
+
+	withClaim := func(name string, value any) map[string]any {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	testcases := []struct {
+		name             string
+		token            func(t *testing.T) string
+		expectedStatus   codes.Code
+		expectedIdentity string
+	}{
+		{"valid RSA token", func(t *testing.T) string { return rsaSigner.sign(t, validClaims()) }, codes.OK, "some-service"},
+		{"valid EC token", func(t *testing.T) string { return ecSigner.sign(t, validClaims()) }, codes.OK, "some-service"},
+		{"valid EdDSA token", func(t *testing.T) string { return edSigner.sign(t, validClaims()) }, codes.OK, "some-service"},
+		{"single audience", func(t *testing.T) string { return rsaSigner.sign(t, withClaim("aud", "spicedb")) }, codes.OK, "some-service"},
+		{"expired", func(t *testing.T) string {
+			return rsaSigner.sign(t, withClaim("exp", time.Now().Add(-time.Hour).Unix()))
+		}, codes.Unauthenticated, ""},
+		{"missing expiration", func(t *testing.T) string { return rsaSigner.sign(t, withClaim("exp", nil)) }, codes.Unauthenticated, ""},
+		{"not yet valid", func(t *testing.T) string {
+			return rsaSigner.sign(t, withClaim("nbf", time.Now().Add(time.Hour).Unix()))
+		}, codes.Unauthenticated, ""},
+		{"wrong issuer", func(t *testing.T) string { return rsaSigner.sign(t, withClaim("iss", "https://example.com")) }, codes.Unauthenticated, ""},
+		{"wrong audience", func(t *testing.T) string { return rsaSigner.sign(t, withClaim("aud", "other")) }, codes.Unauthenticated, ""},
+		{"missing subject", func(t *testing.T) string { return rsaSigner.sign(t, withClaim("sub", nil)) }, codes.Unauthenticated, ""},
+		{"unknown key", func(t *testing.T) string { return unknownSigner.sign(t, validClaims()) }, codes.Unauthenticated, ""},
+		{"invalid signature", func(t *testing.T) string { return impostorSigner.sign(t, validClaims()) }, codes.Unauthenticated, ""},
+		{"algorithm mismatch", func(t *testing.T) string {
+			return testSigner{"rsa", "PS256", rsaKey}.sign(t, validClaims())
+		}, codes.Unauthenticated, ""},
+		{"unsigned", func(t *testing.T) string {
+			parts := strings.Split(rsaSigner.sign(t, validClaims()), ".")
+			header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"rsa"}`))
+			return header + "." + parts[1] + "."
+		}, codes.Unauthenticated, ""},
+		{"symmetric algorithm", func(t *testing.T) string {
+			parts := strings.Split(rsaSigner.sign(t, validClaims()), ".")
+			header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","kid":"rsa"}`))
+			return header + "." + parts[1] + "." + parts[2]
+		}, codes.Unauthenticated, ""},
+		{"malformed", func(t *testing.T) string { return "not-a-jwt" }, codes.Unauthenticated, ""},
+		{"missing", func(t *testing.T) string { return "" }, codes.Unauthenticated, ""},
+	}
+
+	authFunc, err := RequireJWT(JWTConfig{
+		Issuer:   issuer,
+		Audience: "spicedb",
+	})
+	require.NoError(t, err)
+
+	for _, testcase := range testcases {
+		testcase := testcase
+		t.Run(testcase.name, func(t *testing.T) {
+			ctx, err := authFunc(withTokenMetadata("bearer " + testcase.token(t)))
+			if testcase.expectedStatus != codes.OK {
+				require.Error(t, err)
+				grpcutil.RequireStatus(t, testcase.expectedStatus, err)
+				return
+			}
+
+			require.NoError(t, err)
+			caller, ok := CallerFromContext(ctx)
+			require.True(t, ok)
+			require.Equal(t, testcase.expectedIdentity, caller.Identity)
+			require.Equal(t, issuer, caller.Issuer)
+		})
+	}
+}
+
+func TestRequireJWTKeySetFetchFailure(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer := testSigner{"rsa", "RS256", key}
+
+	var issuer string
+	var keySetFetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first fetch of the key set fails.
+		if keySetFetches.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{signer.jwk()},
+		})
+	}))
+	defer server.Close()
+	issuer = server.URL
+
+	authFunc, err := RequireJWT(JWTConfig{
+		Issuer:  issuer,
+		JWKSURL: issuer + "/keys",
+	})
+	require.NoError(t, err)
+
+	token := signer.sign(t, map[string]any{
+		"iss": issuer,
+		"sub": "some-service",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	_, err = authFunc(withTokenMetadata("bearer " + token))
+	grpcutil.RequireStatus(t, codes.Unauthenticated, err)
+
+	// The key set is fetched again by the next request, rather than rejecting tokens until some
+	// refresh interval has passed.
+	ctx, err := authFunc(withTokenMetadata("bearer " + token))
+	require.NoError(t, err)
+	caller, ok := CallerFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "some-service", caller.Identity)
+}
+
+func TestRequireJWTIdentityClaim(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer := testSigner{"", "RS256", key}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{signer.jwk()},
+		})
+	}))
+	defer server.Close()
+
+	authFunc, err := RequireJWT(JWTConfig{
+		Issuer:        "https://issuer.example.com",
+		JWKSURL:       server.URL,
+		IdentityClaim: "email",
+	})
+	require.NoError(t, err)
+
+	ctx, err := authFunc(withTokenMetadata("bearer " + signer.sign(t, map[string]any{
+		"iss":   "https://issuer.example.com",
+		"sub":   "1234",
+		"email": "someone@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})))
+	require.NoError(t, err)
+
+	caller, ok := CallerFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "someone@example.com", caller.Identity)
+}
+
+func TestRequireAnyOf(t *testing.T) {
+	authFunc := RequireAnyOf(MustRequirePresharedKey([]string{"one"}), MustRequirePresharedKey([]string{"two"}))
+
+	_, err := authFunc(withTokenMetadata("bearer one"))
+	require.NoError(t, err)
+
+	_, err = authFunc(withTokenMetadata("bearer two"))
+	require.NoError(t, err)
+
+	_, err = authFunc(withTokenMetadata("bearer three"))
+	grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+
+	_, err = authFunc(context.Background())
+	grpcutil.RequireStatus(t, codes.Unauthenticated, err)
+}
@@ -3,6 +3,9 @@ package auth
 import (
 	"context"
 	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
 
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"google.golang.org/grpc/codes"
@@ -48,3 +51,26 @@ func MustRequirePresharedKey(presharedKeys []string) grpcauth.AuthFunc {
 		return nil, status.Errorf(codes.PermissionDenied, errInvalidPresharedKey, errInvalidToken)
 	}
 }
+
+// RequirePresharedKeyHandler requires that HTTP requests have a Bearer Token value equivalent to
+// one of the provided preshared key(s) before serving them with the handler. If no preshared key
+// is provided, every request is denied.
+func RequirePresharedKeyHandler(presharedKeys []string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "bearer") || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, errMissingPresharedKey, http.StatusUnauthorized)
+			return
+		}
+
+		for _, presharedKey := range presharedKeys {
+			if match := subtle.ConstantTimeCompare([]byte(presharedKey), []byte(token)); match == 1 && presharedKey != "" {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		http.Error(w, fmt.Sprintf(errInvalidPresharedKey, errInvalidToken), http.StatusForbidden)
+	})
+}
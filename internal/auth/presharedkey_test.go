@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/authzed/grpcutil"
@@ -46,6 +48,41 @@ func TestPresharedKeys(t *testing.T) {
 	}
 }
 
+func TestRequirePresharedKeyHandler(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	testcases := []struct {
+		name           string
+		presharedkeys  []string
+		authzHeader    string
+		expectedStatus int
+	}{
+		{"valid request with the first key", []string{"one", "two"}, "Bearer one", http.StatusNoContent},
+		{"valid request with the second key", []string{"one", "two"}, "bearer two", http.StatusNoContent},
+		{"denied due to unknown key", []string{"one", "two"}, "Bearer three", http.StatusForbidden},
+		{"denied without keys", nil, "Bearer one", http.StatusForbidden},
+		{"unauthenticated due to missing key", []string{"one", "two"}, "Bearer ", http.StatusUnauthorized},
+		{"unauthenticated due to other scheme", []string{"one", "two"}, "Basic one", http.StatusUnauthorized},
+		{"unauthenticated due to empty header", []string{"one", "two"}, "", http.StatusUnauthorized},
+	}
+
+	for _, testcase := range testcases {
+		testcase := testcase
+		t.Run(testcase.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if testcase.authzHeader != "" {
+				req.Header.Set("Authorization", testcase.authzHeader)
+			}
+
+			recorder := httptest.NewRecorder()
+			RequirePresharedKeyHandler(testcase.presharedkeys, handler).ServeHTTP(recorder, req)
+			require.Equal(t, testcase.expectedStatus, recorder.Code)
+		})
+	}
+}
+
 func withTokenMetadata(authzHeader string) context.Context {
 	md := metadata.Pairs("authorization", authzHeader)
 	return metautils.MD(md).ToIncoming(context.Background())
@@ -0,0 +1,78 @@
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+var testShape = Shape{
+	Documents:      20,
+	Users:          10,
+	GroupsPerLevel: 3,
+	NestingDepth:   2,
+	Fanout:         3,
+}
+
+func TestGenerate(t *testing.T) {
+	workload, err := Generate(testShape, 1)
+	require.NoError(t, err)
+	require.NotEmpty(t, workload.Relationships)
+
+	// Generation is reproducible.
+	again, err := Generate(testShape, 1)
+	require.NoError(t, err)
+	require.Equal(t, workload.Relationships, again.Relationships)
+
+	var nested bool
+	for _, rel := range workload.Relationships {
+		require.NoError(t, rel.Validate())
+		if rel.Resource.ObjectId == GroupID(1, 0) {
+			require.Equal(t, "group", rel.Subject.Object.ObjectType)
+			nested = true
+		}
+	}
+	require.True(t, nested)
+
+	_, err = Generate(Shape{Documents: 1, Users: 1, Fanout: 1, NestingDepth: 1}, 1)
+	require.Error(t, err)
+}
+
+func TestLoadAndRun(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, false, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := NewClient(conn)
+
+	workload, err := Generate(testShape, 1)
+	require.NoError(t, err)
+
+	writtenAt, err := Load(context.Background(), client, workload)
+	require.NoError(t, err)
+	require.NotNil(t, writtenAt)
+
+	// Loading is idempotent.
+	_, err = Load(context.Background(), client, workload)
+	require.NoError(t, err)
+
+	report, err := Run(context.Background(), client, workload, LoadConfig{
+		Duration:    200 * time.Millisecond,
+		Concurrency: 2,
+		LookupRatio: 0.5,
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: writtenAt}},
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Results, 2)
+	for _, result := range report.Results {
+		require.Positive(t, result.Count)
+		require.Zero(t, result.Errors)
+		require.Positive(t, result.P50)
+		require.LessOrEqual(t, result.P50, result.P99)
+	}
+}
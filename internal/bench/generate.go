@@ -0,0 +1,149 @@
+// Package bench generates synthetic schemas and relationship graphs, and drives permission
+// check and lookup load against a SpiceDB instance to measure its latency.
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+// Schema is the schema of the generated workloads: documents are viewable by users and by the
+// members of groups, which can be nested.
+const Schema = `definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition document {
+	relation viewer: user | group#member
+	permission view = viewer
+}`
+
+// Shape configures the size and structure of a generated relationship graph.
+type Shape struct {
+	// Documents is the number of documents.
+	Documents int
+
+	// Users is the number of users.
+	Users int
+
+	// GroupsPerLevel is the number of groups at each level of nesting.
+	GroupsPerLevel int
+
+	// NestingDepth is the number of levels of nested groups. Groups at the first level have users
+	// as members, and groups at each following level have groups of the previous level as members.
+	// Zero disables groups.
+	NestingDepth int
+
+	// Fanout is the number of viewers of each document and of members of each group.
+	Fanout int
+}
+
+// Validate returns an error if the shape cannot generate a graph.
+func (s Shape) Validate() error {
+	switch {
+	case s.Documents <= 0:
+		return fmt.Errorf("at least one document is required")
+	case s.Users <= 0:
+		return fmt.Errorf("at least one user is required")
+	case s.Fanout <= 0:
+		return fmt.Errorf("fanout must be positive")
+	case s.NestingDepth < 0:
+		return fmt.Errorf("nesting depth cannot be negative")
+	case s.NestingDepth > 0 && s.GroupsPerLevel <= 0:
+		return fmt.Errorf("at least one group per level is required for nested groups")
+	default:
+		return nil
+	}
+}
+
+// Workload is a generated schema and relationship graph.
+type Workload struct {
+	Shape         Shape
+	Schema        string
+	Relationships []*v1.Relationship
+}
+
+// Generate generates a relationship graph of the given shape. The same seed always generates the
+// same graph.
+func Generate(shape Shape, seed int64) (*Workload, error) {
+	if err := shape.Validate(); err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec
+	// G404 use of non cryptographically secure random number generator is not concern here,
+	// as the generated graphs only need to be reproducible.
+	rnd := rand.New(rand.NewSource(seed))
+	seen := make(map[string]struct{})
+	var relationships []*v1.Relationship
+	add := func(resource *v1.ObjectReference, relation string, subject *v1.SubjectReference) {
+		rel := &v1.Relationship{Resource: resource, Relation: relation, Subject: subject}
+		key := fmt.Sprintf("%s:%s#%s@%s:%s#%s", resource.ObjectType, resource.ObjectId, relation,
+			subject.Object.ObjectType, subject.Object.ObjectId, subject.OptionalRelation)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		relationships = append(relationships, rel)
+	}
+
+	// Groups at the first level contain users, and groups at each following level contain
+	// groups of the level before it.
+	for level := 0; level < shape.NestingDepth; level++ {
+		for index := 0; index < shape.GroupsPerLevel; index++ {
+			group := &v1.ObjectReference{ObjectType: "group", ObjectId: GroupID(level, index)}
+			for i := 0; i < shape.Fanout; i++ {
+				if level == 0 {
+					add(group, "member", userSubject(rnd.Intn(shape.Users)))
+				} else {
+					add(group, "member", groupSubject(level-1, rnd.Intn(shape.GroupsPerLevel)))
+				}
+			}
+		}
+	}
+
+	// Documents are viewable by users and by groups of the top level, so that checks traverse
+	// every level of nesting.
+	for index := 0; index < shape.Documents; index++ {
+		document := &v1.ObjectReference{ObjectType: "document", ObjectId: DocumentID(index)}
+		for i := 0; i < shape.Fanout; i++ {
+			if shape.NestingDepth > 0 && rnd.Intn(2) == 0 {
+				add(document, "viewer", groupSubject(shape.NestingDepth-1, rnd.Intn(shape.GroupsPerLevel)))
+			} else {
+				add(document, "viewer", userSubject(rnd.Intn(shape.Users)))
+			}
+		}
+	}
+
+	return &Workload{Shape: shape, Schema: Schema, Relationships: relationships}, nil
+}
+
+// DocumentID returns the ID of the document with the given index.
+func DocumentID(index int) string {
+	return fmt.Sprintf("doc%d", index)
+}
+
+// UserID returns the ID of the user with the given index.
+func UserID(index int) string {
+	return fmt.Sprintf("user%d", index)
+}
+
+// GroupID returns the ID of the group with the given index at the given level of nesting.
+func GroupID(level, index int) string {
+	return fmt.Sprintf("group%d_%d", level, index)
+}
+
+func userSubject(index int) *v1.SubjectReference {
+	return &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: UserID(index)}}
+}
+
+func groupSubject(level, index int) *v1.SubjectReference {
+	return &v1.SubjectReference{
+		Object:           &v1.ObjectReference{ObjectType: "group", ObjectId: GroupID(level, index)},
+		OptionalRelation: "member",
+	}
+}
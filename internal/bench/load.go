@@ -0,0 +1,252 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/influxdata/tdigest"
+	"google.golang.org/grpc"
+)
+
+const (
+	writeBatchSize           = 500
+	latencyDigestCompression = 1000
+)
+
+// Client is the subset of the SpiceDB API used to load and benchmark a workload.
+type Client interface {
+	v1.SchemaServiceClient
+	v1.PermissionsServiceClient
+}
+
+// NewClient returns a client using the given connection.
+func NewClient(conn grpc.ClientConnInterface) Client {
+	return struct {
+		v1.SchemaServiceClient
+		v1.PermissionsServiceClient
+	}{v1.NewSchemaServiceClient(conn), v1.NewPermissionsServiceClient(conn)}
+}
+
+// Load writes the schema and relationships of the workload. Relationships are touched, so that
+// loading the same workload again succeeds. It returns the revision at which all the
+// relationships have been written.
+func Load(ctx context.Context, client Client, workload *Workload) (*v1.ZedToken, error) {
+	if _, err := client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: workload.Schema}); err != nil {
+		return nil, fmt.Errorf("failed to write schema: %w", err)
+	}
+
+	var writtenAt *v1.ZedToken
+	for start := 0; start < len(workload.Relationships); start += writeBatchSize {
+		end := start + writeBatchSize
+		if end > len(workload.Relationships) {
+			end = len(workload.Relationships)
+		}
+
+		updates := make([]*v1.RelationshipUpdate, 0, end-start)
+		for _, rel := range workload.Relationships[start:end] {
+			updates = append(updates, &v1.RelationshipUpdate{
+				Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+				Relationship: rel,
+			})
+		}
+
+		resp, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write relationships: %w", err)
+		}
+		writtenAt = resp.WrittenAt
+	}
+
+	return writtenAt, nil
+}
+
+// Operation is a kind of request sent by the benchmark.
+type Operation string
+
+const (
+	// OperationCheck is a CheckPermission of the view permission of a random document for a
+	// random user.
+	OperationCheck Operation = "check"
+
+	// OperationLookupResources is a LookupResources of the documents viewable by a random user,
+	// reading the full response stream.
+	OperationLookupResources Operation = "lookup-resources"
+)
+
+// LoadConfig configures the load driven against the target.
+type LoadConfig struct {
+	// Duration is how long load is sent for.
+	Duration time.Duration
+
+	// Concurrency is the number of requests in flight at any time.
+	Concurrency int
+
+	// LookupRatio is the fraction of requests which are lookups rather than checks.
+	LookupRatio float64
+
+	// Consistency is the consistency of the requests. If nil, latency is minimized.
+	Consistency *v1.Consistency
+
+	// Seed seeds the selection of the documents and users of requests.
+	Seed int64
+}
+
+// Result is the outcome of the requests of one operation.
+type Result struct {
+	Operation Operation
+	Count     int
+	Errors    int
+	P50       time.Duration
+	P90       time.Duration
+	P99       time.Duration
+	Max       time.Duration
+}
+
+// Report is the outcome of a benchmark run.
+type Report struct {
+	Elapsed time.Duration
+	Results []Result
+}
+
+type recorder struct {
+	sync.Mutex
+	digest *tdigest.TDigest
+	count  int
+	errors int
+	max    time.Duration
+}
+
+func (r *recorder) record(latency time.Duration, err error) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.count++
+	if err != nil {
+		r.errors++
+		return
+	}
+
+	r.digest.Add(float64(latency), 1)
+	if latency > r.max {
+		r.max = latency
+	}
+}
+
+func (r *recorder) result(operation Operation) Result {
+	r.Lock()
+	defer r.Unlock()
+
+	result := Result{Operation: operation, Count: r.count, Errors: r.errors, Max: r.max}
+	if r.count > r.errors {
+		result.P50 = time.Duration(r.digest.Quantile(0.5))
+		result.P90 = time.Duration(r.digest.Quantile(0.9))
+		result.P99 = time.Duration(r.digest.Quantile(0.99))
+	}
+	return result
+}
+
+// Run sends check and lookup requests about the workload for the configured duration, and
+// reports their latencies. Failed requests are counted, but do not stop the run. The run also
+// stops, and reports the requests sent so far, when the context is canceled.
+func Run(ctx context.Context, client Client, workload *Workload, config LoadConfig) (*Report, error) {
+	if config.Concurrency <= 0 {
+		return nil, fmt.Errorf("concurrency must be positive")
+	}
+	if config.LookupRatio < 0 || config.LookupRatio > 1 {
+		return nil, fmt.Errorf("lookup ratio must be between 0 and 1")
+	}
+
+	consistency := config.Consistency
+	if consistency == nil {
+		consistency = &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}
+	}
+
+	recorders := map[Operation]*recorder{
+		OperationCheck:           {digest: tdigest.NewWithCompression(latencyDigestCompression)},
+		OperationLookupResources: {digest: tdigest.NewWithCompression(latencyDigestCompression)},
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for worker := 0; worker < config.Concurrency; worker++ {
+		// nolint:gosec
+		// G404 use of non cryptographically secure random number generator is not concern here,
+		// as it only selects the documents and users of requests.
+		rnd := rand.New(rand.NewSource(config.Seed + int64(worker)))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for runCtx.Err() == nil {
+				operation := OperationCheck
+				if rnd.Float64() < config.LookupRatio {
+					operation = OperationLookupResources
+				}
+
+				requestStart := time.Now()
+				err := sendRequest(runCtx, client, workload, operation, consistency, rnd)
+				if runCtx.Err() != nil {
+					// Requests interrupted by the end of the run are not recorded.
+					return
+				}
+				recorders[operation].record(time.Since(requestStart), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Elapsed: time.Since(start)}
+	for _, operation := range []Operation{OperationCheck, OperationLookupResources} {
+		if result := recorders[operation].result(operation); result.Count > 0 {
+			report.Results = append(report.Results, result)
+		}
+	}
+	return report, nil
+}
+
+func sendRequest(ctx context.Context, client Client, workload *Workload, operation Operation, consistency *v1.Consistency, rnd *rand.Rand) error {
+	user := userSubject(rnd.Intn(workload.Shape.Users))
+
+	switch operation {
+	case OperationCheck:
+		_, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+			Consistency: consistency,
+			Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: DocumentID(rnd.Intn(workload.Shape.Documents))},
+			Permission:  "view",
+			Subject:     user,
+		})
+		return err
+
+	case OperationLookupResources:
+		stream, err := client.LookupResources(ctx, &v1.LookupResourcesRequest{
+			Consistency:        consistency,
+			ResourceObjectType: "document",
+			Permission:         "view",
+			Subject:            user,
+		})
+		if err != nil {
+			return err
+		}
+
+		for {
+			_, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown operation %q", operation)
+	}
+}
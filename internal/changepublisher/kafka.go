@@ -0,0 +1,117 @@
+package changepublisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// maxKafkaMessageBytes is the maximum size of the message read to find the last published
+// revision.
+const maxKafkaMessageBytes = 10 << 20
+
+// NewKafkaSink returns a sink publishing messages to the given Kafka topic. All messages
+// share the same key, so they are written to a single partition and consumed in order.
+func NewKafkaSink(brokers []string, topic string) Sink {
+	return &kafkaSink{
+		brokers: brokers,
+		topic:   topic,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+		},
+	}
+}
+
+type kafkaSink struct {
+	brokers []string
+	topic   string
+	writer  *kafka.Writer
+}
+
+func (ks *kafkaSink) Publish(ctx context.Context, messages []Message) error {
+	kafkaMessages := make([]kafka.Message, 0, len(messages))
+	for _, message := range messages {
+		kafkaMessages = append(kafkaMessages, kafka.Message{
+			Key:     []byte(message.Key),
+			Value:   message.Value,
+			Headers: []kafka.Header{{Key: RevisionHeader, Value: []byte(message.Revision)}},
+		})
+	}
+	return ks.writer.WriteMessages(ctx, kafkaMessages...)
+}
+
+func (ks *kafkaSink) LastRevision(ctx context.Context, key string) (string, error) {
+	var errs []error
+	for _, broker := range ks.brokers {
+		revision, err := ks.lastRevisionFromBroker(ctx, broker, key)
+		if err == nil {
+			return revision, nil
+		}
+		errs = append(errs, err)
+	}
+	return "", fmt.Errorf("failed to read last message from Kafka: %w", errors.Join(errs...))
+}
+
+func (ks *kafkaSink) lastRevisionFromBroker(ctx context.Context, broker string, key string) (string, error) {
+	var dialer kafka.Dialer
+	partitions, err := dialer.LookupPartitions(ctx, "tcp", broker, ks.topic)
+	if errors.Is(err, kafka.UnknownTopicOrPartition) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	// The partition is chosen the way the writer chooses it for messages with the key.
+	partitionIDs := make([]int, 0, len(partitions))
+	for i := range partitions {
+		partitionIDs = append(partitionIDs, i)
+	}
+	partition := ks.writer.Balancer.Balance(kafka.Message{Key: []byte(key)}, partitionIDs...)
+
+	conn, err := dialer.DialLeader(ctx, "tcp", broker, ks.topic, partition)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return "", err
+		}
+	}
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return "", err
+	}
+	if last <= first {
+		return "", nil
+	}
+
+	if _, err := conn.Seek(last-1, kafka.SeekAbsolute); err != nil {
+		return "", err
+	}
+
+	message, err := conn.ReadMessage(maxKafkaMessageBytes)
+	if err != nil {
+		return "", err
+	}
+
+	for _, header := range message.Headers {
+		if header.Key == RevisionHeader {
+			return string(header.Value), nil
+		}
+	}
+	return "", fmt.Errorf("last message at offset %d has no %s header", message.Offset, RevisionHeader)
+}
+
+func (ks *kafkaSink) Close() error {
+	return ks.writer.Close()
+}
@@ -0,0 +1,73 @@
+package changepublisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NewNATSSink returns a sink publishing messages to the given JetStream subject. The revision
+// of each message is used as its message ID, so JetStream discards the duplicates published
+// after a restart within its deduplication window.
+func NewNATSSink(url, subject string) (Sink, error) {
+	conn, err := nats.Connect(url, nats.Name("spicedb-change-publisher"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to initialize JetStream: %w", err)
+	}
+
+	return &natsSink{conn: conn, js: js, subject: subject}, nil
+}
+
+type natsSink struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+}
+
+func (ns *natsSink) Publish(ctx context.Context, messages []Message) error {
+	// Messages are published one at a time, waiting for each acknowledgement, to preserve
+	// their order.
+	for _, message := range messages {
+		msg := nats.NewMsg(ns.subject)
+		msg.Data = message.Value
+		msg.Header.Set(nats.MsgIdHdr, message.Revision)
+		msg.Header.Set(RevisionHeader, message.Revision)
+
+		if _, err := ns.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+			return fmt.Errorf("failed to publish to NATS: %w", err)
+		}
+	}
+	return nil
+}
+
+func (ns *natsSink) LastRevision(ctx context.Context, _ string) (string, error) {
+	stream, err := ns.js.StreamNameBySubject(ns.subject, nats.Context(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to find the JetStream stream of the subject: %w", err)
+	}
+
+	message, err := ns.js.GetLastMsg(stream, ns.subject, nats.Context(ctx))
+	if errors.Is(err, nats.ErrMsgNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read last message from NATS: %w", err)
+	}
+
+	revision := message.Header.Get(RevisionHeader)
+	if revision == "" {
+		return "", fmt.Errorf("last message %d has no %s header", message.Sequence, RevisionHeader)
+	}
+	return revision, nil
+}
+
+func (ns *natsSink) Close() error {
+	return ns.conn.Drain()
+}
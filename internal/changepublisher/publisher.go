@@ -0,0 +1,240 @@
+// Package changepublisher implements a publisher that emits the relationship and schema changes
+// found in the datastore Watch stream to a message broker such as Kafka or NATS.
+//
+// Each revision is published as a single message, in revision order, holding the changes in
+// the same JSON format as webhooks.Event. Publishing resumes after the revision of the last
+// message found in the broker, so every change is published once even across restarts and
+// replicas taking over from each other, as long as a single replica publishes at a time.
+package changepublisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/cenkalti/backoff/v4"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/webhooks"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+const (
+	// RevisionHeader is the message header holding the ZedToken of the revision.
+	RevisionHeader = "spicedb-revision"
+
+	// DefaultBatchSize is the default maximum number of messages published at once.
+	DefaultBatchSize = 100
+
+	// rewatchDelay is the delay before watching again after the watch was disconnected.
+	rewatchDelay = 1 * time.Second
+)
+
+// Message is a message to publish.
+type Message struct {
+	// Key is the key of the message, used by brokers that partition messages.
+	Key string
+
+	// Revision is the ZedToken of the revision of the changes held by the message.
+	Revision string
+
+	// Value is the JSON encoded webhooks.Event of the revision.
+	Value []byte
+}
+
+// Sink publishes messages to a broker.
+type Sink interface {
+	// Publish publishes the messages in order, returning once they were all acknowledged.
+	Publish(ctx context.Context, messages []Message) error
+
+	// LastRevision returns the revision of the last message published with the key, or an empty
+	// string if there is none.
+	LastRevision(ctx context.Context, key string) (string, error)
+
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// Config configures the publisher.
+type Config struct {
+	// Sink is where messages are published. It is not closed by the publisher.
+	Sink Sink
+
+	// BatchSize is the maximum number of messages published at once.
+	BatchSize int
+}
+
+// Publisher publishes changes until the context is canceled.
+type Publisher func(ctx context.Context) error
+
+// DisabledPublisher is the publisher used when no broker is configured.
+func DisabledPublisher(_ context.Context) error {
+	return nil
+}
+
+// NewPublisher creates a publisher of the changes made to the datastore. If no message was
+// published to the sink yet, it starts with the changes made after it started.
+func NewPublisher(ds datastore.Datastore, config Config) (Publisher, error) {
+	if config.Sink == nil {
+		return nil, errors.New("a sink must be provided")
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	p := &publisher{ds: ds, config: config}
+	return p.run, nil
+}
+
+type publisher struct {
+	ds     datastore.Datastore
+	config Config
+}
+
+func (p *publisher) run(ctx context.Context) error {
+	key, err := p.ds.UniqueID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start change publisher: %w", err)
+	}
+
+	afterRevision, err := p.startRevision(ctx, key)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to start change publisher: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Stringer("after", afterRevision).
+		Msg("change publisher started")
+
+	for {
+		watchCtx, cancelWatch := context.WithCancel(ctx)
+		changes, errs := p.ds.Watch(watchCtx, afterRevision, datastore.WatchOptions{
+			Content: datastore.WatchRelationships | datastore.WatchSchema,
+		})
+
+		err := func() error {
+			for {
+				select {
+				case change, ok := <-changes:
+					if !ok {
+						return nil
+					}
+
+					// Gather the changes that are already available into a single batch.
+					batch := []*datastore.RevisionChanges{change}
+				gather:
+					for len(batch) < p.config.BatchSize {
+						select {
+						case change, ok := <-changes:
+							if !ok {
+								break gather
+							}
+							batch = append(batch, change)
+						default:
+							break gather
+						}
+					}
+
+					if err := p.publish(ctx, key, batch); err != nil {
+						return err
+					}
+					afterRevision = batch[len(batch)-1].Revision
+
+				case err := <-errs:
+					return err
+				}
+			}
+		}()
+		cancelWatch()
+
+		switch {
+		case ctx.Err() != nil:
+			return nil
+
+		case errors.As(err, &datastore.ErrWatchDisconnected{}):
+			// The watch fell behind, usually because the broker is slow or unavailable; resume
+			// from the last published revision.
+			log.Ctx(ctx).Warn().Err(err).Msg("change publisher watch disconnected; resuming")
+
+			select {
+			case <-time.After(rewatchDelay):
+			case <-ctx.Done():
+				return nil
+			}
+
+		case err != nil:
+			return fmt.Errorf("change publisher failed: %w", err)
+		}
+	}
+}
+
+// startRevision returns the revision after which to publish changes: that of the last message
+// published to the sink, retrying until the sink is available.
+func (p *publisher) startRevision(ctx context.Context, key string) (datastore.Revision, error) {
+	backoffInterval := backoff.NewExponentialBackOff()
+	backoffInterval.MaxElapsedTime = 0
+
+	var lastRevision string
+	err := backoff.RetryNotify(func() error {
+		var err error
+		lastRevision, err = p.config.Sink.LastRevision(ctx, key)
+		return err
+	}, backoff.WithContext(backoffInterval, ctx), func(err error, next time.Duration) {
+		log.Ctx(ctx).Warn().Err(err).Stringer("next", next).Msg("failed to read last published revision; retrying")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if lastRevision == "" {
+		return p.ds.HeadRevision(ctx)
+	}
+
+	revision, err := zedtoken.DecodeRevisionForDatastore(ctx, &v1.ZedToken{Token: lastRevision}, p.ds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode last published revision: %w", err)
+	}
+	return revision, nil
+}
+
+// publish publishes the changes, retrying until they are acknowledged.
+func (p *publisher) publish(ctx context.Context, key string, changes []*datastore.RevisionChanges) error {
+	messages := make([]Message, 0, len(changes))
+	for _, change := range changes {
+		event, err := webhooks.NewEvent(ctx, p.ds, change)
+		if err != nil {
+			return err
+		}
+		if event == nil {
+			continue
+		}
+
+		value, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode change event: %w", err)
+		}
+
+		messages = append(messages, Message{Key: key, Revision: event.Revision, Value: value})
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	backoffInterval := backoff.NewExponentialBackOff()
+	backoffInterval.MaxElapsedTime = 0
+
+	return backoff.RetryNotify(func() error {
+		return p.config.Sink.Publish(ctx, messages)
+	}, backoff.WithContext(backoffInterval, ctx), func(err error, next time.Duration) {
+		log.Ctx(ctx).Warn().Err(err).Stringer("next", next).Msg("failed to publish changes; retrying")
+	})
+}
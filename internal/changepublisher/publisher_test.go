@@ -0,0 +1,139 @@
+package changepublisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/webhooks"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type fakeSink struct {
+	sync.Mutex
+	failures  int
+	published []Message
+}
+
+func (fs *fakeSink) Publish(_ context.Context, messages []Message) error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	if fs.failures > 0 {
+		fs.failures--
+		return errors.New("broker unavailable")
+	}
+	fs.published = append(fs.published, messages...)
+	return nil
+}
+
+func (fs *fakeSink) LastRevision(_ context.Context, key string) (string, error) {
+	fs.Lock()
+	defer fs.Unlock()
+
+	for i := len(fs.published) - 1; i >= 0; i-- {
+		if fs.published[i].Key == key {
+			return fs.published[i].Revision, nil
+		}
+	}
+	return "", nil
+}
+
+func (fs *fakeSink) Close() error {
+	return nil
+}
+
+func (fs *fakeSink) events(t *testing.T) []webhooks.Event {
+	fs.Lock()
+	defer fs.Unlock()
+
+	events := make([]webhooks.Event, 0, len(fs.published))
+	for _, message := range fs.published {
+		var event webhooks.Event
+		require.NoError(t, json.Unmarshal(message.Value, &event))
+		require.Equal(t, event.Revision, message.Revision)
+		events = append(events, event)
+	}
+	return events
+}
+
+func startPublisher(t *testing.T, ds datastore.Datastore, sink Sink) func() {
+	publisher, err := NewPublisher(ds, Config{Sink: sink})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- publisher(ctx)
+	}()
+
+	// Wait for the publisher to start watching before making changes.
+	time.Sleep(50 * time.Millisecond)
+
+	return func() {
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+func writeRelationship(t *testing.T, ds datastore.Datastore, relationship string) {
+	_, err := common.WriteTuples(context.Background(), ds, core.RelationTupleUpdate_CREATE, tuple.MustParse(relationship))
+	require.NoError(t, err)
+}
+
+func TestPublisher(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	// Changes made before the first start are not published.
+	writeRelationship(t, ds, "document:zeroth#viewer@user:tom")
+
+	sink := &fakeSink{failures: 2}
+	stop := startPublisher(t, ds, sink)
+
+	_, err = ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, &core.NamespaceDefinition{Name: "document"})
+	})
+	require.NoError(t, err)
+	writeRelationship(t, ds, "document:first#viewer@user:tom")
+	writeRelationship(t, ds, "document:second#viewer@user:tom")
+
+	require.Eventually(t, func() bool {
+		return len(sink.events(t)) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	events := sink.events(t)
+	require.Equal(t, []string{"document"}, events[0].ChangedDefinitions)
+	require.Len(t, events[1].RelationshipUpdates, 1)
+	require.Contains(t, string(events[1].RelationshipUpdates[0]), `"objectId":"first"`)
+	require.Contains(t, string(events[2].RelationshipUpdates[0]), `"objectId":"second"`)
+
+	// Changes made while the publisher is stopped are published once it is restarted, after
+	// the last message found in the sink.
+	writeRelationship(t, ds, "document:third#viewer@user:tom")
+
+	stop = startPublisher(t, ds, sink)
+	require.Eventually(t, func() bool {
+		return len(sink.events(t)) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	events = sink.events(t)
+	require.Len(t, events, 4)
+	require.Contains(t, string(events[3].RelationshipUpdates[0]), `"objectId":"third"`)
+}
+
+func TestNewPublisherRequiresSink(t *testing.T) {
+	_, err := NewPublisher(nil, Config{})
+	require.Error(t, err)
+}
@@ -279,14 +279,11 @@ func (sqf SchemaQueryFilterer) MustFilterToResourceIDs(resourceIds []string) Sch
 // FilterWithResourceIDPrefix returns new SchemaQueryFilterer that is limited to resources whose ID
 // starts with the specified prefix.
 func (sqf SchemaQueryFilterer) FilterWithResourceIDPrefix(prefix string) (SchemaQueryFilterer, error) {
-	if strings.Contains(prefix, "%") {
-		return sqf, spiceerrors.MustBugf("prefix cannot contain the percent sign")
-	}
 	if prefix == "" {
 		return sqf, spiceerrors.MustBugf("prefix cannot be empty")
 	}
 
-	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Like{sqf.schema.colObjectID: prefix + "%"})
+	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Like{sqf.schema.colObjectID: LikePrefixPattern(prefix)})
 	sqf.tracerAttributes = append(sqf.tracerAttributes, ObjIDKey.String(prefix+"*"))
 
 	// NOTE: we do *not* record the use of the resource ID column here, because it is not used
@@ -294,6 +291,14 @@ func (sqf SchemaQueryFilterer) FilterWithResourceIDPrefix(prefix string) (Schema
 	return sqf, nil
 }
 
+// LikePrefixPattern returns a LIKE pattern matching the values starting with the prefix. The
+// wildcards of LIKE, and its default escape character, are escaped so they match literally.
+func LikePrefixPattern(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (sqf SchemaQueryFilterer) MustFilterWithResourceIDPrefix(prefix string) SchemaQueryFilterer {
 	updated, err := sqf.FilterWithResourceIDPrefix(prefix)
 	if err != nil {
@@ -448,6 +453,18 @@ func (sqf SchemaQueryFilterer) FilterWithSubjectsSelectors(selectors ...datastor
 			selectorClause = append(selectorClause, sq.Expr(inClause+")", args...))
 		}
 
+		if len(selector.OptionalSubjectIDPrefix) > 0 {
+			if len(selector.OptionalSubjectIds) > 0 {
+				return sqf, spiceerrors.MustBugf("cannot specify both subject IDs and a subject ID prefix")
+			}
+
+			selectorClause = append(selectorClause, sq.Like{sqf.schema.colUsersetObjectID: LikePrefixPattern(selector.OptionalSubjectIDPrefix)})
+			sqf.tracerAttributes = append(sqf.tracerAttributes, SubObjectIDKey.String(selector.OptionalSubjectIDPrefix+"*"))
+
+			// NOTE: as with resource ID prefixes, we do *not* record the use of the subject ID
+			// column here, because it is not used statically.
+		}
+
 		if !selector.RelationFilter.IsEmpty() {
 			if selector.RelationFilter.OnlyNonEllipsisRelations {
 				selectorClause = append(selectorClause, sq.NotEq{sqf.schema.colUsersetRelation: datastore.Ellipsis})
@@ -53,6 +53,15 @@ func TestSchemaQueryFilterer(t *testing.T) {
 			[]any{"someprefix%"},
 			map[string]int{}, // object_id is not statically used, so not present in the map
 		},
+		{
+			"resource ID prefix filter with LIKE wildcards",
+			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
+				return filterer.MustFilterWithResourceIDPrefix(`some_pre%fix\`)
+			},
+			"SELECT * WHERE object_id LIKE ?",
+			[]any{`some\_pre\%fix\\%`},
+			map[string]int{},
+		},
 		{
 			"resource IDs prefix filter",
 			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
@@ -201,6 +210,34 @@ func TestSchemaQueryFilterer(t *testing.T) {
 				"subject_object_id": 1,
 			},
 		},
+		{
+			"subjects filter with ID prefix",
+			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
+				return filterer.MustFilterWithSubjectsSelectors(datastore.SubjectsSelector{
+					OptionalSubjectType:     "somesubjectype",
+					OptionalSubjectIDPrefix: "someprefix",
+				})
+			},
+			"SELECT * WHERE ((subject_ns = ? AND subject_object_id LIKE ?))",
+			[]any{"somesubjectype", "someprefix%"},
+			map[string]int{
+				"subject_ns": 1,
+			},
+		},
+		{
+			"subjects filter with ID prefix with LIKE wildcards",
+			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
+				return filterer.MustFilterWithSubjectsSelectors(datastore.SubjectsSelector{
+					OptionalSubjectType:     "somesubjectype",
+					OptionalSubjectIDPrefix: "some_prefix",
+				})
+			},
+			"SELECT * WHERE ((subject_ns = ? AND subject_object_id LIKE ?))",
+			[]any{"somesubjectype", `some\_prefix%`},
+			map[string]int{
+				"subject_ns": 1,
+			},
+		},
 		{
 			"empty subjects filter",
 			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
@@ -75,6 +75,10 @@ func (p *ctxProxy) Statistics(ctx context.Context) (datastore.Stats, error) {
 	return p.delegate.Statistics(SeparateContextWithTracing(ctx))
 }
 
+func (p *ctxProxy) UniqueID(ctx context.Context) (string, error) {
+	return p.delegate.UniqueID(SeparateContextWithTracing(ctx))
+}
+
 func (p *ctxProxy) ReadyState(ctx context.Context) (datastore.ReadyState, error) {
 	return p.delegate.ReadyState(SeparateContextWithTracing(ctx))
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/IBM/pgxpoolprometheus"
@@ -148,9 +149,6 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 	var keyer overlapKeyer
 	switch config.overlapStrategy {
 	case overlapStrategyStatic:
-		if len(config.overlapKey) == 0 {
-			return nil, fmt.Errorf("static tx overlap strategy specified without an overlap key")
-		}
 		keyer = appendStaticKey(config.overlapKey)
 	case overlapStrategyPrefix:
 		keyer = prefixKeyer
@@ -196,6 +194,7 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 	ds.readPool, err = pool.NewRetryPool(ds.ctx, "read", readPoolConfig, healthChecker, config.maxRetries, config.connectRate)
 	if err != nil {
 		ds.cancel()
+		ds.writePool.Close()
 		return nil, common.RedactAndLogSensitiveConnString(ctx, errUnableToInstantiate, err, url)
 	}
 
@@ -205,6 +204,8 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 			"pool_usage": "write",
 		})); err != nil {
 			ds.cancel()
+			ds.writePool.Close()
+			ds.readPool.Close()
 			return nil, err
 		}
 
@@ -213,6 +214,8 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 			"pool_usage": "read",
 		})); err != nil {
 			ds.cancel()
+			ds.writePool.Close()
+			ds.readPool.Close()
 			return nil, err
 		}
 	}
@@ -245,12 +248,34 @@ func newCRDBDatastore(ctx context.Context, url string, options ...Option) (datas
 
 // NewCRDBDatastore initializes a SpiceDB datastore that uses a CockroachDB
 // database while leveraging its AOST functionality.
+//
+// Connecting to the database is retried for up to the ConnectRetryTimeout. If
+// it still fails and lazy connections are enabled, the datastore starts in a
+// not-ready state and keeps connecting in the background.
 func NewCRDBDatastore(ctx context.Context, url string, options ...Option) (datastore.Datastore, error) {
-	ds, err := newCRDBDatastore(ctx, url, options...)
+	// Invalid configuration is reported immediately, rather than retried.
+	config, err := generateConfig(options)
 	if err != nil {
-		return nil, err
+		return nil, common.RedactAndLogSensitiveConnString(ctx, errUnableToInstantiate, err, url)
+	}
+	if _, err := pgxpool.ParseConfig(url); err != nil {
+		return nil, common.RedactAndLogSensitiveConnString(ctx, errUnableToInstantiate, err, url)
+	}
+
+	connect := func(ctx context.Context) (datastore.Datastore, error) {
+		ds, err := newCRDBDatastore(ctx, url, options...)
+		if err != nil {
+			return nil, err
+		}
+		return datastoreinternal.NewSeparatingContextDatastoreProxy(ds), nil
+	}
+
+	ds, err := datastoreinternal.ConnectWithRetries(ctx, connect, config.connectRetryTimeout)
+	if err != nil && config.lazyConnect {
+		log.Ctx(ctx).Warn().Err(err).Msg("unable to connect to cockroach; starting as not ready and connecting in the background")
+		return datastoreinternal.NewLazyDatastore(connect), nil
 	}
-	return datastoreinternal.NewSeparatingContextDatastoreProxy(ds), nil
+	return ds, err
 }
 
 type crdbDatastore struct {
@@ -269,6 +294,7 @@ type crdbDatastore struct {
 	transactionNowQuery  string
 
 	featureGroup singleflight.Group[string, *datastore.Features]
+	uniqueID     atomic.Pointer[string]
 
 	pruneGroup *errgroup.Group
 	ctx        context.Context
@@ -10,6 +10,8 @@ import (
 type crdbOptions struct {
 	readPoolOpts, writePoolOpts pgxcommon.PoolOptions
 	connectRate                 time.Duration
+	connectRetryTimeout         time.Duration
+	lazyConnect                 bool
 
 	watchBufferLength           uint16
 	watchBufferWriteTimeout     time.Duration
@@ -83,6 +85,10 @@ func generateConfig(options []Option) (crdbOptions, error) {
 		)
 	}
 
+	if computed.overlapStrategy == overlapStrategyStatic && len(computed.overlapKey) == 0 {
+		return computed, fmt.Errorf("static tx overlap strategy specified without an overlap key")
+	}
+
 	return computed, nil
 }
 
@@ -263,6 +269,23 @@ func ConnectRate(rate time.Duration) Option {
 	return func(po *crdbOptions) { po.connectRate = rate }
 }
 
+// ConnectRetryTimeout is how long the datastore retries connecting to the database when it is
+// created, with exponential backoff, before failing.
+//
+// This value defaults to zero, which makes a single attempt.
+func ConnectRetryTimeout(timeout time.Duration) Option {
+	return func(po *crdbOptions) { po.connectRetryTimeout = timeout }
+}
+
+// WithLazyConnect marks whether the datastore, when it cannot connect to the database within the
+// ConnectRetryTimeout, starts in a not-ready state and keeps connecting in the background,
+// instead of failing.
+//
+// Lazy connections are disabled by default.
+func WithLazyConnect(lazyConnect bool) Option {
+	return func(po *crdbOptions) { po.lazyConnect = lazyConnect }
+}
+
 // MaxRetries is the maximum number of times a retriable transaction will be
 // client-side retried.
 // Default: 5
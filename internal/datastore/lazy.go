@@ -0,0 +1,253 @@
+package datastore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// ConnectFunc connects to the database of a datastore and returns the datastore.
+type ConnectFunc func(ctx context.Context) (datastore.Datastore, error)
+
+// ConnectWithRetries calls connect until it succeeds, retrying with exponential backoff for up to
+// the given timeout. A timeout of zero makes a single attempt.
+func ConnectWithRetries(ctx context.Context, connect ConnectFunc, timeout time.Duration) (datastore.Datastore, error) {
+	if timeout <= 0 {
+		return connect(ctx)
+	}
+
+	backoffInterval := backoff.NewExponentialBackOff()
+	backoffInterval.MaxElapsedTime = timeout
+	return connectWithBackoff(ctx, connect, backoffInterval, nil)
+}
+
+func connectWithBackoff(ctx context.Context, connect ConnectFunc, b backoff.BackOff, onError func(error)) (datastore.Datastore, error) {
+	var ds datastore.Datastore
+	err := backoff.RetryNotify(func() error {
+		var err error
+		ds, err = connect(ctx)
+		return err
+	}, backoff.WithContext(b, ctx), func(err error, next time.Duration) {
+		log.Ctx(ctx).Warn().Err(err).Stringer("retry_in", next).Msg("unable to connect to datastore")
+		if onError != nil {
+			onError(err)
+		}
+	})
+	return ds, err
+}
+
+// NewLazyDatastore returns a datastore which connects with the given function in the background,
+// retrying with exponential backoff until it succeeds or the datastore is closed.
+//
+// Until it has connected, the datastore reports that it is not ready and its operations fail
+// with datastore.ErrNotConnected, except for Features, which waits for the connection so that
+// it reports the features of the connected datastore.
+func NewLazyDatastore(connect ConnectFunc) datastore.Datastore {
+	ctx, cancel := context.WithCancel(context.Background())
+	lds := &lazyDatastore{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(lds.done)
+
+		backoffInterval := backoff.NewExponentialBackOff()
+		backoffInterval.MaxElapsedTime = 0
+
+		ds, err := connectWithBackoff(ctx, connect, backoffInterval, lds.setLastError)
+		if err != nil {
+			return
+		}
+
+		log.Ctx(ctx).Info().Msg("datastore connected")
+		lds.Lock()
+		defer lds.Unlock()
+		lds.delegate = ds
+	}()
+
+	return lds
+}
+
+type lazyDatastore struct {
+	sync.RWMutex
+	delegate datastore.Datastore
+	lastErr  error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (lds *lazyDatastore) setLastError(err error) {
+	lds.Lock()
+	defer lds.Unlock()
+	lds.lastErr = err
+}
+
+// connected returns the connected datastore, or a datastore.ErrNotConnected if there is none yet.
+func (lds *lazyDatastore) connected() (datastore.Datastore, error) {
+	lds.RLock()
+	defer lds.RUnlock()
+	if lds.delegate == nil {
+		return nil, datastore.NewNotConnectedErr(lds.lastErr)
+	}
+	return lds.delegate, nil
+}
+
+func (lds *lazyDatastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	ds, err := lds.connected()
+	if err != nil {
+		return notConnectedReader{err}
+	}
+	return ds.SnapshotReader(rev)
+}
+
+func (lds *lazyDatastore) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	ds, err := lds.connected()
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	return ds.ReadWriteTx(ctx, f, opts...)
+}
+
+func (lds *lazyDatastore) OptimizedRevision(ctx context.Context) (datastore.Revision, error) {
+	ds, err := lds.connected()
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	return ds.OptimizedRevision(ctx)
+}
+
+func (lds *lazyDatastore) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	ds, err := lds.connected()
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	return ds.HeadRevision(ctx)
+}
+
+func (lds *lazyDatastore) CheckRevision(ctx context.Context, revision datastore.Revision) error {
+	ds, err := lds.connected()
+	if err != nil {
+		return err
+	}
+	return ds.CheckRevision(ctx, revision)
+}
+
+func (lds *lazyDatastore) RevisionFromString(serialized string) (datastore.Revision, error) {
+	ds, err := lds.connected()
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+	return ds.RevisionFromString(serialized)
+}
+
+func (lds *lazyDatastore) Watch(ctx context.Context, afterRevision datastore.Revision, options datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
+	ds, err := lds.connected()
+	if err != nil {
+		errs := make(chan error, 1)
+		errs <- err
+		return nil, errs
+	}
+	return ds.Watch(ctx, afterRevision, options)
+}
+
+func (lds *lazyDatastore) ReadyState(ctx context.Context) (datastore.ReadyState, error) {
+	ds, err := lds.connected()
+	if err != nil {
+		return datastore.ReadyState{Message: err.Error(), IsReady: false}, nil
+	}
+	return ds.ReadyState(ctx)
+}
+
+func (lds *lazyDatastore) Features(ctx context.Context) (*datastore.Features, error) {
+	select {
+	case <-lds.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	ds, err := lds.connected()
+	if err != nil {
+		return nil, err
+	}
+	return ds.Features(ctx)
+}
+
+func (lds *lazyDatastore) Statistics(ctx context.Context) (datastore.Stats, error) {
+	ds, err := lds.connected()
+	if err != nil {
+		return datastore.Stats{}, err
+	}
+	return ds.Statistics(ctx)
+}
+
+func (lds *lazyDatastore) UniqueID(ctx context.Context) (string, error) {
+	ds, err := lds.connected()
+	if err != nil {
+		return "", err
+	}
+	return ds.UniqueID(ctx)
+}
+
+func (lds *lazyDatastore) Close() error {
+	lds.cancel()
+	<-lds.done
+
+	ds, err := lds.connected()
+	if err != nil {
+		return nil
+	}
+	return ds.Close()
+}
+
+func (lds *lazyDatastore) Unwrap() datastore.Datastore {
+	ds, _ := lds.connected()
+	return ds
+}
+
+type notConnectedReader struct{ err error }
+
+func (r notConnectedReader) ReadCaveatByName(context.Context, string) (*core.CaveatDefinition, datastore.Revision, error) {
+	return nil, datastore.NoRevision, r.err
+}
+
+func (r notConnectedReader) ListAllCaveats(context.Context) ([]datastore.RevisionedCaveat, error) {
+	return nil, r.err
+}
+
+func (r notConnectedReader) LookupCaveatsWithNames(context.Context, []string) ([]datastore.RevisionedCaveat, error) {
+	return nil, r.err
+}
+
+func (r notConnectedReader) ListAllNamespaces(context.Context) ([]datastore.RevisionedNamespace, error) {
+	return nil, r.err
+}
+
+func (r notConnectedReader) LookupNamespacesWithNames(context.Context, []string) ([]datastore.RevisionedNamespace, error) {
+	return nil, r.err
+}
+
+func (r notConnectedReader) ReadNamespaceByName(context.Context, string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	return nil, datastore.NoRevision, r.err
+}
+
+func (r notConnectedReader) QueryRelationships(context.Context, datastore.RelationshipsFilter, ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return nil, r.err
+}
+
+func (r notConnectedReader) ReverseQueryRelationships(context.Context, datastore.SubjectsFilter, ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return nil, r.err
+}
+
+var (
+	_ datastore.Datastore = (*lazyDatastore)(nil)
+	_ datastore.Reader    = notConnectedReader{}
+)
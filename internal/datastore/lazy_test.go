@@ -0,0 +1,99 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var errUnavailable = errors.New("connection refused")
+
+// failingConnect returns a ConnectFunc which fails the given number of times before connecting
+// to a memdb datastore.
+func failingConnect(failures int32) (ConnectFunc, *atomic.Int32) {
+	var attempts atomic.Int32
+	return func(ctx context.Context) (datastore.Datastore, error) {
+		if attempts.Add(1) <= failures {
+			return nil, errUnavailable
+		}
+		return memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	}, &attempts
+}
+
+func TestConnectWithRetries(t *testing.T) {
+	connect, attempts := failingConnect(1)
+	_, err := ConnectWithRetries(context.Background(), connect, 0)
+	require.ErrorIs(t, err, errUnavailable)
+	require.Equal(t, int32(1), attempts.Load())
+
+	ds, err := ConnectWithRetries(context.Background(), connect, 10*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, ds.Close())
+	require.Equal(t, int32(2), attempts.Load())
+}
+
+func TestLazyDatastore(t *testing.T) {
+	ctx := context.Background()
+	connect, attempts := failingConnect(1)
+	ds := NewLazyDatastore(connect)
+
+	require.Eventually(t, func() bool { return attempts.Load() >= 1 }, time.Second, time.Millisecond)
+
+	state, err := ds.ReadyState(ctx)
+	require.NoError(t, err)
+	require.False(t, state.IsReady)
+
+	_, err = ds.HeadRevision(ctx)
+	require.ErrorAs(t, err, &datastore.ErrNotConnected{})
+
+	_, err = ds.SnapshotReader(datastore.NoRevision).ListAllNamespaces(ctx)
+	require.ErrorAs(t, err, &datastore.ErrNotConnected{})
+
+	// Features waits for the connection, and reports the features of the connected datastore.
+	features, err := ds.Features(ctx)
+	require.NoError(t, err)
+	require.True(t, features.Watch.Enabled)
+
+	state, err = ds.ReadyState(ctx)
+	require.NoError(t, err)
+	require.True(t, state.IsReady)
+
+	rev, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	_, err = ds.SnapshotReader(rev).ListAllNamespaces(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ds.Close())
+}
+
+func TestLazyDatastoreClosedBeforeConnecting(t *testing.T) {
+	connect, _ := failingConnect(1000)
+	ds := NewLazyDatastore(connect)
+	require.NoError(t, ds.Close())
+
+	state, err := ds.ReadyState(context.Background())
+	require.NoError(t, err)
+	require.False(t, state.IsReady)
+
+	_, err = ds.Features(context.Background())
+	require.ErrorAs(t, err, &datastore.ErrNotConnected{})
+}
+
+func TestLazyDatastoreFeaturesCanceled(t *testing.T) {
+	connect, _ := failingConnect(1000)
+	ds := NewLazyDatastore(connect)
+	defer ds.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := ds.Features(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,121 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	defaultGCInterval = 3 * time.Minute
+	gcTimeout         = 1 * time.Minute
+)
+
+var _ common.GarbageCollector = (*memdbDatastore)(nil)
+
+// startGarbageCollector starts the background worker which prunes snapshots and changelog
+// entries that have fallen outside of the GC window.
+func (mdb *memdbDatastore) startGarbageCollector(gcWindow time.Duration) {
+	interval := min(defaultGCInterval, gcWindow)
+
+	gcCtx, cancelGC := context.WithCancel(context.Background())
+	gcDone := make(chan struct{})
+
+	mdb.cancelGC = cancelGC
+	mdb.gcDone = gcDone
+
+	go func() {
+		defer close(gcDone)
+		_ = common.StartGarbageCollector(gcCtx, mdb, interval, gcWindow, gcTimeout)
+	}()
+}
+
+// stopGarbageCollector stops the background worker, if any, and waits for it to complete. Must
+// *not* be called with the datastore lock held.
+func (mdb *memdbDatastore) stopGarbageCollector() {
+	if mdb.cancelGC == nil {
+		return
+	}
+
+	mdb.cancelGC()
+	<-mdb.gcDone
+}
+
+func (mdb *memdbDatastore) HasGCRun() bool {
+	return mdb.gcHasRun.Load()
+}
+
+func (mdb *memdbDatastore) MarkGCCompleted() {
+	mdb.gcHasRun.Store(true)
+}
+
+func (mdb *memdbDatastore) ResetGCCompleted() {
+	mdb.gcHasRun.Store(false)
+}
+
+func (mdb *memdbDatastore) Now(_ context.Context) (time.Time, error) {
+	return time.Now().UTC(), nil
+}
+
+func (mdb *memdbDatastore) TxIDBefore(_ context.Context, before time.Time) (datastore.Revision, error) {
+	return revisions.NewForTime(before), nil
+}
+
+// DeleteBeforeTx removes all snapshots and changelog entries with a revision before the given
+// revision. The most recent snapshot is always retained, as it represents the head revision.
+func (mdb *memdbDatastore) DeleteBeforeTx(_ context.Context, txID datastore.Revision) (common.DeletionCounts, error) {
+	mdb.Lock()
+	defer mdb.Unlock()
+
+	if mdb.db == nil {
+		return common.DeletionCounts{}, fmt.Errorf("datastore has been closed")
+	}
+
+	if mdb.activeWriteTxn != nil {
+		return common.DeletionCounts{}, ErrSerialization
+	}
+
+	watermark, ok := txID.(revisions.TimestampRevision)
+	if !ok {
+		return common.DeletionCounts{}, fmt.Errorf("expected timestamp revision, found %T", txID)
+	}
+
+	// Prune the snapshots, which in turn allows for the relationship versions they reference
+	// to be reclaimed. A snapshot is only removed if a newer snapshot also falls before the
+	// watermark, ensuring the state as of the watermark remains readable.
+	var removedSnapshots int
+	for removedSnapshots < len(mdb.revisions)-1 && mdb.revisions[removedSnapshots+1].revision.LessThan(watermark) {
+		removedSnapshots++
+	}
+	clear(mdb.revisions[:removedSnapshots])
+	mdb.revisions = mdb.revisions[removedSnapshots:]
+
+	// Prune the changelog.
+	tx := mdb.db.Txn(true)
+	defer tx.Abort()
+
+	it, err := tx.ReverseLowerBound(tableChangelog, indexRevision, watermark.TimestampNanoSec()-1)
+	if err != nil {
+		return common.DeletionCounts{}, fmt.Errorf("error loading changelog: %w", err)
+	}
+
+	var toDelete []*changelog
+	for changeRaw := it.Next(); changeRaw != nil; changeRaw = it.Next() {
+		toDelete = append(toDelete, changeRaw.(*changelog))
+	}
+
+	for _, change := range toDelete {
+		if err := tx.Delete(tableChangelog, change); err != nil {
+			return common.DeletionCounts{}, fmt.Errorf("error deleting changelog entry: %w", err)
+		}
+	}
+	tx.Commit()
+
+	return common.DeletionCounts{
+		Transactions: int64(removedSnapshots + len(toDelete)),
+	}, nil
+}
@@ -0,0 +1,134 @@
+package memdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/datastore"
+	corev1 "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestGarbageCollection(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ds, err := NewMemdbDatastore(0, 1*time.Millisecond, 1*time.Hour)
+	require.NoError(err)
+	defer ds.Close()
+
+	mdb := ds.(*memdbDatastore)
+
+	var lastRevision datastore.Revision
+	for _, tpl := range []string{
+		"document:first#viewer@user:tom",
+		"document:second#viewer@user:tom",
+		"document:third#viewer@user:tom",
+	} {
+		lastRevision, err = common.WriteTuples(ctx, ds, corev1.RelationTupleUpdate_CREATE, tuple.MustParse(tpl))
+		require.NoError(err)
+	}
+
+	require.Len(mdb.revisions, 4)
+	require.Equal(3, changelogLength(t, mdb))
+
+	// Collect everything before now; only the head snapshot should remain.
+	watermark, err := mdb.TxIDBefore(ctx, time.Now())
+	require.NoError(err)
+
+	counts, err := mdb.DeleteBeforeTx(ctx, watermark)
+	require.NoError(err)
+	require.Equal(int64(3+3), counts.Transactions)
+	require.Len(mdb.revisions, 1)
+	require.Equal(0, changelogLength(t, mdb))
+
+	// The head revision must still be readable.
+	it, err := ds.SnapshotReader(lastRevision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		OptionalResourceType: "document",
+	})
+	require.NoError(err)
+	defer it.Close()
+
+	count := 0
+	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+		count++
+	}
+	require.NoError(it.Err())
+	require.Equal(3, count)
+
+	// Collecting again should be a no-op.
+	counts, err = mdb.DeleteBeforeTx(ctx, watermark)
+	require.NoError(err)
+	require.Equal(int64(0), counts.Transactions)
+}
+
+func TestGarbageCollectionRetainsWindow(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ds, err := NewMemdbDatastore(0, 1*time.Millisecond, 1*time.Hour)
+	require.NoError(err)
+	defer ds.Close()
+
+	mdb := ds.(*memdbDatastore)
+
+	_, err = common.WriteTuples(ctx, ds, corev1.RelationTupleUpdate_CREATE, tuple.MustParse("document:first#viewer@user:tom"))
+	require.NoError(err)
+
+	watermark, err := mdb.TxIDBefore(ctx, time.Now())
+	require.NoError(err)
+
+	_, err = common.WriteTuples(ctx, ds, corev1.RelationTupleUpdate_CREATE, tuple.MustParse("document:second#viewer@user:tom"))
+	require.NoError(err)
+
+	// The snapshot in effect as of the watermark, and the one written after it, must remain.
+	counts, err := mdb.DeleteBeforeTx(ctx, watermark)
+	require.NoError(err)
+	require.Equal(int64(1+1), counts.Transactions)
+	require.Len(mdb.revisions, 2)
+	require.Equal(1, changelogLength(t, mdb))
+}
+
+func TestGarbageCollectorOnlyStartedWhenRequested(t *testing.T) {
+	ds, err := NewMemdbDatastore(0, 1*time.Millisecond, 1*time.Second)
+	require.NoError(t, err)
+	defer ds.Close()
+
+	require.Nil(t, ds.(*memdbDatastore).gcDone)
+}
+
+func TestGarbageCollectorStopsOnClose(t *testing.T) {
+	ds, err := NewMemdbDatastoreWithGC(0, 1*time.Millisecond, 1*time.Second)
+	require.NoError(t, err)
+
+	mdb := ds.(*memdbDatastore)
+	require.NotNil(t, mdb.gcDone)
+	require.NoError(t, ds.Close())
+
+	select {
+	case <-mdb.gcDone:
+	default:
+		require.Fail(t, "expected garbage collector to be stopped")
+	}
+}
+
+func changelogLength(t *testing.T, mdb *memdbDatastore) int {
+	mdb.RLock()
+	defer mdb.RUnlock()
+
+	txn := mdb.db.Txn(false)
+	defer txn.Abort()
+
+	it, err := txn.Get(tableChangelog, indexRevision)
+	require.NoError(t, err)
+
+	count := 0
+	for change := it.Next(); change != nil; change = it.Next() {
+		count++
+	}
+	return count
+}
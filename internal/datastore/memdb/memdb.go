@@ -7,6 +7,7 @@ import (
 	"math"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
@@ -41,6 +42,33 @@ func NewMemdbDatastore(
 	revisionQuantization,
 	gcWindow time.Duration,
 ) (datastore.Datastore, error) {
+	return newMemdbDatastore(watchBufferLength, revisionQuantization, gcWindow)
+}
+
+// NewMemdbDatastoreWithGC creates a new memdb datastore like NewMemdbDatastore, which also runs
+// a background worker garbage collecting the revisions that have fallen outside of the GC window,
+// unless it is DisableGC. The worker is stopped by Close, which must be called.
+func NewMemdbDatastoreWithGC(
+	watchBufferLength uint16,
+	revisionQuantization,
+	gcWindow time.Duration,
+) (datastore.Datastore, error) {
+	mdb, err := newMemdbDatastore(watchBufferLength, revisionQuantization, gcWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	if gcWindow != DisableGC {
+		mdb.startGarbageCollector(gcWindow)
+	}
+	return mdb, nil
+}
+
+func newMemdbDatastore(
+	watchBufferLength uint16,
+	revisionQuantization,
+	gcWindow time.Duration,
+) (*memdbDatastore, error) {
 	if revisionQuantization > gcWindow {
 		return nil, errors.New("gc window must be larger than quantization interval")
 	}
@@ -59,7 +87,7 @@ func NewMemdbDatastore(
 	}
 
 	uniqueID := uuid.NewString()
-	return &memdbDatastore{
+	mdb := &memdbDatastore{
 		CommonDecoder: revisions.CommonDecoder{
 			Kind: revisions.Timestamp,
 		},
@@ -76,7 +104,9 @@ func NewMemdbDatastore(
 		watchBufferLength:       watchBufferLength,
 		watchBufferWriteTimeout: 100 * time.Millisecond,
 		uniqueID:                uniqueID,
-	}, nil
+	}
+
+	return mdb, nil
 }
 
 type memdbDatastore struct {
@@ -92,6 +122,10 @@ type memdbDatastore struct {
 	watchBufferLength       uint16
 	watchBufferWriteTimeout time.Duration
 	uniqueID                string
+
+	cancelGC context.CancelFunc
+	gcDone   chan struct{}
+	gcHasRun atomic.Bool
 }
 
 type snapshot struct {
@@ -301,6 +335,8 @@ func (mdb *memdbDatastore) Features(_ context.Context) (*datastore.Features, err
 }
 
 func (mdb *memdbDatastore) Close() error {
+	mdb.stopGarbageCollector()
+
 	mdb.Lock()
 	defer mdb.Unlock()
 
@@ -22,7 +22,7 @@ import (
 type memDBTest struct{}
 
 func (mdbt memDBTest) New(revisionQuantization, _, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
-	return NewMemdbDatastore(watchBufferLength, revisionQuantization, gcWindow)
+	return NewMemdbDatastoreWithGC(watchBufferLength, revisionQuantization, gcWindow)
 }
 
 func TestMemdbDatastore(t *testing.T) {
@@ -113,3 +113,30 @@ func TestConcurrentWriteRelsError(t *testing.T) {
 	require.Error(werr)
 	require.ErrorContains(werr, "serialization max retries exceeded")
 }
+
+func TestQueryRelationshipsRejectsIDsWithPrefix(t *testing.T) {
+	require := require.New(t)
+
+	ds, err := NewMemdbDatastore(0, 0, DisableGC)
+	require.NoError(err)
+	defer ds.Close()
+
+	ctx := context.Background()
+	headRev, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+	reader := ds.SnapshotReader(headRev)
+
+	// These combinations are also rejected by the SQL datastores.
+	_, err = reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		OptionalResourceIds:      []string{"first"},
+		OptionalResourceIDPrefix: "f",
+	})
+	require.ErrorContains(err, "cannot filter by both resource IDs and ID prefix")
+
+	_, err = reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+		SubjectType:             "user",
+		OptionalSubjectIds:      []string{"tom"},
+		OptionalSubjectIDPrefix: "t",
+	})
+	require.ErrorContains(err, "cannot specify both subject IDs and a subject ID prefix")
+}
@@ -35,6 +35,10 @@ func (r *memdbReader) QueryRelationships(
 		return nil, r.initErr
 	}
 
+	if err := validateFilters(filter.OptionalResourceIds, filter.OptionalResourceIDPrefix, filter.OptionalSubjectsSelectors); err != nil {
+		return nil, err
+	}
+
 	r.mustLock()
 	defer r.Unlock()
 
@@ -97,6 +101,10 @@ func (r *memdbReader) ReverseQueryRelationships(
 		return nil, r.initErr
 	}
 
+	if err := validateFilters(nil, "", []datastore.SubjectsSelector{subjectsFilter.AsSelector()}); err != nil {
+		return nil, err
+	}
+
 	r.mustLock()
 	defer r.Unlock()
 
@@ -133,7 +141,19 @@ func (r *memdbReader) ReverseQueryRelationships(
 	)
 	filteredIterator := memdb.NewFilterIterator(iterator, matchingRelationshipsFilterFunc)
 
-	return newMemdbTupleIterator(filteredIterator, queryOpts.LimitForReverse, queryOpts.SortForReverse), nil
+	switch queryOpts.SortForReverse {
+	case options.Unsorted:
+		fallthrough
+
+	case options.ByResource:
+		return newMemdbTupleIterator(filteredIterator, queryOpts.LimitForReverse, queryOpts.SortForReverse), nil
+
+	case options.BySubject:
+		return newSubjectSortedIterator(filteredIterator, queryOpts.LimitForReverse)
+
+	default:
+		return nil, spiceerrors.MustBugf("unsupported sort order: %v", queryOpts.SortForReverse)
+	}
 }
 
 // ReadNamespace reads a namespace definition and version and returns it, and the revision at
@@ -293,6 +313,20 @@ func iteratorForFilter(txn *memdb.Txn, filter datastore.RelationshipsFilter) (me
 	return iter, err
 }
 
+// validateFilters rejects the combinations of filters which are also rejected by the SQL datastores.
+func validateFilters(optionalResourceIds []string, optionalResourceIDPrefix string, optionalSubjectsSelectors []datastore.SubjectsSelector) error {
+	if len(optionalResourceIds) > 0 && optionalResourceIDPrefix != "" {
+		return fmt.Errorf("cannot filter by both resource IDs and ID prefix")
+	}
+
+	for _, selector := range optionalSubjectsSelectors {
+		if len(selector.OptionalSubjectIds) > 0 && selector.OptionalSubjectIDPrefix != "" {
+			return fmt.Errorf("cannot specify both subject IDs and a subject ID prefix")
+		}
+	}
+	return nil
+}
+
 func filterFuncForFilters(
 	optionalResourceType string,
 	optionalResourceIds []string,
@@ -324,6 +358,8 @@ func filterFuncForFilters(
 				return false
 			case len(selector.OptionalSubjectIds) > 0 && !slices.Contains(selector.OptionalSubjectIds, tuple.subjectObjectID):
 				return false
+			case selector.OptionalSubjectIDPrefix != "" && !strings.HasPrefix(tuple.subjectObjectID, selector.OptionalSubjectIDPrefix):
+				return false
 			}
 
 			if selector.RelationFilter.OnlyNonEllipsisRelations {
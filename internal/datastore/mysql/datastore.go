@@ -445,6 +445,8 @@ type Datastore struct {
 	cancelGc context.CancelFunc
 	gcHasRun atomic.Bool
 
+	uniqueID atomic.Pointer[string]
+
 	createTxn     string
 	createBaseTxn string
 
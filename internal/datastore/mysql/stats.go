@@ -28,7 +28,7 @@ func (mds *Datastore) Statistics(ctx context.Context) (datastore.Stats, error) {
 		}
 	}
 
-	uniqueID, err := mds.getUniqueID(ctx)
+	uniqueID, err := mds.UniqueID(ctx)
 	if err != nil {
 		return datastore.Stats{}, err
 	}
@@ -81,6 +81,20 @@ func (mds *Datastore) Statistics(ctx context.Context) (datastore.Stats, error) {
 	}, nil
 }
 
+func (mds *Datastore) UniqueID(ctx context.Context) (string, error) {
+	if cached := mds.uniqueID.Load(); cached != nil {
+		return *cached, nil
+	}
+
+	uniqueID, err := mds.getUniqueID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mds.uniqueID.Store(&uniqueID)
+	return uniqueID, nil
+}
+
 func (mds *Datastore) getUniqueID(ctx context.Context) (string, error) {
 	sql, args, err := sb.Select(metadataUniqueIDColumn).From(mds.driver.Metadata()).ToSql()
 	if err != nil {
@@ -304,6 +304,8 @@ type pgDatastore struct {
 	gcCtx    context.Context
 	cancelGc context.CancelFunc
 	gcHasRun atomic.Bool
+
+	uniqueID atomic.Pointer[string]
 }
 
 func (pgd *pgDatastore) SnapshotReader(revRaw datastore.Revision) datastore.Reader {
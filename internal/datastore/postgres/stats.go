@@ -28,6 +28,20 @@ var (
 				Where(sq.Eq{colRelname: tableTuple})
 )
 
+func (pgd *pgDatastore) UniqueID(ctx context.Context) (string, error) {
+	if cached := pgd.uniqueID.Load(); cached != nil {
+		return *cached, nil
+	}
+
+	uniqueID, err := pgd.datastoreUniqueID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to query unique ID: %w", err)
+	}
+
+	pgd.uniqueID.Store(&uniqueID)
+	return uniqueID, nil
+}
+
 func (pgd *pgDatastore) datastoreUniqueID(ctx context.Context) (string, error) {
 	idSQL, idArgs, err := queryUniqueID.ToSql()
 	if err != nil {
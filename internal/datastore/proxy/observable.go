@@ -127,6 +127,13 @@ func (p *observableProxy) Statistics(ctx context.Context) (datastore.Stats, erro
 	return p.delegate.Statistics(ctx)
 }
 
+func (p *observableProxy) UniqueID(ctx context.Context) (string, error) {
+	ctx, closer := observe(ctx, "UniqueID")
+	defer closer()
+
+	return p.delegate.UniqueID(ctx)
+}
+
 func (p *observableProxy) Unwrap() datastore.Datastore {
 	return p.delegate
 }
@@ -0,0 +1,345 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type overlayDatastore struct {
+	datastore.Datastore
+	revision        datastore.Revision
+	overlay         datastore.Datastore
+	overlayRevision datastore.Revision
+	hidden          map[string]struct{}
+}
+
+// NewOverlayDatastore creates a read-only proxy presenting the schema and relationships of the
+// overlay datastore at the overlay revision on top of the relationships of a downstream delegate
+// datastore at the given revision, without reading the relationships of the delegate upfront.
+//
+// The schema is read from the overlay only. The relationships of the delegate with the same
+// resource, relation and subject as one of the hidden relationships are not read, so that the
+// overlay can both delete relationships and replace their caveats.
+//
+// Every revision of the proxy is the given revision of the delegate, and closing the proxy closes
+// neither datastore.
+func NewOverlayDatastore(
+	delegate datastore.Datastore,
+	revision datastore.Revision,
+	overlay datastore.Datastore,
+	overlayRevision datastore.Revision,
+	hidden []*core.RelationTuple,
+) datastore.Datastore {
+	hiddenKeys := make(map[string]struct{}, len(hidden))
+	for _, rel := range hidden {
+		hiddenKeys[tuple.StringWithoutCaveat(rel)] = struct{}{}
+	}
+
+	return overlayDatastore{
+		Datastore:       delegate,
+		revision:        revision,
+		overlay:         overlay,
+		overlayRevision: overlayRevision,
+		hidden:          hiddenKeys,
+	}
+}
+
+func (od overlayDatastore) SnapshotReader(datastore.Revision) datastore.Reader {
+	return overlayReader{
+		Reader:   od.overlay.SnapshotReader(od.overlayRevision),
+		delegate: od.Datastore.SnapshotReader(od.revision),
+		revision: od.revision,
+		hidden:   od.hidden,
+	}
+}
+
+func (od overlayDatastore) ReadWriteTx(
+	context.Context,
+	datastore.TxUserFunc,
+	...options.RWTOptionsOption,
+) (datastore.Revision, error) {
+	return datastore.NoRevision, errReadOnly
+}
+
+func (od overlayDatastore) OptimizedRevision(context.Context) (datastore.Revision, error) {
+	return od.revision, nil
+}
+
+func (od overlayDatastore) HeadRevision(context.Context) (datastore.Revision, error) {
+	return od.revision, nil
+}
+
+func (od overlayDatastore) CheckRevision(context.Context, datastore.Revision) error {
+	return nil
+}
+
+func (od overlayDatastore) Watch(context.Context, datastore.Revision, datastore.WatchOptions) (<-chan *datastore.RevisionChanges, <-chan error) {
+	errs := make(chan error, 1)
+	errs <- datastore.NewWatchDisabledErr("overlays cannot be watched")
+	return nil, errs
+}
+
+func (od overlayDatastore) Close() error {
+	return nil
+}
+
+func (od overlayDatastore) Unwrap() datastore.Datastore {
+	return od.Datastore
+}
+
+// overlayReader reads the schema from the overlay, and merges the relationships of the overlay with
+// those of the delegate.
+type overlayReader struct {
+	datastore.Reader
+	delegate datastore.Reader
+	revision datastore.Revision
+	hidden   map[string]struct{}
+}
+
+func (r overlayReader) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	caveat, _, err := r.Reader.ReadCaveatByName(ctx, name)
+	if err != nil {
+		return nil, datastore.NoRevision, err
+	}
+	return caveat, r.revision, nil
+}
+
+func (r overlayReader) ListAllCaveats(ctx context.Context) ([]datastore.RevisionedCaveat, error) {
+	defs, err := r.Reader.ListAllCaveats(ctx)
+	return atRevision(defs, r.revision), err
+}
+
+func (r overlayReader) LookupCaveatsWithNames(ctx context.Context, names []string) ([]datastore.RevisionedCaveat, error) {
+	defs, err := r.Reader.LookupCaveatsWithNames(ctx, names)
+	return atRevision(defs, r.revision), err
+}
+
+func (r overlayReader) ReadNamespaceByName(ctx context.Context, nsName string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	ns, _, err := r.Reader.ReadNamespaceByName(ctx, nsName)
+	if err != nil {
+		return nil, datastore.NoRevision, err
+	}
+	return ns, r.revision, nil
+}
+
+func (r overlayReader) ListAllNamespaces(ctx context.Context) ([]datastore.RevisionedNamespace, error) {
+	defs, err := r.Reader.ListAllNamespaces(ctx)
+	return atRevision(defs, r.revision), err
+}
+
+func (r overlayReader) LookupNamespacesWithNames(ctx context.Context, nsNames []string) ([]datastore.RevisionedNamespace, error) {
+	defs, err := r.Reader.LookupNamespacesWithNames(ctx, nsNames)
+	return atRevision(defs, r.revision), err
+}
+
+// atRevision reports the definitions as written at the revision of the proxy, since the revisions
+// of the overlay are meaningless to the readers of the delegate.
+func atRevision[T datastore.SchemaDefinition](defs []datastore.RevisionedDefinition[T], revision datastore.Revision) []datastore.RevisionedDefinition[T] {
+	for i := range defs {
+		defs[i].LastWrittenRevision = revision
+	}
+	return defs
+}
+
+func (r overlayReader) QueryRelationships(
+	ctx context.Context,
+	filter datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	queryOpts := options.NewQueryOptionsWithOptions(opts...)
+	if queryOpts.After != nil && queryOpts.Sort == options.Unsorted {
+		return nil, datastore.ErrCursorsWithoutSorting
+	}
+
+	// Both sides are sorted, even if the caller does not require it, so that they can be merged.
+	sort := queryOpts.Sort
+	if sort == options.Unsorted {
+		sort = options.ByResource
+	}
+
+	// The limit is only applied to the overlay, since hidden relationships of the delegate do not
+	// count towards it.
+	overlayIt, err := r.Reader.QueryRelationships(ctx, filter, options.WithSort(sort), options.WithAfter(queryOpts.After), options.WithLimit(queryOpts.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	delegateIt, err := r.delegate.QueryRelationships(ctx, filter, options.WithSort(sort), options.WithAfter(queryOpts.After))
+	if err != nil {
+		overlayIt.Close()
+		return nil, err
+	}
+
+	return newMergedIterator(delegateIt, overlayIt, r.hidden, sort, queryOpts.Sort != options.Unsorted, queryOpts.Limit), nil
+}
+
+func (r overlayReader) ReverseQueryRelationships(
+	ctx context.Context,
+	subjectsFilter datastore.SubjectsFilter,
+	opts ...options.ReverseQueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	queryOpts := options.NewReverseQueryOptionsWithOptions(opts...)
+	if queryOpts.AfterForReverse != nil && queryOpts.SortForReverse == options.Unsorted {
+		return nil, datastore.ErrCursorsWithoutSorting
+	}
+
+	sort := queryOpts.SortForReverse
+	if sort == options.Unsorted {
+		sort = options.BySubject
+	}
+
+	overlayIt, err := r.Reader.ReverseQueryRelationships(ctx, subjectsFilter,
+		options.WithResRelation(queryOpts.ResRelation),
+		options.WithSortForReverse(sort),
+		options.WithAfterForReverse(queryOpts.AfterForReverse),
+		options.WithLimitForReverse(queryOpts.LimitForReverse),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delegateIt, err := r.delegate.ReverseQueryRelationships(ctx, subjectsFilter,
+		options.WithResRelation(queryOpts.ResRelation),
+		options.WithSortForReverse(sort),
+		options.WithAfterForReverse(queryOpts.AfterForReverse),
+	)
+	if err != nil {
+		overlayIt.Close()
+		return nil, err
+	}
+
+	return newMergedIterator(delegateIt, overlayIt, r.hidden, sort, queryOpts.SortForReverse != options.Unsorted, queryOpts.LimitForReverse), nil
+}
+
+// mergedIterator merges the relationships of two iterators sorted in the same order, skipping the
+// hidden relationships of the first.
+type mergedIterator struct {
+	delegate, overlay         datastore.RelationshipIterator
+	nextDelegate, nextOverlay *core.RelationTuple
+	delegateDone, overlayDone bool
+
+	hidden map[string]struct{}
+	sort   options.SortOrder
+	sorted bool
+	limit  *uint64
+	count  uint64
+	last   *core.RelationTuple
+	closed bool
+}
+
+func newMergedIterator(
+	delegate, overlay datastore.RelationshipIterator,
+	hidden map[string]struct{},
+	sort options.SortOrder,
+	sorted bool,
+	limit *uint64,
+) *mergedIterator {
+	return &mergedIterator{
+		delegate: delegate,
+		overlay:  overlay,
+		hidden:   hidden,
+		sort:     sort,
+		sorted:   sorted,
+		limit:    limit,
+	}
+}
+
+func (mi *mergedIterator) Next() *core.RelationTuple {
+	if mi.closed || (mi.limit != nil && mi.count >= *mi.limit) {
+		return nil
+	}
+
+	if mi.nextDelegate == nil && !mi.delegateDone {
+		for mi.nextDelegate = mi.delegate.Next(); mi.nextDelegate != nil; mi.nextDelegate = mi.delegate.Next() {
+			if _, ok := mi.hidden[tuple.StringWithoutCaveat(mi.nextDelegate)]; !ok {
+				break
+			}
+		}
+		mi.delegateDone = mi.nextDelegate == nil
+	}
+
+	if mi.nextOverlay == nil && !mi.overlayDone {
+		mi.nextOverlay = mi.overlay.Next()
+		mi.overlayDone = mi.nextOverlay == nil
+	}
+
+	var next *core.RelationTuple
+	switch {
+	case mi.Err() != nil:
+		return nil
+
+	case mi.nextDelegate == nil && mi.nextOverlay == nil:
+		return nil
+
+	case mi.nextDelegate == nil || (mi.nextOverlay != nil && compareRelationships(mi.nextOverlay, mi.nextDelegate, mi.sort) <= 0):
+		next, mi.nextOverlay = mi.nextOverlay, nil
+
+	default:
+		next, mi.nextDelegate = mi.nextDelegate, nil
+	}
+
+	mi.count++
+	mi.last = next
+	return next
+}
+
+func (mi *mergedIterator) Cursor() (options.Cursor, error) {
+	switch {
+	case mi.closed:
+		return nil, datastore.ErrClosedIterator
+	case !mi.sorted:
+		return nil, datastore.ErrCursorsWithoutSorting
+	case mi.last == nil:
+		return nil, datastore.ErrCursorEmpty
+	default:
+		return mi.last, nil
+	}
+}
+
+func (mi *mergedIterator) Err() error {
+	if err := mi.delegate.Err(); err != nil {
+		return err
+	}
+	return mi.overlay.Err()
+}
+
+func (mi *mergedIterator) Close() {
+	if mi.closed {
+		return
+	}
+
+	mi.closed = true
+	mi.delegate.Close()
+	mi.overlay.Close()
+}
+
+// compareRelationships compares relationships in the given sort order, by resource and then by
+// subject unless sorted by subject.
+func compareRelationships(lhs, rhs *core.RelationTuple, sort options.SortOrder) int {
+	first, second := compareObjectAndRelations(lhs.ResourceAndRelation, rhs.ResourceAndRelation), compareObjectAndRelations(lhs.Subject, rhs.Subject)
+	if sort == options.BySubject {
+		first, second = second, first
+	}
+
+	if first != 0 {
+		return first
+	}
+	return second
+}
+
+func compareObjectAndRelations(lhs, rhs *core.ObjectAndRelation) int {
+	if c := strings.Compare(lhs.Namespace, rhs.Namespace); c != 0 {
+		return c
+	}
+	if c := strings.Compare(lhs.ObjectId, rhs.ObjectId); c != 0 {
+		return c
+	}
+	return strings.Compare(lhs.Relation, rhs.Relation)
+}
+
+var _ datastore.Datastore = overlayDatastore{}
@@ -0,0 +1,135 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func newOverlayTestDatastore(t *testing.T) datastore.Datastore {
+	delegateDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = delegateDS.Close() })
+
+	delegate, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(delegateDS, `
+		definition user {}
+
+		definition document {
+			relation viewer: user
+		}
+	`, []*core.RelationTuple{
+		tuple.MustParse("document:a#viewer@user:tom"),
+		tuple.MustParse("document:b#viewer@user:tom"),
+		tuple.MustParse("document:d#viewer@user:tom"),
+		tuple.MustParse("document:e#viewer@user:fred"),
+	}, require.New(t))
+
+	overlayDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = overlayDS.Close() })
+
+	touched := []*core.RelationTuple{
+		tuple.MustParse("document:c#viewer@user:tom"),
+		tuple.MustParse("document:e#viewer@user:tom"),
+	}
+	overlay, overlayRevision := testfixtures.DatastoreFromSchemaAndTestRelationships(overlayDS, `
+		definition user {}
+
+		definition document {
+			relation viewer: user
+			relation editor: user
+		}
+	`, touched, require.New(t))
+
+	// The delegate is only read at the given revision, so later writes are not seen.
+	_, err = delegate.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Create(tuple.MustParse("document:f#viewer@user:tom"))})
+	})
+	require.NoError(t, err)
+
+	return NewOverlayDatastore(delegate, revision, overlay, overlayRevision, append(touched, tuple.MustParse("document:b#viewer@user:tom")))
+}
+
+func collectRelationships(t *testing.T, query func() (datastore.RelationshipIterator, error)) ([]string, options.Cursor) {
+	iter, err := query()
+	require.NoError(t, err)
+	defer iter.Close()
+
+	var found []string
+	for rel := iter.Next(); rel != nil; rel = iter.Next() {
+		found = append(found, tuple.MustString(rel))
+	}
+	require.NoError(t, iter.Err())
+
+	cursor, _ := iter.Cursor()
+	return found, cursor
+}
+
+func TestOverlayDatastoreRelationships(t *testing.T) {
+	ctx := context.Background()
+	ds := newOverlayTestDatastore(t)
+	reader := ds.SnapshotReader(datastore.NoRevision)
+	filter := datastore.RelationshipsFilter{OptionalResourceType: "document"}
+
+	found, _ := collectRelationships(t, func() (datastore.RelationshipIterator, error) {
+		return reader.QueryRelationships(ctx, filter)
+	})
+	require.Equal(t, []string{
+		"document:a#viewer@user:tom",
+		"document:c#viewer@user:tom",
+		"document:d#viewer@user:tom",
+		"document:e#viewer@user:fred",
+		"document:e#viewer@user:tom",
+	}, found)
+
+	// Pages of sorted queries resume after their cursor.
+	found, cursor := collectRelationships(t, func() (datastore.RelationshipIterator, error) {
+		return reader.QueryRelationships(ctx, filter, options.WithSort(options.ByResource), options.WithLimit(options.LimitOne))
+	})
+	require.Equal(t, []string{"document:a#viewer@user:tom"}, found)
+
+	found, _ = collectRelationships(t, func() (datastore.RelationshipIterator, error) {
+		return reader.QueryRelationships(ctx, filter, options.WithSort(options.ByResource), options.WithAfter(cursor))
+	})
+	require.Equal(t, []string{
+		"document:c#viewer@user:tom",
+		"document:d#viewer@user:tom",
+		"document:e#viewer@user:fred",
+		"document:e#viewer@user:tom",
+	}, found)
+
+	found, _ = collectRelationships(t, func() (datastore.RelationshipIterator, error) {
+		return reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{SubjectType: "user", OptionalSubjectIds: []string{"tom"}},
+			options.WithSortForReverse(options.BySubject))
+	})
+	require.Equal(t, []string{
+		"document:a#viewer@user:tom",
+		"document:c#viewer@user:tom",
+		"document:d#viewer@user:tom",
+		"document:e#viewer@user:tom",
+	}, found)
+}
+
+func TestOverlayDatastoreSchema(t *testing.T) {
+	ctx := context.Background()
+	ds := newOverlayTestDatastore(t)
+
+	revision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	ns, lastWritten, err := ds.SnapshotReader(revision).ReadNamespaceByName(ctx, "document")
+	require.NoError(t, err)
+	require.Len(t, ns.Relation, 2)
+	require.True(t, lastWritten.Equal(revision))
+
+	_, err = ds.ReadWriteTx(ctx, func(context.Context, datastore.ReadWriteTransaction) error { return nil })
+	require.ErrorAs(t, err, &datastore.ErrReadOnly{})
+}
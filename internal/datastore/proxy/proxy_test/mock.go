@@ -75,6 +75,11 @@ func (dm *MockDatastore) Statistics(_ context.Context) (datastore.Stats, error)
 	return args.Get(0).(datastore.Stats), args.Error(1)
 }
 
+func (dm *MockDatastore) UniqueID(_ context.Context) (string, error) {
+	args := dm.Called()
+	return args.String(0), args.Error(1)
+}
+
 func (dm *MockDatastore) Close() error {
 	args := dm.Called()
 	return args.Error(0)
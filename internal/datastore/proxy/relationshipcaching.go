@@ -0,0 +1,231 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/pkg/cache"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// relationshipSizeVTMultiplier is the multiplier used for estimating the in-memory cost of
+	// a cached relationship based on its on-wire size, as returned by SizeVT.
+	relationshipSizeVTMultiplier = 4
+
+	// cachedQueryOverhead is the estimated in-memory cost of a cached query result, excluding the
+	// relationships it contains.
+	cachedQueryOverhead = 64
+
+	queryCacheKeyPrefix        = "q"
+	reverseQueryCacheKeyPrefix = "r"
+)
+
+// NewRelationshipCachingDatastoreProxy creates a new Datastore proxy which caches the results of
+// relationship queries made against snapshot readers, keyed by the revision of the reader. As the
+// data found at a revision never changes, cached entries never need to be invalidated, and are
+// instead evicted by the cache itself.
+//
+// Only queries returning at most maxCachedRelationships relationships are cached; larger result sets
+// are streamed from the delegate datastore as normal.
+func NewRelationshipCachingDatastoreProxy(delegate datastore.Datastore, c cache.Cache, maxCachedRelationships uint64) datastore.Datastore {
+	if c == nil {
+		c = cache.NoopCache()
+	}
+
+	return &relationshipCachingProxy{
+		Datastore:              delegate,
+		c:                      c,
+		maxCachedRelationships: maxCachedRelationships,
+	}
+}
+
+type relationshipCachingProxy struct {
+	datastore.Datastore
+	c                      cache.Cache
+	maxCachedRelationships uint64
+}
+
+type cachedRelationships struct {
+	relationships []*core.RelationTuple
+	sort          options.SortOrder
+}
+
+func (p *relationshipCachingProxy) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return &relationshipCachingReader{p.Datastore.SnapshotReader(rev), rev, p}
+}
+
+func (p *relationshipCachingProxy) Close() error {
+	p.c.Close()
+	return p.Datastore.Close()
+}
+
+func (p *relationshipCachingProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+type relationshipCachingReader struct {
+	datastore.Reader
+	rev datastore.Revision
+	p   *relationshipCachingProxy
+}
+
+func (r *relationshipCachingReader) QueryRelationships(
+	ctx context.Context,
+	filter datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	queryOpts := options.NewQueryOptionsWithOptions(opts...)
+	key := queryCacheKey(r.rev, filter, queryOpts)
+	return r.readAndCache(key, queryOpts.Sort, func() (datastore.RelationshipIterator, error) {
+		return r.Reader.QueryRelationships(ctx, filter, opts...)
+	})
+}
+
+func (r *relationshipCachingReader) ReverseQueryRelationships(
+	ctx context.Context,
+	subjectsFilter datastore.SubjectsFilter,
+	opts ...options.ReverseQueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	queryOpts := options.NewReverseQueryOptionsWithOptions(opts...)
+	key := reverseQueryCacheKey(r.rev, subjectsFilter, queryOpts)
+	return r.readAndCache(key, queryOpts.SortForReverse, func() (datastore.RelationshipIterator, error) {
+		return r.Reader.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
+	})
+}
+
+func (r *relationshipCachingReader) readAndCache(
+	key string,
+	sort options.SortOrder,
+	query func() (datastore.RelationshipIterator, error),
+) (datastore.RelationshipIterator, error) {
+	if loadedRaw, found := r.p.c.Get(key); found {
+		loaded := loadedRaw.(*cachedRelationships)
+		return common.NewSliceRelationshipIterator(loaded.relationships, loaded.sort), nil
+	}
+
+	it, err := query()
+	if err != nil {
+		return nil, err
+	}
+
+	// Buffer the results, up to the maximum allowed to be cached.
+	buffered := make([]*core.RelationTuple, 0)
+	estimatedSize := int64(cachedQueryOverhead)
+	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+		buffered = append(buffered, tpl)
+		estimatedSize += int64(tpl.SizeVT() * relationshipSizeVTMultiplier)
+
+		if uint64(len(buffered)) > r.p.maxCachedRelationships {
+			// Too many results to cache: return the buffered results followed by the remainder
+			// of the delegate iterator.
+			return &bufferedRelationshipIterator{buffered: buffered, sort: sort, delegate: it}, nil
+		}
+	}
+
+	if it.Err() != nil {
+		err := it.Err()
+		it.Close()
+		return nil, err
+	}
+	it.Close()
+
+	entry := &cachedRelationships{buffered, sort}
+	r.p.c.Set(key, entry, estimatedSize)
+
+	// We have to call wait here or else Ristretto may not have the key
+	// available to a subsequent caller.
+	r.p.c.Wait()
+	return common.NewSliceRelationshipIterator(buffered, sort), nil
+}
+
+// bufferedRelationshipIterator is an iterator which returns a set of already read relationships
+// before returning those remaining in the delegate iterator.
+type bufferedRelationshipIterator struct {
+	buffered []*core.RelationTuple
+	sort     options.SortOrder
+	index    int
+	delegate datastore.RelationshipIterator
+}
+
+func (bri *bufferedRelationshipIterator) Next() *core.RelationTuple {
+	if bri.index < len(bri.buffered) {
+		bri.index++
+		return bri.buffered[bri.index-1]
+	}
+
+	bri.index = len(bri.buffered) + 1
+	return bri.delegate.Next()
+}
+
+func (bri *bufferedRelationshipIterator) Cursor() (options.Cursor, error) {
+	if bri.index > len(bri.buffered) {
+		return bri.delegate.Cursor()
+	}
+
+	if bri.sort == options.Unsorted {
+		return nil, datastore.ErrCursorsWithoutSorting
+	}
+
+	if bri.index == 0 {
+		return nil, datastore.ErrCursorEmpty
+	}
+
+	return options.Cursor(bri.buffered[bri.index-1]), nil
+}
+
+func (bri *bufferedRelationshipIterator) Err() error {
+	return bri.delegate.Err()
+}
+
+func (bri *bufferedRelationshipIterator) Close() {
+	bri.delegate.Close()
+}
+
+func queryCacheKey(rev datastore.Revision, filter datastore.RelationshipsFilter, opts *options.QueryOptions) string {
+	var sb strings.Builder
+	sb.WriteString(queryCacheKeyPrefix)
+	fmt.Fprintf(&sb, "@%s", rev.String())
+	fmt.Fprintf(&sb, "|%q|%q|%q|%q|%q", filter.OptionalResourceType, filter.OptionalResourceIds, filter.OptionalResourceIDPrefix, filter.OptionalResourceRelation, filter.OptionalCaveatName)
+	for _, selector := range filter.OptionalSubjectsSelectors {
+		writeSubjectSelectorKey(&sb, selector.OptionalSubjectType, selector.OptionalSubjectIds, selector.OptionalSubjectIDPrefix, selector.RelationFilter)
+	}
+	writeQueryOptionsKey(&sb, opts.Limit, opts.Sort, opts.After)
+	return sb.String()
+}
+
+func reverseQueryCacheKey(rev datastore.Revision, filter datastore.SubjectsFilter, opts *options.ReverseQueryOptions) string {
+	var sb strings.Builder
+	sb.WriteString(reverseQueryCacheKeyPrefix)
+	fmt.Fprintf(&sb, "@%s", rev.String())
+	writeSubjectSelectorKey(&sb, filter.SubjectType, filter.OptionalSubjectIds, filter.OptionalSubjectIDPrefix, filter.RelationFilter)
+	if opts.ResRelation != nil {
+		fmt.Fprintf(&sb, "|%q#%q", opts.ResRelation.Namespace, opts.ResRelation.Relation)
+	}
+	writeQueryOptionsKey(&sb, opts.LimitForReverse, opts.SortForReverse, opts.AfterForReverse)
+	return sb.String()
+}
+
+func writeSubjectSelectorKey(sb *strings.Builder, subjectType string, subjectIDs []string, subjectIDPrefix string, relationFilter datastore.SubjectRelationFilter) {
+	fmt.Fprintf(sb, "|s:%q|%q|%q|%q|%t|%t", subjectType, subjectIDs, subjectIDPrefix, relationFilter.NonEllipsisRelation, relationFilter.IncludeEllipsisRelation, relationFilter.OnlyNonEllipsisRelations)
+}
+
+func writeQueryOptionsKey(sb *strings.Builder, limit *uint64, sort options.SortOrder, after options.Cursor) {
+	if limit != nil {
+		fmt.Fprintf(sb, "|l:%d", *limit)
+	}
+	fmt.Fprintf(sb, "|o:%d", sort)
+	if after != nil {
+		fmt.Fprintf(sb, "|a:%s", tuple.MustString(after))
+	}
+}
+
+var (
+	_ datastore.Datastore            = (*relationshipCachingProxy)(nil)
+	_ datastore.UnwrappableDatastore = (*relationshipCachingProxy)(nil)
+)
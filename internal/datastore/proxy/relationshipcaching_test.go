@@ -0,0 +1,185 @@
+package proxy
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/cache"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type countingDatastore struct {
+	datastore.Datastore
+	queryCount *atomic.Int64
+}
+
+func (cd countingDatastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return countingReader{cd.Datastore.SnapshotReader(rev), cd.queryCount}
+}
+
+type countingReader struct {
+	datastore.Reader
+	queryCount *atomic.Int64
+}
+
+func (cr countingReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, opts ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	cr.queryCount.Add(1)
+	return cr.Reader.QueryRelationships(ctx, filter, opts...)
+}
+
+func (cr countingReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, opts ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	cr.queryCount.Add(1)
+	return cr.Reader.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
+}
+
+func newRelationshipCachingTestDatastore(t *testing.T, maxCachedRelationships uint64) (datastore.Datastore, *atomic.Int64) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	c, err := cache.NewCache(&cache.Config{
+		NumCounters: 1000,
+		MaxCost:     1 << 20,
+	})
+	require.NoError(t, err)
+
+	queryCount := &atomic.Int64{}
+	return NewRelationshipCachingDatastoreProxy(countingDatastore{rawDS, queryCount}, c, maxCachedRelationships), queryCount
+}
+
+func readAll(t *testing.T, it datastore.RelationshipIterator, err error) []string {
+	require.NoError(t, err)
+	defer it.Close()
+
+	found := []string{}
+	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+		found = append(found, tuple.MustString(tpl))
+	}
+	require.NoError(t, it.Err())
+	return found
+}
+
+func TestRelationshipCachingQueryRelationships(t *testing.T) {
+	ctx := context.Background()
+	ds, queryCount := newRelationshipCachingTestDatastore(t, 100)
+	defer ds.Close()
+
+	firstRev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse("document:first#viewer@user:tom"),
+		tuple.MustParse("document:first#viewer@user:sarah"),
+	)
+	require.NoError(t, err)
+
+	filter := datastore.RelationshipsFilter{
+		OptionalResourceType:     "document",
+		OptionalResourceIds:      []string{"first"},
+		OptionalResourceRelation: "viewer",
+	}
+
+	expected := []string{"document:first#viewer@user:sarah", "document:first#viewer@user:tom"}
+
+	it, err := ds.SnapshotReader(firstRev).QueryRelationships(ctx, filter, options.WithSort(options.ByResource))
+	require.Equal(t, expected, readAll(t, it, err))
+	require.Equal(t, int64(1), queryCount.Load())
+
+	// A second identical query should be served from the cache.
+	it, err = ds.SnapshotReader(firstRev).QueryRelationships(ctx, filter, options.WithSort(options.ByResource))
+	require.Equal(t, expected, readAll(t, it, err))
+	require.Equal(t, int64(1), queryCount.Load())
+
+	// A query with different options should not.
+	it, err = ds.SnapshotReader(firstRev).QueryRelationships(ctx, filter, options.WithSort(options.ByResource), options.WithLimit(options.LimitOne))
+	require.Equal(t, expected[0:1], readAll(t, it, err))
+	require.Equal(t, int64(2), queryCount.Load())
+
+	// Nor should a query at another revision.
+	secondRev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse("document:first#viewer@user:fred"),
+	)
+	require.NoError(t, err)
+
+	it, err = ds.SnapshotReader(secondRev).QueryRelationships(ctx, filter, options.WithSort(options.ByResource))
+	require.Equal(t, []string{"document:first#viewer@user:fred", "document:first#viewer@user:sarah", "document:first#viewer@user:tom"}, readAll(t, it, err))
+	require.Equal(t, int64(3), queryCount.Load())
+
+	// The original revision should still return the original data.
+	it, err = ds.SnapshotReader(firstRev).QueryRelationships(ctx, filter, options.WithSort(options.ByResource))
+	require.Equal(t, expected, readAll(t, it, err))
+	require.Equal(t, int64(3), queryCount.Load())
+}
+
+func TestRelationshipCachingReverseQueryRelationships(t *testing.T) {
+	ctx := context.Background()
+	ds, queryCount := newRelationshipCachingTestDatastore(t, 100)
+	defer ds.Close()
+
+	rev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse("document:first#viewer@user:tom"),
+		tuple.MustParse("document:second#viewer@user:tom"),
+	)
+	require.NoError(t, err)
+
+	filter := datastore.SubjectsFilter{
+		SubjectType:        "user",
+		OptionalSubjectIds: []string{"tom"},
+	}
+
+	for i := 0; i < 3; i++ {
+		it, err := ds.SnapshotReader(rev).ReverseQueryRelationships(ctx, filter, options.WithSortForReverse(options.BySubject))
+		require.ElementsMatch(t, []string{"document:first#viewer@user:tom", "document:second#viewer@user:tom"}, readAll(t, it, err))
+	}
+	require.Equal(t, int64(1), queryCount.Load())
+}
+
+func TestRelationshipCachingSkipsLargeResults(t *testing.T) {
+	ctx := context.Background()
+	ds, queryCount := newRelationshipCachingTestDatastore(t, 1)
+	defer ds.Close()
+
+	rev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse("document:first#viewer@user:tom"),
+		tuple.MustParse("document:first#viewer@user:sarah"),
+		tuple.MustParse("document:first#viewer@user:fred"),
+	)
+	require.NoError(t, err)
+
+	filter := datastore.RelationshipsFilter{OptionalResourceType: "document"}
+	expected := []string{"document:first#viewer@user:fred", "document:first#viewer@user:sarah", "document:first#viewer@user:tom"}
+
+	for i := 0; i < 2; i++ {
+		it, err := ds.SnapshotReader(rev).QueryRelationships(ctx, filter, options.WithSort(options.ByResource))
+		require.Equal(t, expected, readAll(t, it, err))
+	}
+	require.Equal(t, int64(2), queryCount.Load())
+}
+
+func TestBufferedRelationshipIteratorCursor(t *testing.T) {
+	ctx := context.Background()
+	ds, _ := newRelationshipCachingTestDatastore(t, 1)
+	defer ds.Close()
+
+	rev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse("document:first#viewer@user:tom"),
+		tuple.MustParse("document:second#viewer@user:tom"),
+		tuple.MustParse("document:third#viewer@user:tom"),
+	)
+	require.NoError(t, err)
+
+	it, err := ds.SnapshotReader(rev).QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: "document"}, options.WithSort(options.ByResource))
+	require.NoError(t, err)
+	defer it.Close()
+
+	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+		cursor, err := it.Cursor()
+		require.NoError(t, err)
+		require.Equal(t, tuple.MustString(tpl), tuple.MustString(cursor))
+	}
+	require.NoError(t, it.Err())
+}
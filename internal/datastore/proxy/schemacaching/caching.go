@@ -24,6 +24,14 @@ const (
 	JustInTimeCaching
 )
 
+// Flushable is implemented by the datastore proxies of this package, to drop cached definitions
+// after the schema was changed out-of-band.
+type Flushable interface {
+	// FlushDefinitions drops the cached definitions with the given names, or all of them if none
+	// are given.
+	FlushDefinitions(names ...string)
+}
+
 // DatastoreProxyTestCache returns a cache used for testing.
 func DatastoreProxyTestCache(t testing.TB) cache.Cache {
 	cache, err := cache.NewCache(&cache.Config{
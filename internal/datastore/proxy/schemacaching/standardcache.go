@@ -3,7 +3,9 @@ package schemacaching
 import (
 	"context"
 	"errors"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/authzed/spicedb/pkg/datastore/options"
@@ -23,6 +25,35 @@ type definitionCachingProxy struct {
 	datastore.Datastore
 	c         cache.Cache
 	readGroup singleflight.Group
+
+	// generations holds an *atomic.Uint64 per definition name that was flushed, and is part of the
+	// keys under which the definition is cached.
+	generations sync.Map
+}
+
+var _ Flushable = (*definitionCachingProxy)(nil)
+
+// FlushDefinitions drops the cached definitions with the given names, or all of them if none are
+// given. Entries of flushed definitions are left in the cache, but will never be read again.
+func (p *definitionCachingProxy) FlushDefinitions(names ...string) {
+	if len(names) == 0 {
+		p.c.Clear()
+		return
+	}
+
+	for _, name := range names {
+		generation, _ := p.generations.LoadOrStore(name, &atomic.Uint64{})
+		generation.(*atomic.Uint64).Add(1)
+	}
+}
+
+// cacheKey returns the key under which the definition with the name is cached at the revision.
+func (p *definitionCachingProxy) cacheKey(prefix, name string, rev datastore.Revision) string {
+	key := prefix + ":" + name + "@" + rev.String()
+	if generation, ok := p.generations.Load(name); ok {
+		key += "#" + strconv.FormatUint(generation.(*atomic.Uint64).Load(), 10)
+	}
+	return key
 }
 
 func (p *definitionCachingProxy) Close() error {
@@ -119,7 +150,7 @@ func listAndCache[T schemaDefinition](
 
 	foundDefs := make([]datastore.RevisionedDefinition[T], 0, len(names))
 	for _, name := range names {
-		cacheRevisionKey := prefix + ":" + name + "@" + r.rev.String()
+		cacheRevisionKey := r.p.cacheKey(prefix, name, r.rev)
 		loadedRaw, found := r.p.c.Get(cacheRevisionKey)
 		if !found {
 			continue
@@ -143,7 +174,7 @@ func listAndCache[T schemaDefinition](
 		for _, def := range loadedDefs {
 			foundDefs = append(foundDefs, def)
 
-			cacheRevisionKey := prefix + ":" + def.Definition.GetName() + "@" + r.rev.String()
+			cacheRevisionKey := r.p.cacheKey(prefix, def.Definition.GetName(), r.rev)
 			estimatedDefinitionSize := estimator(def.Definition.SizeVT())
 			entry := &cacheEntry{def.Definition, def.LastWrittenRevision, estimatedDefinitionSize, err}
 			r.p.c.Set(cacheRevisionKey, entry, entry.Size())
@@ -166,7 +197,7 @@ func readAndCache[T schemaDefinition](
 	estimator func(sizeVT int) int64,
 ) (T, datastore.Revision, error) {
 	// Check the cache.
-	cacheRevisionKey := prefix + ":" + name + "@" + r.rev.String()
+	cacheRevisionKey := r.p.cacheKey(prefix, name, r.rev)
 	loadedRaw, found := r.p.c.Get(cacheRevisionKey)
 	if !found {
 		// We couldn't use the cached entry, load one
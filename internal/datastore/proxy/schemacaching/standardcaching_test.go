@@ -202,6 +202,54 @@ func TestSnapshotCaching(t *testing.T) {
 	}
 }
 
+func TestFlushDefinitions(t *testing.T) {
+	for _, tester := range testers {
+		tester := tester
+		t.Run(tester.name, func(t *testing.T) {
+			dsMock := &proxy_test.MockDatastore{}
+
+			oneReader := &proxy_test.MockReader{}
+			dsMock.On("SnapshotReader", one).Return(oneReader)
+			oneReader.On(tester.readSingleFunctionName, nsA).Return(nil, old, nil).Once()
+			oneReader.On(tester.readSingleFunctionName, nsA).Return(nil, zero, nil).Once()
+			oneReader.On(tester.readSingleFunctionName, nsB).Return(nil, old, nil).Once()
+			oneReader.On(tester.readSingleFunctionName, nsB).Return(nil, zero, nil).Once()
+
+			require := require.New(t)
+			ds := NewCachingDatastoreProxy(dsMock, DatastoreProxyTestCache(t), 1*time.Hour, JustInTimeCaching, 100*time.Millisecond)
+
+			_, updatedA, err := tester.readSingleFunc(context.Background(), ds.SnapshotReader(one), nsA)
+			require.NoError(err)
+			require.True(old.Equal(updatedA))
+
+			_, updatedB, err := tester.readSingleFunc(context.Background(), ds.SnapshotReader(one), nsB)
+			require.NoError(err)
+			require.True(old.Equal(updatedB))
+
+			// Flushing a definition by name reloads only that definition.
+			ds.(Flushable).FlushDefinitions(nsA)
+
+			_, updatedA, err = tester.readSingleFunc(context.Background(), ds.SnapshotReader(one), nsA)
+			require.NoError(err)
+			require.True(zero.Equal(updatedA))
+
+			_, updatedB, err = tester.readSingleFunc(context.Background(), ds.SnapshotReader(one), nsB)
+			require.NoError(err)
+			require.True(old.Equal(updatedB))
+
+			// Flushing without names reloads every definition.
+			ds.(Flushable).FlushDefinitions()
+
+			_, updatedB, err = tester.readSingleFunc(context.Background(), ds.SnapshotReader(one), nsB)
+			require.NoError(err)
+			require.True(zero.Equal(updatedB))
+
+			dsMock.AssertExpectations(t)
+			oneReader.AssertExpectations(t)
+		})
+	}
+}
+
 func TestRWTCaching(t *testing.T) {
 	for _, tester := range testers {
 		tester := tester
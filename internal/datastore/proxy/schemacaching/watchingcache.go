@@ -339,6 +339,12 @@ func (p *watchingCachingProxy) startSync(ctx context.Context) error {
 	return nil
 }
 
+// FlushDefinitions drops the definitions cached while the schema watch is in fallback mode. The
+// definitions received from the schema watch are not flushed, as they are kept up to date by it.
+func (p *watchingCachingProxy) FlushDefinitions(names ...string) {
+	p.fallbackCache.FlushDefinitions(names...)
+}
+
 func (p *watchingCachingProxy) Close() error {
 	p.caveatCache.setFallbackMode()
 	p.namespaceCache.setFallbackMode()
@@ -510,6 +510,10 @@ func (*fakeDatastore) Features(context.Context) (*datastore.Features, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
+func (*fakeDatastore) UniqueID(context.Context) (string, error) {
+	return "", fmt.Errorf("not implemented")
+}
+
 func (*fakeDatastore) OptimizedRevision(context.Context) (datastore.Revision, error) {
 	return nil, fmt.Errorf("not implemented")
 }
@@ -69,6 +69,10 @@ func (p *singleflightProxy) Statistics(ctx context.Context) (datastore.Stats, er
 	return stats, err
 }
 
+func (p *singleflightProxy) UniqueID(ctx context.Context) (string, error) {
+	return p.delegate.UniqueID(ctx)
+}
+
 func (p *singleflightProxy) Features(ctx context.Context) (*datastore.Features, error) {
 	return p.delegate.Features(ctx)
 }
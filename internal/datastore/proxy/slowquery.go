@@ -0,0 +1,131 @@
+package proxy
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// NewSlowQueryLoggingProxy creates a new datastore proxy which logs, at warning level, the
+// relationship queries which take at least the threshold from being issued until their iterator
+// is closed, along with their filters and the number of relationships read.
+func NewSlowQueryLoggingProxy(d datastore.Datastore, threshold time.Duration) datastore.Datastore {
+	return &slowQueryProxy{Datastore: d, threshold: threshold}
+}
+
+type slowQueryProxy struct {
+	datastore.Datastore
+	threshold time.Duration
+}
+
+func (p *slowQueryProxy) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return &slowQueryReader{p.Datastore.SnapshotReader(rev), p.threshold}
+}
+
+func (p *slowQueryProxy) ReadWriteTx(
+	ctx context.Context,
+	f datastore.TxUserFunc,
+	opts ...options.RWTOptionsOption,
+) (datastore.Revision, error) {
+	return p.Datastore.ReadWriteTx(ctx, func(ctx context.Context, delegateRWT datastore.ReadWriteTransaction) error {
+		return f(ctx, &slowQueryRWT{delegateRWT, &slowQueryReader{delegateRWT, p.threshold}})
+	}, opts...)
+}
+
+func (p *slowQueryProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+type slowQueryReader struct {
+	datastore.Reader
+	threshold time.Duration
+}
+
+func (r *slowQueryReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	start := time.Now()
+	iterator, err := r.Reader.QueryRelationships(ctx, filter, options...)
+	if err != nil {
+		return iterator, err
+	}
+
+	return &slowQueryIterator{delegate: iterator, start: start, logIfSlow: func(duration time.Duration, count uint64) {
+		if duration >= r.threshold {
+			logSlowQuery(ctx, "QueryRelationships", duration, count).Interface("filter", filter).Msg("slow datastore query")
+		}
+	}}, nil
+}
+
+func (r *slowQueryReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	start := time.Now()
+	iterator, err := r.Reader.ReverseQueryRelationships(ctx, subjectsFilter, options...)
+	if err != nil {
+		return iterator, err
+	}
+
+	return &slowQueryIterator{delegate: iterator, start: start, logIfSlow: func(duration time.Duration, count uint64) {
+		if duration >= r.threshold {
+			logSlowQuery(ctx, "ReverseQueryRelationships", duration, count).Interface("filter", subjectsFilter).Msg("slow datastore query")
+		}
+	}}, nil
+}
+
+func logSlowQuery(ctx context.Context, operation string, duration time.Duration, count uint64) *zerolog.Event {
+	return log.Ctx(ctx).Warn().
+		Str("operation", operation).
+		Dur("duration", duration).
+		Uint64("relationships", count)
+}
+
+type slowQueryRWT struct {
+	datastore.ReadWriteTransaction
+	reader *slowQueryReader
+}
+
+func (rwt *slowQueryRWT) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return rwt.reader.QueryRelationships(ctx, filter, options...)
+}
+
+func (rwt *slowQueryRWT) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return rwt.reader.ReverseQueryRelationships(ctx, subjectsFilter, options...)
+}
+
+type slowQueryIterator struct {
+	delegate  datastore.RelationshipIterator
+	start     time.Time
+	count     uint64
+	closed    bool
+	logIfSlow func(duration time.Duration, count uint64)
+}
+
+func (i *slowQueryIterator) Next() *core.RelationTuple {
+	if next := i.delegate.Next(); next != nil {
+		i.count++
+		return next
+	}
+	return nil
+}
+
+func (i *slowQueryIterator) Err() error { return i.delegate.Err() }
+
+func (i *slowQueryIterator) Cursor() (options.Cursor, error) { return i.delegate.Cursor() }
+
+func (i *slowQueryIterator) Close() {
+	if !i.closed {
+		i.closed = true
+		i.logIfSlow(time.Since(i.start), i.count)
+	}
+	i.delegate.Close()
+}
+
+var (
+	_ datastore.Datastore            = (*slowQueryProxy)(nil)
+	_ datastore.Reader               = (*slowQueryReader)(nil)
+	_ datastore.ReadWriteTransaction = (*slowQueryRWT)(nil)
+	_ datastore.RelationshipIterator = (*slowQueryIterator)(nil)
+)
@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/test"
+)
+
+type slowQueryTest struct{}
+
+func (sqt slowQueryTest) New(revisionQuantization, _, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
+	db, err := memdb.NewMemdbDatastore(watchBufferLength, revisionQuantization, gcWindow)
+	if err != nil {
+		return nil, err
+	}
+	return NewSlowQueryLoggingProxy(db, 0), nil
+}
+
+func TestSlowQueryLoggingProxy(t *testing.T) {
+	test.All(t, slowQueryTest{})
+}
+
+func (p *slowQueryProxy) ExampleRetryableError() error {
+	return memdb.ErrSerialization
+}
+
+func TestSlowQueryLogging(t *testing.T) {
+	require := require.New(t)
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	rawDS, revision := testfixtures.StandardDatastoreWithData(rawDS, require)
+
+	for _, tc := range []struct {
+		name        string
+		threshold   time.Duration
+		expectedLog bool
+	}{
+		{"below threshold", time.Hour, false},
+		{"above threshold", time.Nanosecond, true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ctx := zerolog.New(&buf).WithContext(context.Background())
+
+			ds := NewSlowQueryLoggingProxy(rawDS, tc.threshold)
+			iter, err := ds.SnapshotReader(revision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+				OptionalResourceType:     "document",
+				OptionalResourceRelation: "viewer",
+			})
+			require.NoError(err)
+
+			count := 0
+			for rel := iter.Next(); rel != nil; rel = iter.Next() {
+				count++
+			}
+			require.NoError(iter.Err())
+			iter.Close()
+			require.Positive(count)
+
+			if !tc.expectedLog {
+				require.Empty(buf.String())
+				return
+			}
+
+			logged := buf.String()
+			require.Contains(logged, `"message":"slow datastore query"`)
+			require.Contains(logged, `"operation":"QueryRelationships"`)
+			require.Contains(logged, `"OptionalResourceRelation":"viewer"`)
+			require.Contains(logged, `"relationships":`+strconv.Itoa(count))
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+// ErrOutsideTenant is returned when a definition outside of the object type prefixes of a tenant
+// is accessed through a tenant-scoped datastore.
+type ErrOutsideTenant struct {
+	error
+
+	// Name is the name of the object definition, object type or caveat outside of the tenant.
+	Name string
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrOutsideTenant) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(err, codes.PermissionDenied)
+}
+
+// NewOutsideTenantErr constructs a new error for a name outside of the prefixes of a tenant.
+func NewOutsideTenantErr(name string) error {
+	return ErrOutsideTenant{
+		error: fmt.Errorf("`%s` is outside of the object type prefixes of the caller", name),
+		Name:  name,
+	}
+}
+
+// WithinTenant returns whether the object type or caveat name falls under one of the given object
+// type prefixes, such as `tenant` for `tenant/document`.
+func WithinTenant(prefixes []string, name string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+type tenantDatastore struct {
+	datastore.Datastore
+	prefixes []string
+}
+
+// NewTenantScopedDatastore creates a proxy which scopes a downstream delegate datastore to the
+// object definitions and caveats under the given object type prefixes: the others are neither
+// listed nor found, and cannot be written or deleted.
+//
+// Relationships are scoped likewise: those with a resource or subject type outside of the prefixes
+// are skipped when read, and cannot be written, deleted or loaded.
+func NewTenantScopedDatastore(delegate datastore.Datastore, prefixes []string) datastore.Datastore {
+	return tenantDatastore{Datastore: delegate, prefixes: prefixes}
+}
+
+func (td tenantDatastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return tenantReader{td.Datastore.SnapshotReader(rev), td.prefixes}
+}
+
+func (td tenantDatastore) ReadWriteTx(
+	ctx context.Context,
+	f datastore.TxUserFunc,
+	opts ...options.RWTOptionsOption,
+) (datastore.Revision, error) {
+	return td.Datastore.ReadWriteTx(ctx, func(ctx context.Context, delegateRWT datastore.ReadWriteTransaction) error {
+		return f(ctx, tenantRWT{tenantReader{delegateRWT, td.prefixes}, delegateRWT})
+	}, opts...)
+}
+
+type tenantReader struct {
+	datastore.Reader
+	prefixes []string
+}
+
+func (tr tenantReader) ReadNamespaceByName(ctx context.Context, nsName string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	if !WithinTenant(tr.prefixes, nsName) {
+		return nil, datastore.NoRevision, datastore.NewNamespaceNotFoundErr(nsName)
+	}
+	return tr.Reader.ReadNamespaceByName(ctx, nsName)
+}
+
+func (tr tenantReader) ListAllNamespaces(ctx context.Context) ([]datastore.RevisionedNamespace, error) {
+	namespaces, err := tr.Reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := make([]datastore.RevisionedNamespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		if WithinTenant(tr.prefixes, ns.Definition.Name) {
+			scoped = append(scoped, ns)
+		}
+	}
+	return scoped, nil
+}
+
+func (tr tenantReader) LookupNamespacesWithNames(ctx context.Context, nsNames []string) ([]datastore.RevisionedNamespace, error) {
+	return tr.Reader.LookupNamespacesWithNames(ctx, tr.scoped(nsNames))
+}
+
+func (tr tenantReader) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	if !WithinTenant(tr.prefixes, name) {
+		return nil, datastore.NoRevision, datastore.NewCaveatNameNotFoundErr(name)
+	}
+	return tr.Reader.ReadCaveatByName(ctx, name)
+}
+
+func (tr tenantReader) ListAllCaveats(ctx context.Context) ([]datastore.RevisionedCaveat, error) {
+	caveats, err := tr.Reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := make([]datastore.RevisionedCaveat, 0, len(caveats))
+	for _, caveat := range caveats {
+		if WithinTenant(tr.prefixes, caveat.Definition.Name) {
+			scoped = append(scoped, caveat)
+		}
+	}
+	return scoped, nil
+}
+
+func (tr tenantReader) LookupCaveatsWithNames(ctx context.Context, names []string) ([]datastore.RevisionedCaveat, error) {
+	return tr.Reader.LookupCaveatsWithNames(ctx, tr.scoped(names))
+}
+
+func (tr tenantReader) QueryRelationships(
+	ctx context.Context,
+	filter datastore.RelationshipsFilter,
+	opts ...options.QueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	iter, err := tr.Reader.QueryRelationships(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantIterator{iter, tr.prefixes}, nil
+}
+
+func (tr tenantReader) ReverseQueryRelationships(
+	ctx context.Context,
+	subjectsFilter datastore.SubjectsFilter,
+	opts ...options.ReverseQueryOptionsOption,
+) (datastore.RelationshipIterator, error) {
+	iter, err := tr.Reader.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantIterator{iter, tr.prefixes}, nil
+}
+
+func (tr tenantReader) scoped(names []string) []string {
+	scoped := make([]string, 0, len(names))
+	for _, name := range names {
+		if WithinTenant(tr.prefixes, name) {
+			scoped = append(scoped, name)
+		}
+	}
+	return scoped
+}
+
+type tenantRWT struct {
+	tenantReader
+	delegate datastore.ReadWriteTransaction
+}
+
+func (rwt tenantRWT) WriteRelationships(ctx context.Context, mutations []*core.RelationTupleUpdate) error {
+	for _, mutation := range mutations {
+		if err := checkRelationship(rwt.prefixes, mutation.Tuple); err != nil {
+			return err
+		}
+	}
+	return rwt.delegate.WriteRelationships(ctx, mutations)
+}
+
+func (rwt tenantRWT) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, options ...options.DeleteOptionsOption) (bool, error) {
+	// The filter must have a type, so that relationships of any type are never deleted.
+	names := make([]string, 0, 2)
+	if filter.GetResourceType() != "" {
+		names = append(names, filter.GetResourceType())
+	}
+	if filter.GetOptionalSubjectFilter() != nil {
+		names = append(names, filter.GetOptionalSubjectFilter().GetSubjectType())
+	}
+	if len(names) == 0 {
+		return false, status.Errorf(codes.PermissionDenied, "relationship filters must have an object type for callers bound to object type prefixes")
+	}
+
+	for _, name := range names {
+		if !WithinTenant(rwt.prefixes, name) {
+			return false, NewOutsideTenantErr(name)
+		}
+	}
+	return rwt.delegate.DeleteRelationships(ctx, filter, options...)
+}
+
+func (rwt tenantRWT) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	return rwt.delegate.BulkLoad(ctx, tenantBulkSource{iter, rwt.prefixes})
+}
+
+func (rwt tenantRWT) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
+	for _, nsDef := range newConfigs {
+		if !WithinTenant(rwt.prefixes, nsDef.Name) {
+			return NewOutsideTenantErr(nsDef.Name)
+		}
+	}
+	return rwt.delegate.WriteNamespaces(ctx, newConfigs...)
+}
+
+func (rwt tenantRWT) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
+	for _, nsName := range nsNames {
+		if !WithinTenant(rwt.prefixes, nsName) {
+			return NewOutsideTenantErr(nsName)
+		}
+	}
+	return rwt.delegate.DeleteNamespaces(ctx, nsNames...)
+}
+
+func (rwt tenantRWT) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
+	for _, caveat := range caveats {
+		if !WithinTenant(rwt.prefixes, caveat.Name) {
+			return NewOutsideTenantErr(caveat.Name)
+		}
+	}
+	return rwt.delegate.WriteCaveats(ctx, caveats)
+}
+
+func (rwt tenantRWT) DeleteCaveats(ctx context.Context, names []string) error {
+	for _, name := range names {
+		if !WithinTenant(rwt.prefixes, name) {
+			return NewOutsideTenantErr(name)
+		}
+	}
+	return rwt.delegate.DeleteCaveats(ctx, names)
+}
+
+// checkRelationship returns an error if the resource type, subject type or caveat of the
+// relationship is outside of the prefixes.
+func checkRelationship(prefixes []string, rel *core.RelationTuple) error {
+	names := []string{rel.ResourceAndRelation.Namespace, rel.Subject.Namespace}
+	if rel.Caveat != nil {
+		names = append(names, rel.Caveat.CaveatName)
+	}
+
+	for _, name := range names {
+		if !WithinTenant(prefixes, name) {
+			return NewOutsideTenantErr(name)
+		}
+	}
+	return nil
+}
+
+// tenantIterator skips the relationships outside of the prefixes. Its cursor is that of the
+// delegate iterator, so that the skipped relationships are not read again.
+type tenantIterator struct {
+	datastore.RelationshipIterator
+	prefixes []string
+}
+
+func (ti *tenantIterator) Next() *core.RelationTuple {
+	for rel := ti.RelationshipIterator.Next(); rel != nil; rel = ti.RelationshipIterator.Next() {
+		if WithinTenant(ti.prefixes, rel.ResourceAndRelation.Namespace) && WithinTenant(ti.prefixes, rel.Subject.Namespace) {
+			return rel
+		}
+	}
+	return nil
+}
+
+// tenantBulkSource fails the load at the first relationship outside of the prefixes.
+type tenantBulkSource struct {
+	datastore.BulkWriteRelationshipSource
+	prefixes []string
+}
+
+func (tbs tenantBulkSource) Next(ctx context.Context) (*core.RelationTuple, error) {
+	rel, err := tbs.BulkWriteRelationshipSource.Next(ctx)
+	if err != nil || rel == nil {
+		return rel, err
+	}
+
+	if err := checkRelationship(tbs.prefixes, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
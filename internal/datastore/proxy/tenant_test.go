@@ -0,0 +1,168 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/caveats"
+	caveattypes "github.com/authzed/spicedb/pkg/caveats/types"
+	"github.com/authzed/spicedb/pkg/datastore"
+	ns "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func tenantCaveat(name string) *core.CaveatDefinition {
+	return ns.MustCaveatDefinition(caveats.MustEnvForVariables(
+		map[string]caveattypes.VariableType{
+			"somevar": caveattypes.IntType,
+		},
+	), name, "somevar < 42")
+}
+
+func TestWithinTenant(t *testing.T) {
+	require.True(t, WithinTenant([]string{"acme"}, "acme/document"))
+	require.True(t, WithinTenant([]string{"acme/"}, "acme/document"))
+	require.True(t, WithinTenant([]string{"other", "acme"}, "acme/nested/document"))
+	require.False(t, WithinTenant([]string{"acme"}, "acmecorp/document"))
+	require.False(t, WithinTenant([]string{"acme"}, "document"))
+	require.False(t, WithinTenant(nil, "acme/document"))
+}
+
+func TestTenantScopedDatastore(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	delegate, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	revision, err := delegate.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.WriteNamespaces(ctx, ns.Namespace("acme/document"), ns.Namespace("other/document")); err != nil {
+			return err
+		}
+		return rwt.WriteCaveats(ctx, []*core.CaveatDefinition{tenantCaveat("acme/somecaveat"), tenantCaveat("other/somecaveat")})
+	})
+	require.NoError(err)
+
+	ds := NewTenantScopedDatastore(delegate, []string{"acme"})
+	reader := ds.SnapshotReader(revision)
+
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	require.NoError(err)
+	require.Len(namespaces, 1)
+	require.Equal("acme/document", namespaces[0].Definition.Name)
+
+	namespaces, err = reader.LookupNamespacesWithNames(ctx, []string{"acme/document", "other/document"})
+	require.NoError(err)
+	require.Len(namespaces, 1)
+	require.Equal("acme/document", namespaces[0].Definition.Name)
+
+	_, _, err = reader.ReadNamespaceByName(ctx, "acme/document")
+	require.NoError(err)
+	_, _, err = reader.ReadNamespaceByName(ctx, "other/document")
+	require.ErrorAs(err, &datastore.ErrNamespaceNotFound{})
+
+	caveats, err := reader.ListAllCaveats(ctx)
+	require.NoError(err)
+	require.Len(caveats, 1)
+	require.Equal("acme/somecaveat", caveats[0].Definition.Name)
+
+	caveats, err = reader.LookupCaveatsWithNames(ctx, []string{"acme/somecaveat", "other/somecaveat"})
+	require.NoError(err)
+	require.Len(caveats, 1)
+
+	_, _, err = reader.ReadCaveatByName(ctx, "other/somecaveat")
+	require.ErrorAs(err, &datastore.ErrCaveatNameNotFound{})
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		namespaces, err := rwt.ListAllNamespaces(ctx)
+		require.NoError(err)
+		require.Len(namespaces, 1)
+
+		return rwt.WriteNamespaces(ctx, ns.Namespace("other/folder"))
+	})
+	require.ErrorAs(err, &ErrOutsideTenant{})
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.DeleteNamespaces(ctx, "other/document")
+	})
+	require.ErrorAs(err, &ErrOutsideTenant{})
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.DeleteCaveats(ctx, []string{"other/somecaveat"})
+	})
+	require.ErrorAs(err, &ErrOutsideTenant{})
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, ns.Namespace("acme/folder"))
+	})
+	require.NoError(err)
+}
+
+func TestTenantScopedDatastoreRelationships(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	delegate, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	revision, err := delegate.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("acme/document:masterplan#viewer@acme/user:tom")),
+			tuple.Create(tuple.MustParse("acme/document:masterplan#viewer@other/user:fred")),
+			tuple.Create(tuple.MustParse("other/document:masterplan#viewer@acme/user:tom")),
+		})
+	})
+	require.NoError(err)
+
+	ds := NewTenantScopedDatastore(delegate, []string{"acme"})
+	reader := ds.SnapshotReader(revision)
+
+	found, _ := collectRelationships(t, func() (datastore.RelationshipIterator, error) {
+		return reader.QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: "acme/document"})
+	})
+	require.Equal([]string{"acme/document:masterplan#viewer@acme/user:tom"}, found)
+
+	found, _ = collectRelationships(t, func() (datastore.RelationshipIterator, error) {
+		return reader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{SubjectType: "acme/user"})
+	})
+	require.Equal([]string{"acme/document:masterplan#viewer@acme/user:tom"}, found)
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Touch(tuple.MustParse("acme/document:masterplan#viewer@other/user:tom")),
+		})
+	})
+	require.ErrorAs(err, &ErrOutsideTenant{})
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: "other/document"})
+		return err
+	})
+	require.ErrorAs(err, &ErrOutsideTenant{})
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{OptionalRelation: "viewer"})
+		return err
+	})
+	require.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, testfixtures.NewBulkTupleGenerator("other/document", "viewer", "acme/user", 1, t))
+		return err
+	})
+	require.ErrorAs(err, &ErrOutsideTenant{})
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: "acme/document"})
+		return err
+	})
+	require.NoError(err)
+}
@@ -3,7 +3,6 @@ package spanner
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"cloud.google.com/go/spanner"
 	sq "github.com/Masterminds/squirrel"
@@ -11,6 +10,7 @@ import (
 	"github.com/jzelinskie/stringz"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
@@ -205,11 +205,7 @@ func applyFilterToQuery[T builder[T]](query T, filter *v1.RelationshipFilter) (T
 		query = query.Where(sq.Eq{colRelation: filter.OptionalRelation})
 	}
 	if filter.OptionalResourceIdPrefix != "" {
-		if strings.Contains(filter.OptionalResourceIdPrefix, "%") {
-			return query, fmt.Errorf("unable to delete relationships with a prefix containing the %% character")
-		}
-
-		query = query.Where(sq.Like{colObjectID: filter.OptionalResourceIdPrefix + "%"})
+		query = query.Where(sq.Like{colObjectID: common.LikePrefixPattern(filter.OptionalResourceIdPrefix)})
 	}
 
 	// Add clauses for the SubjectFilter
@@ -6,6 +6,7 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"cloud.google.com/go/spanner"
@@ -78,6 +79,7 @@ type spannerDatastore struct {
 	client   *spanner.Client
 	config   spannerOptions
 	database string
+	uniqueID *atomic.Pointer[string]
 }
 
 // NewSpannerDatastore returns a datastore backed by cloud spanner
@@ -156,6 +158,7 @@ func NewSpannerDatastore(ctx context.Context, database string, opts ...Option) (
 		client:                  client,
 		config:                  config,
 		database:                database,
+		uniqueID:                &atomic.Pointer[string]{},
 		watchBufferWriteTimeout: config.watchBufferWriteTimeout,
 		watchBufferLength:       config.watchBufferLength,
 	}
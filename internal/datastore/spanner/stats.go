@@ -20,17 +20,31 @@ var (
 	rng = rand.NewSource(time.Now().UnixNano())
 )
 
-func (sd spannerDatastore) Statistics(ctx context.Context) (datastore.Stats, error) {
+func (sd spannerDatastore) UniqueID(ctx context.Context) (string, error) {
+	if cached := sd.uniqueID.Load(); cached != nil {
+		return *cached, nil
+	}
+
 	var uniqueID string
 	if err := sd.client.Single().Read(
-		context.Background(),
+		ctx,
 		tableMetadata,
 		spanner.AllKeys(),
 		[]string{colUniqueID},
 	).Do(func(r *spanner.Row) error {
 		return r.Columns(&uniqueID)
 	}); err != nil {
-		return datastore.Stats{}, fmt.Errorf("unable to read unique ID: %w", err)
+		return "", fmt.Errorf("unable to read unique ID: %w", err)
+	}
+
+	sd.uniqueID.Store(&uniqueID)
+	return uniqueID, nil
+}
+
+func (sd spannerDatastore) Statistics(ctx context.Context) (datastore.Stats, error) {
+	uniqueID, err := sd.UniqueID(ctx)
+	if err != nil {
+		return datastore.Stats{}, err
 	}
 
 	iter := sd.client.Single().Read(
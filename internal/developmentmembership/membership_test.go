@@ -10,7 +10,7 @@ import (
 
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 
-	"github.com/authzed/spicedb/pkg/graph"
+	"github.com/authzed/spicedb/pkg/graph/tree"
 	"github.com/authzed/spicedb/pkg/testutil"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
@@ -36,39 +36,39 @@ func CaveatedDS(objectType string, objectID string, objectRelation string, cavea
 var (
 	_this *core.ObjectAndRelation
 
-	companyOwner = graph.Leaf(ONR("folder", "company", "owner"),
+	companyOwner = tree.Leaf(ONR("folder", "company", "owner"),
 		(DS("user", "owner", Ellipsis)),
 	)
-	companyEditor = graph.Union(ONR("folder", "company", "editor"),
-		graph.Leaf(_this, (DS("user", "writer", Ellipsis))),
+	companyEditor = tree.Union(ONR("folder", "company", "editor"),
+		tree.Leaf(_this, (DS("user", "writer", Ellipsis))),
 		companyOwner,
 	)
 
-	auditorsOwner = graph.Leaf(ONR("folder", "auditors", "owner"))
+	auditorsOwner = tree.Leaf(ONR("folder", "auditors", "owner"))
 
-	auditorsEditor = graph.Union(ONR("folder", "auditors", "editor"),
-		graph.Leaf(_this),
+	auditorsEditor = tree.Union(ONR("folder", "auditors", "editor"),
+		tree.Leaf(_this),
 		auditorsOwner,
 	)
 
-	auditorsViewerRecursive = graph.Union(ONR("folder", "auditors", "viewer"),
-		graph.Leaf(_this,
+	auditorsViewerRecursive = tree.Union(ONR("folder", "auditors", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "auditor", "...")),
 		),
 		auditorsEditor,
-		graph.Union(ONR("folder", "auditors", "viewer")),
+		tree.Union(ONR("folder", "auditors", "viewer")),
 	)
 
-	companyViewerRecursive = graph.Union(ONR("folder", "company", "viewer"),
-		graph.Union(ONR("folder", "company", "viewer"),
+	companyViewerRecursive = tree.Union(ONR("folder", "company", "viewer"),
+		tree.Union(ONR("folder", "company", "viewer"),
 			auditorsViewerRecursive,
-			graph.Leaf(_this,
+			tree.Leaf(_this,
 				(DS("user", "legal", "...")),
 				(DS("folder", "auditors", "viewer")),
 			),
 		),
 		companyEditor,
-		graph.Union(ONR("folder", "company", "viewer")),
+		tree.Union(ONR("folder", "company", "viewer")),
 	)
 )
 
@@ -97,11 +97,11 @@ func TestMembershipSetIntersectionBasic(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "legal", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 		),
@@ -117,12 +117,12 @@ func TestMembershipSetIntersectionWithDifferentTypesOneMissingLeft(t *testing.T)
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "legal", "...")),
 			(DS("folder", "foobar", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 		),
@@ -138,11 +138,11 @@ func TestMembershipSetIntersectionWithDifferentTypesOneMissingRight(t *testing.T
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "legal", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 			(DS("folder", "foobar", "...")),
@@ -159,13 +159,13 @@ func TestMembershipSetIntersectionWithDifferentTypes(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "legal", "...")),
 			(DS("folder", "foobar", "...")),
 			(DS("folder", "barbaz", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 			(DS("folder", "barbaz", "...")),
@@ -182,12 +182,12 @@ func TestMembershipSetExclusion(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	exclusion := graph.Exclusion(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	exclusion := tree.Exclusion(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "legal", "...")),
 		),
 	)
@@ -202,16 +202,16 @@ func TestMembershipSetExclusionMultiple(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	exclusion := graph.Exclusion(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	exclusion := tree.Exclusion(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 			(DS("user", "third", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "legal", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 		),
 	)
@@ -226,15 +226,15 @@ func TestMembershipSetExclusionMultipleWithWildcard(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	exclusion := graph.Exclusion(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	exclusion := tree.Exclusion(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "legal", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "*", "...")),
 		),
 	)
@@ -249,16 +249,16 @@ func TestMembershipSetExclusionMultipleMiddle(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	exclusion := graph.Exclusion(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	exclusion := tree.Exclusion(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 			(DS("user", "third", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "another", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 		),
 	)
@@ -273,12 +273,12 @@ func TestMembershipSetIntersectionWithOneWildcard(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "*", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "legal", "...")),
 		),
 	)
@@ -293,12 +293,12 @@ func TestMembershipSetIntersectionWithAllWildcardLeft(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "*", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "*", "...")),
 		),
 	)
@@ -313,11 +313,11 @@ func TestMembershipSetIntersectionWithAllWildcardRight(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "*", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "*", "...")),
 		),
@@ -333,12 +333,12 @@ func TestMembershipSetExclusionWithLeftWildcard(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	exclusion := graph.Exclusion(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	exclusion := tree.Exclusion(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "*", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "legal", "...")),
 		),
 	)
@@ -353,12 +353,12 @@ func TestMembershipSetExclusionWithRightWildcard(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	exclusion := graph.Exclusion(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	exclusion := tree.Exclusion(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "*", "...")),
 		),
 	)
@@ -373,15 +373,15 @@ func TestMembershipSetIntersectionWithThreeWildcards(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "*", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "*", "...")),
 		),
 	)
@@ -396,16 +396,16 @@ func TestMembershipSetIntersectionWithOneBranchMissingWildcards(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 			(DS("user", "*", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "*", "...")),
 		),
 	)
@@ -420,15 +420,15 @@ func TestMembershipSetIntersectionWithTwoBranchesMissingWildcards(t *testing.T)
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "another", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "*", "...")),
 		),
 	)
@@ -443,16 +443,16 @@ func TestMembershipSetWithCaveats(t *testing.T) {
 	require := require.New(t)
 	ms := NewMembershipSet()
 
-	intersection := graph.Intersection(ONR("folder", "company", "viewer"),
-		graph.Leaf(_this,
+	intersection := tree.Intersection(ONR("folder", "company", "viewer"),
+		tree.Leaf(_this,
 			(DS("user", "owner", "...")),
 			(DS("user", "legal", "...")),
 			(DS("user", "*", "...")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(CaveatedDS("user", "owner", "...", "somecaveat")),
 		),
-		graph.Leaf(_this,
+		tree.Leaf(_this,
 			(DS("user", "*", "...")),
 		),
 	)
@@ -7,6 +7,7 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch/caching"
 	"github.com/authzed/spicedb/internal/dispatch/graph"
 	"github.com/authzed/spicedb/internal/dispatch/keys"
+	"github.com/authzed/spicedb/internal/dispatch/slowlog"
 	"github.com/authzed/spicedb/pkg/cache"
 )
 
@@ -17,8 +18,9 @@ type optionState struct {
 	metricsEnabled        bool
 	prometheusSubsystem   string
 	cache                 cache.Cache
-	concurrencyLimits     graph.ConcurrencyLimits
+	concurrencyLimits     *graph.ConcurrencyLimitsHolder
 	remoteDispatchTimeout time.Duration
+	slowDispatchThreshold time.Duration
 }
 
 // MetricsEnabled enables issuing prometheus metrics
@@ -44,6 +46,14 @@ func Cache(c cache.Cache) Option {
 
 // ConcurrencyLimits sets the max number of goroutines per operation
 func ConcurrencyLimits(limits graph.ConcurrencyLimits) Option {
+	return func(state *optionState) {
+		state.concurrencyLimits = graph.NewConcurrencyLimitsHolder(limits)
+	}
+}
+
+// ConcurrencyLimitsHolder sets the holder of the max number of goroutines per
+// operation, through which the limits can be changed while the dispatcher runs.
+func ConcurrencyLimitsHolder(limits *graph.ConcurrencyLimitsHolder) Option {
 	return func(state *optionState) {
 		state.concurrencyLimits = limits
 	}
@@ -57,6 +67,14 @@ func RemoteDispatchTimeout(remoteDispatchTimeout time.Duration) Option {
 	}
 }
 
+// SlowDispatchThreshold sets the duration above which dispatched subproblems
+// are logged. Disabled if zero.
+func SlowDispatchThreshold(threshold time.Duration) Option {
+	return func(state *optionState) {
+		state.slowDispatchThreshold = threshold
+	}
+}
+
 // NewClusterDispatcher takes a dispatcher (such as one created by
 // combined.NewDispatcher) and returns a cluster dispatcher suitable for use as
 // the dispatcher for the dispatch grpc server.
@@ -66,7 +84,14 @@ func NewClusterDispatcher(dispatch dispatch.Dispatcher, options ...Option) (disp
 		fn(&opts)
 	}
 
-	clusterDispatch := graph.NewDispatcher(dispatch, opts.concurrencyLimits)
+	if opts.concurrencyLimits == nil {
+		opts.concurrencyLimits = graph.NewConcurrencyLimitsHolder(graph.ConcurrencyLimits{})
+	}
+
+	clusterDispatch := graph.NewDispatcherWithLimitsHolder(dispatch, opts.concurrencyLimits)
+	if opts.slowDispatchThreshold > 0 {
+		clusterDispatch = slowlog.New(clusterDispatch, opts.slowDispatchThreshold)
+	}
 
 	if opts.prometheusSubsystem == "" {
 		opts.prometheusSubsystem = "dispatch"
@@ -3,11 +3,14 @@
 package combined
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"time"
 
 	"github.com/authzed/grpcutil"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/authzed/spicedb/internal/dispatch"
@@ -16,9 +19,11 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch/keys"
 	"github.com/authzed/spicedb/internal/dispatch/remote"
 	"github.com/authzed/spicedb/internal/dispatch/singleflight"
+	"github.com/authzed/spicedb/internal/dispatch/slowlog"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/cache"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/x509util"
 )
 
 // Option is a function-style option for configuring a combined Dispatcher.
@@ -29,11 +34,14 @@ type optionState struct {
 	prometheusSubsystem    string
 	upstreamAddr           string
 	upstreamCAPath         string
+	upstreamTLSCertPath    string
+	upstreamTLSKeyPath     string
 	grpcPresharedKey       string
 	grpcDialOpts           []grpc.DialOption
 	cache                  cache.Cache
-	concurrencyLimits      graph.ConcurrencyLimits
+	concurrencyLimits      *graph.ConcurrencyLimitsHolder
 	remoteDispatchTimeout  time.Duration
+	slowDispatchThreshold  time.Duration
 	secondaryUpstreamAddrs map[string]string
 	secondaryUpstreamExprs map[string]string
 }
@@ -67,6 +75,22 @@ func UpstreamCAPath(path string) Option {
 	}
 }
 
+// UpstreamTLSCertPath sets the optional client certificate presented to the
+// cluster dispatching upstream for mutual TLS.
+func UpstreamTLSCertPath(path string) Option {
+	return func(state *optionState) {
+		state.upstreamTLSCertPath = path
+	}
+}
+
+// UpstreamTLSKeyPath sets the optional key for the client certificate
+// presented to the cluster dispatching upstream for mutual TLS.
+func UpstreamTLSKeyPath(path string) Option {
+	return func(state *optionState) {
+		state.upstreamTLSKeyPath = path
+	}
+}
+
 // SecondaryUpstreamAddrs sets a named map of upstream addresses for secondary
 // dispatching.
 func SecondaryUpstreamAddrs(addrs map[string]string) Option {
@@ -109,6 +133,14 @@ func Cache(c cache.Cache) Option {
 
 // ConcurrencyLimits sets the max number of goroutines per operation
 func ConcurrencyLimits(limits graph.ConcurrencyLimits) Option {
+	return func(state *optionState) {
+		state.concurrencyLimits = graph.NewConcurrencyLimitsHolder(limits)
+	}
+}
+
+// ConcurrencyLimitsHolder sets the holder of the max number of goroutines per
+// operation, through which the limits can be changed while the dispatcher runs.
+func ConcurrencyLimitsHolder(limits *graph.ConcurrencyLimitsHolder) Option {
 	return func(state *optionState) {
 		state.concurrencyLimits = limits
 	}
@@ -122,6 +154,14 @@ func RemoteDispatchTimeout(remoteDispatchTimeout time.Duration) Option {
 	}
 }
 
+// SlowDispatchThreshold sets the duration above which dispatched subproblems
+// are logged. Disabled if zero.
+func SlowDispatchThreshold(threshold time.Duration) Option {
+	return func(state *optionState) {
+		state.slowDispatchThreshold = threshold
+	}
+}
+
 // NewDispatcher initializes a Dispatcher that caches and redispatches
 // optionally to the provided upstream.
 func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
@@ -140,19 +180,34 @@ func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
 		return nil, err
 	}
 
-	redispatch := graph.NewDispatcher(cachingRedispatch, opts.concurrencyLimits)
+	if opts.concurrencyLimits == nil {
+		opts.concurrencyLimits = graph.NewConcurrencyLimitsHolder(graph.ConcurrencyLimits{})
+	}
+
+	redispatch := graph.NewDispatcherWithLimitsHolder(cachingRedispatch, opts.concurrencyLimits)
+	if opts.slowDispatchThreshold > 0 {
+		redispatch = slowlog.New(redispatch, opts.slowDispatchThreshold)
+	}
 	redispatch = singleflight.New(redispatch, &keys.CanonicalKeyHandler{})
 
 	// If an upstream is specified, create a cluster dispatcher.
 	if opts.upstreamAddr != "" {
-		if opts.upstreamCAPath != "" {
+		switch {
+		case opts.upstreamTLSCertPath != "" || opts.upstreamTLSKeyPath != "":
+			creds, err := mutualTLSCredentials(opts.upstreamCAPath, opts.upstreamTLSCertPath, opts.upstreamTLSKeyPath)
+			if err != nil {
+				return nil, err
+			}
+			opts.grpcDialOpts = append(opts.grpcDialOpts, grpc.WithTransportCredentials(creds))
+			opts.grpcDialOpts = append(opts.grpcDialOpts, grpcutil.WithBearerToken(opts.grpcPresharedKey))
+		case opts.upstreamCAPath != "":
 			customCertOpt, err := grpcutil.WithCustomCerts(grpcutil.VerifyCA, opts.upstreamCAPath)
 			if err != nil {
 				return nil, err
 			}
 			opts.grpcDialOpts = append(opts.grpcDialOpts, customCertOpt)
 			opts.grpcDialOpts = append(opts.grpcDialOpts, grpcutil.WithBearerToken(opts.grpcPresharedKey))
-		} else {
+		default:
 			opts.grpcDialOpts = append(opts.grpcDialOpts, grpcutil.WithInsecureBearerToken(opts.grpcPresharedKey))
 			opts.grpcDialOpts = append(opts.grpcDialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		}
@@ -196,3 +251,40 @@ func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
 
 	return cachingRedispatch, nil
 }
+
+// mutualTLSCredentials returns transport credentials that present the given client certificate
+// to the upstream. The certificate is reloaded from disk for each new connection, so that rotated
+// certificates are picked up without a restart.
+func mutualTLSCredentials(caPath, certPath, keyPath string) (credentials.TransportCredentials, error) {
+	if certPath == "" || keyPath == "" {
+		return nil, fmt.Errorf("both a certificate and key are required for mutual TLS with the dispatch upstream")
+	}
+
+	// Fail fast on an invalid key pair, rather than on the first dispatch.
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		return nil, fmt.Errorf("failed to load dispatch upstream client certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	var err error
+	if caPath != "" {
+		pool, err = x509util.CustomCertPool(caPath)
+	} else {
+		pool, err = x509.SystemCertPool()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dispatch upstream CA: %w", err)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+			if err != nil {
+				return nil, err
+			}
+			return &cert, nil
+		},
+	}), nil
+}
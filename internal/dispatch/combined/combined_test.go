@@ -2,16 +2,25 @@ package combined
 
 import (
 	"context"
+	"crypto/tls"
+	"net"
+	"path/filepath"
 	"testing"
+	"time"
 
+	_ "github.com/mostynb/go-grpc-compression/experimental/s2"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testutil"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/x509util"
 )
 
 func TestCombinedRecursiveCall(t *testing.T) {
@@ -58,3 +67,85 @@ func TestCombinedRecursiveCall(t *testing.T) {
 	require.Error(t, err)
 	require.ErrorContains(t, err, "max depth exceeded")
 }
+
+type fakeDispatchServer struct {
+	dispatchv1.UnimplementedDispatchServiceServer
+}
+
+func (fakeDispatchServer) DispatchCheck(context.Context, *dispatchv1.DispatchCheckRequest) (*dispatchv1.DispatchCheckResponse, error) {
+	return &dispatchv1.DispatchCheckResponse{Metadata: &dispatchv1.ResponseMeta{DispatchCount: 1}}, nil
+}
+
+func TestCombinedMutualTLSUpstream(t *testing.T) {
+	certDir := t.TempDir()
+	ca, caKey := testutil.WriteTestCertificate(t, certDir, "ca", nil, nil)
+	testutil.WriteTestCertificate(t, certDir, "server", ca, caKey, "127.0.0.1")
+	testutil.WriteTestCertificate(t, certDir, "client", ca, caKey, "127.0.0.1")
+	otherCA, otherCAKey := testutil.WriteTestCertificate(t, certDir, "otherca", nil, nil)
+	testutil.WriteTestCertificate(t, certDir, "other", otherCA, otherCAKey, "127.0.0.1")
+
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(certDir, "server.crt"), filepath.Join(certDir, "server.key"))
+	require.NoError(t, err)
+	pool, err := x509util.CustomCertPool(filepath.Join(certDir, "ca.crt"))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})))
+	dispatchv1.RegisterDispatchServiceServer(srv, fakeDispatchServer{})
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition resource {
+			relation viewer: user
+			permission view = viewer
+		}
+	`, nil, require.New(t))
+
+	checkWithClientCert := func(name string) error {
+		dispatcher, err := NewDispatcher(
+			UpstreamAddr(listener.Addr().String()),
+			UpstreamCAPath(filepath.Join(certDir, "ca.crt")),
+			UpstreamTLSCertPath(filepath.Join(certDir, name+".crt")),
+			UpstreamTLSKeyPath(filepath.Join(certDir, name+".key")),
+			GrpcPresharedKey("somekey"),
+		)
+		require.NoError(t, err)
+		defer dispatcher.Close()
+
+		ctx, cancel := context.WithTimeout(datastoremw.ContextWithHandle(context.Background()), 10*time.Second)
+		defer cancel()
+		require.NoError(t, datastoremw.SetInContext(ctx, ds))
+
+		_, err = dispatcher.DispatchCheck(ctx, &dispatchv1.DispatchCheckRequest{
+			ResourceRelation: &core.RelationReference{Namespace: "resource", Relation: "view"},
+			ResourceIds:      []string{"someresource"},
+			Subject:          &core.ObjectAndRelation{Namespace: "user", ObjectId: "fred", Relation: tuple.Ellipsis},
+			Metadata:         &dispatchv1.ResolverMeta{AtRevision: revision.String(), DepthRemaining: 50},
+		})
+		return err
+	}
+
+	require.NoError(t, checkWithClientCert("client"))
+	require.Error(t, checkWithClientCert("other"))
+}
+
+func TestCombinedMutualTLSRequiresKey(t *testing.T) {
+	_, err := NewDispatcher(
+		UpstreamAddr("localhost:50053"),
+		UpstreamTLSCertPath("some/client.crt"),
+	)
+	require.ErrorContains(t, err, "both a certificate and key are required")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
@@ -66,6 +67,32 @@ func limitOrDefault(limit uint16, defaultLimit uint16) uint16 {
 	return limit
 }
 
+// ConcurrencyLimitsHolder holds the ConcurrencyLimits of dispatchers, which can be changed while
+// they are in use. It is safe for concurrent use.
+type ConcurrencyLimitsHolder struct {
+	current atomic.Pointer[ConcurrencyLimits]
+}
+
+// NewConcurrencyLimitsHolder creates a holder of the given limits. Zero limits are replaced by
+// their defaults.
+func NewConcurrencyLimitsHolder(limits ConcurrencyLimits) *ConcurrencyLimitsHolder {
+	holder := &ConcurrencyLimitsHolder{}
+	holder.Store(limits)
+	return holder
+}
+
+// Load returns the current limits.
+func (h *ConcurrencyLimitsHolder) Load() ConcurrencyLimits {
+	return *h.current.Load()
+}
+
+// Store replaces the current limits, which apply to the dispatches started from then on. Zero
+// limits are replaced by their defaults.
+func (h *ConcurrencyLimitsHolder) Store(limits ConcurrencyLimits) {
+	withDefaults := limitsOrDefaults(limits, defaultConcurrencyLimit)
+	h.current.Store(&withDefaults)
+}
+
 // SharedConcurrencyLimits returns a ConcurrencyLimits struct with the limit
 // set to that provided for each operation.
 func SharedConcurrencyLimits(concurrencyLimit uint16) ConcurrencyLimits {
@@ -85,40 +112,33 @@ func NewLocalOnlyDispatcher(concurrencyLimit uint16) dispatch.Dispatcher {
 // NewLocalOnlyDispatcherWithLimits creates a dispatcher thatg consults with the graph to formulate a response
 // and has the defined concurrency limits per dispatch type.
 func NewLocalOnlyDispatcherWithLimits(concurrencyLimits ConcurrencyLimits) dispatch.Dispatcher {
-	d := &localDispatcher{}
-
-	concurrencyLimits = limitsOrDefaults(concurrencyLimits, defaultConcurrencyLimit)
-
-	d.checker = graph.NewConcurrentChecker(d, concurrencyLimits.Check)
-	d.expander = graph.NewConcurrentExpander(d)
-	d.reachableResourcesHandler = graph.NewCursoredReachableResources(d, concurrencyLimits.ReachableResources)
-	d.lookupResourcesHandler = graph.NewCursoredLookupResources(d, d, concurrencyLimits.LookupResources)
-	d.lookupSubjectsHandler = graph.NewConcurrentLookupSubjects(d, concurrencyLimits.LookupSubjects)
-
+	d := &localDispatcher{limits: NewConcurrencyLimitsHolder(concurrencyLimits)}
+	d.redispatcher = d
 	return d
 }
 
 // NewDispatcher creates a dispatcher that consults with the graph and redispatches subproblems to
 // the provided redispatcher.
 func NewDispatcher(redispatcher dispatch.Dispatcher, concurrencyLimits ConcurrencyLimits) dispatch.Dispatcher {
-	concurrencyLimits = limitsOrDefaults(concurrencyLimits, defaultConcurrencyLimit)
-
-	checker := graph.NewConcurrentChecker(redispatcher, concurrencyLimits.Check)
-	expander := graph.NewConcurrentExpander(redispatcher)
-	reachableResourcesHandler := graph.NewCursoredReachableResources(redispatcher, concurrencyLimits.ReachableResources)
-	lookupResourcesHandler := graph.NewCursoredLookupResources(redispatcher, redispatcher, concurrencyLimits.LookupResources)
-	lookupSubjectsHandler := graph.NewConcurrentLookupSubjects(redispatcher, concurrencyLimits.LookupSubjects)
-
-	return &localDispatcher{
-		checker:                   checker,
-		expander:                  expander,
-		reachableResourcesHandler: reachableResourcesHandler,
-		lookupResourcesHandler:    lookupResourcesHandler,
-		lookupSubjectsHandler:     lookupSubjectsHandler,
-	}
+	return NewDispatcherWithLimitsHolder(redispatcher, NewConcurrencyLimitsHolder(concurrencyLimits))
+}
+
+// NewDispatcherWithLimitsHolder creates a dispatcher that consults with the graph and redispatches
+// subproblems to the provided redispatcher, with the concurrency limits currently in the holder.
+func NewDispatcherWithLimitsHolder(redispatcher dispatch.Dispatcher, concurrencyLimits *ConcurrencyLimitsHolder) dispatch.Dispatcher {
+	return &localDispatcher{redispatcher: redispatcher, limits: concurrencyLimits}
 }
 
 type localDispatcher struct {
+	redispatcher dispatch.Dispatcher
+	limits       *ConcurrencyLimitsHolder
+	current      atomic.Pointer[dispatchHandlers]
+}
+
+// dispatchHandlers are the handlers of each dispatch type, which are created with the
+// concurrency limits in effect.
+type dispatchHandlers struct {
+	limits                    *ConcurrencyLimits
 	checker                   *graph.ConcurrentChecker
 	expander                  *graph.ConcurrentExpander
 	reachableResourcesHandler *graph.CursoredReachableResources
@@ -126,6 +146,26 @@ type localDispatcher struct {
 	lookupSubjectsHandler     *graph.ConcurrentLookupSubjects
 }
 
+// handlers returns the handlers for the current concurrency limits, creating them anew if the
+// limits were changed.
+func (ld *localDispatcher) handlers() *dispatchHandlers {
+	limits := ld.limits.current.Load()
+	if current := ld.current.Load(); current != nil && current.limits == limits {
+		return current
+	}
+
+	updated := &dispatchHandlers{
+		limits:                    limits,
+		checker:                   graph.NewConcurrentChecker(ld.redispatcher, limits.Check),
+		expander:                  graph.NewConcurrentExpander(ld.redispatcher),
+		reachableResourcesHandler: graph.NewCursoredReachableResources(ld.redispatcher, limits.ReachableResources),
+		lookupResourcesHandler:    graph.NewCursoredLookupResources(ld.redispatcher, ld.redispatcher, limits.LookupResources),
+		lookupSubjectsHandler:     graph.NewConcurrentLookupSubjects(ld.redispatcher, limits.LookupSubjects),
+	}
+	ld.current.Store(updated)
+	return updated
+}
+
 func (ld *localDispatcher) loadNamespace(ctx context.Context, nsName string, revision datastore.Revision) (*core.NamespaceDefinition, error) {
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(revision)
 
@@ -232,11 +272,11 @@ func (ld *localDispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCh
 			Revision: revision,
 		}
 
-		resp, err := ld.checker.Check(ctx, validatedReq, relation)
+		resp, err := ld.handlers().checker.Check(ctx, validatedReq, relation)
 		return resp, rewriteError(ctx, err)
 	}
 
-	resp, err := ld.checker.Check(ctx, graph.ValidatedCheckRequest{
+	resp, err := ld.handlers().checker.Check(ctx, graph.ValidatedCheckRequest{
 		DispatchCheckRequest: req,
 		Revision:             revision,
 	}, relation)
@@ -269,7 +309,7 @@ func (ld *localDispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchE
 		return &v1.DispatchExpandResponse{Metadata: emptyMetadata}, err
 	}
 
-	return ld.expander.Expand(ctx, graph.ValidatedExpandRequest{
+	return ld.handlers().expander.Expand(ctx, graph.ValidatedExpandRequest{
 		DispatchExpandRequest: req,
 		Revision:              revision,
 	}, relation)
@@ -299,7 +339,7 @@ func (ld *localDispatcher) DispatchReachableResources(
 		return err
 	}
 
-	return ld.reachableResourcesHandler.ReachableResources(
+	return ld.handlers().reachableResourcesHandler.ReachableResources(
 		graph.ValidatedReachableResourcesRequest{
 			DispatchReachableResourcesRequest: req,
 			Revision:                          revision,
@@ -328,7 +368,7 @@ func (ld *localDispatcher) DispatchLookupResources(
 		return err
 	}
 
-	return ld.lookupResourcesHandler.LookupResources(
+	return ld.handlers().lookupResourcesHandler.LookupResources(
 		graph.ValidatedLookupResourcesRequest{
 			DispatchLookupResourcesRequest: req,
 			Revision:                       revision,
@@ -362,7 +402,7 @@ func (ld *localDispatcher) DispatchLookupSubjects(
 		return err
 	}
 
-	return ld.lookupSubjectsHandler.LookupSubjects(
+	return ld.handlers().lookupSubjectsHandler.LookupSubjects(
 		graph.ValidatedLookupSubjectsRequest{
 			DispatchLookupSubjectsRequest: req,
 			Revision:                      revision,
@@ -56,3 +56,20 @@ func TestSharedConcurrencyLimits(t *testing.T) {
 	require.Equal(t, uint16(42), withDefaults.LookupSubjects)
 	require.Equal(t, uint16(42), withDefaults.ReachableResources)
 }
+
+func TestConcurrencyLimitsHolderReloadsHandlers(t *testing.T) {
+	holder := NewConcurrencyLimitsHolder(ConcurrencyLimits{Check: 10})
+	require.Equal(t, ConcurrencyLimits{Check: 10, LookupResources: 50, LookupSubjects: 50, ReachableResources: 50}, holder.Load())
+
+	ld := NewDispatcherWithLimitsHolder(nil, holder).(*localDispatcher)
+	first := ld.handlers()
+	require.Same(t, first, ld.handlers())
+
+	holder.Store(SharedConcurrencyLimits(5))
+	require.Equal(t, SharedConcurrencyLimits(5), holder.Load())
+
+	reloaded := ld.handlers()
+	require.NotSame(t, first, reloaded)
+	require.Equal(t, SharedConcurrencyLimits(5), *reloaded.limits)
+	require.Same(t, reloaded, ld.handlers())
+}
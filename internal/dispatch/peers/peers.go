@@ -0,0 +1,299 @@
+// Package peers observes the consistent hashring balancer used to dispatch to the other nodes of
+// the cluster, to report the members of each ring along with the health of every peer.
+package peers
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/authzed/consistent"
+	"github.com/authzed/consistent/hashring"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/resolver"
+)
+
+// Ring is the state of a hashring balancing the dispatches to a target.
+type Ring struct {
+	Target            string   `json:"target"`
+	ReplicationFactor uint16   `json:"replicationFactor"`
+	Members           []Member `json:"members"`
+}
+
+// Member is the state of a peer of a ring, as observed by this node.
+type Member struct {
+	// Key is the key under which the peer is placed on the ring.
+	Key string `json:"key"`
+
+	// State is the connectivity state of the connection to the peer.
+	State string `json:"state"`
+
+	// Ownership is the fraction of the hash space owned by the peer.
+	Ownership float64 `json:"ownership"`
+
+	// Dispatches and Failures count the dispatches to the peer since the start of the process.
+	Dispatches uint64 `json:"dispatches"`
+	Failures   uint64 `json:"failures"`
+
+	// MeanLatencyMillis and LastLatencyMillis are the latencies of the dispatches to the peer.
+	MeanLatencyMillis float64 `json:"meanLatencyMillis"`
+	LastLatencyMillis float64 `json:"lastLatencyMillis"`
+}
+
+// Builder is a consistent hashring balancer builder which records the state of the balancers it
+// builds. It is registered with gRPC in place of the builder of the consistent package.
+type Builder struct {
+	consistent.Builder
+	hashfn hashring.HashFunc
+
+	lock      sync.Mutex
+	balancers map[*observingBalancer]struct{}
+}
+
+var _ consistent.Builder = (*Builder)(nil)
+
+// NewBuilder returns a Builder of consistent hashring balancers using the hash function.
+func NewBuilder(hashfn hashring.HashFunc) *Builder {
+	return &Builder{
+		Builder:   consistent.NewBuilder(hashfn),
+		hashfn:    hashfn,
+		balancers: make(map[*observingBalancer]struct{}),
+	}
+}
+
+func (b *Builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	ob := &observingBalancer{
+		builder: b,
+		target:  opts.Target.String(),
+		peers:   make(map[balancer.SubConn]*peer),
+	}
+	ob.Balancer = b.Builder.Build(&observingClientConn{cc, ob}, opts)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.balancers[ob] = struct{}{}
+	return ob
+}
+
+// Rings returns the state of the rings of every balancer, ordered by target.
+func (b *Builder) Rings() []Ring {
+	b.lock.Lock()
+	balancers := make([]*observingBalancer, 0, len(b.balancers))
+	for ob := range b.balancers {
+		balancers = append(balancers, ob)
+	}
+	b.lock.Unlock()
+
+	rings := make([]Ring, 0, len(balancers))
+	for _, ob := range balancers {
+		rings = append(rings, ob.ring(b.hashfn))
+	}
+	sort.Slice(rings, func(i, j int) bool { return rings[i].Target < rings[j].Target })
+	return rings
+}
+
+type peer struct {
+	key          string
+	state        connectivity.State
+	dispatches   uint64
+	failures     uint64
+	totalLatency time.Duration
+	lastLatency  time.Duration
+}
+
+// observingBalancer records the peers of the balancer it wraps, and the dispatches made to them.
+type observingBalancer struct {
+	balancer.Balancer
+	builder *Builder
+	target  string
+
+	lock              sync.Mutex
+	replicationFactor uint16
+	peers             map[balancer.SubConn]*peer
+}
+
+func (b *observingBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	if config, ok := s.BalancerConfig.(*consistent.BalancerConfig); ok {
+		b.lock.Lock()
+		b.replicationFactor = config.ReplicationFactor
+		b.lock.Unlock()
+	}
+	return b.Balancer.UpdateClientConnState(s)
+}
+
+func (b *observingBalancer) UpdateSubConnState(sc balancer.SubConn, state balancer.SubConnState) {
+	b.lock.Lock()
+	if p, ok := b.peers[sc]; ok {
+		p.state = state.ConnectivityState
+	}
+	b.lock.Unlock()
+
+	b.Balancer.UpdateSubConnState(sc, state)
+}
+
+func (b *observingBalancer) Close() {
+	b.builder.lock.Lock()
+	delete(b.builder.balancers, b)
+	b.builder.lock.Unlock()
+
+	b.Balancer.Close()
+}
+
+func (b *observingBalancer) recordDispatch(sc balancer.SubConn, latency time.Duration, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	p, ok := b.peers[sc]
+	if !ok {
+		return
+	}
+
+	p.dispatches++
+	if err != nil {
+		p.failures++
+	}
+	p.totalLatency += latency
+	p.lastLatency = latency
+}
+
+func (b *observingBalancer) ring(hashfn hashring.HashFunc) Ring {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	keys := make([]string, 0, len(b.peers))
+	for _, p := range b.peers {
+		keys = append(keys, p.key)
+	}
+	owned := ownership(hashfn, b.replicationFactor, keys)
+
+	members := make([]Member, 0, len(b.peers))
+	for _, p := range b.peers {
+		member := Member{
+			Key:               p.key,
+			State:             p.state.String(),
+			Ownership:         owned[p.key],
+			Dispatches:        p.dispatches,
+			Failures:          p.failures,
+			LastLatencyMillis: millis(p.lastLatency),
+		}
+		if p.dispatches > 0 {
+			member.MeanLatencyMillis = millis(p.totalLatency / time.Duration(p.dispatches))
+		}
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Key < members[j].Key })
+
+	return Ring{
+		Target:            b.target,
+		ReplicationFactor: b.replicationFactor,
+		Members:           members,
+	}
+}
+
+// observingClientConn records the connections created by the balancer, and wraps its pickers to
+// observe the dispatches.
+type observingClientConn struct {
+	balancer.ClientConn
+	balancer *observingBalancer
+}
+
+func (cc *observingClientConn) NewSubConn(addrs []resolver.Address, opts balancer.NewSubConnOptions) (balancer.SubConn, error) {
+	sc, err := cc.ClientConn.NewSubConn(addrs, opts)
+	if err != nil || len(addrs) == 0 {
+		return sc, err
+	}
+
+	cc.balancer.lock.Lock()
+	defer cc.balancer.lock.Unlock()
+
+	// This is the key under which the consistent balancer places the connection on the ring.
+	cc.balancer.peers[sc] = &peer{key: addrs[0].ServerName + addrs[0].Addr, state: connectivity.Idle}
+	return sc, nil
+}
+
+func (cc *observingClientConn) RemoveSubConn(sc balancer.SubConn) {
+	cc.balancer.lock.Lock()
+	delete(cc.balancer.peers, sc)
+	cc.balancer.lock.Unlock()
+
+	cc.ClientConn.RemoveSubConn(sc)
+}
+
+func (cc *observingClientConn) UpdateState(state balancer.State) {
+	state.Picker = &observingPicker{state.Picker, cc.balancer}
+	cc.ClientConn.UpdateState(state)
+}
+
+type observingPicker struct {
+	balancer.Picker
+	balancer *observingBalancer
+}
+
+func (p *observingPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	result, err := p.Picker.Pick(info)
+	if err != nil {
+		return result, err
+	}
+
+	start := time.Now()
+	sc := result.SubConn
+	done := result.Done
+	result.Done = func(info balancer.DoneInfo) {
+		p.balancer.recordDispatch(sc, time.Since(start), info.Err)
+		if done != nil {
+			done(info)
+		}
+	}
+	return result, nil
+}
+
+// ownershipSamples is the number of keys placed on a ring to estimate the fraction of the hash
+// space owned by each of its members, which is within about 1% of the exact fraction.
+const ownershipSamples = 10000
+
+type ringMember string
+
+func (m ringMember) Key() string { return string(m) }
+
+// ownership estimates the fraction of the hash space owned by each of the keys on a ring with the
+// replication factor, as the share of sample keys a hashring with those members places on each of
+// them.
+func ownership(hashfn hashring.HashFunc, replicationFactor uint16, keys []string) map[string]float64 {
+	owned := make(map[string]float64, len(keys))
+	ring, err := hashring.New(hashfn, replicationFactor)
+	if err != nil {
+		return owned
+	}
+
+	for _, key := range keys {
+		if err := ring.Add(ringMember(key)); err != nil && !errors.Is(err, hashring.ErrMemberAlreadyExists) {
+			return owned
+		}
+	}
+	if len(ring.Members()) == 0 {
+		return owned
+	}
+
+	placed := make(map[string]int, len(keys))
+	sample := make([]byte, 8)
+	for i := uint64(0); i < ownershipSamples; i++ {
+		binary.LittleEndian.PutUint64(sample, i)
+		found, err := ring.FindN(sample, 1)
+		if err != nil {
+			return owned
+		}
+		placed[found[0].Key()]++
+	}
+
+	for key, count := range placed {
+		owned[key] = float64(count) / ownershipSamples
+	}
+	return owned
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,114 @@
+package peers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/authzed/consistent"
+	"github.com/authzed/consistent/hashring"
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestOwnership(t *testing.T) {
+	keys := []string{"10.0.0.1:50053", "10.0.0.2:50053", "10.0.0.3:50053"}
+	owned := ownership(xxhash.Sum64, 100, keys)
+
+	ring := hashring.MustNew(xxhash.Sum64, 100)
+	for _, key := range keys {
+		require.NoError(t, ring.Add(ringMember(key)))
+	}
+
+	// The ownership matches the share of keys the ring places on each member.
+	const samples = 30000
+	placed := make(map[string]int, len(keys))
+	for i := 0; i < samples; i++ {
+		found, err := ring.FindN([]byte(fmt.Sprintf("somekey%d", i)), 1)
+		require.NoError(t, err)
+		placed[found[0].Key()]++
+	}
+
+	total := 0.0
+	for _, key := range keys {
+		require.InDelta(t, float64(placed[key])/samples, owned[key], 0.02)
+		total += owned[key]
+	}
+	require.InDelta(t, 1, total, 1e-9)
+
+	require.Equal(t, map[string]float64{"10.0.0.1:50053": 1}, ownership(xxhash.Sum64, 100, keys[:1]))
+	require.Empty(t, ownership(xxhash.Sum64, 100, nil))
+}
+
+type fakeSubConn struct {
+	balancer.SubConn
+}
+
+func (sc *fakeSubConn) Connect() {}
+
+type fakeClientConn struct {
+	balancer.ClientConn
+	subConns []balancer.SubConn
+	picker   balancer.Picker
+}
+
+func (cc *fakeClientConn) NewSubConn([]resolver.Address, balancer.NewSubConnOptions) (balancer.SubConn, error) {
+	sc := &fakeSubConn{}
+	cc.subConns = append(cc.subConns, sc)
+	return sc, nil
+}
+
+func (cc *fakeClientConn) RemoveSubConn(balancer.SubConn) {}
+
+func (cc *fakeClientConn) UpdateState(state balancer.State) {
+	cc.picker = state.Picker
+}
+
+func TestBuilder(t *testing.T) {
+	builder := NewBuilder(xxhash.Sum64)
+	config, err := builder.ParseConfig([]byte(`{"replicationFactor": 50, "spread": 1}`))
+	require.NoError(t, err)
+
+	cc := &fakeClientConn{}
+	b := builder.Build(cc, balancer.BuildOptions{})
+	require.NoError(t, b.UpdateClientConnState(balancer.ClientConnState{
+		ResolverState: resolver.State{Addresses: []resolver.Address{
+			{Addr: "10.0.0.1:50053"},
+			{Addr: "10.0.0.2:50053"},
+		}},
+		BalancerConfig: config,
+	}))
+
+	b.UpdateSubConnState(cc.subConns[0], balancer.SubConnState{ConnectivityState: connectivity.Ready})
+
+	rings := builder.Rings()
+	require.Len(t, rings, 1)
+	require.Equal(t, uint16(50), rings[0].ReplicationFactor)
+	require.Len(t, rings[0].Members, 2)
+	require.Equal(t, "10.0.0.1:50053", rings[0].Members[0].Key)
+	require.Equal(t, "READY", rings[0].Members[0].State)
+	require.Equal(t, "IDLE", rings[0].Members[1].State)
+	require.InDelta(t, 1, rings[0].Members[0].Ownership+rings[0].Members[1].Ownership, 1e-9)
+
+	ctx := context.WithValue(context.Background(), consistent.CtxKey, []byte("somekey"))
+	for _, dispatchErr := range []error{nil, errors.New("some error")} {
+		result, err := cc.picker.Pick(balancer.PickInfo{Ctx: ctx})
+		require.NoError(t, err)
+		result.Done(balancer.DoneInfo{Err: dispatchErr})
+	}
+
+	var dispatches, failures uint64
+	for _, member := range builder.Rings()[0].Members {
+		dispatches += member.Dispatches
+		failures += member.Failures
+	}
+	require.Equal(t, uint64(2), dispatches)
+	require.Equal(t, uint64(1), failures)
+
+	b.Close()
+	require.Empty(t, builder.Rings())
+}
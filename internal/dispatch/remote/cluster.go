@@ -11,6 +11,10 @@ import (
 	"github.com/authzed/consistent"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/protobuf/proto"
@@ -29,10 +33,27 @@ var dispatchCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
 	Help:      "which dispatcher handled a request",
 }, []string{"request_kind", "handler_name"})
 
+var tracer = otel.Tracer("spicedb/internal/dispatch/remote")
+
 func init() {
 	prometheus.MustRegister(dispatchCounter)
 }
 
+// startDispatchSpan starts the span covering a dispatch to a peer. The gRPC client span (and therefore
+// the trace context sent to the peer) is created as a child of this span.
+func startDispatchSpan(ctx context.Context, reqKey string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "RemoteDispatch "+reqKey, trace.WithAttributes(
+		attribute.String("request-kind", reqKey),
+	))
+}
+
+func recordSpanError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
 type ClusterClient interface {
 	DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest, opts ...grpc.CallOption) (*v1.DispatchCheckResponse, error)
 	DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest, opts ...grpc.CallOption) (*v1.DispatchExpandResponse, error)
@@ -140,17 +161,24 @@ type secondaryRespTuple[S responseMessage] struct {
 }
 
 func dispatchRequest[Q requestMessage, S responseMessage](ctx context.Context, cr *clusterDispatcher, reqKey string, req Q, handler func(context.Context, ClusterClient) (S, error)) (S, error) {
+	ctx, span := startDispatchSpan(ctx, reqKey)
+	defer span.End()
+
 	withTimeout, cancelFn := context.WithTimeout(ctx, cr.dispatchOverallTimeout)
 	defer cancelFn()
 
 	if len(cr.secondaryDispatchExprs) == 0 || len(cr.secondaryDispatch) == 0 {
-		return handler(withTimeout, cr.clusterClient)
+		resp, err := handler(withTimeout, cr.clusterClient)
+		recordSpanError(span, err)
+		return resp, err
 	}
 
 	// If no secondary dispatches are defined, just invoke directly.
 	expr, ok := cr.secondaryDispatchExprs[reqKey]
 	if !ok {
-		return handler(withTimeout, cr.clusterClient)
+		resp, err := handler(withTimeout, cr.clusterClient)
+		recordSpanError(span, err)
+		return resp, err
 	}
 
 	// Otherwise invoke in parallel with any secondary matches.
@@ -169,6 +197,7 @@ func dispatchRequest[Q requestMessage, S responseMessage](ctx context.Context, c
 	}
 
 	log.Trace().Str("secondary-dispatchers", strings.Join(result, ",")).Object("request", req).Msg("running secondary dispatchers")
+	span.SetAttributes(attribute.StringSlice("secondary-dispatchers", result))
 
 	for _, secondaryDispatchName := range result {
 		secondary, ok := cr.secondaryDispatch[secondaryDispatchName]
@@ -194,11 +223,14 @@ func dispatchRequest[Q requestMessage, S responseMessage](ctx context.Context, c
 	var foundError error
 	select {
 	case <-withTimeout.Done():
-		return *new(S), fmt.Errorf("check dispatch has timed out")
+		err := fmt.Errorf("check dispatch has timed out")
+		recordSpanError(span, err)
+		return *new(S), err
 
 	case r := <-primaryResultChan:
 		if r.err == nil {
 			dispatchCounter.WithLabelValues(reqKey, "(primary)").Add(1)
+			span.SetAttributes(attribute.String("handler", "(primary)"))
 			return r.resp, nil
 		}
 
@@ -208,10 +240,13 @@ func dispatchRequest[Q requestMessage, S responseMessage](ctx context.Context, c
 
 	case r := <-secondaryResultChan:
 		dispatchCounter.WithLabelValues(reqKey, r.handlerName).Add(1)
+		span.SetAttributes(attribute.String("handler", r.handlerName))
 		return r.resp, nil
 	}
 
 	dispatchCounter.WithLabelValues(reqKey, "(primary)").Add(1)
+	span.SetAttributes(attribute.String("handler", "(primary)"))
+	recordSpanError(span, foundError)
 	return *new(S), foundError
 }
 
@@ -240,12 +275,15 @@ func (cr *clusterDispatcher) DispatchExpand(ctx context.Context, req *v1.Dispatc
 	}
 
 	ctx = context.WithValue(ctx, consistent.CtxKey, requestKey)
+	ctx, span := startDispatchSpan(ctx, "expand")
+	defer span.End()
 
 	withTimeout, cancelFn := context.WithTimeout(ctx, cr.dispatchOverallTimeout)
 	defer cancelFn()
 
 	resp, err := cr.clusterClient.DispatchExpand(withTimeout, req)
 	if err != nil {
+		recordSpanError(span, err)
 		return &v1.DispatchExpandResponse{Metadata: requestFailureMetadata}, err
 	}
 
@@ -263,6 +301,8 @@ func (cr *clusterDispatcher) DispatchReachableResources(
 	}
 
 	ctx := context.WithValue(stream.Context(), consistent.CtxKey, requestKey)
+	ctx, span := startDispatchSpan(ctx, "reachableresources")
+	defer span.End()
 	stream = dispatch.StreamWithContext(ctx, stream)
 
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
@@ -313,6 +353,8 @@ func (cr *clusterDispatcher) DispatchLookupResources(
 	}
 
 	ctx := context.WithValue(stream.Context(), consistent.CtxKey, requestKey)
+	ctx, span := startDispatchSpan(ctx, "lookupresources")
+	defer span.End()
 	stream = dispatch.StreamWithContext(ctx, stream)
 
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
@@ -363,6 +405,8 @@ func (cr *clusterDispatcher) DispatchLookupSubjects(
 	}
 
 	ctx := context.WithValue(stream.Context(), consistent.CtxKey, requestKey)
+	ctx, span := startDispatchSpan(ctx, "lookupsubjects")
+	defer span.End()
 	stream = dispatch.StreamWithContext(ctx, stream)
 
 	if err := dispatch.CheckDepth(ctx, req); err != nil {
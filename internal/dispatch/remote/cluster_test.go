@@ -11,6 +11,10 @@ import (
 
 	humanize "github.com/dustin/go-humanize"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/test/bufconn"
@@ -242,6 +246,55 @@ func TestSecondaryDispatch(t *testing.T) {
 	}
 }
 
+func TestSecondaryDispatchSpan(t *testing.T) {
+	spanrecorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(spanrecorder),
+	)
+	defaultProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(defaultProvider) })
+
+	conn := connectionForDispatching(t, &fakeDispatchSvc{dispatchCount: 1, sleepTime: 1 * time.Second})
+	secondaryConn := connectionForDispatching(t, &fakeDispatchSvc{dispatchCount: 2, sleepTime: 0 * time.Millisecond})
+
+	parsed, err := ParseDispatchExpression("check", "['secondary']")
+	require.NoError(t, err)
+
+	dispatcher := NewClusterDispatcher(v1.NewDispatchServiceClient(conn), conn, ClusterDispatcherConfig{
+		KeyHandler:             &keys.DirectKeyHandler{},
+		DispatchOverallTimeout: 30 * time.Second,
+	}, map[string]SecondaryDispatch{
+		"secondary": {Name: "secondary", Client: v1.NewDispatchServiceClient(secondaryConn)},
+	}, map[string]*DispatchExpr{
+		"check": parsed,
+	})
+
+	_, err = dispatcher.DispatchCheck(context.Background(), &v1.DispatchCheckRequest{
+		ResourceRelation: &corev1.RelationReference{
+			Namespace: "somenamespace",
+			Relation:  "somerelation",
+		},
+		ResourceIds: []string{"foo"},
+		Metadata:    &v1.ResolverMeta{DepthRemaining: 50},
+		Subject:     &corev1.ObjectAndRelation{Namespace: "foo", ObjectId: "bar", Relation: "..."},
+	})
+	require.NoError(t, err)
+
+	var found bool
+	for _, span := range spanrecorder.Ended() {
+		if span.Name() != "RemoteDispatch check" {
+			continue
+		}
+
+		found = true
+		require.Contains(t, span.Attributes(), attribute.String("request-kind", "check"))
+		require.Contains(t, span.Attributes(), attribute.String("handler", "secondary"))
+	}
+	require.True(t, found, "missing remote dispatch span")
+}
+
 func connectionForDispatching(t *testing.T, svc v1.DispatchServiceServer) *grpc.ClientConn {
 	listener := bufconn.Listen(humanize.MiByte)
 	s := grpc.NewServer()
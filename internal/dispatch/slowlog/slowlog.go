@@ -0,0 +1,96 @@
+// Package slowlog implements a dispatcher that logs the dispatched subproblems which take longer
+// than a threshold to resolve.
+package slowlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	log "github.com/authzed/spicedb/internal/logging"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// New creates a dispatcher which logs, at warning level, the requests to the delegate which take
+// at least the threshold to resolve, along with their number of results and dispatches.
+func New(delegate dispatch.Dispatcher, threshold time.Duration) dispatch.Dispatcher {
+	return &Dispatcher{delegate: delegate, threshold: threshold}
+}
+
+type Dispatcher struct {
+	delegate  dispatch.Dispatcher
+	threshold time.Duration
+}
+
+func (d *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	start := time.Now()
+	resp, err := d.delegate.DispatchCheck(ctx, req)
+	d.logIfSlow(ctx, "DispatchCheck", req, start, len(resp.GetResultsByResourceId()), resp.GetMetadata(), err)
+	return resp, err
+}
+
+func (d *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	start := time.Now()
+	resp, err := d.delegate.DispatchExpand(ctx, req)
+	d.logIfSlow(ctx, "DispatchExpand", req, start, 1, resp.GetMetadata(), err)
+	return resp, err
+}
+
+func (d *Dispatcher) DispatchReachableResources(req *v1.DispatchReachableResourcesRequest, stream dispatch.ReachableResourcesStream) error {
+	start := time.Now()
+	counting := dispatch.NewCountingDispatchStream(stream)
+	err := d.delegate.DispatchReachableResources(req, counting)
+	d.logIfSlow(stream.Context(), "DispatchReachableResources", req, start, int(counting.PublishedCount()), nil, err)
+	return err
+}
+
+func (d *Dispatcher) DispatchLookupResources(req *v1.DispatchLookupResourcesRequest, stream dispatch.LookupResourcesStream) error {
+	start := time.Now()
+	counting := dispatch.NewCountingDispatchStream(stream)
+	err := d.delegate.DispatchLookupResources(req, counting)
+	d.logIfSlow(stream.Context(), "DispatchLookupResources", req, start, int(counting.PublishedCount()), nil, err)
+	return err
+}
+
+func (d *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	start := time.Now()
+	counting := dispatch.NewCountingDispatchStream(stream)
+	err := d.delegate.DispatchLookupSubjects(req, counting)
+	d.logIfSlow(stream.Context(), "DispatchLookupSubjects", req, start, int(counting.PublishedCount()), nil, err)
+	return err
+}
+
+func (d *Dispatcher) Close() error {
+	return d.delegate.Close()
+}
+
+func (d *Dispatcher) ReadyState() dispatch.ReadyState {
+	return d.delegate.ReadyState()
+}
+
+func (d *Dispatcher) logIfSlow(ctx context.Context, method string, req zerolog.LogObjectMarshaler, start time.Time, results int, metadata *v1.ResponseMeta, err error) {
+	duration := time.Since(start)
+	if duration < d.threshold {
+		return
+	}
+
+	event := log.Ctx(ctx).Warn().
+		Str("method", method).
+		Object("request", req).
+		Dur("duration", duration).
+		Int("results", results)
+	if metadata != nil {
+		event = event.
+			Uint32("dispatch_count", metadata.DispatchCount).
+			Uint32("cached_dispatch_count", metadata.CachedDispatchCount).
+			Uint32("depth_required", metadata.DepthRequired)
+	}
+	if err != nil {
+		event = event.Err(err)
+	}
+	event.Msg("slow dispatch")
+}
+
+var _ dispatch.Dispatcher = &Dispatcher{}
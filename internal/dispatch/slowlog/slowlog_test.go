@@ -0,0 +1,95 @@
+package slowlog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestSlowDispatchLogging(t *testing.T) {
+	req := &v1.DispatchCheckRequest{
+		ResourceRelation: tuple.RelationReference("document", "view"),
+		ResourceIds:      []string{"foo", "bar"},
+		Subject:          tuple.ObjectAndRelation("user", "tom", "..."),
+		Metadata:         &v1.ResolverMeta{AtRevision: "1234", DepthRemaining: 50},
+	}
+
+	var buf bytes.Buffer
+	ctx := zerolog.New(&buf).WithContext(context.Background())
+
+	disp := New(mockDispatcher{delay: 10 * time.Millisecond}, time.Hour)
+	_, err := disp.DispatchCheck(ctx, req)
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+
+	disp = New(mockDispatcher{delay: 10 * time.Millisecond}, 5*time.Millisecond)
+	_, err = disp.DispatchCheck(ctx, req)
+	require.NoError(t, err)
+
+	logged := buf.String()
+	require.Contains(t, logged, `"message":"slow dispatch"`)
+	require.Contains(t, logged, `"method":"DispatchCheck"`)
+	require.Contains(t, logged, `"resource-ids":["foo","bar"]`)
+	require.Contains(t, logged, `"results":2`)
+	require.Contains(t, logged, `"dispatch_count":3`)
+
+	buf.Reset()
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupSubjectsResponse](ctx)
+	err = disp.DispatchLookupSubjects(&v1.DispatchLookupSubjectsRequest{
+		Metadata: &v1.ResolverMeta{AtRevision: "1234", DepthRemaining: 50},
+	}, stream)
+	require.NoError(t, err)
+	require.Len(t, stream.Results(), 1)
+	require.Contains(t, buf.String(), `"method":"DispatchLookupSubjects"`)
+	require.Contains(t, buf.String(), `"results":1`)
+}
+
+type mockDispatcher struct {
+	delay time.Duration
+}
+
+func (m mockDispatcher) DispatchCheck(_ context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	time.Sleep(m.delay)
+	results := make(map[string]*v1.ResourceCheckResult, len(req.ResourceIds))
+	for _, id := range req.ResourceIds {
+		results[id] = &v1.ResourceCheckResult{Membership: v1.ResourceCheckResult_MEMBER}
+	}
+	return &v1.DispatchCheckResponse{
+		Metadata:            &v1.ResponseMeta{DispatchCount: 3},
+		ResultsByResourceId: results,
+	}, nil
+}
+
+func (m mockDispatcher) DispatchExpand(_ context.Context, _ *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	time.Sleep(m.delay)
+	return &v1.DispatchExpandResponse{}, nil
+}
+
+func (m mockDispatcher) DispatchReachableResources(_ *v1.DispatchReachableResourcesRequest, _ dispatch.ReachableResourcesStream) error {
+	return nil
+}
+
+func (m mockDispatcher) DispatchLookupResources(_ *v1.DispatchLookupResourcesRequest, _ dispatch.LookupResourcesStream) error {
+	return nil
+}
+
+func (m mockDispatcher) DispatchLookupSubjects(_ *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	time.Sleep(m.delay)
+	return stream.Publish(&v1.DispatchLookupSubjectsResponse{})
+}
+
+func (m mockDispatcher) Close() error {
+	return nil
+}
+
+func (m mockDispatcher) ReadyState() dispatch.ReadyState {
+	return dispatch.ReadyState{}
+}
@@ -0,0 +1,223 @@
+package extauthz
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	defaultIdentityClaim     = "sub"
+	defaultMetadataNamespace = "envoy.filters.http.jwt_authn"
+	defaultMetadataKey       = "jwt_payload"
+)
+
+// Config is the configuration of the adapter, usually loaded from a YAML file with LoadConfig.
+//
+// Example:
+//
+//	subject:
+//	  type: user
+//	  claim: sub
+//	rules:
+//	  - methods: [GET]
+//	    path: /documents/{id}
+//	    resource:
+//	      type: document
+//	      id: "{id}"
+//	    permission: view
+type Config struct {
+	// JWT, if set, configures the adapter to verify the bearer token found in the Authorization
+	// header of the request itself. Otherwise the claims are read from the dynamic metadata set
+	// by Envoy's jwt_authn filter, which must then be configured with `payload_in_metadata`.
+	JWT *JWTConfig `yaml:"jwt"`
+
+	// Subject configures how the subject of checks is determined from the token claims.
+	Subject SubjectConfig `yaml:"subject"`
+
+	// Rules are matched in order against each request; the first matching rule is used.
+	// Requests matching no rule are denied.
+	Rules []RuleConfig `yaml:"rules"`
+}
+
+// JWTConfig configures the verification of bearer tokens by the adapter.
+type JWTConfig struct {
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	JWKSURL  string `yaml:"jwksUrl"`
+}
+
+// SubjectConfig configures how the subject of checks is determined.
+type SubjectConfig struct {
+	// Type is the object type of the subject.
+	Type string `yaml:"type"`
+
+	// Relation is the optional relation of the subject.
+	Relation string `yaml:"relation"`
+
+	// Claim is the claim holding the subject's object ID. Defaults to `sub`.
+	Claim string `yaml:"claim"`
+
+	// MetadataNamespace is the dynamic metadata namespace holding the token claims. Defaults to
+	// `envoy.filters.http.jwt_authn`.
+	MetadataNamespace string `yaml:"metadataNamespace"`
+
+	// MetadataKey is the key of the token claims in the metadata namespace. Defaults to
+	// `jwt_payload`.
+	MetadataKey string `yaml:"metadataKey"`
+}
+
+// RuleConfig maps requests onto a permission check.
+type RuleConfig struct {
+	// Methods are the HTTP methods matched by the rule. If empty, all methods are matched.
+	Methods []string `yaml:"methods"`
+
+	// Path is the path template matched by the rule. Each segment is either a literal, `*`
+	// matching any single segment, or `{name}` capturing the segment as a variable.
+	Path string `yaml:"path"`
+
+	// Resource is the resource checked. The ID may reference the variables of the path
+	// template, e.g. `{id}` or `org-{org}`.
+	Resource ResourceConfig `yaml:"resource"`
+
+	// Permission is the permission checked on the resource.
+	Permission string `yaml:"permission"`
+}
+
+// ResourceConfig is the resource checked by a rule.
+type ResourceConfig struct {
+	Type string `yaml:"type"`
+	ID   string `yaml:"id"`
+}
+
+// LoadConfig reads and validates the configuration found at the given path.
+func LoadConfig(path string) (*Config, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ext_authz config: %w", err)
+	}
+
+	return ParseConfig(contents)
+}
+
+// ParseConfig parses and validates a YAML configuration.
+func ParseConfig(contents []byte) (*Config, error) {
+	decoder := yamlv3.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("error parsing ext_authz config: %w", err)
+	}
+
+	if _, err := compileRules(config.Rules); err != nil {
+		return nil, err
+	}
+
+	if config.Subject.Type == "" {
+		return nil, fmt.Errorf("invalid ext_authz config: missing subject type")
+	}
+
+	return &config, nil
+}
+
+var variablePattern = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+type compiledRule struct {
+	methods    map[string]struct{}
+	segments   []string
+	rule       RuleConfig
+	resourceID string
+}
+
+// match returns the variables captured by the path template if the rule matches the request.
+func (cr compiledRule) match(method, path string) (map[string]string, bool) {
+	if len(cr.methods) > 0 {
+		if _, ok := cr.methods[strings.ToUpper(method)]; !ok {
+			return nil, false
+		}
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) != len(cr.segments) {
+		return nil, false
+	}
+
+	variables := map[string]string{}
+	for index, templateSegment := range cr.segments {
+		segment := segments[index]
+		switch {
+		case templateSegment == "*":
+			if segment == "" {
+				return nil, false
+			}
+		case isVariable(templateSegment):
+			if segment == "" {
+				return nil, false
+			}
+			variables[templateSegment[1:len(templateSegment)-1]] = segment
+		case templateSegment != segment:
+			return nil, false
+		}
+	}
+
+	return variables, true
+}
+
+// resourceObjectID returns the resource ID of the rule with the variables substituted.
+func (cr compiledRule) resourceObjectID(variables map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(cr.resourceID, func(variable string) string {
+		return variables[variable[1:len(variable)-1]]
+	})
+}
+
+func isVariable(segment string) bool {
+	return variablePattern.MatchString(segment) && variablePattern.FindString(segment) == segment
+}
+
+func compileRules(rules []RuleConfig) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for index, rule := range rules {
+		if !strings.HasPrefix(rule.Path, "/") {
+			return nil, fmt.Errorf("invalid ext_authz rule #%d: path `%s` must start with `/`", index, rule.Path)
+		}
+
+		if rule.Resource.Type == "" || rule.Resource.ID == "" || rule.Permission == "" {
+			return nil, fmt.Errorf("invalid ext_authz rule #%d: resource type, resource ID and permission are required", index)
+		}
+
+		segments := strings.Split(strings.Trim(rule.Path, "/"), "/")
+		variables := map[string]struct{}{}
+		for _, segment := range segments {
+			if strings.ContainsAny(segment, "{}") {
+				if !isVariable(segment) {
+					return nil, fmt.Errorf("invalid ext_authz rule #%d: segment `%s` must be either a literal or a single variable", index, segment)
+				}
+				variables[segment[1:len(segment)-1]] = struct{}{}
+			}
+		}
+
+		for _, match := range variablePattern.FindAllStringSubmatch(rule.Resource.ID, -1) {
+			if _, ok := variables[match[1]]; !ok {
+				return nil, fmt.Errorf("invalid ext_authz rule #%d: resource ID references unknown variable `%s`", index, match[1])
+			}
+		}
+
+		methods := make(map[string]struct{}, len(rule.Methods))
+		for _, method := range rule.Methods {
+			methods[strings.ToUpper(method)] = struct{}{}
+		}
+
+		compiled = append(compiled, compiledRule{
+			methods:    methods,
+			segments:   segments,
+			rule:       rule,
+			resourceID: rule.Resource.ID,
+		})
+	}
+	return compiled, nil
+}
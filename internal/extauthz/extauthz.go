@@ -0,0 +1,163 @@
+// Package extauthz implements an adapter serving Envoy's ext_authz gRPC protocol, which maps
+// incoming HTTP requests onto permission checks using configurable rules.
+package extauthz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/auth"
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// Server implements the Envoy ext_authz Authorization service.
+type Server struct {
+	client   v1.PermissionsServiceClient
+	subject  SubjectConfig
+	rules    []compiledRule
+	verifier *auth.JWTVerifier
+}
+
+var _ authv3.AuthorizationServer = (*Server)(nil)
+
+// NewServer creates a new adapter, checking permissions with the given client.
+func NewServer(client v1.PermissionsServiceClient, config Config) (*Server, error) {
+	rules, err := compileRules(config.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := config.Subject
+	if subject.Claim == "" {
+		subject.Claim = defaultIdentityClaim
+	}
+	if subject.MetadataNamespace == "" {
+		subject.MetadataNamespace = defaultMetadataNamespace
+	}
+	if subject.MetadataKey == "" {
+		subject.MetadataKey = defaultMetadataKey
+	}
+
+	server := &Server{
+		client:  client,
+		subject: subject,
+		rules:   rules,
+	}
+
+	if config.JWT != nil {
+		server.verifier, err = auth.NewJWTVerifier(auth.JWTConfig{
+			Issuer:        config.JWT.Issuer,
+			Audience:      config.JWT.Audience,
+			JWKSURL:       config.JWT.JWKSURL,
+			IdentityClaim: subject.Claim,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return server, nil
+}
+
+var errMissingSubject = errors.New("missing subject")
+
+// Check implements authv3.AuthorizationServer.
+func (s *Server) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	httpRequest := req.GetAttributes().GetRequest().GetHttp()
+	if httpRequest == nil {
+		return denied(codes.InvalidArgument, typev3.StatusCode_Forbidden, "not an HTTP request"), nil
+	}
+
+	path, _, _ := strings.Cut(httpRequest.Path, "?")
+
+	var rule *compiledRule
+	var variables map[string]string
+	for index := range s.rules {
+		if found, ok := s.rules[index].match(httpRequest.Method, path); ok {
+			rule, variables = &s.rules[index], found
+			break
+		}
+	}
+	if rule == nil {
+		return denied(codes.PermissionDenied, typev3.StatusCode_Forbidden, "no matching rule"), nil
+	}
+
+	subjectID, err := s.subjectID(ctx, req)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("path", path).Msg("ext_authz request unauthenticated")
+		return denied(codes.Unauthenticated, typev3.StatusCode_Unauthorized, "unauthenticated"), nil
+	}
+
+	resp, err := s.client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Resource: &v1.ObjectReference{
+			ObjectType: rule.rule.Resource.Type,
+			ObjectId:   rule.resourceObjectID(variables),
+		},
+		Permission: rule.rule.Permission,
+		Subject: &v1.SubjectReference{
+			Object: &v1.ObjectReference{
+				ObjectType: s.subject.Type,
+				ObjectId:   subjectID,
+			},
+			OptionalRelation: s.subject.Relation,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Permissionship != v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
+		return denied(codes.PermissionDenied, typev3.StatusCode_Forbidden, "permission denied"), nil
+	}
+
+	return &authv3.CheckResponse{
+		Status:       &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: &authv3.OkHttpResponse{}},
+	}, nil
+}
+
+// subjectID returns the object ID of the subject found in the token of the request.
+func (s *Server) subjectID(ctx context.Context, req *authv3.CheckRequest) (string, error) {
+	if s.verifier != nil {
+		authorization := req.GetAttributes().GetRequest().GetHttp().GetHeaders()["authorization"]
+		scheme, token, found := strings.Cut(authorization, " ")
+		if !found || !strings.EqualFold(scheme, "bearer") || token == "" {
+			return "", errMissingSubject
+		}
+
+		caller, err := s.verifier.Verify(ctx, token)
+		if err != nil {
+			return "", err
+		}
+		return caller.Identity, nil
+	}
+
+	payload := req.GetAttributes().GetMetadataContext().GetFilterMetadata()[s.subject.MetadataNamespace].GetFields()[s.subject.MetadataKey].GetStructValue()
+	if payload == nil {
+		return "", errMissingSubject
+	}
+
+	subjectID, ok := payload.GetFields()[s.subject.Claim].AsInterface().(string)
+	if !ok || subjectID == "" {
+		return "", fmt.Errorf("claim `%s` is missing or not a string", s.subject.Claim)
+	}
+	return subjectID, nil
+}
+
+func denied(code codes.Code, httpCode typev3.StatusCode, body string) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(code), Message: body},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: &authv3.DeniedHttpResponse{
+			Status: &typev3.HttpStatus{Code: httpCode},
+			Body:   body,
+		}},
+	}
+}
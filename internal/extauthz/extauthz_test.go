@@ -0,0 +1,205 @@
+package extauthz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const testConfig = `
+subject:
+  type: user
+rules:
+  - methods: [GET]
+    path: /orgs/{org}/documents/{id}
+    resource:
+      type: document
+      id: "{org}-{id}"
+    permission: view
+  - path: /orgs/*/documents/{id}
+    resource:
+      type: document
+      id: "{id}"
+    permission: edit
+`
+
+type fakePermissionsClient struct {
+	v1.PermissionsServiceClient
+
+	allowed map[string]struct{}
+	checked []*v1.CheckPermissionRequest
+}
+
+func (c *fakePermissionsClient) CheckPermission(_ context.Context, req *v1.CheckPermissionRequest, _ ...grpc.CallOption) (*v1.CheckPermissionResponse, error) {
+	c.checked = append(c.checked, req)
+	if req.Resource.ObjectId == "broken" {
+		return nil, errors.New("upstream unavailable")
+	}
+
+	key := req.Resource.ObjectType + ":" + req.Resource.ObjectId + "#" + req.Permission + "@" + req.Subject.Object.ObjectType + ":" + req.Subject.Object.ObjectId
+	if _, ok := c.allowed[key]; ok {
+		return &v1.CheckPermissionResponse{Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION}, nil
+	}
+	return &v1.CheckPermissionResponse{Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION}, nil
+}
+
+func checkRequest(method, path string, claims map[string]any) *authv3.CheckRequest {
+	req := &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Request: &authv3.AttributeContext_Request{
+				Http: &authv3.AttributeContext_HttpRequest{Method: method, Path: path},
+			},
+		},
+	}
+
+	if claims != nil {
+		payload, err := structpb.NewStruct(claims)
+		if err != nil {
+			panic(err)
+		}
+		req.Attributes.MetadataContext = &corev3.Metadata{
+			FilterMetadata: map[string]*structpb.Struct{
+				defaultMetadataNamespace: {Fields: map[string]*structpb.Value{
+					defaultMetadataKey: structpb.NewStructValue(payload),
+				}},
+			},
+		}
+	}
+	return req
+}
+
+func TestCheck(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig))
+	require.NoError(t, err)
+
+	client := &fakePermissionsClient{allowed: map[string]struct{}{
+		"document:acme-readme#view@user:alice": {},
+		"document:readme#edit@user:alice":      {},
+	}}
+	server, err := NewServer(client, *config)
+	require.NoError(t, err)
+
+	alice := map[string]any{"sub": "alice"}
+	bob := map[string]any{"sub": "bob"}
+
+	testcases := []struct {
+		name             string
+		request          *authv3.CheckRequest
+		expectedCode     codes.Code
+		expectedHTTPCode typev3.StatusCode
+		expectedResource string
+	}{
+		{"allowed view", checkRequest("GET", "/orgs/acme/documents/readme", alice), codes.OK, 0, "acme-readme"},
+		{"allowed view with query", checkRequest("GET", "/orgs/acme/documents/readme?page=2", alice), codes.OK, 0, "acme-readme"},
+		{"denied view", checkRequest("GET", "/orgs/acme/documents/readme", bob), codes.PermissionDenied, typev3.StatusCode_Forbidden, "acme-readme"},
+		{"allowed edit", checkRequest("PUT", "/orgs/acme/documents/readme", alice), codes.OK, 0, "readme"},
+		{"no matching rule", checkRequest("GET", "/orgs/acme", alice), codes.PermissionDenied, typev3.StatusCode_Forbidden, ""},
+		{"empty segment", checkRequest("GET", "/orgs//documents/readme", alice), codes.PermissionDenied, typev3.StatusCode_Forbidden, ""},
+		{"missing claims", checkRequest("GET", "/orgs/acme/documents/readme", nil), codes.Unauthenticated, typev3.StatusCode_Unauthorized, ""},
+		{"non-string subject", checkRequest("GET", "/orgs/acme/documents/readme", map[string]any{"sub": 42}), codes.Unauthenticated, typev3.StatusCode_Unauthorized, ""},
+	}
+
+	for _, testcase := range testcases {
+		testcase := testcase
+		t.Run(testcase.name, func(t *testing.T) {
+			client.checked = nil
+
+			resp, err := server.Check(context.Background(), testcase.request)
+			require.NoError(t, err)
+			require.Equal(t, int32(testcase.expectedCode), resp.Status.Code)
+
+			if testcase.expectedCode == codes.OK {
+				require.NotNil(t, resp.GetOkResponse())
+			} else {
+				require.Equal(t, testcase.expectedHTTPCode, resp.GetDeniedResponse().Status.Code)
+			}
+
+			if testcase.expectedResource == "" {
+				require.Empty(t, client.checked)
+				return
+			}
+
+			require.Len(t, client.checked, 1)
+			require.Equal(t, testcase.expectedResource, client.checked[0].Resource.ObjectId)
+			require.Equal(t, "user", client.checked[0].Subject.Object.ObjectType)
+		})
+	}
+}
+
+func TestCheckUpstreamError(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig))
+	require.NoError(t, err)
+
+	server, err := NewServer(&fakePermissionsClient{}, *config)
+	require.NoError(t, err)
+
+	_, err = server.Check(context.Background(), checkRequest("PUT", "/orgs/acme/documents/broken", map[string]any{"sub": "alice"}))
+	require.Error(t, err)
+}
+
+func TestParseConfig(t *testing.T) {
+	testcases := []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{"valid", testConfig, ""},
+		{"missing subject type", `
+rules:
+  - path: /documents/{id}
+    resource: {type: document, id: "{id}"}
+    permission: view
+`, "missing subject type"},
+		{"unknown variable", `
+subject: {type: user}
+rules:
+  - path: /documents/{id}
+    resource: {type: document, id: "{name}"}
+    permission: view
+`, "unknown variable `name`"},
+		{"partial variable segment", `
+subject: {type: user}
+rules:
+  - path: /documents/doc-{id}
+    resource: {type: document, id: "{id}"}
+    permission: view
+`, "must be either a literal or a single variable"},
+		{"relative path", `
+subject: {type: user}
+rules:
+  - path: documents/{id}
+    resource: {type: document, id: "{id}"}
+    permission: view
+`, "must start with `/`"},
+		{"missing permission", `
+subject: {type: user}
+rules:
+  - path: /documents/{id}
+    resource: {type: document, id: "{id}"}
+`, "permission are required"},
+		{"unknown field", `
+subject: {type: user, kind: user}
+`, "field kind not found"},
+	}
+
+	for _, testcase := range testcases {
+		testcase := testcase
+		t.Run(testcase.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(testcase.config))
+			if testcase.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, testcase.expectedError)
+		})
+	}
+}
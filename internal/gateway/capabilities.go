@@ -0,0 +1,160 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/namespace"
+	iv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+// CapabilitiesPath is the path under which the experimental capabilities API is served.
+const CapabilitiesPath = "/v1/experimental/capabilities"
+
+// CapabilitiesResponse lists the permissions held by a subject on resources.
+type CapabilitiesResponse struct {
+	CheckedAt *v1.ZedToken           `json:"checkedAt"`
+	Resources []ResourceCapabilities `json:"resources"`
+}
+
+// ResourceCapabilities maps each permission of a resource to the permissionship of the subject,
+// such as `PERMISSIONSHIP_HAS_PERMISSION`.
+type ResourceCapabilities struct {
+	ResourceObjectID string            `json:"resourceObjectId"`
+	Permissions      map[string]string `json:"permissions"`
+}
+
+// NewCapabilitiesHandler returns an http.Handler serving the experimental capabilities API: given
+// resources of a type and a subject, it returns which of the permissions of the type the subject
+// holds on each resource, so that a matrix of capabilities can be rendered with a single call.
+//
+// The resources are given by repeating `resource_object_id`. All the permissions are checked with
+// a single call to CheckBulkPermissions, which dispatches the checks of a permission over all the
+// resources together and shares the results of their common subproblems. Permissions depending on
+// a caveat are reported as `PERMISSIONSHIP_CONDITIONAL_PERMISSION`, as no caveat context is sent.
+func NewCapabilitiesHandler(conn grpc.ClientConnInterface) http.Handler {
+	schemaClient := v1.NewSchemaServiceClient(conn)
+	permissionsClient := v1.NewPermissionsServiceClient(conn)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "capabilities must be requested with GET", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		resourceType := query.Get("resource_object_type")
+		resourceIDs := query["resource_object_id"]
+		subject := &v1.SubjectReference{
+			Object: &v1.ObjectReference{
+				ObjectType: query.Get("subject_object_type"),
+				ObjectId:   query.Get("subject_object_id"),
+			},
+			OptionalRelation: query.Get("optional_subject_relation"),
+		}
+		if resourceType == "" || len(resourceIDs) == 0 || subject.Object.ObjectType == "" || subject.Object.ObjectId == "" {
+			http.Error(w, "resource_object_type, resource_object_id, subject_object_type and subject_object_id are required", http.StatusBadRequest)
+			return
+		}
+
+		consistency := &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}
+		if token := query.Get("at_least_as_fresh"); token != "" {
+			consistency = &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: &v1.ZedToken{Token: token}}}
+		}
+
+		ctx := outgoingContext(r)
+		permissions, err := definitionPermissions(ctx, schemaClient, resourceType)
+		if err != nil {
+			writeGRPCError(w, err)
+			return
+		}
+
+		var checkedAt *v1.ZedToken
+		byResource := make(map[string]map[string]string, len(resourceIDs))
+		for _, resourceID := range resourceIDs {
+			byResource[resourceID] = make(map[string]string, len(permissions))
+		}
+
+		if len(permissions) > 0 {
+			items := make([]*v1.CheckBulkPermissionsRequestItem, 0, len(resourceIDs)*len(permissions))
+			for _, resourceID := range resourceIDs {
+				for _, permission := range permissions {
+					items = append(items, &v1.CheckBulkPermissionsRequestItem{
+						Resource:   &v1.ObjectReference{ObjectType: resourceType, ObjectId: resourceID},
+						Permission: permission,
+						Subject:    subject,
+					})
+				}
+			}
+
+			checked, err := permissionsClient.CheckBulkPermissions(ctx, &v1.CheckBulkPermissionsRequest{
+				Consistency: consistency,
+				Items:       items,
+			})
+			if err != nil {
+				writeGRPCError(w, err)
+				return
+			}
+
+			checkedAt = checked.CheckedAt
+			for _, pair := range checked.Pairs {
+				if pairErr := pair.GetError(); pairErr != nil {
+					writeGRPCError(w, status.ErrorProto(pairErr))
+					return
+				}
+				byResource[pair.Request.Resource.ObjectId][pair.Request.Permission] = pair.GetItem().Permissionship.String()
+			}
+		}
+
+		resp := CapabilitiesResponse{CheckedAt: checkedAt, Resources: make([]ResourceCapabilities, 0, len(resourceIDs))}
+		for _, resourceID := range resourceIDs {
+			resp.Resources = append(resp.Resources, ResourceCapabilities{
+				ResourceObjectID: resourceID,
+				Permissions:      byResource[resourceID],
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// definitionPermissions returns the names of the permissions of the definition in the schema.
+func definitionPermissions(ctx context.Context, client v1.SchemaServiceClient, definitionName string) ([]string, error) {
+	resp, err := client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: resp.SchemaText,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to compile schema: %s", err)
+	}
+
+	for _, def := range compiled.ObjectDefinitions {
+		if def.Name != definitionName {
+			continue
+		}
+
+		var permissions []string
+		for _, relation := range def.Relation {
+			if namespace.GetRelationKind(relation) == iv1.RelationMetadata_PERMISSION {
+				permissions = append(permissions, relation.Name)
+			}
+		}
+		return permissions, nil
+	}
+	return nil, status.Errorf(codes.FailedPrecondition, "object definition `%s` not found", definitionName)
+}
@@ -0,0 +1,132 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/gateway"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+const capabilitiesSchema = `
+definition user {}
+
+definition document {
+	relation owner: user
+	relation viewer: user
+	relation reviewer: user with on_weekdays
+
+	permission edit = owner
+	permission view = viewer + edit
+	permission review = reviewer
+}
+
+definition folder {
+	relation viewer: user
+}
+
+caveat on_weekdays(weekday int) {
+	weekday < 6
+}
+`
+
+func TestCapabilities(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, false, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{Schema: capabilitiesSchema})
+	require.NoError(t, err)
+
+	client := v1.NewPermissionsServiceClient(conn)
+	revision := writeRelationships(t, client, v1.RelationshipUpdate_OPERATION_TOUCH,
+		"document:first#owner@user:tom",
+		"document:second#viewer@user:tom",
+		"document:second#reviewer@user:tom[on_weekdays]",
+	)
+
+	handler := gateway.NewCapabilitiesHandler(conn)
+	capabilities := func(query url.Values) (int, gateway.CapabilitiesResponse) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, gateway.CapabilitiesPath+"?"+query.Encode(), nil))
+
+		var resp gateway.CapabilitiesResponse
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+		}
+		return recorder.Code, resp
+	}
+
+	code, resp := capabilities(url.Values{
+		"resource_object_type": {"document"},
+		"resource_object_id":   {"first", "second", "third"},
+		"subject_object_type":  {"user"},
+		"subject_object_id":    {"tom"},
+		"at_least_as_fresh":    {revision.Token},
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.NotNil(t, resp.CheckedAt)
+	require.Equal(t, []gateway.ResourceCapabilities{
+		{
+			ResourceObjectID: "first",
+			Permissions: map[string]string{
+				"edit":   "PERMISSIONSHIP_HAS_PERMISSION",
+				"view":   "PERMISSIONSHIP_HAS_PERMISSION",
+				"review": "PERMISSIONSHIP_NO_PERMISSION",
+			},
+		},
+		{
+			ResourceObjectID: "second",
+			Permissions: map[string]string{
+				"edit":   "PERMISSIONSHIP_NO_PERMISSION",
+				"view":   "PERMISSIONSHIP_HAS_PERMISSION",
+				"review": "PERMISSIONSHIP_CONDITIONAL_PERMISSION",
+			},
+		},
+		{
+			ResourceObjectID: "third",
+			Permissions: map[string]string{
+				"edit":   "PERMISSIONSHIP_NO_PERMISSION",
+				"view":   "PERMISSIONSHIP_NO_PERMISSION",
+				"review": "PERMISSIONSHIP_NO_PERMISSION",
+			},
+		},
+	}, resp.Resources)
+
+	// A definition without permissions has no capabilities.
+	code, resp = capabilities(url.Values{
+		"resource_object_type": {"folder"},
+		"resource_object_id":   {"root"},
+		"subject_object_type":  {"user"},
+		"subject_object_id":    {"tom"},
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []gateway.ResourceCapabilities{{ResourceObjectID: "root", Permissions: map[string]string{}}}, resp.Resources)
+
+	code, _ = capabilities(url.Values{
+		"resource_object_type": {"unknown"},
+		"resource_object_id":   {"first"},
+		"subject_object_type":  {"user"},
+		"subject_object_id":    {"tom"},
+	})
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = capabilities(url.Values{
+		"resource_object_type": {"document"},
+		"resource_object_id":   {"first"},
+		"subject_object_type":  {"unknown"},
+		"subject_object_id":    {"tom"},
+	})
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = capabilities(url.Values{"resource_object_type": {"document"}})
+	require.Equal(t, http.StatusBadRequest, code)
+}
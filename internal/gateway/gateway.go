@@ -4,6 +4,7 @@ package gateway
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,6 +24,9 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/datastore"
 )
 
 var histogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
@@ -32,50 +36,84 @@ var histogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
 	Help:      "A histogram of the duration spent processing requests to the SpiceDB REST Gateway.",
 }, []string{"method"})
 
+// Config is the configuration of the REST gateway handler.
+type Config struct {
+	// UpstreamAddr is the address of the SpiceDB gRPC server to which requests are forwarded.
+	UpstreamAddr string
+
+	// UpstreamTLSCertPath is the path to the certificate of the upstream server. If empty, the
+	// connection to the upstream server is not encrypted.
+	UpstreamTLSCertPath string
+
+	// GraphQLEnabled serves a GraphQL endpoint under GraphQLPath.
+	GraphQLEnabled bool
+
+	// OPAEnabled serves relationship snapshots as OPA data under OPABundlePath and OPADataPath.
+	OPAEnabled bool
+
+	// LookupWatchEnabled serves the experimental LookupWatch API under LookupWatchPath.
+	LookupWatchEnabled bool
+
+	// SimulateEnabled serves the experimental simulation API under SimulatePath. It requires the
+	// Datastore.
+	SimulateEnabled bool
+
+	// Datastore is the datastore of the upstream server, over which changes are simulated.
+	Datastore datastore.Datastore
+
+	// CapabilitiesEnabled serves the experimental capabilities API under CapabilitiesPath.
+	CapabilitiesEnabled bool
+
+	// KetoSubjectType, if not empty, serves the read and check APIs of Ory Keto under
+	// KetoRelationTuplesPath, with Keto subject IDs mapped onto objects of that type.
+	KetoSubjectType string
+}
+
 // NewHandler creates an REST gateway HTTP CloserHandler with the provided upstream
-// configuration.
-func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string) (*CloserHandler, error) {
-	if upstreamAddr == "" {
+// configuration. Every API is served over a single connection to the upstream server, closed when the
+// handler is closed.
+func NewHandler(ctx context.Context, config Config) (*CloserHandler, error) {
+	if config.UpstreamAddr == "" {
 		return nil, fmt.Errorf("upstreamAddr must not be empty")
 	}
 
 	opts := []grpc.DialOption{
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	}
-	if upstreamTLSCertPath == "" {
+	if config.UpstreamTLSCertPath == "" {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	} else {
-		certsOpt, err := grpcutil.WithCustomCerts(grpcutil.SkipVerifyCA, upstreamTLSCertPath)
+		certsOpt, err := grpcutil.WithCustomCerts(grpcutil.SkipVerifyCA, config.UpstreamTLSCertPath)
 		if err != nil {
 			return nil, err
 		}
 		opts = append(opts, certsOpt)
 	}
 
-	healthConn, err := grpc.DialContext(ctx, upstreamAddr, opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	gwMux := runtime.NewServeMux(runtime.WithMetadata(OtelAnnotator), runtime.WithHealthzEndpoint(healthpb.NewHealthClient(healthConn)))
-	schemaConn, err := registerHandler(ctx, gwMux, upstreamAddr, opts, v1.RegisterSchemaServiceHandler)
+	conn, err := grpc.DialContext(ctx, config.UpstreamAddr, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	permissionsConn, err := registerHandler(ctx, gwMux, upstreamAddr, opts, v1.RegisterPermissionsServiceHandler)
-	if err != nil {
-		return nil, err
-	}
-
-	watchConn, err := registerHandler(ctx, gwMux, upstreamAddr, opts, v1.RegisterWatchServiceHandler)
+	handler, err := newHandler(ctx, conn, config)
 	if err != nil {
+		_ = conn.Close()
 		return nil, err
 	}
+	return newCloserHandler(handler, conn), nil
+}
 
-	experimentalConn, err := registerHandler(ctx, gwMux, upstreamAddr, opts, v1.RegisterExperimentalServiceHandler)
-	if err != nil {
-		return nil, err
+func newHandler(ctx context.Context, conn *grpc.ClientConn, config Config) (http.Handler, error) {
+	gwMux := runtime.NewServeMux(runtime.WithMetadata(OtelAnnotator), runtime.WithHealthzEndpoint(healthpb.NewHealthClient(conn)))
+	for _, register := range []HandlerRegisterer{
+		v1.RegisterSchemaServiceHandler,
+		v1.RegisterPermissionsServiceHandler,
+		v1.RegisterWatchServiceHandler,
+		v1.RegisterExperimentalServiceHandler,
+	} {
+		if err := register(ctx, gwMux, conn); err != nil {
+			return nil, err
+		}
 	}
 
 	mux := http.NewServeMux()
@@ -84,8 +122,43 @@ func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string) (
 	}))
 	mux.Handle("/", gwMux)
 
-	finalHandler := promhttp.InstrumentHandlerDuration(histogram, otelhttp.NewHandler(mux, "gateway"))
-	return newCloserHandler(finalHandler, schemaConn, permissionsConn, watchConn, healthConn, experimentalConn), nil
+	if config.GraphQLEnabled {
+		graphQLHandler, err := NewGraphQLHandler(conn)
+		if err != nil {
+			return nil, err
+		}
+		mux.Handle(GraphQLPath, graphQLHandler)
+	}
+
+	if config.OPAEnabled {
+		opaHandler := NewOPAHandler(conn)
+		mux.Handle(OPABundlePath, opaHandler)
+		mux.Handle(OPADataPath, opaHandler)
+	}
+
+	if config.LookupWatchEnabled {
+		mux.Handle(LookupWatchPath, NewLookupWatchHandler(conn))
+	}
+
+	if config.SimulateEnabled {
+		if config.Datastore == nil {
+			return nil, errors.New("the simulation API requires the datastore of the upstream server")
+		}
+		mux.Handle(SimulatePath, NewSimulateHandler(conn, config.Datastore))
+	}
+
+	if config.CapabilitiesEnabled {
+		mux.Handle(CapabilitiesPath, NewCapabilitiesHandler(conn))
+	}
+
+	if config.KetoSubjectType != "" {
+		ketoHandler := NewKetoHandler(conn, config.KetoSubjectType)
+		mux.Handle(KetoRelationTuplesPath, ketoHandler)
+		mux.Handle(KetoCheckPath, ketoHandler)
+		mux.Handle(KetoCheckOpenAPIPath, ketoHandler)
+	}
+
+	return promhttp.InstrumentHandlerDuration(histogram, otelhttp.NewHandler(mux, "gateway")), nil
 }
 
 // CloserHandler is a http.Handler and a io.Closer. Meant to keep track of resources to closer
@@ -119,29 +192,6 @@ func (cdh CloserHandler) Close() error {
 // HandlerRegisterer is a function that registers a Gateway Handler in a ServeMux
 type HandlerRegisterer func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error
 
-// registerHandler will open a connection with the provided grpc.DialOptions against the endpoint, and
-// will use it to invoke an HTTP Gateway handler factory method HandlerRegisterer. It returns the gRPC
-// connection.
-//
-// gRPC generated code does not expose a means to close the opened connections other than implicitly via
-// context cancellation. This factory method makes closing them explicit.
-func registerHandler(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption,
-	registerer HandlerRegisterer,
-) (*grpc.ClientConn, error) {
-	conn, err := grpc.Dial(endpoint, opts...)
-	if err != nil {
-		return nil, err
-	}
-	if err := registerer(ctx, mux, conn); err != nil {
-		if connerr := conn.Close(); connerr != nil {
-			return nil, err
-		}
-		return nil, err
-	}
-
-	return conn, nil
-}
-
 var defaultOtelOpts = []otelgrpc.Option{
 	otelgrpc.WithPropagators(otel.GetTextMapPropagator()),
 	otelgrpc.WithTracerProvider(otel.GetTracerProvider()),
@@ -157,3 +207,18 @@ func OtelAnnotator(ctx context.Context, r *http.Request) metadata.MD {
 	otelgrpc.Inject(ctx, &metadataCopy, defaultOtelOpts...)
 	return metadataCopy
 }
+
+// outgoingContext returns the context of calls to the upstream for the request, forwarding its
+// tracing context and Authorization header.
+func outgoingContext(r *http.Request) context.Context {
+	md := OtelAnnotator(r.Context(), r)
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		md.Set("authorization", authorization)
+	}
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+func writeGRPCError(w http.ResponseWriter, err error) {
+	s := status.Convert(err)
+	http.Error(w, s.Message(), runtime.HTTPStatusFromCode(s.Code()))
+}
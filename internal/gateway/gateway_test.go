@@ -11,6 +11,8 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/goleak"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
 )
 
 func TestOtelForwarding(t *testing.T) {
@@ -47,11 +49,34 @@ func TestOtelForwarding(t *testing.T) {
 func TestCloseConnections(t *testing.T) {
 	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
 
-	gatewayHandler, err := NewHandler(context.Background(), "192.0.2.0:4321", "")
-	require.NoError(t, err)
-	// 4 conns for permission+schema+watch+experimental services, 1 for health check
-	require.Len(t, gatewayHandler.closers, 5)
+	for _, config := range []Config{
+		{},
+		{GraphQLEnabled: true},
+		{OPAEnabled: true},
+		{LookupWatchEnabled: true},
+		{SimulateEnabled: true},
+		{CapabilitiesEnabled: true},
+		{KetoSubjectType: "user"},
+		{GraphQLEnabled: true, OPAEnabled: true, LookupWatchEnabled: true, SimulateEnabled: true, CapabilitiesEnabled: true, KetoSubjectType: "user"},
+	} {
+		config.UpstreamAddr = "192.0.2.0:4321"
+		if config.SimulateEnabled {
+			ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+			require.NoError(t, err)
+			config.Datastore = ds
+		}
+
+		gatewayHandler, err := NewHandler(context.Background(), config)
+		require.NoError(t, err)
+		// every API shares a single conn
+		require.Len(t, gatewayHandler.closers, 1)
+
+		// if connections are not closed, goleak would detect it
+		require.NoError(t, gatewayHandler.Close())
+	}
+}
 
-	// if connections are not closed, goleak would detect it
-	require.NoError(t, gatewayHandler.Close())
+func TestSimulateRequiresDatastore(t *testing.T) {
+	_, err := NewHandler(context.Background(), Config{UpstreamAddr: "192.0.2.0:4321", SimulateEnabled: true})
+	require.ErrorContains(t, err, "requires the datastore")
 }
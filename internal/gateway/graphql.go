@@ -0,0 +1,444 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	graphql "github.com/graph-gophers/graphql-go"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// GraphQLPath is the path under which the GraphQL endpoint is served by the gateway.
+const GraphQLPath = "/graphql"
+
+const (
+	maxGraphQLRequestBytes = 1 << 20
+
+	// maxGraphQLDepth is the maximum nesting depth of the fields of a query. The schema itself is
+	// only two levels deep, but the introspection query sent by GraphiQL and similar tools nests
+	// thirteen levels deep.
+	maxGraphQLDepth = 15
+
+	// maxGraphQLPermissionQueries is the maximum number of permission queries, each sent upstream
+	// as a separate call, run for a single request, including any repeated under aliases.
+	maxGraphQLPermissionQueries = 20
+
+	// maxGraphQLParallelism is the maximum number of permission queries of a single request run
+	// concurrently.
+	maxGraphQLParallelism = 4
+)
+
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	# Checks whether the subject has the permission on the resource.
+	permissionCheck(resource: ObjectInput!, permission: String!, subject: SubjectInput!, consistency: ConsistencyInput): PermissionCheckResult!
+
+	# Returns the resources of the given type on which the subject has the permission.
+	accessibleResources(resourceType: String!, permission: String!, subject: SubjectInput!, consistency: ConsistencyInput, limit: Int): [AccessibleResource!]!
+
+	# Returns the subjects of the given type that have the permission on the resource.
+	permittedSubjects(resource: ObjectInput!, permission: String!, subjectType: String!, subjectRelation: String, consistency: ConsistencyInput): [PermittedSubject!]!
+
+	# Expands the permission on the resource into a JSON encoded permission tree.
+	expandPermission(resource: ObjectInput!, permission: String!, consistency: ConsistencyInput): ExpandedPermission!
+}
+
+input ObjectInput {
+	type: String!
+	id: String!
+}
+
+input SubjectInput {
+	type: String!
+	id: String!
+	relation: String
+}
+
+# At most one field may be set. When none are, the results are minimize-latency.
+input ConsistencyInput {
+	fullyConsistent: Boolean
+	atLeastAsFresh: String
+	atExactSnapshot: String
+}
+
+enum Permissionship {
+	NO_PERMISSION
+	HAS_PERMISSION
+	CONDITIONAL_PERMISSION
+}
+
+type PermissionCheckResult {
+	permissionship: Permissionship!
+	checkedAt: String!
+}
+
+type AccessibleResource {
+	resourceId: String!
+	permissionship: Permissionship!
+	lookedUpAt: String!
+}
+
+type PermittedSubject {
+	subjectId: String!
+	excludedSubjectIds: [String!]!
+	permissionship: Permissionship!
+	lookedUpAt: String!
+}
+
+type ExpandedPermission {
+	tree: String!
+	expandedAt: String!
+}
+`
+
+// NewGraphQLHandler returns an http.Handler serving a GraphQL endpoint over the permissions
+// service found on the provided connection.
+//
+// The Authorization header of each request is forwarded to the upstream, so the endpoint is
+// subject to the same authentication as the gRPC API.
+func NewGraphQLHandler(conn grpc.ClientConnInterface) (http.Handler, error) {
+	schema, err := graphql.ParseSchema(graphQLSchema, &graphQLResolver{v1.NewPermissionsServiceClient(conn)},
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(maxGraphQLDepth),
+		graphql.MaxParallelism(maxGraphQLParallelism),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing GraphQL schema: %w", err)
+	}
+	return &graphQLHandler{schema}, nil
+}
+
+type graphQLHandler struct {
+	schema *graphql.Schema
+}
+
+func (h *graphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "GraphQL queries must be sent with POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var params struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxGraphQLRequestBytes)).Decode(&params); err != nil {
+		http.Error(w, "invalid GraphQL request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.WithValue(outgoingContext(r), graphQLBudgetKey{}, new(atomic.Int32))
+	response := h.schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(encoded)
+}
+
+type graphQLBudgetKey struct{}
+
+// spendGraphQLBudget returns an error if the request has already run the maximum number of
+// permission queries.
+func spendGraphQLBudget(ctx context.Context) error {
+	if spent, ok := ctx.Value(graphQLBudgetKey{}).(*atomic.Int32); ok && spent.Add(1) > maxGraphQLPermissionQueries {
+		return fmt.Errorf("a request may run at most %d permission queries", maxGraphQLPermissionQueries)
+	}
+	return nil
+}
+
+type objectInput struct {
+	Type string
+	ID   string
+}
+
+func (o objectInput) toObjectReference() *v1.ObjectReference {
+	return &v1.ObjectReference{ObjectType: o.Type, ObjectId: o.ID}
+}
+
+type subjectInput struct {
+	Type     string
+	ID       string
+	Relation *string
+}
+
+func (s subjectInput) toSubjectReference() *v1.SubjectReference {
+	subject := &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: s.Type, ObjectId: s.ID}}
+	if s.Relation != nil {
+		subject.OptionalRelation = *s.Relation
+	}
+	return subject
+}
+
+type consistencyInput struct {
+	FullyConsistent *bool
+	AtLeastAsFresh  *string
+	AtExactSnapshot *string
+}
+
+var errMultipleConsistencies = errors.New("at most one consistency requirement may be specified")
+
+func toConsistency(input *consistencyInput) (*v1.Consistency, error) {
+	if input == nil {
+		return nil, nil
+	}
+
+	var found []*v1.Consistency
+	if input.FullyConsistent != nil && *input.FullyConsistent {
+		found = append(found, &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}})
+	}
+	if input.AtLeastAsFresh != nil {
+		found = append(found, &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: &v1.ZedToken{Token: *input.AtLeastAsFresh}}})
+	}
+	if input.AtExactSnapshot != nil {
+		found = append(found, &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: &v1.ZedToken{Token: *input.AtExactSnapshot}}})
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return found[0], nil
+	default:
+		return nil, errMultipleConsistencies
+	}
+}
+
+const (
+	permissionshipNone        = "NO_PERMISSION"
+	permissionshipHas         = "HAS_PERMISSION"
+	permissionshipConditional = "CONDITIONAL_PERMISSION"
+)
+
+func lookupPermissionship(permissionship v1.LookupPermissionship) string {
+	if permissionship == v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION {
+		return permissionshipConditional
+	}
+	return permissionshipHas
+}
+
+type permissionCheckResult struct {
+	Permissionship string
+	CheckedAt      string
+}
+
+type accessibleResource struct {
+	ResourceID     string
+	Permissionship string
+	LookedUpAt     string
+}
+
+type permittedSubject struct {
+	SubjectID          string
+	ExcludedSubjectIDs []string
+	Permissionship     string
+	LookedUpAt         string
+}
+
+type expandedPermission struct {
+	Tree       string
+	ExpandedAt string
+}
+
+type graphQLResolver struct {
+	client v1.PermissionsServiceClient
+}
+
+func (gr *graphQLResolver) PermissionCheck(ctx context.Context, args struct {
+	Resource    objectInput
+	Permission  string
+	Subject     subjectInput
+	Consistency *consistencyInput
+},
+) (*permissionCheckResult, error) {
+	if err := spendGraphQLBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	consistency, err := toConsistency(args.Consistency)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := gr.client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Consistency: consistency,
+		Resource:    args.Resource.toObjectReference(),
+		Permission:  args.Permission,
+		Subject:     args.Subject.toSubjectReference(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	permissionship := permissionshipNone
+	switch resp.Permissionship {
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION:
+		permissionship = permissionshipHas
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+		permissionship = permissionshipConditional
+	}
+
+	return &permissionCheckResult{
+		Permissionship: permissionship,
+		CheckedAt:      resp.CheckedAt.GetToken(),
+	}, nil
+}
+
+func (gr *graphQLResolver) AccessibleResources(ctx context.Context, args struct {
+	ResourceType string
+	Permission   string
+	Subject      subjectInput
+	Consistency  *consistencyInput
+	Limit        *int32
+},
+) ([]*accessibleResource, error) {
+	if err := spendGraphQLBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	consistency, err := toConsistency(args.Consistency)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit uint32
+	if args.Limit != nil {
+		if *args.Limit < 0 {
+			return nil, errors.New("limit must not be negative")
+		}
+		limit = uint32(*args.Limit)
+	}
+
+	stream, err := gr.client.LookupResources(ctx, &v1.LookupResourcesRequest{
+		Consistency:        consistency,
+		ResourceObjectType: args.ResourceType,
+		Permission:         args.Permission,
+		Subject:            args.Subject.toSubjectReference(),
+		OptionalLimit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*accessibleResource, 0)
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		results = append(results, &accessibleResource{
+			ResourceID:     resp.ResourceObjectId,
+			Permissionship: lookupPermissionship(resp.Permissionship),
+			LookedUpAt:     resp.LookedUpAt.GetToken(),
+		})
+	}
+}
+
+func (gr *graphQLResolver) PermittedSubjects(ctx context.Context, args struct {
+	Resource        objectInput
+	Permission      string
+	SubjectType     string
+	SubjectRelation *string
+	Consistency     *consistencyInput
+},
+) ([]*permittedSubject, error) {
+	if err := spendGraphQLBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	consistency, err := toConsistency(args.Consistency)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &v1.LookupSubjectsRequest{
+		Consistency:       consistency,
+		Resource:          args.Resource.toObjectReference(),
+		Permission:        args.Permission,
+		SubjectObjectType: args.SubjectType,
+	}
+	if args.SubjectRelation != nil {
+		req.OptionalSubjectRelation = *args.SubjectRelation
+	}
+
+	stream, err := gr.client.LookupSubjects(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*permittedSubject, 0)
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		excluded := make([]string, 0, len(resp.ExcludedSubjects))
+		for _, excludedSubject := range resp.ExcludedSubjects {
+			excluded = append(excluded, excludedSubject.SubjectObjectId)
+		}
+
+		results = append(results, &permittedSubject{
+			SubjectID:          resp.Subject.GetSubjectObjectId(),
+			ExcludedSubjectIDs: excluded,
+			Permissionship:     lookupPermissionship(resp.Subject.GetPermissionship()),
+			LookedUpAt:         resp.LookedUpAt.GetToken(),
+		})
+	}
+}
+
+func (gr *graphQLResolver) ExpandPermission(ctx context.Context, args struct {
+	Resource    objectInput
+	Permission  string
+	Consistency *consistencyInput
+},
+) (*expandedPermission, error) {
+	if err := spendGraphQLBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	consistency, err := toConsistency(args.Consistency)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := gr.client.ExpandPermissionTree(ctx, &v1.ExpandPermissionTreeRequest{
+		Consistency: consistency,
+		Resource:    args.Resource.toObjectReference(),
+		Permission:  args.Permission,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := protojson.Marshal(resp.TreeRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding permission tree: %w", err)
+	}
+
+	return &expandedPermission{
+		Tree:       string(tree),
+		ExpandedAt: resp.ExpandedAt.GetToken(),
+	}, nil
+}
@@ -0,0 +1,293 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakePermissionsServer struct {
+	v1.UnimplementedPermissionsServiceServer
+}
+
+func requireAuthorization(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) != 1 || values[0] != "Bearer somekey" {
+		return status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+	return nil
+}
+
+func (fakePermissionsServer) CheckPermission(ctx context.Context, req *v1.CheckPermissionRequest) (*v1.CheckPermissionResponse, error) {
+	if err := requireAuthorization(ctx); err != nil {
+		return nil, err
+	}
+
+	permissionship := v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
+	if req.Subject.Object.ObjectId == "tom" {
+		permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+	}
+	if req.Consistency.GetFullyConsistent() {
+		return &v1.CheckPermissionResponse{CheckedAt: &v1.ZedToken{Token: "full"}, Permissionship: permissionship}, nil
+	}
+	return &v1.CheckPermissionResponse{CheckedAt: &v1.ZedToken{Token: "sometoken"}, Permissionship: permissionship}, nil
+}
+
+func (fakePermissionsServer) LookupResources(req *v1.LookupResourcesRequest, stream v1.PermissionsService_LookupResourcesServer) error {
+	if err := requireAuthorization(stream.Context()); err != nil {
+		return err
+	}
+
+	for _, resourceID := range []string{"first", "second", "third"}[:req.OptionalLimit] {
+		if err := stream.Send(&v1.LookupResourcesResponse{
+			LookedUpAt:       &v1.ZedToken{Token: "sometoken"},
+			ResourceObjectId: resourceID,
+			Permissionship:   v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fakePermissionsServer) LookupSubjects(_ *v1.LookupSubjectsRequest, stream v1.PermissionsService_LookupSubjectsServer) error {
+	if err := requireAuthorization(stream.Context()); err != nil {
+		return err
+	}
+
+	return stream.Send(&v1.LookupSubjectsResponse{
+		LookedUpAt: &v1.ZedToken{Token: "sometoken"},
+		Subject: &v1.ResolvedSubject{
+			SubjectObjectId: "*",
+			Permissionship:  v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION,
+		},
+		ExcludedSubjects: []*v1.ResolvedSubject{{SubjectObjectId: "fred"}},
+	})
+}
+
+func (fakePermissionsServer) ExpandPermissionTree(ctx context.Context, _ *v1.ExpandPermissionTreeRequest) (*v1.ExpandPermissionTreeResponse, error) {
+	if err := requireAuthorization(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.ExpandPermissionTreeResponse{
+		ExpandedAt: &v1.ZedToken{Token: "sometoken"},
+		TreeRoot: &v1.PermissionRelationshipTree{
+			ExpandedObject:   &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+			ExpandedRelation: "view",
+		},
+	}, nil
+}
+
+func newTestGraphQLHandler(t *testing.T) http.Handler {
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	v1.RegisterPermissionsServiceServer(srv, fakePermissionsServer{})
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	handler, err := NewGraphQLHandler(conn)
+	require.NoError(t, err)
+	return handler
+}
+
+func serveGraphQL(t *testing.T, handler http.Handler, query string, authorization string) (json.RawMessage, []string) {
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, GraphQLPath, strings.NewReader(string(body)))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+
+	messages := make([]string, 0, len(response.Errors))
+	for _, err := range response.Errors {
+		messages = append(messages, err.Message)
+	}
+	return response.Data, messages
+}
+
+func TestGraphQLHandler(t *testing.T) {
+	handler := newTestGraphQLHandler(t)
+
+	testCases := []struct {
+		name          string
+		query         string
+		authorization string
+		expectedData  string
+		expectedError string
+	}{
+		{
+			"check",
+			`{ permissionCheck(resource: {type: "document", id: "first"}, permission: "view", subject: {type: "user", id: "tom"}) { permissionship checkedAt } }`,
+			"Bearer somekey",
+			`{"permissionCheck":{"permissionship":"HAS_PERMISSION","checkedAt":"sometoken"}}`,
+			"",
+		},
+		{
+			"check with consistency",
+			`{ permissionCheck(resource: {type: "document", id: "first"}, permission: "view", subject: {type: "user", id: "fred"}, consistency: {fullyConsistent: true}) { permissionship checkedAt } }`,
+			"Bearer somekey",
+			`{"permissionCheck":{"permissionship":"NO_PERMISSION","checkedAt":"full"}}`,
+			"",
+		},
+		{
+			"check with multiple consistencies",
+			`{ permissionCheck(resource: {type: "document", id: "first"}, permission: "view", subject: {type: "user", id: "fred"}, consistency: {fullyConsistent: true, atLeastAsFresh: "sometoken"}) { permissionship } }`,
+			"Bearer somekey",
+			"",
+			errMultipleConsistencies.Error(),
+		},
+		{
+			"accessible resources",
+			`{ accessibleResources(resourceType: "document", permission: "view", subject: {type: "user", id: "tom"}, limit: 2) { resourceId permissionship } }`,
+			"Bearer somekey",
+			`{"accessibleResources":[{"resourceId":"first","permissionship":"HAS_PERMISSION"},{"resourceId":"second","permissionship":"HAS_PERMISSION"}]}`,
+			"",
+		},
+		{
+			"permitted subjects",
+			`{ permittedSubjects(resource: {type: "document", id: "first"}, permission: "view", subjectType: "user") { subjectId excludedSubjectIds permissionship } }`,
+			"Bearer somekey",
+			`{"permittedSubjects":[{"subjectId":"*","excludedSubjectIds":["fred"],"permissionship":"CONDITIONAL_PERMISSION"}]}`,
+			"",
+		},
+		{
+			"expand",
+			`{ expandPermission(resource: {type: "document", id: "first"}, permission: "view") { expandedAt } }`,
+			"Bearer somekey",
+			`{"expandPermission":{"expandedAt":"sometoken"}}`,
+			"",
+		},
+		{
+			"unauthenticated",
+			`{ permissionCheck(resource: {type: "document", id: "first"}, permission: "view", subject: {type: "user", id: "tom"}) { permissionship } }`,
+			"",
+			"",
+			"missing or invalid token",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			data, errs := serveGraphQL(t, handler, tc.query, tc.authorization)
+			if tc.expectedError != "" {
+				require.NotEmpty(t, errs)
+				require.Contains(t, errs[0], tc.expectedError)
+				return
+			}
+
+			require.Empty(t, errs)
+			require.JSONEq(t, tc.expectedData, string(data))
+		})
+	}
+}
+
+func TestGraphQLHandlerRequiresPost(t *testing.T) {
+	handler, err := NewGraphQLHandler(nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, GraphQLPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+const introspectionQuery = `
+query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    types { ...FullType }
+    directives { name description locations args { ...InputValue } }
+  }
+}
+
+fragment FullType on __Type {
+  kind name description
+  fields(includeDeprecated: true) { name description args { ...InputValue } type { ...TypeRef } isDeprecated deprecationReason }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name description type { ...TypeRef } defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+func TestGraphQLHandlerLimits(t *testing.T) {
+	handler := newTestGraphQLHandler(t)
+
+	t.Run("introspection", func(t *testing.T) {
+		data, errs := serveGraphQL(t, handler, introspectionQuery, "")
+		require.Empty(t, errs)
+		require.Contains(t, string(data), "permissionCheck")
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		query := "{ __schema { types { fields { type " + strings.Repeat("{ ofType ", 12) + "{ name }" + strings.Repeat(" }", 12) + " } } } }"
+		_, errs := serveGraphQL(t, handler, query, "")
+		require.NotEmpty(t, errs)
+		require.Contains(t, errs[0], "exceeds max depth")
+	})
+
+	checks := func(count int) string {
+		var query strings.Builder
+		query.WriteString("{")
+		for i := 0; i < count; i++ {
+			fmt.Fprintf(&query, ` c%d: permissionCheck(resource: {type: "document", id: "first"}, permission: "view", subject: {type: "user", id: "tom"}) { permissionship }`, i)
+		}
+		query.WriteString(" }")
+		return query.String()
+	}
+
+	t.Run("at most the maximum queries", func(t *testing.T) {
+		_, errs := serveGraphQL(t, handler, checks(maxGraphQLPermissionQueries), "Bearer somekey")
+		require.Empty(t, errs)
+	})
+
+	t.Run("too many queries", func(t *testing.T) {
+		_, errs := serveGraphQL(t, handler, checks(maxGraphQLPermissionQueries+1), "Bearer somekey")
+		require.NotEmpty(t, errs)
+		require.Contains(t, errs[0], "at most 20 permission queries")
+	})
+}
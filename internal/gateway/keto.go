@@ -0,0 +1,308 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// KetoRelationTuplesPath is the path under which relation tuples are read, as with the read
+	// API of Ory Keto.
+	KetoRelationTuplesPath = "/relation-tuples"
+
+	// KetoCheckPath is the path under which permissions are checked, responding with 403 Forbidden
+	// when they are denied.
+	KetoCheckPath = "/relation-tuples/check"
+
+	// KetoCheckOpenAPIPath is the path under which permissions are checked, always responding
+	// with 200 OK.
+	KetoCheckOpenAPIPath = "/relation-tuples/check/openapi"
+
+	defaultKetoPageSize = 100
+)
+
+// ketoSubjectSet is a subject set of a Keto relation tuple: the objects having the relation on
+// an object. An empty relation is the object itself.
+type ketoSubjectSet struct {
+	Namespace string `json:"namespace"`
+	Object    string `json:"object"`
+	Relation  string `json:"relation"`
+}
+
+// ketoRelationTuple is a Keto relation tuple, whose subject is either a subject ID or a subject set.
+type ketoRelationTuple struct {
+	Namespace  string          `json:"namespace"`
+	Object     string          `json:"object"`
+	Relation   string          `json:"relation"`
+	SubjectID  *string         `json:"subject_id,omitempty"`
+	SubjectSet *ketoSubjectSet `json:"subject_set,omitempty"`
+}
+
+type ketoReadResponse struct {
+	RelationTuples []ketoRelationTuple `json:"relation_tuples"`
+	NextPageToken  string              `json:"next_page_token"`
+}
+
+type ketoCheckResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+// NewKetoHandler returns an http.Handler serving the read and check REST APIs of Ory Keto, to ease
+// migrating clients off Keto.
+//
+// Keto namespaces are object types and Keto relations are relations or permissions. Keto subject
+// IDs, which have no type, are mapped onto objects of the given subject type, and subject sets
+// onto subjects with an optional relation.
+//
+// The Authorization header of each request is forwarded to the upstream, so the endpoints are
+// subject to the same authentication as the gRPC API.
+func NewKetoHandler(conn grpc.ClientConnInterface, subjectType string) http.Handler {
+	handler := &ketoHandler{
+		client:      v1.NewPermissionsServiceClient(conn),
+		subjectType: subjectType,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(KetoRelationTuplesPath, handler.serveRead)
+	mux.HandleFunc(KetoCheckPath, func(w http.ResponseWriter, r *http.Request) {
+		handler.serveCheck(w, r, true)
+	})
+	mux.HandleFunc(KetoCheckOpenAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		handler.serveCheck(w, r, false)
+	})
+	return mux
+}
+
+type ketoHandler struct {
+	client      v1.PermissionsServiceClient
+	subjectType string
+}
+
+func (h *ketoHandler) serveRead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeKetoError(w, http.StatusMethodNotAllowed, "relation tuples must be read with GET")
+		return
+	}
+
+	query := r.URL.Query()
+	tuple := ketoTupleFromQuery(query)
+	if tuple.Namespace == "" {
+		writeKetoError(w, http.StatusBadRequest, "a namespace is required")
+		return
+	}
+
+	pageSize := defaultKetoPageSize
+	if value := query.Get("page_size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeKetoError(w, http.StatusBadRequest, "invalid page_size: "+value)
+			return
+		}
+		pageSize = parsed
+	}
+
+	req := &v1.ReadRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{
+			ResourceType:          tuple.Namespace,
+			OptionalResourceId:    tuple.Object,
+			OptionalRelation:      tuple.Relation,
+			OptionalSubjectFilter: h.subjectFilter(tuple),
+		},
+		OptionalLimit: uint32(pageSize),
+	}
+	if token := query.Get("page_token"); token != "" {
+		req.OptionalCursor = &v1.Cursor{Token: token}
+	}
+
+	stream, err := h.client.ReadRelationships(outgoingContext(r), req)
+	if err != nil {
+		writeKetoGRPCError(w, err)
+		return
+	}
+
+	response := ketoReadResponse{RelationTuples: []ketoRelationTuple{}}
+	var lastCursor *v1.Cursor
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			writeKetoGRPCError(w, err)
+			return
+		}
+
+		response.RelationTuples = append(response.RelationTuples, h.tupleFromRelationship(resp.Relationship))
+		lastCursor = resp.AfterResultCursor
+	}
+
+	// A full page may be followed by more results.
+	if len(response.RelationTuples) == pageSize && lastCursor != nil {
+		response.NextPageToken = lastCursor.Token
+	}
+	writeKetoJSON(w, http.StatusOK, response)
+}
+
+func (h *ketoHandler) serveCheck(w http.ResponseWriter, r *http.Request, forbidDenied bool) {
+	var tuple ketoRelationTuple
+	switch r.Method {
+	case http.MethodGet:
+		tuple = ketoTupleFromQuery(r.URL.Query())
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&tuple); err != nil {
+			writeKetoError(w, http.StatusBadRequest, "invalid relation tuple: "+err.Error())
+			return
+		}
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		writeKetoError(w, http.StatusMethodNotAllowed, "permissions must be checked with GET or POST")
+		return
+	}
+
+	subject := h.subjectReference(tuple)
+	if tuple.Namespace == "" || tuple.Object == "" || tuple.Relation == "" || subject == nil {
+		writeKetoError(w, http.StatusBadRequest, "a namespace, object, relation and subject are required")
+		return
+	}
+
+	resp, err := h.client.CheckPermission(outgoingContext(r), &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: tuple.Namespace, ObjectId: tuple.Object},
+		Permission: tuple.Relation,
+		Subject:    subject,
+	})
+	if err != nil {
+		writeKetoGRPCError(w, err)
+		return
+	}
+
+	// Keto has no notion of caveats, so conditional permissions are denied.
+	allowed := resp.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+	code := http.StatusOK
+	if !allowed && forbidDenied {
+		code = http.StatusForbidden
+	}
+	writeKetoJSON(w, code, ketoCheckResponse{Allowed: allowed})
+}
+
+// ketoTupleFromQuery returns the relation tuple of the query parameters of Keto requests, where
+// subject sets are given as `subject_set.namespace`, `subject_set.object` and `subject_set.relation`.
+func ketoTupleFromQuery(query url.Values) ketoRelationTuple {
+	tuple := ketoRelationTuple{
+		Namespace: query.Get("namespace"),
+		Object:    query.Get("object"),
+		Relation:  query.Get("relation"),
+	}
+	if query.Has("subject_id") {
+		subjectID := query.Get("subject_id")
+		tuple.SubjectID = &subjectID
+	} else if query.Has("subject_set.namespace") {
+		tuple.SubjectSet = &ketoSubjectSet{
+			Namespace: query.Get("subject_set.namespace"),
+			Object:    query.Get("subject_set.object"),
+			Relation:  query.Get("subject_set.relation"),
+		}
+	}
+	return tuple
+}
+
+func (h *ketoHandler) subjectReference(tuple ketoRelationTuple) *v1.SubjectReference {
+	switch {
+	case tuple.SubjectID != nil:
+		return &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: h.subjectType, ObjectId: *tuple.SubjectID}}
+	case tuple.SubjectSet != nil:
+		return &v1.SubjectReference{
+			Object:           &v1.ObjectReference{ObjectType: tuple.SubjectSet.Namespace, ObjectId: tuple.SubjectSet.Object},
+			OptionalRelation: tuple.SubjectSet.Relation,
+		}
+	default:
+		return nil
+	}
+}
+
+func (h *ketoHandler) subjectFilter(tuple ketoRelationTuple) *v1.SubjectFilter {
+	switch {
+	case tuple.SubjectID != nil:
+		return &v1.SubjectFilter{
+			SubjectType:       h.subjectType,
+			OptionalSubjectId: *tuple.SubjectID,
+			OptionalRelation:  &v1.SubjectFilter_RelationFilter{},
+		}
+	case tuple.SubjectSet != nil:
+		return &v1.SubjectFilter{
+			SubjectType:       tuple.SubjectSet.Namespace,
+			OptionalSubjectId: tuple.SubjectSet.Object,
+			OptionalRelation:  &v1.SubjectFilter_RelationFilter{Relation: tuple.SubjectSet.Relation},
+		}
+	default:
+		return nil
+	}
+}
+
+func (h *ketoHandler) tupleFromRelationship(rel *v1.Relationship) ketoRelationTuple {
+	tuple := ketoRelationTuple{
+		Namespace: rel.Resource.ObjectType,
+		Object:    rel.Resource.ObjectId,
+		Relation:  rel.Relation,
+	}
+	if rel.Subject.Object.ObjectType == h.subjectType && rel.Subject.OptionalRelation == "" {
+		subjectID := rel.Subject.Object.ObjectId
+		tuple.SubjectID = &subjectID
+		return tuple
+	}
+
+	tuple.SubjectSet = &ketoSubjectSet{
+		Namespace: rel.Subject.Object.ObjectType,
+		Object:    rel.Subject.Object.ObjectId,
+		Relation:  rel.Subject.OptionalRelation,
+	}
+	return tuple
+}
+
+func writeKetoJSON(w http.ResponseWriter, code int, value any) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		writeKetoError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(encoded)
+}
+
+// writeKetoError writes an error in the format of the Keto API:
+//
+//	{"error": {"code": 400, "status": "Bad Request", "message": "..."}}
+func writeKetoError(w http.ResponseWriter, code int, message string) {
+	encoded, _ := json.Marshal(map[string]any{
+		"error": map[string]any{
+			"code":    code,
+			"status":  http.StatusText(code),
+			"message": message,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(encoded)
+}
+
+func writeKetoGRPCError(w http.ResponseWriter, err error) {
+	s := status.Convert(err)
+	if s.Code() == codes.FailedPrecondition {
+		// Unknown namespaces and relations are reported by Keto as bad requests.
+		writeKetoError(w, http.StatusBadRequest, s.Message())
+		return
+	}
+	writeKetoError(w, runtime.HTTPStatusFromCode(s.Code()), s.Message())
+}
@@ -0,0 +1,246 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeKetoPermissionsServer struct {
+	v1.UnimplementedPermissionsServiceServer
+
+	checked []*v1.CheckPermissionRequest
+	read    []*v1.ReadRelationshipsRequest
+}
+
+func (f *fakeKetoPermissionsServer) CheckPermission(ctx context.Context, req *v1.CheckPermissionRequest) (*v1.CheckPermissionResponse, error) {
+	if err := requireAuthorization(ctx); err != nil {
+		return nil, err
+	}
+	f.checked = append(f.checked, req)
+
+	if req.Resource.ObjectType == "unknown" {
+		return nil, status.Error(codes.FailedPrecondition, "object definition `unknown` not found")
+	}
+
+	permissionship := v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
+	if req.Subject.Object.ObjectId == "tom" {
+		permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+	}
+	return &v1.CheckPermissionResponse{Permissionship: permissionship}, nil
+}
+
+func (f *fakeKetoPermissionsServer) ReadRelationships(req *v1.ReadRelationshipsRequest, stream v1.PermissionsService_ReadRelationshipsServer) error {
+	if err := requireAuthorization(stream.Context()); err != nil {
+		return err
+	}
+	f.read = append(f.read, req)
+
+	relationships := []*v1.Relationship{
+		{
+			Resource: &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+			Relation: "viewer",
+			Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+		},
+		{
+			Resource: &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+			Relation: "viewer",
+			Subject: &v1.SubjectReference{
+				Object:           &v1.ObjectReference{ObjectType: "group", ObjectId: "eng"},
+				OptionalRelation: "member",
+			},
+		},
+	}
+
+	for index, rel := range relationships[:min(int(req.OptionalLimit), len(relationships))] {
+		if err := stream.Send(&v1.ReadRelationshipsResponse{
+			Relationship:      rel,
+			AfterResultCursor: &v1.Cursor{Token: "cursor" + strconv.Itoa(index)},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newKetoTestHandler(t *testing.T) (http.Handler, *fakeKetoPermissionsServer) {
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	permissions := &fakeKetoPermissionsServer{}
+	v1.RegisterPermissionsServiceServer(srv, permissions)
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return NewKetoHandler(conn, "user"), permissions
+}
+
+func serveKeto(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer somekey")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestKetoCheck(t *testing.T) {
+	handler, permissions := newKetoTestHandler(t)
+
+	testCases := []struct {
+		name            string
+		method          string
+		target          string
+		body            string
+		expectedStatus  int
+		expectedBody    string
+		expectedSubject string
+	}{
+		{
+			"allowed subject id",
+			http.MethodGet,
+			KetoCheckPath + "?namespace=document&object=first&relation=view&subject_id=tom",
+			"",
+			http.StatusOK,
+			`{"allowed": true}`,
+			"user:tom",
+		},
+		{
+			"denied subject id",
+			http.MethodGet,
+			KetoCheckPath + "?namespace=document&object=first&relation=view&subject_id=fred",
+			"",
+			http.StatusForbidden,
+			`{"allowed": false}`,
+			"user:fred",
+		},
+		{
+			"denied subject id with openapi",
+			http.MethodGet,
+			KetoCheckOpenAPIPath + "?namespace=document&object=first&relation=view&subject_id=fred",
+			"",
+			http.StatusOK,
+			`{"allowed": false}`,
+			"user:fred",
+		},
+		{
+			"subject set",
+			http.MethodGet,
+			KetoCheckPath + "?namespace=document&object=first&relation=view&subject_set.namespace=group&subject_set.object=eng&subject_set.relation=member",
+			"",
+			http.StatusForbidden,
+			`{"allowed": false}`,
+			"group:eng#member",
+		},
+		{
+			"posted tuple",
+			http.MethodPost,
+			KetoCheckOpenAPIPath,
+			`{"namespace": "document", "object": "first", "relation": "view", "subject_id": "tom"}`,
+			http.StatusOK,
+			`{"allowed": true}`,
+			"user:tom",
+		},
+		{
+			"missing subject",
+			http.MethodGet,
+			KetoCheckPath + "?namespace=document&object=first&relation=view",
+			"",
+			http.StatusBadRequest,
+			`{"error": {"code": 400, "status": "Bad Request", "message": "a namespace, object, relation and subject are required"}}`,
+			"",
+		},
+		{
+			"unknown namespace",
+			http.MethodGet,
+			KetoCheckPath + "?namespace=unknown&object=first&relation=view&subject_id=tom",
+			"",
+			http.StatusBadRequest,
+			"{\"error\": {\"code\": 400, \"status\": \"Bad Request\", \"message\": \"object definition `unknown` not found\"}}",
+			"user:tom",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			permissions.checked = nil
+
+			recorder := serveKeto(handler, tc.method, tc.target, tc.body)
+			require.Equal(t, tc.expectedStatus, recorder.Code, recorder.Body.String())
+			require.JSONEq(t, tc.expectedBody, recorder.Body.String())
+
+			if tc.expectedSubject == "" {
+				require.Empty(t, permissions.checked)
+				return
+			}
+
+			require.Len(t, permissions.checked, 1)
+			subject := permissions.checked[0].Subject
+			subjectString := subject.Object.ObjectType + ":" + subject.Object.ObjectId
+			if subject.OptionalRelation != "" {
+				subjectString += "#" + subject.OptionalRelation
+			}
+			require.Equal(t, tc.expectedSubject, subjectString)
+		})
+	}
+}
+
+func TestKetoRead(t *testing.T) {
+	handler, permissions := newKetoTestHandler(t)
+
+	recorder := serveKeto(handler, http.MethodGet, KetoRelationTuplesPath+"?namespace=document&object=first", "")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.JSONEq(t, `{
+		"relation_tuples": [
+			{"namespace": "document", "object": "first", "relation": "viewer", "subject_id": "tom"},
+			{"namespace": "document", "object": "first", "relation": "viewer", "subject_set": {"namespace": "group", "object": "eng", "relation": "member"}}
+		],
+		"next_page_token": ""
+	}`, recorder.Body.String())
+	require.Equal(t, "document", permissions.read[0].RelationshipFilter.ResourceType)
+	require.Equal(t, "first", permissions.read[0].RelationshipFilter.OptionalResourceId)
+	require.Nil(t, permissions.read[0].RelationshipFilter.OptionalSubjectFilter)
+
+	// A full page returns the cursor of its last tuple as the next page token.
+	recorder = serveKeto(handler, http.MethodGet, KetoRelationTuplesPath+"?namespace=document&subject_id=tom&page_size=1&page_token=previous", "")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.JSONEq(t, `{
+		"relation_tuples": [
+			{"namespace": "document", "object": "first", "relation": "viewer", "subject_id": "tom"}
+		],
+		"next_page_token": "cursor0"
+	}`, recorder.Body.String())
+	require.Equal(t, uint32(1), permissions.read[1].OptionalLimit)
+	require.Equal(t, "previous", permissions.read[1].OptionalCursor.Token)
+	require.Equal(t, "user", permissions.read[1].RelationshipFilter.OptionalSubjectFilter.SubjectType)
+	require.Equal(t, "tom", permissions.read[1].RelationshipFilter.OptionalSubjectFilter.OptionalSubjectId)
+
+	recorder = serveKeto(handler, http.MethodGet, KetoRelationTuplesPath, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = serveKeto(handler, http.MethodGet, KetoRelationTuplesPath+"?namespace=document&page_size=none", "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = serveKeto(handler, http.MethodPut, KetoRelationTuplesPath+"?namespace=document", "")
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
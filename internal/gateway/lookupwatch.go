@@ -0,0 +1,388 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// LookupWatchPath is the path under which the experimental LookupWatch API is served.
+const LookupWatchPath = "/v1/experimental/lookupwatch"
+
+const (
+	// LookupWatchPermissionGained is the change of events whose subject gained the permission.
+	LookupWatchPermissionGained = "PERMISSION_GAINED"
+
+	// LookupWatchPermissionLost is the change of events whose subject lost the permission.
+	LookupWatchPermissionLost = "PERMISSION_LOST"
+)
+
+// LookupWatchEvent is a change of the permission of a subject on a resource.
+type LookupWatchEvent struct {
+	ResourceObjectID string       `json:"resourceObjectId"`
+	SubjectObjectID  string       `json:"subjectObjectId"`
+	Change           string       `json:"change"`
+	ChangesThrough   *v1.ZedToken `json:"changesThrough"`
+}
+
+// NewLookupWatchHandler returns an http.Handler serving the experimental LookupWatch API: given
+// a resource type, a permission and a subject type, it streams an event whenever a subject gains
+// or loses the permission on a resource, as relationships and the schema change, e.g. to keep
+// the permission filters of a search index up to date.
+//
+// Events are computed incrementally from the Watch API. For each batch of changes, the subjects
+// whose permissions may have changed are found by looking up the subjects of the changed
+// relationships, and only their resources are looked up again, before and after the changes. A
+// change of the schema looks up again the resources of every subject found in a relationship; as
+// the Watch API only streams changes of relationships, it is noticed with the next such change.
+//
+// Only unconditional permissions are considered, and subjects are never wildcards: gaining or
+// losing a permission through a caveat or through public access is not reported.
+//
+// Events are written as newline-delimited JSON, as `{"result": <event>}`, in the same format as
+// the streaming APIs of the gateway. Watching starts at the given `optional_start_cursor`, or
+// at the current revision.
+func NewLookupWatchHandler(conn grpc.ClientConnInterface) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "LookupWatch must be called with GET", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		watcher := &lookupWatcher{
+			permissions:  v1.NewPermissionsServiceClient(conn),
+			schema:       v1.NewSchemaServiceClient(conn),
+			watch:        v1.NewWatchServiceClient(conn),
+			resourceType: query.Get("resource_object_type"),
+			permission:   query.Get("permission"),
+			subjectType:  query.Get("subject_object_type"),
+		}
+		if watcher.resourceType == "" || watcher.permission == "" || watcher.subjectType == "" {
+			http.Error(w, "resource_object_type, permission and subject_object_type are required", http.StatusBadRequest)
+			return
+		}
+
+		ctx := outgoingContext(r)
+		start, err := watcher.loadSchema(ctx)
+		if err != nil {
+			writeGRPCError(w, err)
+			return
+		}
+		if cursor := query.Get("optional_start_cursor"); cursor != "" {
+			start = &v1.ZedToken{Token: cursor}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		encoder := json.NewEncoder(w)
+		err = watcher.run(ctx, start, func(events []LookupWatchEvent) error {
+			for _, event := range events {
+				if err := encoder.Encode(map[string]any{"result": event}); err != nil {
+					return err
+				}
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		})
+		if err != nil && r.Context().Err() == nil {
+			s := status.Convert(err)
+			_ = encoder.Encode(map[string]any{"error": map[string]any{"code": s.Code(), "message": s.Message()}})
+		}
+	})
+}
+
+// lookupWatcher computes the changes of a permission from the changes of relationships.
+type lookupWatcher struct {
+	permissions v1.PermissionsServiceClient
+	schema      v1.SchemaServiceClient
+	watch       v1.WatchServiceClient
+
+	resourceType string
+	permission   string
+	subjectType  string
+
+	// schemaText is the last schema read, and relations the names of the relations and
+	// permissions of each of its definitions.
+	schemaText string
+	relations  map[string][]string
+}
+
+// loadSchema reads the schema, returning the revision at which it was read.
+func (lw *lookupWatcher) loadSchema(ctx context.Context) (*v1.ZedToken, error) {
+	resp, err := lw.schema.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if status.Code(err) == codes.NotFound {
+		// No schema has been written yet.
+		lw.schemaText, lw.relations = "", nil
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if resp.SchemaText == lw.schemaText {
+		return resp.ReadAt, nil
+	}
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: resp.SchemaText,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to compile schema: %s", err)
+	}
+
+	lw.relations = make(map[string][]string, len(compiled.ObjectDefinitions))
+	for _, def := range compiled.ObjectDefinitions {
+		for _, relation := range def.Relation {
+			lw.relations[def.Name] = append(lw.relations[def.Name], relation.Name)
+		}
+	}
+	lw.schemaText = resp.SchemaText
+	return resp.ReadAt, nil
+}
+
+// run watches for changes after the start revision, emitting the events of each batch of
+// changes until the context is canceled or an error occurs.
+func (lw *lookupWatcher) run(ctx context.Context, start *v1.ZedToken, emit func([]LookupWatchEvent) error) error {
+	stream, err := lw.watch.Watch(ctx, &v1.WatchRequest{OptionalStartCursor: start})
+	if err != nil {
+		return err
+	}
+
+	before := start
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		after := resp.ChangesThrough
+		previousSchema := lw.schemaText
+		if _, err := lw.loadSchema(ctx); err != nil {
+			return err
+		}
+
+		var subjects map[string]struct{}
+		if lw.schemaText != previousSchema {
+			subjects, err = lw.allSubjects(ctx, before, after)
+		} else {
+			subjects, err = lw.affectedSubjects(ctx, resp.Updates, before, after)
+		}
+		if err != nil {
+			return err
+		}
+
+		events, err := lw.events(ctx, subjects, before, after)
+		if err != nil {
+			return err
+		}
+		if len(events) > 0 {
+			if err := emit(events); err != nil {
+				return err
+			}
+		}
+		before = after
+	}
+}
+
+// affectedSubjects returns the subjects whose permissions may have been changed by the updates:
+// the subjects of the subject type found through the subject of each updated relationship,
+// before or after the updates.
+func (lw *lookupWatcher) affectedSubjects(ctx context.Context, updates []*v1.RelationshipUpdate, before, after *v1.ZedToken) (map[string]struct{}, error) {
+	subjects := map[string]struct{}{}
+	for _, update := range updates {
+		subject := update.Relationship.Subject
+		if subject.Object.ObjectType == lw.subjectType && subject.OptionalRelation == "" {
+			if subject.Object.ObjectId != tuple.PublicWildcard {
+				subjects[subject.Object.ObjectId] = struct{}{}
+			}
+			continue
+		}
+
+		// A subject without a relation is reached through an arrow, whose permission is not
+		// known from the relationship, so every relation and permission of its type is looked up.
+		relations := []string{subject.OptionalRelation}
+		if subject.OptionalRelation == "" {
+			relations = lw.relations[subject.Object.ObjectType]
+		}
+
+		for _, relation := range relations {
+			for _, at := range []*v1.ZedToken{before, after} {
+				if err := lw.lookupSubjects(ctx, subject.Object, relation, at, subjects); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return subjects, nil
+}
+
+// allSubjects returns every subject of the subject type found in a relationship, before or after
+// the changes.
+func (lw *lookupWatcher) allSubjects(ctx context.Context, before, after *v1.ZedToken) (map[string]struct{}, error) {
+	subjects := map[string]struct{}{}
+	for resourceType := range lw.relations {
+		for _, at := range []*v1.ZedToken{before, after} {
+			stream, err := lw.permissions.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+				Consistency: atExactSnapshot(at),
+				RelationshipFilter: &v1.RelationshipFilter{
+					ResourceType:          resourceType,
+					OptionalSubjectFilter: &v1.SubjectFilter{SubjectType: lw.subjectType},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for {
+				resp, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				} else if err != nil {
+					// Definitions added by the schema change did not exist before it.
+					if status.Code(err) == codes.FailedPrecondition {
+						break
+					}
+					return nil, err
+				}
+				if id := resp.Relationship.Subject.Object.ObjectId; id != tuple.PublicWildcard {
+					subjects[id] = struct{}{}
+				}
+			}
+		}
+	}
+	return subjects, nil
+}
+
+func (lw *lookupWatcher) lookupSubjects(ctx context.Context, object *v1.ObjectReference, permission string, at *v1.ZedToken, subjects map[string]struct{}) error {
+	stream, err := lw.permissions.LookupSubjects(ctx, &v1.LookupSubjectsRequest{
+		Consistency:       atExactSnapshot(at),
+		Resource:          object,
+		Permission:        permission,
+		SubjectObjectType: lw.subjectType,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			// The relation or the subject type may not have existed at the revision.
+			if status.Code(err) == codes.FailedPrecondition {
+				return nil
+			}
+			return err
+		}
+		if id := resp.Subject.SubjectObjectId; id != tuple.PublicWildcard {
+			subjects[id] = struct{}{}
+		}
+	}
+}
+
+// events returns the changes of the permission of the subjects between the two revisions.
+func (lw *lookupWatcher) events(ctx context.Context, subjects map[string]struct{}, before, after *v1.ZedToken) ([]LookupWatchEvent, error) {
+	subjectIDs := make([]string, 0, len(subjects))
+	for subjectID := range subjects {
+		subjectIDs = append(subjectIDs, subjectID)
+	}
+	sort.Strings(subjectIDs)
+
+	var events []LookupWatchEvent
+	for _, subjectID := range subjectIDs {
+		previous, err := lw.lookupResources(ctx, subjectID, before)
+		if err != nil {
+			return nil, err
+		}
+		current, err := lw.lookupResources(ctx, subjectID, after)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, change := range []struct {
+			from, to map[string]struct{}
+			change   string
+		}{
+			{current, previous, LookupWatchPermissionGained},
+			{previous, current, LookupWatchPermissionLost},
+		} {
+			var resourceIDs []string
+			for resourceID := range change.from {
+				if _, ok := change.to[resourceID]; !ok {
+					resourceIDs = append(resourceIDs, resourceID)
+				}
+			}
+			sort.Strings(resourceIDs)
+
+			for _, resourceID := range resourceIDs {
+				events = append(events, LookupWatchEvent{
+					ResourceObjectID: resourceID,
+					SubjectObjectID:  subjectID,
+					Change:           change.change,
+					ChangesThrough:   after,
+				})
+			}
+		}
+	}
+	return events, nil
+}
+
+// lookupResources returns the resources on which the subject has the permission, without
+// caveats, at the revision.
+func (lw *lookupWatcher) lookupResources(ctx context.Context, subjectID string, at *v1.ZedToken) (map[string]struct{}, error) {
+	stream, err := lw.permissions.LookupResources(ctx, &v1.LookupResourcesRequest{
+		Consistency:        atExactSnapshot(at),
+		ResourceObjectType: lw.resourceType,
+		Permission:         lw.permission,
+		Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: lw.subjectType, ObjectId: subjectID}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := map[string]struct{}{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return resources, nil
+		} else if err != nil {
+			// The permission may not have existed at the revision.
+			if status.Code(err) == codes.FailedPrecondition {
+				return resources, nil
+			}
+			return nil, fmt.Errorf("failed to look up resources of %s:%s: %w", lw.subjectType, subjectID, err)
+		}
+		if resp.Permissionship == v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION {
+			resources[resp.ResourceObjectId] = struct{}{}
+		}
+	}
+}
+
+func atExactSnapshot(token *v1.ZedToken) *v1.Consistency {
+	return &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: token}}
+}
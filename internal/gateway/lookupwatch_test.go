@@ -0,0 +1,141 @@
+package gateway_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/gateway"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const lookupWatchSchema = `
+definition user {}
+
+definition group {
+	relation member: user
+}
+
+definition document {
+	relation viewer: user | group#member
+	permission view = viewer
+}
+`
+
+func writeRelationships(t *testing.T, client v1.PermissionsServiceClient, operation v1.RelationshipUpdate_Operation, rels ...string) *v1.ZedToken {
+	var updates []*v1.RelationshipUpdate
+	for _, rel := range rels {
+		updates = append(updates, &v1.RelationshipUpdate{
+			Operation:    operation,
+			Relationship: tuple.MustToRelationship(tuple.MustParse(rel)),
+		})
+	}
+
+	resp, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+	return resp.WrittenAt
+}
+
+func TestLookupWatch(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, false, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{Schema: lookupWatchSchema})
+	require.NoError(t, err)
+
+	client := v1.NewPermissionsServiceClient(conn)
+	start := writeRelationships(t, client, v1.RelationshipUpdate_OPERATION_TOUCH, "document:first#viewer@user:tom")
+
+	server := httptest.NewServer(gateway.NewLookupWatchHandler(conn))
+	t.Cleanup(server.Close)
+
+	query := url.Values{}
+	query.Set("resource_object_type", "document")
+	query.Set("permission", "view")
+	query.Set("subject_object_type", "user")
+	query.Set("optional_start_cursor", start.Token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+gateway.LookupWatchPath+"?"+query.Encode(), nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	scanner := bufio.NewScanner(resp.Body)
+	next := func() gateway.LookupWatchEvent {
+		require.True(t, scanner.Scan(), "expected an event: %v", scanner.Err())
+
+		var line struct {
+			Result gateway.LookupWatchEvent `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		return line.Result
+	}
+
+	// Fred gains access through a group.
+	revision := writeRelationships(t, client, v1.RelationshipUpdate_OPERATION_TOUCH,
+		"group:eng#member@user:fred",
+		"document:second#viewer@group:eng#member",
+	)
+	require.Equal(t, gateway.LookupWatchEvent{
+		ResourceObjectID: "second",
+		SubjectObjectID:  "fred",
+		Change:           gateway.LookupWatchPermissionGained,
+		ChangesThrough:   revision,
+	}, next())
+
+	// Fred leaves the group and loses access.
+	revision = writeRelationships(t, client, v1.RelationshipUpdate_OPERATION_DELETE, "group:eng#member@user:fred")
+	require.Equal(t, gateway.LookupWatchEvent{
+		ResourceObjectID: "second",
+		SubjectObjectID:  "fred",
+		Change:           gateway.LookupWatchPermissionLost,
+		ChangesThrough:   revision,
+	}, next())
+
+	// Tom gains access to the second document only, having access to the first already.
+	revision = writeRelationships(t, client, v1.RelationshipUpdate_OPERATION_TOUCH, "group:eng#member@user:tom")
+	require.Equal(t, gateway.LookupWatchEvent{
+		ResourceObjectID: "second",
+		SubjectObjectID:  "tom",
+		Change:           gateway.LookupWatchPermissionGained,
+		ChangesThrough:   revision,
+	}, next())
+
+	revision = writeRelationships(t, client, v1.RelationshipUpdate_OPERATION_DELETE, "document:first#viewer@user:tom")
+	require.Equal(t, gateway.LookupWatchEvent{
+		ResourceObjectID: "first",
+		SubjectObjectID:  "tom",
+		Change:           gateway.LookupWatchPermissionLost,
+		ChangesThrough:   revision,
+	}, next())
+}
+
+func TestLookupWatchInvalidRequest(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, false, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	handler := gateway.NewLookupWatchHandler(conn)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, gateway.LookupWatchPath+"?resource_object_type=document", nil))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, gateway.LookupWatchPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
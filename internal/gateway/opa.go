@@ -0,0 +1,230 @@
+package gateway
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+)
+
+const (
+	// OPABundlePath is the path under which the relationships are served as an OPA bundle.
+	OPABundlePath = "/opa/bundle.tar.gz"
+
+	// OPADataPath is the path under which the relationships are served as a plain OPA data
+	// document, e.g. for use with `http.send` or the OPA data API.
+	OPADataPath = "/opa/data"
+
+	// OPABundleRoot is the root of the data document within the bundle; policies reference the
+	// relationships as `data.spicedb.relationships`.
+	OPABundleRoot = "spicedb"
+)
+
+// opaDocument is the data document of the relationships found at a revision:
+//
+//	{
+//	  "revision": "<zedtoken>",
+//	  "relationships": {"document": {"readme": {"viewer": ["user:tom", "group:eng#member"]}}},
+//	  "caveated": {"document": {"readme": {"viewer": [{"subject": "user:fred", "caveat": "...", "context": {...}}]}}}
+//	}
+//
+// Caveated relationships are kept apart, since they only apply when their caveat is satisfied.
+type opaDocument struct {
+	Revision      string                                                `json:"revision"`
+	Relationships map[string]map[string]map[string][]string             `json:"relationships"`
+	Caveated      map[string]map[string]map[string][]opaCaveatedSubject `json:"caveated"`
+}
+
+type opaCaveatedSubject struct {
+	Subject string         `json:"subject"`
+	Caveat  string         `json:"caveat"`
+	Context map[string]any `json:"context,omitempty"`
+}
+
+// NewOPAHandler returns an http.Handler serving a snapshot of all relationships as OPA data,
+// either as a bundle under OPABundlePath or as a plain document under OPADataPath.
+//
+// The snapshot is taken at the revision of the `revision` query parameter if provided, or at
+// the latest revision otherwise. The revision of a bundle is also its ETag, so OPA only
+// downloads bundles again once the revision changes.
+//
+// The Authorization header of each request is forwarded to the upstream, so the endpoints are
+// subject to the same authentication as the gRPC API.
+func NewOPAHandler(conn grpc.ClientConnInterface) http.Handler {
+	handler := &opaHandler{
+		schemaClient:       v1.NewSchemaServiceClient(conn),
+		experimentalClient: v1.NewExperimentalServiceClient(conn),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(OPABundlePath, handler.serveBundle)
+	mux.HandleFunc(OPADataPath, handler.serveData)
+	return mux
+}
+
+type opaHandler struct {
+	schemaClient       v1.SchemaServiceClient
+	experimentalClient v1.ExperimentalServiceClient
+}
+
+func (h *opaHandler) serveBundle(w http.ResponseWriter, r *http.Request) {
+	ctx, revision, ok := h.prepare(w, r)
+	if !ok {
+		return
+	}
+
+	etag := `"` + revision + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	document, err := h.export(ctx, revision)
+	if err != nil {
+		writeGRPCError(w, err)
+		return
+	}
+
+	data, err := json.Marshal(map[string]any{OPABundleRoot: document})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	manifest, err := json.Marshal(map[string]any{"revision": revision, "roots": []string{OPABundleRoot}})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("ETag", etag)
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range []struct {
+		name     string
+		contents []byte
+	}{{"/data.json", data}, {"/.manifest", manifest}} {
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Name:     file.name,
+			Mode:     0o600,
+			Size:     int64(len(file.contents)),
+			Typeflag: tar.TypeReg,
+			ModTime:  time.Unix(0, 0),
+		}); err != nil {
+			return
+		}
+		if _, err := tarWriter.Write(file.contents); err != nil {
+			return
+		}
+	}
+	_ = tarWriter.Close()
+	_ = gzipWriter.Close()
+}
+
+func (h *opaHandler) serveData(w http.ResponseWriter, r *http.Request) {
+	ctx, revision, ok := h.prepare(w, r)
+	if !ok {
+		return
+	}
+
+	document, err := h.export(ctx, revision)
+	if err != nil {
+		writeGRPCError(w, err)
+		return
+	}
+
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(encoded)
+}
+
+// prepare returns the outgoing context of the request and the revision at which to export.
+func (h *opaHandler) prepare(w http.ResponseWriter, r *http.Request) (context.Context, string, bool) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "OPA data must be requested with GET", http.StatusMethodNotAllowed)
+		return nil, "", false
+	}
+
+	ctx := outgoingContext(r)
+
+	if revision := r.URL.Query().Get("revision"); revision != "" {
+		return ctx, revision, true
+	}
+
+	// Reading the schema is the cheapest way of finding the latest revision.
+	resp, err := h.schemaClient.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if err != nil {
+		writeGRPCError(w, err)
+		return nil, "", false
+	}
+	return ctx, resp.ReadAt.GetToken(), true
+}
+
+func (h *opaHandler) export(ctx context.Context, revision string) (*opaDocument, error) {
+	stream, err := h.experimentalClient.BulkExportRelationships(ctx, &v1.BulkExportRelationshipsRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: &v1.ZedToken{Token: revision}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	document := &opaDocument{
+		Revision:      revision,
+		Relationships: map[string]map[string]map[string][]string{},
+		Caveated:      map[string]map[string]map[string][]opaCaveatedSubject{},
+	}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return document, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, rel := range resp.Relationships {
+			resourceType, resourceID := rel.Resource.ObjectType, rel.Resource.ObjectId
+			subject := rel.Subject.Object.ObjectType + ":" + rel.Subject.Object.ObjectId
+			if rel.Subject.OptionalRelation != "" {
+				subject += "#" + rel.Subject.OptionalRelation
+			}
+
+			if rel.OptionalCaveat == nil {
+				if document.Relationships[resourceType] == nil {
+					document.Relationships[resourceType] = map[string]map[string][]string{}
+				}
+				if document.Relationships[resourceType][resourceID] == nil {
+					document.Relationships[resourceType][resourceID] = map[string][]string{}
+				}
+				document.Relationships[resourceType][resourceID][rel.Relation] = append(document.Relationships[resourceType][resourceID][rel.Relation], subject)
+				continue
+			}
+
+			if document.Caveated[resourceType] == nil {
+				document.Caveated[resourceType] = map[string]map[string][]opaCaveatedSubject{}
+			}
+			if document.Caveated[resourceType][resourceID] == nil {
+				document.Caveated[resourceType][resourceID] = map[string][]opaCaveatedSubject{}
+			}
+			document.Caveated[resourceType][resourceID][rel.Relation] = append(document.Caveated[resourceType][resourceID][rel.Relation], opaCaveatedSubject{
+				Subject: subject,
+				Caveat:  rel.OptionalCaveat.CaveatName,
+				Context: rel.OptionalCaveat.Context.AsMap(),
+			})
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package gateway
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type fakeSchemaServer struct {
+	v1.UnimplementedSchemaServiceServer
+}
+
+func (fakeSchemaServer) ReadSchema(ctx context.Context, _ *v1.ReadSchemaRequest) (*v1.ReadSchemaResponse, error) {
+	if err := requireAuthorization(ctx); err != nil {
+		return nil, err
+	}
+	return &v1.ReadSchemaResponse{ReadAt: &v1.ZedToken{Token: "headtoken"}}, nil
+}
+
+type fakeExperimentalServer struct {
+	v1.UnimplementedExperimentalServiceServer
+
+	exportedAt []string
+}
+
+func (f *fakeExperimentalServer) BulkExportRelationships(req *v1.BulkExportRelationshipsRequest, stream v1.ExperimentalService_BulkExportRelationshipsServer) error {
+	if err := requireAuthorization(stream.Context()); err != nil {
+		return err
+	}
+	f.exportedAt = append(f.exportedAt, req.Consistency.GetAtExactSnapshot().GetToken())
+
+	caveatContext, err := structpb.NewStruct(map[string]any{"ip": "10.0.0.1"})
+	if err != nil {
+		return err
+	}
+
+	relationship := func(resourceID, relation, subjectType, subjectID, subjectRelation string) *v1.Relationship {
+		return &v1.Relationship{
+			Resource: &v1.ObjectReference{ObjectType: "document", ObjectId: resourceID},
+			Relation: relation,
+			Subject: &v1.SubjectReference{
+				Object:           &v1.ObjectReference{ObjectType: subjectType, ObjectId: subjectID},
+				OptionalRelation: subjectRelation,
+			},
+		}
+	}
+
+	caveated := relationship("first", "viewer", "user", "fred", "")
+	caveated.OptionalCaveat = &v1.ContextualizedCaveat{CaveatName: "on_network", Context: caveatContext}
+
+	if err := stream.Send(&v1.BulkExportRelationshipsResponse{Relationships: []*v1.Relationship{
+		relationship("first", "viewer", "user", "tom", ""),
+		relationship("first", "viewer", "group", "eng", "member"),
+	}}); err != nil {
+		return err
+	}
+	return stream.Send(&v1.BulkExportRelationshipsResponse{Relationships: []*v1.Relationship{
+		relationship("second", "owner", "user", "tom", ""),
+		caveated,
+	}})
+}
+
+const expectedOPADocument = `{
+	"revision": "%s",
+	"relationships": {
+		"document": {
+			"first": {"viewer": ["user:tom", "group:eng#member"]},
+			"second": {"owner": ["user:tom"]}
+		}
+	},
+	"caveated": {
+		"document": {
+			"first": {"viewer": [{"subject": "user:fred", "caveat": "on_network", "context": {"ip": "10.0.0.1"}}]}
+		}
+	}
+}`
+
+func newOPATestHandler(t *testing.T) (http.Handler, *fakeExperimentalServer) {
+	listener := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	experimental := &fakeExperimentalServer{}
+	v1.RegisterSchemaServiceServer(srv, fakeSchemaServer{})
+	v1.RegisterExperimentalServiceServer(srv, experimental)
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return NewOPAHandler(conn), experimental
+}
+
+func TestOPAData(t *testing.T) {
+	handler, experimental := newOPATestHandler(t)
+
+	testCases := []struct {
+		name             string
+		method           string
+		target           string
+		authorization    string
+		expectedStatus   int
+		expectedRevision string
+	}{
+		{"latest revision", http.MethodGet, OPADataPath, "Bearer somekey", http.StatusOK, "headtoken"},
+		{"requested revision", http.MethodGet, OPADataPath + "?revision=oldtoken", "Bearer somekey", http.StatusOK, "oldtoken"},
+		{"unauthenticated", http.MethodGet, OPADataPath, "", http.StatusUnauthorized, ""},
+		{"wrong method", http.MethodPost, OPADataPath, "Bearer somekey", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			experimental.exportedAt = nil
+
+			req := httptest.NewRequest(tc.method, tc.target, nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			require.Equal(t, tc.expectedStatus, recorder.Code, recorder.Body.String())
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			require.Equal(t, []string{tc.expectedRevision}, experimental.exportedAt)
+			require.JSONEq(t, fmt.Sprintf(expectedOPADocument, tc.expectedRevision), recorder.Body.String())
+		})
+	}
+}
+
+func TestOPABundle(t *testing.T) {
+	handler, experimental := newOPATestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, OPABundlePath, nil)
+	req.Header.Set("Authorization", "Bearer somekey")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Equal(t, `"headtoken"`, recorder.Header().Get("ETag"))
+
+	gzipReader, err := gzip.NewReader(recorder.Body)
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		contents, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		files[header.Name] = string(contents)
+	}
+
+	require.JSONEq(t, `{"revision": "headtoken", "roots": ["spicedb"]}`, files["/.manifest"])
+
+	var data map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(files["/data.json"]), &data))
+	require.JSONEq(t, fmt.Sprintf(expectedOPADocument, "headtoken"), string(data[OPABundleRoot]))
+
+	// Bundles are not exported again while the revision is unchanged.
+	experimental.exportedAt = nil
+	req = httptest.NewRequest(http.MethodGet, OPABundlePath, nil)
+	req.Header.Set("Authorization", "Bearer somekey")
+	req.Header.Set("If-None-Match", `"headtoken"`)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+	require.Empty(t, experimental.exportedAt)
+}
@@ -0,0 +1,405 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/proxy"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/development"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// SimulatePath is the path under which the experimental simulation API is served.
+const SimulatePath = "/v1/experimental/simulate"
+
+const (
+	maxSimulateRequestBytes = 4 << 20
+
+	// simulateConcurrencyLimit is the concurrency limit of the dispatches of a simulation.
+	simulateConcurrencyLimit = 10
+)
+
+// SimulateRequest proposes changes to the schema and relationships, along with the checks and
+// lookups to evaluate with and without them.
+type SimulateRequest struct {
+	// Schema is the proposed schema. If empty, the current schema is used.
+	Schema string `json:"schema"`
+
+	// Touch and Delete are the proposed relationship changes, such as
+	// `document:first#viewer@user:tom`.
+	Touch  []string `json:"touch"`
+	Delete []string `json:"delete"`
+
+	Checks          []SimulateCheck  `json:"checks"`
+	LookupResources []SimulateLookup `json:"lookupResources"`
+}
+
+// SimulateCheck is a permission check, such as `document:first#view@user:tom`, with its optional
+// caveat context.
+type SimulateCheck struct {
+	Check   string         `json:"check"`
+	Context map[string]any `json:"context,omitempty"`
+}
+
+// SimulateLookup is a lookup of the resources of a type on which a subject, such as `user:tom`,
+// has a permission.
+type SimulateLookup struct {
+	ResourceObjectType string         `json:"resourceObjectType"`
+	Permission         string         `json:"permission"`
+	Subject            string         `json:"subject"`
+	Context            map[string]any `json:"context,omitempty"`
+}
+
+// SimulateResponse holds the results of the checks and lookups of a simulation.
+type SimulateResponse struct {
+	// ReadAt is the revision of the current data over which the changes were simulated.
+	ReadAt          *v1.ZedToken      `json:"readAt"`
+	Checks          []SimulatedCheck  `json:"checks"`
+	LookupResources []SimulatedLookup `json:"lookupResources"`
+}
+
+// SimulatedCheck holds the permissionship of a check without and with the proposed changes. The
+// current permissionship is empty if the check is invalid without the changes, e.g. because it
+// refers to a permission they add.
+type SimulatedCheck struct {
+	SimulateCheck
+	Current   string `json:"current,omitempty"`
+	Simulated string `json:"simulated"`
+}
+
+// SimulatedLookup holds the IDs of the resources found by a lookup without and with the proposed
+// changes. The current IDs are empty if the lookup is invalid without the changes.
+type SimulatedLookup struct {
+	SimulateLookup
+	Current   []string `json:"current"`
+	Simulated []string `json:"simulated"`
+}
+
+// NewSimulateHandler returns an http.Handler serving the experimental simulation API, to preview
+// the effect of changes to the schema or relationships without writing them: checks and lookups
+// are evaluated over the current data, and over the current data with the changes applied.
+//
+// The current schema and revision are read through the upstream connection, which authenticates
+// the caller. The changes are then simulated over the given datastore, which must be that of the
+// upstream server: the proposed schema and touched relationships are loaded into an in-memory
+// development context, which is overlaid on the current relationships at that revision, so that
+// no relationship is copied. A proposed schema removing relations which still have relationships
+// is reported as an error. Lookups only return the resources on which the permission is held
+// unconditionally.
+func NewSimulateHandler(conn grpc.ClientConnInterface, ds datastore.Datastore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "simulations must be requested with POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req SimulateRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxSimulateRequestBytes)).Decode(&req); err != nil {
+			http.Error(w, "invalid simulation request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp, devErrs, err := simulate(outgoingContext(r), conn, ds, req)
+		if err != nil {
+			writeGRPCError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if devErrs != nil {
+			messages := make([]string, 0, len(devErrs.InputErrors))
+			for _, devErr := range devErrs.InputErrors {
+				messages = append(messages, devErr.Message)
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"errors": messages})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+func simulate(ctx context.Context, conn grpc.ClientConnInterface, ds datastore.Datastore, req SimulateRequest) (*SimulateResponse, *devinterface.DeveloperErrors, error) {
+	touched, err := parseSimulatedRelationships(req.Touch)
+	if err != nil {
+		return nil, nil, err
+	}
+	deleted, err := parseSimulatedRelationships(req.Delete)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schema, readAt, revision, err := currentSchema(ctx, conn, ds)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Schema != "" {
+		schema = req.Schema
+	}
+
+	// The development context only holds the proposed schema and touched relationships, which
+	// it validates.
+	devContext, devErrs, err := development.NewDevContext(ctx, &devinterface.RequestContext{
+		Schema:        schema,
+		Relationships: touched,
+	})
+	if err != nil || devErrs != nil {
+		return nil, devErrs, err
+	}
+	defer devContext.Dispose()
+
+	if req.Schema != "" {
+		invalidated, err := invalidatedRelations(ctx, ds.SnapshotReader(revision), devContext.CompiledSchema.ObjectDefinitions)
+		if err != nil || len(invalidated) > 0 {
+			return nil, &devinterface.DeveloperErrors{InputErrors: invalidated}, err
+		}
+	}
+
+	overlayContext := &development.DevContext{
+		Ctx:            ctx,
+		Datastore:      proxy.NewOverlayDatastore(ds, revision, devContext.Datastore, devContext.Revision, append(deleted, touched...)),
+		Revision:       revision,
+		CompiledSchema: devContext.CompiledSchema,
+		Dispatcher:     graph.NewLocalOnlyDispatcher(simulateConcurrencyLimit),
+	}
+	defer overlayContext.Dispose()
+
+	simulatedConn, stop, err := overlayContext.RunV1InMemoryService()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer stop()
+
+	currentClient := v1.NewPermissionsServiceClient(conn)
+	simulatedClient := v1.NewPermissionsServiceClient(simulatedConn)
+	currentConsistency := atExactSnapshot(readAt)
+	simulatedConsistency := &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+
+	resp := &SimulateResponse{
+		ReadAt:          readAt,
+		Checks:          make([]SimulatedCheck, 0, len(req.Checks)),
+		LookupResources: make([]SimulatedLookup, 0, len(req.LookupResources)),
+	}
+
+	for _, check := range req.Checks {
+		rel := tuple.ParseRel(check.Check)
+		if rel == nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid check `%s`", check.Check)
+		}
+		caveatContext, err := simulatedContext(check.Context)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		checkRequest := &v1.CheckPermissionRequest{
+			Resource:   rel.Resource,
+			Permission: rel.Relation,
+			Subject:    rel.Subject,
+			Context:    caveatContext,
+		}
+
+		result := SimulatedCheck{SimulateCheck: check}
+		if readAt != nil {
+			checkRequest.Consistency = currentConsistency
+			checked, err := currentClient.CheckPermission(ctx, checkRequest)
+			if err != nil && !isInvalidRequest(err) {
+				return nil, nil, err
+			} else if err == nil {
+				result.Current = checked.Permissionship.String()
+			}
+		}
+
+		checkRequest.Consistency = simulatedConsistency
+		checked, err := simulatedClient.CheckPermission(ctx, checkRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Simulated = checked.Permissionship.String()
+		resp.Checks = append(resp.Checks, result)
+	}
+
+	for _, lookup := range req.LookupResources {
+		subject := tuple.ParseSubjectONR(lookup.Subject)
+		if subject == nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid subject `%s`", lookup.Subject)
+		}
+		caveatContext, err := simulatedContext(lookup.Context)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		lookupRequest := &v1.LookupResourcesRequest{
+			ResourceObjectType: lookup.ResourceObjectType,
+			Permission:         lookup.Permission,
+			Subject: &v1.SubjectReference{
+				Object:           &v1.ObjectReference{ObjectType: subject.Namespace, ObjectId: subject.ObjectId},
+				OptionalRelation: stripEllipsis(subject.Relation),
+			},
+			Context: caveatContext,
+		}
+
+		result := SimulatedLookup{SimulateLookup: lookup, Current: []string{}}
+		if readAt != nil {
+			lookupRequest.Consistency = currentConsistency
+			resourceIDs, err := lookupResourceIDs(ctx, currentClient, lookupRequest)
+			if err != nil && !isInvalidRequest(err) {
+				return nil, nil, err
+			} else if err == nil {
+				result.Current = resourceIDs
+			}
+		}
+
+		lookupRequest.Consistency = simulatedConsistency
+		result.Simulated, err = lookupResourceIDs(ctx, simulatedClient, lookupRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp.LookupResources = append(resp.LookupResources, result)
+	}
+
+	return resp, nil, nil
+}
+
+func parseSimulatedRelationships(rels []string) ([]*core.RelationTuple, error) {
+	parsed := make([]*core.RelationTuple, 0, len(rels))
+	for _, rel := range rels {
+		tpl := tuple.Parse(rel)
+		if tpl == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid relationship `%s`", rel)
+		}
+		parsed = append(parsed, tpl)
+	}
+	return parsed, nil
+}
+
+// currentSchema returns the current schema, along with the revision at which it was read. The
+// ZedToken of the revision is nil if no schema has been written.
+func currentSchema(ctx context.Context, conn grpc.ClientConnInterface, ds datastore.Datastore) (string, *v1.ZedToken, datastore.Revision, error) {
+	schema, err := v1.NewSchemaServiceClient(conn).ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if status.Code(err) == codes.NotFound {
+		revision, err := ds.HeadRevision(ctx)
+		return "", nil, revision, err
+	} else if err != nil {
+		return "", nil, nil, err
+	}
+
+	revision, err := zedtoken.DecodeRevision(schema.ReadAt, ds)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return schema.SchemaText, schema.ReadAt, revision, nil
+}
+
+// invalidatedRelations returns an error for each relation removed by the proposed definitions
+// which still has relationships.
+func invalidatedRelations(ctx context.Context, reader datastore.Reader, proposed []*core.NamespaceDefinition) ([]*devinterface.DeveloperError, error) {
+	proposedRelations := make(map[string]struct{})
+	for _, def := range proposed {
+		for _, relation := range def.Relation {
+			proposedRelations[tuple.JoinRelRef(def.Name, relation.Name)] = struct{}{}
+		}
+	}
+
+	current, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var invalidated []*devinterface.DeveloperError
+	for _, def := range current {
+		for _, relation := range def.Definition.Relation {
+			relationRef := tuple.JoinRelRef(def.Definition.Name, relation.Name)
+			if _, ok := proposedRelations[relationRef]; ok {
+				continue
+			}
+
+			iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+				OptionalResourceType:     def.Definition.Name,
+				OptionalResourceRelation: relation.Name,
+			}, options.WithLimit(options.LimitOne))
+			if err != nil {
+				return nil, err
+			}
+			rel, err := iter.Next(), iter.Err()
+			iter.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			if rel != nil {
+				invalidated = append(invalidated, &devinterface.DeveloperError{
+					Message: fmt.Sprintf("relation `%s` is removed by the proposed schema, but still has relationships such as `%s`", relationRef, tuple.MustString(rel)),
+					Kind:    devinterface.DeveloperError_SCHEMA_ISSUE,
+					Source:  devinterface.DeveloperError_SCHEMA,
+					Context: relationRef,
+				})
+			}
+		}
+	}
+	return invalidated, nil
+}
+
+func lookupResourceIDs(ctx context.Context, client v1.PermissionsServiceClient, req *v1.LookupResourcesRequest) ([]string, error) {
+	stream, err := client.LookupResources(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceIDs := []string{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			sort.Strings(resourceIDs)
+			return resourceIDs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if resp.Permissionship == v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION {
+			resourceIDs = append(resourceIDs, resp.ResourceObjectId)
+		}
+	}
+}
+
+func simulatedContext(caveatContext map[string]any) (*structpb.Struct, error) {
+	if caveatContext == nil {
+		return nil, nil
+	}
+
+	converted, err := structpb.NewStruct(caveatContext)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid caveat context: %s", err)
+	}
+	return converted, nil
+}
+
+// isInvalidRequest returns whether the error was caused by a request referring to types or
+// permissions missing from the schema.
+func isInvalidRequest(err error) bool {
+	code := status.Code(err)
+	return code == codes.FailedPrecondition || code == codes.InvalidArgument
+}
+
+func stripEllipsis(relation string) string {
+	if relation == tuple.Ellipsis {
+		return ""
+	}
+	return relation
+}
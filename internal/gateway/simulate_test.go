@@ -0,0 +1,116 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/gateway"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+const simulateSchema = `
+definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}
+`
+
+func TestSimulate(t *testing.T) {
+	conn, cleanup, ds, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, false, testfixtures.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{Schema: simulateSchema})
+	require.NoError(t, err)
+
+	client := v1.NewPermissionsServiceClient(conn)
+	writeRelationships(t, client, v1.RelationshipUpdate_OPERATION_TOUCH,
+		"document:first#viewer@user:tom",
+		"document:second#viewer@user:tom",
+	)
+
+	handler := gateway.NewSimulateHandler(conn, ds)
+	simulate := func(req gateway.SimulateRequest) *httptest.ResponseRecorder {
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, gateway.SimulatePath, strings.NewReader(string(body))))
+		return recorder
+	}
+
+	recorder := simulate(gateway.SimulateRequest{
+		Schema: simulateSchema + `
+definition folder {
+	relation viewer: user
+	permission view = viewer
+}`,
+		Touch:  []string{"document:third#viewer@user:tom", "folder:root#viewer@user:tom"},
+		Delete: []string{"document:first#viewer@user:tom"},
+		Checks: []gateway.SimulateCheck{
+			{Check: "document:first#view@user:tom"},
+			{Check: "document:second#view@user:tom"},
+			{Check: "folder:root#view@user:tom"},
+		},
+		LookupResources: []gateway.SimulateLookup{
+			{ResourceObjectType: "document", Permission: "view", Subject: "user:tom"},
+		},
+	})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var resp gateway.SimulateResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.NotNil(t, resp.ReadAt)
+	require.Equal(t, []gateway.SimulatedCheck{
+		{
+			SimulateCheck: gateway.SimulateCheck{Check: "document:first#view@user:tom"},
+			Current:       "PERMISSIONSHIP_HAS_PERMISSION",
+			Simulated:     "PERMISSIONSHIP_NO_PERMISSION",
+		},
+		{
+			SimulateCheck: gateway.SimulateCheck{Check: "document:second#view@user:tom"},
+			Current:       "PERMISSIONSHIP_HAS_PERMISSION",
+			Simulated:     "PERMISSIONSHIP_HAS_PERMISSION",
+		},
+		{
+			// The folder definition does not exist without the proposed schema.
+			SimulateCheck: gateway.SimulateCheck{Check: "folder:root#view@user:tom"},
+			Simulated:     "PERMISSIONSHIP_HAS_PERMISSION",
+		},
+	}, resp.Checks)
+	require.Equal(t, []gateway.SimulatedLookup{
+		{
+			SimulateLookup: gateway.SimulateLookup{ResourceObjectType: "document", Permission: "view", Subject: "user:tom"},
+			Current:        []string{"first", "second"},
+			Simulated:      []string{"second", "third"},
+		},
+	}, resp.LookupResources)
+
+	// Nothing was written.
+	checked, err := client.CheckPermission(context.Background(), &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+		Permission:  "view",
+		Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checked.Permissionship)
+
+	// A schema invalidating existing relationships is reported.
+	recorder = simulate(gateway.SimulateRequest{Schema: "definition user {}\n\ndefinition document {}"})
+	require.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	require.Contains(t, recorder.Body.String(), "viewer")
+
+	recorder = simulate(gateway.SimulateRequest{Touch: []string{"not a relationship"}})
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -2,12 +2,14 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
 )
 
 func TestAsyncDispatch(t *testing.T) {
@@ -83,3 +85,37 @@ func TestAsyncDispatch(t *testing.T) {
 		})
 	}
 }
+
+func TestSetOperationsDoNotLeakOnError(t *testing.T) {
+	failingErr := errors.New("some error")
+
+	testCases := []struct {
+		name    string
+		reducer func(ctx context.Context, crc currentRequestContext, children []int, handler func(ctx context.Context, crc currentRequestContext, child int) CheckResult, concurrencyLimit uint16) CheckResult
+	}{
+		{"union", union[int]},
+		{"all", all[int]},
+		{"difference", difference[int]},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+			children := []int{0, 1, 2, 3, 4}
+			result := tc.reducer(context.Background(), currentRequestContext{}, children,
+				func(ctx context.Context, crc currentRequestContext, child int) CheckResult {
+					if child == 0 {
+						return checkResultError(failingErr, emptyMetadata)
+					}
+
+					// Block until the reducer cancels the remaining children.
+					<-ctx.Done()
+					return checkResultError(ctx.Err(), emptyMetadata)
+				}, 5)
+
+			require.ErrorIs(t, result.Err, failingErr)
+		})
+	}
+}
@@ -157,6 +157,23 @@ func (e ErrInvalidArgument) Unwrap() error {
 	return e.error
 }
 
+// ErrInvalidRewrite occurs when a userset rewrite found in a namespace definition is malformed
+// and cannot be evaluated.
+type ErrInvalidRewrite struct {
+	error
+}
+
+// NewInvalidRewriteErr constructs a new invalid rewrite error.
+func NewInvalidRewriteErr(baseErr error) error {
+	return ErrInvalidRewrite{
+		error: fmt.Errorf("invalid userset rewrite: %w", baseErr),
+	}
+}
+
+func (e ErrInvalidRewrite) Unwrap() error {
+	return e.error
+}
+
 // ErrUnimplemented is returned when some functionality is not yet supported.
 type ErrUnimplemented struct {
 	error
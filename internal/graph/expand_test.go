@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestExpandComputedUsersetWithoutTuple(t *testing.T) {
+	ce := NewConcurrentExpander(nil)
+	req := ValidatedExpandRequest{
+		DispatchExpandRequest: &v1.DispatchExpandRequest{
+			ResourceAndRelation: tuple.ParseONR("document:first#view"),
+		},
+	}
+
+	result := expandOne(context.Background(), ce.expandComputedUserset(context.Background(), req, &core.ComputedUserset{
+		Object:   core.ComputedUserset_TUPLE_USERSET_OBJECT,
+		Relation: "viewer",
+	}, nil))
+	require.Error(t, result.Err)
+	require.True(t, errors.As(result.Err, &ErrInvalidRewrite{}))
+}
+
+func TestExpandSetOperationDoesNotLeakOnError(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	failingErr := errors.New("some error")
+	start := tuple.ParseONR("document:first#view")
+
+	blockUntilCanceled := func(ctx context.Context, resultChan chan<- ExpandResult) {
+		<-ctx.Done()
+		resultChan <- expandResultError(ctx.Err(), emptyMetadata)
+	}
+
+	requests := []ReduceableExpandFunc{
+		expandError(failingErr),
+		blockUntilCanceled,
+		blockUntilCanceled,
+		blockUntilCanceled,
+	}
+
+	for _, reducer := range []ExpandReducer{expandAny, expandAll, expandDifference} {
+		result := reducer(context.Background(), start, requests)
+		require.ErrorIs(t, result.Err, failingErr)
+	}
+}
@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// failingLookupSubjects fails the dispatches of the relation, and blocks the others until they
+// are canceled.
+type failingLookupSubjects struct {
+	failingRelation string
+	err             error
+}
+
+func (fls failingLookupSubjects) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	if req.ResourceRelation.Relation == fls.failingRelation {
+		return fls.err
+	}
+
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+func TestLookupSubjectsSetOperationDoesNotLeakOnError(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition document {
+			relation first: user
+			relation second: user
+			relation third: user
+			relation fourth: user
+		}
+	`, nil, require.New(t))
+
+	failingErr := errors.New("some error")
+	cl := NewConcurrentLookupSubjects(failingLookupSubjects{"first", failingErr}, 5)
+
+	req := ValidatedLookupSubjectsRequest{
+		DispatchLookupSubjectsRequest: &v1.DispatchLookupSubjectsRequest{
+			ResourceRelation: tuple.RelationReference("document", "view"),
+			ResourceIds:      []string{"somedoc"},
+			SubjectRelation:  tuple.RelationReference("user", tuple.Ellipsis),
+			Metadata:         &v1.ResolverMeta{DepthRemaining: 50},
+		},
+		Revision: revision,
+	}
+
+	so := &core.SetOperation{}
+	for _, relation := range []string{"first", "second", "third", "fourth"} {
+		so.Child = append(so.Child, &core.SetOperation_Child{
+			ChildType: &core.SetOperation_Child_ComputedUserset{
+				ComputedUserset: &core.ComputedUserset{Relation: relation},
+			},
+		})
+	}
+
+	testCases := []struct {
+		name    string
+		reducer func(parentStream dispatch.LookupSubjectsStream) lookupSubjectsReducer
+	}{
+		{"union", func(s dispatch.LookupSubjectsStream) lookupSubjectsReducer { return newLookupSubjectsUnion(s) }},
+		{"intersection", func(s dispatch.LookupSubjectsStream) lookupSubjectsReducer { return newLookupSubjectsIntersection(s) }},
+		{"exclusion", func(s dispatch.LookupSubjectsStream) lookupSubjectsReducer { return newLookupSubjectsExclusion(s) }},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+			ctx := datastoremw.ContextWithDatastore(context.Background(), ds)
+			parentStream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupSubjectsResponse](ctx)
+
+			err := cl.lookupSetOperation(ctx, req, so, tc.reducer(parentStream))
+			require.ErrorIs(t, err, failingErr)
+			require.Empty(t, parentStream.Results())
+		})
+	}
+}
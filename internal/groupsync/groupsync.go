@@ -0,0 +1,343 @@
+// Package groupsync mirrors the group memberships of an identity provider, such as those exposed
+// by a SCIM service, into relationships, so that organizational groups can be used in permissions
+// without maintaining the relationships by hand.
+//
+// Each group becomes an object of the group type, whose synced relation relates the users and
+// the members of the nested groups of the group. The synced relation is dedicated to the
+// synchronization, so that memberships written by hand, to another relation of groups, are never
+// changed by it; schemas combine both in the member permission, such as:
+//
+//	definition group {
+//		relation synced_member: user | group#member
+//		relation direct_member: user | group#member
+//		permission member = synced_member + direct_member
+//	}
+//
+// On every synchronization, the relationships of the synced relation are diffed against the
+// memberships of the identity provider: missing relationships are touched and those of members
+// which were removed are deleted. A synchronization which would delete all the memberships, or
+// more than a configured fraction of them, is refused, as it is more likely caused by a
+// misbehaving identity provider than by an actual reorganization.
+//
+// Groups are listed from SCIM services with NewSCIMSource; other identity providers, such as LDAP
+// directories, can be synchronized by implementing Source.
+package groupsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// DefaultInterval is the default amount of time between synchronizations.
+	DefaultInterval = 5 * time.Minute
+
+	// DefaultGroupType is the default object type of groups.
+	DefaultGroupType = "group"
+
+	// DefaultUserType is the default object type of users.
+	DefaultUserType = "user"
+
+	// DefaultMemberRelation is the default relation or permission of groups relating all their
+	// members.
+	DefaultMemberRelation = "member"
+
+	// DefaultSyncedRelation is the default relation of groups written by the synchronization.
+	DefaultSyncedRelation = "synced_member"
+
+	// DefaultMaxDeleteFraction is the default maximum fraction of the synchronized memberships
+	// deleted by a single synchronization.
+	DefaultMaxDeleteFraction = 0.1
+
+	// writeBatchSize is the maximum number of relationship updates written per transaction.
+	writeBatchSize = 1000
+)
+
+// Group is a group of an identity provider.
+type Group struct {
+	// ID is the identifier of the group, used as the ID of its object.
+	ID string
+
+	// Members are the direct members of the group.
+	Members []Member
+}
+
+// Member is a member of a group: either a user or a nested group.
+type Member struct {
+	// ID is the identifier of the user or group.
+	ID string
+
+	// IsGroup is whether the member is a nested group, whose members are all members of the group.
+	IsGroup bool
+}
+
+// Source lists the groups of an identity provider.
+type Source interface {
+	// Groups returns all the groups along with their direct members.
+	Groups(ctx context.Context) ([]Group, error)
+}
+
+// Config configures the synchronization.
+type Config struct {
+	// Source is the identity provider whose groups are mirrored.
+	Source Source
+
+	// Interval is the amount of time between synchronizations.
+	Interval time.Duration
+
+	// GroupType is the object type of groups.
+	GroupType string
+
+	// UserType is the object type of users.
+	UserType string
+
+	// MemberRelation is the relation or permission of groups relating all their members. The
+	// members of nested groups are related through it.
+	MemberRelation string
+
+	// SyncedRelation is the relation of groups written by the synchronization. Its relationships
+	// to users and to the members of groups are owned by the synchronization, and those not found
+	// in the identity provider are deleted. It must differ from MemberRelation.
+	SyncedRelation string
+
+	// MaxDeleteFraction is the maximum fraction, between 0 and 1, of the synchronized memberships
+	// which a synchronization may delete. Synchronizations deleting more are refused. If zero,
+	// DefaultMaxDeleteFraction is used.
+	MaxDeleteFraction float64
+}
+
+// Stats counts the relationships changed by a synchronization.
+type Stats struct {
+	Touched uint64
+	Deleted uint64
+}
+
+// Syncer synchronizes groups until the context is canceled.
+type Syncer func(ctx context.Context) error
+
+// DisabledSyncer is the syncer used when no identity provider is configured.
+func DisabledSyncer(_ context.Context) error {
+	return nil
+}
+
+// NewSyncer creates a syncer which synchronizes groups into the datastore immediately and then
+// on every interval. Failed synchronizations are logged and retried on the next interval.
+func NewSyncer(ds datastore.Datastore, config Config) (Syncer, error) {
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		log.Ctx(ctx).Info().
+			Stringer("interval", config.Interval).
+			Str("group_type", config.GroupType).
+			Msg("group synchronization started")
+
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+
+		for {
+			stats, err := Sync(ctx, ds, config)
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case err != nil:
+				log.Ctx(ctx).Warn().Err(err).Msg("group synchronization failed")
+			default:
+				log.Ctx(ctx).Info().
+					Uint64("touched", stats.Touched).
+					Uint64("deleted", stats.Deleted).
+					Msg("group synchronization completed")
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}, nil
+}
+
+// Sync synchronizes the groups of the source into the datastore once.
+func Sync(ctx context.Context, ds datastore.Datastore, config Config) (Stats, error) {
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return Stats{}, err
+	}
+
+	groups, err := config.Source.Groups(ctx)
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	desired := make(map[string]*core.RelationTuple)
+	for _, group := range groups {
+		for _, member := range group.Members {
+			rel := config.relationship(group.ID, member)
+			if err := rel.Validate(); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("group", group.ID).Str("member", member.ID).Msg("skipping membership with invalid ID")
+				continue
+			}
+			desired[tuple.StringWithoutCaveat(rel)] = rel
+		}
+	}
+
+	existing, err := config.existingRelationships(ctx, ds)
+	if err != nil {
+		return Stats{}, err
+	}
+	if len(groups) == 0 && len(existing) > 0 {
+		return Stats{}, errNoGroups
+	}
+
+	var updates []*core.RelationTupleUpdate
+	var stats Stats
+	for key, rel := range desired {
+		if current, ok := existing[key]; ok && current.Caveat == nil {
+			continue
+		}
+		updates = append(updates, tuple.Touch(rel))
+		stats.Touched++
+	}
+	for key, rel := range existing {
+		if _, ok := desired[key]; !ok {
+			updates = append(updates, tuple.Delete(rel))
+			stats.Deleted++
+		}
+	}
+	if maxDeleted := config.MaxDeleteFraction * float64(len(existing)); float64(stats.Deleted) > maxDeleted {
+		return Stats{}, fmt.Errorf("refusing to delete %d of the %d synchronized group memberships, more than the maximum fraction of %g", stats.Deleted, len(existing), config.MaxDeleteFraction)
+	}
+
+	for len(updates) > 0 {
+		batch := updates[:min(len(updates), writeBatchSize)]
+		updates = updates[len(batch):]
+		if err := writeUpdates(ctx, ds, batch); err != nil {
+			return Stats{}, err
+		}
+	}
+	return stats, nil
+}
+
+func (c Config) withDefaults() Config {
+	if c.Interval <= 0 {
+		c.Interval = DefaultInterval
+	}
+	if c.GroupType == "" {
+		c.GroupType = DefaultGroupType
+	}
+	if c.UserType == "" {
+		c.UserType = DefaultUserType
+	}
+	if c.MemberRelation == "" {
+		c.MemberRelation = DefaultMemberRelation
+	}
+	if c.SyncedRelation == "" {
+		c.SyncedRelation = DefaultSyncedRelation
+	}
+	if c.MaxDeleteFraction == 0 {
+		c.MaxDeleteFraction = DefaultMaxDeleteFraction
+	}
+	return c
+}
+
+func (c Config) validate() error {
+	switch {
+	case c.Source == nil:
+		return errors.New("a group source must be provided")
+	case c.SyncedRelation == c.MemberRelation:
+		return fmt.Errorf("the synced relation must differ from the member relation %s, so that memberships written by hand are kept", c.MemberRelation)
+	case c.MaxDeleteFraction < 0 || c.MaxDeleteFraction > 1:
+		return fmt.Errorf("the maximum fraction of deleted memberships must be between 0 and 1, got %g", c.MaxDeleteFraction)
+	default:
+		return nil
+	}
+}
+
+// errNoGroups is returned when the source lists no groups while memberships were synchronized, as
+// an identity provider returning nothing is far more likely to be failing than to be empty.
+var errNoGroups = errors.New("refusing to delete all synchronized group memberships: the source listed no groups")
+
+func (c Config) relationship(groupID string, member Member) *core.RelationTuple {
+	subject := &core.ObjectAndRelation{Namespace: c.UserType, ObjectId: member.ID, Relation: tuple.Ellipsis}
+	if member.IsGroup {
+		subject = &core.ObjectAndRelation{Namespace: c.GroupType, ObjectId: member.ID, Relation: c.MemberRelation}
+	}
+
+	return &core.RelationTuple{
+		ResourceAndRelation: &core.ObjectAndRelation{Namespace: c.GroupType, ObjectId: groupID, Relation: c.SyncedRelation},
+		Subject:             subject,
+	}
+}
+
+// isManaged returns whether the relationship of the synced relation is owned by the
+// synchronization: a membership of a user or of the members of a group.
+func (c Config) isManaged(rel *core.RelationTuple) bool {
+	switch rel.Subject.Namespace {
+	case c.UserType:
+		return rel.Subject.Relation == tuple.Ellipsis
+	case c.GroupType:
+		return rel.Subject.Relation == c.MemberRelation
+	default:
+		return false
+	}
+}
+
+// existingRelationships returns the managed relationships of the synced relation of groups, at
+// the head revision.
+func (c Config) existingRelationships(ctx context.Context, ds datastore.Datastore) (map[string]*core.RelationTuple, error) {
+	revision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read head revision: %w", err)
+	}
+
+	iter, err := ds.SnapshotReader(revision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		OptionalResourceType:     c.GroupType,
+		OptionalResourceRelation: c.SyncedRelation,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group memberships: %w", err)
+	}
+	defer iter.Close()
+
+	existing := make(map[string]*core.RelationTuple)
+	for rel := iter.Next(); rel != nil; rel = iter.Next() {
+		if c.isManaged(rel) {
+			existing[tuple.StringWithoutCaveat(rel)] = rel
+		}
+	}
+	if iter.Err() != nil {
+		return nil, fmt.Errorf("failed to read group memberships: %w", iter.Err())
+	}
+	return existing, nil
+}
+
+func writeUpdates(ctx context.Context, ds datastore.Datastore, updates []*core.RelationTupleUpdate) error {
+	var touched []*core.RelationTuple
+	for _, update := range updates {
+		if update.Operation == core.RelationTupleUpdate_TOUCH {
+			touched = append(touched, update.Tuple)
+		}
+	}
+
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if err := relationships.ValidateRelationshipsForCreateOrTouch(ctx, rwt, touched); err != nil {
+			return err
+		}
+		return rwt.WriteRelationships(ctx, updates)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write group memberships: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package groupsync
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `
+definition user {}
+definition serviceaccount {}
+
+definition group {
+	relation synced_member: user | group#member | serviceaccount
+	relation direct_member: user | group#member
+	permission member = synced_member + direct_member
+}
+`
+
+type staticSource []Group
+
+func (s staticSource) Groups(_ context.Context) ([]Group, error) {
+	return s, nil
+}
+
+func readMemberships(t *testing.T, ds datastore.Datastore) []string {
+	ctx := context.Background()
+	revision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	iter, err := ds.SnapshotReader(revision).QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: "group"})
+	require.NoError(t, err)
+	defer iter.Close()
+
+	var memberships []string
+	for rel := iter.Next(); rel != nil; rel = iter.Next() {
+		memberships = append(memberships, tuple.MustString(rel))
+	}
+	require.NoError(t, iter.Err())
+
+	sort.Strings(memberships)
+	return memberships
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, []*core.RelationTuple{
+		tuple.MustParse("group:eng#synced_member@user:removed"),
+		tuple.MustParse("group:eng#synced_member@user:tom"),
+		tuple.MustParse("group:eng#synced_member@serviceaccount:ci"),
+		tuple.MustParse("group:eng#direct_member@user:manual"),
+	}, require.New(t))
+
+	source := staticSource{
+		{ID: "eng", Members: []Member{{ID: "tom"}, {ID: "fred"}, {ID: "infra", IsGroup: true}}},
+		{ID: "infra", Members: []Member{{ID: "sarah"}, {ID: "invalid id"}}},
+		{ID: "invalid id", Members: []Member{{ID: "tom"}}},
+	}
+
+	// Deleting one of the two synchronized memberships is refused by default.
+	_, err = Sync(ctx, ds, Config{Source: source})
+	require.ErrorContains(t, err, "refusing to delete 1 of the 2 synchronized group memberships")
+
+	stats, err := Sync(ctx, ds, Config{Source: source, MaxDeleteFraction: 0.5})
+	require.NoError(t, err)
+	require.Equal(t, Stats{Touched: 3, Deleted: 1}, stats)
+	require.Equal(t, []string{
+		"group:eng#direct_member@user:manual",
+		"group:eng#synced_member@group:infra#member",
+		"group:eng#synced_member@serviceaccount:ci",
+		"group:eng#synced_member@user:fred",
+		"group:eng#synced_member@user:tom",
+		"group:infra#synced_member@user:sarah",
+	}, readMemberships(t, ds))
+
+	// Synchronizing again changes nothing.
+	stats, err = Sync(ctx, ds, Config{Source: source})
+	require.NoError(t, err)
+	require.Equal(t, Stats{}, stats)
+
+	// A source listing no groups never deletes every membership.
+	_, err = Sync(ctx, ds, Config{Source: staticSource{}, MaxDeleteFraction: 1})
+	require.ErrorIs(t, err, errNoGroups)
+
+	stats, err = Sync(ctx, ds, Config{Source: staticSource{{ID: "eng", Members: []Member{{ID: "tom"}}}}, MaxDeleteFraction: 1})
+	require.NoError(t, err)
+	require.Equal(t, Stats{Deleted: 3}, stats)
+	require.Equal(t, []string{
+		"group:eng#direct_member@user:manual",
+		"group:eng#synced_member@serviceaccount:ci",
+		"group:eng#synced_member@user:tom",
+	}, readMemberships(t, ds))
+}
+
+func TestSyncInvalidConfig(t *testing.T) {
+	source := staticSource{{ID: "eng", Members: []Member{{ID: "tom"}}}}
+
+	_, err := Sync(context.Background(), nil, Config{Source: source, SyncedRelation: DefaultMemberRelation})
+	require.ErrorContains(t, err, "must differ from the member relation")
+
+	_, err = Sync(context.Background(), nil, Config{Source: source, MaxDeleteFraction: 2})
+	require.ErrorContains(t, err, "must be between 0 and 1")
+}
+
+func TestSyncInvalidSchema(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+definition user {}
+
+definition team {
+	relation synced_member: user
+}
+`, nil, require.New(t))
+
+	_, err = Sync(context.Background(), ds, Config{
+		Source: staticSource{{ID: "eng", Members: []Member{{ID: "tom"}}}},
+	})
+	require.ErrorContains(t, err, "failed to write group memberships")
+
+	stats, err := Sync(context.Background(), ds, Config{
+		Source:    staticSource{{ID: "eng", Members: []Member{{ID: "tom"}}}},
+		GroupType: "team",
+	})
+	require.NoError(t, err)
+	require.Equal(t, Stats{Touched: 1}, stats)
+}
+
+func TestSyncer(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, nil, require.New(t))
+
+	_, err = NewSyncer(ds, Config{})
+	require.Error(t, err)
+
+	syncer, err := NewSyncer(ds, Config{
+		Source:   staticSource{{ID: "eng", Members: []Member{{ID: "tom"}}}},
+		Interval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- syncer(ctx) }()
+
+	require.Eventually(t, func() bool {
+		return len(readMemberships(t, ds)) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestSCIMSource(t *testing.T) {
+	groups := []scimGroup{
+		{ID: "eng", Members: []scimMember{{Value: "tom", Type: "User"}, {Value: "infra", Type: "Group"}}},
+		{ID: "infra", Members: []scimMember{{Value: "sarah"}}},
+		{ID: "empty"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/scim/v2/Groups" || r.Header.Get("Authorization") != "Bearer sometoken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// Serve pages of two groups, regardless of the requested count.
+		startIndex, err := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		require.NoError(t, err)
+		start := min(startIndex-1, len(groups))
+		end := min(start+2, len(groups))
+
+		w.Header().Set("Content-Type", "application/scim+json")
+		require.NoError(t, json.NewEncoder(w).Encode(scimListResponse{
+			TotalResults: len(groups),
+			Resources:    groups[start:end],
+		}))
+	}))
+	defer server.Close()
+
+	listed, err := NewSCIMSource(server.URL+"/scim/v2/", "sometoken").Groups(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []Group{
+		{ID: "eng", Members: []Member{{ID: "tom"}, {ID: "infra", IsGroup: true}}},
+		{ID: "infra", Members: []Member{{ID: "sarah"}}},
+		{ID: "empty"},
+	}, listed)
+
+	_, err = NewSCIMSource(server.URL+"/scim/v2", "wrongtoken").Groups(context.Background())
+	require.ErrorContains(t, err, "401 Unauthorized")
+}
+
+func TestSCIMSourceTruncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Claim three groups, but only ever return the first.
+		resources := []scimGroup{{ID: "eng"}}
+		if r.URL.Query().Get("startIndex") != "1" {
+			resources = nil
+		}
+
+		w.Header().Set("Content-Type", "application/scim+json")
+		require.NoError(t, json.NewEncoder(w).Encode(scimListResponse{TotalResults: 3, Resources: resources}))
+	}))
+	defer server.Close()
+
+	_, err := NewSCIMSource(server.URL, "").Groups(context.Background())
+	require.ErrorContains(t, err, "SCIM list response truncated: no groups from index 2 of 3")
+}
@@ -0,0 +1,116 @@
+package groupsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scimPageSize is the number of groups requested per page.
+const scimPageSize = 100
+
+// scimListResponse is a page of a SCIM 2.0 list response of groups.
+type scimListResponse struct {
+	TotalResults int         `json:"totalResults"`
+	Resources    []scimGroup `json:"Resources"`
+}
+
+type scimGroup struct {
+	ID      string       `json:"id"`
+	Members []scimMember `json:"members"`
+}
+
+type scimMember struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// NewSCIMSource returns a source listing the groups of a SCIM 2.0 service, such as those of
+// most identity providers, at the given base URL. The token, if any, is sent as a bearer token.
+//
+// Members are users unless their type is `Group`.
+func NewSCIMSource(baseURL, token string) Source {
+	return &scimSource{
+		groupsURL: strings.TrimSuffix(baseURL, "/") + "/Groups",
+		token:     token,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type scimSource struct {
+	groupsURL string
+	token     string
+	client    *http.Client
+}
+
+func (s *scimSource) Groups(ctx context.Context) ([]Group, error) {
+	var groups []Group
+
+	// SCIM indexes are 1-based.
+	for startIndex := 1; ; {
+		page, err := s.page(ctx, startIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range page.Resources {
+			group := Group{ID: resource.ID}
+			for _, member := range resource.Members {
+				group.Members = append(group.Members, Member{
+					ID:      member.Value,
+					IsGroup: strings.EqualFold(member.Type, "Group"),
+				})
+			}
+			groups = append(groups, group)
+		}
+
+		// A listing cut short would delete the memberships of the groups which were not listed.
+		if len(page.Resources) == 0 && startIndex <= page.TotalResults {
+			return nil, fmt.Errorf("SCIM list response truncated: no groups from index %d of %d", startIndex, page.TotalResults)
+		}
+
+		startIndex += len(page.Resources)
+		if startIndex > page.TotalResults {
+			return groups, nil
+		}
+	}
+}
+
+func (s *scimSource) page(ctx context.Context, startIndex int) (*scimListResponse, error) {
+	query := url.Values{}
+	query.Set("startIndex", strconv.Itoa(startIndex))
+	query.Set("count", strconv.Itoa(scimPageSize))
+	query.Set("attributes", "members")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.groupsURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/scim+json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SCIM groups: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to list SCIM groups: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var page scimListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("invalid SCIM list response: %w", err)
+	}
+	return &page, nil
+}
@@ -0,0 +1,214 @@
+// Package leaderelection elects the single replica of a cluster that runs a background task, such
+// as the delivery of webhooks, which would otherwise be run once by every replica.
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+const (
+	// DefaultLeasePrefix is the default prefix of the names of the Leases.
+	DefaultLeasePrefix = "spicedb"
+
+	// DefaultLeaseDuration is the default amount of time a Lease that is not renewed is held
+	// before another replica may acquire it.
+	DefaultLeaseDuration = 15 * time.Second
+
+	serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// Elector runs the named task on a single replica of the cluster until the context is canceled.
+type Elector func(ctx context.Context, name string, task func(ctx context.Context) error) error
+
+// Unelected is the elector of deployments with a single replica: it runs every task directly.
+func Unelected(ctx context.Context, _ string, task func(ctx context.Context) error) error {
+	return task(ctx)
+}
+
+// Config configures the election of replicas using Kubernetes Leases.
+type Config struct {
+	// Namespace is the namespace of the Leases. If empty, it is the namespace of the pod.
+	Namespace string
+
+	// LeasePrefix is prepended to the name of each task to form the name of its Lease.
+	LeasePrefix string
+
+	// Identity identifies the replica as the holder of a Lease. If empty, it is the hostname,
+	// which is the name of the pod.
+	Identity string
+
+	// LeaseDuration is the amount of time a Lease that is not renewed is held before another
+	// replica may acquire it.
+	LeaseDuration time.Duration
+}
+
+// NewInClusterElector returns an elector using the Leases of the Kubernetes cluster in which
+// the replica runs.
+func NewInClusterElector(config Config) (Elector, error) {
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Kubernetes client: %w", err)
+	}
+
+	client, err := coordinationv1.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	if config.Namespace == "" {
+		namespace, err := os.ReadFile(serviceAccountNamespacePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine the namespace of the pod: %w", err)
+		}
+		config.Namespace = strings.TrimSpace(string(namespace))
+	}
+
+	return NewKubernetesElector(client, config)
+}
+
+// NewKubernetesElector returns an elector running each task on the replica holding the Lease
+// of the task. Every task has its own Lease, so different tasks may run on different replicas.
+//
+// A replica releases a Lease only once its task returned, so a task may use its remaining
+// time, such as to flush pending work, before another replica starts it.
+func NewKubernetesElector(leases coordinationv1.LeasesGetter, config Config) (Elector, error) {
+	if config.Namespace == "" {
+		return nil, errors.New("a namespace must be provided")
+	}
+
+	if config.LeasePrefix == "" {
+		config.LeasePrefix = DefaultLeasePrefix
+	}
+
+	if config.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine the identity of the replica: %w", err)
+		}
+		config.Identity = hostname
+	}
+
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = DefaultLeaseDuration
+	}
+
+	ke := &kubernetesElector{leases: leases, config: config}
+	return ke.run, nil
+}
+
+type kubernetesElector struct {
+	leases coordinationv1.LeasesGetter
+	config Config
+}
+
+func (ke *kubernetesElector) run(ctx context.Context, name string, task func(ctx context.Context) error) error {
+	leaseName := ke.config.LeasePrefix + "-" + name
+	logger := log.Ctx(ctx).With().Str("lease", leaseName).Str("identity", ke.config.Identity).Logger()
+
+	for {
+		// The election is only canceled once the task returned, so that the Lease is not
+		// released while the task is still running.
+		electionCtx, cancelElection := context.WithCancel(context.WithoutCancel(ctx))
+
+		var (
+			lock    sync.Mutex
+			leading bool
+			done    = make(chan error, 1)
+		)
+		stopWaiting := context.AfterFunc(ctx, func() {
+			lock.Lock()
+			defer lock.Unlock()
+			if !leading {
+				cancelElection()
+			}
+		})
+
+		elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+			Lock: &resourcelock.LeaseLock{
+				LeaseMeta:  metav1.ObjectMeta{Name: leaseName, Namespace: ke.config.Namespace},
+				Client:     ke.leases,
+				LockConfig: resourcelock.ResourceLockConfig{Identity: ke.config.Identity},
+			},
+			LeaseDuration:   ke.config.LeaseDuration,
+			RenewDeadline:   ke.config.LeaseDuration * 2 / 3,
+			RetryPeriod:     ke.config.LeaseDuration / 5,
+			ReleaseOnCancel: true,
+			Name:            leaseName,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(leaderCtx context.Context) {
+					defer cancelElection()
+
+					lock.Lock()
+					if ctx.Err() != nil {
+						lock.Unlock()
+						return
+					}
+					leading = true
+					lock.Unlock()
+
+					logger.Info().Msg("elected to run task")
+
+					taskCtx, cancelTask := context.WithCancel(leaderCtx)
+					stopTask := context.AfterFunc(ctx, cancelTask)
+					defer stopTask()
+					defer cancelTask()
+
+					err := task(taskCtx)
+					if err == nil && leaderCtx.Err() != nil && ctx.Err() == nil {
+						err = errLeaseLost
+					}
+					done <- err
+				},
+				OnStoppedLeading: func() {},
+			},
+		})
+		if err != nil {
+			stopWaiting()
+			cancelElection()
+			return fmt.Errorf("failed to configure election for %s: %w", leaseName, err)
+		}
+
+		elector.Run(electionCtx)
+		stopWaiting()
+
+		lock.Lock()
+		wasLeading := leading
+		lock.Unlock()
+
+		if !wasLeading {
+			cancelElection()
+			return nil
+		}
+
+		// Run returns as soon as the Lease is lost, while the task may still be stopping.
+		err = <-done
+		cancelElection()
+
+		switch {
+		case errors.Is(err, errLeaseLost):
+			logger.Warn().Msg("lost lease; task stopped until it is acquired again")
+
+		case err != nil:
+			return err
+
+		default:
+			return nil
+		}
+	}
+}
+
+var errLeaseLost = errors.New("lease lost")
@@ -0,0 +1,131 @@
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestElector(t *testing.T, client *fake.Clientset, identity string) Elector {
+	elector, err := NewKubernetesElector(client.CoordinationV1(), Config{
+		Namespace:     "spicedb",
+		Identity:      identity,
+		LeaseDuration: 1 * time.Second,
+	})
+	require.NoError(t, err)
+	return elector
+}
+
+func TestKubernetesElectorRunsTaskOnOneReplica(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	var running, maxRunning atomic.Int32
+	started := make(chan string, 2)
+	task := func(identity string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			current := running.Add(1)
+			defer running.Add(-1)
+			for {
+				previous := maxRunning.Load()
+				if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+
+			started <- identity
+			<-ctx.Done()
+
+			// Keep running a bit after being canceled, as a task flushing its work would.
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}
+	}
+
+	contexts := map[string]context.CancelFunc{}
+	results := make(chan error, 2)
+	for _, identity := range []string{"first", "second"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		contexts[identity] = cancel
+		t.Cleanup(cancel)
+
+		elector := newTestElector(t, client, identity)
+		go func(identity string) {
+			results <- elector(ctx, "sometask", task(identity))
+		}(identity)
+	}
+
+	var leader string
+	select {
+	case leader = <-started:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no replica was elected")
+	}
+
+	// Stopping the leader releases the Lease once its task returned.
+	contexts[leader]()
+	select {
+	case err := <-results:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "the leader did not stop")
+	}
+
+	select {
+	case next := <-started:
+		require.NotEqual(t, leader, next)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no other replica was elected")
+	}
+
+	for _, cancel := range contexts {
+		cancel()
+	}
+	select {
+	case err := <-results:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "the second leader did not stop")
+	}
+
+	require.Equal(t, int32(1), maxRunning.Load())
+}
+
+func TestKubernetesElectorReturnsTaskError(t *testing.T) {
+	elector := newTestElector(t, fake.NewSimpleClientset(), "first")
+
+	taskErr := errors.New("some error")
+	err := elector(context.Background(), "sometask", func(ctx context.Context) error {
+		return taskErr
+	})
+	require.ErrorIs(t, err, taskErr)
+}
+
+func TestKubernetesElectorStopsWhileWaiting(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	defer cancelLeader()
+
+	started := make(chan struct{})
+	go func() {
+		_ = newTestElector(t, client, "first")(leaderCtx, "sometask", func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	err := newTestElector(t, client, "second")(ctx, "sometask", func(ctx context.Context) error {
+		return errors.New("should not run")
+	})
+	require.NoError(t, err)
+}
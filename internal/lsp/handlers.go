@@ -163,8 +163,8 @@ func (s *Server) getCompiledContents(path lsp.DocumentURI, files *persistent.Map
 		return compiled, nil
 	}
 
-	justCompiled, derr, err := development.CompileSchema(file.contents)
-	if err != nil || derr != nil {
+	justCompiled, derrs, err := development.CompileSchema(file.contents)
+	if err != nil || len(derrs) > 0 {
 		return nil, err
 	}
 
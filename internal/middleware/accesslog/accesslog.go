@@ -0,0 +1,97 @@
+// Package accesslog implements middleware that logs a structured summary of
+// each API request once it has completed.
+package accesslog
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/auth"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/consistency"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that logs each request with its
+// method, caller, latency, resolved revision, dispatch count and status.
+//
+// Successful requests are logged with probability sampleRate, while failed requests are always
+// logged. A sampleRate of zero or less disables the access log.
+func UnaryServerInterceptor(sampleRate float64) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if sampleRate <= 0 {
+			return handler(ctx, req)
+		}
+
+		newCtx := contextWithHandles(ctx)
+		start := time.Now()
+		resp, err := handler(newCtx, req)
+		logRequest(newCtx, info.FullMethod, start, err, sampleRate)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that logs each request with its
+// method, caller, latency, resolved revision, dispatch count and status.
+//
+// Successful requests are logged with probability sampleRate, while failed requests are always
+// logged. A sampleRate of zero or less disables the access log.
+func StreamServerInterceptor(sampleRate float64) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if sampleRate <= 0 {
+			return handler(srv, stream)
+		}
+
+		wrapped := middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = contextWithHandles(wrapped.WrappedContext)
+		start := time.Now()
+		err := handler(srv, wrapped)
+		logRequest(wrapped.WrappedContext, info.FullMethod, start, err, sampleRate)
+		return err
+	}
+}
+
+// contextWithHandles adds the placeholders filled by the authentication and consistency
+// middleware and by the services, as the access log runs before all of them.
+func contextWithHandles(ctx context.Context) context.Context {
+	ctx = auth.ContextWithCallerHandle(ctx)
+	ctx = consistency.ContextWithHandle(ctx)
+	return usagemetrics.ContextWithHandle(ctx)
+}
+
+func logRequest(ctx context.Context, method string, start time.Time, err error, sampleRate float64) {
+	code := status.Code(err)
+	if err == nil && sampleRate < 1 && rand.Float64() >= sampleRate { // nolint:gosec
+		return
+	}
+
+	event := log.Ctx(ctx).Info().
+		Str("grpc.method", method).
+		Str("grpc.code", code.String()).
+		Dur("duration", time.Since(start))
+
+	if caller, ok := auth.CallerFromContext(ctx); ok {
+		event = event.Str("caller", caller.Identity)
+	}
+
+	if revision, _, err := consistency.RevisionFromContext(ctx); err == nil {
+		event = event.Str("revision", revision.String())
+	}
+
+	if responseMeta := usagemetrics.FromContext(ctx); responseMeta != nil {
+		event = event.
+			Uint32("dispatch_count", responseMeta.DispatchCount).
+			Uint32("cached_dispatch_count", responseMeta.CachedDispatchCount)
+	}
+
+	if err != nil {
+		event = event.Err(err)
+	}
+
+	event.Msg("request completed")
+}
@@ -0,0 +1,104 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/middleware/consistency"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+const testMethod = "/authzed.api.v1.PermissionsService/CheckPermission"
+
+func runUnary(t *testing.T, sampleRate float64, handlerErr error) []map[string]any {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	ctx := logger.WithContext(context.Background())
+
+	interceptor := UnaryServerInterceptor(sampleRate)
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, func(ctx context.Context, _ any) (any, error) {
+		// The access log runs before authentication, so the caller is attached to a context
+		// derived from the one it passed down.
+		_ = auth.ContextWithCaller(ctx, &auth.Caller{Identity: "some-service"})
+
+		usagemetrics.SetInContext(ctx, &dispatch.ResponseMeta{
+			DispatchCount:       3,
+			CachedDispatchCount: 1,
+		})
+		return nil, handlerErr
+	})
+	require.ErrorIs(t, err, handlerErr)
+
+	var entries []map[string]any
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var entry map[string]any
+		require.NoError(t, decoder.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	entries := runUnary(t, 1, nil)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	require.Equal(t, testMethod, entry["grpc.method"])
+	require.Equal(t, codes.OK.String(), entry["grpc.code"])
+	require.Equal(t, "some-service", entry["caller"])
+	require.Equal(t, float64(3), entry["dispatch_count"])
+	require.Equal(t, float64(1), entry["cached_dispatch_count"])
+	require.Contains(t, entry, "duration")
+	require.NotContains(t, entry, "revision")
+}
+
+func TestUnaryServerInterceptorSampling(t *testing.T) {
+	require.Empty(t, runUnary(t, 0, nil))
+	require.Empty(t, runUnary(t, 1e-12, nil))
+
+	// Failed requests are logged regardless of the sample rate.
+	entries := runUnary(t, 1e-12, status.Error(codes.NotFound, "missing"))
+	require.Len(t, entries, 1)
+	require.Equal(t, codes.NotFound.String(), entries[0]["grpc.code"])
+
+	entries = runUnary(t, 1e-12, errors.New("unexpected"))
+	require.Len(t, entries, 1)
+	require.Equal(t, codes.Unknown.String(), entries[0]["grpc.code"])
+
+	// Disabled means disabled, even for failures.
+	require.Empty(t, runUnary(t, 0, errors.New("unexpected")))
+}
+
+func TestUnaryServerInterceptorRevision(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	ctx := zerolog.New(&buf).WithContext(context.Background())
+
+	interceptor := UnaryServerInterceptor(1)
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, func(ctx context.Context, _ any) (any, error) {
+		return nil, consistency.AddRevisionToContext(ctx, &v1.CheckPermissionRequest{
+			Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		}, ds)
+	})
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.NewDecoder(&buf).Decode(&entry))
+	require.Contains(t, entry, "revision")
+}
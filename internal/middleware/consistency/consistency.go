@@ -40,6 +40,7 @@ var errInvalidZedToken = errors.New("invalid revision requested")
 
 type revisionHandle struct {
 	revision datastore.Revision
+	ds       datastore.Datastore
 }
 
 // ContextWithHandle adds a placeholder to a context that will later be
@@ -48,6 +49,15 @@ func ContextWithHandle(ctx context.Context) context.Context {
 	return context.WithValue(ctx, revisionKey, &revisionHandle{})
 }
 
+// contextWithHandleIfMissing adds a placeholder to the context unless one was already added by
+// middleware running earlier, such as the access log, so that it can read the revision.
+func contextWithHandleIfMissing(ctx context.Context) context.Context {
+	if ctx.Value(revisionKey) != nil {
+		return ctx
+	}
+	return ContextWithHandle(ctx)
+}
+
 // RevisionFromContext reads the selected revision out of a context.Context, computes a zedtoken
 // from it, and returns an error if it has not been set on the context.
 func RevisionFromContext(ctx context.Context) (datastore.Revision, *v1.ZedToken, error) {
@@ -55,7 +65,11 @@ func RevisionFromContext(ctx context.Context) (datastore.Revision, *v1.ZedToken,
 		handle := c.(*revisionHandle)
 		rev := handle.revision
 		if rev != nil {
-			return rev, zedtoken.MustNewFromRevision(rev), nil
+			zt, err := zedtoken.NewFromRevisionForDatastore(ctx, rev, handle.ds)
+			if err != nil {
+				return nil, nil, err
+			}
+			return rev, zt, nil
 		}
 	}
 
@@ -147,9 +161,9 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 		// Exact snapshot: Use the revision as encoded in the zed token.
 		ConsistentyCounter.WithLabelValues("snapshot", "request").Inc()
 
-		requestedRev, err := zedtoken.DecodeRevision(consistency.GetAtExactSnapshot(), ds)
+		requestedRev, err := zedtoken.DecodeRevisionForDatastore(ctx, consistency.GetAtExactSnapshot(), ds)
 		if err != nil {
-			return errInvalidZedToken
+			return rewriteZedTokenError(err)
 		}
 
 		err = ds.CheckRevision(ctx, requestedRev)
@@ -164,6 +178,7 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 	}
 
 	handle.(*revisionHandle).revision = revision
+	handle.(*revisionHandle).ds = ds
 	return nil
 }
 
@@ -183,7 +198,7 @@ func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 			}
 		}
 		ds := datastoremw.MustFromContext(ctx)
-		newCtx := ContextWithHandle(ctx)
+		newCtx := contextWithHandleIfMissing(ctx)
 		if err := AddRevisionToContext(newCtx, req, ds); err != nil {
 			return nil, err
 		}
@@ -201,7 +216,7 @@ func StreamServerInterceptor() grpc.StreamServerInterceptor {
 				return handler(srv, stream)
 			}
 		}
-		wrapper := &recvWrapper{stream, ContextWithHandle(stream.Context())}
+		wrapper := &recvWrapper{stream, contextWithHandleIfMissing(stream.Context())}
 		return handler(srv, wrapper)
 	}
 }
@@ -232,9 +247,9 @@ func pickBestRevision(ctx context.Context, requested *v1.ZedToken, ds datastore.
 	}
 
 	if requested != nil {
-		requestedRev, err := zedtoken.DecodeRevision(requested, ds)
+		requestedRev, err := zedtoken.DecodeRevisionForDatastore(ctx, requested, ds)
 		if err != nil {
-			return datastore.NoRevision, false, errInvalidZedToken
+			return datastore.NoRevision, false, rewriteZedTokenError(err)
 		}
 
 		if databaseRev.GreaterThan(requestedRev) {
@@ -247,6 +262,13 @@ func pickBestRevision(ctx context.Context, requested *v1.ZedToken, ds datastore.
 	return databaseRev, false, nil
 }
 
+func rewriteZedTokenError(err error) error {
+	if errors.Is(err, zedtoken.ErrMismatchedDatastore) {
+		return status.Errorf(codes.InvalidArgument, "invalid zedtoken: %s", err)
+	}
+	return errInvalidZedToken
+}
+
 func rewriteDatastoreError(ctx context.Context, err error) error {
 	// Check if the error can be directly used.
 	if _, ok := status.FromError(err); ok {
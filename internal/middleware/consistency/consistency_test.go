@@ -7,6 +7,8 @@ import (
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
 	"github.com/authzed/spicedb/internal/datastore/revisions"
@@ -22,10 +24,16 @@ var (
 	head      = revisions.NewForTransactionID(145)
 )
 
+const (
+	uniqueID      = "3f6f5b1e-4c1a-4a7e-9a55-0d3a4d4f2b3c"
+	otherUniqueID = "a81d2c0e-7b7e-4f43-8c5c-6e0b9a1f7d22"
+)
+
 func TestAddRevisionToContextNoneSupplied(t *testing.T) {
 	require := require.New(t)
 
 	ds := &proxy_test.MockDatastore{}
+	ds.On("UniqueID").Return(uniqueID, nil)
 	ds.On("OptimizedRevision").Return(optimized, nil).Once()
 
 	updated := ContextWithHandle(context.Background())
@@ -43,6 +51,7 @@ func TestAddRevisionToContextMinimizeLatency(t *testing.T) {
 	require := require.New(t)
 
 	ds := &proxy_test.MockDatastore{}
+	ds.On("UniqueID").Return(uniqueID, nil)
 	ds.On("OptimizedRevision").Return(optimized, nil).Once()
 
 	updated := ContextWithHandle(context.Background())
@@ -66,6 +75,7 @@ func TestAddRevisionToContextFullyConsistent(t *testing.T) {
 	require := require.New(t)
 
 	ds := &proxy_test.MockDatastore{}
+	ds.On("UniqueID").Return(uniqueID, nil)
 	ds.On("HeadRevision").Return(head, nil).Once()
 
 	updated := ContextWithHandle(context.Background())
@@ -89,6 +99,7 @@ func TestAddRevisionToContextAtLeastAsFresh(t *testing.T) {
 	require := require.New(t)
 
 	ds := &proxy_test.MockDatastore{}
+	ds.On("UniqueID").Return(uniqueID, nil)
 	ds.On("OptimizedRevision").Return(optimized, nil).Once()
 	ds.On("RevisionFromString", exact.String()).Return(exact, nil).Once()
 
@@ -113,6 +124,7 @@ func TestAddRevisionToContextAtValidExactSnapshot(t *testing.T) {
 	require := require.New(t)
 
 	ds := &proxy_test.MockDatastore{}
+	ds.On("UniqueID").Return(uniqueID, nil)
 	ds.On("CheckRevision", exact).Return(nil).Times(1)
 	ds.On("RevisionFromString", exact.String()).Return(exact, nil).Once()
 
@@ -152,6 +164,52 @@ func TestAddRevisionToContextAtInvalidExactSnapshot(t *testing.T) {
 	ds.AssertExpectations(t)
 }
 
+func TestAddRevisionToContextMismatchedDatastore(t *testing.T) {
+	require := require.New(t)
+
+	issuer := &proxy_test.MockDatastore{}
+	issuer.On("UniqueID").Return(otherUniqueID, nil)
+
+	token, err := zedtoken.NewFromRevisionForDatastore(context.Background(), exact, issuer)
+	require.NoError(err)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("UniqueID").Return(uniqueID, nil)
+
+	updated := ContextWithHandle(context.Background())
+	err = AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtExactSnapshot{
+				AtExactSnapshot: token,
+			},
+		},
+	}, ds)
+	require.Error(err)
+	require.Equal(codes.InvalidArgument, status.Code(err))
+	ds.AssertExpectations(t)
+}
+
+func TestRevisionFromContextBindsDatastore(t *testing.T) {
+	require := require.New(t)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("UniqueID").Return(uniqueID, nil)
+	ds.On("OptimizedRevision").Return(optimized, nil).Once()
+	ds.On("RevisionFromString", optimized.String()).Return(optimized, nil).Once()
+
+	updated := ContextWithHandle(context.Background())
+	err := AddRevisionToContext(updated, &v1.ReadRelationshipsRequest{}, ds)
+	require.NoError(err)
+
+	_, token, err := RevisionFromContext(updated)
+	require.NoError(err)
+
+	rev, err := zedtoken.DecodeRevisionForDatastore(context.Background(), token, ds)
+	require.NoError(err)
+	require.True(optimized.Equal(rev))
+	ds.AssertExpectations(t)
+}
+
 func TestAddRevisionToContextNoConsistencyAPI(t *testing.T) {
 	require := require.New(t)
 
@@ -165,6 +223,7 @@ func TestAddRevisionToContextWithCursor(t *testing.T) {
 	require := require.New(t)
 
 	ds := &proxy_test.MockDatastore{}
+	ds.On("UniqueID").Return(uniqueID, nil)
 	ds.On("CheckRevision", optimized).Return(nil).Times(1)
 	ds.On("RevisionFromString", optimized.String()).Return(optimized, nil).Once()
 
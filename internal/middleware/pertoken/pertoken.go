@@ -3,12 +3,14 @@ package pertoken
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
 	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"google.golang.org/grpc"
+	yamlv3 "gopkg.in/yaml.v3"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	log "github.com/authzed/spicedb/internal/logging"
@@ -22,19 +24,34 @@ const (
 	revisionQuantization = 10 * time.Millisecond
 )
 
+// Seed is the data with which every datastore created by the middleware is initialized.
+type Seed struct {
+	// ConfigFilePaths are validation files whose schema and relationships are loaded.
+	ConfigFilePaths []string
+
+	// SchemaFilePath is a file containing a schema to be loaded, if any.
+	SchemaFilePath string
+
+	// RelationshipsFilePath is a file containing relationships to be loaded, one per line,
+	// if any.
+	RelationshipsFilePath string
+}
+
 // MiddlewareForTesting is used to create a unique datastore for each token. It is intended for use in the
 // testserver only.
 type MiddlewareForTesting struct {
 	datastoreByToken *sync.Map
-	configFilePaths  []string
+	seed             Seed
+	isolatePerToken  bool
 }
 
-// NewMiddleware returns a new per-token datastore middleware that initializes each datastore with the data in the
-// config files.
-func NewMiddleware(configFilePaths []string) *MiddlewareForTesting {
+// NewMiddleware returns a new per-token datastore middleware that initializes each datastore with the seed
+// data. If isolatePerToken is false, all the requests share a single datastore.
+func NewMiddleware(seed Seed, isolatePerToken bool) *MiddlewareForTesting {
 	return &MiddlewareForTesting{
 		datastoreByToken: &sync.Map{},
-		configFilePaths:  configFilePaths,
+		seed:             seed,
+		isolatePerToken:  isolatePerToken,
 	}
 }
 
@@ -43,7 +60,11 @@ type squashable interface {
 }
 
 func (m *MiddlewareForTesting) getOrCreateDatastore(ctx context.Context) (datastore.Datastore, error) {
-	tokenStr, _ := grpcauth.AuthFromMD(ctx, "bearer")
+	var tokenStr string
+	if m.isolatePerToken {
+		tokenStr, _ = grpcauth.AuthFromMD(ctx, "bearer")
+	}
+
 	tokenDatastore, ok := m.datastoreByToken.Load(tokenStr)
 	if ok {
 		return tokenDatastore.(datastore.Datastore), nil
@@ -55,7 +76,12 @@ func (m *MiddlewareForTesting) getOrCreateDatastore(ctx context.Context) (datast
 		return nil, fmt.Errorf("failed to init datastore: %w", err)
 	}
 
-	_, _, err = validationfile.PopulateFromFiles(ctx, ds, m.configFilePaths)
+	contents, err := m.seed.contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seed files: %w", err)
+	}
+
+	_, _, err = validationfile.PopulateFromFilesContents(ctx, ds, contents)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config files: %w", err)
 	}
@@ -63,10 +89,58 @@ func (m *MiddlewareForTesting) getOrCreateDatastore(ctx context.Context) (datast
 	// Squash the revisions so that the caller sees all the populated data.
 	ds.(squashable).SquashRevisionsForTesting()
 
-	m.datastoreByToken.Store(tokenStr, ds)
+	// Another request for the same token may have raced to create the datastore first.
+	existing, loaded := m.datastoreByToken.LoadOrStore(tokenStr, ds)
+	if loaded {
+		if err := ds.Close(); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to close unused datastore")
+		}
+		return existing.(datastore.Datastore), nil
+	}
 	return ds, nil
 }
 
+// contents returns the seed data in the form of validation files contents, keyed by path.
+func (s Seed) contents() (map[string][]byte, error) {
+	contents := make(map[string][]byte, len(s.ConfigFilePaths)+1)
+	for _, filePath := range s.ConfigFilePaths {
+		fileContents, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		contents[filePath] = fileContents
+	}
+
+	if s.SchemaFilePath == "" && s.RelationshipsFilePath == "" {
+		return contents, nil
+	}
+
+	// The schema and relationships files are combined into a validation file, so that they are
+	// validated and loaded in the same way as the config files.
+	seedFile := map[string]string{}
+	if s.SchemaFilePath != "" {
+		schema, err := os.ReadFile(s.SchemaFilePath)
+		if err != nil {
+			return nil, err
+		}
+		seedFile["schema"] = string(schema)
+	}
+	if s.RelationshipsFilePath != "" {
+		relationships, err := os.ReadFile(s.RelationshipsFilePath)
+		if err != nil {
+			return nil, err
+		}
+		seedFile["relationships"] = string(relationships)
+	}
+
+	encoded, err := yamlv3.Marshal(seedFile)
+	if err != nil {
+		return nil, err
+	}
+	contents[s.SchemaFilePath+"+"+s.RelationshipsFilePath] = encoded
+	return contents, nil
+}
+
 // UnaryServerInterceptor returns a new unary server interceptor that sets a separate in-memory datastore per token
 func (m *MiddlewareForTesting) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
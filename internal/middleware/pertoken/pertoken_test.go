@@ -0,0 +1,92 @@
+package pertoken
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+func writeFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func datastoreFor(t *testing.T, m *MiddlewareForTesting, token string) datastore.Datastore {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer "+token))
+
+	var ds datastore.Datastore
+	_, err := m.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		ds = datastoremw.MustFromContext(ctx)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	return ds
+}
+
+func countRelationships(t *testing.T, ds datastore.Datastore) int {
+	ctx := context.Background()
+	revision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	iter, err := ds.SnapshotReader(revision).QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: "document"})
+	require.NoError(t, err)
+	defer iter.Close()
+
+	count := 0
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		count++
+	}
+	require.NoError(t, iter.Err())
+	return count
+}
+
+func TestSeedFromSchemaAndRelationshipsFiles(t *testing.T) {
+	m := NewMiddleware(Seed{
+		SchemaFilePath: writeFile(t, "schema.zed", `
+			definition user {}
+			definition document {
+				relation viewer: user
+			}
+		`),
+		RelationshipsFilePath: writeFile(t, "relationships.txt", "document:first#viewer@user:tom\ndocument:second#viewer@user:tom\n"),
+	}, true)
+
+	ds := datastoreFor(t, m, "first")
+	require.Equal(t, 2, countRelationships(t, ds))
+
+	revision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+	_, _, err = ds.SnapshotReader(revision).ReadNamespaceByName(context.Background(), "document")
+	require.NoError(t, err)
+}
+
+func TestIsolatePerToken(t *testing.T) {
+	seed := Seed{
+		SchemaFilePath: writeFile(t, "schema.zed", "definition user {}"),
+	}
+
+	isolated := NewMiddleware(seed, true)
+	require.Same(t, datastoreFor(t, isolated, "first"), datastoreFor(t, isolated, "first"))
+	require.NotSame(t, datastoreFor(t, isolated, "first"), datastoreFor(t, isolated, "second"))
+
+	shared := NewMiddleware(seed, false)
+	require.Same(t, datastoreFor(t, shared, "first"), datastoreFor(t, shared, "second"))
+}
+
+func TestSeedMissingFile(t *testing.T) {
+	m := NewMiddleware(Seed{SchemaFilePath: filepath.Join(t.TempDir(), "missing.zed")}, true)
+
+	_, err := m.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	require.Error(t, err)
+}
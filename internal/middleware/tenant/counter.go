@@ -0,0 +1,287 @@
+package tenant
+
+import (
+	"context"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type usageDatastore struct {
+	datastore.Datastore
+	tracker *UsageTracker
+}
+
+// Datastore returns a proxy of the datastore updating the counters of relationships of the tenants
+// in every transaction writing their relationships, and failing the transactions which would
+// exceed the quota of relationships of a tenant.
+func (t *UsageTracker) Datastore(delegate datastore.Datastore) datastore.Datastore {
+	return usageDatastore{Datastore: delegate, tracker: t}
+}
+
+func (ud usageDatastore) ReadWriteTx(
+	ctx context.Context,
+	f datastore.TxUserFunc,
+	opts ...options.RWTOptionsOption,
+) (datastore.Revision, error) {
+	return ud.Datastore.ReadWriteTx(ctx, func(ctx context.Context, delegate datastore.ReadWriteTransaction) error {
+		rwt := ud.tracker.newUsageRWT(delegate)
+		if err := f(ctx, rwt); err != nil {
+			return err
+		}
+		return rwt.flush(ctx)
+	}, opts...)
+}
+
+func (ud usageDatastore) Unwrap() datastore.Datastore {
+	return ud.Datastore
+}
+
+// usageRWT accounts for the relationships written by a transaction, and writes the changed
+// counters when the transaction is about to be committed.
+type usageRWT struct {
+	datastore.ReadWriteTransaction
+	tracker  *UsageTracker
+	counters map[*tenantUsage]*counter
+}
+
+type counter struct {
+	initial uint64
+	count   uint64
+	changed bool
+}
+
+func (t *UsageTracker) newUsageRWT(delegate datastore.ReadWriteTransaction) *usageRWT {
+	return &usageRWT{ReadWriteTransaction: delegate, tracker: t, counters: map[*tenantUsage]*counter{}}
+}
+
+// counter returns the counter of the tenant in the transaction, initializing it by counting the
+// relationships of the tenant if it was never initialized. It must be loaded before relationships
+// of the tenant are written, as some datastores do not read the writes of their transactions.
+func (rwt *usageRWT) counter(ctx context.Context, usage *tenantUsage) (*counter, error) {
+	if c, ok := rwt.counters[usage]; ok {
+		return c, nil
+	}
+
+	count, found, err := readCounter(ctx, rwt.ReadWriteTransaction, usage)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if count, err = countRelationships(ctx, rwt.ReadWriteTransaction, usage); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &counter{initial: count, count: count, changed: !found}
+	rwt.counters[usage] = c
+	return c, nil
+}
+
+// add adds the number of relationships created, or removes the number deleted, to the counters of
+// the tenants.
+func (rwt *usageRWT) add(ctx context.Context, deltas map[*tenantUsage]int64) error {
+	for usage, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+
+		c, err := rwt.counter(ctx, usage)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case delta > 0:
+			c.count += uint64(delta)
+		case uint64(-delta) > c.count:
+			c.count = 0
+		default:
+			c.count -= uint64(-delta)
+		}
+		c.changed = true
+	}
+	return nil
+}
+
+// flush writes the changed counters, returning a ResourceExhausted error if the relationships of
+// a tenant grew beyond its quota. Tenants beyond their quota can always delete relationships.
+func (rwt *usageRWT) flush(ctx context.Context) error {
+	var mutations []*core.RelationTupleUpdate
+	for _, usage := range rwt.tracker.allTenants() {
+		c, ok := rwt.counters[usage]
+		if !ok || !c.changed {
+			continue
+		}
+
+		if maxRelationships := rwt.tracker.quotas.MaxRelationships; maxRelationships > 0 && c.count > maxRelationships && c.count > c.initial {
+			rwt.tracker.rejected(usage)
+			return status.Errorf(codes.ResourceExhausted, "exceeded the quota of %d relationships", maxRelationships)
+		}
+
+		rel, err := counterRelationship(usage, c.count)
+		if err != nil {
+			return err
+		}
+		mutations = append(mutations, tuple.Touch(rel))
+	}
+
+	if len(mutations) == 0 {
+		return nil
+	}
+	return rwt.ReadWriteTransaction.WriteRelationships(ctx, mutations)
+}
+
+// exists returns whether the relationship exists, regardless of its caveat.
+func (rwt *usageRWT) exists(ctx context.Context, rel *core.RelationTuple) (bool, error) {
+	it, err := rwt.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		OptionalResourceType:     rel.ResourceAndRelation.Namespace,
+		OptionalResourceIds:      []string{rel.ResourceAndRelation.ObjectId},
+		OptionalResourceRelation: rel.ResourceAndRelation.Relation,
+		OptionalSubjectsSelectors: []datastore.SubjectsSelector{{
+			OptionalSubjectType: rel.Subject.Namespace,
+			OptionalSubjectIds:  []string{rel.Subject.ObjectId},
+			RelationFilter:      datastore.SubjectRelationFilter{}.WithRelation(rel.Subject.Relation),
+		}},
+	}, options.WithLimit(options.LimitOne))
+	if err != nil {
+		return false, err
+	}
+	defer it.Close()
+
+	found := it.Next() != nil
+	return found, it.Err()
+}
+
+func (rwt *usageRWT) WriteRelationships(ctx context.Context, mutations []*core.RelationTupleUpdate) error {
+	deltas := map[*tenantUsage]int64{}
+	for _, mutation := range mutations {
+		tenants := rwt.tracker.tenantsOf(mutation.Tuple.ResourceAndRelation.Namespace)
+		if len(tenants) == 0 {
+			continue
+		}
+
+		var delta int64
+		switch mutation.Operation {
+		case core.RelationTupleUpdate_CREATE:
+			delta = 1
+
+		case core.RelationTupleUpdate_TOUCH, core.RelationTupleUpdate_DELETE:
+			exists, err := rwt.exists(ctx, mutation.Tuple)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case mutation.Operation == core.RelationTupleUpdate_TOUCH && !exists:
+				delta = 1
+			case mutation.Operation == core.RelationTupleUpdate_DELETE && exists:
+				delta = -1
+			}
+		}
+
+		for _, usage := range tenants {
+			deltas[usage] += delta
+		}
+	}
+
+	if err := rwt.add(ctx, deltas); err != nil {
+		return err
+	}
+	return rwt.ReadWriteTransaction.WriteRelationships(ctx, mutations)
+}
+
+func (rwt *usageRWT) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
+	if filter.GetResourceType() != "" && len(rwt.tracker.tenantsOf(filter.GetResourceType())) == 0 {
+		return rwt.ReadWriteTransaction.DeleteRelationships(ctx, filter, opts...)
+	}
+
+	dsFilter, err := datastore.RelationshipsFilterFromPublicFilter(filter)
+	if err != nil {
+		return false, err
+	}
+
+	// A limited deletion is made of the relationships counted, as the datastore could otherwise
+	// delete others.
+	delOpts := options.NewDeleteOptionsWithOptionsAndDefaults(opts...)
+	limited := delOpts.DeleteLimit != nil && *delOpts.DeleteLimit > 0
+
+	var queryOpts []options.QueryOptionsOption
+	if limited {
+		queryOpts = append(queryOpts, options.WithLimit(delOpts.DeleteLimit))
+	}
+
+	it, err := rwt.QueryRelationships(ctx, dsFilter, queryOpts...)
+	if err != nil {
+		return false, err
+	}
+
+	deltas := map[*tenantUsage]int64{}
+	var mutations []*core.RelationTupleUpdate
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		for _, usage := range rwt.tracker.tenantsOf(rel.ResourceAndRelation.Namespace) {
+			deltas[usage]--
+		}
+		if limited {
+			mutations = append(mutations, tuple.Delete(rel.CloneVT()))
+		}
+	}
+	err = it.Err()
+	it.Close()
+	if err != nil {
+		return false, err
+	}
+
+	if err := rwt.add(ctx, deltas); err != nil {
+		return false, err
+	}
+
+	if !limited {
+		return rwt.ReadWriteTransaction.DeleteRelationships(ctx, filter, opts...)
+	}
+	return uint64(len(mutations)) == *delOpts.DeleteLimit, rwt.ReadWriteTransaction.WriteRelationships(ctx, mutations)
+}
+
+func (rwt *usageRWT) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	// The tenants of the relationships are only known once loaded, so every counter is loaded
+	// beforehand.
+	for _, usage := range rwt.tracker.allTenants() {
+		if _, err := rwt.counter(ctx, usage); err != nil {
+			return 0, err
+		}
+	}
+
+	source := &usageBulkSource{BulkWriteRelationshipSource: iter, tracker: rwt.tracker, deltas: map[*tenantUsage]int64{}}
+	loaded, err := rwt.ReadWriteTransaction.BulkLoad(ctx, source)
+	if err != nil {
+		return loaded, err
+	}
+	return loaded, rwt.add(ctx, source.deltas)
+}
+
+// usageBulkSource counts the relationships loaded for each tenant.
+type usageBulkSource struct {
+	datastore.BulkWriteRelationshipSource
+	tracker *UsageTracker
+	deltas  map[*tenantUsage]int64
+}
+
+func (ubs *usageBulkSource) Next(ctx context.Context) (*core.RelationTuple, error) {
+	rel, err := ubs.BulkWriteRelationshipSource.Next(ctx)
+	if err != nil || rel == nil {
+		return rel, err
+	}
+
+	for _, usage := range ubs.tracker.tenantsOf(rel.ResourceAndRelation.Namespace) {
+		ubs.deltas[usage]++
+	}
+	return rel, nil
+}
+
+var _ datastore.Datastore = usageDatastore{}
@@ -0,0 +1,273 @@
+// Package tenant implements the isolation of tenants sharing a cluster, each bound to object type
+// prefixes.
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/internal/datastore/proxy"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+// Bindings maps the identity of a caller to the object type prefixes it is bound to. The identity
+// is either one of the preshared keys, or the identity of a caller authenticated with a JWT.
+type Bindings map[string][]string
+
+// ParseBindings parses bindings of the form `<identity>=<prefix>[,<prefix>...]`. A caller bound
+// more than once is bound to all the prefixes.
+func ParseBindings(values []string) (Bindings, error) {
+	bindings := make(Bindings, len(values))
+	for _, value := range values {
+		index := strings.LastIndex(value, "=")
+		if index <= 0 {
+			return nil, fmt.Errorf("invalid tenant binding, expected `<identity>=<prefix>[,<prefix>...]`")
+		}
+
+		identity := value[:index]
+		for _, prefix := range strings.Split(value[index+1:], ",") {
+			prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+			if prefix == "" {
+				return nil, fmt.Errorf("invalid tenant binding, expected `<identity>=<prefix>[,<prefix>...]`")
+			}
+			bindings[identity] = append(bindings[identity], prefix)
+		}
+	}
+	return bindings, nil
+}
+
+// prefixesFor returns the prefixes the caller of the request is bound to, if any.
+func (b Bindings) prefixesFor(ctx context.Context) ([]string, bool) {
+	if caller, ok := auth.CallerFromContext(ctx); ok {
+		prefixes, ok := b[caller.Identity]
+		return prefixes, ok
+	}
+
+	token, err := grpcauth.AuthFromMD(ctx, "bearer")
+	if err != nil {
+		return nil, false
+	}
+
+	prefixes, ok := b[token]
+	return prefixes, ok
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that restricts callers bound to
+// object type prefixes to those prefixes: the object types referenced by the request must fall
+// under them, and the datastore is scoped to them. If a usage tracker is given, the request is
+// accounted for and rejected if it exceeds the quotas of the tenant, and the relationships written
+// by any caller are counted for their tenants. Callers without a binding are not restricted. It
+// must run after the datastore middleware.
+func UnaryServerInterceptor(bindings Bindings, usage *UsageTracker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		prefixes, ok := bindings.prefixesFor(ctx)
+		if ok {
+			if usage != nil {
+				if err := usage.admitRequest(prefixes); err != nil {
+					return nil, err
+				}
+			}
+
+			if err := validateRequest(prefixes, req); err != nil {
+				return nil, err
+			}
+
+			if usage != nil {
+				if err := usage.admitSchema(prefixes, req); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if err := datastoremw.SetInContext(ctx, scopedDatastore(datastoremw.MustFromContext(ctx), prefixes, ok, usage)); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that restricts callers bound to
+// object type prefixes to those prefixes, validating each incoming request message. If a usage
+// tracker is given, the stream is accounted for and rejected if it exceeds the quotas of the
+// tenant, and the relationships written by any caller are counted for their tenants. Callers
+// without a binding are not restricted. It must run after the datastore middleware.
+func StreamServerInterceptor(bindings Bindings, usage *UsageTracker) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		prefixes, ok := bindings.prefixesFor(stream.Context())
+		if ok && usage != nil {
+			if err := usage.admitRequest(prefixes); err != nil {
+				return err
+			}
+		}
+
+		wrapped := middleware.WrapServerStream(stream)
+		if err := datastoremw.SetInContext(wrapped.WrappedContext, scopedDatastore(datastoremw.MustFromContext(stream.Context()), prefixes, ok, usage)); err != nil {
+			return err
+		}
+
+		if !ok {
+			return handler(srv, wrapped)
+		}
+		return handler(srv, &recvWrapper{wrapped, prefixes})
+	}
+}
+
+// scopedDatastore returns the datastore scoped to the prefixes if the caller is bound, counting the
+// relationships written if a usage tracker is given.
+func scopedDatastore(ds datastore.Datastore, prefixes []string, bound bool, usage *UsageTracker) datastore.Datastore {
+	if usage != nil {
+		ds = usage.Datastore(ds)
+	}
+	if bound {
+		ds = proxy.NewTenantScopedDatastore(ds, prefixes)
+	}
+	return ds
+}
+
+type recvWrapper struct {
+	grpc.ServerStream
+	prefixes []string
+}
+
+func (s *recvWrapper) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateRequest(s.prefixes, m)
+}
+
+// validateRequest returns an error if the request references an object type or caveat outside of
+// the prefixes, or could read or watch relationships of any type. Requests of any other type than
+// those known to be scoped are denied.
+func validateRequest(prefixes []string, req interface{}) error {
+	var names []string
+	switch req := req.(type) {
+	case *v1.CheckPermissionRequest:
+		names = append(names, req.GetResource().GetObjectType(), req.GetSubject().GetObject().GetObjectType())
+
+	case *v1.CheckBulkPermissionsRequest:
+		for _, item := range req.GetItems() {
+			names = append(names, item.GetResource().GetObjectType(), item.GetSubject().GetObject().GetObjectType())
+		}
+
+	case *v1.BulkCheckPermissionRequest:
+		for _, item := range req.GetItems() {
+			names = append(names, item.GetResource().GetObjectType(), item.GetSubject().GetObject().GetObjectType())
+		}
+
+	case *v1.ExpandPermissionTreeRequest:
+		names = append(names, req.GetResource().GetObjectType())
+
+	case *v1.LookupResourcesRequest:
+		names = append(names, req.GetResourceObjectType(), req.GetSubject().GetObject().GetObjectType())
+
+	case *v1.LookupSubjectsRequest:
+		names = append(names, req.GetResource().GetObjectType(), req.GetSubjectObjectType())
+
+	case *v1.ReadRelationshipsRequest:
+		names = append(names, filterNames(req.GetRelationshipFilter())...)
+
+	case *v1.WriteRelationshipsRequest:
+		for _, update := range req.GetUpdates() {
+			names = append(names, relationshipNames(update.GetRelationship())...)
+		}
+		for _, precondition := range req.GetOptionalPreconditions() {
+			names = append(names, filterNames(precondition.GetFilter())...)
+		}
+
+	case *v1.DeleteRelationshipsRequest:
+		names = append(names, filterNames(req.GetRelationshipFilter())...)
+		for _, precondition := range req.GetOptionalPreconditions() {
+			names = append(names, filterNames(precondition.GetFilter())...)
+		}
+
+	case *v1.BulkImportRelationshipsRequest:
+		for _, rel := range req.GetRelationships() {
+			names = append(names, relationshipNames(rel)...)
+		}
+
+	case *v1.BulkExportRelationshipsRequest:
+		if req.GetOptionalRelationshipFilter() == nil {
+			return status.Errorf(codes.PermissionDenied, "a relationship filter is required for callers bound to object type prefixes")
+		}
+		names = append(names, filterNames(req.GetOptionalRelationshipFilter())...)
+
+	case *v1.WatchRequest:
+		if len(req.GetOptionalObjectTypes()) == 0 && len(req.GetOptionalRelationshipFilters()) == 0 {
+			return status.Errorf(codes.PermissionDenied, "object types or relationship filters are required for callers bound to object type prefixes")
+		}
+		names = append(names, req.GetOptionalObjectTypes()...)
+		for _, filter := range req.GetOptionalRelationshipFilters() {
+			names = append(names, filterNames(filter)...)
+		}
+
+	case *v1.WriteSchemaRequest:
+		compiled, err := compiler.Compile(compiler.InputSchema{
+			Source:       input.Source("schema"),
+			SchemaString: req.GetSchema(),
+		}, compiler.AllowUnprefixedObjectType())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		for _, def := range compiled.ObjectDefinitions {
+			names = append(names, def.Name)
+		}
+		for _, caveat := range compiled.CaveatDefinitions {
+			names = append(names, caveat.Name)
+		}
+
+	case *v1.ReadSchemaRequest, *healthpb.HealthCheckRequest:
+		// The schema is scoped by the datastore, and health checks reference no object type.
+
+	default:
+		return status.Errorf(codes.PermissionDenied, "%T is not supported for callers bound to object type prefixes", req)
+	}
+
+	for _, name := range names {
+		if name == "" {
+			return status.Errorf(codes.PermissionDenied, "relationship filters must have an object type for callers bound to object type prefixes")
+		}
+		if !proxy.WithinTenant(prefixes, name) {
+			return proxy.NewOutsideTenantErr(name)
+		}
+	}
+	return nil
+}
+
+// filterNames returns the object types of the filter, requiring at least one of them so that
+// relationships of any type are never matched.
+func filterNames(filter *v1.RelationshipFilter) []string {
+	var names []string
+	if filter.GetResourceType() != "" {
+		names = append(names, filter.GetResourceType())
+	}
+	if filter.GetOptionalSubjectFilter() != nil {
+		names = append(names, filter.GetOptionalSubjectFilter().GetSubjectType())
+	}
+	if len(names) == 0 {
+		names = append(names, "")
+	}
+	return names
+}
+
+func relationshipNames(rel *v1.Relationship) []string {
+	names := []string{rel.GetResource().GetObjectType(), rel.GetSubject().GetObject().GetObjectType()}
+	if caveat := rel.GetOptionalCaveat(); caveat != nil {
+		names = append(names, caveat.GetCaveatName())
+	}
+	return names
+}
@@ -0,0 +1,170 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	ns "github.com/authzed/spicedb/pkg/namespace"
+)
+
+func TestParseBindings(t *testing.T) {
+	bindings, err := ParseBindings([]string{"somekey=acme", "key=with=equals=acme/,widgets", "somekey=other"})
+	require.NoError(t, err)
+	require.Equal(t, Bindings{
+		"somekey":         {"acme", "other"},
+		"key=with=equals": {"acme", "widgets"},
+	}, bindings)
+
+	_, err = ParseBindings([]string{"somekey"})
+	require.Error(t, err)
+
+	_, err = ParseBindings([]string{"=acme"})
+	require.Error(t, err)
+
+	_, err = ParseBindings([]string{"somekey=acme,"})
+	require.Error(t, err)
+}
+
+func check(resourceType, subjectType string) *v1.CheckPermissionRequest {
+	return &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: resourceType, ObjectId: "masterplan"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: subjectType, ObjectId: "tom"}},
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	prefixes := []string{"acme"}
+
+	for _, tc := range []struct {
+		name    string
+		request any
+		allowed bool
+	}{
+		{"check within tenant", check("acme/document", "acme/user"), true},
+		{"check of another tenant", check("other/document", "acme/user"), false},
+		{"check with subject of another tenant", check("acme/document", "other/user"), false},
+		{
+			"lookup resources of another tenant",
+			&v1.LookupResourcesRequest{ResourceObjectType: "other/document", Subject: check("acme/document", "acme/user").Subject},
+			false,
+		},
+		{
+			"lookup subjects within tenant",
+			&v1.LookupSubjectsRequest{Resource: check("acme/document", "acme/user").Resource, SubjectObjectType: "acme/user"},
+			true,
+		},
+		{
+			"read with subject filter only",
+			&v1.ReadRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{
+				OptionalSubjectFilter: &v1.SubjectFilter{SubjectType: "acme/user"},
+			}},
+			true,
+		},
+		{
+			"read of any type",
+			&v1.ReadRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{OptionalRelation: "viewer"}},
+			false,
+		},
+		{
+			"write with caveat of another tenant",
+			&v1.WriteRelationshipsRequest{Updates: []*v1.RelationshipUpdate{{
+				Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+				Relationship: &v1.Relationship{
+					Resource:       &v1.ObjectReference{ObjectType: "acme/document", ObjectId: "masterplan"},
+					Relation:       "viewer",
+					Subject:        &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "acme/user", ObjectId: "tom"}},
+					OptionalCaveat: &v1.ContextualizedCaveat{CaveatName: "other/somecaveat"},
+				},
+			}}},
+			false,
+		},
+		{"watch of any type", &v1.WatchRequest{}, false},
+		{"watch within tenant", &v1.WatchRequest{OptionalObjectTypes: []string{"acme/document"}}, true},
+		{"export of any type", &v1.BulkExportRelationshipsRequest{}, false},
+		{"schema within tenant", &v1.WriteSchemaRequest{Schema: "definition acme/user {}"}, true},
+		{"schema of another tenant", &v1.WriteSchemaRequest{Schema: "definition acme/user {}\ndefinition other/user {}"}, false},
+		{"read schema", &v1.ReadSchemaRequest{}, true},
+		{"health check", &healthpb.HealthCheckRequest{}, true},
+		{"unknown request", &v1.LookupResourcesResponse{}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateRequest(prefixes, tc.request)
+			if tc.allowed {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, codes.PermissionDenied, status.Code(err))
+		})
+	}
+
+	// Schemas that do not compile cannot be checked, so they are rejected.
+	err := validateRequest(prefixes, &v1.WriteSchemaRequest{Schema: "definition acme/user {\ndefinition other/user {}"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	_, err = ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, ns.Namespace("acme/document"), ns.Namespace("other/document"))
+	})
+	require.NoError(t, err)
+
+	interceptor := UnaryServerInterceptor(Bindings{"somekey": {"acme"}, "someidentity": {"other"}}, nil)
+
+	// handler returns the names of the namespaces visible through the datastore in context.
+	handler := func(ctx context.Context, req any) (any, error) {
+		ds := datastoremw.MustFromContext(ctx)
+		revision, err := ds.HeadRevision(ctx)
+		require.NoError(t, err)
+
+		namespaces, err := ds.SnapshotReader(revision).ListAllNamespaces(ctx)
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(namespaces))
+		for _, namespace := range namespaces {
+			names = append(names, namespace.Definition.Name)
+		}
+		return names, nil
+	}
+
+	call := func(ctx context.Context, req any) (any, error) {
+		ctx = datastoremw.ContextWithDatastore(ctx, ds)
+		return interceptor(ctx, req, &grpc.UnaryServerInfo{}, handler)
+	}
+
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer "+key))
+	}
+
+	names, err := call(withKey("somekey"), check("acme/document", "acme/user"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"acme/document"}, names)
+
+	_, err = call(withKey("somekey"), check("other/document", "acme/user"))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// A caller authenticated with a JWT is bound by identity.
+	names, err = call(auth.ContextWithCaller(context.Background(), &auth.Caller{Identity: "someidentity"}), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"other/document"}, names)
+
+	// Callers without a binding are not restricted.
+	names, err = call(withKey("anotherkey"), check("other/document", "acme/user"))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"acme/document", "other/document"}, names)
+}
@@ -0,0 +1,356 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/datastore/proxy"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// UsagePath is the path under which the usage of tenants is served by the admin API.
+const UsagePath = "/admin/tenants/usage"
+
+const (
+	// usageNamespace is the type of the relationships holding the number of relationships of each
+	// tenant, since the datastore stores nothing but schema and relationships. It has no prefix,
+	// so that no tenant can read or write it.
+	usageNamespace = "spicedb_tenant_usage"
+
+	// usageRelation is the relation of the relationship holding the number of relationships of a
+	// tenant, which is stored in the context of its caveat so that the relationship is updated
+	// in place rather than replaced.
+	usageRelation = "relationships"
+	usageCaveat   = "count"
+	usageCountKey = "relationships"
+)
+
+// Quotas are the limits applied to each tenant. A zero value is unlimited.
+type Quotas struct {
+	// MaxRelationships is the number of relationships a tenant can store.
+	MaxRelationships uint64
+
+	// MaxObjectDefinitions is the number of object definitions a tenant can have in its schema.
+	MaxObjectDefinitions uint64
+
+	// MaxRequestsPerSecond is the rate of API requests a tenant can make.
+	MaxRequestsPerSecond float64
+}
+
+// TenantUsage is the usage of a tenant, identified by the object type prefixes it is bound to.
+type TenantUsage struct {
+	Prefixes          []string `json:"prefixes"`
+	Relationships     uint64   `json:"relationships"`
+	ObjectDefinitions uint64   `json:"objectDefinitions"`
+	Requests          uint64   `json:"requests"`
+	RejectedRequests  uint64   `json:"rejectedRequests"`
+}
+
+// UsageTracker accounts for the usage of tenants and enforces their quotas.
+//
+// The relationships of each tenant are counted by a counter stored in the datastore, which is
+// updated in the same transaction as the relationships written through the datastore returned by
+// Datastore, so that the quota of relationships is exact. A counter is initialized by counting the
+// relationships of its tenant once, when first needed. Object definitions are counted from the
+// schema, and the requests made by each tenant are counted since the start of the process.
+type UsageTracker struct {
+	ds     datastore.Datastore
+	quotas Quotas
+
+	lock    sync.Mutex
+	tenants map[string]*tenantUsage
+}
+
+type tenantUsage struct {
+	key      string
+	prefixes []string
+	limiter  *rate.Limiter
+
+	lock             sync.Mutex
+	requests         uint64
+	rejectedRequests uint64
+}
+
+// NewUsageTracker returns a tracker of the usage of the tenants bound in the bindings, whose
+// relationships are counted in the datastore.
+func NewUsageTracker(ds datastore.Datastore, bindings Bindings, quotas Quotas) *UsageTracker {
+	tracker := &UsageTracker{
+		ds:      ds,
+		quotas:  quotas,
+		tenants: make(map[string]*tenantUsage, len(bindings)),
+	}
+	for _, prefixes := range bindings {
+		tracker.tenant(prefixes)
+	}
+	return tracker
+}
+
+// tenant returns the usage of the tenant bound to the prefixes. Callers bound to the same prefixes
+// share their usage and quotas.
+func (t *UsageTracker) tenant(prefixes []string) *tenantUsage {
+	sorted := slices.Clone(prefixes)
+	sort.Strings(sorted)
+	key := strings.Join(sorted, "|")
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if usage, ok := t.tenants[key]; ok {
+		return usage
+	}
+
+	usage := &tenantUsage{key: key, prefixes: sorted, limiter: rate.NewLimiter(rate.Inf, 0)}
+	if t.quotas.MaxRequestsPerSecond > 0 {
+		usage.limiter = rate.NewLimiter(rate.Limit(t.quotas.MaxRequestsPerSecond), int(math.Ceil(t.quotas.MaxRequestsPerSecond)))
+	}
+	t.tenants[key] = usage
+	return usage
+}
+
+// tenantsOf returns the usage of the tenants whose prefixes the object type falls under.
+func (t *UsageTracker) tenantsOf(objectType string) []*tenantUsage {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var tenants []*tenantUsage
+	for _, usage := range t.tenants {
+		if proxy.WithinTenant(usage.prefixes, objectType) {
+			tenants = append(tenants, usage)
+		}
+	}
+	return tenants
+}
+
+// allTenants returns the usage of every tenant, sorted by their prefixes.
+func (t *UsageTracker) allTenants() []*tenantUsage {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tenants := make([]*tenantUsage, 0, len(t.tenants))
+	for _, usage := range t.tenants {
+		tenants = append(tenants, usage)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].key < tenants[j].key })
+	return tenants
+}
+
+// admitRequest accounts for a request of the tenant, returning a ResourceExhausted error if it
+// exceeds the rate of requests of the tenant.
+func (t *UsageTracker) admitRequest(prefixes []string) error {
+	usage := t.tenant(prefixes)
+	allowed := usage.limiter.Allow()
+
+	usage.lock.Lock()
+	defer usage.lock.Unlock()
+
+	usage.requests++
+	if !allowed {
+		usage.rejectedRequests++
+		return status.Errorf(codes.ResourceExhausted, "exceeded the quota of %v requests per second", t.quotas.MaxRequestsPerSecond)
+	}
+	return nil
+}
+
+// admitSchema returns a ResourceExhausted error if the request message writes a schema exceeding
+// the quota of object definitions of the tenant.
+func (t *UsageTracker) admitSchema(prefixes []string, req interface{}) error {
+	writeSchema, ok := req.(*v1.WriteSchemaRequest)
+	if !ok || t.quotas.MaxObjectDefinitions == 0 {
+		return nil
+	}
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: writeSchema.GetSchema(),
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil || uint64(len(compiled.ObjectDefinitions)) <= t.quotas.MaxObjectDefinitions {
+		return nil
+	}
+
+	t.rejected(t.tenant(prefixes))
+	return status.Errorf(codes.ResourceExhausted, "exceeded the quota of %d object definitions", t.quotas.MaxObjectDefinitions)
+}
+
+func (t *UsageTracker) rejected(usage *tenantUsage) {
+	usage.lock.Lock()
+	defer usage.lock.Unlock()
+	usage.rejectedRequests++
+}
+
+// Usage returns the usage of every tenant. The counters of relationships which were never
+// initialized are initialized first.
+func (t *UsageTracker) Usage(ctx context.Context) ([]TenantUsage, error) {
+	tenants := t.allTenants()
+
+	revision, err := t.ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := t.ds.SnapshotReader(revision)
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	relationships := make(map[*tenantUsage]uint64, len(tenants))
+	var uninitialized []*tenantUsage
+	for _, usage := range tenants {
+		count, found, err := readCounter(ctx, reader, usage)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			uninitialized = append(uninitialized, usage)
+		}
+		relationships[usage] = count
+	}
+
+	if len(uninitialized) > 0 {
+		if _, err := t.ds.ReadWriteTx(ctx, func(ctx context.Context, delegate datastore.ReadWriteTransaction) error {
+			rwt := t.newUsageRWT(delegate)
+			for _, usage := range uninitialized {
+				c, err := rwt.counter(ctx, usage)
+				if err != nil {
+					return err
+				}
+				relationships[usage] = c.count
+			}
+			return rwt.flush(ctx)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	usages := make([]TenantUsage, 0, len(tenants))
+	for _, usage := range tenants {
+		var objectDefinitions uint64
+		for _, ns := range namespaces {
+			if proxy.WithinTenant(usage.prefixes, ns.Definition.Name) {
+				objectDefinitions++
+			}
+		}
+
+		usage.lock.Lock()
+		usages = append(usages, TenantUsage{
+			Prefixes:          usage.prefixes,
+			Relationships:     relationships[usage],
+			ObjectDefinitions: objectDefinitions,
+			Requests:          usage.requests,
+			RejectedRequests:  usage.rejectedRequests,
+		})
+		usage.lock.Unlock()
+	}
+	return usages, nil
+}
+
+// NewUsageHandler returns an http.Handler serving the usage of every tenant as JSON, for billing
+// and chargeback.
+func NewUsageHandler(tracker *UsageTracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "usage must be requested with GET", http.StatusMethodNotAllowed)
+			return
+		}
+
+		usages, err := tracker.Usage(r.Context())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to count usage: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string][]TenantUsage{"tenants": usages})
+	})
+}
+
+// countRelationships counts the relationships of the tenant, with a resource type under its
+// prefixes.
+func countRelationships(ctx context.Context, reader datastore.Reader, usage *tenantUsage) (uint64, error) {
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var relationships uint64
+	for _, ns := range namespaces {
+		if !proxy.WithinTenant(usage.prefixes, ns.Definition.Name) {
+			continue
+		}
+
+		it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: ns.Definition.Name})
+		if err != nil {
+			return 0, err
+		}
+
+		for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+			relationships++
+		}
+		err = it.Err()
+		it.Close()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return relationships, nil
+}
+
+// readCounter reads the counter of relationships of the tenant, if it was initialized.
+func readCounter(ctx context.Context, reader datastore.Reader, usage *tenantUsage) (uint64, bool, error) {
+	it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		OptionalResourceType:     usageNamespace,
+		OptionalResourceIds:      []string{usage.key},
+		OptionalResourceRelation: usageRelation,
+	}, options.WithLimit(options.LimitOne))
+	if err != nil {
+		return 0, false, err
+	}
+	defer it.Close()
+
+	tpl := it.Next()
+	if err := it.Err(); err != nil {
+		return 0, false, err
+	}
+	if tpl == nil {
+		return 0, false, nil
+	}
+
+	count, ok := tpl.GetCaveat().GetContext().AsMap()[usageCountKey].(float64)
+	if !ok || count < 0 {
+		return 0, false, fmt.Errorf("invalid relationship counter for tenant `%s`", usage.key)
+	}
+	return uint64(count), true, nil
+}
+
+// counterRelationship returns the relationship holding the counter of relationships of the tenant.
+func counterRelationship(usage *tenantUsage, count uint64) (*core.RelationTuple, error) {
+	countContext, err := structpb.NewStruct(map[string]any{usageCountKey: float64(count)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.RelationTuple{
+		ResourceAndRelation: &core.ObjectAndRelation{Namespace: usageNamespace, ObjectId: usage.key, Relation: usageRelation},
+		Subject:             &core.ObjectAndRelation{Namespace: usageNamespace, ObjectId: usage.key, Relation: tuple.Ellipsis},
+		Caveat:              &core.ContextualizedCaveat{CaveatName: usageCaveat, Context: countContext},
+	}, nil
+}
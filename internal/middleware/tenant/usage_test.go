@@ -0,0 +1,135 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	ns "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func writeRelationships(ctx context.Context, ds datastore.Datastore, operation core.RelationTupleUpdate_Operation, rels ...string) error {
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		mutations := make([]*core.RelationTupleUpdate, 0, len(rels))
+		for _, rel := range rels {
+			mutations = append(mutations, &core.RelationTupleUpdate{Operation: operation, Tuple: tuple.MustParse(rel)})
+		}
+		return rwt.WriteRelationships(ctx, mutations)
+	})
+	return err
+}
+
+func TestUsageTracker(t *testing.T) {
+	ctx := context.Background()
+	delegate, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	_, err = delegate.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, ns.Namespace("acme/document"), ns.Namespace("acme/user"), ns.Namespace("other/document"))
+	})
+	require.NoError(t, err)
+
+	_, err = common.WriteTuples(ctx, delegate, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse("acme/document:first#viewer@acme/user:tom"),
+		tuple.MustParse("acme/document:second#viewer@acme/user:tom"),
+		tuple.MustParse("other/document:first#viewer@other/user:fred"),
+	)
+	require.NoError(t, err)
+
+	acme := []string{"acme"}
+	tracker := NewUsageTracker(delegate, Bindings{"somekey": acme, "otherkey": {"other"}}, Quotas{
+		MaxRelationships:     3,
+		MaxObjectDefinitions: 2,
+		MaxRequestsPerSecond: 1,
+	})
+	ds := tracker.Datastore(delegate)
+
+	require.NoError(t, tracker.admitRequest(acme))
+	require.Equal(t, codes.ResourceExhausted, status.Code(tracker.admitRequest(acme)))
+
+	// Touching existing relationships does not count them again.
+	require.NoError(t, writeRelationships(ctx, ds, core.RelationTupleUpdate_TOUCH,
+		"acme/document:first#viewer@acme/user:tom",
+		"acme/document:third#viewer@acme/user:tom",
+	))
+	err = writeRelationships(ctx, ds, core.RelationTupleUpdate_TOUCH, "acme/document:fourth#viewer@acme/user:tom")
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Relationships outside of every tenant are not counted.
+	require.NoError(t, writeRelationships(ctx, ds, core.RelationTupleUpdate_TOUCH, "unbound/document:first#viewer@acme/user:tom"))
+
+	// Deletions are never limited, and free the quota.
+	require.NoError(t, writeRelationships(ctx, ds, core.RelationTupleUpdate_DELETE,
+		"acme/document:first#viewer@acme/user:tom",
+		"acme/document:missing#viewer@acme/user:tom",
+	))
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: "acme/document"}, options.WithDeleteLimit(options.LimitOne))
+		return err
+	})
+	require.NoError(t, err)
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.BulkLoad(ctx, testfixtures.NewBulkTupleGenerator("acme/document", "viewer", "acme/user", 2, t))
+		return err
+	})
+	require.NoError(t, err)
+
+	err = tracker.admitSchema(acme, &v1.WriteSchemaRequest{Schema: "definition acme/user {}\ndefinition acme/document {}\ndefinition acme/folder {}"})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.NoError(t, tracker.admitSchema(acme, &v1.WriteSchemaRequest{Schema: "definition acme/user {}\ndefinition acme/document {}"}))
+
+	usages, err := tracker.Usage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []TenantUsage{
+		{
+			Prefixes:          []string{"acme"},
+			Relationships:     3,
+			ObjectDefinitions: 2,
+			Requests:          2,
+			RejectedRequests:  3,
+		},
+		{
+			Prefixes:          []string{"other"},
+			Relationships:     1,
+			ObjectDefinitions: 1,
+		},
+	}, usages)
+
+	// The counters hold the number of relationships of the tenants.
+	revision, err := delegate.HeadRevision(ctx)
+	require.NoError(t, err)
+	for _, usage := range tracker.allTenants() {
+		count, found, err := readCounter(ctx, delegate.SnapshotReader(revision), usage)
+		require.NoError(t, err)
+		require.True(t, found)
+
+		counted, err := countRelationships(ctx, delegate.SnapshotReader(revision), usage)
+		require.NoError(t, err)
+		require.Equal(t, counted, count)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewUsageHandler(tracker).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, UsagePath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp struct {
+		Tenants []TenantUsage `json:"tenants"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, usages, resp.Tenants)
+}
@@ -37,7 +37,9 @@ type reporter struct{}
 
 func (r *reporter) ServerReporter(ctx context.Context, callMeta interceptors.CallMeta) (interceptors.Reporter, context.Context) {
 	_, methodName := grpcutil.SplitMethodName(callMeta.FullMethod())
-	ctx = ContextWithHandle(ctx)
+	if ctx.Value(metadataCtxKey) == nil {
+		ctx = ContextWithHandle(ctx)
+	}
 	return &serverReporter{ctx: ctx, methodName: methodName}, ctx
 }
 
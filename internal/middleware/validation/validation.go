@@ -0,0 +1,31 @@
+// Package validation implements the validation of incoming API requests, shared by every service
+// so that no method can skip it.
+package validation
+
+import (
+	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/middleware"
+	"github.com/authzed/spicedb/internal/middleware/handwrittenvalidation"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that validates the incoming
+// request if it implements the protoc-gen-validate interface, followed by its handwritten
+// validation, if any.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return middleware.ChainUnaryServer(
+		grpcvalidate.UnaryServerInterceptor(),
+		handwrittenvalidation.UnaryServerInterceptor,
+	)
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that validates each incoming
+// request message if it implements the protoc-gen-validate interface, followed by its handwritten
+// validation, if any.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return middleware.ChainStreamServer(
+		grpcvalidate.StreamServerInterceptor(),
+		handwrittenvalidation.StreamServerInterceptor,
+	)
+}
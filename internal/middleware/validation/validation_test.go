@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var validRequest = &v1.CheckPermissionRequest{
+	Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "masterplan"},
+	Permission: "view",
+	Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+}
+
+var invalidRequest = &v1.CheckPermissionRequest{
+	Resource:   &v1.ObjectReference{ObjectType: "Document!", ObjectId: "masterplan"},
+	Permission: "view",
+	Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	handler := func(ctx context.Context, req any) (any, error) { return "handled", nil }
+
+	resp, err := interceptor(context.Background(), validRequest, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	require.Equal(t, "handled", resp)
+
+	_, err = interceptor(context.Background(), invalidRequest, &grpc.UnaryServerInfo{}, handler)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	received proto.Message
+}
+
+func (s *fakeServerStream) Context() context.Context { return context.Background() }
+
+func (s *fakeServerStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.received)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor()
+	handler := func(srv any, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&v1.CheckPermissionRequest{})
+	}
+
+	err := interceptor(nil, &fakeServerStream{received: validRequest}, &grpc.StreamServerInfo{}, handler)
+	require.NoError(t, err)
+
+	err = interceptor(nil, &fakeServerStream{received: invalidRequest}, &grpc.StreamServerInfo{}, handler)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
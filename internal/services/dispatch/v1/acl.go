@@ -5,17 +5,13 @@ import (
 	"errors"
 	"time"
 
-	"github.com/authzed/spicedb/internal/middleware/streamtimeout"
-
-	"github.com/authzed/spicedb/internal/middleware"
-
-	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/graph"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/streamtimeout"
 	"github.com/authzed/spicedb/internal/services/shared"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
@@ -24,7 +20,7 @@ const streamAPITimeout = 45 * time.Second
 
 type dispatchServer struct {
 	dispatchv1.UnimplementedDispatchServiceServer
-	shared.WithServiceSpecificInterceptors
+	shared.WithStreamServiceSpecificInterceptor
 
 	localDispatch dispatch.Dispatcher
 }
@@ -33,12 +29,8 @@ type dispatchServer struct {
 func NewDispatchServer(localDispatch dispatch.Dispatcher) dispatchv1.DispatchServiceServer {
 	return &dispatchServer{
 		localDispatch: localDispatch,
-		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
-			Unary: grpcvalidate.UnaryServerInterceptor(),
-			Stream: middleware.ChainStreamServer(
-				grpcvalidate.StreamServerInterceptor(),
-				streamtimeout.MustStreamServerInterceptor(streamAPITimeout),
-			),
+		WithStreamServiceSpecificInterceptor: shared.WithStreamServiceSpecificInterceptor{
+			Stream: streamtimeout.MustStreamServerInterceptor(streamAPITimeout),
 		},
 	}
 }
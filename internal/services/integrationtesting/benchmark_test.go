@@ -196,7 +196,7 @@ func BenchmarkServices(b *testing.B) {
 					dsCtx := datastoremw.ContextWithHandle(context.Background())
 					brequire.NoError(datastoremw.SetInContext(dsCtx, ds))
 
-					testers := consistencytestutil.ServiceTesters(conn[0])
+					testers := consistencytestutil.ServiceTesters(conn[0], ds)
 
 					for _, tester := range testers {
 						b.Run(tester.Name(), func(b *testing.B) {
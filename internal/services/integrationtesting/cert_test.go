@@ -28,6 +28,7 @@ import (
 	"github.com/authzed/spicedb/internal/middleware/consistency"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/middleware/servicespecific"
+	"github.com/authzed/spicedb/internal/middleware/validation"
 	tf "github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/util"
@@ -140,6 +141,10 @@ func TestCertRotation(t *testing.T) {
 			{
 				Operation: server.OperationReplaceAllUnsafe,
 				Middlewares: []server.ReferenceableMiddleware[grpc.UnaryServerInterceptor]{
+					{
+						Name:       "validation",
+						Middleware: validation.UnaryServerInterceptor(),
+					},
 					{
 						Name:       "datastore",
 						Middleware: datastoremw.UnaryServerInterceptor(ds),
@@ -159,6 +164,10 @@ func TestCertRotation(t *testing.T) {
 			{
 				Operation: server.OperationReplaceAllUnsafe,
 				Middlewares: []server.ReferenceableMiddleware[grpc.StreamServerInterceptor]{
+					{
+						Name:       "validation",
+						Middleware: validation.StreamServerInterceptor(),
+					},
 					{
 						Name:       "datastore",
 						Middleware: datastoremw.StreamServerInterceptor(ds),
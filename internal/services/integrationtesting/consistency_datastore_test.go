@@ -53,7 +53,7 @@ func TestConsistencyPerDatastore(t *testing.T) {
 					require.NoError(t, err)
 
 					// Run the assertions within each file.
-					testers := consistencytestutil.ServiceTesters(cad.Conn)
+					testers := consistencytestutil.ServiceTesters(cad.Conn, cad.DataStore)
 					for _, tester := range testers {
 						tester := tester
 
@@ -90,7 +90,7 @@ func runConsistencyTestSuiteForFile(t *testing.T, filePath string, useCachingDis
 	}
 
 	// Run consistency tests.
-	testers := consistencytestutil.ServiceTesters(cad.Conn)
+	testers := consistencytestutil.ServiceTesters(cad.Conn, cad.DataStore)
 	for _, tester := range testers {
 		tester := tester
 
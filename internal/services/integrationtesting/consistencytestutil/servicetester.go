@@ -16,9 +16,9 @@ import (
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
-func ServiceTesters(conn *grpc.ClientConn) []ServiceTester {
+func ServiceTesters(conn *grpc.ClientConn, ds datastore.Datastore) []ServiceTester {
 	return []ServiceTester{
-		v1ServiceTester{v1.NewPermissionsServiceClient(conn), v1.NewExperimentalServiceClient(conn)},
+		v1ServiceTester{v1.NewPermissionsServiceClient(conn), v1.NewExperimentalServiceClient(conn), ds},
 	}
 }
 
@@ -47,12 +47,22 @@ func optionalizeRelation(relation string) string {
 type v1ServiceTester struct {
 	permClient v1.PermissionsServiceClient
 	expClient  v1.ExperimentalServiceClient
+	ds         datastore.Datastore
 }
 
 func (v1st v1ServiceTester) Name() string {
 	return "v1"
 }
 
+// mustNewZedToken encodes the revision as a zedtoken bound to the datastore under test.
+func (v1st v1ServiceTester) mustNewZedToken(ctx context.Context, revision datastore.Revision) *v1.ZedToken {
+	encoded, err := zedtoken.NewFromRevisionForDatastore(ctx, revision, v1st.ds)
+	if err != nil {
+		panic(err)
+	}
+	return encoded
+}
+
 func (v1st v1ServiceTester) Check(ctx context.Context, resource *core.ObjectAndRelation, subject *core.ObjectAndRelation, atRevision datastore.Revision, caveatContext map[string]any) (v1.CheckPermissionResponse_Permissionship, error) {
 	var context *structpb.Struct
 	if caveatContext != nil {
@@ -78,7 +88,7 @@ func (v1st v1ServiceTester) Check(ctx context.Context, resource *core.ObjectAndR
 		},
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: v1st.mustNewZedToken(ctx, atRevision),
 			},
 		},
 		Context: context,
@@ -98,7 +108,7 @@ func (v1st v1ServiceTester) Expand(ctx context.Context, resource *core.ObjectAnd
 		Permission: resource.Relation,
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: v1st.mustNewZedToken(ctx, atRevision),
 			},
 		},
 	})
@@ -121,14 +131,14 @@ func (v1st v1ServiceTester) Write(ctx context.Context, relationship *core.Relati
 	return err
 }
 
-func (v1st v1ServiceTester) Read(_ context.Context, namespaceName string, atRevision datastore.Revision) ([]*core.RelationTuple, error) {
+func (v1st v1ServiceTester) Read(ctx context.Context, namespaceName string, atRevision datastore.Revision) ([]*core.RelationTuple, error) {
 	readResp, err := v1st.permClient.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
 		RelationshipFilter: &v1.RelationshipFilter{
 			ResourceType: namespaceName,
 		},
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: v1st.mustNewZedToken(ctx, atRevision),
 			},
 		},
 	})
@@ -153,7 +163,7 @@ func (v1st v1ServiceTester) Read(_ context.Context, namespaceName string, atRevi
 	return tuples, nil
 }
 
-func (v1st v1ServiceTester) LookupResources(_ context.Context, resourceRelation *core.RelationReference, subject *core.ObjectAndRelation, atRevision datastore.Revision, cursor *v1.Cursor, limit uint32) ([]*v1.LookupResourcesResponse, *v1.Cursor, error) {
+func (v1st v1ServiceTester) LookupResources(ctx context.Context, resourceRelation *core.RelationReference, subject *core.ObjectAndRelation, atRevision datastore.Revision, cursor *v1.Cursor, limit uint32) ([]*v1.LookupResourcesResponse, *v1.Cursor, error) {
 	lookupResp, err := v1st.permClient.LookupResources(context.Background(), &v1.LookupResourcesRequest{
 		ResourceObjectType: resourceRelation.Namespace,
 		Permission:         resourceRelation.Relation,
@@ -166,7 +176,7 @@ func (v1st v1ServiceTester) LookupResources(_ context.Context, resourceRelation
 		},
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: v1st.mustNewZedToken(ctx, atRevision),
 			},
 		},
 		OptionalLimit:  limit,
@@ -194,7 +204,7 @@ func (v1st v1ServiceTester) LookupResources(_ context.Context, resourceRelation
 	return found, lastCursor, nil
 }
 
-func (v1st v1ServiceTester) LookupSubjects(_ context.Context, resource *core.ObjectAndRelation, subjectRelation *core.RelationReference, atRevision datastore.Revision, caveatContext map[string]any) (map[string]*v1.LookupSubjectsResponse, error) {
+func (v1st v1ServiceTester) LookupSubjects(ctx context.Context, resource *core.ObjectAndRelation, subjectRelation *core.RelationReference, atRevision datastore.Revision, caveatContext map[string]any) (map[string]*v1.LookupSubjectsResponse, error) {
 	var builtContext *structpb.Struct
 	if caveatContext != nil {
 		built, err := structpb.NewStruct(caveatContext)
@@ -214,7 +224,7 @@ func (v1st v1ServiceTester) LookupSubjects(_ context.Context, resource *core.Obj
 		OptionalSubjectRelation: optionalizeRelation(subjectRelation.Relation),
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: v1st.mustNewZedToken(ctx, atRevision),
 			},
 		},
 		Context: builtContext,
@@ -244,7 +254,7 @@ func (v1st v1ServiceTester) BulkCheck(ctx context.Context, items []*v1.BulkCheck
 		Items: items,
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: v1st.mustNewZedToken(ctx, atRevision),
 			},
 		},
 	})
@@ -260,7 +270,7 @@ func (v1st v1ServiceTester) CheckBulk(ctx context.Context, items []*v1.CheckBulk
 		Items: items,
 		Consistency: &v1.Consistency{
 			Requirement: &v1.Consistency_AtLeastAsFresh{
-				AtLeastAsFresh: zedtoken.MustNewFromRevision(atRevision),
+				AtLeastAsFresh: v1st.mustNewZedToken(ctx, atRevision),
 			},
 		},
 	})
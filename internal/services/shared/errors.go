@@ -10,6 +10,7 @@ import (
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/authzed/spicedb/internal/sharederrors"
 	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/requestid"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
@@ -118,7 +120,55 @@ type ConfigForErrors struct {
 	MaximumAPIDepth uint32
 }
 
+// RewriteError converts the given error into a gRPC status error, with the ID of the request
+// attached as error details.
 func RewriteError(ctx context.Context, err error, config *ConfigForErrors) error {
+	return WithRequestDetails(ctx, rewriteError(ctx, err, config))
+}
+
+// WithRequestDetails attaches the ID of the request being handled to the status of the error as
+// RequestInfo, so that errors reported by clients can be correlated with the server logs. The
+// status of internal and unknown errors also include the ID in their message and the original
+// error as DebugInfo.
+func WithRequestDetails(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	requestID, ok := requestid.FromContext(ctx)
+	if !ok {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.New(codes.Unknown, err.Error())
+	}
+
+	for _, detail := range st.Details() {
+		if _, ok := detail.(*errdetails.RequestInfo); ok {
+			return err
+		}
+	}
+
+	details := []protoadapt.MessageV1{&errdetails.RequestInfo{RequestId: requestID}}
+	if st.Code() == codes.Internal || st.Code() == codes.Unknown {
+		details = append(details, &errdetails.DebugInfo{Detail: err.Error()})
+
+		withID := st.Proto()
+		withID.Message = fmt.Sprintf("%s (request ID: %s)", withID.Message, requestID)
+		st = status.FromProto(withID)
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		log.Ctx(ctx).Warn().Err(detailsErr).Msg("could not attach request details to error")
+		return err
+	}
+	return withDetails.Err()
+}
+
+func rewriteError(ctx context.Context, err error, config *ConfigForErrors) error {
 	// Check if the error can be directly used.
 	if _, ok := status.FromError(err); ok {
 		return err
@@ -159,6 +209,8 @@ func RewriteError(ctx context.Context, err error, config *ConfigForErrors) error
 
 	case errors.As(err, &datastore.ErrReadOnly{}):
 		return ErrServiceReadOnly
+	case errors.As(err, &datastore.ErrNotConnected{}):
+		return status.Errorf(codes.Unavailable, "%s", err)
 	case errors.As(err, &datastore.ErrInvalidRevision{}):
 		return status.Errorf(codes.OutOfRange, "invalid zedtoken: %s", err)
 	case errors.As(err, &datastore.ErrReadOnly{}):
@@ -170,6 +222,8 @@ func RewriteError(ctx context.Context, err error, config *ConfigForErrors) error
 
 	case errors.As(err, &graph.ErrInvalidArgument{}):
 		return status.Errorf(codes.InvalidArgument, "%s", err)
+	case errors.As(err, &graph.ErrInvalidRewrite{}):
+		return status.Errorf(codes.InvalidArgument, "%s", err)
 	case errors.As(err, &graph.ErrRelationMissingTypeInfo{}):
 		return status.Errorf(codes.FailedPrecondition, "failed precondition: %s", err)
 	case errors.As(err, &graph.ErrAlwaysFail{}):
@@ -2,14 +2,20 @@ package shared
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/authzed/authzed-go/pkg/requestmeta"
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
 
@@ -43,3 +49,38 @@ func TestRewriteMaximumDepthExceededErrorForCheck(t *testing.T) {
 	require.ErrorContains(t, errorRewritten, "--explain")
 	grpcutil.RequireStatus(t, codes.ResourceExhausted, errorRewritten)
 }
+
+func TestRewriteInvalidRewriteError(t *testing.T) {
+	errorRewritten := RewriteError(context.Background(), graph.NewInvalidRewriteErr(errors.New("computed userset for tupleset without tuple")), nil)
+	require.ErrorContains(t, errorRewritten, "invalid userset rewrite")
+	grpcutil.RequireStatus(t, codes.InvalidArgument, errorRewritten)
+}
+
+func TestRewriteErrorWithRequestID(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(string(requestmeta.RequestIDKey), "somerequestid"))
+
+	errorRewritten := RewriteError(ctx, graph.NewInvalidRewriteErr(errors.New("computed userset for tupleset without tuple")), nil)
+	grpcutil.RequireStatus(t, codes.InvalidArgument, errorRewritten)
+
+	st, _ := status.FromError(errorRewritten)
+	require.NotContains(t, st.Message(), "somerequestid")
+	require.Len(t, st.Details(), 1)
+	require.Equal(t, "somerequestid", st.Details()[0].(*errdetails.RequestInfo).RequestId)
+
+	errorRewritten = RewriteError(ctx, errors.New("something unexpected"), nil)
+	grpcutil.RequireStatus(t, codes.Unknown, errorRewritten)
+
+	st, _ = status.FromError(errorRewritten)
+	require.Equal(t, "something unexpected (request ID: somerequestid)", st.Message())
+	require.Len(t, st.Details(), 2)
+	require.Equal(t, "somerequestid", st.Details()[0].(*errdetails.RequestInfo).RequestId)
+	require.Equal(t, "something unexpected", st.Details()[1].(*errdetails.DebugInfo).Detail)
+
+	// Details are only attached once.
+	require.Equal(t, errorRewritten, WithRequestDetails(ctx, errorRewritten))
+}
+
+func TestRewriteErrorWithoutRequestID(t *testing.T) {
+	err := errors.New("something unexpected")
+	require.Equal(t, err, RewriteError(context.Background(), err, nil))
+}
@@ -49,4 +49,5 @@ var (
 	_ servicespecific.ExtraUnaryInterceptor  = WithUnaryServiceSpecificInterceptor{}
 	_ servicespecific.ExtraUnaryInterceptor  = WithServiceSpecificInterceptors{}
 	_ servicespecific.ExtraStreamInterceptor = WithServiceSpecificInterceptors{}
+	_ servicespecific.ExtraStreamInterceptor = WithStreamServiceSpecificInterceptor{}
 )
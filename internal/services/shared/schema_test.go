@@ -11,6 +11,7 @@ import (
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/typesystem"
 )
 
 func TestApplySchemaChanges(t *testing.T) {
@@ -72,3 +73,49 @@ func TestApplySchemaChanges(t *testing.T) {
 	})
 	require.NoError(err)
 }
+
+func TestValidateSchemaChangesArrowTargets(t *testing.T) {
+	require := require.New(t)
+
+	compile := func(schema string) *compiler.CompiledSchema {
+		compiled, err := compiler.Compile(compiler.InputSchema{
+			Source:       input.Source("schema"),
+			SchemaString: schema,
+		}, compiler.AllowUnprefixedObjectType())
+		require.NoError(err)
+		return compiled
+	}
+
+	// Schemas with an arrow which can never be satisfied are rejected before anything is read
+	// from the datastore, so existing definitions are not exempt.
+	_, err := ValidateSchemaChanges(context.Background(), compile(`
+		definition user {}
+
+		definition document {
+			relation parent: user
+			relation viewer: user
+			permission view = parent->view + viewer
+		}
+	`), false)
+	require.ErrorContains(err, "for arrow `parent->view` under permission `view`")
+
+	var arrowErr typesystem.ErrArrowTargetNotFound
+	require.ErrorAs(err, &arrowErr)
+
+	// A new definition with such an arrow is rejected.
+	_, err = ValidateSchemaChanges(context.Background(), compile(`
+		definition user {}
+
+		definition document {
+			relation parent: user
+			relation viewer: user
+			permission view = viewer
+		}
+
+		definition folder {
+			relation parent: user
+			permission view = parent->view
+		}
+	`), false)
+	require.ErrorContains(err, "for arrow `parent->view` under permission `view`")
+}
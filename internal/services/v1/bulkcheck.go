@@ -27,7 +27,7 @@ import (
 // bulkChecker contains the logic to allow ExperimentalService/BulkCheckPermission and
 // PermissionsService/CheckBulkPermissions to share the same implementation.
 type bulkChecker struct {
-	maxAPIDepth          uint32
+	limits               *RuntimeLimitsHolder
 	maxCaveatContextSize int
 	maxConcurrency       uint16
 
@@ -35,6 +35,7 @@ type bulkChecker struct {
 }
 
 func (bc *bulkChecker) checkBulkPermissions(ctx context.Context, req *v1.CheckBulkPermissionsRequest) (*v1.CheckBulkPermissionsResponse, error) {
+	maximumAPIDepth := bc.limits.Load().MaximumAPIDepth
 	atRevision, checkedAt, err := consistency.RevisionFromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -65,7 +66,7 @@ func (bc *bulkChecker) checkBulkPermissions(ctx context.Context, req *v1.CheckBu
 	groupedItems, err := groupItems(ctx, groupingParameters{
 		atRevision:           atRevision,
 		maxCaveatContextSize: bc.maxCaveatContextSize,
-		maximumAPIDepth:      bc.maxAPIDepth,
+		maximumAPIDepth:      maximumAPIDepth,
 	}, req.Items)
 	if err != nil {
 		return nil, err
@@ -105,7 +106,7 @@ func (bc *bulkChecker) checkBulkPermissions(ctx context.Context, req *v1.CheckBu
 
 	appendResultsForError := func(params *computed.CheckParameters, resourceIDs []string, err error) error {
 		rewritten := shared.RewriteError(ctx, err, &shared.ConfigForErrors{
-			MaximumAPIDepth: bc.maxAPIDepth,
+			MaximumAPIDepth: maximumAPIDepth,
 		})
 		statusResp, ok := status.FromError(rewritten)
 		if !ok {
@@ -5,12 +5,14 @@ import (
 	"strconv"
 
 	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
@@ -129,7 +131,8 @@ func NewExceedsMaximumPreconditionsErr(preconditionCount uint64, maxCountAllowed
 // ErrPreconditionFailed occurs when the precondition to a write tuple call does not match.
 type ErrPreconditionFailed struct {
 	error
-	precondition *v1.Precondition
+	precondition         *v1.Precondition
+	matchingRelationship *core.RelationTuple
 }
 
 // MarshalZerologObject implements zerolog object marshalling.
@@ -137,8 +140,17 @@ func (err ErrPreconditionFailed) MarshalZerologObject(e *zerolog.Event) {
 	e.Err(err.error).Interface("precondition", err.precondition)
 }
 
-// NewPreconditionFailedErr constructs a new precondition failed error.
-func NewPreconditionFailedErr(precondition *v1.Precondition) error {
+// NewPreconditionFailedErr constructs a new precondition failed error. The matching relationship
+// is the relationship which matched a MUST_NOT_MATCH precondition, if any.
+func NewPreconditionFailedErr(precondition *v1.Precondition, matchingRelationship *core.RelationTuple) error {
+	if matchingRelationship != nil {
+		return ErrPreconditionFailed{
+			error:                fmt.Errorf("unable to satisfy write precondition `%s`: found relationship `%s`", precondition, tuple.StringWithoutCaveat(matchingRelationship)),
+			precondition:         precondition,
+			matchingRelationship: matchingRelationship,
+		}
+	}
+
 	return ErrPreconditionFailed{
 		error:        fmt.Errorf("unable to satisfy write precondition `%s`", precondition),
 		precondition: precondition,
@@ -179,6 +191,17 @@ func (err ErrPreconditionFailed) GRPCStatus() *status.Status {
 		}
 	}
 
+	violation := &errdetails.PreconditionFailure_Violation{
+		Type:        v1.Precondition_Operation_name[int32(err.precondition.Operation)],
+		Description: "no relationship matches the precondition filter",
+	}
+	if err.matchingRelationship != nil {
+		matching := tuple.StringWithoutCaveat(err.matchingRelationship)
+		metadata["precondition_matching_relationship"] = matching
+		violation.Subject = matching
+		violation.Description = "a relationship matches the precondition filter"
+	}
+
 	return spiceerrors.WithCodeAndDetails(
 		err,
 		codes.FailedPrecondition,
@@ -186,6 +209,9 @@ func (err ErrPreconditionFailed) GRPCStatus() *status.Status {
 			v1.ErrorReason_ERROR_REASON_WRITE_OR_DELETE_PRECONDITION_FAILURE,
 			metadata,
 		),
+		&errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{violation},
+		},
 	)
 }
 
@@ -398,6 +424,39 @@ func (err ErrEmptyPrecondition) GRPCStatus() *status.Status {
 	)
 }
 
+// ErrInvalidExpansionMode indicates that an unknown expansion mode was requested.
+type ErrInvalidExpansionMode struct {
+	error
+	expansionMode string
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrInvalidExpansionMode) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("expansionMode", err.expansionMode)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrInvalidExpansionMode) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.InvalidArgument,
+		spiceerrors.ForReason(
+			v1.ErrorReason_ERROR_REASON_UNSPECIFIED,
+			map[string]string{
+				"expansion_mode": err.expansionMode,
+			},
+		),
+	)
+}
+
+// NewInvalidExpansionModeErr constructs a new invalid expansion mode error.
+func NewInvalidExpansionModeErr(expansionMode string) ErrInvalidExpansionMode {
+	return ErrInvalidExpansionMode{
+		error:         fmt.Errorf("unknown expansion mode `%s`: must be `%s` or `%s`", expansionMode, ExpansionModeShallow, ExpansionModeRecursive),
+		expansionMode: expansionMode,
+	}
+}
+
 func defaultIfZero[T comparable](value T, defaultValue T) T {
 	var zero T
 	if value == zero {
@@ -0,0 +1,64 @@
+package v1
+
+import "sync/atomic"
+
+// RuntimeLimits are the limits of the v1 services which can be changed while the server is
+// running, without the restart that would flush its dispatch and namespace caches.
+type RuntimeLimits struct {
+	// MaximumAPIDepth is the default/starting depth remaining for API calls.
+	MaximumAPIDepth uint32
+
+	// MaxUpdatesPerWrite holds the maximum number of updates allowed per
+	// WriteRelationships call.
+	MaxUpdatesPerWrite uint16
+
+	// MaxPreconditionsCount holds the maximum number of preconditions allowed
+	// on a WriteRelationships or DeleteRelationships call.
+	MaxPreconditionsCount uint16
+}
+
+func (rl RuntimeLimits) withDefaults() RuntimeLimits {
+	return RuntimeLimits{
+		MaximumAPIDepth:       defaultIfZero(rl.MaximumAPIDepth, 50),
+		MaxUpdatesPerWrite:    defaultIfZero(rl.MaxUpdatesPerWrite, 1000),
+		MaxPreconditionsCount: defaultIfZero(rl.MaxPreconditionsCount, 1000),
+	}
+}
+
+// RuntimeLimitsHolder holds the current RuntimeLimits of the v1 services. It is safe for
+// concurrent use.
+type RuntimeLimitsHolder struct {
+	current atomic.Pointer[RuntimeLimits]
+}
+
+// NewRuntimeLimitsHolder creates a holder of the given limits. Zero limits are replaced by their
+// defaults.
+func NewRuntimeLimitsHolder(limits RuntimeLimits) *RuntimeLimitsHolder {
+	holder := &RuntimeLimitsHolder{}
+	holder.Store(limits)
+	return holder
+}
+
+// Load returns the current limits.
+func (h *RuntimeLimitsHolder) Load() RuntimeLimits {
+	return *h.current.Load()
+}
+
+// Store replaces the current limits, which apply to the requests received from then on. Zero
+// limits are replaced by their defaults.
+func (h *RuntimeLimitsHolder) Store(limits RuntimeLimits) {
+	withDefaults := limits.withDefaults()
+	h.current.Store(&withDefaults)
+}
+
+func (config PermissionsServerConfig) runtimeLimits() *RuntimeLimitsHolder {
+	if config.RuntimeLimits != nil {
+		return config.RuntimeLimits
+	}
+
+	return NewRuntimeLimitsHolder(RuntimeLimits{
+		MaximumAPIDepth:       config.MaximumAPIDepth,
+		MaxUpdatesPerWrite:    config.MaxUpdatesPerWrite,
+		MaxPreconditionsCount: config.MaxPreconditionsCount,
+	})
+}
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeLimitsHolder(t *testing.T) {
+	holder := NewRuntimeLimitsHolder(RuntimeLimits{MaxUpdatesPerWrite: 10})
+	require.Equal(t, RuntimeLimits{
+		MaximumAPIDepth:       50,
+		MaxUpdatesPerWrite:    10,
+		MaxPreconditionsCount: 1000,
+	}, holder.Load())
+
+	holder.Store(RuntimeLimits{MaximumAPIDepth: 5, MaxPreconditionsCount: 2})
+	require.Equal(t, RuntimeLimits{
+		MaximumAPIDepth:       5,
+		MaxUpdatesPerWrite:    1000,
+		MaxPreconditionsCount: 2,
+	}, holder.Load())
+}
+
+func TestRuntimeLimitsFromConfig(t *testing.T) {
+	config := PermissionsServerConfig{MaximumAPIDepth: 7}
+	require.Equal(t, uint32(7), config.runtimeLimits().Load().MaximumAPIDepth)
+
+	holder := NewRuntimeLimitsHolder(RuntimeLimits{MaximumAPIDepth: 3})
+	config.RuntimeLimits = holder
+	require.Same(t, holder, config.runtimeLimits())
+}
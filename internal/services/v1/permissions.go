@@ -31,7 +31,7 @@ import (
 
 func (ps *permissionServer) rewriteError(ctx context.Context, err error) error {
 	return shared.RewriteError(ctx, err, &shared.ConfigForErrors{
-		MaximumAPIDepth: ps.config.MaximumAPIDepth,
+		MaximumAPIDepth: ps.config.RuntimeLimits.Load().MaximumAPIDepth,
 	})
 }
 
@@ -90,7 +90,7 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 			},
 			CaveatContext: caveatContext,
 			AtRevision:    atRevision,
-			MaximumDepth:  ps.config.MaximumAPIDepth,
+			MaximumDepth:  ps.config.RuntimeLimits.Load().MaximumAPIDepth,
 			DebugOption:   debugOption,
 		},
 		req.Resource.ObjectId,
@@ -179,12 +179,52 @@ func requestItemFromResourceAndParameters(params *computed.CheckParameters, reso
 	return item, nil
 }
 
+// ExpansionModeKey, if specified in a request header of ExpandPermissionTree, selects how the
+// tree is expanded: ExpansionModeShallow, the default, returns the subject sets found as leaves,
+// while ExpansionModeRecursive expands them as well, returning a fully resolved tree.
+const ExpansionModeKey requestmeta.RequestMetadataHeaderKey = "io.spicedb.expansionmode"
+
+const (
+	// ExpansionModeShallow stops the expansion at subject sets.
+	ExpansionModeShallow = "shallow"
+
+	// ExpansionModeRecursive expands subject sets until only subjects remain.
+	ExpansionModeRecursive = "recursive"
+)
+
+// expansionModeFromContext returns the expansion mode requested in the request headers.
+func expansionModeFromContext(ctx context.Context) (dispatch.DispatchExpandRequest_ExpansionMode, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return dispatch.DispatchExpandRequest_SHALLOW, nil
+	}
+
+	values := md.Get(string(ExpansionModeKey))
+	if len(values) == 0 {
+		return dispatch.DispatchExpandRequest_SHALLOW, nil
+	}
+
+	switch values[0] {
+	case ExpansionModeShallow:
+		return dispatch.DispatchExpandRequest_SHALLOW, nil
+	case ExpansionModeRecursive:
+		return dispatch.DispatchExpandRequest_RECURSIVE, nil
+	default:
+		return dispatch.DispatchExpandRequest_SHALLOW, NewInvalidExpansionModeErr(values[0])
+	}
+}
+
 func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.ExpandPermissionTreeRequest) (*v1.ExpandPermissionTreeResponse, error) {
 	atRevision, expandedAt, err := consistency.RevisionFromContext(ctx)
 	if err != nil {
 		return nil, ps.rewriteError(ctx, err)
 	}
 
+	expansionMode, err := expansionModeFromContext(ctx)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
 
 	err = namespace.CheckNamespaceAndRelation(ctx, req.Resource.ObjectType, req.Permission, false, ds)
@@ -192,7 +232,8 @@ func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.Ex
 		return nil, ps.rewriteError(ctx, err)
 	}
 
-	bf, err := dispatch.NewTraversalBloomFilter(uint(ps.config.MaximumAPIDepth))
+	maximumAPIDepth := ps.config.RuntimeLimits.Load().MaximumAPIDepth
+	bf, err := dispatch.NewTraversalBloomFilter(uint(maximumAPIDepth))
 	if err != nil {
 		return nil, err
 	}
@@ -200,7 +241,7 @@ func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.Ex
 	resp, err := ps.dispatch.DispatchExpand(ctx, &dispatch.DispatchExpandRequest{
 		Metadata: &dispatch.ResolverMeta{
 			AtRevision:     atRevision.String(),
-			DepthRemaining: ps.config.MaximumAPIDepth,
+			DepthRemaining: maximumAPIDepth,
 			TraversalBloom: bf,
 		},
 		ResourceAndRelation: &core.ObjectAndRelation{
@@ -208,7 +249,7 @@ func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.Ex
 			ObjectId:  req.Resource.ObjectId,
 			Relation:  req.Permission,
 		},
-		ExpansionMode: dispatch.DispatchExpandRequest_SHALLOW,
+		ExpansionMode: expansionMode,
 	})
 	usagemetrics.SetInContext(ctx, resp.Metadata)
 	if err != nil {
@@ -459,7 +500,8 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 		return nil
 	})
 
-	bf, err := dispatch.NewTraversalBloomFilter(uint(ps.config.MaximumAPIDepth))
+	maximumAPIDepth := ps.config.RuntimeLimits.Load().MaximumAPIDepth
+	bf, err := dispatch.NewTraversalBloomFilter(uint(maximumAPIDepth))
 	if err != nil {
 		return err
 	}
@@ -468,7 +510,7 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 		&dispatch.DispatchLookupResourcesRequest{
 			Metadata: &dispatch.ResolverMeta{
 				AtRevision:     atRevision.String(),
-				DepthRemaining: ps.config.MaximumAPIDepth,
+				DepthRemaining: maximumAPIDepth,
 				TraversalBloom: bf,
 			},
 			ObjectRelation: &core.RelationReference{
@@ -583,7 +625,8 @@ func (ps *permissionServer) LookupSubjects(req *v1.LookupSubjectsRequest, resp v
 		return nil
 	})
 
-	bf, err := dispatch.NewTraversalBloomFilter(uint(ps.config.MaximumAPIDepth))
+	maximumAPIDepth := ps.config.RuntimeLimits.Load().MaximumAPIDepth
+	bf, err := dispatch.NewTraversalBloomFilter(uint(maximumAPIDepth))
 	if err != nil {
 		return err
 	}
@@ -592,7 +635,7 @@ func (ps *permissionServer) LookupSubjects(req *v1.LookupSubjectsRequest, resp v
 		&dispatch.DispatchLookupSubjectsRequest{
 			Metadata: &dispatch.ResolverMeta{
 				AtRevision:     atRevision.String(),
-				DepthRemaining: ps.config.MaximumAPIDepth,
+				DepthRemaining: maximumAPIDepth,
 				TraversalBloom: bf,
 			},
 			ResourceRelation: &core.RelationReference{
@@ -638,6 +638,87 @@ func TestExpand(t *testing.T) {
 	}
 }
 
+func TestExpandRecursive(t *testing.T) {
+	dsInit := func(ds datastore.Datastore, assertions *require.Assertions) (datastore.Datastore, datastore.Revision) {
+		return tf.DatastoreFromSchemaAndTestRelationships(ds, `
+			definition user {}
+
+			definition group {
+				relation member: user | group#member
+			}
+
+			definition document {
+				relation viewer: user | group#member
+			}
+		`, []*core.RelationTuple{
+			tuple.MustParse("document:masterplan#viewer@group:eng#member"),
+			tuple.MustParse("group:eng#member@user:tom"),
+			tuple.MustParse("group:eng#member@group:infra#member"),
+			tuple.MustParse("group:infra#member@user:sarah"),
+		}, assertions)
+	}
+
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, false, dsInit)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	expand := func(expansionMode string) (*v1.ExpandPermissionTreeResponse, error) {
+		ctx := context.Background()
+		if expansionMode != "" {
+			ctx = requestmeta.SetRequestHeaders(ctx, map[requestmeta.RequestMetadataHeaderKey]string{
+				v1svc.ExpansionModeKey: expansionMode,
+			})
+		}
+
+		return client.ExpandPermissionTree(ctx, &v1.ExpandPermissionTreeRequest{
+			Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "masterplan"},
+			Permission: "viewer",
+			Consistency: &v1.Consistency{
+				Requirement: &v1.Consistency_AtLeastAsFresh{
+					AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+				},
+			},
+		})
+	}
+
+	for _, expansionMode := range []string{"", v1svc.ExpansionModeShallow} {
+		expanded, err := expand(expansionMode)
+		require.NoError(err)
+		require.Equal([]string{"group:eng#member"}, leafSubjects(expanded.TreeRoot))
+	}
+
+	expanded, err := expand(v1svc.ExpansionModeRecursive)
+	require.NoError(err)
+	require.Equal([]string{"group:eng#member", "group:infra#member", "user:sarah", "user:tom"}, leafSubjects(expanded.TreeRoot))
+
+	_, err = expand("deep")
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+// leafSubjects returns the distinct subjects found in the leaves of the tree, sorted.
+func leafSubjects(node *v1.PermissionRelationshipTree) []string {
+	found := mapz.NewSet[string]()
+	var collect func(node *v1.PermissionRelationshipTree)
+	collect = func(node *v1.PermissionRelationshipTree) {
+		switch t := node.TreeType.(type) {
+		case *v1.PermissionRelationshipTree_Leaf:
+			for _, subject := range t.Leaf.Subjects {
+				found.Add(tuple.StringSubjectRef(subject))
+			}
+		case *v1.PermissionRelationshipTree_Intermediate:
+			for _, child := range t.Intermediate.Children {
+				collect(child)
+			}
+		}
+	}
+	collect(node)
+
+	subjects := found.AsSlice()
+	slices.Sort(subjects)
+	return subjects
+}
+
 func countLeafs(node *v1.PermissionRelationshipTree) int {
 	switch t := node.TreeType.(type) {
 	case *v1.PermissionRelationshipTree_Leaf:
@@ -40,11 +40,11 @@ func checkPreconditions(
 		switch precond.Operation {
 		case v1.Precondition_OPERATION_MUST_NOT_MATCH:
 			if first != nil {
-				return NewPreconditionFailedErr(precond)
+				return NewPreconditionFailedErr(precond, first)
 			}
 		case v1.Precondition_OPERATION_MUST_MATCH:
 			if first == nil {
-				return NewPreconditionFailedErr(precond)
+				return NewPreconditionFailedErr(precond, nil)
 			}
 		default:
 			return fmt.Errorf("unspecified precondition operation: %s", precond.Operation)
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
-	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
 	"github.com/jzelinskie/stringz"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -16,7 +15,6 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/middleware"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
-	"github.com/authzed/spicedb/internal/middleware/handwrittenvalidation"
 	"github.com/authzed/spicedb/internal/middleware/streamtimeout"
 	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
 	"github.com/authzed/spicedb/internal/namespace"
@@ -73,6 +71,10 @@ type PermissionsServerConfig struct {
 	// MaxCheckBulkConcurrency defines the maximum number of concurrent checks that can be
 	// made in a single CheckBulkPermissions call.
 	MaxCheckBulkConcurrency uint16
+
+	// RuntimeLimits, if set, holds the limits which can be changed while the server is running,
+	// and takes precedence over MaximumAPIDepth, MaxUpdatesPerWrite and MaxPreconditionsCount.
+	RuntimeLimits *RuntimeLimitsHolder
 }
 
 // NewPermissionsServer creates a PermissionsServiceServer instance.
@@ -81,9 +83,7 @@ func NewPermissionsServer(
 	config PermissionsServerConfig,
 ) v1.PermissionsServiceServer {
 	configWithDefaults := PermissionsServerConfig{
-		MaxPreconditionsCount:      defaultIfZero(config.MaxPreconditionsCount, 1000),
-		MaxUpdatesPerWrite:         defaultIfZero(config.MaxUpdatesPerWrite, 1000),
-		MaximumAPIDepth:            defaultIfZero(config.MaximumAPIDepth, 50),
+		RuntimeLimits:              config.runtimeLimits(),
 		StreamingAPITimeout:        defaultIfZero(config.StreamingAPITimeout, 30*time.Second),
 		MaxCaveatContextSize:       defaultIfZero(config.MaxCaveatContextSize, 4096),
 		MaxRelationshipContextSize: defaultIfZero(config.MaxRelationshipContextSize, 25_000),
@@ -95,19 +95,15 @@ func NewPermissionsServer(
 		config:   configWithDefaults,
 		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
 			Unary: middleware.ChainUnaryServer(
-				grpcvalidate.UnaryServerInterceptor(),
-				handwrittenvalidation.UnaryServerInterceptor,
 				usagemetrics.UnaryServerInterceptor(),
 			),
 			Stream: middleware.ChainStreamServer(
-				grpcvalidate.StreamServerInterceptor(),
-				handwrittenvalidation.StreamServerInterceptor,
 				usagemetrics.StreamServerInterceptor(),
 				streamtimeout.MustStreamServerInterceptor(configWithDefaults.StreamingAPITimeout),
 			),
 		},
 		bulkChecker: &bulkChecker{
-			maxAPIDepth:          configWithDefaults.MaximumAPIDepth,
+			limits:               configWithDefaults.RuntimeLimits,
 			maxCaveatContextSize: configWithDefaults.MaxCaveatContextSize,
 			maxConcurrency:       configWithDefaults.MaxCheckBulkConcurrency,
 			dispatch:             dispatch,
@@ -181,14 +177,33 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 		return ps.rewriteError(ctx, fmt.Errorf("error filtering: %w", err))
 	}
 
-	tupleIterator, err := pagination.NewPaginatedIterator(
-		ctx,
-		ds,
-		dsFilter,
-		pageSize,
-		options.ByResource,
-		startCursor,
-	)
+	// Filters constraining only the subject, such as to find all the relationships of a user,
+	// are read starting from the subject, ordered by subject, using the subject indexes.
+	var tupleIterator datastore.RelationshipIterator
+	if isSubjectOnlyFilter(req.RelationshipFilter) {
+		selector := dsFilter.OptionalSubjectsSelectors[0]
+		tupleIterator, err = pagination.NewPaginatedReverseIterator(
+			ctx,
+			ds,
+			datastore.SubjectsFilter{
+				SubjectType:        selector.OptionalSubjectType,
+				OptionalSubjectIds: selector.OptionalSubjectIds,
+				RelationFilter:     selector.RelationFilter,
+			},
+			pageSize,
+			options.BySubject,
+			startCursor,
+		)
+	} else {
+		tupleIterator, err = pagination.NewPaginatedIterator(
+			ctx,
+			ds,
+			dsFilter,
+			pageSize,
+			options.ByResource,
+			startCursor,
+		)
+	}
 	if err != nil {
 		return ps.rewriteError(ctx, err)
 	}
@@ -245,17 +260,18 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 	span := trace.SpanFromContext(ctx)
 	span.AddEvent("validating mutations")
 	// Ensure that the updates and preconditions are not over the configured limits.
-	if len(req.Updates) > int(ps.config.MaxUpdatesPerWrite) {
+	limits := ps.config.RuntimeLimits.Load()
+	if len(req.Updates) > int(limits.MaxUpdatesPerWrite) {
 		return nil, ps.rewriteError(
 			ctx,
-			NewExceedsMaximumUpdatesErr(uint64(len(req.Updates)), uint64(ps.config.MaxUpdatesPerWrite)),
+			NewExceedsMaximumUpdatesErr(uint64(len(req.Updates)), uint64(limits.MaxUpdatesPerWrite)),
 		)
 	}
 
-	if len(req.OptionalPreconditions) > int(ps.config.MaxPreconditionsCount) {
+	if len(req.OptionalPreconditions) > int(limits.MaxPreconditionsCount) {
 		return nil, ps.rewriteError(
 			ctx,
-			NewExceedsMaximumPreconditionsErr(uint64(len(req.OptionalPreconditions)), uint64(ps.config.MaxPreconditionsCount)),
+			NewExceedsMaximumPreconditionsErr(uint64(len(req.OptionalPreconditions)), uint64(limits.MaxPreconditionsCount)),
 		)
 	}
 
@@ -329,16 +345,22 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 		writeUpdateCounter.WithLabelValues(v1.RelationshipUpdate_Operation_name[int32(kind)]).Observe(float64(count))
 	}
 
+	writtenAt, err := zedtoken.NewFromRevisionForDatastore(ctx, revision, ds)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
 	return &v1.WriteRelationshipsResponse{
-		WrittenAt: zedtoken.MustNewFromRevision(revision),
+		WrittenAt: writtenAt,
 	}, nil
 }
 
 func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.DeleteRelationshipsRequest) (*v1.DeleteRelationshipsResponse, error) {
-	if len(req.OptionalPreconditions) > int(ps.config.MaxPreconditionsCount) {
+	maxPreconditionsCount := ps.config.RuntimeLimits.Load().MaxPreconditionsCount
+	if len(req.OptionalPreconditions) > int(maxPreconditionsCount) {
 		return nil, ps.rewriteError(
 			ctx,
-			NewExceedsMaximumPreconditionsErr(uint64(len(req.OptionalPreconditions)), uint64(ps.config.MaxPreconditionsCount)),
+			NewExceedsMaximumPreconditionsErr(uint64(len(req.OptionalPreconditions)), uint64(maxPreconditionsCount)),
 		)
 	}
 
@@ -424,8 +446,13 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 		return nil, ps.rewriteError(ctx, err)
 	}
 
+	deletedAt, err := zedtoken.NewFromRevisionForDatastore(ctx, revision, ds)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
 	return &v1.DeleteRelationshipsResponse{
-		DeletedAt:        zedtoken.MustNewFromRevision(revision),
+		DeletedAt:        deletedAt,
 		DeletionProgress: deletionProgress,
 	}, nil
 }
@@ -450,6 +477,15 @@ func checkFilterComponent(ctx context.Context, objectType, optionalRelation stri
 	return namespace.CheckNamespaceAndRelation(ctx, objectType, relationToTest, allowEllipsis, ds)
 }
 
+// isSubjectOnlyFilter returns whether the filter only constrains the subject of relationships.
+func isSubjectOnlyFilter(filter *v1.RelationshipFilter) bool {
+	return filter.ResourceType == "" &&
+		filter.OptionalResourceId == "" &&
+		filter.OptionalResourceIdPrefix == "" &&
+		filter.OptionalRelation == "" &&
+		filter.OptionalSubjectFilter != nil
+}
+
 func validateRelationshipsFilter(ctx context.Context, filter *v1.RelationshipFilter, ds datastore.Reader) error {
 	// ResourceType is optional, so only check the relation if it is specified.
 	if filter.ResourceType != "" {
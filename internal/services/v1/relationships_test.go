@@ -13,6 +13,7 @@ import (
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -141,6 +142,14 @@ func TestReadRelationships(t *testing.T) {
 				"document:masterplan#parent@folder:plans":    {},
 			},
 		},
+		{
+			"resource ID prefix with underscore",
+			&v1.RelationshipFilter{
+				OptionalResourceIdPrefix: "master_",
+			},
+			codes.OK,
+			map[string]struct{}{},
+		},
 		{
 			"namespace and userset",
 			&v1.RelationshipFilter{
@@ -171,6 +180,51 @@ func TestReadRelationships(t *testing.T) {
 				"document:masterplan#parent@folder:plans": {},
 			},
 		},
+		{
+			"just subject",
+			&v1.RelationshipFilter{
+				OptionalSubjectFilter: &v1.SubjectFilter{
+					SubjectType:       "folder",
+					OptionalSubjectId: "plans",
+				},
+			},
+			codes.OK,
+			map[string]struct{}{
+				"document:masterplan#parent@folder:plans": {},
+				"document:healthplan#parent@folder:plans": {},
+			},
+		},
+		{
+			"just subject type",
+			&v1.RelationshipFilter{
+				OptionalSubjectFilter: &v1.SubjectFilter{
+					SubjectType: "folder",
+				},
+			},
+			codes.OK,
+			map[string]struct{}{
+				"document:companyplan#parent@folder:company":   {},
+				"document:masterplan#parent@folder:strategy":   {},
+				"document:masterplan#parent@folder:plans":      {},
+				"document:healthplan#parent@folder:plans":      {},
+				"folder:strategy#parent@folder:company":        {},
+				"folder:company#viewer@folder:auditors#viewer": {},
+			},
+		},
+		{
+			"just subject with ellipsis relation",
+			&v1.RelationshipFilter{
+				OptionalSubjectFilter: &v1.SubjectFilter{
+					SubjectType:       "user",
+					OptionalSubjectId: "auditor",
+					OptionalRelation:  &v1.SubjectFilter_RelationFilter{},
+				},
+			},
+			codes.OK,
+			map[string]struct{}{
+				"folder:auditors#viewer@user:auditor": {},
+			},
+		},
 		{
 			"bad objectId",
 			&v1.RelationshipFilter{
@@ -456,6 +510,43 @@ func TestWriteRelationships(t *testing.T) {
 	}
 }
 
+func TestWriteRelationshipsPreconditionFailureDetails(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	existing := tuple.MustParse("document:masterplan#owner@user:product_manager")
+	_, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: tuple.MustToRelationship(tuple.MustParse("document:masterplan#viewer@user:tom")),
+		}},
+		OptionalPreconditions: []*v1.Precondition{{
+			Operation: v1.Precondition_OPERATION_MUST_NOT_MATCH,
+			Filter: &v1.RelationshipFilter{
+				ResourceType:       "document",
+				OptionalResourceId: "masterplan",
+				OptionalRelation:   "owner",
+			},
+		}},
+	})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "found relationship `document:masterplan#owner@user:product_manager`")
+	spiceerrors.RequireReason(t, v1.ErrorReason_ERROR_REASON_WRITE_OR_DELETE_PRECONDITION_FAILURE, err,
+		"precondition_operation",
+		"precondition_matching_relationship",
+	)
+
+	withStatus, _ := status.FromError(err)
+	require.Len(withStatus.Details(), 2)
+	failure := withStatus.Details()[1].(*errdetails.PreconditionFailure)
+	require.Len(failure.Violations, 1)
+	require.Equal("OPERATION_MUST_NOT_MATCH", failure.Violations[0].Type)
+	require.Equal(tuple.MustString(existing), failure.Violations[0].Subject)
+}
+
 func TestDeleteRelationshipViaWriteNoop(t *testing.T) {
 	require := require.New(t)
 
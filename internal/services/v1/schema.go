@@ -4,12 +4,10 @@ import (
 	"context"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
-	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	log "github.com/authzed/spicedb/internal/logging"
-	"github.com/authzed/spicedb/internal/middleware"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
 	"github.com/authzed/spicedb/internal/services/shared"
@@ -26,14 +24,8 @@ import (
 func NewSchemaServer(additiveOnly bool) v1.SchemaServiceServer {
 	return &schemaServer{
 		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
-			Unary: middleware.ChainUnaryServer(
-				grpcvalidate.UnaryServerInterceptor(),
-				usagemetrics.UnaryServerInterceptor(),
-			),
-			Stream: middleware.ChainStreamServer(
-				grpcvalidate.StreamServerInterceptor(),
-				usagemetrics.StreamServerInterceptor(),
-			),
+			Unary:  usagemetrics.UnaryServerInterceptor(),
+			Stream: usagemetrics.StreamServerInterceptor(),
 		},
 		additiveOnly: additiveOnly,
 	}
@@ -97,9 +89,14 @@ func (ss *schemaServer) ReadSchema(ctx context.Context, _ *v1.ReadSchemaRequest)
 		DispatchCount: dispatchCount,
 	})
 
+	readAt, err := zedtoken.NewFromRevisionForDatastore(ctx, headRevision, ds)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
 	return &v1.ReadSchemaResponse{
 		SchemaText: schemaText,
-		ReadAt:     zedtoken.MustNewFromRevision(headRevision),
+		ReadAt:     readAt,
 	}, nil
 }
 
@@ -145,7 +142,12 @@ func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaReque
 		return nil, ss.rewriteError(ctx, err)
 	}
 
+	writtenAt, err := zedtoken.NewFromRevisionForDatastore(ctx, revision, ds)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
 	return &v1.WriteSchemaResponse{
-		WrittenAt: zedtoken.MustNewFromRevision(revision),
+		WrittenAt: writtenAt,
 	}, nil
 }
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
-	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -23,7 +22,6 @@ import (
 
 type watchServer struct {
 	v1.UnimplementedWatchServiceServer
-	shared.WithStreamServiceSpecificInterceptor
 
 	heartbeatDuration time.Duration
 }
@@ -31,9 +29,6 @@ type watchServer struct {
 // NewWatchServer creates an instance of the watch server.
 func NewWatchServer(heartbeatDuration time.Duration) v1.WatchServiceServer {
 	s := &watchServer{
-		WithStreamServiceSpecificInterceptor: shared.WithStreamServiceSpecificInterceptor{
-			Stream: grpcvalidate.StreamServerInterceptor(),
-		},
 		heartbeatDuration: heartbeatDuration,
 	}
 	return s
@@ -52,7 +47,7 @@ func (ws *watchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchS
 
 	var afterRevision datastore.Revision
 	if req.OptionalStartCursor != nil && req.OptionalStartCursor.Token != "" {
-		decodedRevision, err := zedtoken.DecodeRevision(req.OptionalStartCursor, ds)
+		decodedRevision, err := zedtoken.DecodeRevisionForDatastore(ctx, req.OptionalStartCursor, ds)
 		if err != nil {
 			return status.Errorf(codes.InvalidArgument, "failed to decode start revision: %s", err)
 		}
@@ -95,9 +90,14 @@ func (ws *watchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchS
 			if ok {
 				filtered := filterUpdates(objectTypes, filters, update.RelationshipChanges)
 				if len(filtered) > 0 {
+					changesThrough, err := zedtoken.NewFromRevisionForDatastore(ctx, update.Revision, ds)
+					if err != nil {
+						return status.Errorf(codes.Internal, "failed to encode revision: %s", err)
+					}
+
 					if err := stream.Send(&v1.WatchResponse{
 						Updates:        filtered,
-						ChangesThrough: zedtoken.MustNewFromRevision(update.Revision),
+						ChangesThrough: changesThrough,
 					}); err != nil {
 						return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
 					}
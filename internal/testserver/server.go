@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/authzed/spicedb/internal/middleware/servicespecific"
+	"github.com/authzed/spicedb/internal/middleware/validation"
 
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
@@ -84,6 +85,10 @@ func NewTestServerWithConfig(require *require.Assertions,
 						Name:       "logging",
 						Middleware: logging.UnaryServerInterceptor(),
 					},
+					{
+						Name:       "validation",
+						Middleware: validation.UnaryServerInterceptor(),
+					},
 					{
 						Name:       "datastore",
 						Middleware: datastoremw.UnaryServerInterceptor(ds),
@@ -107,6 +112,10 @@ func NewTestServerWithConfig(require *require.Assertions,
 						Name:       "logging",
 						Middleware: logging.StreamServerInterceptor(),
 					},
+					{
+						Name:       "validation",
+						Middleware: validation.StreamServerInterceptor(),
+					},
 					{
 						Name:       "datastore",
 						Middleware: datastoremw.StreamServerInterceptor(ds),
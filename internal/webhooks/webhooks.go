@@ -0,0 +1,340 @@
+// Package webhooks implements a dispatcher that delivers the relationship and schema changes
+// found in the datastore Watch stream to HTTP endpoints, for systems that cannot consume the
+// gRPC Watch API.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+const (
+	// SignatureHeader is the header holding the HMAC-SHA256 signature of the timestamp of the
+	// request, a period and the request body, formatted as `sha256=<hex digest>`, when a secret
+	// is configured.
+	SignatureHeader = "X-SpiceDB-Signature"
+
+	// TimestampHeader is the header holding the time at which the request was sent, in seconds
+	// since the Unix epoch. Receivers should reject requests whose timestamp is too old, so that
+	// a captured request cannot be replayed.
+	TimestampHeader = "X-SpiceDB-Timestamp"
+
+	// DefaultBatchSize is the default maximum number of events delivered in a single request.
+	DefaultBatchSize = 100
+
+	// DefaultFlushInterval is the default maximum amount of time events are held before being
+	// delivered.
+	DefaultFlushInterval = 1 * time.Second
+
+	// DefaultMaxRetries is the default number of times a failed delivery is retried.
+	DefaultMaxRetries = 5
+
+	// rewatchDelay is the delay before watching again after the watch was disconnected.
+	rewatchDelay = 1 * time.Second
+
+	// shutdownFlushTimeout is the maximum amount of time spent delivering the pending events
+	// once the dispatcher is stopped.
+	shutdownFlushTimeout = 5 * time.Second
+)
+
+// Config configures the webhook dispatcher.
+type Config struct {
+	// URLs are the endpoints to which every batch of events is POSTed.
+	URLs []string
+
+	// Secret, if set, is used to sign the body of each request.
+	Secret string
+
+	// BatchSize is the maximum number of events delivered in a single request.
+	BatchSize int
+
+	// FlushInterval is the maximum amount of time events are held before being delivered.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a failed delivery is retried before the batch is dropped.
+	MaxRetries uint64
+
+	// HTTPClient is the client used for deliveries.
+	HTTPClient *http.Client
+}
+
+// Event holds the changes made at a single revision.
+type Event struct {
+	// Revision is the ZedToken of the revision at which the changes were made.
+	Revision string `json:"revision"`
+
+	// RelationshipUpdates are the relationships changed at the revision, encoded as
+	// RelationshipUpdate messages of the v1 API.
+	RelationshipUpdates []json.RawMessage `json:"relationshipUpdates,omitempty"`
+
+	// ChangedDefinitions are the names of the definitions and caveats added or changed.
+	ChangedDefinitions []string `json:"changedDefinitions,omitempty"`
+
+	// DeletedDefinitions are the names of the definitions deleted.
+	DeletedDefinitions []string `json:"deletedDefinitions,omitempty"`
+
+	// DeletedCaveats are the names of the caveats deleted.
+	DeletedCaveats []string `json:"deletedCaveats,omitempty"`
+}
+
+// Payload is the body of each request.
+type Payload struct {
+	Events []Event `json:"events"`
+}
+
+// Dispatcher delivers changes until the context is canceled.
+type Dispatcher func(ctx context.Context) error
+
+// DisabledDispatcher is the dispatcher used when no webhooks are configured.
+func DisabledDispatcher(_ context.Context) error {
+	return nil
+}
+
+// NewDispatcher creates a dispatcher delivering the changes made to the datastore after it
+// started. Changes are delivered at most once: batches that still cannot be delivered after
+// the configured retries are dropped.
+func NewDispatcher(ds datastore.Datastore, config Config) (Dispatcher, error) {
+	if len(config.URLs) == 0 {
+		return nil, errors.New("at least one webhook URL must be provided")
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	d := &dispatcher{ds: ds, config: config}
+	return d.run, nil
+}
+
+type dispatcher struct {
+	ds     datastore.Datastore
+	config Config
+}
+
+func (d *dispatcher) run(ctx context.Context) error {
+	afterRevision, err := d.ds.HeadRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start webhook dispatcher: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Strs("urls", d.config.URLs).
+		Int("batch-size", d.config.BatchSize).
+		Stringer("flush-interval", d.config.FlushInterval).
+		Msg("webhook dispatcher started")
+
+	var pending []Event
+	flush := func() {
+		if len(pending) > 0 {
+			d.deliver(ctx, pending)
+			pending = nil
+		}
+	}
+
+	// The events received before the dispatcher was stopped are still delivered, with a fresh
+	// context as the dispatcher's own is canceled.
+	flushOnShutdown := func() {
+		if len(pending) > 0 {
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownFlushTimeout)
+			defer cancel()
+			d.deliver(flushCtx, pending)
+			pending = nil
+		}
+	}
+
+	ticker := time.NewTicker(d.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		watchCtx, cancelWatch := context.WithCancel(ctx)
+		changes, errs := d.ds.Watch(watchCtx, afterRevision, datastore.WatchOptions{
+			Content: datastore.WatchRelationships | datastore.WatchSchema,
+		})
+
+		err := func() error {
+			for {
+				select {
+				case change, ok := <-changes:
+					if !ok {
+						return nil
+					}
+
+					event, err := NewEvent(ctx, d.ds, change)
+					if err != nil {
+						return err
+					}
+					afterRevision = change.Revision
+
+					if event != nil {
+						pending = append(pending, *event)
+						if len(pending) >= d.config.BatchSize {
+							flush()
+						}
+					}
+
+				case <-ticker.C:
+					flush()
+
+				case err := <-errs:
+					return err
+				}
+			}
+		}()
+		cancelWatch()
+
+		switch {
+		case ctx.Err() != nil:
+			flushOnShutdown()
+			return nil
+
+		case errors.As(err, &datastore.ErrWatchDisconnected{}):
+			// The watch fell behind, usually because deliveries are slow; resume from the last
+			// change received.
+			log.Ctx(ctx).Warn().Err(err).Msg("webhook dispatcher watch disconnected; resuming")
+			flush()
+
+			select {
+			case <-time.After(rewatchDelay):
+			case <-ctx.Done():
+				flushOnShutdown()
+				return nil
+			}
+
+		case err != nil:
+			flush()
+			return fmt.Errorf("webhook dispatcher watch failed: %w", err)
+		}
+	}
+}
+
+// NewEvent converts the changes made at a revision into an event, returning nil if there is
+// nothing to deliver.
+func NewEvent(ctx context.Context, ds datastore.Datastore, change *datastore.RevisionChanges) (*Event, error) {
+	event := &Event{
+		DeletedDefinitions: change.DeletedNamespaces,
+		DeletedCaveats:     change.DeletedCaveats,
+	}
+
+	for _, update := range tuple.UpdatesToRelationshipUpdates(change.RelationshipChanges) {
+		encoded, err := protojson.Marshal(update)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode relationship update: %w", err)
+		}
+		event.RelationshipUpdates = append(event.RelationshipUpdates, encoded)
+	}
+
+	for _, definition := range change.ChangedDefinitions {
+		event.ChangedDefinitions = append(event.ChangedDefinitions, definition.GetName())
+	}
+
+	if len(event.RelationshipUpdates) == 0 && len(event.ChangedDefinitions) == 0 &&
+		len(event.DeletedDefinitions) == 0 && len(event.DeletedCaveats) == 0 {
+		return nil, nil
+	}
+
+	revision, err := zedtoken.NewFromRevisionForDatastore(ctx, change.Revision, ds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode revision: %w", err)
+	}
+	event.Revision = revision.Token
+
+	return event, nil
+}
+
+// deliver POSTs the events to every configured URL, retrying failed deliveries.
+func (d *dispatcher) deliver(ctx context.Context, events []Event) {
+	body, err := json.Marshal(Payload{Events: events})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to encode webhook payload")
+		return
+	}
+
+	for _, url := range d.config.URLs {
+		backoffInterval := backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), d.config.MaxRetries), ctx)
+		err := backoff.Retry(func() error {
+			return d.post(ctx, url, body)
+		}, backoffInterval)
+		if err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Str("url", url).
+				Int("events", len(events)).
+				Str("revision", events[len(events)-1].Revision).
+				Msg("failed to deliver webhook; dropping events")
+			continue
+		}
+
+		log.Ctx(ctx).Debug().Str("url", url).Int("events", len(events)).Msg("delivered webhook")
+	}
+}
+
+func (d *dispatcher) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(fmt.Errorf("failed to create webhook request: %w", err))
+	}
+
+	// Every attempt is signed with its own timestamp, so that retries are not rejected as
+	// replays.
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	if d.config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(d.config.Secret, timestamp, body))
+	}
+
+	resp, err := d.config.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("unexpected webhook response: %d: %s", resp.StatusCode, responseBody)
+
+		// Client errors other than throttling will not be resolved by retrying.
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Sign returns the signature of a request sent at the timestamp with the body, as held by the
+// SignatureHeader.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type receiver struct {
+	sync.Mutex
+	payloads []Payload
+	failures atomic.Int32
+}
+
+func (r *receiver) handler(t *testing.T, secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if r.failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		timestamp := req.Header.Get(TimestampHeader)
+		sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), time.Unix(sentAt, 0), time.Minute)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get(SignatureHeader))
+
+		var payload Payload
+		require.NoError(t, json.Unmarshal(body, &payload))
+
+		r.Lock()
+		defer r.Unlock()
+		r.payloads = append(r.payloads, payload)
+	}
+}
+
+func (r *receiver) events() []Event {
+	r.Lock()
+	defer r.Unlock()
+
+	var events []Event
+	for _, payload := range r.payloads {
+		events = append(events, payload.Events...)
+	}
+	return events
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	recv := &receiver{}
+	recv.failures.Store(1)
+	server := httptest.NewServer(recv.handler(t, "somesecret"))
+	defer server.Close()
+
+	dispatcher, err := NewDispatcher(ds, Config{
+		URLs:          []string{server.URL},
+		Secret:        "somesecret",
+		BatchSize:     10,
+		FlushInterval: 10 * time.Millisecond,
+		MaxRetries:    3,
+	})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- dispatcher(ctx)
+	}()
+
+	// Wait for the dispatcher to start watching before making changes.
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, &core.NamespaceDefinition{Name: "document"})
+	})
+	require.NoError(t, err)
+
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, tuple.MustParse("document:readme#viewer@user:tom"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(recv.events()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	events := recv.events()
+	require.Equal(t, []string{"document"}, events[0].ChangedDefinitions)
+	require.NotEmpty(t, events[0].Revision)
+
+	require.Len(t, events[1].RelationshipUpdates, 1)
+	var update v1.RelationshipUpdate
+	require.NoError(t, protojson.Unmarshal(events[1].RelationshipUpdates[0], &update))
+	require.NotEqual(t, v1.RelationshipUpdate_OPERATION_DELETE, update.Operation)
+	require.Equal(t, "document:readme#viewer@user:tom", tuple.MustStringRelationship(update.Relationship))
+	require.NotEqual(t, events[0].Revision, events[1].Revision)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestDispatcherFlushesOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	recv := &receiver{}
+	server := httptest.NewServer(recv.handler(t, "somesecret"))
+	defer server.Close()
+
+	dispatcher, err := NewDispatcher(ds, Config{
+		URLs:          []string{server.URL},
+		Secret:        "somesecret",
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- dispatcher(ctx)
+	}()
+
+	// Wait for the dispatcher to start watching before making changes.
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, &core.NamespaceDefinition{Name: "document"})
+	})
+	require.NoError(t, err)
+
+	// Wait for the dispatcher to receive the change, which is then held until the next flush.
+	time.Sleep(50 * time.Millisecond)
+	require.Empty(t, recv.events())
+
+	cancel()
+	require.NoError(t, <-done)
+
+	events := recv.events()
+	require.Len(t, events, 1)
+	require.Equal(t, []string{"document"}, events[0].ChangedDefinitions)
+}
+
+func TestDispatcherDropsUndeliverableBatches(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	d := &dispatcher{config: Config{
+		URLs:       []string{server.URL},
+		MaxRetries: 3,
+		HTTPClient: server.Client(),
+	}}
+	d.deliver(context.Background(), []Event{{Revision: "sometoken"}})
+
+	// Client errors are not retried.
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestNewDispatcherRequiresURLs(t *testing.T) {
+	_, err := NewDispatcher(nil, Config{})
+	require.Error(t, err)
+}
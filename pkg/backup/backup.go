@@ -0,0 +1,362 @@
+// Package backup implements consistent backups of the schema and relationships of a datastore,
+// and their restoration into another, empty, datastore.
+//
+// A backup is a stream of JSON lines: a header recording the format version and the revision
+// of the backup, followed by the caveat and namespace definitions, and finally by the
+// relationships, each in its canonical JSON encoding.
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// FormatVersion is the version of the backup format written by Backup.
+const FormatVersion = 1
+
+// DefaultBatchSize is the default number of relationships read from the datastore at once.
+const DefaultBatchSize = 1000
+
+// maxLineSize is the maximum size of a single line of a backup.
+const maxLineSize = 16 * 1024 * 1024
+
+// ErrDatastoreNotEmpty is returned by Restore when the target datastore already contains a
+// schema.
+var ErrDatastoreNotEmpty = errors.New("backups can only be restored into an empty datastore")
+
+// Header is the first line of a backup.
+type Header struct {
+	Version  int    `json:"version"`
+	Revision string `json:"revision"`
+}
+
+// entry is a line of a backup following the header. Exactly one of its fields is set.
+type entry struct {
+	Caveat       json.RawMessage `json:"caveat,omitempty"`
+	Namespace    json.RawMessage `json:"namespace,omitempty"`
+	Relationship json.RawMessage `json:"relationship,omitempty"`
+}
+
+// Stats counts the contents of a backup.
+type Stats struct {
+	Caveats       uint64
+	Namespaces    uint64
+	Relationships uint64
+}
+
+// Options configures a backup.
+type Options struct {
+	// BatchSize is the number of relationships read from the datastore at once.
+	BatchSize uint64
+
+	// AtRevision, if set, is the revision to back up instead of the head revision. It must not
+	// have been garbage collected, which allows recovering the schema and relationships as of
+	// any revision within the garbage collection window, e.g. after an accidental deletion.
+	AtRevision datastore.Revision
+
+	// OnProgress, if set, is called after each batch of relationships with the number of
+	// relationships written so far.
+	OnProgress func(relationships uint64)
+}
+
+// Backup writes all the caveats, namespaces and relationships of the datastore, as of its head
+// revision or of the revision of the options, to w. It returns the revision of the backup.
+func Backup(ctx context.Context, ds datastore.Datastore, w io.Writer, opts Options) (datastore.Revision, Stats, error) {
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	revision, err := backupRevision(ctx, ds, opts.AtRevision)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+	reader := ds.SnapshotReader(revision)
+
+	var stats Stats
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	if err := encoder.Encode(Header{Version: FormatVersion, Revision: revision.String()}); err != nil {
+		return nil, stats, err
+	}
+
+	caveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, stats, fmt.Errorf("failed to read caveats: %w", err)
+	}
+	for _, caveat := range caveats {
+		encoded, err := protojson.Marshal(caveat.Definition)
+		if err != nil {
+			return nil, stats, err
+		}
+		if err := encoder.Encode(entry{Caveat: encoded}); err != nil {
+			return nil, stats, err
+		}
+		stats.Caveats++
+	}
+
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, stats, fmt.Errorf("failed to read namespaces: %w", err)
+	}
+	for _, namespace := range namespaces {
+		encoded, err := protojson.Marshal(namespace.Definition)
+		if err != nil {
+			return nil, stats, err
+		}
+		if err := encoder.Encode(entry{Namespace: encoded}); err != nil {
+			return nil, stats, err
+		}
+		stats.Namespaces++
+	}
+
+	for _, namespace := range namespaces {
+		var cursor options.Cursor
+		for {
+			count, nextCursor, err := backupRelationships(ctx, reader, namespace.Definition.Name, cursor, opts.BatchSize, encoder)
+			stats.Relationships += count
+			if err != nil {
+				return nil, stats, err
+			}
+
+			if opts.OnProgress != nil && count > 0 {
+				opts.OnProgress(stats.Relationships)
+			}
+			if count < opts.BatchSize {
+				break
+			}
+			cursor = nextCursor
+		}
+	}
+
+	return revision, stats, buffered.Flush()
+}
+
+func backupRevision(ctx context.Context, ds datastore.Datastore, atRevision datastore.Revision) (datastore.Revision, error) {
+	if atRevision == nil {
+		revision, err := ds.HeadRevision(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read head revision: %w", err)
+		}
+		return revision, nil
+	}
+
+	if err := ds.CheckRevision(ctx, atRevision); err != nil {
+		return nil, fmt.Errorf("cannot back up revision %s: %w", atRevision, err)
+	}
+	return atRevision, nil
+}
+
+func backupRelationships(ctx context.Context, reader datastore.Reader, namespace string, cursor options.Cursor, limit uint64, encoder *json.Encoder) (uint64, options.Cursor, error) {
+	iter, err := reader.QueryRelationships(ctx,
+		datastore.RelationshipsFilter{OptionalResourceType: namespace},
+		options.WithLimit(&limit),
+		options.WithAfter(cursor),
+		options.WithSort(options.ByResource),
+	)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read relationships: %w", err)
+	}
+	defer iter.Close()
+
+	var count uint64
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		encoded, err := tuple.MarshalJSON(tpl)
+		if err != nil {
+			return count, nil, err
+		}
+		if err := encoder.Encode(entry{Relationship: encoded}); err != nil {
+			return count, nil, err
+		}
+		count++
+	}
+	if iter.Err() != nil {
+		return count, nil, fmt.Errorf("failed to read relationships: %w", iter.Err())
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+
+	nextCursor, err := iter.Cursor()
+	if err != nil {
+		return count, nil, err
+	}
+	return count, nextCursor, nil
+}
+
+// Restore loads the backup read from r into the datastore, which must not contain a schema, in
+// a single transaction. The restored data is assigned the revision of that transaction, which
+// is returned along with the header of the backup.
+func Restore(ctx context.Context, ds datastore.Datastore, r io.Reader) (datastore.Revision, *Header, Stats, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	header, err := readHeader(scanner)
+	if err != nil {
+		return nil, nil, Stats{}, err
+	}
+
+	var stats Stats
+	revision, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		// Reset the counts, in case the transaction is retried.
+		stats = Stats{}
+
+		if err := requireEmpty(ctx, rwt); err != nil {
+			return err
+		}
+
+		source := &relationshipSource{scanner: scanner}
+		if err := restoreDefinitions(ctx, rwt, source, &stats); err != nil {
+			return err
+		}
+
+		loaded, err := rwt.BulkLoad(ctx, source)
+		stats.Relationships = loaded
+		if err != nil {
+			return err
+		}
+		return source.err
+	}, options.WithDisableRetries(true))
+	if err != nil {
+		return nil, header, stats, err
+	}
+
+	return revision, header, stats, nil
+}
+
+func readHeader(scanner *bufio.Scanner) (*Header, error) {
+	if !scanner.Scan() {
+		if scanner.Err() != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", scanner.Err())
+		}
+		return nil, errors.New("backup is empty")
+	}
+
+	var header Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, fmt.Errorf("invalid backup header: %w", err)
+	}
+	if header.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d", header.Version)
+	}
+	return &header, nil
+}
+
+func requireEmpty(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+	namespaces, err := rwt.ListAllNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	caveats, err := rwt.ListAllCaveats(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(namespaces) > 0 || len(caveats) > 0 {
+		return ErrDatastoreNotEmpty
+	}
+	return nil
+}
+
+// restoreDefinitions writes the caveats and namespaces found before the first relationship of
+// the backup, which is left pending in the source.
+func restoreDefinitions(ctx context.Context, rwt datastore.ReadWriteTransaction, source *relationshipSource, stats *Stats) error {
+	var caveats []*core.CaveatDefinition
+	var namespaces []*core.NamespaceDefinition
+
+	for source.scanner.Scan() {
+		var line entry
+		if err := json.Unmarshal(source.scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("invalid backup entry: %w", err)
+		}
+
+		if line.Relationship != nil {
+			source.pending = line.Relationship
+			break
+		}
+
+		switch {
+		case line.Caveat != nil:
+			caveat := &core.CaveatDefinition{}
+			if err := protojson.Unmarshal(line.Caveat, caveat); err != nil {
+				return fmt.Errorf("invalid caveat in backup: %w", err)
+			}
+			caveats = append(caveats, caveat)
+
+		case line.Namespace != nil:
+			namespace := &core.NamespaceDefinition{}
+			if err := protojson.Unmarshal(line.Namespace, namespace); err != nil {
+				return fmt.Errorf("invalid namespace in backup: %w", err)
+			}
+			namespaces = append(namespaces, namespace)
+
+		default:
+			return errors.New("invalid backup entry: no caveat, namespace or relationship")
+		}
+	}
+	if source.scanner.Err() != nil {
+		return fmt.Errorf("failed to read backup: %w", source.scanner.Err())
+	}
+
+	if err := rwt.WriteCaveats(ctx, caveats); err != nil {
+		return fmt.Errorf("failed to restore caveats: %w", err)
+	}
+	if err := rwt.WriteNamespaces(ctx, namespaces...); err != nil {
+		return fmt.Errorf("failed to restore namespaces: %w", err)
+	}
+
+	stats.Caveats = uint64(len(caveats))
+	stats.Namespaces = uint64(len(namespaces))
+	return nil
+}
+
+// relationshipSource reads the relationships of a backup for bulk loading.
+type relationshipSource struct {
+	scanner *bufio.Scanner
+	pending json.RawMessage
+	err     error
+}
+
+func (rs *relationshipSource) Next(_ context.Context) (*core.RelationTuple, error) {
+	encoded := rs.pending
+	rs.pending = nil
+
+	if encoded == nil {
+		if !rs.scanner.Scan() {
+			if rs.scanner.Err() != nil {
+				rs.err = fmt.Errorf("failed to read backup: %w", rs.scanner.Err())
+				return nil, rs.err
+			}
+			return nil, nil
+		}
+
+		var line entry
+		if err := json.Unmarshal(rs.scanner.Bytes(), &line); err != nil {
+			rs.err = fmt.Errorf("invalid backup entry: %w", err)
+			return nil, rs.err
+		}
+		if line.Relationship == nil {
+			rs.err = errors.New("invalid backup: definitions must precede relationships")
+			return nil, rs.err
+		}
+		encoded = line.Relationship
+	}
+
+	tpl, err := tuple.UnmarshalJSON(encoded)
+	if err != nil {
+		rs.err = fmt.Errorf("invalid relationship in backup: %w", err)
+		return nil, rs.err
+	}
+	return tpl, nil
+}
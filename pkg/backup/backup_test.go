@@ -0,0 +1,189 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func readAllRelationships(t *testing.T, ds datastore.Datastore, revision datastore.Revision) []string {
+	ctx := context.Background()
+	reader := ds.SnapshotReader(revision)
+
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	require.NoError(t, err)
+
+	var relationships []string
+	for _, namespace := range namespaces {
+		iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: namespace.Definition.Name})
+		require.NoError(t, err)
+		for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+			relationships = append(relationships, tuple.MustString(tpl))
+		}
+		require.NoError(t, iter.Err())
+		iter.Close()
+	}
+
+	sort.Strings(relationships)
+	return relationships
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	for _, batchSize := range []uint64{0, 1, 3} {
+		batchSize := batchSize
+		t.Run(fmt.Sprintf("batch size %d", batchSize), func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+			require.NoError(err)
+			sourceDS, sourceRevision := testfixtures.StandardDatastoreWithCaveatedData(rawDS, require)
+
+			var progress []uint64
+			buf := &bytes.Buffer{}
+			backupRevision, stats, err := Backup(ctx, sourceDS, buf, Options{
+				BatchSize:  batchSize,
+				OnProgress: func(relationships uint64) { progress = append(progress, relationships) },
+			})
+			require.NoError(err)
+			require.True(backupRevision.Equal(sourceRevision))
+			require.Equal(uint64(1), stats.Caveats)
+			require.NotEmpty(progress)
+			require.Equal(stats.Relationships, progress[len(progress)-1])
+
+			expected := readAllRelationships(t, sourceDS, sourceRevision)
+			require.Equal(uint64(len(expected)), stats.Relationships)
+
+			targetDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+			require.NoError(err)
+
+			restoredRevision, header, restoreStats, err := Restore(ctx, targetDS, buf)
+			require.NoError(err)
+			require.Equal(FormatVersion, header.Version)
+			require.Equal(sourceRevision.String(), header.Revision)
+			require.Equal(stats, restoreStats)
+			require.Equal(expected, readAllRelationships(t, targetDS, restoredRevision))
+
+			reader := targetDS.SnapshotReader(restoredRevision)
+			caveats, err := reader.ListAllCaveats(ctx)
+			require.NoError(err)
+			require.Len(caveats, 1)
+			require.Equal(testfixtures.CaveatDef.Name, caveats[0].Definition.Name)
+
+			namespaces, err := reader.ListAllNamespaces(ctx)
+			require.NoError(err)
+			require.Len(namespaces, int(stats.Namespaces))
+		})
+	}
+}
+
+func TestRestoreIntoNonEmptyDatastore(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	ds, _ := testfixtures.StandardDatastoreWithData(rawDS, require)
+
+	buf := &bytes.Buffer{}
+	_, _, err = Backup(ctx, ds, buf, Options{})
+	require.NoError(err)
+
+	_, _, _, err = Restore(ctx, ds, buf)
+	require.ErrorIs(err, ErrDatastoreNotEmpty)
+}
+
+func TestRestoreInvalidBackup(t *testing.T) {
+	tcs := []struct {
+		name          string
+		contents      string
+		expectedError string
+	}{
+		{"empty", "", "backup is empty"},
+		{"invalid header", "not json\n", "invalid backup header"},
+		{"unsupported version", `{"version":2,"revision":"1"}` + "\n", "unsupported backup format version 2"},
+		{
+			"definition after relationships",
+			`{"version":1,"revision":"1"}
+{"namespace":{"name":"user"}}
+{"namespace":{"name":"document","relation":[{"name":"viewer","typeInformation":{"allowedDirectRelations":[{"namespace":"user","relation":"..."}]}}]}}
+{"relationship":{"resourceAndRelation":{"namespace":"document","objectId":"doc","relation":"viewer"},"subject":{"namespace":"user","objectId":"tom","relation":"..."}}}
+{"namespace":{"name":"folder"}}
+`,
+			"definitions must precede relationships",
+		},
+		{
+			"empty entry",
+			`{"version":1,"revision":"1"}
+{}
+`,
+			"no caveat, namespace or relationship",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+			require.NoError(t, err)
+
+			_, _, _, err = Restore(context.Background(), ds, strings.NewReader(tc.contents))
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestBackupAtPastRevision(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	sourceDS, beforeDeletion := testfixtures.StandardDatastoreWithData(rawDS, require)
+	expected := readAllRelationships(t, sourceDS, beforeDeletion)
+
+	// Accidentally delete all the relationships of documents.
+	afterDeletion, err := sourceDS.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: testfixtures.DocumentNS.Name})
+		return err
+	})
+	require.NoError(err)
+	require.Less(len(readAllRelationships(t, sourceDS, afterDeletion)), len(expected))
+
+	buf := &bytes.Buffer{}
+	revision, _, err := Backup(ctx, sourceDS, buf, Options{AtRevision: beforeDeletion})
+	require.NoError(err)
+	require.True(revision.Equal(beforeDeletion))
+
+	targetDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+
+	restoredRevision, _, _, err := Restore(ctx, targetDS, buf)
+	require.NoError(err)
+	require.Equal(expected, readAllRelationships(t, targetDS, restoredRevision))
+}
+
+func TestBackupAtGarbageCollectedRevision(t *testing.T) {
+	require := require.New(t)
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, time.Hour)
+	require.NoError(err)
+
+	staleRevision, err := ds.RevisionFromString("1")
+	require.NoError(err)
+
+	_, _, err = Backup(context.Background(), ds, &bytes.Buffer{}, Options{AtRevision: staleRevision})
+	require.ErrorContains(err, "cannot back up revision 1")
+}
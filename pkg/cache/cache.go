@@ -56,6 +56,9 @@ type Cache interface {
 	// Wait waits for the cache to process and apply updates.
 	Wait()
 
+	// Clear removes all the entries of the cache.
+	Clear()
+
 	// Close closes the cache's background workers (if any).
 	Close()
 
@@ -90,6 +93,7 @@ var _ Cache = (*noopCache)(nil)
 func (no *noopCache) Get(_ any) (any, bool)      { return nil, false }
 func (no *noopCache) Set(_, _ any, _ int64) bool { return false }
 func (no *noopCache) Wait()                      {}
+func (no *noopCache) Clear()                     {}
 func (no *noopCache) Close()                     {}
 func (no *noopCache) GetMetrics() Metrics        { return &noopMetrics{} }
 func (no *noopCache) MarshalZerologObject(e *zerolog.Event) {
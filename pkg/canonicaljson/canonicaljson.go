@@ -0,0 +1,65 @@
+// Package canonicaljson encodes protobuf messages as canonical JSON: equal messages always
+// produce identical bytes.
+//
+// The encoding is the protobuf JSON mapping of the message, as produced by protojson: fields use
+// their lowerCamelCase JSON names, enums are encoded by name, 64-bit integers as strings and unset
+// fields are omitted. The object keys at every level are then sorted in byte order, no
+// insignificant whitespace is emitted and HTML characters are not escaped.
+//
+// Decoding accepts any valid protobuf JSON encoding of the message, in any key order, but rejects
+// unknown fields.
+package canonicaljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Validatable is a message which can validate its own contents.
+type Validatable interface {
+	proto.Message
+	Validate() error
+}
+
+// Marshal returns the canonical JSON encoding of the message.
+func Marshal(msg proto.Message) ([]byte, error) {
+	encoded, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	// protojson neither sorts the keys of maps and structs nor guarantees stable whitespace, so
+	// the output is decoded and encoded again with encoding/json, which sorts object keys. Numbers
+	// are kept as written to avoid any loss of precision.
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	var canonical bytes.Buffer
+	encoder := json.NewEncoder(&canonical)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, fmt.Errorf("error encoding JSON: %w", err)
+	}
+	return bytes.TrimSuffix(canonical.Bytes(), []byte("\n")), nil
+}
+
+// Unmarshal decodes the message from its JSON encoding and validates it.
+func Unmarshal(data []byte, msg Validatable) error {
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("error decoding JSON: %w", err)
+	}
+
+	if err := msg.Validate(); err != nil {
+		return fmt.Errorf("invalid %s: %w", msg.ProtoReflect().Descriptor().Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package canonicaljson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/testutil"
+)
+
+func TestMarshalSortsKeys(t *testing.T) {
+	require := require.New(t)
+
+	context, err := structpb.NewStruct(map[string]any{
+		"zeta":  "<last>",
+		"alpha": 1.5,
+		"mu":    map[string]any{"b": true, "a": nil},
+	})
+	require.NoError(err)
+
+	tpl := &core.RelationTuple{
+		ResourceAndRelation: &core.ObjectAndRelation{Namespace: "document", ObjectId: "foo", Relation: "viewer"},
+		Subject:             &core.ObjectAndRelation{Namespace: "user", ObjectId: "tom", Relation: "..."},
+		Caveat:              &core.ContextualizedCaveat{CaveatName: "somecaveat", Context: context},
+	}
+
+	expected := `{"caveat":{"caveatName":"somecaveat","context":{"alpha":1.5,"mu":{"a":null,"b":true},"zeta":"<last>"}},"resourceAndRelation":{"namespace":"document","objectId":"foo","relation":"viewer"},"subject":{"namespace":"user","objectId":"tom","relation":"..."}}`
+	for i := 0; i < 10; i++ {
+		encoded, err := Marshal(tpl)
+		require.NoError(err)
+		require.Equal(expected, string(encoded))
+	}
+
+	decoded := &core.RelationTuple{}
+	require.NoError(Unmarshal([]byte(expected), decoded))
+	testutil.RequireProtoEqual(t, tpl, decoded, "JSON round trip mismatch")
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	require := require.New(t)
+
+	require.ErrorContains(Unmarshal([]byte(`{"unknown":true}`), &core.RelationTuple{}), "error decoding JSON")
+	require.ErrorContains(Unmarshal([]byte(`{}`), &core.RelationTuple{}), "invalid RelationTuple")
+}
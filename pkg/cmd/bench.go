@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/bench"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+// BenchConfig is the configuration for the bench command.
+type BenchConfig struct {
+	// ClientConnectionConfig configures the connection to the SpiceDB instance to benchmark.
+	ClientConnectionConfig
+
+	// SkipDataLoad skips writing the workload, when it was loaded by a previous run.
+	SkipDataLoad bool
+
+	// Shape and Seed determine the generated workload.
+	Shape bench.Shape
+	Seed  int64
+
+	Duration        time.Duration
+	Concurrency     int
+	LookupRatio     float64
+	FullyConsistent bool
+	AtLeastAsFresh  bool
+}
+
+func RegisterBenchFlags(cmd *cobra.Command, config *BenchConfig) {
+	RegisterClientConnectionFlags(cmd.Flags(), &config.ClientConnectionConfig)
+	cmd.Flags().BoolVar(&config.SkipDataLoad, "skip-data-load", false, "do not write the schema and relationships, as they were loaded by a previous run with the same shape and seed")
+
+	cmd.Flags().IntVar(&config.Shape.Documents, "documents", 1000, "number of documents to generate")
+	cmd.Flags().IntVar(&config.Shape.Users, "users", 1000, "number of users to generate")
+	cmd.Flags().IntVar(&config.Shape.GroupsPerLevel, "groups-per-level", 100, "number of groups at each level of group nesting")
+	cmd.Flags().IntVar(&config.Shape.NestingDepth, "nesting-depth", 3, "number of levels of nested groups; 0 disables groups")
+	cmd.Flags().IntVar(&config.Shape.Fanout, "fanout", 10, "number of viewers of each document and of members of each group")
+	cmd.Flags().Int64Var(&config.Seed, "seed", 1, "seed of the generated relationships and of the requests sent")
+
+	cmd.Flags().DurationVar(&config.Duration, "duration", 30*time.Second, "how long to send requests for")
+	cmd.Flags().IntVar(&config.Concurrency, "concurrency", 10, "number of requests in flight at any time")
+	cmd.Flags().Float64Var(&config.LookupRatio, "lookup-ratio", 0.1, "fraction of requests which are LookupResources rather than CheckPermission")
+	cmd.Flags().BoolVar(&config.FullyConsistent, "fully-consistent", false, "send fully consistent requests, bypassing caches")
+	cmd.Flags().BoolVar(&config.AtLeastAsFresh, "at-least-as-fresh", false, "send requests at least as fresh as the loaded relationships, rather than minimizing latency")
+}
+
+func NewBenchCommand(programName string, config *BenchConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "bench",
+		Short: "benchmarks a SpiceDB instance",
+		Long: "Generates a schema and a graph of documents, users and nested groups of the configured shape, loads them into a SpiceDB instance, " +
+			"then sends CheckPermission and LookupResources requests to it and reports their latency percentiles.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			signalctx := SignalContextWithGracePeriod(context.Background(), 0)
+			return config.Run(signalctx, cmd.OutOrStdout())
+		}),
+	}
+}
+
+// Run loads the workload into the endpoint, benchmarks it, and writes the report to out.
+func (c *BenchConfig) Run(ctx context.Context, out io.Writer) error {
+	if c.FullyConsistent && c.AtLeastAsFresh {
+		return fmt.Errorf("cannot specify both --fully-consistent and --at-least-as-fresh")
+	}
+	if c.AtLeastAsFresh && c.SkipDataLoad {
+		return fmt.Errorf("cannot specify both --at-least-as-fresh and --skip-data-load")
+	}
+
+	workload, err := bench.Generate(c.Shape, c.Seed)
+	if err != nil {
+		return err
+	}
+
+	conn, err := c.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := bench.NewClient(conn)
+
+	var consistency *v1.Consistency
+	if c.FullyConsistent {
+		consistency = &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	}
+
+	if !c.SkipDataLoad {
+		log.Ctx(ctx).Info().Int("relationships", len(workload.Relationships)).Msg("loading schema and relationships")
+		writtenAt, err := bench.Load(ctx, client, workload)
+		if err != nil {
+			return err
+		}
+
+		if c.AtLeastAsFresh {
+			consistency = &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: writtenAt}}
+		}
+	}
+
+	log.Ctx(ctx).Info().Stringer("duration", c.Duration).Int("concurrency", c.Concurrency).Msg("sending requests")
+	report, err := bench.Run(ctx, client, workload, bench.LoadConfig{
+		Duration:    c.Duration,
+		Concurrency: c.Concurrency,
+		LookupRatio: c.LookupRatio,
+		Consistency: consistency,
+		Seed:        c.Seed,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeBenchReport(out, report)
+}
+
+func writeBenchReport(out io.Writer, report *bench.Report) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OPERATION\tREQUESTS\tERRORS\tRPS\tP50\tP90\tP99\tMAX")
+	for _, result := range report.Results {
+		rps := float64(result.Count) / report.Elapsed.Seconds()
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\n",
+			result.Operation, result.Count, result.Errors, rps,
+			roundLatency(result.P50), roundLatency(result.P90), roundLatency(result.P99), roundLatency(result.Max))
+	}
+	return w.Flush()
+}
+
+func roundLatency(latency time.Duration) time.Duration {
+	return latency.Round(time.Microsecond)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/bench"
+)
+
+func TestBenchConflictingConsistency(t *testing.T) {
+	config := &BenchConfig{FullyConsistent: true, AtLeastAsFresh: true}
+	require.ErrorContains(t, config.Run(context.Background(), &bytes.Buffer{}), "--fully-consistent")
+
+	config = &BenchConfig{AtLeastAsFresh: true, SkipDataLoad: true}
+	require.ErrorContains(t, config.Run(context.Background(), &bytes.Buffer{}), "--skip-data-load")
+}
+
+func TestWriteBenchReport(t *testing.T) {
+	out := &bytes.Buffer{}
+	require.NoError(t, writeBenchReport(out, &bench.Report{
+		Elapsed: 2 * time.Second,
+		Results: []bench.Result{{
+			Operation: bench.OperationCheck,
+			Count:     100,
+			Errors:    1,
+			P50:       1500 * time.Microsecond,
+			P90:       3 * time.Millisecond,
+			P99:       7*time.Millisecond + 123,
+			Max:       12 * time.Millisecond,
+		}},
+	}))
+
+	require.Equal(t, `OPERATION  REQUESTS  ERRORS  RPS   P50    P90  P99  MAX
+check      100       1       50.0  1.5ms  3ms  7ms  12ms
+`, out.String())
+}
@@ -0,0 +1,511 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// ClientConnectionConfig configures the connection of commands acting as clients of a running
+// SpiceDB instance.
+type ClientConnectionConfig struct {
+	// Endpoint is the address of the SpiceDB instance.
+	Endpoint string
+	Token    string
+	Insecure bool
+	CAPath   string
+}
+
+func RegisterClientConnectionFlags(flags *pflag.FlagSet, config *ClientConnectionConfig) {
+	flags.StringVar(&config.Endpoint, "endpoint", "localhost:50051", "address of the SpiceDB instance")
+	flags.StringVar(&config.Token, "token", "", "preshared key to authenticate with")
+	flags.BoolVar(&config.Insecure, "insecure", false, "connect without TLS")
+	flags.StringVar(&config.CAPath, "ca-path", "", "path to the CA certificate used to verify the endpoint, instead of the system certificates")
+}
+
+// Dial connects to the configured SpiceDB instance.
+func (c *ClientConnectionConfig) Dial(ctx context.Context) (*grpc.ClientConn, error) {
+	var opts []grpc.DialOption
+	switch {
+	case c.Insecure:
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if c.Token != "" {
+			opts = append(opts, grpcutil.WithInsecureBearerToken(c.Token))
+		}
+
+	case c.CAPath != "":
+		certsOpt, err := grpcutil.WithCustomCerts(grpcutil.VerifyCA, c.CAPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, certsOpt)
+		if c.Token != "" {
+			opts = append(opts, grpcutil.WithBearerToken(c.Token))
+		}
+
+	default:
+		certsOpt, err := grpcutil.WithSystemCerts(grpcutil.VerifyCA)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, certsOpt)
+		if c.Token != "" {
+			opts = append(opts, grpcutil.WithBearerToken(c.Token))
+		}
+	}
+
+	return grpc.DialContext(ctx, c.Endpoint, opts...)
+}
+
+func RegisterClientFlags(cmd *cobra.Command, config *ClientConnectionConfig) {
+	RegisterClientConnectionFlags(cmd.PersistentFlags(), config)
+}
+
+func NewClientCommand(programName string, config *ClientConnectionConfig) *cobra.Command {
+	clientCmd := &cobra.Command{
+		Use:   "client",
+		Short: "client operations",
+		Long:  "Operations against a running SpiceDB instance, using the relationship text format, e.g. document:readme#viewer@user:tom",
+	}
+
+	checkCmd := &cobra.Command{
+		Use:     "check <resource:id#permission@subject:id[#relation]>",
+		Short:   "checks whether a subject has a permission on a resource",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			return runClientCommand(cmd, config, func(ctx context.Context, conn grpc.ClientConnInterface, consistency *v1.Consistency) error {
+				caveatContext, err := parseCaveatContext(cobrautil.MustGetString(cmd, "caveat-context"))
+				if err != nil {
+					return err
+				}
+				return clientCheck(ctx, v1.NewPermissionsServiceClient(conn), cmd.OutOrStdout(), args[0], consistency, caveatContext)
+			})
+		}),
+	}
+	registerClientConsistencyFlags(checkCmd)
+	checkCmd.Flags().String("caveat-context", "", "JSON object of the context to evaluate caveats with")
+	clientCmd.AddCommand(checkCmd)
+
+	expandCmd := &cobra.Command{
+		Use:     "expand <resource:id#permission>",
+		Short:   "expands the tree of subjects of a permission on a resource",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			return runClientCommand(cmd, config, func(ctx context.Context, conn grpc.ClientConnInterface, consistency *v1.Consistency) error {
+				return clientExpand(ctx, v1.NewPermissionsServiceClient(conn), cmd.OutOrStdout(), args[0], consistency)
+			})
+		}),
+	}
+	registerClientConsistencyFlags(expandCmd)
+	clientCmd.AddCommand(expandCmd)
+
+	lookupCmd := &cobra.Command{
+		Use:     "lookup <resource-type> <permission> <subject:id[#relation]>",
+		Short:   "looks up the resources on which a subject has a permission",
+		Args:    cobra.ExactArgs(3),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			return runClientCommand(cmd, config, func(ctx context.Context, conn grpc.ClientConnInterface, consistency *v1.Consistency) error {
+				return clientLookup(ctx, v1.NewPermissionsServiceClient(conn), cmd.OutOrStdout(), args[0], args[1], args[2], consistency)
+			})
+		}),
+	}
+	registerClientConsistencyFlags(lookupCmd)
+	clientCmd.AddCommand(lookupCmd)
+
+	readCmd := &cobra.Command{
+		Use:     "read <resource-type[:id][#relation][@subject-type[:id][#relation]]>",
+		Short:   "reads the relationships matching a filter",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			return runClientCommand(cmd, config, func(ctx context.Context, conn grpc.ClientConnInterface, consistency *v1.Consistency) error {
+				return clientRead(ctx, v1.NewPermissionsServiceClient(conn), cmd.OutOrStdout(), args[0], consistency)
+			})
+		}),
+	}
+	registerClientConsistencyFlags(readCmd)
+	clientCmd.AddCommand(readCmd)
+
+	writeCmd := &cobra.Command{
+		Use:     "write [relationships...]",
+		Short:   "writes relationships",
+		Long:    "Writes relationships in a single transaction and prints the ZedToken at which they were written.\nIf no relationships are given, they are read from stdin, one per line.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			relationships := args
+			if len(relationships) == 0 {
+				var err error
+				relationships, err = readRelationshipLines(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+			}
+
+			return runClientCommand(cmd, config, func(ctx context.Context, conn grpc.ClientConnInterface, _ *v1.Consistency) error {
+				return clientWrite(ctx, v1.NewPermissionsServiceClient(conn), cmd.OutOrStdout(), cobrautil.MustGetString(cmd, "operation"), relationships)
+			})
+		}),
+	}
+	writeCmd.Flags().String("operation", "touch", "operation applied to the relationships (create, touch, delete)")
+	clientCmd.AddCommand(writeCmd)
+
+	writeSchemaCmd := &cobra.Command{
+		Use:     "write-schema [files...]",
+		Short:   "writes a schema",
+		Long:    "Writes a schema, which can be split across multiple files, and prints the ZedToken at which it was written.\nIf no files are given, the schema is read from stdin.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			compiled, err := compileSchemaFiles(cmd.InOrStdin(), args)
+			if err != nil {
+				return err
+			}
+
+			return runClientCommand(cmd, config, func(ctx context.Context, conn grpc.ClientConnInterface, _ *v1.Consistency) error {
+				return clientWriteSchema(ctx, v1.NewSchemaServiceClient(conn), cmd.OutOrStdout(), compiled)
+			})
+		}),
+	}
+	clientCmd.AddCommand(writeSchemaCmd)
+
+	return clientCmd
+}
+
+func registerClientConsistencyFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("fully-consistent", false, "evaluate at the newest revision, bypassing caches")
+	cmd.Flags().String("at-least-as-fresh", "", "evaluate at a revision at least as fresh as the given ZedToken")
+	cmd.Flags().String("at-exact-snapshot", "", "evaluate at the exact revision of the given ZedToken")
+}
+
+func clientConsistency(cmd *cobra.Command) (*v1.Consistency, error) {
+	if cmd.Flags().Lookup("fully-consistent") == nil {
+		return nil, nil
+	}
+
+	fullyConsistent := cobrautil.MustGetBool(cmd, "fully-consistent")
+	atLeastAsFresh := cobrautil.MustGetString(cmd, "at-least-as-fresh")
+	atExactSnapshot := cobrautil.MustGetString(cmd, "at-exact-snapshot")
+
+	var consistency *v1.Consistency
+	var count int
+	if fullyConsistent {
+		count++
+		consistency = &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	}
+	if atLeastAsFresh != "" {
+		count++
+		consistency = &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: &v1.ZedToken{Token: atLeastAsFresh}}}
+	}
+	if atExactSnapshot != "" {
+		count++
+		consistency = &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: &v1.ZedToken{Token: atExactSnapshot}}}
+	}
+	if count > 1 {
+		return nil, errors.New("only one of --fully-consistent, --at-least-as-fresh and --at-exact-snapshot can be specified")
+	}
+	return consistency, nil
+}
+
+func runClientCommand(cmd *cobra.Command, config *ClientConnectionConfig, run func(context.Context, grpc.ClientConnInterface, *v1.Consistency) error) error {
+	consistency, err := clientConsistency(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx := SignalContextWithGracePeriod(context.Background(), 0)
+	conn, err := config.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return run(ctx, conn, consistency)
+}
+
+func parseCaveatContext(contextJSON string) (*structpb.Struct, error) {
+	if contextJSON == "" {
+		return nil, nil
+	}
+
+	var contextMap map[string]any
+	if err := json.Unmarshal([]byte(contextJSON), &contextMap); err != nil {
+		return nil, fmt.Errorf("invalid caveat context: %w", err)
+	}
+	return structpb.NewStruct(contextMap)
+}
+
+func readRelationshipLines(in io.Reader) ([]string, error) {
+	var relationships []string
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		relationships = append(relationships, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read relationships from stdin: %w", err)
+	}
+	return relationships, nil
+}
+
+func parseSubjectReference(subject string) (*v1.SubjectReference, error) {
+	onr := tuple.ParseSubjectONR(subject)
+	if onr == nil {
+		return nil, fmt.Errorf("invalid subject %q", subject)
+	}
+
+	ref := &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: onr.Namespace, ObjectId: onr.ObjectId}}
+	if onr.Relation != tuple.Ellipsis {
+		ref.OptionalRelation = onr.Relation
+	}
+	return ref, nil
+}
+
+func clientCheck(ctx context.Context, client v1.PermissionsServiceClient, out io.Writer, check string, consistency *v1.Consistency, caveatContext *structpb.Struct) error {
+	rel := tuple.ParseRel(check)
+	if rel == nil {
+		return fmt.Errorf("invalid check %q: expected resource:id#permission@subject:id", check)
+	}
+
+	resp, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Consistency: consistency,
+		Resource:    rel.Resource,
+		Permission:  rel.Relation,
+		Subject:     rel.Subject,
+		Context:     caveatContext,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch resp.Permissionship {
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION:
+		_, err = fmt.Fprintln(out, "true")
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+		_, err = fmt.Fprintf(out, "conditional: missing caveat context %s\n", strings.Join(resp.PartialCaveatInfo.GetMissingRequiredContext(), ", "))
+	default:
+		_, err = fmt.Fprintln(out, "false")
+	}
+	return err
+}
+
+func clientExpand(ctx context.Context, client v1.PermissionsServiceClient, out io.Writer, expand string, consistency *v1.Consistency) error {
+	onr := tuple.ParseONR(expand)
+	if onr == nil {
+		return fmt.Errorf("invalid expansion %q: expected resource:id#permission", expand)
+	}
+
+	resp, err := client.ExpandPermissionTree(ctx, &v1.ExpandPermissionTreeRequest{
+		Consistency: consistency,
+		Resource:    &v1.ObjectReference{ObjectType: onr.Namespace, ObjectId: onr.ObjectId},
+		Permission:  onr.Relation,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeExpansionTree(out, resp.TreeRoot, "")
+}
+
+// writeExpansionTree writes an expansion tree with one node per line, indenting children under
+// their parent.
+func writeExpansionTree(out io.Writer, tree *v1.PermissionRelationshipTree, indent string) error {
+	name := tuple.StringObjectRef(tree.ExpandedObject) + "#" + tree.ExpandedRelation
+
+	switch treeType := tree.TreeType.(type) {
+	case *v1.PermissionRelationshipTree_Intermediate:
+		operation := strings.ToLower(strings.TrimPrefix(treeType.Intermediate.Operation.String(), "OPERATION_"))
+		if _, err := fmt.Fprintf(out, "%s%s (%s)\n", indent, name, operation); err != nil {
+			return err
+		}
+		for _, child := range treeType.Intermediate.Children {
+			if err := writeExpansionTree(out, child, indent+"  "); err != nil {
+				return err
+			}
+		}
+
+	case *v1.PermissionRelationshipTree_Leaf:
+		if _, err := fmt.Fprintf(out, "%s%s\n", indent, name); err != nil {
+			return err
+		}
+		for _, subject := range treeType.Leaf.Subjects {
+			if _, err := fmt.Fprintf(out, "%s  %s\n", indent, tuple.StringSubjectRef(subject)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func clientLookup(ctx context.Context, client v1.PermissionsServiceClient, out io.Writer, resourceType, permission, subject string, consistency *v1.Consistency) error {
+	subjectRef, err := parseSubjectReference(subject)
+	if err != nil {
+		return err
+	}
+
+	stream, err := client.LookupResources(ctx, &v1.LookupResourcesRequest{
+		Consistency:        consistency,
+		ResourceObjectType: resourceType,
+		Permission:         permission,
+		Subject:            subjectRef,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resource := tuple.JoinObjectRef(resourceType, resp.ResourceObjectId)
+		if resp.Permissionship == v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION {
+			resource += " (conditional)"
+		}
+		if _, err := fmt.Fprintln(out, resource); err != nil {
+			return err
+		}
+	}
+}
+
+// parseRelationshipFilter parses a filter of the form
+// resource-type[:id][#relation][@subject-type[:id][#relation]].
+func parseRelationshipFilter(filter string) (*v1.RelationshipFilter, error) {
+	resource, subject, hasSubject := strings.Cut(filter, "@")
+
+	parsed := &v1.RelationshipFilter{}
+	resource, parsed.OptionalRelation, _ = strings.Cut(resource, "#")
+	parsed.ResourceType, parsed.OptionalResourceId, _ = strings.Cut(resource, ":")
+	if parsed.ResourceType == "" {
+		return nil, fmt.Errorf("invalid filter %q: a resource type is required", filter)
+	}
+
+	if hasSubject {
+		subjectFilter := &v1.SubjectFilter{}
+		subject, relation, hasRelation := strings.Cut(subject, "#")
+		subjectFilter.SubjectType, subjectFilter.OptionalSubjectId, _ = strings.Cut(subject, ":")
+		if subjectFilter.SubjectType == "" {
+			return nil, fmt.Errorf("invalid filter %q: a subject type is required after @", filter)
+		}
+		if hasRelation {
+			if relation == tuple.Ellipsis {
+				relation = ""
+			}
+			subjectFilter.OptionalRelation = &v1.SubjectFilter_RelationFilter{Relation: relation}
+		}
+		parsed.OptionalSubjectFilter = subjectFilter
+	}
+
+	return parsed, nil
+}
+
+func clientRead(ctx context.Context, client v1.PermissionsServiceClient, out io.Writer, filter string, consistency *v1.Consistency) error {
+	relationshipFilter, err := parseRelationshipFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency:        consistency,
+		RelationshipFilter: relationshipFilter,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rel, err := tuple.StringRelationship(resp.Relationship)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(out, rel); err != nil {
+			return err
+		}
+	}
+}
+
+var clientWriteOperations = map[string]v1.RelationshipUpdate_Operation{
+	"create": v1.RelationshipUpdate_OPERATION_CREATE,
+	"touch":  v1.RelationshipUpdate_OPERATION_TOUCH,
+	"delete": v1.RelationshipUpdate_OPERATION_DELETE,
+}
+
+func clientWrite(ctx context.Context, client v1.PermissionsServiceClient, out io.Writer, operation string, relationships []string) error {
+	op, ok := clientWriteOperations[operation]
+	if !ok {
+		return fmt.Errorf("unknown operation %q: expected create, touch or delete", operation)
+	}
+	if len(relationships) == 0 {
+		return errors.New("no relationships to write")
+	}
+
+	updates := make([]*v1.RelationshipUpdate, 0, len(relationships))
+	for _, relationship := range relationships {
+		rel := tuple.ParseRel(relationship)
+		if rel == nil {
+			return fmt.Errorf("invalid relationship %q", relationship)
+		}
+		updates = append(updates, &v1.RelationshipUpdate{Operation: op, Relationship: rel})
+	}
+
+	resp, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, resp.WrittenAt.Token)
+	return err
+}
+
+func clientWriteSchema(ctx context.Context, client v1.SchemaServiceClient, out io.Writer, compiled *compiler.CompiledSchema) error {
+	schema, ok, err := generator.GenerateSchema(compiled.OrderedDefinitions)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("schema cannot be written without losing information")
+	}
+
+	resp, err := client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: schema})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, resp.WrittenAt.Token)
+	return err
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+var fullyConsistent = &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+
+func TestClientCommands(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, false, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	client := v1.NewPermissionsServiceClient(conn)
+	ctx := context.Background()
+
+	run := func(t *testing.T, f func(out *bytes.Buffer) error) string {
+		out := &bytes.Buffer{}
+		require.NoError(t, f(out))
+		return out.String()
+	}
+
+	t.Run("check", func(t *testing.T) {
+		require.Equal(t, "true\n", run(t, func(out *bytes.Buffer) error {
+			return clientCheck(ctx, client, out, "document:masterplan#view@user:eng_lead", fullyConsistent, nil)
+		}))
+		require.Equal(t, "false\n", run(t, func(out *bytes.Buffer) error {
+			return clientCheck(ctx, client, out, "document:masterplan#view@user:villain", fullyConsistent, nil)
+		}))
+		require.ErrorContains(t, clientCheck(ctx, client, &bytes.Buffer{}, "document:masterplan", nil, nil), "invalid check")
+	})
+
+	t.Run("expand", func(t *testing.T) {
+		out := run(t, func(out *bytes.Buffer) error {
+			return clientExpand(ctx, client, out, "document:masterplan#edit", fullyConsistent)
+		})
+		require.Equal(t, `document:masterplan#edit (union)
+  document:masterplan#owner
+    user:product_manager
+  document:masterplan#editor
+`, out)
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		out := run(t, func(out *bytes.Buffer) error {
+			return clientLookup(ctx, client, out, "document", "view", "user:eng_lead", fullyConsistent)
+		})
+		require.Equal(t, "document:masterplan\n", out)
+	})
+
+	t.Run("write and read", func(t *testing.T) {
+		token := run(t, func(out *bytes.Buffer) error {
+			return clientWrite(ctx, client, out, "create", []string{
+				"document:newplan#viewer@user:tom",
+				"document:newplan#parent@folder:plans",
+			})
+		})
+		require.NotEmpty(t, token)
+
+		atToken := &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: &v1.ZedToken{Token: token[:len(token)-1]}}}
+		require.Equal(t, "document:newplan#viewer@user:tom\n", run(t, func(out *bytes.Buffer) error {
+			return clientRead(ctx, client, out, "document:newplan@user", atToken)
+		}))
+		require.Equal(t, "document:newplan#parent@folder:plans\ndocument:newplan#viewer@user:tom\n", run(t, func(out *bytes.Buffer) error {
+			return clientRead(ctx, client, out, "document:newplan", atToken)
+		}))
+
+		run(t, func(out *bytes.Buffer) error {
+			return clientWrite(ctx, client, out, "delete", []string{"document:newplan#viewer@user:tom"})
+		})
+		require.Equal(t, "document:newplan#parent@folder:plans\n", run(t, func(out *bytes.Buffer) error {
+			return clientRead(ctx, client, out, "document:newplan", fullyConsistent)
+		}))
+
+		require.ErrorContains(t, clientWrite(ctx, client, &bytes.Buffer{}, "upsert", []string{"document:newplan#viewer@user:tom"}), `unknown operation "upsert"`)
+		require.ErrorContains(t, clientWrite(ctx, client, &bytes.Buffer{}, "touch", []string{"document:newplan"}), "invalid relationship")
+	})
+}
+
+func TestClientWriteSchema(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, false, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+
+	dir := t.TempDir()
+	usersFile := filepath.Join(dir, "users.zed")
+	require.NoError(t, os.WriteFile(usersFile, []byte("definition user {}"), 0o600))
+	documentsFile := filepath.Join(dir, "documents.zed")
+	require.NoError(t, os.WriteFile(documentsFile, []byte("definition document {\n\trelation viewer: user\n}"), 0o600))
+
+	compiled, err := compileSchemaFiles(nil, []string{usersFile, documentsFile})
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	client := v1.NewSchemaServiceClient(conn)
+	require.NoError(t, clientWriteSchema(context.Background(), client, out, compiled))
+	require.NotEmpty(t, out.String())
+
+	resp, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "definition document {\n\trelation viewer: user\n}\n\ndefinition user {}", resp.SchemaText)
+
+	// Definitions must be unique across the files.
+	_, err = compileSchemaFiles(nil, []string{usersFile, usersFile})
+	require.ErrorContains(t, err, "found duplicate input source")
+}
+
+func TestParseRelationshipFilter(t *testing.T) {
+	tcs := []struct {
+		filter   string
+		expected *v1.RelationshipFilter
+	}{
+		{"document", &v1.RelationshipFilter{ResourceType: "document"}},
+		{"document:plan#viewer", &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "plan", OptionalRelation: "viewer"}},
+		{"document@user:tom", &v1.RelationshipFilter{
+			ResourceType:          "document",
+			OptionalSubjectFilter: &v1.SubjectFilter{SubjectType: "user", OptionalSubjectId: "tom"},
+		}},
+		{"document#viewer@group#member", &v1.RelationshipFilter{
+			ResourceType:     "document",
+			OptionalRelation: "viewer",
+			OptionalSubjectFilter: &v1.SubjectFilter{
+				SubjectType:      "group",
+				OptionalRelation: &v1.SubjectFilter_RelationFilter{Relation: "member"},
+			},
+		}},
+		{"document@user#...", &v1.RelationshipFilter{
+			ResourceType: "document",
+			OptionalSubjectFilter: &v1.SubjectFilter{
+				SubjectType:      "user",
+				OptionalRelation: &v1.SubjectFilter_RelationFilter{},
+			},
+		}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.filter, func(t *testing.T) {
+			filter, err := parseRelationshipFilter(tc.filter)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected.String(), filter.String())
+		})
+	}
+
+	_, err := parseRelationshipFilter(":plan")
+	require.ErrorContains(t, err, "a resource type is required")
+
+	_, err = parseRelationshipFilter("document@:tom")
+	require.ErrorContains(t, err, "a subject type is required")
+}
@@ -0,0 +1,246 @@
+// Package configfile loads the values of command flags from a YAML configuration file.
+//
+// Values are resolved in the following order of precedence, from highest to lowest:
+//
+//  1. flags given on the command line
+//  2. environment variables, e.g. SPICEDB_DATASTORE_CONN_URI, including those of spicedb.env
+//  3. the configuration file
+//  4. the defaults of the flags
+//
+// The keys of the configuration file are the names of the flags. Nested maps are joined with
+// dashes, so that the following are equivalent:
+//
+//	datastore-engine: postgres
+//
+//	datastore:
+//	  engine: postgres
+//
+// Keys which are not flags of the command being run are ignored, so that a single file can
+// configure every command.
+//
+// A subset of the flags can be reloaded from the configuration file while the command is running,
+// with Reload().
+package configfile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFileFlagName = "config-file"
+
+	// setFromConfigFileAnnotation marks the flags whose values were set from the configuration
+	// file, which, unlike those given on the command line or in the environment, can be reloaded.
+	setFromConfigFileAnnotation = "configfile_set_from_config_file"
+)
+
+// RegisterFlags adds the flag for the path of the configuration file.
+func RegisterFlags(flags *pflag.FlagSet) {
+	flags.String(configFileFlagName, "", "path to a YAML file of flag values; flags and environment variables take precedence over it")
+}
+
+// RunE returns a Cobra RunFunc that sets the flags which were not given on the command line or in
+// the environment from the configuration file, if any. It must run after the environment has
+// been synchronized with the flags.
+//
+// The required flags can be added to a command by using RegisterFlags().
+func RunE() cobrautil.CobraRunFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if cobrautil.IsBuiltinCommand(cmd) {
+			return nil // No-op for builtins
+		}
+
+		if cmd.Flags().Lookup(configFileFlagName) == nil {
+			return nil
+		}
+
+		path := cobrautil.MustGetString(cmd, configFileFlagName)
+		if path == "" {
+			return nil
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read config file: %w", err)
+		}
+
+		if err := Apply(cmd.Flags(), contents); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		return nil
+	}
+}
+
+// Reload re-reads the configuration file and sets the named flags which were not given on the
+// command line or in the environment from it. Flags whose keys were removed from the file are
+// reset to their defaults. It returns the names of the flags whose values changed.
+//
+// Reload is a no-op if no configuration file was given.
+func Reload(flags *pflag.FlagSet, names ...string) ([]string, error) {
+	path, err := flags.GetString(configFileFlagName)
+	if err != nil || path == "" {
+		return nil, nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(contents, &values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	valuesByFlag := make(map[string]any)
+	if err := walkValues(flags, "", values, func(flag *pflag.Flag, value any) error {
+		valuesByFlag[flag.Name] = value
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	var changed []string
+	for _, name := range names {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return nil, fmt.Errorf("unknown flag %s", name)
+		}
+
+		if flag.Changed && !setFromConfigFile(flag) {
+			continue
+		}
+
+		previous := flag.Value.String()
+		value, ok := valuesByFlag[name]
+		if ok && value != nil {
+			if err := setFlag(flags, flag, value); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", name, err)
+			}
+		} else if flag.Changed {
+			if err := flag.Value.Set(flag.DefValue); err != nil {
+				return nil, fmt.Errorf("unable to reset %s: %w", name, err)
+			}
+			flag.Changed = false
+			delete(flag.Annotations, setFromConfigFileAnnotation)
+		}
+
+		if flag.Value.String() != previous {
+			changed = append(changed, name)
+		}
+	}
+	return changed, nil
+}
+
+// Apply sets the flags of the flag set which have not been changed from the given YAML
+// configuration.
+func Apply(flags *pflag.FlagSet, contents []byte) error {
+	var values map[string]any
+	if err := yaml.Unmarshal(contents, &values); err != nil {
+		return err
+	}
+
+	return walkValues(flags, "", values, func(flag *pflag.Flag, value any) error {
+		if flag.Changed {
+			return nil
+		}
+		return setFlag(flags, flag, value)
+	})
+}
+
+// walkValues calls fn with each flag of the flag set given a value in the configuration.
+func walkValues(flags *pflag.FlagSet, prefix string, values map[string]any, fn func(*pflag.Flag, any) error) error {
+	// Sort the keys, so that errors are reported deterministically.
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := key
+		if prefix != "" {
+			name = prefix + "-" + key
+		}
+
+		value := values[key]
+		flag := flags.Lookup(name)
+		if flag == nil {
+			if nested, ok := value.(map[string]any); ok {
+				if err := walkValues(flags, name, nested, fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := fn(flag, value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setFromConfigFile(flag *pflag.Flag) bool {
+	_, ok := flag.Annotations[setFromConfigFileAnnotation]
+	return ok
+}
+
+func setFlag(flags *pflag.FlagSet, flag *pflag.Flag, value any) error {
+	if value == nil {
+		return nil
+	}
+
+	if err := setFlagValue(flags, flag, value); err != nil {
+		return err
+	}
+
+	if flag.Annotations == nil {
+		flag.Annotations = make(map[string][]string)
+	}
+	flag.Annotations[setFromConfigFileAnnotation] = []string{"true"}
+	return nil
+}
+
+func setFlagValue(flags *pflag.FlagSet, flag *pflag.Flag, value any) error {
+	switch typed := value.(type) {
+	case []any:
+		items := make([]string, 0, len(typed))
+		for _, item := range typed {
+			items = append(items, fmt.Sprint(item))
+		}
+
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			if err := slice.Replace(items); err != nil {
+				return err
+			}
+			flag.Changed = true
+			return nil
+		}
+		return flags.Set(flag.Name, strings.Join(items, ","))
+
+	case map[string]any:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, 0, len(typed))
+		for _, key := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", key, typed[key]))
+		}
+		return flags.Set(flag.Name, strings.Join(pairs, ","))
+
+	default:
+		return flags.Set(flag.Name, fmt.Sprint(typed))
+	}
+}
@@ -0,0 +1,178 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+datastore-engine: postgres
+datastore:
+  conn-uri: postgres://localhost:5432/spicedb
+  gc-window: 1h
+grpc-preshared-key:
+  - first
+  - second
+dispatch-upstream-addrs:
+  check: localhost:50053
+dispatch-max-depth: 25
+enabled: true
+unknown-key: ignored
+unknown:
+  nested: ignored
+`
+
+type testFlags struct {
+	engine        string
+	uri           string
+	gcWindow      time.Duration
+	presharedKeys []string
+	upstreamAddrs map[string]string
+	maxDepth      uint32
+	enabled       bool
+}
+
+func newTestCommand(flags *testFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "test",
+		PreRunE: cobrautil.CommandStack(
+			cobrautil.SyncViperPreRunE("spicedb"),
+			RunE(),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+
+	RegisterFlags(cmd.Flags())
+	cmd.Flags().StringVar(&flags.engine, "datastore-engine", "memory", "")
+	cmd.Flags().StringVar(&flags.uri, "datastore-conn-uri", "", "")
+	cmd.Flags().DurationVar(&flags.gcWindow, "datastore-gc-window", 24*time.Hour, "")
+	cmd.Flags().StringSliceVar(&flags.presharedKeys, "grpc-preshared-key", []string{}, "")
+	cmd.Flags().StringToStringVar(&flags.upstreamAddrs, "dispatch-upstream-addrs", map[string]string{}, "")
+	cmd.Flags().Uint32Var(&flags.maxDepth, "dispatch-max-depth", 50, "")
+	cmd.Flags().BoolVar(&flags.enabled, "enabled", false, "")
+	return cmd
+}
+
+func writeConfig(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "spicedb.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestConfigFile(t *testing.T) {
+	var flags testFlags
+	cmd := newTestCommand(&flags)
+	cmd.SetArgs([]string{"--config-file", writeConfig(t, testConfig)})
+	require.NoError(t, cmd.Execute())
+
+	require.Equal(t, testFlags{
+		engine:        "postgres",
+		uri:           "postgres://localhost:5432/spicedb",
+		gcWindow:      time.Hour,
+		presharedKeys: []string{"first", "second"},
+		upstreamAddrs: map[string]string{"check": "localhost:50053"},
+		maxDepth:      25,
+		enabled:       true,
+	}, flags)
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	t.Setenv("SPICEDB_DATASTORE_CONN_URI", "postgres://fromenv:5432/spicedb")
+	t.Setenv("SPICEDB_DISPATCH_MAX_DEPTH", "30")
+
+	var flags testFlags
+	cmd := newTestCommand(&flags)
+	cmd.SetArgs([]string{"--config-file", writeConfig(t, testConfig), "--dispatch-max-depth", "10"})
+	require.NoError(t, cmd.Execute())
+
+	require.Equal(t, "postgres", flags.engine)
+	require.Equal(t, "postgres://fromenv:5432/spicedb", flags.uri)
+	require.Equal(t, uint32(10), flags.maxDepth)
+}
+
+func TestConfigFileFromEnvironment(t *testing.T) {
+	t.Setenv("SPICEDB_CONFIG_FILE", writeConfig(t, testConfig))
+
+	var flags testFlags
+	cmd := newTestCommand(&flags)
+	cmd.SetArgs([]string{})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, "postgres", flags.engine)
+}
+
+func TestInvalidConfigFile(t *testing.T) {
+	tcs := []struct {
+		name          string
+		contents      string
+		expectedError string
+	}{
+		{"invalid yaml", "datastore-engine: [", "invalid config file"},
+		{"invalid value", "dispatch-max-depth: deep", "invalid value for dispatch-max-depth"},
+		{"invalid nested value", "datastore:\n  gc-window: forever", "invalid value for datastore-gc-window"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var flags testFlags
+			cmd := newTestCommand(&flags)
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			cmd.SetArgs([]string{"--config-file", writeConfig(t, tc.contents)})
+			require.ErrorContains(t, cmd.Execute(), tc.expectedError)
+		})
+	}
+
+	var flags testFlags
+	cmd := newTestCommand(&flags)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	cmd.SetArgs([]string{"--config-file", filepath.Join(t.TempDir(), "missing.yaml")})
+	require.ErrorContains(t, cmd.Execute(), "unable to read config file")
+}
+
+func TestReload(t *testing.T) {
+	t.Setenv("SPICEDB_DATASTORE_CONN_URI", "postgres://fromenv:5432/spicedb")
+
+	path := writeConfig(t, testConfig)
+
+	var flags testFlags
+	cmd := newTestCommand(&flags)
+	cmd.SetArgs([]string{"--config-file", path, "--enabled=false"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, uint32(25), flags.maxDepth)
+	require.Equal(t, time.Hour, flags.gcWindow)
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+dispatch-max-depth: 10
+datastore-conn-uri: postgres://fromfile:5432/spicedb
+enabled: true
+`), 0o600))
+
+	changed, err := Reload(cmd.Flags(), "dispatch-max-depth", "datastore-conn-uri", "datastore-gc-window", "enabled", "datastore-engine")
+	require.NoError(t, err)
+	require.Equal(t, []string{"dispatch-max-depth", "datastore-gc-window", "datastore-engine"}, changed)
+
+	require.Equal(t, uint32(10), flags.maxDepth)
+	require.Equal(t, 24*time.Hour, flags.gcWindow)
+	require.Equal(t, "memory", flags.engine)
+	require.Equal(t, "postgres://fromenv:5432/spicedb", flags.uri)
+	require.False(t, flags.enabled)
+
+	changed, err = Reload(cmd.Flags(), "dispatch-max-depth")
+	require.NoError(t, err)
+	require.Empty(t, changed)
+
+	require.NoError(t, os.WriteFile(path, []byte("dispatch-max-depth: deep"), 0o600))
+	_, err = Reload(cmd.Flags(), "dispatch-max-depth")
+	require.ErrorContains(t, err, "invalid value for dispatch-max-depth")
+
+	_, err = Reload(cmd.Flags(), "unknown")
+	require.ErrorContains(t, err, "unknown flag unknown")
+}
@@ -3,16 +3,22 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
+	"github.com/jzelinskie/cobrautil/v2"
 	"github.com/spf13/cobra"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/backup"
 	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
 	dspkg "github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/openfga"
+	"github.com/authzed/spicedb/pkg/schemautil"
 )
 
 func RegisterDatastoreRootFlags(_ *cobra.Command) {
@@ -43,6 +49,40 @@ func NewDatastoreCommand(programName string) (*cobra.Command, error) {
 	}
 	datastoreCmd.AddCommand(repairCmd)
 
+	renameDefinitionCmd := NewRenameDefinitionDatastoreCommand(programName, &cfg)
+	RegisterRenameFlags(renameDefinitionCmd)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(renameDefinitionCmd.Flags(), "", &cfg); err != nil {
+		return nil, err
+	}
+	datastoreCmd.AddCommand(renameDefinitionCmd)
+
+	renameRelationCmd := NewRenameRelationDatastoreCommand(programName, &cfg)
+	RegisterRenameFlags(renameRelationCmd)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(renameRelationCmd.Flags(), "", &cfg); err != nil {
+		return nil, err
+	}
+	datastoreCmd.AddCommand(renameRelationCmd)
+
+	backupCmd := NewBackupDatastoreCommand(programName, &cfg)
+	RegisterBackupFlags(backupCmd)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(backupCmd.Flags(), "", &cfg); err != nil {
+		return nil, err
+	}
+	datastoreCmd.AddCommand(backupCmd)
+
+	restoreCmd := NewRestoreDatastoreCommand(programName, &cfg)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(restoreCmd.Flags(), "", &cfg); err != nil {
+		return nil, err
+	}
+	datastoreCmd.AddCommand(restoreCmd)
+
+	importOpenFGACmd := NewImportOpenFGADatastoreCommand(programName, &cfg)
+	RegisterImportOpenFGAFlags(importOpenFGACmd)
+	if err := datastore.RegisterDatastoreFlagsWithPrefix(importOpenFGACmd.Flags(), "", &cfg); err != nil {
+		return nil, err
+	}
+	datastoreCmd.AddCommand(importOpenFGACmd)
+
 	headCmd := NewHeadCommand(programName)
 	RegisterHeadFlags(headCmd)
 	datastoreCmd.AddCommand(headCmd)
@@ -132,3 +172,261 @@ func NewRepairDatastoreCommand(programName string, cfg *datastore.Config) *cobra
 		}),
 	}
 }
+
+func RegisterRenameFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64("batch-size", schemautil.DefaultRenameBatchSize, "number of relationships to rewrite per transaction")
+}
+
+func NewRenameDefinitionDatastoreCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "rename-definition <old-name> <new-name>",
+		Short:   "renames an object definition",
+		Long:    "Renames an object definition in the schema and rewrites all relationships referencing it.\nRelationships referencing the old definition should not be written while the rename is running.",
+		Args:    cobra.ExactArgs(2),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			return runRename(cmd, cfg, func(ctx context.Context, ds dspkg.Datastore, opts schemautil.RenameOptions) error {
+				return schemautil.RenameObjectDefinition(ctx, ds, args[0], args[1], opts)
+			})
+		}),
+	}
+}
+
+func NewRenameRelationDatastoreCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "rename-relation <definition> <old-name> <new-name>",
+		Short:   "renames a relation or permission",
+		Long:    "Renames a relation or permission in the schema and rewrites all relationships referencing it.\nRelationships referencing the old relation should not be written while the rename is running.",
+		Args:    cobra.ExactArgs(3),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			return runRename(cmd, cfg, func(ctx context.Context, ds dspkg.Datastore, opts schemautil.RenameOptions) error {
+				return schemautil.RenameRelation(ctx, ds, args[0], args[1], args[2], opts)
+			})
+		}),
+	}
+}
+
+func runRename(cmd *cobra.Command, cfg *datastore.Config, rename func(context.Context, dspkg.Datastore, schemautil.RenameOptions) error) error {
+	ctx := context.Background()
+
+	// Disable background GC and hedging.
+	cfg.GCInterval = -1 * time.Hour
+	cfg.RequestHedgingEnabled = false
+
+	ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+	if err != nil {
+		return fmt.Errorf("failed to create datastore: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Msg("Running rename...")
+	return rename(ctx, ds, schemautil.RenameOptions{
+		BatchSize: cobrautil.MustGetUint64(cmd, "batch-size"),
+		OnProgress: func(progress schemautil.RenameProgress) {
+			log.Ctx(ctx).Info().
+				Str("phase", string(progress.Phase)).
+				Uint64("relationships_migrated", progress.RelationshipsMigrated).
+				Msg("Rename progress")
+		},
+	})
+}
+
+func RegisterBackupFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64("batch-size", backup.DefaultBatchSize, "number of relationships to read from the datastore at once")
+	cmd.Flags().String("revision", "", "revision to back up instead of the head revision; it must not have been garbage collected yet")
+}
+
+func NewBackupDatastoreCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "backup <file>",
+		Short: "backs up the schema and relationships",
+		Long: "Writes the schema and all relationships of the datastore, as of a single revision, to a file, or to standard output if the file is -.\n" +
+			"Backing up a past revision with --revision and restoring it into a new datastore recovers the relationships as they were at that revision, " +
+			"e.g. before an accidental deletion, as long as the revision is within the garbage collection window.",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			ds, err := newMaintenanceDatastore(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			var atRevision dspkg.Revision
+			if revision := cobrautil.MustGetString(cmd, "revision"); revision != "" {
+				atRevision, err = ds.RevisionFromString(revision)
+				if err != nil {
+					return fmt.Errorf("invalid revision %q: %w", revision, err)
+				}
+			}
+
+			var out io.Writer = cmd.OutOrStdout()
+			if args[0] != "-" {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to create backup file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			log.Ctx(ctx).Info().Msg("Running backup...")
+			revision, stats, err := backup.Backup(ctx, ds, out, backup.Options{
+				BatchSize:  cobrautil.MustGetUint64(cmd, "batch-size"),
+				AtRevision: atRevision,
+				OnProgress: func(relationships uint64) {
+					log.Ctx(ctx).Info().Uint64("relationships", relationships).Msg("Backup progress")
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			log.Ctx(ctx).Info().
+				Stringer("revision", revision).
+				Uint64("caveats", stats.Caveats).
+				Uint64("namespaces", stats.Namespaces).
+				Uint64("relationships", stats.Relationships).
+				Msg("Backup completed")
+			return nil
+		}),
+	}
+}
+
+func NewRestoreDatastoreCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <file>",
+		Short: "restores a backup into an empty datastore",
+		Long: "Loads a backup written by the backup command, or read from standard input if the file is -, into an empty datastore in a single transaction.\n" +
+			"The restored schema and relationships are assigned a new revision of the datastore.",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			ds, err := newMaintenanceDatastore(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			var in io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open backup file: %w", err)
+				}
+				defer f.Close()
+				in = f
+			}
+
+			log.Ctx(ctx).Info().Msg("Running restore...")
+			revision, header, stats, err := backup.Restore(ctx, ds, in)
+			if err != nil {
+				return err
+			}
+
+			log.Ctx(ctx).Info().
+				Str("backup_revision", header.Revision).
+				Stringer("revision", revision).
+				Uint64("caveats", stats.Caveats).
+				Uint64("namespaces", stats.Namespaces).
+				Uint64("relationships", stats.Relationships).
+				Msg("Restore completed")
+			return nil
+		}),
+	}
+}
+
+func RegisterImportOpenFGAFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("dry-run", false, "validate the tuples and print the converted schema without importing anything")
+}
+
+func NewImportOpenFGADatastoreCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import-openfga <model-file> [<tuples-file>]",
+		Short: "imports an OpenFGA model and its tuples into an empty datastore",
+		Long: "Converts an OpenFGA authorization model, in its JSON representation, into a schema and loads it, along with the relationships of the OpenFGA tuples, into an empty datastore in a single transaction.\n" +
+			"Tuples are read as a JSON array of tuples or as the response of the OpenFGA Read API. Constructs of the model which are not converted one for one are logged as warnings.",
+		Args:    cobra.RangeArgs(1, 2),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			modelFile, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open model file: %w", err)
+			}
+			defer modelFile.Close()
+
+			model, err := openfga.ParseModel(modelFile)
+			if err != nil {
+				return err
+			}
+
+			conversion, err := openfga.Convert(ctx, model)
+			if err != nil {
+				return err
+			}
+			for _, warning := range conversion.Warnings {
+				log.Ctx(ctx).Warn().Str("type", warning.Type).Str("relation", warning.Relation).Msg(warning.Message)
+			}
+
+			var tuples []openfga.Tuple
+			if len(args) > 1 {
+				tuplesFile, err := os.Open(args[1])
+				if err != nil {
+					return fmt.Errorf("failed to open tuples file: %w", err)
+				}
+				defer tuplesFile.Close()
+
+				tuples, err = openfga.ParseTuples(tuplesFile)
+				if err != nil {
+					return err
+				}
+			}
+
+			if cobrautil.MustGetBool(cmd, "dry-run") {
+				for _, tpl := range tuples {
+					if _, err := conversion.Relationship(tpl); err != nil {
+						return err
+					}
+				}
+				_, err := fmt.Fprint(cmd.OutOrStdout(), conversion.Schema)
+				return err
+			}
+
+			ds, err := newMaintenanceDatastore(ctx, cfg)
+			if err != nil {
+				return err
+			}
+
+			log.Ctx(ctx).Info().Int("tuples", len(tuples)).Msg("Running OpenFGA import...")
+			revision, stats, err := openfga.Import(ctx, ds, conversion, tuples)
+			if err != nil {
+				return err
+			}
+
+			log.Ctx(ctx).Info().
+				Stringer("revision", revision).
+				Uint64("caveats", stats.Caveats).
+				Uint64("namespaces", stats.Namespaces).
+				Uint64("relationships", stats.Relationships).
+				Msg("OpenFGA import completed")
+			return nil
+		}),
+	}
+}
+
+// newMaintenanceDatastore creates the configured datastore without background garbage
+// collection or request hedging.
+func newMaintenanceDatastore(ctx context.Context, cfg *datastore.Config) (dspkg.Datastore, error) {
+	cfg.GCInterval = -1 * time.Hour
+	cfg.RequestHedgingEnabled = false
+
+	ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create datastore: %w", err)
+	}
+	return ds, nil
+}
@@ -126,6 +126,8 @@ type Config struct {
 	OverlapStrategy           string        `debugmap:"visible"`
 	EnableConnectionBalancing bool          `debugmap:"visible"`
 	ConnectRate               time.Duration `debugmap:"visible"`
+	ConnectRetryTimeout       time.Duration `debugmap:"visible"`
+	LazyConnect               bool          `debugmap:"visible"`
 
 	// Postgres
 	GCInterval         time.Duration `debugmap:"visible"`
@@ -140,6 +142,9 @@ type Config struct {
 	// MySQL
 	TablePrefix string `debugmap:"visible"`
 
+	// Memory
+	MemoryGCEnabled bool `debugmap:"visible"`
+
 	// Internal
 	WatchBufferLength       uint16        `debugmap:"visible"`
 	WatchBufferWriteTimeout time.Duration `debugmap:"visible"`
@@ -207,11 +212,14 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.StringVar(&opts.OverlapKey, flagName("datastore-tx-overlap-key"), "key", "static key to touch when writing to ensure transactions overlap (only used if --datastore-tx-overlap-strategy=static is set; cockroach driver only)")
 	flagSet.BoolVar(&opts.EnableConnectionBalancing, flagName("datastore-connection-balancing"), defaults.EnableConnectionBalancing, "enable connection balancing between database nodes (cockroach driver only)")
 	flagSet.DurationVar(&opts.ConnectRate, flagName("datastore-connect-rate"), 100*time.Millisecond, "rate at which new connections are allowed to the datastore (at a rate of 1/duration) (cockroach driver only)")
+	flagSet.DurationVar(&opts.ConnectRetryTimeout, flagName("datastore-connect-retry-timeout"), defaults.ConnectRetryTimeout, "how long to retry connecting to the datastore at startup, with exponential backoff, before failing (0 value means a single attempt) (cockroach driver only)")
+	flagSet.BoolVar(&opts.LazyConnect, flagName("datastore-lazy-connect"), defaults.LazyConnect, "if the datastore cannot be connected to at startup, start as not ready and keep connecting in the background instead of failing; the features of the datastore are determined once it has connected (cockroach driver only)")
 	flagSet.StringVar(&opts.SpannerCredentialsFile, flagName("datastore-spanner-credentials"), "", "path to service account key credentials file with access to the cloud spanner instance (omit to use application default credentials)")
 	flagSet.StringVar(&opts.SpannerEmulatorHost, flagName("datastore-spanner-emulator-host"), "", "URI of spanner emulator instance used for development and testing (e.g. localhost:9010)")
 	flagSet.Uint64Var(&opts.SpannerMinSessions, flagName("datastore-spanner-min-sessions"), 100, "minimum number of sessions across all Spanner gRPC connections the client can have at a given time")
 	flagSet.Uint64Var(&opts.SpannerMaxSessions, flagName("datastore-spanner-max-sessions"), 400, "maximum number of sessions across all Spanner gRPC connections the client can have at a given time")
 	flagSet.StringVar(&opts.TablePrefix, flagName("datastore-mysql-table-prefix"), "", "prefix to add to the name of all SpiceDB database tables")
+	flagSet.BoolVar(&opts.MemoryGCEnabled, flagName("datastore-memory-gc-enabled"), defaults.MemoryGCEnabled, "garbage collect the revisions outside of the GC window, which are otherwise kept in memory for the life of the process (memory driver only)")
 	flagSet.StringVar(&opts.MigrationPhase, flagName("datastore-migration-phase"), "", "datastore-specific flag that should be used to signal to a datastore which phase of a multi-step migration it is in")
 	flagSet.Uint16Var(&opts.WatchBufferLength, flagName("datastore-watch-buffer-length"), 1024, "how large the watch buffer should be before blocking")
 	flagSet.DurationVar(&opts.WatchBufferWriteTimeout, flagName("datastore-watch-buffer-write-timeout"), 1*time.Second, "how long the watch buffer should queue before forcefully disconnecting the reader")
@@ -249,6 +257,9 @@ func DefaultDatastoreConfig() *Config {
 		OverlapKey:                     "key",
 		OverlapStrategy:                "static",
 		ConnectRate:                    100 * time.Millisecond,
+		ConnectRetryTimeout:            30 * time.Second,
+		LazyConnect:                    false,
+		MemoryGCEnabled:                false,
 		EnableConnectionBalancing:      true,
 		GCInterval:                     3 * time.Minute,
 		GCMaxOperationTime:             1 * time.Minute,
@@ -385,6 +396,8 @@ func newCRDBDatastore(ctx context.Context, opts Config) (datastore.Datastore, er
 		crdb.WithEnablePrometheusStats(opts.EnableDatastoreMetrics),
 		crdb.WithEnableConnectionBalancing(opts.EnableConnectionBalancing),
 		crdb.ConnectRate(opts.ConnectRate),
+		crdb.ConnectRetryTimeout(opts.ConnectRetryTimeout),
+		crdb.WithLazyConnect(opts.LazyConnect),
 	)
 }
 
@@ -462,5 +475,8 @@ func newMySQLDatastore(ctx context.Context, opts Config) (datastore.Datastore, e
 
 func newMemoryDatstore(_ context.Context, opts Config) (datastore.Datastore, error) {
 	log.Warn().Msg("in-memory datastore is not persistent and not feasible to run in a high availability fashion")
+	if opts.MemoryGCEnabled && !opts.ReadOnly {
+		return memdb.NewMemdbDatastoreWithGC(opts.WatchBufferLength, opts.RevisionQuantization, opts.GCWindow)
+	}
 	return memdb.NewMemdbDatastore(opts.WatchBufferLength, opts.RevisionQuantization, opts.GCWindow)
 }
@@ -56,6 +56,8 @@ func (c *Config) ToOption() ConfigOption {
 		to.OverlapStrategy = c.OverlapStrategy
 		to.EnableConnectionBalancing = c.EnableConnectionBalancing
 		to.ConnectRate = c.ConnectRate
+		to.ConnectRetryTimeout = c.ConnectRetryTimeout
+		to.LazyConnect = c.LazyConnect
 		to.GCInterval = c.GCInterval
 		to.GCMaxOperationTime = c.GCMaxOperationTime
 		to.SpannerCredentialsFile = c.SpannerCredentialsFile
@@ -63,6 +65,7 @@ func (c *Config) ToOption() ConfigOption {
 		to.SpannerMinSessions = c.SpannerMinSessions
 		to.SpannerMaxSessions = c.SpannerMaxSessions
 		to.TablePrefix = c.TablePrefix
+		to.MemoryGCEnabled = c.MemoryGCEnabled
 		to.WatchBufferLength = c.WatchBufferLength
 		to.WatchBufferWriteTimeout = c.WatchBufferWriteTimeout
 		to.MigrationPhase = c.MigrationPhase
@@ -97,6 +100,8 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["OverlapStrategy"] = helpers.DebugValue(c.OverlapStrategy, false)
 	debugMap["EnableConnectionBalancing"] = helpers.DebugValue(c.EnableConnectionBalancing, false)
 	debugMap["ConnectRate"] = helpers.DebugValue(c.ConnectRate, false)
+	debugMap["ConnectRetryTimeout"] = helpers.DebugValue(c.ConnectRetryTimeout, false)
+	debugMap["LazyConnect"] = helpers.DebugValue(c.LazyConnect, false)
 	debugMap["GCInterval"] = helpers.DebugValue(c.GCInterval, false)
 	debugMap["GCMaxOperationTime"] = helpers.DebugValue(c.GCMaxOperationTime, false)
 	debugMap["SpannerCredentialsFile"] = helpers.DebugValue(c.SpannerCredentialsFile, false)
@@ -104,6 +109,7 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["SpannerMinSessions"] = helpers.DebugValue(c.SpannerMinSessions, false)
 	debugMap["SpannerMaxSessions"] = helpers.DebugValue(c.SpannerMaxSessions, false)
 	debugMap["TablePrefix"] = helpers.DebugValue(c.TablePrefix, false)
+	debugMap["MemoryGCEnabled"] = helpers.DebugValue(c.MemoryGCEnabled, false)
 	debugMap["WatchBufferLength"] = helpers.DebugValue(c.WatchBufferLength, false)
 	debugMap["WatchBufferWriteTimeout"] = helpers.DebugValue(c.WatchBufferWriteTimeout, false)
 	debugMap["MigrationPhase"] = helpers.DebugValue(c.MigrationPhase, false)
@@ -315,6 +321,20 @@ func WithConnectRate(connectRate time.Duration) ConfigOption {
 	}
 }
 
+// WithConnectRetryTimeout returns an option that can set ConnectRetryTimeout on a Config
+func WithConnectRetryTimeout(connectRetryTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ConnectRetryTimeout = connectRetryTimeout
+	}
+}
+
+// WithLazyConnect returns an option that can set LazyConnect on a Config
+func WithLazyConnect(lazyConnect bool) ConfigOption {
+	return func(c *Config) {
+		c.LazyConnect = lazyConnect
+	}
+}
+
 // WithGCInterval returns an option that can set GCInterval on a Config
 func WithGCInterval(gCInterval time.Duration) ConfigOption {
 	return func(c *Config) {
@@ -364,6 +384,13 @@ func WithTablePrefix(tablePrefix string) ConfigOption {
 	}
 }
 
+// WithMemoryGCEnabled returns an option that can set MemoryGCEnabled on a Config
+func WithMemoryGCEnabled(memoryGCEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.MemoryGCEnabled = memoryGCEnabled
+	}
+}
+
 // WithWatchBufferLength returns an option that can set WatchBufferLength on a Config
 func WithWatchBufferLength(watchBufferLength uint16) ConfigOption {
 	return func(c *Config) {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/internal/lsp"
+	"github.com/authzed/spicedb/pkg/cmd/configfile"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
 	"github.com/authzed/spicedb/pkg/releases"
 )
@@ -41,6 +42,7 @@ func NewLSPCommand(programName string, config *LSPConfig) *cobra.Command {
 		Short: "serve language server protocol",
 		PreRunE: cobrautil.CommandStack(
 			cobrautil.SyncViperDotEnvPreRunE(programName, "spicedb.env", zerologr.New(&logging.Logger)),
+			configfile.RunE(),
 			cobrazerolog.New(
 				cobrazerolog.WithTarget(func(logger zerolog.Logger) {
 					logging.SetGlobalLogger(logger)
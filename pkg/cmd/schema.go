@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/development"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+func RegisterSchemaRootFlags(_ *cobra.Command) {
+}
+
+func NewSchemaCommand(programName string) *cobra.Command {
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "schema operations",
+		Long:  "Operations against schema files",
+	}
+
+	fmtCmd := NewSchemaFormatCommand(programName)
+	RegisterSchemaFormatFlags(fmtCmd)
+	schemaCmd.AddCommand(fmtCmd)
+
+	lintCmd := NewSchemaLintCommand(programName)
+	RegisterSchemaLintFlags(lintCmd)
+	schemaCmd.AddCommand(lintCmd)
+
+	graphCmd := NewSchemaGraphCommand(programName)
+	RegisterSchemaGraphFlags(graphCmd)
+	schemaCmd.AddCommand(graphCmd)
+
+	return schemaCmd
+}
+
+func RegisterSchemaFormatFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("write", "w", false, "write the formatted schema back to the source file(s) instead of to stdout")
+	cmd.Flags().Bool("check", false, "do not write anything; exit with an error if any file is not formatted")
+}
+
+func NewSchemaFormatCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "fmt [files...]",
+		Short:   "formats schema files",
+		Long:    "Formats schema files with canonical indentation and spacing.\nIf no files are given, the schema is read from stdin and written to stdout.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE:    termination.PublishError(schemaFormatRun),
+	}
+}
+
+func schemaFormatRun(cmd *cobra.Command, args []string) error {
+	write := cobrautil.MustGetBool(cmd, "write")
+	check := cobrautil.MustGetBool(cmd, "check")
+
+	if write && check {
+		return errors.New("cannot specify both --write and --check")
+	}
+
+	if len(args) == 0 {
+		if write {
+			return errors.New("cannot use --write when reading from stdin")
+		}
+
+		contents, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("unable to read schema from stdin: %w", err)
+		}
+
+		formatted, err := FormatSchema("stdin", string(contents))
+		if err != nil {
+			return err
+		}
+
+		if check {
+			if formatted != string(contents) {
+				return errors.New("schema is not formatted")
+			}
+			return nil
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), formatted)
+		return err
+	}
+
+	var unformatted []string
+	for _, filename := range args {
+		contents, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("unable to read schema file %s: %w", filename, err)
+		}
+
+		formatted, err := FormatSchema(filename, string(contents))
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case check:
+			if formatted != string(contents) {
+				unformatted = append(unformatted, filename)
+				fmt.Fprintln(cmd.OutOrStdout(), filename)
+			}
+
+		case write:
+			if formatted == string(contents) {
+				continue
+			}
+
+			info, err := os.Stat(filename)
+			if err != nil {
+				return fmt.Errorf("unable to stat schema file %s: %w", filename, err)
+			}
+
+			if err := os.WriteFile(filename, []byte(formatted), info.Mode().Perm()); err != nil {
+				return fmt.Errorf("unable to write schema file %s: %w", filename, err)
+			}
+
+		default:
+			if _, err := fmt.Fprint(cmd.OutOrStdout(), formatted); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(unformatted) > 0 {
+		return fmt.Errorf("%d schema file(s) are not formatted", len(unformatted))
+	}
+
+	return nil
+}
+
+func RegisterSchemaLintFlags(_ *cobra.Command) {
+}
+
+func NewSchemaLintCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "lint [files...]",
+		Short:   "lints schema files",
+		Long:    "Checks a schema for likely mistakes, such as unused relations and permissions that can never be granted.\nThe schema can be split across multiple files, which may reference the definitions of one another. If no files are given, the schema is read from stdin.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE:    termination.PublishError(schemaLintRun),
+	}
+}
+
+func schemaLintRun(cmd *cobra.Command, args []string) error {
+	compiled, err := compileSchemaFiles(cmd.InOrStdin(), args)
+	if err != nil {
+		return err
+	}
+
+	warnings := development.LintSchema(compiled)
+	for _, warning := range warnings {
+		source, _ := compiled.DefinitionSource(warning.DefinitionName)
+		fmt.Fprintf(cmd.OutOrStdout(), "%s:%s\n", source, warning)
+	}
+
+	if len(warnings) > 0 {
+		return fmt.Errorf("found %d lint warning(s)", len(warnings))
+	}
+
+	return nil
+}
+
+func RegisterSchemaGraphFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", string(generator.GraphFormatDOT), fmt.Sprintf("format of the graph (%s)", joinGraphFormats()))
+}
+
+func NewSchemaGraphCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "graph [files...]",
+		Short: "renders a schema as a graph",
+		Long: "Renders the object definitions of a schema as a Graphviz DOT or Mermaid graph, with nodes for object types, relations and permissions, " +
+			"and edges for allowed subject types and for the relations permissions are computed from.\nThe schema can be split across multiple files. If no files are given, the schema is read from stdin.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE:    termination.PublishError(schemaGraphRun),
+	}
+}
+
+func schemaGraphRun(cmd *cobra.Command, args []string) error {
+	format := generator.GraphFormat(cobrautil.MustGetString(cmd, "format"))
+
+	compiled, err := compileSchemaFiles(cmd.InOrStdin(), args)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := generator.GenerateGraph(compiled.ObjectDefinitions, format)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(cmd.OutOrStdout(), rendered)
+	return err
+}
+
+func joinGraphFormats() string {
+	formats := make([]string, 0, len(generator.GraphFormats))
+	for _, format := range generator.GraphFormats {
+		formats = append(formats, string(format))
+	}
+	return strings.Join(formats, ", ")
+}
+
+// compileSchemaFiles compiles a schema split across the given files into a single set of
+// definitions. If no files are given, the schema is read from the reader.
+func compileSchemaFiles(in io.Reader, filenames []string) (*compiler.CompiledSchema, error) {
+	if len(filenames) == 0 {
+		contents, err := io.ReadAll(in)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read schema from stdin: %w", err)
+		}
+
+		return compiler.Compile(compiler.InputSchema{
+			Source:       input.Source("stdin"),
+			SchemaString: string(contents),
+		}, compiler.AllowUnprefixedObjectType())
+	}
+
+	schemas := make([]compiler.InputSchema, 0, len(filenames))
+	for _, filename := range filenames {
+		contents, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read schema file %s: %w", filename, err)
+		}
+
+		schemas = append(schemas, compiler.InputSchema{
+			Source:       input.Source(filename),
+			SchemaString: string(contents),
+		})
+	}
+
+	return compiler.CompileMultiple(schemas, compiler.AllowUnprefixedObjectType())
+}
+
+// FormatSchema parses the given schema and returns it re-emitted in canonical form.
+func FormatSchema(sourceName string, schema string) (string, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source(sourceName),
+		SchemaString: schema,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return "", err
+	}
+
+	formatted, ok, err := generator.GenerateSchema(compiled.OrderedDefinitions)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", fmt.Errorf("schema in %s cannot be formatted without losing information", sourceName)
+	}
+
+	return formatted + "\n", nil
+}
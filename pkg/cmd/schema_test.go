@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const unformattedSchema = `definition user {}
+
+definition document {
+relation   viewer: user
+	permission view =viewer
+}`
+
+const formattedSchema = `definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}
+`
+
+func TestFormatSchema(t *testing.T) {
+	formatted, err := FormatSchema("test", unformattedSchema)
+	require.NoError(t, err)
+	require.Equal(t, formattedSchema, formatted)
+
+	// Formatting must be idempotent.
+	reformatted, err := FormatSchema("test", formatted)
+	require.NoError(t, err)
+	require.Equal(t, formatted, reformatted)
+
+	_, err = FormatSchema("test", "definition document {")
+	require.Error(t, err)
+}
+
+func runSchemaFormat(t *testing.T, stdin string, args ...string) (string, error) {
+	cmd := NewSchemaFormatCommand("spicedb")
+	RegisterSchemaFormatFlags(cmd)
+	cmd.PreRunE = nil
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	out := &bytes.Buffer{}
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(out)
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestSchemaFormatCommand(t *testing.T) {
+	out, err := runSchemaFormat(t, unformattedSchema)
+	require.NoError(t, err)
+	require.Equal(t, formattedSchema, out)
+
+	_, err = runSchemaFormat(t, unformattedSchema, "--check")
+	require.Error(t, err)
+
+	_, err = runSchemaFormat(t, formattedSchema, "--check")
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "schema.zed")
+	require.NoError(t, os.WriteFile(filename, []byte(unformattedSchema), 0o600))
+
+	out, err = runSchemaFormat(t, "", "--check", filename)
+	require.Error(t, err)
+	require.Equal(t, filename+"\n", out)
+
+	_, err = runSchemaFormat(t, "", "--write", filename)
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, formattedSchema, string(contents))
+
+	_, err = runSchemaFormat(t, "", "--check", filename)
+	require.NoError(t, err)
+}
+
+func TestSchemaLintCommand(t *testing.T) {
+	cmd := NewSchemaLintCommand("spicedb")
+	RegisterSchemaLintFlags(cmd)
+	cmd.PreRunE = nil
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	out := &bytes.Buffer{}
+	cmd.SetIn(strings.NewReader(`definition user {}
+
+definition document {
+	relation viewer: user
+	relation editor: user
+	permission view = viewer
+}`))
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{})
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "found 1 lint warning(s)")
+	require.Equal(t, "stdin:5:2: document#editor: relation is not referenced by any permission or subject type\n", out.String())
+
+	out.Reset()
+	cmd.SetIn(strings.NewReader(formattedSchema))
+	require.NoError(t, cmd.Execute())
+	require.Empty(t, out.String())
+
+	// A schema split across files is linted as a whole, with warnings located in their file.
+	dir := t.TempDir()
+	usersFile := filepath.Join(dir, "users.zed")
+	require.NoError(t, os.WriteFile(usersFile, []byte("definition user {}\n"), 0o600))
+	documentsFile := filepath.Join(dir, "documents.zed")
+	require.NoError(t, os.WriteFile(documentsFile, []byte(`definition document {
+	relation viewer: user
+	relation editor: user
+	permission view = viewer
+}`), 0o600))
+
+	out.Reset()
+	cmd.SetArgs([]string{usersFile, documentsFile})
+	require.EqualError(t, cmd.Execute(), "found 1 lint warning(s)")
+	require.Equal(t, documentsFile+":3:2: document#editor: relation is not referenced by any permission or subject type\n", out.String())
+}
+
+func TestSchemaGraphCommand(t *testing.T) {
+	cmd := NewSchemaGraphCommand("spicedb")
+	RegisterSchemaGraphFlags(cmd)
+	cmd.PreRunE = nil
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	out := &bytes.Buffer{}
+	cmd.SetIn(strings.NewReader(formattedSchema))
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"--format", "mermaid"})
+
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, out.String(), "flowchart LR\n")
+	require.Contains(t, out.String(), "\tn3 --> n2\n")
+
+	cmd.SetIn(strings.NewReader(formattedSchema))
+	cmd.SetArgs([]string{"--format", "svg"})
+	require.ErrorContains(t, cmd.Execute(), `unknown graph format "svg"`)
+}
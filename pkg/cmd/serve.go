@@ -3,11 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 
+	"github.com/authzed/spicedb/internal/changepublisher"
+	"github.com/authzed/spicedb/internal/groupsync"
+	"github.com/authzed/spicedb/internal/leaderelection"
+	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/internal/telemetry"
+	"github.com/authzed/spicedb/internal/webhooks"
+	"github.com/authzed/spicedb/pkg/cmd/configfile"
 	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
@@ -16,6 +25,21 @@ import (
 
 const PresharedKeyFlag = "grpc-preshared-key"
 
+// reloadableFlags are the flags which are reloaded from the config file when the serve command
+// receives a SIGHUP. The server has no rate limits; the limits on the work of each request are
+// the dispatch depth and concurrency limits.
+var reloadableFlags = []string{
+	"dispatch-max-depth",
+	"dispatch-concurrency-limit",
+	"dispatch-check-permission-concurrency-limit",
+	"dispatch-lookup-resources-concurrency-limit",
+	"dispatch-lookup-subjects-concurrency-limit",
+	"dispatch-reachable-resources-concurrency-limit",
+	"write-relationships-max-updates-per-call",
+	"update-relationships-max-preconditions-per-call",
+	"log-level",
+}
+
 var (
 	namespaceCacheDefaults = &server.CacheConfig{
 		Name:        "namespace",
@@ -25,6 +49,14 @@ var (
 		MaxCost:     "32MiB",
 	}
 
+	relationshipCacheDefaults = &server.CacheConfig{
+		Name:        "relationship",
+		Enabled:     false,
+		Metrics:     true,
+		NumCounters: 10_000,
+		MaxCost:     "10%",
+	}
+
 	dispatchCacheDefaults = &server.CacheConfig{
 		Name:        "dispatch",
 		Enabled:     true,
@@ -50,14 +82,31 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	// Flags for logging
 	cmd.Flags().BoolVar(&config.EnableRequestLogs, "grpc-log-requests-enabled", false, "logs API request payloads")
 	cmd.Flags().BoolVar(&config.EnableResponseLogs, "grpc-log-responses-enabled", false, "logs API response payloads")
+	cmd.Flags().BoolVar(&config.EnableAccessLogs, "grpc-access-log-enabled", false, "logs a structured summary of each API request, including caller, revision and dispatch count")
+	cmd.Flags().Float64Var(&config.AccessLogSampleRate, "grpc-access-log-sample-rate", 1.0, "fraction of successful API requests to include in the access log; failed requests are always logged")
+	cmd.Flags().DurationVar(&config.SlowQueryThreshold, "slow-query-log-threshold", 0, "logs datastore queries and dispatched subproblems which take at least this long, with their filters, result counts and durations (0 to disable)")
+
+	// Flags for the audit log
+	cmd.Flags().StringVar(&config.AuditLogFilePath, "audit-log-file-path", "", "file to which audit records of writes, deletes, imports and schema changes are appended as JSON lines")
+	cmd.Flags().BoolVar(&config.AuditLogSyslogEnabled, "audit-log-syslog-enabled", false, "write audit records to syslog")
+	cmd.Flags().StringVar(&config.AuditLogSyslogNetwork, "audit-log-syslog-network", "", "network of the syslog server (\"udp\", \"tcp\"); the local syslog is used if empty")
+	cmd.Flags().StringVar(&config.AuditLogSyslogAddress, "audit-log-syslog-address", "", "address of the syslog server; the local syslog is used if empty")
+	cmd.Flags().StringVar(&config.AuditLogHTTPURL, "audit-log-http-url", "", "URL to which audit records are POSTed in batches as JSON lines")
+	cmd.Flags().BoolVar(&config.AuditLogCheckDecisions, "audit-log-check-decisions", false, "also record the decisions of CheckPermission requests, and of each item of bulk permission checks, in the audit log")
 
 	// Flags for the gRPC API server
 	util.RegisterGRPCServerFlags(cmd.Flags(), &config.GRPCServer, "grpc", "gRPC", ":50051", true)
 	cmd.Flags().StringSliceVar(&config.PresharedSecureKey, PresharedKeyFlag, []string{}, "preshared key(s) to require for authenticated requests")
+	cmd.Flags().StringVar(&config.GRPCJWTIssuer, "grpc-jwt-issuer", "", "issuer of JWTs accepted for authenticated requests, as an alternative to preshared keys (a preshared key is still required for dispatch and the ext_authz adapter)")
+	cmd.Flags().StringVar(&config.GRPCJWTAudience, "grpc-jwt-audience", "", "audience that accepted JWTs must be intended for")
+	cmd.Flags().StringVar(&config.GRPCJWTJWKSURL, "grpc-jwt-jwks-url", "", "URL of the JSON Web Key Set used to verify JWTs (defaults to the one found via the issuer's OpenID Connect discovery document)")
+	cmd.Flags().StringVar(&config.GRPCJWTIdentityClaim, "grpc-jwt-identity-claim", "sub", "JWT claim holding the identity of the caller")
+	cmd.Flags().StringArrayVar(&config.GRPCTenantPrefixes, "grpc-tenant-prefixes", []string{}, "binds a preshared key or JWT identity to the object type prefixes it is restricted to, as `<key or identity>=<prefix>[,<prefix>...]` (callers without a binding are not restricted)")
+	cmd.Flags().Uint64Var(&config.GRPCTenantMaxRelationships, "grpc-tenant-max-relationships", 0, "maximum number of relationships each tenant bound by --grpc-tenant-prefixes can store (0 for unlimited)")
+	cmd.Flags().Uint64Var(&config.GRPCTenantMaxObjectDefinitions, "grpc-tenant-max-object-definitions", 0, "maximum number of object definitions in the schema of each tenant bound by --grpc-tenant-prefixes (0 for unlimited)")
+	cmd.Flags().Float64Var(&config.GRPCTenantMaxRequestsPerSecond, "grpc-tenant-max-requests-per-second", 0, "maximum rate of API requests of each tenant bound by --grpc-tenant-prefixes (0 for unlimited)")
 	cmd.Flags().DurationVar(&config.ShutdownGracePeriod, "grpc-shutdown-grace-period", 0*time.Second, "amount of time after receiving sigint to continue serving")
-	if err := cmd.MarkFlagRequired(PresharedKeyFlag); err != nil {
-		return fmt.Errorf("failed to mark flag as required: %w", err)
-	}
+	cmd.MarkFlagsOneRequired(PresharedKeyFlag, "grpc-jwt-issuer")
 
 	// Flags for the datastore
 	if err := datastore.RegisterDatastoreFlags(cmd, &config.DatastoreConfig); err != nil {
@@ -74,6 +123,9 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	}
 	server.RegisterCacheFlags(cmd.Flags(), "ns-cache", &config.NamespaceCacheConfig, namespaceCacheDefaults)
 
+	// Flags for the relationship cache
+	server.RegisterCacheFlags(cmd.Flags(), "relationship-cache", &config.RelationshipCacheConfig, relationshipCacheDefaults)
+
 	cmd.Flags().BoolVar(&config.EnableExperimentalWatchableSchemaCache, "enable-experimental-watchable-schema-cache", false, "enables the experimental schema cache which makes use of the Watch API for automatic updates")
 	cmd.Flags().DurationVar(&config.SchemaWatchHeartbeat, "datastore-schema-watch-heartbeat", 1*time.Second, "heartbeat time on the schema watch in the datastore (if supported). 0 means to default to the datastore's minimum.")
 
@@ -94,6 +146,13 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	if err := cmd.Flags().MarkHidden("http-cors-enabled"); err != nil {
 		return fmt.Errorf("failed to mark flag as hidden: %w", err)
 	}
+	cmd.Flags().BoolVar(&config.HTTPGatewayGraphQLEnabled, "http-graphql-enabled", false, "serve a GraphQL endpoint for permission queries at /graphql on the http gateway")
+	cmd.Flags().BoolVar(&config.HTTPGatewayOPAEnabled, "http-opa-enabled", false, "serve relationship snapshots as OPA data at /opa/bundle.tar.gz and /opa/data on the http gateway")
+	cmd.Flags().BoolVar(&config.HTTPGatewayLookupWatchEnabled, "http-lookupwatch-enabled", false, "experimental: stream changes of permissions on resources at /v1/experimental/lookupwatch on the http gateway")
+	cmd.Flags().BoolVar(&config.HTTPGatewaySimulateEnabled, "http-simulate-enabled", false, "experimental: preview the effect of schema and relationship changes at /v1/experimental/simulate on the http gateway")
+	cmd.Flags().BoolVar(&config.HTTPGatewayCapabilitiesEnabled, "http-capabilities-enabled", false, "experimental: list the permissions of a subject on resources at /v1/experimental/capabilities on the http gateway")
+	cmd.Flags().BoolVar(&config.HTTPGatewayKetoEnabled, "http-keto-enabled", false, "serve the read and check APIs of Ory Keto at /relation-tuples on the http gateway, to ease migrating off Keto")
+	cmd.Flags().StringVar(&config.HTTPGatewayKetoSubjectType, "http-keto-subject-type", "user", "object type onto which the subject IDs of Keto relation tuples are mapped")
 	cmd.Flags().StringSliceVar(&config.HTTPGatewayCorsAllowedOrigins, "http-cors-allowed-origins", []string{"*"}, "Set CORS allowed origins for http gateway, defaults to all origins")
 	if err := cmd.Flags().MarkHidden("http-cors-allowed-origins"); err != nil {
 		return fmt.Errorf("failed to mark flag as hidden: %w", err)
@@ -108,6 +167,8 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	cmd.Flags().Uint32Var(&config.DispatchMaxDepth, "dispatch-max-depth", 50, "maximum recursion depth for nested calls")
 	cmd.Flags().StringVar(&config.DispatchUpstreamAddr, "dispatch-upstream-addr", "", "upstream grpc address to dispatch to")
 	cmd.Flags().StringVar(&config.DispatchUpstreamCAPath, "dispatch-upstream-ca-path", "", "local path to the TLS CA used when connecting to the dispatch cluster")
+	cmd.Flags().StringVar(&config.DispatchUpstreamTLSCertPath, "dispatch-upstream-tls-cert-path", "", "local path to the TLS client certificate presented when connecting to the dispatch cluster; use with --dispatch-cluster-mtls-client-ca-path for mutual TLS")
+	cmd.Flags().StringVar(&config.DispatchUpstreamTLSKeyPath, "dispatch-upstream-tls-key-path", "", "local path to the TLS client key used when connecting to the dispatch cluster")
 	cmd.Flags().DurationVar(&config.DispatchUpstreamTimeout, "dispatch-upstream-timeout", 60*time.Second, "maximum duration of a dispatch call an upstream cluster before it times out")
 
 	cmd.Flags().Uint16Var(&config.GlobalDispatchConcurrencyLimit, "dispatch-concurrency-limit", 50, "maximum number of parallel goroutines to create for each request or subrequest")
@@ -140,11 +201,44 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 
 	// Flags for misc services
 	util.RegisterHTTPServerFlags(cmd.Flags(), &config.MetricsAPI, "metrics", "metrics", ":9090", true)
+	cmd.Flags().BoolVar(&config.MetricsAPICacheFlushEnabled, "metrics-cache-flush-enabled", false, "serve POST /admin/caches/flush on the metrics server, flushing the caches of the node for callers holding a preshared key not bound to a tenant")
+	util.RegisterGRPCServerFlags(cmd.Flags(), &config.ExtAuthzServer, "ext-authz", "Envoy ext_authz", ":50054", false)
+	cmd.Flags().StringVar(&config.ExtAuthzConfigPath, "ext-authz-config-path", "", "path to the YAML file of rules mapping HTTP requests onto permission checks for the Envoy ext_authz adapter")
 
 	if err := util.RegisterDeprecatedHTTPServerFlags(cmd, "dashboard", "dashboard"); err != nil {
 		return err
 	}
 
+	// Flags for leader election
+	cmd.Flags().BoolVar(&config.LeaderElectionEnabled, "leader-election-enabled", false, "elect a single replica, using Kubernetes Leases, to deliver webhooks, to publish changes and to synchronize groups; required when running more than one replica with any of them")
+	cmd.Flags().StringVar(&config.LeaderElectionNamespace, "leader-election-namespace", "", "namespace of the Kubernetes Leases used for leader election; defaults to the namespace of the pod")
+	cmd.Flags().StringVar(&config.LeaderElectionLeasePrefix, "leader-election-lease-prefix", leaderelection.DefaultLeasePrefix, "prefix of the names of the Kubernetes Leases used for leader election")
+	cmd.Flags().DurationVar(&config.LeaderElectionLeaseDuration, "leader-election-lease-duration", leaderelection.DefaultLeaseDuration, "amount of time a Lease that is not renewed is held before another replica may acquire it")
+
+	// Flags for webhooks
+	cmd.Flags().StringSliceVar(&config.WebhookURLs, "webhook-urls", nil, "URLs to which relationship and schema changes are POSTed as batched JSON events")
+	cmd.Flags().StringVar(&config.WebhookSecret, "webhook-secret", "", "secret used to sign the timestamp and body of webhook requests with HMAC-SHA256 in the X-SpiceDB-Signature header")
+	cmd.Flags().IntVar(&config.WebhookBatchSize, "webhook-batch-size", webhooks.DefaultBatchSize, "maximum number of change events delivered in a single webhook request")
+	cmd.Flags().DurationVar(&config.WebhookFlushInterval, "webhook-flush-interval", webhooks.DefaultFlushInterval, "maximum amount of time change events are held before being delivered to webhooks")
+	cmd.Flags().Uint64Var(&config.WebhookMaxRetries, "webhook-max-retries", webhooks.DefaultMaxRetries, "number of times a failed webhook delivery is retried before its events are dropped")
+
+	// Flags for change publishing
+	cmd.Flags().StringSliceVar(&config.ChangePublisherKafkaBrokers, "change-publisher-kafka-brokers", nil, "Kafka brokers to which relationship and schema changes are published")
+	cmd.Flags().StringVar(&config.ChangePublisherKafkaTopic, "change-publisher-kafka-topic", "spicedb-changes", "Kafka topic to which changes are published")
+	cmd.Flags().StringVar(&config.ChangePublisherNATSURL, "change-publisher-nats-url", "", "NATS server to which relationship and schema changes are published using JetStream")
+	cmd.Flags().StringVar(&config.ChangePublisherNATSSubject, "change-publisher-nats-subject", "spicedb.changes", "NATS subject to which changes are published")
+	cmd.Flags().IntVar(&config.ChangePublisherBatchSize, "change-publisher-batch-size", changepublisher.DefaultBatchSize, "maximum number of revisions published at once")
+
+	// Flags for group synchronization
+	cmd.Flags().StringVar(&config.GroupSyncSCIMURL, "group-sync-scim-url", "", "base URL of a SCIM 2.0 service whose group memberships are mirrored into relationships")
+	cmd.Flags().StringVar(&config.GroupSyncSCIMToken, "group-sync-scim-token", "", "bearer token used to authenticate to the SCIM service")
+	cmd.Flags().DurationVar(&config.GroupSyncInterval, "group-sync-interval", groupsync.DefaultInterval, "amount of time between group synchronizations")
+	cmd.Flags().StringVar(&config.GroupSyncGroupType, "group-sync-group-type", groupsync.DefaultGroupType, "object type of synchronized groups")
+	cmd.Flags().StringVar(&config.GroupSyncUserType, "group-sync-user-type", groupsync.DefaultUserType, "object type of the users of synchronized groups")
+	cmd.Flags().StringVar(&config.GroupSyncMemberRelation, "group-sync-member-relation", groupsync.DefaultMemberRelation, "relation or permission of groups relating all their members, through which the members of nested groups are related")
+	cmd.Flags().StringVar(&config.GroupSyncSyncedRelation, "group-sync-synced-relation", groupsync.DefaultSyncedRelation, "relation of groups dedicated to synchronized memberships; its relationships to users and groups not found in the SCIM service are deleted")
+	cmd.Flags().Float64Var(&config.GroupSyncMaxDeleteFraction, "group-sync-max-delete-fraction", groupsync.DefaultMaxDeleteFraction, "maximum fraction of the synchronized memberships a group synchronization may delete; synchronizations deleting more are refused")
+
 	// Flags for telemetry
 	cmd.Flags().StringVar(&config.TelemetryEndpoint, "telemetry-endpoint", telemetry.DefaultEndpoint, "endpoint to which telemetry is reported, empty string to disable")
 	cmd.Flags().StringVar(&config.TelemetryCAOverridePath, "telemetry-ca-override-path", "", "TODO")
@@ -168,8 +262,38 @@ func NewServeCommand(programName string, config *server.Config) *cobra.Command {
 				context.Background(),
 				config.ShutdownGracePeriod,
 			)
+			OnHangup(signalctx, func() {
+				if err := reloadServeConfig(signalctx, cmd, config, server); err != nil {
+					log.Ctx(signalctx).Error().Err(err).Msg("failed to reload configuration")
+				}
+			})
 			return server.Run(signalctx)
 		}),
 		Example: server.ServeExample(programName),
 	}
 }
+
+// reloadServeConfig reloads the reloadable flags from the config file and applies them to the
+// running server. Flags given on the command line or in the environment are left unchanged.
+func reloadServeConfig(ctx context.Context, cmd *cobra.Command, config *server.Config, runnable server.RunnableServer) error {
+	changed, err := configfile.Reload(cmd.Flags(), reloadableFlags...)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range changed {
+		if name != "log-level" {
+			continue
+		}
+
+		level, err := zerolog.ParseLevel(strings.ToLower(cobrautil.MustGetString(cmd, "log-level")))
+		if err != nil {
+			return fmt.Errorf("invalid log level: %w", err)
+		}
+		log.SetGlobalLogger(log.Logger.Level(level))
+		log.Ctx(ctx).Info().Stringer("log_level", level).Msg("reloaded log level")
+	}
+
+	runnable.ReloadRuntimeLimits(ctx, config)
+	return nil
+}
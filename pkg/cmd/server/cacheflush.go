@@ -0,0 +1,53 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/authzed/spicedb/internal/datastore/proxy/schemacaching"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cache"
+)
+
+// CacheFlushPath is the path under which the caches of the node are flushed by the admin API.
+const CacheFlushPath = "/admin/caches/flush"
+
+// cacheFlushResponse is the body returned once the caches were flushed.
+type cacheFlushResponse struct {
+	Caches     []string `json:"caches"`
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// newCacheFlushHandler returns an http.Handler flushing the cached schema definitions and the
+// given caches of this node, so that an out-of-band fix of the datastore is seen immediately.
+//
+// The definitions to flush can be selected by repeating the `namespace` query parameter, and all
+// of them are flushed otherwise. The other caches are always flushed entirely, as the results
+// they hold can depend on any definition.
+func newCacheFlushHandler(definitions schemacaching.Flushable, caches map[string]cache.Cache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "caches must be flushed with POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		resp := cacheFlushResponse{Namespaces: r.URL.Query()["namespace"]}
+		if definitions != nil {
+			definitions.FlushDefinitions(resp.Namespaces...)
+			resp.Caches = append(resp.Caches, "namespace")
+		}
+
+		for name, c := range caches {
+			c.Clear()
+			resp.Caches = append(resp.Caches, name)
+		}
+		sort.Strings(resp.Caches)
+
+		log.Ctx(r.Context()).Info().Strs("caches", resp.Caches).Strs("namespaces", resp.Namespaces).Msg("flushed caches")
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
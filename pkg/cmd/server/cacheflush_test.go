@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/cache"
+)
+
+type fakeFlushable struct {
+	flushed [][]string
+}
+
+func (f *fakeFlushable) FlushDefinitions(names ...string) {
+	f.flushed = append(f.flushed, names)
+}
+
+func TestCacheFlushHandler(t *testing.T) {
+	dispatchCache, err := cache.NewCache(&cache.Config{NumCounters: 100, MaxCost: 1000})
+	require.NoError(t, err)
+
+	definitions := &fakeFlushable{}
+	handler := newCacheFlushHandler(definitions, map[string]cache.Cache{"dispatch": dispatchCache})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, CacheFlushPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	require.Empty(t, definitions.flushed)
+
+	require.True(t, dispatchCache.Set("somekey", "somevalue", 1))
+	dispatchCache.Wait()
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, CacheFlushPath+"?namespace=document&namespace=user", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp cacheFlushResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, cacheFlushResponse{
+		Caches:     []string{"dispatch", "namespace"},
+		Namespaces: []string{"document", "user"},
+	}, resp)
+	require.Equal(t, [][]string{{"document", "user"}}, definitions.flushed)
+
+	_, found := dispatchCache.Get("somekey")
+	require.False(t, found)
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/authzed/spicedb/internal/dispatch/peers"
+	"github.com/authzed/spicedb/pkg/cache"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// ClusterStatePath is the path under which the state of the cluster, as seen by the node, is
+// served by the admin API.
+const ClusterStatePath = "/admin/cluster"
+
+// clusterState is the state of the cluster as seen by a node. Each node only reports its own view,
+// so comparing the state served by every node of the cluster is the way to find which of them
+// disagree on the ring or on the head revision.
+type clusterState struct {
+	Node              string       `json:"node"`
+	HeadRevision      string       `json:"headRevision,omitempty"`
+	OptimizedRevision string       `json:"optimizedRevision,omitempty"`
+	RevisionError     string       `json:"revisionError,omitempty"`
+	Rings             []peers.Ring `json:"rings"`
+	Caches            []cacheState `json:"caches"`
+}
+
+// cacheState is the usage of a cache of the node since the start of the process.
+type cacheState struct {
+	Name     string  `json:"name"`
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hitRatio"`
+	Cost     uint64  `json:"cost"`
+}
+
+// newClusterStateHandler returns an http.Handler serving the state of the cluster as JSON: the
+// dispatch rings known to the node with the health of their peers, the usage of the caches of the
+// node, and the revisions the node considers to be the head of the datastore.
+func newClusterStateHandler(ds datastore.Datastore, rings func() []peers.Ring, caches map[string]cache.Cache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "cluster state must be requested with GET", http.StatusMethodNotAllowed)
+			return
+		}
+
+		state := clusterState{Rings: rings(), Caches: make([]cacheState, 0, len(caches))}
+		state.Node, _ = os.Hostname()
+
+		// The rest of the state is still served when the datastore is unavailable, as it is most
+		// useful to debug exactly that.
+		if head, err := ds.HeadRevision(r.Context()); err != nil {
+			state.RevisionError = err.Error()
+		} else if optimized, err := ds.OptimizedRevision(r.Context()); err != nil {
+			state.RevisionError = err.Error()
+		} else {
+			state.HeadRevision = head.String()
+			state.OptimizedRevision = optimized.String()
+		}
+
+		for name, c := range caches {
+			metrics := c.GetMetrics()
+			cs := cacheState{
+				Name:   name,
+				Hits:   metrics.Hits(),
+				Misses: metrics.Misses(),
+			}
+			if lookups := cs.Hits + cs.Misses; lookups > 0 {
+				cs.HitRatio = float64(cs.Hits) / float64(lookups)
+			}
+			if added, evicted := metrics.CostAdded(), metrics.CostEvicted(); added > evicted {
+				cs.Cost = added - evicted
+			}
+			state.Caches = append(state.Caches, cs)
+		}
+		sort.Slice(state.Caches, func(i, j int) bool { return state.Caches[i].Name < state.Caches[j].Name })
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(state)
+	})
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch/peers"
+	"github.com/authzed/spicedb/pkg/cache"
+)
+
+func TestClusterStateHandler(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	dispatchCache, err := cache.NewCache(&cache.Config{NumCounters: 100, MaxCost: 1000})
+	require.NoError(t, err)
+
+	rings := []peers.Ring{{
+		Target:            "kubernetes:///spicedb.default:50053",
+		ReplicationFactor: 100,
+		Members:           []peers.Member{{Key: "10.0.0.1:50053", State: "READY", Ownership: 1}},
+	}}
+	handler := newClusterStateHandler(ds, func() []peers.Ring { return rings }, map[string]cache.Cache{
+		"dispatch":  dispatchCache,
+		"namespace": cache.NoopCache(),
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, ClusterStatePath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ClusterStatePath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var state clusterState
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+	require.NotEmpty(t, state.HeadRevision)
+	require.NotEmpty(t, state.OptimizedRevision)
+	require.Empty(t, state.RevisionError)
+	require.Equal(t, rings, state.Rings)
+	require.Equal(t, []cacheState{{Name: "dispatch"}, {Name: "namespace"}}, state.Caches)
+}
@@ -29,12 +29,17 @@ import (
 
 	"github.com/authzed/authzed-go/pkg/requestmeta"
 
+	"github.com/authzed/spicedb/internal/audit"
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/accesslog"
 	consistencymw "github.com/authzed/spicedb/internal/middleware/consistency"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	dispatchmw "github.com/authzed/spicedb/internal/middleware/dispatcher"
 	"github.com/authzed/spicedb/internal/middleware/servicespecific"
+	"github.com/authzed/spicedb/internal/middleware/tenant"
+	"github.com/authzed/spicedb/internal/middleware/validation"
+	"github.com/authzed/spicedb/pkg/cmd/configfile"
 	"github.com/authzed/spicedb/pkg/datastore"
 	logmw "github.com/authzed/spicedb/pkg/middleware/logging"
 	"github.com/authzed/spicedb/pkg/middleware/requestid"
@@ -61,11 +66,12 @@ func ServeExample(programName string) string {
 	)
 }
 
-// DefaultPreRunE sets up viper, zerolog, and OpenTelemetry flag handling for a
+// DefaultPreRunE sets up viper, config file, zerolog, and OpenTelemetry flag handling for a
 // command.
 func DefaultPreRunE(programName string) cobrautil.CobraRunFunc {
 	return cobrautil.CommandStack(
 		cobrautil.SyncViperDotEnvPreRunE(programName, "spicedb.env", zerologr.New(&logging.Logger)),
+		configfile.RunE(),
 		cobrazerolog.New(
 			cobrazerolog.WithTarget(func(logger zerolog.Logger) {
 				logging.SetGlobalLogger(logger)
@@ -151,9 +157,13 @@ const (
 	DefaultMiddlewareGRPCProm      = "grpcprom"
 	DefaultMiddlewareServerVersion = "serverversion"
 
+	DefaultInternalMiddlewareValidation     = "validation"
 	DefaultInternalMiddlewareDispatch       = "dispatch"
 	DefaultInternalMiddlewareDatastore      = "datastore"
+	DefaultInternalMiddlewareTenant         = "tenant"
 	DefaultInternalMiddlewareConsistency    = "consistency"
+	DefaultInternalMiddlewareAccessLog      = "accesslog"
+	DefaultInternalMiddlewareAudit          = "audit"
 	DefaultInternalMiddlewareServerSpecific = "servicespecific"
 )
 
@@ -166,6 +176,10 @@ type MiddlewareOption struct {
 	enableRequestLog      bool
 	enableResponseLog     bool
 	disableGRPCHistogram  bool
+	accessLogSampleRate   float64
+	auditLogger           *audit.Logger
+	tenantBindings        tenant.Bindings
+	tenantUsage           *tenant.UsageTracker
 }
 
 // gRPCMetricsUnaryInterceptor creates the default prometheus metrics interceptor for unary gRPCs
@@ -239,6 +253,12 @@ func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryS
 			WithInterceptor(grpcMetricsUnaryInterceptor).
 			Done(),
 
+		NewUnaryMiddleware().
+			WithName(DefaultInternalMiddlewareAccessLog).
+			WithInternal(true).
+			WithInterceptor(accesslog.UnaryServerInterceptor(opts.accessLogSampleRate)).
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultMiddlewareGRPCAuth).
 			WithInterceptor(grpcauth.UnaryServerInterceptor(opts.authFunc)).
@@ -250,6 +270,12 @@ func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryS
 			WithInterceptor(serverversion.UnaryServerInterceptor(opts.enableVersionResponse)).
 			Done(),
 
+		NewUnaryMiddleware().
+			WithName(DefaultInternalMiddlewareValidation).
+			WithInternal(true).
+			WithInterceptor(validation.UnaryServerInterceptor()).
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultInternalMiddlewareDispatch).
 			WithInternal(true).
@@ -262,12 +288,25 @@ func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryS
 			WithInterceptor(datastoremw.UnaryServerInterceptor(opts.ds)).
 			Done(),
 
+		NewUnaryMiddleware().
+			WithName(DefaultInternalMiddlewareTenant).
+			WithInternal(true).
+			WithInterceptor(tenant.UnaryServerInterceptor(opts.tenantBindings, opts.tenantUsage)).
+			EnsureAlreadyExecuted(DefaultInternalMiddlewareDatastore). // so that the scoped datastore replaces the one set in context
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultInternalMiddlewareConsistency).
 			WithInternal(true).
 			WithInterceptor(consistencymw.UnaryServerInterceptor()).
 			Done(),
 
+		NewUnaryMiddleware().
+			WithName(DefaultInternalMiddlewareAudit).
+			WithInternal(true).
+			WithInterceptor(audit.UnaryServerInterceptor(opts.auditLogger)).
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultInternalMiddlewareServerSpecific).
 			WithInternal(true).
@@ -317,6 +356,12 @@ func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.St
 			WithInterceptor(grpcMetricsStreamingInterceptor).
 			Done(),
 
+		NewStreamMiddleware().
+			WithName(DefaultInternalMiddlewareAccessLog).
+			WithInternal(true).
+			WithInterceptor(accesslog.StreamServerInterceptor(opts.accessLogSampleRate)).
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultMiddlewareGRPCAuth).
 			WithInterceptor(grpcauth.StreamServerInterceptor(opts.authFunc)).
@@ -328,6 +373,12 @@ func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.St
 			WithInterceptor(serverversion.StreamServerInterceptor(opts.enableVersionResponse)).
 			Done(),
 
+		NewStreamMiddleware().
+			WithName(DefaultInternalMiddlewareValidation).
+			WithInternal(true).
+			WithInterceptor(validation.StreamServerInterceptor()).
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultInternalMiddlewareDispatch).
 			WithInternal(true).
@@ -340,12 +391,25 @@ func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.St
 			WithInterceptor(datastoremw.StreamServerInterceptor(opts.ds)).
 			Done(),
 
+		NewStreamMiddleware().
+			WithName(DefaultInternalMiddlewareTenant).
+			WithInternal(true).
+			WithInterceptor(tenant.StreamServerInterceptor(opts.tenantBindings, opts.tenantUsage)).
+			EnsureInterceptorAlreadyExecuted(DefaultInternalMiddlewareDatastore). // so that the scoped datastore replaces the one set in context
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultInternalMiddlewareConsistency).
 			WithInternal(true).
 			WithInterceptor(consistencymw.StreamServerInterceptor()).
 			Done(),
 
+		NewStreamMiddleware().
+			WithName(DefaultInternalMiddlewareAudit).
+			WithInternal(true).
+			WithInterceptor(audit.StreamServerInterceptor(opts.auditLogger)).
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultInternalMiddlewareServerSpecific).
 			WithInternal(true).
@@ -379,6 +443,7 @@ func DefaultDispatchMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc
 			grpclog.UnaryServerInterceptor(InterceptorLogger(logger), defaultCodeToLevel, durationFieldOption, traceIDFieldOption),
 			grpcMetricsUnaryInterceptor,
 			grpcauth.UnaryServerInterceptor(authFunc),
+			validation.UnaryServerInterceptor(),
 			datastoremw.UnaryServerInterceptor(ds),
 			servicespecific.UnaryServerInterceptor,
 		}, []grpc.StreamServerInterceptor{
@@ -387,6 +452,7 @@ func DefaultDispatchMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc
 			grpclog.StreamServerInterceptor(InterceptorLogger(logger), defaultCodeToLevel, durationFieldOption, traceIDFieldOption),
 			grpcMetricsStreamingInterceptor,
 			grpcauth.StreamServerInterceptor(authFunc),
+			validation.StreamServerInterceptor(),
 			datastoremw.StreamServerInterceptor(ds),
 			servicespecific.StreamServerInterceptor,
 		}
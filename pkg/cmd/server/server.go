@@ -2,17 +2,22 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/consistent"
 	"github.com/authzed/grpcutil"
 	"github.com/cespare/xxhash/v2"
 	"github.com/ecordell/optgen/helpers"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"github.com/hashicorp/go-multierror"
 	"github.com/prometheus/client_golang/prometheus"
@@ -24,20 +29,29 @@ import (
 	"google.golang.org/grpc"
 	_ "google.golang.org/grpc/encoding/gzip" // enable gzip compression on all derivative servers
 
+	"github.com/authzed/spicedb/internal/audit"
 	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/internal/changepublisher"
 	"github.com/authzed/spicedb/internal/datastore/proxy"
 	"github.com/authzed/spicedb/internal/datastore/proxy/schemacaching"
 	"github.com/authzed/spicedb/internal/dispatch"
 	clusterdispatch "github.com/authzed/spicedb/internal/dispatch/cluster"
 	combineddispatch "github.com/authzed/spicedb/internal/dispatch/combined"
 	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/dispatch/peers"
+	"github.com/authzed/spicedb/internal/extauthz"
 	"github.com/authzed/spicedb/internal/gateway"
+	"github.com/authzed/spicedb/internal/groupsync"
+	"github.com/authzed/spicedb/internal/leaderelection"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/tenant"
 	"github.com/authzed/spicedb/internal/services"
 	dispatchSvc "github.com/authzed/spicedb/internal/services/dispatch"
 	"github.com/authzed/spicedb/internal/services/health"
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
 	"github.com/authzed/spicedb/internal/telemetry"
+	"github.com/authzed/spicedb/internal/webhooks"
+	"github.com/authzed/spicedb/pkg/cache"
 	datastorecfg "github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -46,8 +60,9 @@ import (
 )
 
 // ConsistentHashringBuilder is a balancer Builder that uses xxhash as the
-// underlying hash for the ConsistentHashringBalancers it creates.
-var ConsistentHashringBuilder = consistent.NewBuilder(xxhash.Sum64)
+// underlying hash for the ConsistentHashringBalancers it creates. The rings
+// of the balancers it creates are reported by the cluster state admin API.
+var ConsistentHashringBuilder = peers.NewBuilder(xxhash.Sum64)
 
 //go:generate go run github.com/ecordell/optgen -output zz_generated.options.go . Config
 type Config struct {
@@ -55,15 +70,32 @@ type Config struct {
 	GRPCServer             util.GRPCServerConfig `debugmap:"visible"`
 	GRPCAuthFunc           grpc_auth.AuthFunc    `debugmap:"visible"`
 	PresharedSecureKey     []string              `debugmap:"sensitive"`
+	GRPCJWTIssuer          string                `debugmap:"visible"`
+	GRPCJWTAudience        string                `debugmap:"visible"`
+	GRPCJWTJWKSURL         string                `debugmap:"visible"`
+	GRPCJWTIdentityClaim   string                `debugmap:"visible"`
+	GRPCTenantPrefixes     []string              `debugmap:"sensitive"`
 	ShutdownGracePeriod    time.Duration         `debugmap:"visible"`
 	DisableVersionResponse bool                  `debugmap:"visible"`
 
+	// Tenant quotas
+	GRPCTenantMaxRelationships     uint64  `debugmap:"visible"`
+	GRPCTenantMaxObjectDefinitions uint64  `debugmap:"visible"`
+	GRPCTenantMaxRequestsPerSecond float64 `debugmap:"visible"`
+
 	// GRPC Gateway config
 	HTTPGateway                    util.HTTPServerConfig `debugmap:"visible"`
 	HTTPGatewayUpstreamAddr        string                `debugmap:"visible"`
 	HTTPGatewayUpstreamTLSCertPath string                `debugmap:"visible"`
 	HTTPGatewayCorsEnabled         bool                  `debugmap:"visible"`
 	HTTPGatewayCorsAllowedOrigins  []string              `debugmap:"visible-format"`
+	HTTPGatewayGraphQLEnabled      bool                  `debugmap:"visible"`
+	HTTPGatewayOPAEnabled          bool                  `debugmap:"visible"`
+	HTTPGatewayLookupWatchEnabled  bool                  `debugmap:"visible"`
+	HTTPGatewaySimulateEnabled     bool                  `debugmap:"visible"`
+	HTTPGatewayCapabilitiesEnabled bool                  `debugmap:"visible"`
+	HTTPGatewayKetoEnabled         bool                  `debugmap:"visible"`
+	HTTPGatewayKetoSubjectType     string                `debugmap:"visible"`
 
 	// Datastore
 	DatastoreConfig datastorecfg.Config `debugmap:"visible"`
@@ -78,6 +110,9 @@ type Config struct {
 	SchemaWatchHeartbeat                   time.Duration `debugmap:"visible"`
 	NamespaceCacheConfig                   CacheConfig   `debugmap:"visible"`
 
+	// Relationship cache
+	RelationshipCacheConfig CacheConfig `debugmap:"visible"`
+
 	// Schema options
 	SchemaPrefixesRequired bool `debugmap:"visible"`
 
@@ -88,6 +123,8 @@ type Config struct {
 	DispatchConcurrencyLimits         graph.ConcurrencyLimits `debugmap:"visible"`
 	DispatchUpstreamAddr              string                  `debugmap:"visible"`
 	DispatchUpstreamCAPath            string                  `debugmap:"visible"`
+	DispatchUpstreamTLSCertPath       string                  `debugmap:"visible"`
+	DispatchUpstreamTLSKeyPath        string                  `debugmap:"visible"`
 	DispatchUpstreamTimeout           time.Duration           `debugmap:"visible"`
 	DispatchClientMetricsEnabled      bool                    `debugmap:"visible"`
 	DispatchClientMetricsPrefix       string                  `debugmap:"visible"`
@@ -113,7 +150,42 @@ type Config struct {
 	WatchHeartbeat           time.Duration `debugmap:"visible"`
 
 	// Additional Services
-	MetricsAPI util.HTTPServerConfig `debugmap:"visible"`
+	MetricsAPI                  util.HTTPServerConfig `debugmap:"visible"`
+	MetricsAPICacheFlushEnabled bool                  `debugmap:"visible"`
+
+	// Envoy ext_authz adapter
+	ExtAuthzServer     util.GRPCServerConfig `debugmap:"visible"`
+	ExtAuthzConfigPath string                `debugmap:"visible"`
+
+	// Leader election
+	LeaderElectionEnabled       bool          `debugmap:"visible"`
+	LeaderElectionNamespace     string        `debugmap:"visible"`
+	LeaderElectionLeasePrefix   string        `debugmap:"visible"`
+	LeaderElectionLeaseDuration time.Duration `debugmap:"visible"`
+
+	// Webhooks
+	WebhookURLs          []string      `debugmap:"visible"`
+	WebhookSecret        string        `debugmap:"sensitive"`
+	WebhookBatchSize     int           `debugmap:"visible"`
+	WebhookFlushInterval time.Duration `debugmap:"visible"`
+	WebhookMaxRetries    uint64        `debugmap:"visible"`
+
+	// Change publishing
+	ChangePublisherKafkaBrokers []string `debugmap:"visible"`
+	ChangePublisherKafkaTopic   string   `debugmap:"visible"`
+	ChangePublisherNATSURL      string   `debugmap:"visible"`
+	ChangePublisherNATSSubject  string   `debugmap:"visible"`
+	ChangePublisherBatchSize    int      `debugmap:"visible"`
+
+	// Group synchronization
+	GroupSyncSCIMURL           string        `debugmap:"visible"`
+	GroupSyncSCIMToken         string        `debugmap:"sensitive"`
+	GroupSyncInterval          time.Duration `debugmap:"visible"`
+	GroupSyncGroupType         string        `debugmap:"visible"`
+	GroupSyncUserType          string        `debugmap:"visible"`
+	GroupSyncMemberRelation    string        `debugmap:"visible"`
+	GroupSyncSyncedRelation    string        `debugmap:"visible"`
+	GroupSyncMaxDeleteFraction float64       `debugmap:"visible"`
 
 	// Middleware for grpc API
 	UnaryMiddlewareModification     []MiddlewareModification[grpc.UnaryServerInterceptor]  `debugmap:"hidden"`
@@ -130,8 +202,19 @@ type Config struct {
 	TelemetryInterval        time.Duration `debugmap:"visible"`
 
 	// Logs
-	EnableRequestLogs  bool `debugmap:"visible"`
-	EnableResponseLogs bool `debugmap:"visible"`
+	EnableRequestLogs   bool          `debugmap:"visible"`
+	EnableResponseLogs  bool          `debugmap:"visible"`
+	EnableAccessLogs    bool          `debugmap:"visible"`
+	AccessLogSampleRate float64       `debugmap:"visible"`
+	SlowQueryThreshold  time.Duration `debugmap:"visible"`
+
+	// Audit logs
+	AuditLogFilePath       string `debugmap:"visible"`
+	AuditLogSyslogEnabled  bool   `debugmap:"visible"`
+	AuditLogSyslogNetwork  string `debugmap:"visible"`
+	AuditLogSyslogAddress  string `debugmap:"visible"`
+	AuditLogHTTPURL        string `debugmap:"visible"`
+	AuditLogCheckDecisions bool   `debugmap:"visible"`
 
 	// Metrics
 	DisableGRPCLatencyHistogram bool `debugmap:"visible"`
@@ -191,8 +274,42 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		}
 	}()
 
-	if len(c.PresharedSecureKey) < 1 && c.GRPCAuthFunc == nil {
-		return nil, fmt.Errorf("a preshared key must be provided to authenticate API requests")
+	if len(c.PresharedSecureKey) < 1 && c.GRPCAuthFunc == nil && c.GRPCJWTIssuer == "" {
+		return nil, fmt.Errorf("a preshared key or JWT issuer must be provided to authenticate API requests")
+	}
+
+	// Nodes authenticate to each other, and the ext_authz adapter to the API, with the first
+	// preshared key: JWTs cannot be used for these requests.
+	if len(c.PresharedSecureKey) < 1 && c.GRPCAuthFunc == nil && (c.DispatchServer.Enabled || c.DispatchUpstreamAddr != "" || c.ExtAuthzServer.Enabled) {
+		return nil, fmt.Errorf("a preshared key must be provided when dispatch or the ext_authz adapter is enabled, as it is used to authenticate their requests")
+	}
+
+	// The REST gateway connects to the gRPC server without a client certificate.
+	if c.HTTPGateway.HTTPEnabled && c.GRPCServer.MTLSClientCAPath != "" {
+		return nil, fmt.Errorf("the REST gateway cannot be enabled when the gRPC server requires client certificates, as it does not present one")
+	}
+
+	// dispatchAuthFunc authenticates requests to the dispatch server, which are made with the
+	// preshared key unless a custom auth func was configured.
+	dispatchAuthFunc := c.GRPCAuthFunc
+
+	if c.GRPCAuthFunc == nil && c.GRPCJWTIssuer != "" {
+		log.Ctx(ctx).Trace().Str("issuer", c.GRPCJWTIssuer).Msg("using gRPC auth with JWTs")
+		jwtAuthFunc, err := auth.RequireJWT(auth.JWTConfig{
+			Issuer:        c.GRPCJWTIssuer,
+			Audience:      c.GRPCJWTAudience,
+			JWKSURL:       c.GRPCJWTJWKSURL,
+			IdentityClaim: c.GRPCJWTIdentityClaim,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure JWT auth: %w", err)
+		}
+
+		// Preshared keys remain accepted alongside JWTs.
+		c.GRPCAuthFunc = jwtAuthFunc
+		if len(c.PresharedSecureKey) > 0 {
+			c.GRPCAuthFunc = auth.RequireAnyOf(auth.MustRequirePresharedKey(c.PresharedSecureKey), jwtAuthFunc)
+		}
 	}
 
 	if c.GRPCAuthFunc == nil {
@@ -234,13 +351,35 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		cachingMode = schemacaching.WatchIfSupported
 	}
 
+	rcc, err := c.RelationshipCacheConfig.WithRevisionParameters(
+		c.DatastoreConfig.RevisionQuantization,
+		c.DatastoreConfig.FollowerReadDelay,
+		c.DatastoreConfig.MaxRevisionStalenessPercent,
+	).Complete()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relationship cache: %w", err)
+	}
+	log.Ctx(ctx).Info().EmbedObject(rcc).Msg("configured relationship cache")
+
+	if c.SlowQueryThreshold > 0 {
+		ds = proxy.NewSlowQueryLoggingProxy(ds, c.SlowQueryThreshold)
+	}
 	ds = proxy.NewObservableDatastoreProxy(ds)
 	ds = proxy.NewSingleflightDatastoreProxy(ds)
+	if c.RelationshipCacheConfig.Enabled {
+		ds = proxy.NewRelationshipCachingDatastoreProxy(ds, rcc, c.MaxDatastoreReadPageSize)
+	}
 	ds = schemacaching.NewCachingDatastoreProxy(ds, nscc, c.DatastoreConfig.GCWindow, cachingMode, c.SchemaWatchHeartbeat)
 	closeables.AddWithError(ds.Close)
 
+	// flushableCaches are the caches flushed by the admin API, in addition to the schema cache.
+	flushableCaches := make(map[string]cache.Cache, 3)
+	if c.RelationshipCacheConfig.Enabled {
+		flushableCaches["relationship"] = rcc
+	}
+
 	specificConcurrencyLimits := c.DispatchConcurrencyLimits
-	concurrencyLimits := specificConcurrencyLimits.WithOverallDefaultLimit(c.GlobalDispatchConcurrencyLimit)
+	concurrencyLimits := graph.NewConcurrencyLimitsHolder(specificConcurrencyLimits.WithOverallDefaultLimit(c.GlobalDispatchConcurrencyLimit))
 
 	dispatcher := c.Dispatcher
 	if dispatcher == nil {
@@ -254,6 +393,7 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		}
 		closeables.AddWithoutError(cc.Close)
 		log.Ctx(ctx).Info().EmbedObject(cc).Msg("configured dispatch cache")
+		flushableCaches["dispatch"] = cc
 
 		dispatchPresharedKey := ""
 		if len(c.PresharedSecureKey) > 0 {
@@ -271,6 +411,8 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		dispatcher, err = combineddispatch.NewDispatcher(
 			combineddispatch.UpstreamAddr(c.DispatchUpstreamAddr),
 			combineddispatch.UpstreamCAPath(c.DispatchUpstreamCAPath),
+			combineddispatch.UpstreamTLSCertPath(c.DispatchUpstreamTLSCertPath),
+			combineddispatch.UpstreamTLSKeyPath(c.DispatchUpstreamTLSKeyPath),
 			combineddispatch.SecondaryUpstreamAddrs(c.DispatchSecondaryUpstreamAddrs),
 			combineddispatch.SecondaryUpstreamExprs(c.DispatchSecondaryUpstreamExprs),
 			combineddispatch.GrpcPresharedKey(dispatchPresharedKey),
@@ -287,21 +429,22 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 			combineddispatch.MetricsEnabled(c.DispatchClientMetricsEnabled),
 			combineddispatch.PrometheusSubsystem(c.DispatchClientMetricsPrefix),
 			combineddispatch.Cache(cc),
-			combineddispatch.ConcurrencyLimits(concurrencyLimits),
+			combineddispatch.ConcurrencyLimitsHolder(concurrencyLimits),
+			combineddispatch.SlowDispatchThreshold(c.SlowQueryThreshold),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create dispatcher: %w", err)
 		}
 
-		log.Ctx(ctx).Info().EmbedObject(concurrencyLimits).RawJSON("balancerconfig", []byte(hashringConfigJSON)).Msg("configured dispatcher")
+		log.Ctx(ctx).Info().EmbedObject(concurrencyLimits.Load()).RawJSON("balancerconfig", []byte(hashringConfigJSON)).Msg("configured dispatcher")
 	}
 	closeables.AddWithError(dispatcher.Close)
 
 	if len(c.DispatchUnaryMiddleware) == 0 && len(c.DispatchStreamingMiddleware) == 0 {
-		if c.GRPCAuthFunc == nil {
+		if dispatchAuthFunc == nil {
 			c.DispatchUnaryMiddleware, c.DispatchStreamingMiddleware = DefaultDispatchMiddleware(log.Logger, auth.MustRequirePresharedKey(c.PresharedSecureKey), ds, c.DisableGRPCLatencyHistogram)
 		} else {
-			c.DispatchUnaryMiddleware, c.DispatchStreamingMiddleware = DefaultDispatchMiddleware(log.Logger, c.GRPCAuthFunc, ds, c.DisableGRPCLatencyHistogram)
+			c.DispatchUnaryMiddleware, c.DispatchStreamingMiddleware = DefaultDispatchMiddleware(log.Logger, dispatchAuthFunc, ds, c.DisableGRPCLatencyHistogram)
 		}
 	}
 
@@ -317,6 +460,7 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		}
 		log.Ctx(ctx).Info().EmbedObject(cdcc).Msg("configured cluster dispatch cache")
 		closeables.AddWithoutError(cdcc.Close)
+		flushableCaches["cluster-dispatch"] = cdcc
 
 		cachingClusterDispatch, err = clusterdispatch.NewClusterDispatcher(
 			dispatcher,
@@ -324,7 +468,8 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 			clusterdispatch.PrometheusSubsystem(c.DispatchClusterMetricsPrefix),
 			clusterdispatch.Cache(cdcc),
 			clusterdispatch.RemoteDispatchTimeout(c.DispatchUpstreamTimeout),
-			clusterdispatch.ConcurrencyLimits(concurrencyLimits),
+			clusterdispatch.ConcurrencyLimitsHolder(concurrencyLimits),
+			clusterdispatch.SlowDispatchThreshold(c.SlowQueryThreshold),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to configure cluster dispatch: %w", err)
@@ -363,6 +508,33 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		watchServiceOption = services.WatchServiceDisabled
 	}
 
+	var accessLogSampleRate float64
+	if c.EnableAccessLogs {
+		accessLogSampleRate = c.AccessLogSampleRate
+	}
+
+	auditLogger, err := c.initializeAuditLogger(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if auditLogger != nil {
+		closeables.AddWithError(auditLogger.Close)
+	}
+
+	tenantBindings, err := tenant.ParseBindings(c.GRPCTenantPrefixes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tenant prefixes: %w", err)
+	}
+
+	var tenantUsage *tenant.UsageTracker
+	if len(tenantBindings) > 0 {
+		tenantUsage = tenant.NewUsageTracker(ds, tenantBindings, tenant.Quotas{
+			MaxRelationships:     c.GRPCTenantMaxRelationships,
+			MaxObjectDefinitions: c.GRPCTenantMaxObjectDefinitions,
+			MaxRequestsPerSecond: c.GRPCTenantMaxRequestsPerSecond,
+		})
+	}
+
 	opts := MiddlewareOption{
 		log.Logger,
 		c.GRPCAuthFunc,
@@ -372,6 +544,10 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		c.EnableRequestLogs,
 		c.EnableResponseLogs,
 		c.DisableGRPCLatencyHistogram,
+		accessLogSampleRate,
+		auditLogger,
+		tenantBindings,
+		tenantUsage,
 	}
 	defaultUnaryMiddlewareChain, err := DefaultUnaryMiddleware(opts)
 	if err != nil {
@@ -401,10 +577,9 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		return nil, fmt.Errorf("error building streaming middlewares: %w", err)
 	}
 
+	runtimeLimits := v1svc.NewRuntimeLimitsHolder(c.runtimeLimits())
 	permSysConfig := v1svc.PermissionsServerConfig{
-		MaxPreconditionsCount:      c.MaximumPreconditionCount,
-		MaxUpdatesPerWrite:         c.MaximumUpdatesPerWrite,
-		MaximumAPIDepth:            c.DispatchMaxDepth,
+		RuntimeLimits:              runtimeLimits,
 		MaxCaveatContextSize:       c.MaxCaveatContextSize,
 		MaxRelationshipContextSize: c.MaxRelationshipContextSize,
 		MaxDatastoreReadPageSize:   c.MaxDatastoreReadPageSize,
@@ -430,13 +605,83 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 	}
 	closeables.AddWithoutError(grpcServer.GracefulStop)
 
-	gatewayServer, gatewayCloser, err := c.initializeGateway(ctx)
+	gatewayServer, gatewayCloser, err := c.initializeGateway(ctx, ds)
 	if err != nil {
 		return nil, err
 	}
 	closeables.AddCloser(gatewayCloser)
 	closeables.AddWithoutError(gatewayServer.Close)
 
+	extAuthzServer, extAuthzCloser, err := c.initializeExtAuthz(ctx, grpcServer)
+	if err != nil {
+		return nil, err
+	}
+	closeables.AddCloser(extAuthzCloser)
+	closeables.AddWithoutError(extAuthzServer.GracefulStop)
+
+	elector := leaderelection.Unelected
+	if c.LeaderElectionEnabled {
+		log.Ctx(ctx).Info().Str("prefix", c.LeaderElectionLeasePrefix).Msg("electing replicas to run background tasks using Kubernetes Leases")
+		elector, err = leaderelection.NewInClusterElector(leaderelection.Config{
+			Namespace:     c.LeaderElectionNamespace,
+			LeasePrefix:   c.LeaderElectionLeasePrefix,
+			LeaseDuration: c.LeaderElectionLeaseDuration,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize leader election: %w", err)
+		}
+	}
+
+	webhookDispatcher := webhooks.DisabledDispatcher
+	if len(c.WebhookURLs) > 0 {
+		dispatcher, err := webhooks.NewDispatcher(ds, webhooks.Config{
+			URLs:          c.WebhookURLs,
+			Secret:        c.WebhookSecret,
+			BatchSize:     c.WebhookBatchSize,
+			FlushInterval: c.WebhookFlushInterval,
+			MaxRetries:    c.WebhookMaxRetries,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize webhook dispatcher: %w", err)
+		}
+		webhookDispatcher = func(ctx context.Context) error {
+			return elector(ctx, "webhooks", dispatcher)
+		}
+	}
+
+	changePublisher, changePublisherSink, err := c.initializeChangePublisher(ctx, ds, elector)
+	if err != nil {
+		return nil, err
+	}
+	closeables.AddCloser(changePublisherSink)
+
+	groupSyncer := groupsync.DisabledSyncer
+	if c.GroupSyncSCIMURL != "" {
+		log.Ctx(ctx).Info().Str("url", c.GroupSyncSCIMURL).Msg("synchronizing groups from SCIM")
+
+		// Groups may be synchronized into the object types of a tenant.
+		groupDS := ds
+		if tenantUsage != nil {
+			groupDS = tenantUsage.Datastore(ds)
+		}
+
+		syncer, err := groupsync.NewSyncer(groupDS, groupsync.Config{
+			Source:            groupsync.NewSCIMSource(c.GroupSyncSCIMURL, c.GroupSyncSCIMToken),
+			Interval:          c.GroupSyncInterval,
+			GroupType:         c.GroupSyncGroupType,
+			UserType:          c.GroupSyncUserType,
+			MemberRelation:    c.GroupSyncMemberRelation,
+			SyncedRelation:    c.GroupSyncSyncedRelation,
+			MaxDeleteFraction: c.GroupSyncMaxDeleteFraction,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize group synchronization: %w", err)
+		}
+		groupSyncer = func(ctx context.Context) error {
+			return elector(ctx, "group-sync", syncer)
+		}
+	}
+
 	var telemetryRegistry *prometheus.Registry
 
 	reporter := telemetry.DisabledReporter
@@ -462,7 +707,28 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		}
 	}
 
-	metricsServer, err := c.MetricsAPI.Complete(zerolog.InfoLevel, MetricsHandler(telemetryRegistry, c))
+	definitions, _ := ds.(schemacaching.Flushable)
+
+	clusterStateCaches := maps.Clone(flushableCaches)
+	clusterStateCaches["namespace"] = nscc
+
+	// The admin endpoints are served to the callers holding a preshared key not bound to a tenant.
+	adminKeys := slices.DeleteFunc(slices.Clone(c.PresharedSecureKey), func(key string) bool {
+		_, bound := tenantBindings[key]
+		return bound
+	})
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/", MetricsHandler(telemetryRegistry, c))
+	if c.MetricsAPICacheFlushEnabled {
+		metricsMux.Handle(CacheFlushPath, auth.RequirePresharedKeyHandler(adminKeys, newCacheFlushHandler(definitions, flushableCaches)))
+	}
+	metricsMux.Handle(ClusterStatePath, auth.RequirePresharedKeyHandler(adminKeys, newClusterStateHandler(ds, ConsistentHashringBuilder.Rings, clusterStateCaches)))
+	if tenantUsage != nil {
+		metricsMux.Handle(tenant.UsagePath, auth.RequirePresharedKeyHandler(adminKeys, tenant.NewUsageHandler(tenantUsage)))
+	}
+
+	metricsServer, err := c.MetricsAPI.Complete(zerolog.InfoLevel, metricsMux)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize metrics server: %w", err)
 	}
@@ -473,16 +739,30 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		gRPCServer:          grpcServer,
 		dispatchGRPCServer:  dispatchGrpcServer,
 		gatewayServer:       gatewayServer,
+		extAuthzServer:      extAuthzServer,
 		metricsServer:       metricsServer,
 		unaryMiddleware:     unaryMiddleware,
 		streamingMiddleware: streamingMiddleware,
 		presharedKeys:       c.PresharedSecureKey,
 		telemetryReporter:   reporter,
+		webhookDispatcher:   webhookDispatcher,
+		changePublisher:     changePublisher,
+		groupSyncer:         groupSyncer,
 		healthManager:       healthManager,
+		runtimeLimits:       runtimeLimits,
+		concurrencyLimits:   concurrencyLimits,
 		closeFunc:           closeables.Close,
 	}, nil
 }
 
+func (c *Config) runtimeLimits() v1svc.RuntimeLimits {
+	return v1svc.RuntimeLimits{
+		MaximumAPIDepth:       c.DispatchMaxDepth,
+		MaxUpdatesPerWrite:    c.MaximumUpdatesPerWrite,
+		MaxPreconditionsCount: c.MaximumPreconditionCount,
+	}
+}
+
 func (c *Config) buildUnaryMiddleware(defaultMiddleware *MiddlewareChain[grpc.UnaryServerInterceptor]) ([]grpc.UnaryServerInterceptor, error) {
 	chain := MiddlewareChain[grpc.UnaryServerInterceptor]{}
 	if defaultMiddleware != nil {
@@ -510,7 +790,7 @@ func (c *Config) buildStreamingMiddleware(defaultMiddleware *MiddlewareChain[grp
 }
 
 // initializeGateway Configures the gateway to serve HTTP
-func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer, io.Closer, error) {
+func (c *Config) initializeGateway(ctx context.Context, ds datastore.Datastore) (util.RunnableHTTPServer, io.Closer, error) {
 	if len(c.HTTPGatewayUpstreamAddr) == 0 {
 		c.HTTPGatewayUpstreamAddr = c.GRPCServer.Address
 	} else {
@@ -534,7 +814,25 @@ func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer
 	}
 
 	var gatewayHandler http.Handler
-	closeableGatewayHandler, err := gateway.NewHandler(ctx, c.HTTPGatewayUpstreamAddr, c.HTTPGatewayUpstreamTLSCertPath)
+	var ketoSubjectType string
+	if c.HTTPGatewayKetoEnabled {
+		if c.HTTPGatewayKetoSubjectType == "" {
+			return nil, nil, fmt.Errorf("the Keto API requires a subject type")
+		}
+		ketoSubjectType = c.HTTPGatewayKetoSubjectType
+	}
+
+	closeableGatewayHandler, err := gateway.NewHandler(ctx, gateway.Config{
+		UpstreamAddr:        c.HTTPGatewayUpstreamAddr,
+		UpstreamTLSCertPath: c.HTTPGatewayUpstreamTLSCertPath,
+		GraphQLEnabled:      c.HTTPGatewayGraphQLEnabled,
+		OPAEnabled:          c.HTTPGatewayOPAEnabled,
+		LookupWatchEnabled:  c.HTTPGatewayLookupWatchEnabled,
+		SimulateEnabled:     c.HTTPGatewaySimulateEnabled,
+		Datastore:           ds,
+		CapabilitiesEnabled: c.HTTPGatewayCapabilitiesEnabled,
+		KetoSubjectType:     ketoSubjectType,
+	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
 	}
@@ -551,7 +849,7 @@ func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer
 	}
 
 	if c.HTTPGateway.HTTPEnabled {
-		log.Ctx(ctx).Info().Str("upstream", c.HTTPGatewayUpstreamAddr).Msg("starting REST gateway")
+		log.Ctx(ctx).Info().Str("upstream", c.HTTPGatewayUpstreamAddr).Bool("graphql", c.HTTPGatewayGraphQLEnabled).Bool("opa", c.HTTPGatewayOPAEnabled).Bool("lookupwatch", c.HTTPGatewayLookupWatchEnabled).Bool("simulate", c.HTTPGatewaySimulateEnabled).Bool("capabilities", c.HTTPGatewayCapabilitiesEnabled).Bool("keto", c.HTTPGatewayKetoEnabled).Msg("starting REST gateway")
 	}
 
 	gatewayServer, err := c.HTTPGateway.Complete(zerolog.InfoLevel, gatewayHandler)
@@ -561,11 +859,147 @@ func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer
 	return gatewayServer, closeableGatewayHandler, nil
 }
 
+// initializeExtAuthz configures the Envoy ext_authz adapter, which checks permissions against
+// the gRPC API of this server.
+func (c *Config) initializeExtAuthz(ctx context.Context, grpcServer util.RunnableGRPCServer) (util.RunnableGRPCServer, io.Closer, error) {
+	if !c.ExtAuthzServer.Enabled {
+		extAuthzServer, err := c.ExtAuthzServer.Complete(zerolog.InfoLevel, nil)
+		return extAuthzServer, nil, err
+	}
+
+	if c.ExtAuthzConfigPath == "" {
+		return nil, nil, fmt.Errorf("the ext_authz adapter requires a config file")
+	}
+
+	extAuthzConfig, err := extauthz.LoadConfig(c.ExtAuthzConfigPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var opts []grpc.DialOption
+	if len(c.PresharedSecureKey) > 0 {
+		if grpcServer.Insecure() {
+			opts = append(opts, grpcutil.WithInsecureBearerToken(c.PresharedSecureKey[0]))
+		} else {
+			opts = append(opts, grpcutil.WithBearerToken(c.PresharedSecureKey[0]))
+		}
+	}
+
+	conn, err := grpcServer.DialContext(ctx, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to dial gRPC server for ext_authz adapter: %w", err)
+	}
+
+	adapter, err := extauthz.NewServer(v1.NewPermissionsServiceClient(conn), *extAuthzConfig)
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("failed to initialize ext_authz adapter: %w", err), conn.Close())
+	}
+
+	log.Ctx(ctx).Info().Str("config", c.ExtAuthzConfigPath).Int("rules", len(extAuthzConfig.Rules)).Msg("starting ext_authz adapter")
+
+	extAuthzServer, err := c.ExtAuthzServer.Complete(zerolog.InfoLevel,
+		func(server *grpc.Server) {
+			authv3.RegisterAuthorizationServer(server, adapter)
+		},
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	)
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("failed to create ext_authz gRPC server: %w", err), conn.Close())
+	}
+	return extAuthzServer, conn, nil
+}
+
+// initializeAuditLogger configures the audit log with the configured sinks, or returns nil
+// if no sink is configured.
+func (c *Config) initializeAuditLogger(ctx context.Context) (*audit.Logger, error) {
+	var sinks []audit.Sink
+	closeSinks := func() error {
+		errs := make([]error, 0, len(sinks))
+		for _, sink := range sinks {
+			errs = append(errs, sink.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	if c.AuditLogFilePath != "" {
+		log.Ctx(ctx).Info().Str("path", c.AuditLogFilePath).Msg("writing audit log to file")
+		fileSink, err := audit.NewFileSink(c.AuditLogFilePath)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+	}
+
+	if c.AuditLogSyslogEnabled {
+		log.Ctx(ctx).Info().Str("address", c.AuditLogSyslogAddress).Msg("writing audit log to syslog")
+		syslogSink, err := audit.NewSyslogSink(c.AuditLogSyslogNetwork, c.AuditLogSyslogAddress, "spicedb")
+		if err != nil {
+			return nil, errors.Join(err, closeSinks())
+		}
+		sinks = append(sinks, syslogSink)
+	}
+
+	if c.AuditLogHTTPURL != "" {
+		log.Ctx(ctx).Info().Str("url", c.AuditLogHTTPURL).Msg("sending audit log to HTTP endpoint")
+		sinks = append(sinks, audit.NewHTTPSink(c.AuditLogHTTPURL))
+	}
+
+	if len(sinks) == 0 {
+		if c.AuditLogCheckDecisions {
+			return nil, fmt.Errorf("auditing check decisions requires an audit log sink")
+		}
+		return nil, nil
+	}
+	return audit.NewLogger(c.AuditLogCheckDecisions, sinks...), nil
+}
+
+// initializeChangePublisher configures the publishing of changes to Kafka or NATS, if either
+// is configured, from the replica elected to publish them.
+func (c *Config) initializeChangePublisher(ctx context.Context, ds datastore.Datastore, elector leaderelection.Elector) (changepublisher.Publisher, io.Closer, error) {
+	var sink changepublisher.Sink
+	switch {
+	case len(c.ChangePublisherKafkaBrokers) > 0 && c.ChangePublisherNATSURL != "":
+		return nil, nil, fmt.Errorf("changes can be published to either Kafka or NATS, but not both")
+
+	case len(c.ChangePublisherKafkaBrokers) > 0:
+		log.Ctx(ctx).Info().Strs("brokers", c.ChangePublisherKafkaBrokers).Str("topic", c.ChangePublisherKafkaTopic).Msg("publishing changes to Kafka")
+		sink = changepublisher.NewKafkaSink(c.ChangePublisherKafkaBrokers, c.ChangePublisherKafkaTopic)
+
+	case c.ChangePublisherNATSURL != "":
+		log.Ctx(ctx).Info().Str("subject", c.ChangePublisherNATSSubject).Msg("publishing changes to NATS")
+		natsSink, err := changepublisher.NewNATSSink(c.ChangePublisherNATSURL, c.ChangePublisherNATSSubject)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize change publisher: %w", err)
+		}
+		sink = natsSink
+
+	default:
+		return changepublisher.DisabledPublisher, nil, nil
+	}
+
+	publisher, err := changepublisher.NewPublisher(ds, changepublisher.Config{
+		Sink:      sink,
+		BatchSize: c.ChangePublisherBatchSize,
+	})
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("failed to initialize change publisher: %w", err), sink.Close())
+	}
+
+	return func(ctx context.Context) error {
+		return elector(ctx, "change-publisher", publisher)
+	}, sink, nil
+}
+
 // RunnableServer is a spicedb service set ready to run
 type RunnableServer interface {
 	Run(ctx context.Context) error
 	GRPCDialContext(ctx context.Context, opts ...grpc.DialOption) (*grpc.ClientConn, error)
 	DispatchNetDialContext(ctx context.Context, s string) (net.Conn, error)
+
+	// ReloadRuntimeLimits applies the limits of the given config which can be changed while the
+	// server is running: the dispatch depth, the dispatch concurrency limits and the maximum
+	// updates and preconditions per write.
+	ReloadRuntimeLimits(ctx context.Context, c *Config)
 }
 
 // completedServerConfig holds the full configuration to run a spicedb server,
@@ -577,9 +1011,15 @@ type completedServerConfig struct {
 	gRPCServer         util.RunnableGRPCServer
 	dispatchGRPCServer util.RunnableGRPCServer
 	gatewayServer      util.RunnableHTTPServer
+	extAuthzServer     util.RunnableGRPCServer
 	metricsServer      util.RunnableHTTPServer
 	telemetryReporter  telemetry.Reporter
+	webhookDispatcher  webhooks.Dispatcher
+	changePublisher    changepublisher.Publisher
+	groupSyncer        groupsync.Syncer
 	healthManager      health.Manager
+	runtimeLimits      *v1svc.RuntimeLimitsHolder
+	concurrencyLimits  *graph.ConcurrencyLimitsHolder
 
 	unaryMiddleware     []grpc.UnaryServerInterceptor
 	streamingMiddleware []grpc.StreamServerInterceptor
@@ -587,6 +1027,28 @@ type completedServerConfig struct {
 	closeFunc           func() error
 }
 
+func (c *completedServerConfig) ReloadRuntimeLimits(ctx context.Context, config *Config) {
+	previous := c.runtimeLimits.Load()
+	c.runtimeLimits.Store(config.runtimeLimits())
+	current := c.runtimeLimits.Load()
+
+	log.Ctx(ctx).Info().
+		Uint32("previous_dispatch_max_depth", previous.MaximumAPIDepth).
+		Uint32("dispatch_max_depth", current.MaximumAPIDepth).
+		Uint16("previous_max_updates_per_write", previous.MaxUpdatesPerWrite).
+		Uint16("max_updates_per_write", current.MaxUpdatesPerWrite).
+		Uint16("previous_max_preconditions_count", previous.MaxPreconditionsCount).
+		Uint16("max_preconditions_count", current.MaxPreconditionsCount).
+		Msg("reloaded runtime limits")
+
+	previousConcurrency := c.concurrencyLimits.Load()
+	c.concurrencyLimits.Store(config.DispatchConcurrencyLimits.WithOverallDefaultLimit(config.GlobalDispatchConcurrencyLimit))
+	log.Ctx(ctx).Info().
+		Object("previous_dispatch_concurrency_limits", previousConcurrency).
+		Object("dispatch_concurrency_limits", c.concurrencyLimits.Load()).
+		Msg("reloaded dispatch concurrency limits")
+}
+
 func (c *completedServerConfig) GRPCDialContext(ctx context.Context, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	if len(c.presharedKeys) == 0 {
 		return c.gRPCServer.DialContext(ctx, opts...)
@@ -631,8 +1093,12 @@ func (c *completedServerConfig) Run(ctx context.Context) error {
 	g.Go(grpcServer.Listen(ctx))
 	g.Go(c.dispatchGRPCServer.Listen(ctx))
 	g.Go(c.gatewayServer.ListenAndServe)
+	g.Go(c.extAuthzServer.Listen(ctx))
 	g.Go(c.metricsServer.ListenAndServe)
 	g.Go(func() error { return c.telemetryReporter(ctx) })
+	g.Go(func() error { return c.webhookDispatcher(ctx) })
+	g.Go(func() error { return c.changePublisher(ctx) })
+	g.Go(func() error { return c.groupSyncer(ctx) })
 
 	g.Go(stopOnCancelWithErr(c.closeFunc))
 
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,12 +15,16 @@ import (
 	"github.com/authzed/spicedb/pkg/cmd/util"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/goleak"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func TestServerGracefulTermination(t *testing.T) {
@@ -176,6 +182,129 @@ func TestServerGracefulTerminationOnError(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServerJWTRequiresPresharedKeyForDispatch(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	c := ConfigWithOptions(&Config{
+		GRPCServer: util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+		},
+	}, WithGRPCJWTIssuer("https://issuer.example.com"), WithDispatchUpstreamAddr("localhost:50053"), WithDatastore(ds))
+	_, err = c.Complete(context.Background())
+	require.ErrorContains(t, err, "a preshared key must be provided")
+}
+
+func TestServerGatewayRejectedWithMutualTLS(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	c := ConfigWithOptions(&Config{
+		GRPCServer: util.GRPCServerConfig{
+			Network:          util.BufferedNetwork,
+			MTLSClientCAPath: "some/ca.crt",
+		},
+		HTTPGateway: util.HTTPServerConfig{
+			HTTPEnabled: true,
+		},
+	}, WithPresharedSecureKey("psk"), WithDatastore(ds))
+	_, err = c.Complete(context.Background())
+	require.ErrorContains(t, err, "the REST gateway cannot be enabled")
+}
+
+func TestServerExtAuthz(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	configPath := filepath.Join(t.TempDir(), "ext-authz.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+subject:
+  type: user
+rules:
+  - methods: [GET]
+    path: /documents/{id}
+    resource:
+      type: document
+      id: "{id}"
+    permission: view
+`), 0o600))
+
+	c := ConfigWithOptions(
+		&Config{},
+		WithPresharedSecureKey("psk"),
+		WithDatastore(ds),
+		WithGRPCServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		WithExtAuthzServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		WithExtAuthzConfigPath(configPath),
+		WithNamespaceCacheConfig(CacheConfig{Enabled: true}),
+		WithDispatchCacheConfig(CacheConfig{Enabled: true}),
+		WithClusterDispatchCacheConfig(CacheConfig{Enabled: true}),
+	)
+	rs, err := c.Complete(ctx)
+	require.NoError(t, err)
+
+	go func() {
+		_ = rs.Run(ctx)
+	}()
+
+	conn, err := rs.GRPCDialContext(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = v1.NewSchemaServiceClient(conn).WriteSchema(ctx, &v1.WriteSchemaRequest{
+		Schema: "definition user {}\ndefinition document {\n\trelation viewer: user\n\tpermission view = viewer\n}",
+	})
+	require.NoError(t, err)
+
+	_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation: v1.RelationshipUpdate_OPERATION_CREATE,
+			Relationship: &v1.Relationship{
+				Resource: &v1.ObjectReference{ObjectType: "document", ObjectId: "readme"},
+				Relation: "viewer",
+				Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "alice"}},
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	extAuthzConn, err := rs.(*completedServerConfig).extAuthzServer.DialContext(ctx)
+	require.NoError(t, err)
+	defer extAuthzConn.Close()
+
+	check := func(subject string) codes.Code {
+		claims, err := structpb.NewStruct(map[string]any{"sub": subject})
+		require.NoError(t, err)
+
+		resp, err := authv3.NewAuthorizationClient(extAuthzConn).Check(ctx, &authv3.CheckRequest{
+			Attributes: &authv3.AttributeContext{
+				Request: &authv3.AttributeContext_Request{
+					Http: &authv3.AttributeContext_HttpRequest{Method: "GET", Path: "/documents/readme"},
+				},
+				MetadataContext: &corev3.Metadata{FilterMetadata: map[string]*structpb.Struct{
+					"envoy.filters.http.jwt_authn": {Fields: map[string]*structpb.Value{
+						"jwt_payload": structpb.NewStructValue(claims),
+					}},
+				}},
+			},
+		})
+		require.NoError(t, err)
+		return codes.Code(resp.Status.Code)
+	}
+
+	require.Equal(t, codes.OK, check("alice"))
+	require.Equal(t, codes.PermissionDenied, check("bob"))
+}
+
 func TestReplaceUnaryMiddleware(t *testing.T) {
 	c := Config{UnaryMiddlewareModification: []MiddlewareModification[grpc.UnaryServerInterceptor]{
 		{
@@ -230,7 +359,7 @@ func TestModifyUnaryMiddleware(t *testing.T) {
 		},
 	}}
 
-	opt := MiddlewareOption{logging.Logger, nil, false, nil, nil, false, false, false}
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, nil, false, false, false, 0, nil, nil, nil}
 	defaultMw, err := DefaultUnaryMiddleware(opt)
 	require.NoError(t, err)
 
@@ -256,7 +385,7 @@ func TestModifyStreamingMiddleware(t *testing.T) {
 		},
 	}}
 
-	opt := MiddlewareOption{logging.Logger, nil, false, nil, nil, false, false, false}
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, nil, false, false, false, 0, nil, nil, nil}
 	defaultMw, err := DefaultStreamingMiddleware(opt)
 	require.NoError(t, err)
 
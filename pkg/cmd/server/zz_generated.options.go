@@ -41,13 +41,28 @@ func (c *Config) ToOption() ConfigOption {
 		to.GRPCServer = c.GRPCServer
 		to.GRPCAuthFunc = c.GRPCAuthFunc
 		to.PresharedSecureKey = c.PresharedSecureKey
+		to.GRPCJWTIssuer = c.GRPCJWTIssuer
+		to.GRPCJWTAudience = c.GRPCJWTAudience
+		to.GRPCJWTJWKSURL = c.GRPCJWTJWKSURL
+		to.GRPCJWTIdentityClaim = c.GRPCJWTIdentityClaim
+		to.GRPCTenantPrefixes = c.GRPCTenantPrefixes
 		to.ShutdownGracePeriod = c.ShutdownGracePeriod
 		to.DisableVersionResponse = c.DisableVersionResponse
+		to.GRPCTenantMaxRelationships = c.GRPCTenantMaxRelationships
+		to.GRPCTenantMaxObjectDefinitions = c.GRPCTenantMaxObjectDefinitions
+		to.GRPCTenantMaxRequestsPerSecond = c.GRPCTenantMaxRequestsPerSecond
 		to.HTTPGateway = c.HTTPGateway
 		to.HTTPGatewayUpstreamAddr = c.HTTPGatewayUpstreamAddr
 		to.HTTPGatewayUpstreamTLSCertPath = c.HTTPGatewayUpstreamTLSCertPath
 		to.HTTPGatewayCorsEnabled = c.HTTPGatewayCorsEnabled
 		to.HTTPGatewayCorsAllowedOrigins = c.HTTPGatewayCorsAllowedOrigins
+		to.HTTPGatewayGraphQLEnabled = c.HTTPGatewayGraphQLEnabled
+		to.HTTPGatewayOPAEnabled = c.HTTPGatewayOPAEnabled
+		to.HTTPGatewayLookupWatchEnabled = c.HTTPGatewayLookupWatchEnabled
+		to.HTTPGatewaySimulateEnabled = c.HTTPGatewaySimulateEnabled
+		to.HTTPGatewayCapabilitiesEnabled = c.HTTPGatewayCapabilitiesEnabled
+		to.HTTPGatewayKetoEnabled = c.HTTPGatewayKetoEnabled
+		to.HTTPGatewayKetoSubjectType = c.HTTPGatewayKetoSubjectType
 		to.DatastoreConfig = c.DatastoreConfig
 		to.Datastore = c.Datastore
 		to.MaxCaveatContextSize = c.MaxCaveatContextSize
@@ -55,6 +70,7 @@ func (c *Config) ToOption() ConfigOption {
 		to.EnableExperimentalWatchableSchemaCache = c.EnableExperimentalWatchableSchemaCache
 		to.SchemaWatchHeartbeat = c.SchemaWatchHeartbeat
 		to.NamespaceCacheConfig = c.NamespaceCacheConfig
+		to.RelationshipCacheConfig = c.RelationshipCacheConfig
 		to.SchemaPrefixesRequired = c.SchemaPrefixesRequired
 		to.DispatchServer = c.DispatchServer
 		to.DispatchMaxDepth = c.DispatchMaxDepth
@@ -62,6 +78,8 @@ func (c *Config) ToOption() ConfigOption {
 		to.DispatchConcurrencyLimits = c.DispatchConcurrencyLimits
 		to.DispatchUpstreamAddr = c.DispatchUpstreamAddr
 		to.DispatchUpstreamCAPath = c.DispatchUpstreamCAPath
+		to.DispatchUpstreamTLSCertPath = c.DispatchUpstreamTLSCertPath
+		to.DispatchUpstreamTLSKeyPath = c.DispatchUpstreamTLSKeyPath
 		to.DispatchUpstreamTimeout = c.DispatchUpstreamTimeout
 		to.DispatchClientMetricsEnabled = c.DispatchClientMetricsEnabled
 		to.DispatchClientMetricsPrefix = c.DispatchClientMetricsPrefix
@@ -82,6 +100,31 @@ func (c *Config) ToOption() ConfigOption {
 		to.StreamingAPITimeout = c.StreamingAPITimeout
 		to.WatchHeartbeat = c.WatchHeartbeat
 		to.MetricsAPI = c.MetricsAPI
+		to.MetricsAPICacheFlushEnabled = c.MetricsAPICacheFlushEnabled
+		to.ExtAuthzServer = c.ExtAuthzServer
+		to.ExtAuthzConfigPath = c.ExtAuthzConfigPath
+		to.LeaderElectionEnabled = c.LeaderElectionEnabled
+		to.LeaderElectionNamespace = c.LeaderElectionNamespace
+		to.LeaderElectionLeasePrefix = c.LeaderElectionLeasePrefix
+		to.LeaderElectionLeaseDuration = c.LeaderElectionLeaseDuration
+		to.WebhookURLs = c.WebhookURLs
+		to.WebhookSecret = c.WebhookSecret
+		to.WebhookBatchSize = c.WebhookBatchSize
+		to.WebhookFlushInterval = c.WebhookFlushInterval
+		to.WebhookMaxRetries = c.WebhookMaxRetries
+		to.ChangePublisherKafkaBrokers = c.ChangePublisherKafkaBrokers
+		to.ChangePublisherKafkaTopic = c.ChangePublisherKafkaTopic
+		to.ChangePublisherNATSURL = c.ChangePublisherNATSURL
+		to.ChangePublisherNATSSubject = c.ChangePublisherNATSSubject
+		to.ChangePublisherBatchSize = c.ChangePublisherBatchSize
+		to.GroupSyncSCIMURL = c.GroupSyncSCIMURL
+		to.GroupSyncSCIMToken = c.GroupSyncSCIMToken
+		to.GroupSyncInterval = c.GroupSyncInterval
+		to.GroupSyncGroupType = c.GroupSyncGroupType
+		to.GroupSyncUserType = c.GroupSyncUserType
+		to.GroupSyncMemberRelation = c.GroupSyncMemberRelation
+		to.GroupSyncSyncedRelation = c.GroupSyncSyncedRelation
+		to.GroupSyncMaxDeleteFraction = c.GroupSyncMaxDeleteFraction
 		to.UnaryMiddlewareModification = c.UnaryMiddlewareModification
 		to.StreamingMiddlewareModification = c.StreamingMiddlewareModification
 		to.DispatchUnaryMiddleware = c.DispatchUnaryMiddleware
@@ -92,6 +135,15 @@ func (c *Config) ToOption() ConfigOption {
 		to.TelemetryInterval = c.TelemetryInterval
 		to.EnableRequestLogs = c.EnableRequestLogs
 		to.EnableResponseLogs = c.EnableResponseLogs
+		to.EnableAccessLogs = c.EnableAccessLogs
+		to.AccessLogSampleRate = c.AccessLogSampleRate
+		to.SlowQueryThreshold = c.SlowQueryThreshold
+		to.AuditLogFilePath = c.AuditLogFilePath
+		to.AuditLogSyslogEnabled = c.AuditLogSyslogEnabled
+		to.AuditLogSyslogNetwork = c.AuditLogSyslogNetwork
+		to.AuditLogSyslogAddress = c.AuditLogSyslogAddress
+		to.AuditLogHTTPURL = c.AuditLogHTTPURL
+		to.AuditLogCheckDecisions = c.AuditLogCheckDecisions
 		to.DisableGRPCLatencyHistogram = c.DisableGRPCLatencyHistogram
 	}
 }
@@ -102,13 +154,28 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["GRPCServer"] = helpers.DebugValue(c.GRPCServer, false)
 	debugMap["GRPCAuthFunc"] = helpers.DebugValue(c.GRPCAuthFunc, false)
 	debugMap["PresharedSecureKey"] = helpers.SensitiveDebugValue(c.PresharedSecureKey)
+	debugMap["GRPCJWTIssuer"] = helpers.DebugValue(c.GRPCJWTIssuer, false)
+	debugMap["GRPCJWTAudience"] = helpers.DebugValue(c.GRPCJWTAudience, false)
+	debugMap["GRPCJWTJWKSURL"] = helpers.DebugValue(c.GRPCJWTJWKSURL, false)
+	debugMap["GRPCJWTIdentityClaim"] = helpers.DebugValue(c.GRPCJWTIdentityClaim, false)
+	debugMap["GRPCTenantPrefixes"] = helpers.SensitiveDebugValue(c.GRPCTenantPrefixes)
 	debugMap["ShutdownGracePeriod"] = helpers.DebugValue(c.ShutdownGracePeriod, false)
 	debugMap["DisableVersionResponse"] = helpers.DebugValue(c.DisableVersionResponse, false)
+	debugMap["GRPCTenantMaxRelationships"] = helpers.DebugValue(c.GRPCTenantMaxRelationships, false)
+	debugMap["GRPCTenantMaxObjectDefinitions"] = helpers.DebugValue(c.GRPCTenantMaxObjectDefinitions, false)
+	debugMap["GRPCTenantMaxRequestsPerSecond"] = helpers.DebugValue(c.GRPCTenantMaxRequestsPerSecond, false)
 	debugMap["HTTPGateway"] = helpers.DebugValue(c.HTTPGateway, false)
 	debugMap["HTTPGatewayUpstreamAddr"] = helpers.DebugValue(c.HTTPGatewayUpstreamAddr, false)
 	debugMap["HTTPGatewayUpstreamTLSCertPath"] = helpers.DebugValue(c.HTTPGatewayUpstreamTLSCertPath, false)
 	debugMap["HTTPGatewayCorsEnabled"] = helpers.DebugValue(c.HTTPGatewayCorsEnabled, false)
 	debugMap["HTTPGatewayCorsAllowedOrigins"] = helpers.DebugValue(c.HTTPGatewayCorsAllowedOrigins, true)
+	debugMap["HTTPGatewayGraphQLEnabled"] = helpers.DebugValue(c.HTTPGatewayGraphQLEnabled, false)
+	debugMap["HTTPGatewayOPAEnabled"] = helpers.DebugValue(c.HTTPGatewayOPAEnabled, false)
+	debugMap["HTTPGatewayLookupWatchEnabled"] = helpers.DebugValue(c.HTTPGatewayLookupWatchEnabled, false)
+	debugMap["HTTPGatewaySimulateEnabled"] = helpers.DebugValue(c.HTTPGatewaySimulateEnabled, false)
+	debugMap["HTTPGatewayCapabilitiesEnabled"] = helpers.DebugValue(c.HTTPGatewayCapabilitiesEnabled, false)
+	debugMap["HTTPGatewayKetoEnabled"] = helpers.DebugValue(c.HTTPGatewayKetoEnabled, false)
+	debugMap["HTTPGatewayKetoSubjectType"] = helpers.DebugValue(c.HTTPGatewayKetoSubjectType, false)
 	debugMap["DatastoreConfig"] = helpers.DebugValue(c.DatastoreConfig, false)
 	debugMap["Datastore"] = helpers.DebugValue(c.Datastore, false)
 	debugMap["MaxCaveatContextSize"] = helpers.DebugValue(c.MaxCaveatContextSize, false)
@@ -116,6 +183,7 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["EnableExperimentalWatchableSchemaCache"] = helpers.DebugValue(c.EnableExperimentalWatchableSchemaCache, false)
 	debugMap["SchemaWatchHeartbeat"] = helpers.DebugValue(c.SchemaWatchHeartbeat, false)
 	debugMap["NamespaceCacheConfig"] = helpers.DebugValue(c.NamespaceCacheConfig, false)
+	debugMap["RelationshipCacheConfig"] = helpers.DebugValue(c.RelationshipCacheConfig, false)
 	debugMap["SchemaPrefixesRequired"] = helpers.DebugValue(c.SchemaPrefixesRequired, false)
 	debugMap["DispatchServer"] = helpers.DebugValue(c.DispatchServer, false)
 	debugMap["DispatchMaxDepth"] = helpers.DebugValue(c.DispatchMaxDepth, false)
@@ -123,6 +191,8 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["DispatchConcurrencyLimits"] = helpers.DebugValue(c.DispatchConcurrencyLimits, false)
 	debugMap["DispatchUpstreamAddr"] = helpers.DebugValue(c.DispatchUpstreamAddr, false)
 	debugMap["DispatchUpstreamCAPath"] = helpers.DebugValue(c.DispatchUpstreamCAPath, false)
+	debugMap["DispatchUpstreamTLSCertPath"] = helpers.DebugValue(c.DispatchUpstreamTLSCertPath, false)
+	debugMap["DispatchUpstreamTLSKeyPath"] = helpers.DebugValue(c.DispatchUpstreamTLSKeyPath, false)
 	debugMap["DispatchUpstreamTimeout"] = helpers.DebugValue(c.DispatchUpstreamTimeout, false)
 	debugMap["DispatchClientMetricsEnabled"] = helpers.DebugValue(c.DispatchClientMetricsEnabled, false)
 	debugMap["DispatchClientMetricsPrefix"] = helpers.DebugValue(c.DispatchClientMetricsPrefix, false)
@@ -143,12 +213,46 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["StreamingAPITimeout"] = helpers.DebugValue(c.StreamingAPITimeout, false)
 	debugMap["WatchHeartbeat"] = helpers.DebugValue(c.WatchHeartbeat, false)
 	debugMap["MetricsAPI"] = helpers.DebugValue(c.MetricsAPI, false)
+	debugMap["MetricsAPICacheFlushEnabled"] = helpers.DebugValue(c.MetricsAPICacheFlushEnabled, false)
+	debugMap["ExtAuthzServer"] = helpers.DebugValue(c.ExtAuthzServer, false)
+	debugMap["ExtAuthzConfigPath"] = helpers.DebugValue(c.ExtAuthzConfigPath, false)
+	debugMap["LeaderElectionEnabled"] = helpers.DebugValue(c.LeaderElectionEnabled, false)
+	debugMap["LeaderElectionNamespace"] = helpers.DebugValue(c.LeaderElectionNamespace, false)
+	debugMap["LeaderElectionLeasePrefix"] = helpers.DebugValue(c.LeaderElectionLeasePrefix, false)
+	debugMap["LeaderElectionLeaseDuration"] = helpers.DebugValue(c.LeaderElectionLeaseDuration, false)
+	debugMap["WebhookURLs"] = helpers.DebugValue(c.WebhookURLs, false)
+	debugMap["WebhookSecret"] = helpers.DebugValue(c.WebhookSecret, true)
+	debugMap["WebhookBatchSize"] = helpers.DebugValue(c.WebhookBatchSize, false)
+	debugMap["WebhookFlushInterval"] = helpers.DebugValue(c.WebhookFlushInterval, false)
+	debugMap["WebhookMaxRetries"] = helpers.DebugValue(c.WebhookMaxRetries, false)
+	debugMap["ChangePublisherKafkaBrokers"] = helpers.DebugValue(c.ChangePublisherKafkaBrokers, false)
+	debugMap["ChangePublisherKafkaTopic"] = helpers.DebugValue(c.ChangePublisherKafkaTopic, false)
+	debugMap["ChangePublisherNATSURL"] = helpers.DebugValue(c.ChangePublisherNATSURL, false)
+	debugMap["ChangePublisherNATSSubject"] = helpers.DebugValue(c.ChangePublisherNATSSubject, false)
+	debugMap["ChangePublisherBatchSize"] = helpers.DebugValue(c.ChangePublisherBatchSize, false)
+	debugMap["GroupSyncSCIMURL"] = helpers.DebugValue(c.GroupSyncSCIMURL, false)
+	debugMap["GroupSyncSCIMToken"] = helpers.DebugValue(c.GroupSyncSCIMToken, true)
+	debugMap["GroupSyncInterval"] = helpers.DebugValue(c.GroupSyncInterval, false)
+	debugMap["GroupSyncGroupType"] = helpers.DebugValue(c.GroupSyncGroupType, false)
+	debugMap["GroupSyncUserType"] = helpers.DebugValue(c.GroupSyncUserType, false)
+	debugMap["GroupSyncMemberRelation"] = helpers.DebugValue(c.GroupSyncMemberRelation, false)
+	debugMap["GroupSyncSyncedRelation"] = helpers.DebugValue(c.GroupSyncSyncedRelation, false)
+	debugMap["GroupSyncMaxDeleteFraction"] = helpers.DebugValue(c.GroupSyncMaxDeleteFraction, false)
 	debugMap["SilentlyDisableTelemetry"] = helpers.DebugValue(c.SilentlyDisableTelemetry, false)
 	debugMap["TelemetryCAOverridePath"] = helpers.DebugValue(c.TelemetryCAOverridePath, false)
 	debugMap["TelemetryEndpoint"] = helpers.DebugValue(c.TelemetryEndpoint, false)
 	debugMap["TelemetryInterval"] = helpers.DebugValue(c.TelemetryInterval, false)
 	debugMap["EnableRequestLogs"] = helpers.DebugValue(c.EnableRequestLogs, false)
 	debugMap["EnableResponseLogs"] = helpers.DebugValue(c.EnableResponseLogs, false)
+	debugMap["EnableAccessLogs"] = helpers.DebugValue(c.EnableAccessLogs, false)
+	debugMap["AccessLogSampleRate"] = helpers.DebugValue(c.AccessLogSampleRate, false)
+	debugMap["SlowQueryThreshold"] = helpers.DebugValue(c.SlowQueryThreshold, false)
+	debugMap["AuditLogFilePath"] = helpers.DebugValue(c.AuditLogFilePath, false)
+	debugMap["AuditLogSyslogEnabled"] = helpers.DebugValue(c.AuditLogSyslogEnabled, false)
+	debugMap["AuditLogSyslogNetwork"] = helpers.DebugValue(c.AuditLogSyslogNetwork, false)
+	debugMap["AuditLogSyslogAddress"] = helpers.DebugValue(c.AuditLogSyslogAddress, false)
+	debugMap["AuditLogHTTPURL"] = helpers.DebugValue(c.AuditLogHTTPURL, false)
+	debugMap["AuditLogCheckDecisions"] = helpers.DebugValue(c.AuditLogCheckDecisions, false)
 	debugMap["DisableGRPCLatencyHistogram"] = helpers.DebugValue(c.DisableGRPCLatencyHistogram, false)
 	return debugMap
 }
@@ -197,6 +301,48 @@ func SetPresharedSecureKey(presharedSecureKey []string) ConfigOption {
 	}
 }
 
+// WithGRPCJWTIssuer returns an option that can set GRPCJWTIssuer on a Config
+func WithGRPCJWTIssuer(gRPCJWTIssuer string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCJWTIssuer = gRPCJWTIssuer
+	}
+}
+
+// WithGRPCJWTAudience returns an option that can set GRPCJWTAudience on a Config
+func WithGRPCJWTAudience(gRPCJWTAudience string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCJWTAudience = gRPCJWTAudience
+	}
+}
+
+// WithGRPCJWTJWKSURL returns an option that can set GRPCJWTJWKSURL on a Config
+func WithGRPCJWTJWKSURL(gRPCJWTJWKSURL string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCJWTJWKSURL = gRPCJWTJWKSURL
+	}
+}
+
+// WithGRPCJWTIdentityClaim returns an option that can set GRPCJWTIdentityClaim on a Config
+func WithGRPCJWTIdentityClaim(gRPCJWTIdentityClaim string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCJWTIdentityClaim = gRPCJWTIdentityClaim
+	}
+}
+
+// WithGRPCTenantPrefixes returns an option that can append GRPCTenantPrefixess to Config.GRPCTenantPrefixes
+func WithGRPCTenantPrefixes(gRPCTenantPrefixes string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCTenantPrefixes = append(c.GRPCTenantPrefixes, gRPCTenantPrefixes)
+	}
+}
+
+// SetGRPCTenantPrefixes returns an option that can set GRPCTenantPrefixes on a Config
+func SetGRPCTenantPrefixes(gRPCTenantPrefixes []string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCTenantPrefixes = gRPCTenantPrefixes
+	}
+}
+
 // WithShutdownGracePeriod returns an option that can set ShutdownGracePeriod on a Config
 func WithShutdownGracePeriod(shutdownGracePeriod time.Duration) ConfigOption {
 	return func(c *Config) {
@@ -211,6 +357,27 @@ func WithDisableVersionResponse(disableVersionResponse bool) ConfigOption {
 	}
 }
 
+// WithGRPCTenantMaxRelationships returns an option that can set GRPCTenantMaxRelationships on a Config
+func WithGRPCTenantMaxRelationships(gRPCTenantMaxRelationships uint64) ConfigOption {
+	return func(c *Config) {
+		c.GRPCTenantMaxRelationships = gRPCTenantMaxRelationships
+	}
+}
+
+// WithGRPCTenantMaxObjectDefinitions returns an option that can set GRPCTenantMaxObjectDefinitions on a Config
+func WithGRPCTenantMaxObjectDefinitions(gRPCTenantMaxObjectDefinitions uint64) ConfigOption {
+	return func(c *Config) {
+		c.GRPCTenantMaxObjectDefinitions = gRPCTenantMaxObjectDefinitions
+	}
+}
+
+// WithGRPCTenantMaxRequestsPerSecond returns an option that can set GRPCTenantMaxRequestsPerSecond on a Config
+func WithGRPCTenantMaxRequestsPerSecond(gRPCTenantMaxRequestsPerSecond float64) ConfigOption {
+	return func(c *Config) {
+		c.GRPCTenantMaxRequestsPerSecond = gRPCTenantMaxRequestsPerSecond
+	}
+}
+
 // WithHTTPGateway returns an option that can set HTTPGateway on a Config
 func WithHTTPGateway(hTTPGateway util.HTTPServerConfig) ConfigOption {
 	return func(c *Config) {
@@ -253,6 +420,55 @@ func SetHTTPGatewayCorsAllowedOrigins(hTTPGatewayCorsAllowedOrigins []string) Co
 	}
 }
 
+// WithHTTPGatewayGraphQLEnabled returns an option that can set HTTPGatewayGraphQLEnabled on a Config
+func WithHTTPGatewayGraphQLEnabled(hTTPGatewayGraphQLEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayGraphQLEnabled = hTTPGatewayGraphQLEnabled
+	}
+}
+
+// WithHTTPGatewayOPAEnabled returns an option that can set HTTPGatewayOPAEnabled on a Config
+func WithHTTPGatewayOPAEnabled(hTTPGatewayOPAEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayOPAEnabled = hTTPGatewayOPAEnabled
+	}
+}
+
+// WithHTTPGatewayLookupWatchEnabled returns an option that can set HTTPGatewayLookupWatchEnabled on a Config
+func WithHTTPGatewayLookupWatchEnabled(hTTPGatewayLookupWatchEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayLookupWatchEnabled = hTTPGatewayLookupWatchEnabled
+	}
+}
+
+// WithHTTPGatewaySimulateEnabled returns an option that can set HTTPGatewaySimulateEnabled on a Config
+func WithHTTPGatewaySimulateEnabled(hTTPGatewaySimulateEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewaySimulateEnabled = hTTPGatewaySimulateEnabled
+	}
+}
+
+// WithHTTPGatewayCapabilitiesEnabled returns an option that can set HTTPGatewayCapabilitiesEnabled on a Config
+func WithHTTPGatewayCapabilitiesEnabled(hTTPGatewayCapabilitiesEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayCapabilitiesEnabled = hTTPGatewayCapabilitiesEnabled
+	}
+}
+
+// WithHTTPGatewayKetoEnabled returns an option that can set HTTPGatewayKetoEnabled on a Config
+func WithHTTPGatewayKetoEnabled(hTTPGatewayKetoEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayKetoEnabled = hTTPGatewayKetoEnabled
+	}
+}
+
+// WithHTTPGatewayKetoSubjectType returns an option that can set HTTPGatewayKetoSubjectType on a Config
+func WithHTTPGatewayKetoSubjectType(hTTPGatewayKetoSubjectType string) ConfigOption {
+	return func(c *Config) {
+		c.HTTPGatewayKetoSubjectType = hTTPGatewayKetoSubjectType
+	}
+}
+
 // WithDatastoreConfig returns an option that can set DatastoreConfig on a Config
 func WithDatastoreConfig(datastoreConfig datastore.Config) ConfigOption {
 	return func(c *Config) {
@@ -302,6 +518,13 @@ func WithNamespaceCacheConfig(namespaceCacheConfig CacheConfig) ConfigOption {
 	}
 }
 
+// WithRelationshipCacheConfig returns an option that can set RelationshipCacheConfig on a Config
+func WithRelationshipCacheConfig(relationshipCacheConfig CacheConfig) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipCacheConfig = relationshipCacheConfig
+	}
+}
+
 // WithSchemaPrefixesRequired returns an option that can set SchemaPrefixesRequired on a Config
 func WithSchemaPrefixesRequired(schemaPrefixesRequired bool) ConfigOption {
 	return func(c *Config) {
@@ -351,6 +574,20 @@ func WithDispatchUpstreamCAPath(dispatchUpstreamCAPath string) ConfigOption {
 	}
 }
 
+// WithDispatchUpstreamTLSCertPath returns an option that can set DispatchUpstreamTLSCertPath on a Config
+func WithDispatchUpstreamTLSCertPath(dispatchUpstreamTLSCertPath string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUpstreamTLSCertPath = dispatchUpstreamTLSCertPath
+	}
+}
+
+// WithDispatchUpstreamTLSKeyPath returns an option that can set DispatchUpstreamTLSKeyPath on a Config
+func WithDispatchUpstreamTLSKeyPath(dispatchUpstreamTLSKeyPath string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUpstreamTLSKeyPath = dispatchUpstreamTLSKeyPath
+	}
+}
+
 // WithDispatchUpstreamTimeout returns an option that can set DispatchUpstreamTimeout on a Config
 func WithDispatchUpstreamTimeout(dispatchUpstreamTimeout time.Duration) ConfigOption {
 	return func(c *Config) {
@@ -505,6 +742,195 @@ func WithMetricsAPI(metricsAPI util.HTTPServerConfig) ConfigOption {
 	}
 }
 
+// WithMetricsAPICacheFlushEnabled returns an option that can set MetricsAPICacheFlushEnabled on a Config
+func WithMetricsAPICacheFlushEnabled(metricsAPICacheFlushEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.MetricsAPICacheFlushEnabled = metricsAPICacheFlushEnabled
+	}
+}
+
+// WithExtAuthzServer returns an option that can set ExtAuthzServer on a Config
+func WithExtAuthzServer(extAuthzServer util.GRPCServerConfig) ConfigOption {
+	return func(c *Config) {
+		c.ExtAuthzServer = extAuthzServer
+	}
+}
+
+// WithExtAuthzConfigPath returns an option that can set ExtAuthzConfigPath on a Config
+func WithExtAuthzConfigPath(extAuthzConfigPath string) ConfigOption {
+	return func(c *Config) {
+		c.ExtAuthzConfigPath = extAuthzConfigPath
+	}
+}
+
+// WithLeaderElectionEnabled returns an option that can set LeaderElectionEnabled on a Config
+func WithLeaderElectionEnabled(leaderElectionEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.LeaderElectionEnabled = leaderElectionEnabled
+	}
+}
+
+// WithLeaderElectionNamespace returns an option that can set LeaderElectionNamespace on a Config
+func WithLeaderElectionNamespace(leaderElectionNamespace string) ConfigOption {
+	return func(c *Config) {
+		c.LeaderElectionNamespace = leaderElectionNamespace
+	}
+}
+
+// WithLeaderElectionLeasePrefix returns an option that can set LeaderElectionLeasePrefix on a Config
+func WithLeaderElectionLeasePrefix(leaderElectionLeasePrefix string) ConfigOption {
+	return func(c *Config) {
+		c.LeaderElectionLeasePrefix = leaderElectionLeasePrefix
+	}
+}
+
+// WithLeaderElectionLeaseDuration returns an option that can set LeaderElectionLeaseDuration on a Config
+func WithLeaderElectionLeaseDuration(leaderElectionLeaseDuration time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.LeaderElectionLeaseDuration = leaderElectionLeaseDuration
+	}
+}
+
+// WithWebhookURLs returns an option that can append WebhookURLss to Config.WebhookURLs
+func WithWebhookURLs(webhookURLs string) ConfigOption {
+	return func(c *Config) {
+		c.WebhookURLs = append(c.WebhookURLs, webhookURLs)
+	}
+}
+
+// SetWebhookURLs returns an option that can set WebhookURLs on a Config
+func SetWebhookURLs(webhookURLs []string) ConfigOption {
+	return func(c *Config) {
+		c.WebhookURLs = webhookURLs
+	}
+}
+
+// WithWebhookSecret returns an option that can set WebhookSecret on a Config
+func WithWebhookSecret(webhookSecret string) ConfigOption {
+	return func(c *Config) {
+		c.WebhookSecret = webhookSecret
+	}
+}
+
+// WithWebhookBatchSize returns an option that can set WebhookBatchSize on a Config
+func WithWebhookBatchSize(webhookBatchSize int) ConfigOption {
+	return func(c *Config) {
+		c.WebhookBatchSize = webhookBatchSize
+	}
+}
+
+// WithWebhookFlushInterval returns an option that can set WebhookFlushInterval on a Config
+func WithWebhookFlushInterval(webhookFlushInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.WebhookFlushInterval = webhookFlushInterval
+	}
+}
+
+// WithWebhookMaxRetries returns an option that can set WebhookMaxRetries on a Config
+func WithWebhookMaxRetries(webhookMaxRetries uint64) ConfigOption {
+	return func(c *Config) {
+		c.WebhookMaxRetries = webhookMaxRetries
+	}
+}
+
+// WithChangePublisherKafkaBrokers returns an option that can append ChangePublisherKafkaBrokerss to Config.ChangePublisherKafkaBrokers
+func WithChangePublisherKafkaBrokers(changePublisherKafkaBrokers string) ConfigOption {
+	return func(c *Config) {
+		c.ChangePublisherKafkaBrokers = append(c.ChangePublisherKafkaBrokers, changePublisherKafkaBrokers)
+	}
+}
+
+// SetChangePublisherKafkaBrokers returns an option that can set ChangePublisherKafkaBrokers on a Config
+func SetChangePublisherKafkaBrokers(changePublisherKafkaBrokers []string) ConfigOption {
+	return func(c *Config) {
+		c.ChangePublisherKafkaBrokers = changePublisherKafkaBrokers
+	}
+}
+
+// WithChangePublisherKafkaTopic returns an option that can set ChangePublisherKafkaTopic on a Config
+func WithChangePublisherKafkaTopic(changePublisherKafkaTopic string) ConfigOption {
+	return func(c *Config) {
+		c.ChangePublisherKafkaTopic = changePublisherKafkaTopic
+	}
+}
+
+// WithChangePublisherNATSURL returns an option that can set ChangePublisherNATSURL on a Config
+func WithChangePublisherNATSURL(changePublisherNATSURL string) ConfigOption {
+	return func(c *Config) {
+		c.ChangePublisherNATSURL = changePublisherNATSURL
+	}
+}
+
+// WithChangePublisherNATSSubject returns an option that can set ChangePublisherNATSSubject on a Config
+func WithChangePublisherNATSSubject(changePublisherNATSSubject string) ConfigOption {
+	return func(c *Config) {
+		c.ChangePublisherNATSSubject = changePublisherNATSSubject
+	}
+}
+
+// WithChangePublisherBatchSize returns an option that can set ChangePublisherBatchSize on a Config
+func WithChangePublisherBatchSize(changePublisherBatchSize int) ConfigOption {
+	return func(c *Config) {
+		c.ChangePublisherBatchSize = changePublisherBatchSize
+	}
+}
+
+// WithGroupSyncSCIMURL returns an option that can set GroupSyncSCIMURL on a Config
+func WithGroupSyncSCIMURL(groupSyncSCIMURL string) ConfigOption {
+	return func(c *Config) {
+		c.GroupSyncSCIMURL = groupSyncSCIMURL
+	}
+}
+
+// WithGroupSyncSCIMToken returns an option that can set GroupSyncSCIMToken on a Config
+func WithGroupSyncSCIMToken(groupSyncSCIMToken string) ConfigOption {
+	return func(c *Config) {
+		c.GroupSyncSCIMToken = groupSyncSCIMToken
+	}
+}
+
+// WithGroupSyncInterval returns an option that can set GroupSyncInterval on a Config
+func WithGroupSyncInterval(groupSyncInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.GroupSyncInterval = groupSyncInterval
+	}
+}
+
+// WithGroupSyncGroupType returns an option that can set GroupSyncGroupType on a Config
+func WithGroupSyncGroupType(groupSyncGroupType string) ConfigOption {
+	return func(c *Config) {
+		c.GroupSyncGroupType = groupSyncGroupType
+	}
+}
+
+// WithGroupSyncUserType returns an option that can set GroupSyncUserType on a Config
+func WithGroupSyncUserType(groupSyncUserType string) ConfigOption {
+	return func(c *Config) {
+		c.GroupSyncUserType = groupSyncUserType
+	}
+}
+
+// WithGroupSyncMemberRelation returns an option that can set GroupSyncMemberRelation on a Config
+func WithGroupSyncMemberRelation(groupSyncMemberRelation string) ConfigOption {
+	return func(c *Config) {
+		c.GroupSyncMemberRelation = groupSyncMemberRelation
+	}
+}
+
+// WithGroupSyncSyncedRelation returns an option that can set GroupSyncSyncedRelation on a Config
+func WithGroupSyncSyncedRelation(groupSyncSyncedRelation string) ConfigOption {
+	return func(c *Config) {
+		c.GroupSyncSyncedRelation = groupSyncSyncedRelation
+	}
+}
+
+// WithGroupSyncMaxDeleteFraction returns an option that can set GroupSyncMaxDeleteFraction on a Config
+func WithGroupSyncMaxDeleteFraction(groupSyncMaxDeleteFraction float64) ConfigOption {
+	return func(c *Config) {
+		c.GroupSyncMaxDeleteFraction = groupSyncMaxDeleteFraction
+	}
+}
+
 // WithUnaryMiddlewareModification returns an option that can append UnaryMiddlewareModifications to Config.UnaryMiddlewareModification
 func WithUnaryMiddlewareModification(unaryMiddlewareModification MiddlewareModification[grpc.UnaryServerInterceptor]) ConfigOption {
 	return func(c *Config) {
@@ -603,6 +1029,69 @@ func WithEnableResponseLogs(enableResponseLogs bool) ConfigOption {
 	}
 }
 
+// WithEnableAccessLogs returns an option that can set EnableAccessLogs on a Config
+func WithEnableAccessLogs(enableAccessLogs bool) ConfigOption {
+	return func(c *Config) {
+		c.EnableAccessLogs = enableAccessLogs
+	}
+}
+
+// WithAccessLogSampleRate returns an option that can set AccessLogSampleRate on a Config
+func WithAccessLogSampleRate(accessLogSampleRate float64) ConfigOption {
+	return func(c *Config) {
+		c.AccessLogSampleRate = accessLogSampleRate
+	}
+}
+
+// WithSlowQueryThreshold returns an option that can set SlowQueryThreshold on a Config
+func WithSlowQueryThreshold(slowQueryThreshold time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.SlowQueryThreshold = slowQueryThreshold
+	}
+}
+
+// WithAuditLogFilePath returns an option that can set AuditLogFilePath on a Config
+func WithAuditLogFilePath(auditLogFilePath string) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogFilePath = auditLogFilePath
+	}
+}
+
+// WithAuditLogSyslogEnabled returns an option that can set AuditLogSyslogEnabled on a Config
+func WithAuditLogSyslogEnabled(auditLogSyslogEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogSyslogEnabled = auditLogSyslogEnabled
+	}
+}
+
+// WithAuditLogSyslogNetwork returns an option that can set AuditLogSyslogNetwork on a Config
+func WithAuditLogSyslogNetwork(auditLogSyslogNetwork string) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogSyslogNetwork = auditLogSyslogNetwork
+	}
+}
+
+// WithAuditLogSyslogAddress returns an option that can set AuditLogSyslogAddress on a Config
+func WithAuditLogSyslogAddress(auditLogSyslogAddress string) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogSyslogAddress = auditLogSyslogAddress
+	}
+}
+
+// WithAuditLogHTTPURL returns an option that can set AuditLogHTTPURL on a Config
+func WithAuditLogHTTPURL(auditLogHTTPURL string) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogHTTPURL = auditLogHTTPURL
+	}
+}
+
+// WithAuditLogCheckDecisions returns an option that can set AuditLogCheckDecisions on a Config
+func WithAuditLogCheckDecisions(auditLogCheckDecisions bool) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogCheckDecisions = auditLogCheckDecisions
+	}
+}
+
 // WithDisableGRPCLatencyHistogram returns an option that can set DisableGRPCLatencyHistogram on a Config
 func WithDisableGRPCLatencyHistogram(disableGRPCLatencyHistogram bool) ConfigOption {
 	return func(c *Config) {
@@ -38,3 +38,21 @@ func SignalContextWithGracePeriod(ctx context.Context, gracePeriod time.Duration
 
 	return newCtx
 }
+
+// OnHangup calls fn each time a SIGHUP signal is received, until the context is cancelled.
+func OnHangup(ctx context.Context, fn func()) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				log.Ctx(ctx).Info().Msg("received hangup")
+				fn()
+			}
+		}
+	}()
+}
@@ -19,6 +19,9 @@ func RegisterTestingFlags(cmd *cobra.Command, config *testserver.Config) {
 	util.RegisterHTTPServerFlags(cmd.Flags(), &config.ReadOnlyHTTPGateway, "readonly-http", "read-only HTTP", ":8444", false)
 
 	cmd.Flags().StringSliceVar(&config.LoadConfigs, "load-configs", []string{}, "configuration yaml files to load")
+	cmd.Flags().StringVar(&config.LoadSchema, "load-schema", "", "schema file to load")
+	cmd.Flags().StringVar(&config.LoadRelationships, "load-relationships", "", "file of relationships to load, one per line")
+	cmd.Flags().BoolVar(&config.IsolatePerToken, "isolate-per-token", true, "serve a separate datastore for each client-supplied auth token; if false, all clients share a single datastore")
 
 	// Flags for API behavior
 	cmd.Flags().Uint16Var(&config.MaximumUpdatesPerWrite, "write-relationships-max-updates-per-call", 1000, "maximum number of updates allowed for WriteRelationships calls")
@@ -31,7 +34,7 @@ func NewTestingCommand(programName string, config *testserver.Config) *cobra.Com
 	return &cobra.Command{
 		Use:     "serve-testing",
 		Short:   "test server with an in-memory datastore",
-		Long:    "An in-memory spicedb server which serves completely isolated datastores per client-supplied auth token used.",
+		Long:    "An in-memory spicedb server which serves completely isolated datastores per client-supplied auth token used, each initialized with the loaded schema and relationships.",
 		PreRunE: server.DefaultPreRunE(programName),
 		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
 			signalctx := SignalContextWithGracePeriod(
@@ -17,6 +17,7 @@ import (
 	"github.com/authzed/spicedb/internal/middleware/pertoken"
 	"github.com/authzed/spicedb/internal/middleware/readonly"
 	"github.com/authzed/spicedb/internal/middleware/servicespecific"
+	"github.com/authzed/spicedb/internal/middleware/validation"
 	"github.com/authzed/spicedb/internal/services"
 	"github.com/authzed/spicedb/internal/services/health"
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
@@ -33,6 +34,9 @@ type Config struct {
 	HTTPGateway                util.HTTPServerConfig `debugmap:"visible"`
 	ReadOnlyHTTPGateway        util.HTTPServerConfig `debugmap:"visible"`
 	LoadConfigs                []string              `debugmap:"visible"`
+	LoadSchema                 string                `debugmap:"visible"`
+	LoadRelationships          string                `debugmap:"visible"`
+	IsolatePerToken            bool                  `debugmap:"visible" default:"true"`
 	MaximumUpdatesPerWrite     uint16                `debugmap:"visible"`
 	MaximumPreconditionCount   uint16                `debugmap:"visible"`
 	MaxCaveatContextSize       int                   `debugmap:"visible"`
@@ -54,7 +58,11 @@ func (dr datastoreReady) ReadyState(_ context.Context) (datastore.ReadyState, er
 func (c *Config) Complete() (RunnableTestServer, error) {
 	dispatcher := graph.NewLocalOnlyDispatcher(10)
 
-	datastoreMiddleware := pertoken.NewMiddleware(c.LoadConfigs)
+	datastoreMiddleware := pertoken.NewMiddleware(pertoken.Seed{
+		ConfigFilePaths:       c.LoadConfigs,
+		SchemaFilePath:        c.LoadSchema,
+		RelationshipsFilePath: c.LoadRelationships,
+	}, c.IsolatePerToken)
 
 	healthManager := health.NewHealthManager(dispatcher, &datastoreReady{})
 
@@ -76,12 +84,14 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 	}
 	gRPCSrv, err := c.GRPCServer.Complete(zerolog.InfoLevel, registerServices,
 		grpc.ChainUnaryInterceptor(
+			validation.UnaryServerInterceptor(),
 			datastoreMiddleware.UnaryServerInterceptor(),
 			dispatchmw.UnaryServerInterceptor(dispatcher),
 			consistencymw.UnaryServerInterceptor(),
 			servicespecific.UnaryServerInterceptor,
 		),
 		grpc.ChainStreamInterceptor(
+			validation.StreamServerInterceptor(),
 			datastoreMiddleware.StreamServerInterceptor(),
 			dispatchmw.StreamServerInterceptor(dispatcher),
 			consistencymw.StreamServerInterceptor(),
@@ -94,6 +104,7 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 
 	readOnlyGRPCSrv, err := c.ReadOnlyGRPCServer.Complete(zerolog.InfoLevel, registerServices,
 		grpc.ChainUnaryInterceptor(
+			validation.UnaryServerInterceptor(),
 			datastoreMiddleware.UnaryServerInterceptor(),
 			readonly.UnaryServerInterceptor(),
 			dispatchmw.UnaryServerInterceptor(dispatcher),
@@ -101,6 +112,7 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 			servicespecific.UnaryServerInterceptor,
 		),
 		grpc.ChainStreamInterceptor(
+			validation.StreamServerInterceptor(),
 			datastoreMiddleware.StreamServerInterceptor(),
 			readonly.StreamServerInterceptor(),
 			dispatchmw.StreamServerInterceptor(dispatcher),
@@ -112,7 +124,10 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 		return nil, err
 	}
 
-	gatewayHandler, err := gateway.NewHandler(context.TODO(), c.GRPCServer.Address, c.GRPCServer.TLSCertPath)
+	gatewayHandler, err := gateway.NewHandler(context.TODO(), gateway.Config{
+		UpstreamAddr:        c.GRPCServer.Address,
+		UpstreamTLSCertPath: c.GRPCServer.TLSCertPath,
+	})
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to initialize rest gateway")
 	}
@@ -126,7 +141,10 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 		return nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
 	}
 
-	readOnlyGatewayHandler, err := gateway.NewHandler(context.TODO(), c.ReadOnlyGRPCServer.Address, c.ReadOnlyGRPCServer.TLSCertPath)
+	readOnlyGatewayHandler, err := gateway.NewHandler(context.TODO(), gateway.Config{
+		UpstreamAddr:        c.ReadOnlyGRPCServer.Address,
+		UpstreamTLSCertPath: c.ReadOnlyGRPCServer.TLSCertPath,
+	})
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to initialize rest gateway")
 	}
@@ -36,6 +36,9 @@ func (c *Config) ToOption() ConfigOption {
 		to.HTTPGateway = c.HTTPGateway
 		to.ReadOnlyHTTPGateway = c.ReadOnlyHTTPGateway
 		to.LoadConfigs = c.LoadConfigs
+		to.LoadSchema = c.LoadSchema
+		to.LoadRelationships = c.LoadRelationships
+		to.IsolatePerToken = c.IsolatePerToken
 		to.MaximumUpdatesPerWrite = c.MaximumUpdatesPerWrite
 		to.MaximumPreconditionCount = c.MaximumPreconditionCount
 		to.MaxCaveatContextSize = c.MaxCaveatContextSize
@@ -51,6 +54,9 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["HTTPGateway"] = helpers.DebugValue(c.HTTPGateway, false)
 	debugMap["ReadOnlyHTTPGateway"] = helpers.DebugValue(c.ReadOnlyHTTPGateway, false)
 	debugMap["LoadConfigs"] = helpers.DebugValue(c.LoadConfigs, false)
+	debugMap["LoadSchema"] = helpers.DebugValue(c.LoadSchema, false)
+	debugMap["LoadRelationships"] = helpers.DebugValue(c.LoadRelationships, false)
+	debugMap["IsolatePerToken"] = helpers.DebugValue(c.IsolatePerToken, false)
 	debugMap["MaximumUpdatesPerWrite"] = helpers.DebugValue(c.MaximumUpdatesPerWrite, false)
 	debugMap["MaximumPreconditionCount"] = helpers.DebugValue(c.MaximumPreconditionCount, false)
 	debugMap["MaxCaveatContextSize"] = helpers.DebugValue(c.MaxCaveatContextSize, false)
@@ -116,6 +122,27 @@ func SetLoadConfigs(loadConfigs []string) ConfigOption {
 	}
 }
 
+// WithLoadSchema returns an option that can set LoadSchema on a Config
+func WithLoadSchema(loadSchema string) ConfigOption {
+	return func(c *Config) {
+		c.LoadSchema = loadSchema
+	}
+}
+
+// WithLoadRelationships returns an option that can set LoadRelationships on a Config
+func WithLoadRelationships(loadRelationships string) ConfigOption {
+	return func(c *Config) {
+		c.LoadRelationships = loadRelationships
+	}
+}
+
+// WithIsolatePerToken returns an option that can set IsolatePerToken on a Config
+func WithIsolatePerToken(isolatePerToken bool) ConfigOption {
+	return func(c *Config) {
+		c.IsolatePerToken = isolatePerToken
+	}
+}
+
 // WithMaximumUpdatesPerWrite returns an option that can set MaximumUpdatesPerWrite on a Config
 func WithMaximumUpdatesPerWrite(maximumUpdatesPerWrite uint16) ConfigOption {
 	return func(c *Config) {
@@ -46,6 +46,31 @@ type GRPCServerConfig struct {
 	ClientCAPath string        `debugmap:"visible"`
 	MaxWorkers   uint32        `debugmap:"visible"`
 
+	// MTLSClientCAPath is the path to a CA bundle used to verify client certificates. When set, clients
+	// must present a certificate signed by one of these CAs.
+	MTLSClientCAPath string `debugmap:"visible"`
+
+	// MaxRecvMsgSize and MaxSendMsgSize are the maximum sizes, in bytes, of the messages the server
+	// can receive and send. Zero uses the gRPC defaults.
+	MaxRecvMsgSize int `debugmap:"visible"`
+	MaxSendMsgSize int `debugmap:"visible"`
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams per connection. Zero means
+	// no limit.
+	MaxConcurrentStreams uint32 `debugmap:"visible"`
+
+	// KeepaliveTime and KeepaliveTimeout are how long the server waits before pinging an idle
+	// connection, and how long it then waits for the ping to be acknowledged before closing it.
+	// Zero uses the gRPC defaults.
+	KeepaliveTime    time.Duration `debugmap:"visible"`
+	KeepaliveTimeout time.Duration `debugmap:"visible"`
+
+	// KeepaliveMinTime and KeepalivePermitWithoutStream are the keepalive enforcement policy:
+	// clients which ping more often than KeepaliveMinTime, or without any active stream unless
+	// permitted, have their connections closed. When both are unset, the gRPC defaults apply.
+	KeepaliveMinTime             time.Duration `debugmap:"visible"`
+	KeepalivePermitWithoutStream bool          `debugmap:"visible"`
+
 	flagPrefix string
 }
 
@@ -54,7 +79,15 @@ type GRPCServerConfig struct {
 // - "$PREFIX-addr"
 // - "$PREFIX-tls-cert-path"
 // - "$PREFIX-tls-key-path"
+// - "$PREFIX-mtls-client-ca-path"
 // - "$PREFIX-max-conn-age"
+// - "$PREFIX-max-recv-message-size"
+// - "$PREFIX-max-send-message-size"
+// - "$PREFIX-max-concurrent-streams"
+// - "$PREFIX-keepalive-time"
+// - "$PREFIX-keepalive-timeout"
+// - "$PREFIX-keepalive-min-time"
+// - "$PREFIX-keepalive-permit-without-stream"
 func RegisterGRPCServerFlags(flags *pflag.FlagSet, config *GRPCServerConfig, flagPrefix, serviceName, defaultAddr string, defaultEnabled bool) {
 	flagPrefix = stringz.DefaultEmpty(flagPrefix, "grpc")
 	serviceName = stringz.DefaultEmpty(serviceName, "grpc")
@@ -65,9 +98,17 @@ func RegisterGRPCServerFlags(flags *pflag.FlagSet, config *GRPCServerConfig, fla
 	flags.StringVar(&config.Network, flagPrefix+"-network", "tcp", "network type to serve "+serviceName+` ("tcp", "tcp4", "tcp6", "unix", "unixpacket")`)
 	flags.StringVar(&config.TLSCertPath, flagPrefix+"-tls-cert-path", "", "local path to the TLS certificate used to serve "+serviceName)
 	flags.StringVar(&config.TLSKeyPath, flagPrefix+"-tls-key-path", "", "local path to the TLS key used to serve "+serviceName)
+	flags.StringVar(&config.MTLSClientCAPath, flagPrefix+"-mtls-client-ca-path", "", "local path to a CA bundle used to verify client certificates for "+serviceName+"; enables mutual TLS")
 	flags.DurationVar(&config.MaxConnAge, flagPrefix+"-max-conn-age", 30*time.Second, "how long a connection serving "+serviceName+" should be able to live")
 	flags.BoolVar(&config.Enabled, flagPrefix+"-enabled", defaultEnabled, "enable "+serviceName+" gRPC server")
 	flags.Uint32Var(&config.MaxWorkers, flagPrefix+"-max-workers", 0, "set the number of workers for this server (0 value means 1 worker per request)")
+	flags.IntVar(&config.MaxRecvMsgSize, flagPrefix+"-max-recv-message-size", 0, "maximum size in bytes of a message received by "+serviceName+" (0 value means the gRPC default of 4MiB)")
+	flags.IntVar(&config.MaxSendMsgSize, flagPrefix+"-max-send-message-size", 0, "maximum size in bytes of a message sent by "+serviceName+" (0 value means the gRPC default of 2GiB)")
+	flags.Uint32Var(&config.MaxConcurrentStreams, flagPrefix+"-max-concurrent-streams", 0, "maximum number of concurrent streams per connection to "+serviceName+" (0 value means unlimited)")
+	flags.DurationVar(&config.KeepaliveTime, flagPrefix+"-keepalive-time", 2*time.Hour, "how long a connection serving "+serviceName+" can be idle before the server pings the client")
+	flags.DurationVar(&config.KeepaliveTimeout, flagPrefix+"-keepalive-timeout", 20*time.Second, "how long the server waits for a keepalive ping to be acknowledged before closing the connection serving "+serviceName)
+	flags.DurationVar(&config.KeepaliveMinTime, flagPrefix+"-keepalive-min-time", 5*time.Minute, "minimum interval at which clients of "+serviceName+" may send keepalive pings; connections of clients pinging more often are closed")
+	flags.BoolVar(&config.KeepalivePermitWithoutStream, flagPrefix+"-keepalive-permit-without-stream", false, "allow clients of "+serviceName+" to send keepalive pings when there are no active streams")
 }
 
 type (
@@ -83,9 +124,7 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 	if c.BufferSize == 0 {
 		c.BufferSize = 1024 * 1024
 	}
-	opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
-		MaxConnectionAge: c.MaxConnAge,
-	}), grpc.NumStreamWorkers(c.MaxWorkers))
+	opts = append(opts, c.tuningOpts()...)
 
 	tlsOpts, certWatcher, err := c.tlsOpts()
 	if err != nil {
@@ -93,7 +132,7 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 	}
 	opts = append(opts, tlsOpts...)
 
-	clientCreds, err := c.clientCreds()
+	clientCreds, err := c.clientCreds(certWatcher)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +147,7 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 		Str("service", c.flagPrefix).
 		Uint32("workers", c.MaxWorkers).
 		Bool("insecure", c.TLSCertPath == "" && c.TLSKeyPath == "").
+		Bool("mtls", c.MTLSClientCAPath != "").
 		Msg("grpc server started serving")
 
 	srv := grpc.NewServer(opts...)
@@ -134,6 +174,36 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 	}, nil
 }
 
+// tuningOpts returns the server options for message sizes, streams and keepalives. Unset values
+// are left to the gRPC defaults.
+func (c *GRPCServerConfig) tuningOpts() []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionAge: c.MaxConnAge,
+			Time:             c.KeepaliveTime,
+			Timeout:          c.KeepaliveTimeout,
+		}),
+		grpc.NumStreamWorkers(c.MaxWorkers),
+	}
+
+	if c.KeepaliveMinTime > 0 || c.KeepalivePermitWithoutStream {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             c.KeepaliveMinTime,
+			PermitWithoutStream: c.KeepalivePermitWithoutStream,
+		}))
+	}
+	if c.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(c.MaxRecvMsgSize))
+	}
+	if c.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(c.MaxSendMsgSize))
+	}
+	if c.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+	return opts
+}
+
 func (c *GRPCServerConfig) listenerAndDialer() (net.Listener, DialFunc, NetDialFunc, error) {
 	if c.Network == BufferedNetwork {
 		bl := bufconn.Listen(c.BufferSize)
@@ -158,6 +228,8 @@ func (c *GRPCServerConfig) listenerAndDialer() (net.Listener, DialFunc, NetDialF
 
 func (c *GRPCServerConfig) tlsOpts() ([]grpc.ServerOption, *certwatcher.CertWatcher, error) {
 	switch {
+	case c.MTLSClientCAPath != "" && (c.TLSCertPath == "" || c.TLSKeyPath == ""):
+		return nil, nil, fmt.Errorf("client certificate verification requires a TLS certificate and key to be configured")
 	case c.TLSCertPath == "" && c.TLSKeyPath == "":
 		return nil, nil, nil
 	case c.TLSCertPath != "" && c.TLSKeyPath != "":
@@ -165,17 +237,25 @@ func (c *GRPCServerConfig) tlsOpts() ([]grpc.ServerOption, *certwatcher.CertWatc
 		if err != nil {
 			return nil, nil, err
 		}
-		creds := credentials.NewTLS(&tls.Config{
+		tlsConfig := &tls.Config{
 			GetCertificate: watcher.GetCertificate,
 			MinVersion:     tls.VersionTLS12,
-		})
-		return []grpc.ServerOption{grpc.Creds(creds)}, watcher, nil
+		}
+		if c.MTLSClientCAPath != "" {
+			pool, err := x509util.CustomCertPool(c.MTLSClientCAPath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to load client CA: %w", err)
+			}
+			tlsConfig.ClientCAs = pool
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, watcher, nil
 	default:
 		return nil, nil, nil
 	}
 }
 
-func (c *GRPCServerConfig) clientCreds() (credentials.TransportCredentials, error) {
+func (c *GRPCServerConfig) clientCreds(watcher *certwatcher.CertWatcher) (credentials.TransportCredentials, error) {
 	switch {
 	case c.TLSCertPath == "" && c.TLSKeyPath == "":
 		return insecure.NewCredentials(), nil
@@ -191,7 +271,14 @@ func (c *GRPCServerConfig) clientCreds() (credentials.TransportCredentials, erro
 			return nil, err
 		}
 
-		return credentials.NewTLS(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}), nil
+		tlsConfig := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		if c.MTLSClientCAPath != "" && watcher != nil {
+			// Clients dialed through DialContext authenticate using the server's own certificate.
+			tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return watcher.GetCertificate(nil)
+			}
+		}
+		return credentials.NewTLS(tlsConfig), nil
 	default:
 		return nil, nil
 	}
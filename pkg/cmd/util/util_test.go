@@ -2,10 +2,22 @@ package util
 
 import (
 	"context"
+	"crypto/tls"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/testutil"
+	"github.com/authzed/spicedb/pkg/x509util"
 )
 
 func TestDisabledGRPC(t *testing.T) {
@@ -22,3 +34,102 @@ func TestDisabledHTTP(t *testing.T) {
 	require.NoError(t, s.ListenAndServe())
 	s.Close()
 }
+
+func TestGRPCMutualTLS(t *testing.T) {
+	certDir := t.TempDir()
+	ca, caKey := testutil.WriteTestCertificate(t, certDir, "ca", nil, nil)
+	_, _ = testutil.WriteTestCertificate(t, certDir, "server", ca, caKey, BufferedNetwork)
+	otherCA, otherCAKey := testutil.WriteTestCertificate(t, certDir, "otherca", nil, nil)
+	_, _ = testutil.WriteTestCertificate(t, certDir, "other", otherCA, otherCAKey, BufferedNetwork)
+
+	s, err := (&GRPCServerConfig{
+		Network:          BufferedNetwork,
+		Enabled:          true,
+		TLSCertPath:      filepath.Join(certDir, "server.crt"),
+		TLSKeyPath:       filepath.Join(certDir, "server.key"),
+		ClientCAPath:     filepath.Join(certDir, "ca.crt"),
+		MTLSClientCAPath: filepath.Join(certDir, "ca.crt"),
+	}).Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	})
+	require.NoError(t, err)
+	require.False(t, s.Insecure())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = s.Listen(ctx)()
+	}()
+	t.Cleanup(s.GracefulStop)
+
+	checkHealth := func(creds credentials.TransportCredentials) error {
+		conn, err := grpc.DialContext(ctx, BufferedNetwork, grpc.WithContextDialer(s.NetDialContext), grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	// The in-process client presents the server's certificate, which is signed by the trusted CA.
+	conn, err := s.DialContext(ctx)
+	require.NoError(t, err)
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	// A client without a certificate is rejected.
+	pool, err := x509util.CustomCertPool(filepath.Join(certDir, "ca.crt"))
+	require.NoError(t, err)
+	require.Error(t, checkHealth(credentials.NewTLS(&tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	})))
+
+	// A client with a certificate from an untrusted CA is rejected.
+	otherCert, err := tls.LoadX509KeyPair(filepath.Join(certDir, "other.crt"), filepath.Join(certDir, "other.key"))
+	require.NoError(t, err)
+	require.Error(t, checkHealth(credentials.NewTLS(&tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{otherCert},
+		MinVersion:   tls.VersionTLS12,
+	})))
+}
+
+func TestGRPCMaxRecvMsgSize(t *testing.T) {
+	s, err := (&GRPCServerConfig{
+		Network:        BufferedNetwork,
+		Enabled:        true,
+		MaxRecvMsgSize: 64,
+	}).Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = s.Listen(ctx)()
+	}()
+	t.Cleanup(s.GracefulStop)
+
+	conn, err := s.DialContext(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	client := healthpb.NewHealthClient(conn)
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: strings.Repeat("a", 128)})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestGRPCMutualTLSRequiresCertificate(t *testing.T) {
+	_, err := (&GRPCServerConfig{
+		Network:          BufferedNetwork,
+		Enabled:          true,
+		MTLSClientCAPath: "some/ca.crt",
+	}).Complete(zerolog.InfoLevel, func(*grpc.Server) {})
+	require.ErrorContains(t, err, "requires a TLS certificate and key")
+}
@@ -40,6 +40,14 @@ func (g *GRPCServerConfig) ToOption() GRPCServerConfigOption {
 		to.BufferSize = g.BufferSize
 		to.ClientCAPath = g.ClientCAPath
 		to.MaxWorkers = g.MaxWorkers
+		to.MTLSClientCAPath = g.MTLSClientCAPath
+		to.MaxRecvMsgSize = g.MaxRecvMsgSize
+		to.MaxSendMsgSize = g.MaxSendMsgSize
+		to.MaxConcurrentStreams = g.MaxConcurrentStreams
+		to.KeepaliveTime = g.KeepaliveTime
+		to.KeepaliveTimeout = g.KeepaliveTimeout
+		to.KeepaliveMinTime = g.KeepaliveMinTime
+		to.KeepalivePermitWithoutStream = g.KeepalivePermitWithoutStream
 		to.flagPrefix = g.flagPrefix
 	}
 }
@@ -56,6 +64,14 @@ func (g GRPCServerConfig) DebugMap() map[string]any {
 	debugMap["BufferSize"] = helpers.DebugValue(g.BufferSize, false)
 	debugMap["ClientCAPath"] = helpers.DebugValue(g.ClientCAPath, false)
 	debugMap["MaxWorkers"] = helpers.DebugValue(g.MaxWorkers, false)
+	debugMap["MTLSClientCAPath"] = helpers.DebugValue(g.MTLSClientCAPath, false)
+	debugMap["MaxRecvMsgSize"] = helpers.DebugValue(g.MaxRecvMsgSize, false)
+	debugMap["MaxSendMsgSize"] = helpers.DebugValue(g.MaxSendMsgSize, false)
+	debugMap["MaxConcurrentStreams"] = helpers.DebugValue(g.MaxConcurrentStreams, false)
+	debugMap["KeepaliveTime"] = helpers.DebugValue(g.KeepaliveTime, false)
+	debugMap["KeepaliveTimeout"] = helpers.DebugValue(g.KeepaliveTimeout, false)
+	debugMap["KeepaliveMinTime"] = helpers.DebugValue(g.KeepaliveMinTime, false)
+	debugMap["KeepalivePermitWithoutStream"] = helpers.DebugValue(g.KeepalivePermitWithoutStream, false)
 	return debugMap
 }
 
@@ -138,6 +154,62 @@ func WithMaxWorkers(maxWorkers uint32) GRPCServerConfigOption {
 	}
 }
 
+// WithMTLSClientCAPath returns an option that can set MTLSClientCAPath on a GRPCServerConfig
+func WithMTLSClientCAPath(mTLSClientCAPath string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MTLSClientCAPath = mTLSClientCAPath
+	}
+}
+
+// WithMaxRecvMsgSize returns an option that can set MaxRecvMsgSize on a GRPCServerConfig
+func WithMaxRecvMsgSize(maxRecvMsgSize int) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MaxRecvMsgSize = maxRecvMsgSize
+	}
+}
+
+// WithMaxSendMsgSize returns an option that can set MaxSendMsgSize on a GRPCServerConfig
+func WithMaxSendMsgSize(maxSendMsgSize int) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MaxSendMsgSize = maxSendMsgSize
+	}
+}
+
+// WithMaxConcurrentStreams returns an option that can set MaxConcurrentStreams on a GRPCServerConfig
+func WithMaxConcurrentStreams(maxConcurrentStreams uint32) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MaxConcurrentStreams = maxConcurrentStreams
+	}
+}
+
+// WithKeepaliveTime returns an option that can set KeepaliveTime on a GRPCServerConfig
+func WithKeepaliveTime(keepaliveTime time.Duration) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.KeepaliveTime = keepaliveTime
+	}
+}
+
+// WithKeepaliveTimeout returns an option that can set KeepaliveTimeout on a GRPCServerConfig
+func WithKeepaliveTimeout(keepaliveTimeout time.Duration) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.KeepaliveTimeout = keepaliveTimeout
+	}
+}
+
+// WithKeepaliveMinTime returns an option that can set KeepaliveMinTime on a GRPCServerConfig
+func WithKeepaliveMinTime(keepaliveMinTime time.Duration) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.KeepaliveMinTime = keepaliveMinTime
+	}
+}
+
+// WithKeepalivePermitWithoutStream returns an option that can set KeepalivePermitWithoutStream on a GRPCServerConfig
+func WithKeepalivePermitWithoutStream(keepalivePermitWithoutStream bool) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.KeepalivePermitWithoutStream = keepalivePermitWithoutStream
+	}
+}
+
 type HTTPServerConfigOption func(h *HTTPServerConfig)
 
 // NewHTTPServerConfigWithOptions creates a new HTTPServerConfig with the passed in options set
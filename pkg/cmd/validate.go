@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	yamlv2 "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/development"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+)
+
+func RegisterValidateFlags(_ *cobra.Command) {
+}
+
+func NewValidateCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <validation files...>",
+		Short: "validates schemas and relationships against validation files",
+		Long: "Loads the schema and relationships of each validation file, and checks its assertions and expected relations.\n" +
+			"Exits with an error if any file is invalid or any check fails, printing the differences between the expected and computed relations.",
+		Args:    cobra.MinimumNArgs(1),
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE:    termination.PublishError(validateRun),
+	}
+}
+
+func validateRun(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	var failedCount int
+	for _, filename := range args {
+		contents, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("unable to read validation file %s: %w", filename, err)
+		}
+
+		result, err := development.RunValidationFileContents(cmd.Context(), contents)
+		if err != nil {
+			return fmt.Errorf("unable to validate %s: %w", filename, err)
+		}
+
+		if result.Passed() {
+			fmt.Fprintf(out, "%s: ok\n", filename)
+			continue
+		}
+
+		failedCount++
+		for _, devErr := range result.Errors() {
+			printDeveloperError(out, filename, devErr)
+		}
+
+		if len(result.ValidationErrors) > 0 {
+			diff, err := expectedRelationsDiff(contents, result.UpdatedValidationYaml)
+			if err != nil {
+				return fmt.Errorf("unable to compare expected relations of %s: %w", filename, err)
+			}
+			fmt.Fprintf(out, "\nexpected relations differ from those computed:\n%s\n", diff)
+		}
+	}
+
+	if failedCount > 0 {
+		return fmt.Errorf("%d validation file(s) failed", failedCount)
+	}
+
+	return nil
+}
+
+// printDeveloperError prints the error with its location and the source it refers to.
+func printDeveloperError(out io.Writer, filename string, devErr *devinterface.DeveloperError) {
+	location := filename
+	if devErr.Line > 0 {
+		// Errors in the schema are located relative to the schema block.
+		if devErr.Source == devinterface.DeveloperError_SCHEMA {
+			location += ": schema"
+		}
+		location += fmt.Sprintf(":%d:%d", devErr.Line, devErr.Column)
+	}
+
+	fmt.Fprintf(out, "%s: %s: %s\n", location, strings.ToLower(devErr.Kind.String()), devErr.Message)
+	if devErr.Context != "" {
+		fmt.Fprintf(out, "    %s\n", devErr.Context)
+	}
+}
+
+// expectedRelationsDiff returns a unified diff between the expected relations of the validation
+// file and the computed ones. The expected relations are formatted in the same way as the
+// computed ones, so that only the differences in content are reported.
+func expectedRelationsDiff(contents []byte, computed string) (string, error) {
+	var file struct {
+		Validation map[string][]string `yaml:"validation"`
+	}
+	if err := yamlv3.Unmarshal(contents, &file); err != nil {
+		return "", err
+	}
+
+	for _, subjects := range file.Validation {
+		sort.Strings(subjects)
+	}
+
+	expected, err := yamlv2.Marshal(file.Validation)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(computed),
+		FromFile: "expected",
+		ToFile:   "computed",
+		Context:  2,
+	})
+}
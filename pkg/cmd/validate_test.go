@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const validationFile = `schema: |-
+  definition user {}
+
+  definition document {
+    relation editor: user
+    relation viewer: user
+    permission view = viewer + editor
+  }
+relationships: |-
+  document:plan#viewer@user:alice
+  document:plan#editor@user:bob
+assertions:
+  assertTrue:
+    - document:plan#view@user:alice
+  assertFalse:
+    - document:plan#view@user:carol
+`
+
+func runValidate(t *testing.T, args ...string) (string, error) {
+	cmd := NewValidateCommand("spicedb")
+	RegisterValidateFlags(cmd)
+	cmd.PreRunE = nil
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func writeValidationFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "validation.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestValidateCommand(t *testing.T) {
+	path := writeValidationFile(t, validationFile+`validation:
+  document:plan#view:
+    - "[user:bob] is <document:plan#editor>"
+    - "[user:alice] is <document:plan#viewer>"
+`)
+
+	out, err := runValidate(t, path)
+	require.NoError(t, err)
+	require.Equal(t, path+": ok\n", out)
+}
+
+func TestValidateCommandFailedAssertion(t *testing.T) {
+	path := writeValidationFile(t, validationFile+`    - document:plan#view@user:bob
+`)
+
+	out, err := runValidate(t, path)
+	require.ErrorContains(t, err, "1 validation file(s) failed")
+	require.Contains(t, out, path+":17:7: assertion_failed:")
+	require.Contains(t, out, "document:plan#view@user:bob")
+}
+
+func TestValidateCommandExpectedRelationsDiff(t *testing.T) {
+	path := writeValidationFile(t, validationFile+`validation:
+  document:plan#view:
+    - "[user:alice] is <document:plan#viewer>"
+    - "[user:carol] is <document:plan#viewer>"
+`)
+
+	out, err := runValidate(t, path)
+	require.Error(t, err)
+	require.Contains(t, out, "missing expected subject `user:carol`")
+	require.Contains(t, out, "subject `user:bob` found but missing from specified")
+	require.Contains(t, out, "--- expected\n+++ computed\n")
+	require.Contains(t, out, "+- '[user:bob] is <document:plan#editor>'\n")
+	require.Contains(t, out, "-- '[user:carol] is <document:plan#viewer>'\n")
+}
+
+func TestValidateCommandInvalidSchema(t *testing.T) {
+	path := writeValidationFile(t, `schema: |-
+  definition document {
+    relation viewer: user
+  }
+`)
+
+	out, err := runValidate(t, path)
+	require.Error(t, err)
+	require.Contains(t, out, path+": schema:")
+	require.Contains(t, out, "schema_issue")
+}
@@ -198,8 +198,13 @@ type SubjectsSelector struct {
 	OptionalSubjectType string
 
 	// OptionalSubjectIds are the IDs of the subjects to find. If nil or empty, any subject ID will be allowed.
+	// Cannot be used with OptionalSubjectIDPrefix.
 	OptionalSubjectIds []string
 
+	// OptionalSubjectIDPrefix is the prefix to use for subject IDs. If empty, any prefix is allowed.
+	// Cannot be used with OptionalSubjectIds.
+	OptionalSubjectIDPrefix string
+
 	// RelationFilter is the filter to use for the relation(s) of the subjects. If neither field
 	// is set, any relation is allowed.
 	RelationFilter SubjectRelationFilter
@@ -215,6 +220,10 @@ func (ss SubjectsSelector) Test(subject *core.ObjectAndRelation) bool {
 		return false
 	}
 
+	if ss.OptionalSubjectIDPrefix != "" && !strings.HasPrefix(subject.ObjectId, ss.OptionalSubjectIDPrefix) {
+		return false
+	}
+
 	if !ss.RelationFilter.IsEmpty() {
 		if ss.RelationFilter.IncludeEllipsisRelation && subject.Relation == tuple.Ellipsis {
 			return true
@@ -291,8 +300,13 @@ type SubjectsFilter struct {
 	SubjectType string
 
 	// OptionalSubjectIds are the IDs of the subjects to find. If nil or empty, any subject ID will be allowed.
+	// Cannot be used with OptionalSubjectIDPrefix.
 	OptionalSubjectIds []string
 
+	// OptionalSubjectIDPrefix is the prefix to use for subject IDs. If empty, any prefix is allowed.
+	// Cannot be used with OptionalSubjectIds.
+	OptionalSubjectIDPrefix string
+
 	// RelationFilter is the filter to use for the relation(s) of the subjects. If neither field
 	// is set, any relation is allowed.
 	RelationFilter SubjectRelationFilter
@@ -300,9 +314,10 @@ type SubjectsFilter struct {
 
 func (sf SubjectsFilter) AsSelector() SubjectsSelector {
 	return SubjectsSelector{
-		OptionalSubjectType: sf.SubjectType,
-		OptionalSubjectIds:  sf.OptionalSubjectIds,
-		RelationFilter:      sf.RelationFilter,
+		OptionalSubjectType:     sf.SubjectType,
+		OptionalSubjectIds:      sf.OptionalSubjectIds,
+		OptionalSubjectIDPrefix: sf.OptionalSubjectIDPrefix,
+		RelationFilter:          sf.RelationFilter,
 	}
 }
 
@@ -500,6 +515,10 @@ type Datastore interface {
 	// Statistics returns relevant values about the data contained in this cluster.
 	Statistics(ctx context.Context) (Stats, error)
 
+	// UniqueID returns the unique identifier of the datastore. The identifier is stable for the
+	// lifetime of the underlying storage and is shared by all nodes connected to it.
+	UniqueID(ctx context.Context) (string, error)
+
 	// Close closes the data store.
 	Close() error
 }
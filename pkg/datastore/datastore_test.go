@@ -626,6 +626,10 @@ func (f fakeDatastore) Statistics(_ context.Context) (Stats, error) {
 	return Stats{}, nil
 }
 
+func (f fakeDatastore) UniqueID(_ context.Context) (string, error) {
+	return "", nil
+}
+
 func (f fakeDatastore) Close() error {
 	return nil
 }
@@ -55,6 +55,10 @@ type ErrWatchDisabled struct{ error }
 // read-only mode.
 type ErrReadOnly struct{ error }
 
+// ErrNotConnected is returned when the operation cannot be completed because the datastore has
+// not yet connected to its database.
+type ErrNotConnected struct{ error }
+
 // ErrWatchRetryable is returned when a transient/temporary error occurred in watch and indicates that
 // the caller *may* retry the watch after some backoff time.
 type ErrWatchRetryable struct{ error }
@@ -146,6 +150,19 @@ func NewReadonlyErr() error {
 	}
 }
 
+// NewNotConnectedErr constructs an error for when a request has failed because the datastore
+// has not yet connected to its database, with the error of the last connection attempt, if any.
+func NewNotConnectedErr(lastErr error) error {
+	if lastErr == nil {
+		return ErrNotConnected{
+			error: fmt.Errorf("datastore is not yet connected"),
+		}
+	}
+	return ErrNotConnected{
+		error: fmt.Errorf("datastore is not yet connected: %w", lastErr),
+	}
+}
+
 // NewInvalidRevisionErr constructs a new invalid revision error.
 func NewInvalidRevisionErr(revision Revision, reason InvalidRevisionReason) error {
 	switch reason {
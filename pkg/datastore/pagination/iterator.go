@@ -19,13 +19,50 @@ func NewPaginatedIterator(
 	pageSize uint64,
 	order options.SortOrder,
 	startCursor options.Cursor,
+) (datastore.RelationshipIterator, error) {
+	return newPaginatedIterator(ctx, pageSize, startCursor, func(cursor options.Cursor) (datastore.RelationshipIterator, error) {
+		return reader.QueryRelationships(
+			ctx,
+			filter,
+			options.WithSort(order),
+			options.WithLimit(&pageSize),
+			options.WithAfter(cursor),
+		)
+	})
+}
+
+// NewPaginatedReverseIterator creates an implementation of the datastore.Iterator
+// interface that internally paginates over the results of reverse queries, starting
+// from the subjects.
+func NewPaginatedReverseIterator(
+	ctx context.Context,
+	reader datastore.Reader,
+	filter datastore.SubjectsFilter,
+	pageSize uint64,
+	order options.SortOrder,
+	startCursor options.Cursor,
+) (datastore.RelationshipIterator, error) {
+	return newPaginatedIterator(ctx, pageSize, startCursor, func(cursor options.Cursor) (datastore.RelationshipIterator, error) {
+		return reader.ReverseQueryRelationships(
+			ctx,
+			filter,
+			options.WithSortForReverse(order),
+			options.WithLimitForReverse(&pageSize),
+			options.WithAfterForReverse(cursor),
+		)
+	})
+}
+
+func newPaginatedIterator(
+	ctx context.Context,
+	pageSize uint64,
+	startCursor options.Cursor,
+	query func(cursor options.Cursor) (datastore.RelationshipIterator, error),
 ) (datastore.RelationshipIterator, error) {
 	pi := &paginatedIterator{
 		ctx:      ctx,
-		reader:   reader,
-		filter:   filter,
+		query:    query,
 		pageSize: pageSize,
-		order:    order,
 		delegate: common.NewSliceRelationshipIterator(nil, options.ByResource),
 	}
 
@@ -36,10 +73,8 @@ func NewPaginatedIterator(
 
 type paginatedIterator struct {
 	ctx      context.Context
-	reader   datastore.Reader
-	filter   datastore.RelationshipsFilter
+	query    func(cursor options.Cursor) (datastore.RelationshipIterator, error)
 	pageSize uint64
-	order    options.SortOrder
 
 	delegate          datastore.RelationshipIterator
 	returnedFromBatch uint64
@@ -88,13 +123,7 @@ func (pi *paginatedIterator) Next() *core.RelationTuple {
 func (pi *paginatedIterator) startNewBatch(cursor options.Cursor) {
 	pi.delegate.Close()
 	pi.returnedFromBatch = 0
-	pi.delegate, pi.err = pi.reader.QueryRelationships(
-		pi.ctx,
-		pi.filter,
-		options.WithSort(pi.order),
-		options.WithLimit(&pi.pageSize),
-		options.WithAfter(cursor),
-	)
+	pi.delegate, pi.err = pi.query(cursor)
 }
 
 func (pi *paginatedIterator) Cursor() (options.Cursor, error) {
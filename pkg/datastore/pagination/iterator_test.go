@@ -161,7 +161,7 @@ func TestPaginatedIterator(t *testing.T) {
 				})
 			}
 
-			ds := generateMock(tpls, tc.pageSize, options.ByResource)
+			ds := generateMock("QueryRelationships", tpls, tc.pageSize, options.ByResource)
 
 			ctx := context.Background()
 			iter, err := NewPaginatedIterator(ctx, ds, datastore.RelationshipsFilter{
@@ -198,7 +198,47 @@ func TestPaginatedIterator(t *testing.T) {
 	}
 }
 
-func generateMock(tpls []*core.RelationTuple, pageSize uint64, order options.SortOrder) *mockedReader {
+func TestPaginatedReverseIterator(t *testing.T) {
+	for _, pageSize := range []uint64{1, 3, 10} {
+		t.Run(strconv.FormatUint(pageSize, 10), func(t *testing.T) {
+			require := require.New(t)
+
+			tpls := make([]*core.RelationTuple, 0, 10)
+			for i := 0; i < 10; i++ {
+				tpls = append(tpls, &core.RelationTuple{
+					ResourceAndRelation: &core.ObjectAndRelation{
+						Namespace: "document",
+						ObjectId:  strconv.Itoa(i),
+						Relation:  "owner",
+					},
+					Subject: &core.ObjectAndRelation{
+						Namespace: "user",
+						ObjectId:  "tom",
+						Relation:  datastore.Ellipsis,
+					},
+				})
+			}
+
+			ds := generateMock("ReverseQueryRelationships", tpls, pageSize, options.BySubject)
+
+			iter, err := NewPaginatedReverseIterator(context.Background(), ds, datastore.SubjectsFilter{
+				SubjectType: "user",
+			}, pageSize, options.BySubject, nil)
+			require.NoError(err)
+			defer iter.Close()
+
+			var found []*core.RelationTuple
+			for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+				found = append(found, tpl)
+			}
+			require.NoError(iter.Err())
+			require.Equal(tpls, found)
+			require.True(ds.AssertExpectations(t))
+		})
+	}
+}
+
+func generateMock(method string, tpls []*core.RelationTuple, pageSize uint64, order options.SortOrder) *mockedReader {
 	mock := &mockedReader{}
 	tplsLen := uint64(len(tpls))
 
@@ -210,7 +250,7 @@ func generateMock(tpls []*core.RelationTuple, pageSize uint64, order options.Sor
 		}
 
 		iter := common.NewSliceRelationshipIterator(tpls[i:pastLastIndex], order)
-		mock.On("QueryRelationships", last, order, pageSize).Return(iter, nil)
+		mock.On(method, last, order, pageSize).Return(iter, nil)
 		if tplsLen > 0 {
 			last = tpls[pastLastIndex-1]
 		}
@@ -242,9 +282,15 @@ func (m *mockedReader) QueryRelationships(
 func (m *mockedReader) ReverseQueryRelationships(
 	_ context.Context,
 	_ datastore.SubjectsFilter,
-	_ ...options.ReverseQueryOptionsOption,
+	opts ...options.ReverseQueryOptionsOption,
 ) (datastore.RelationshipIterator, error) {
-	panic("not implemented")
+	queryOpts := options.NewReverseQueryOptionsWithOptions(opts...)
+	args := m.Called(queryOpts.AfterForReverse, queryOpts.SortForReverse, *queryOpts.LimitForReverse)
+	potentialRelIter := args.Get(0)
+	if potentialRelIter == nil {
+		return nil, args.Error(1)
+	}
+	return potentialRelIter.(datastore.RelationshipIterator), args.Error(1)
 }
 
 func (m *mockedReader) ReadCaveatByName(_ context.Context, _ string) (caveat *core.CaveatDefinition, lastWritten datastore.Revision, err error) {
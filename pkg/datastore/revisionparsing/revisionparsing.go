@@ -13,6 +13,12 @@ import (
 // ParsingFunc is a function that can parse a string into a revision.
 type ParsingFunc func(revisionStr string) (rev datastore.Revision, err error)
 
+// RevisionFromString parses the string into a revision, so that a ParsingFunc can be used as a
+// zedtoken.RevisionDecoder.
+func (pf ParsingFunc) RevisionFromString(revisionStr string) (datastore.Revision, error) {
+	return pf(revisionStr)
+}
+
 // ParseRevisionStringByDatastoreEngineID defines a map from datastore engine ID to its associated
 // revision parsing function.
 var ParseRevisionStringByDatastoreEngineID = map[string]ParsingFunc{
@@ -43,5 +43,9 @@ func StatsTest(t *testing.T, tester DatastoreTester) {
 		newStats, err := ds.Statistics(ctx)
 		require.NoError(err)
 		require.Equal(newStats.UniqueID, stats.UniqueID, "unique ID must be stable")
+
+		uniqueID, err := ds.UniqueID(ctx)
+		require.NoError(err)
+		require.Equal(stats.UniqueID, uniqueID, "unique ID must match statistics")
 	}
 }
@@ -183,6 +183,14 @@ func SimpleTest(t *testing.T, tester DatastoreTester) {
 			require.NoError(err)
 			tRequire.VerifyIteratorResults(iter, testTuples...)
 
+			// Check a reverse query filtered by subject ID prefix.
+			iter, err = dsReader.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
+				SubjectType:             testUserNamespace,
+				OptionalSubjectIDPrefix: "user0",
+			})
+			require.NoError(err)
+			tRequire.VerifyIteratorResults(iter, testTuples[0])
+
 			// Check limit.
 			if len(testTuples) > 1 {
 				limit := uint64(len(testTuples) - 1)
@@ -838,6 +846,14 @@ func DeleteRelationshipsWithVariousFiltersTest(t *testing.T, tester DatastoreTes
 			relationships:   []string{"document:first#viewer@user:tom", "document:second#viewer@user:tom", "document:fourth#viewer@user:tom", "folder:fsomething#viewer@user:tom"},
 			expectedDeleted: []string{"document:first#viewer@user:tom", "document:fourth#viewer@user:tom", "folder:fsomething#viewer@user:tom"},
 		},
+		{
+			name: "resource id prefix with underscore",
+			filter: &v1.RelationshipFilter{
+				OptionalResourceIdPrefix: "first_",
+			},
+			relationships:   []string{"document:first_doc#viewer@user:tom", "document:firstdoc#viewer@user:tom", "document:first-doc#viewer@user:tom"},
+			expectedDeleted: []string{"document:first_doc#viewer@user:tom"},
+		},
 		{
 			name: "resource relation",
 			filter: &v1.RelationshipFilter{
@@ -1101,6 +1117,18 @@ func QueryRelationshipsWithVariousFiltersTest(t *testing.T, tester DatastoreTest
 				"folder:secondfolder#viewer@user:tom",
 			},
 		},
+		{
+			name: "resource id prefix with underscore",
+			filter: datastore.RelationshipsFilter{
+				OptionalResourceIDPrefix: "first_",
+			},
+			relationships: []string{
+				"document:first_doc#viewer@user:tom",
+				"document:firstdoc#viewer@user:tom",
+				"document:first-doc#viewer@user:tom",
+			},
+			expected: []string{"document:first_doc#viewer@user:tom"},
+		},
 		{
 			name: "resource type and resource id different prefix",
 			filter: datastore.RelationshipsFilter{
@@ -1171,6 +1199,22 @@ func QueryRelationshipsWithVariousFiltersTest(t *testing.T, tester DatastoreTest
 				"folder:someotherfolder#viewer@user:tom",
 			},
 		},
+		{
+			name: "subject id prefix with underscore",
+			filter: datastore.RelationshipsFilter{
+				OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+					{
+						OptionalSubjectIDPrefix: "tom_",
+					},
+				},
+			},
+			relationships: []string{
+				"document:first#viewer@user:tom_smith",
+				"document:first#viewer@user:tomsmith",
+				"document:first#viewer@user:tom-smith",
+			},
+			expected: []string{"document:first#viewer@user:tom_smith"},
+		},
 		{
 			name: "subject ids",
 			filter: datastore.RelationshipsFilter{
@@ -1191,6 +1235,27 @@ func QueryRelationshipsWithVariousFiltersTest(t *testing.T, tester DatastoreTest
 				"folder:someotherfolder#viewer@user:tom",
 			},
 		},
+		{
+			name: "subject id prefix",
+			filter: datastore.RelationshipsFilter{
+				OptionalSubjectsSelectors: []datastore.SubjectsSelector{
+					{
+						OptionalSubjectType:     "user",
+						OptionalSubjectIDPrefix: "to",
+					},
+				},
+			},
+			relationships: []string{
+				"document:first#viewer@user:tom",
+				"document:second#viewer@user:toby",
+				"folder:secondfolder#viewer@user:fred",
+				"folder:someotherfolder#viewer@anotheruser:tom",
+			},
+			expected: []string{
+				"document:first#viewer@user:tom",
+				"document:second#viewer@user:toby",
+			},
+		},
 		{
 			name: "multiple subject ids",
 			filter: datastore.RelationshipsFilter{
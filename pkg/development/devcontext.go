@@ -21,6 +21,7 @@ import (
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/internal/middleware/consistency"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/validation"
 	"github.com/authzed/spicedb/internal/namespace"
 	"github.com/authzed/spicedb/internal/relationships"
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
@@ -70,13 +71,13 @@ func NewDevContext(ctx context.Context, requestContext *devinterface.RequestCont
 
 func newDevContextWithDatastore(ctx context.Context, requestContext *devinterface.RequestContext, ds datastore.Datastore) (*DevContext, *devinterface.DeveloperErrors, error) {
 	// Compile the schema and load its caveats and namespaces into the datastore.
-	compiled, devError, err := CompileSchema(requestContext.Schema)
+	compiled, devErrs, err := CompileSchema(requestContext.Schema)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if devError != nil {
-		return nil, &devinterface.DeveloperErrors{InputErrors: []*devinterface.DeveloperError{devError}}, nil
+	if len(devErrs) > 0 {
+		return nil, &devinterface.DeveloperErrors{InputErrors: devErrs}, nil
 	}
 
 	var inputErrors []*devinterface.DeveloperError
@@ -123,10 +124,12 @@ func (dc *DevContext) RunV1InMemoryService() (*grpc.ClientConn, func(), error) {
 
 	s := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
+			validation.UnaryServerInterceptor(),
 			datastoremw.UnaryServerInterceptor(dc.Datastore),
 			consistency.UnaryServerInterceptor(),
 		),
 		grpc.ChainStreamInterceptor(
+			validation.StreamServerInterceptor(),
 			datastoremw.StreamServerInterceptor(dc.Datastore),
 			consistency.StreamServerInterceptor(),
 		),
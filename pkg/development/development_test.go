@@ -76,6 +76,54 @@ definition document {
 	require.Contains(t, err.Error(), "invalid resource id")
 }
 
+func TestDevelopmentMultipleSchemaErrors(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
+
+	_, devErrs, err := NewDevContext(context.Background(), &devinterface.RequestContext{
+		Schema: `definition user {}
+
+caveat somecaveat(someparam unknowntype) {
+	someparam
+}
+
+definition user {}
+`,
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, devErrs)
+	require.Len(t, devErrs.InputErrors, 2)
+
+	require.Equal(t, uint32(3), devErrs.InputErrors[0].Line)
+	require.Contains(t, devErrs.InputErrors[0].Message, "invalid type for caveat parameter `someparam`")
+
+	require.Equal(t, uint32(7), devErrs.InputErrors[1].Line)
+	require.Equal(t, "found name reused between multiple definitions and/or caveats: user", devErrs.InputErrors[1].Message)
+}
+
+func TestDevelopmentArrowTargetNotFound(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
+
+	_, devErrs, err := NewDevContext(context.Background(), &devinterface.RequestContext{
+		Schema: `definition user {}
+
+definition document {
+	relation parent: user
+	permission view = parent->view
+}
+`,
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, devErrs)
+	require.Len(t, devErrs.InputErrors, 1)
+
+	require.Equal(t, devinterface.DeveloperError_SCHEMA_ISSUE, devErrs.InputErrors[0].Kind)
+	require.Equal(t, uint32(5), devErrs.InputErrors[0].Line)
+	require.Equal(t, "view", devErrs.InputErrors[0].Context)
+	require.Contains(t, devErrs.InputErrors[0].Message, "for arrow `parent->view` under permission `view`")
+}
+
 func TestDevelopmentCaveatedExpectedRels(t *testing.T) {
 	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("github.com/golang/glog.(*loggingT).flushDaemon"), goleak.IgnoreCurrent())
 
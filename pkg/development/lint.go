@@ -0,0 +1,469 @@
+package development
+
+import (
+	"fmt"
+
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// LintWarning is a warning found when linting a schema. Warnings do not prevent a schema from
+// being written, but usually indicate a mistake.
+type LintWarning struct {
+	// DefinitionName is the name of the definition on which the warning was found.
+	DefinitionName string
+
+	// RelationName is the name of the relation or permission on which the warning was found,
+	// if any.
+	RelationName string
+
+	// Message is the human-readable message for the warning.
+	Message string
+
+	// Line is the 1-indexed line of the relation or permission, or zero if unknown.
+	Line uint32
+
+	// Column is the 1-indexed column of the relation or permission, or zero if unknown.
+	Column uint32
+}
+
+func (lw LintWarning) String() string {
+	name := lw.DefinitionName
+	if lw.RelationName != "" {
+		name = tuple.JoinRelRef(lw.DefinitionName, lw.RelationName)
+	}
+
+	if lw.Line == 0 {
+		return fmt.Sprintf("%s: %s", name, lw.Message)
+	}
+
+	return fmt.Sprintf("%d:%d: %s: %s", lw.Line, lw.Column, name, lw.Message)
+}
+
+// LintSchema runs the lint checks over the object definitions in the compiled schema and returns
+// the warnings found, in definition order. The checks flag:
+//   - relations that are not referenced by any permission or subject type
+//   - permissions that always evaluate to the empty set
+//   - permissions that can never be granted, such as intersections over disjoint subject types
+//     or recursion without a base case
+//   - relations and permissions that shadow the name of a definition
+//   - relations with no allowed subject types
+//   - exclusions of `nil`, which have no effect
+func LintSchema(compiled *compiler.CompiledSchema) []LintWarning {
+	l := &linter{
+		definitionNames:   mapz.NewSet[string](),
+		relationsByNS:     make(map[string]map[string]*core.Relation, len(compiled.ObjectDefinitions)),
+		referenced:        mapz.NewSet[string](),
+		referencedByArrow: mapz.NewSet[string](),
+	}
+
+	for _, nsDef := range compiled.ObjectDefinitions {
+		l.definitionNames.Add(nsDef.Name)
+
+		relations := make(map[string]*core.Relation, len(nsDef.Relation))
+		for _, relation := range nsDef.Relation {
+			relations[relation.Name] = relation
+			l.collectReferences(nsDef.Name, relation)
+		}
+		l.relationsByNS[nsDef.Name] = relations
+	}
+
+	l.computeSubjectTypes()
+
+	var warnings []LintWarning
+	for _, nsDef := range compiled.ObjectDefinitions {
+		for _, relation := range nsDef.Relation {
+			for _, message := range l.lintRelation(nsDef.Name, relation) {
+				warning := LintWarning{
+					DefinitionName: nsDef.Name,
+					RelationName:   relation.Name,
+					Message:        message,
+				}
+
+				if relation.SourcePosition != nil {
+					warning.Line = uint32(relation.SourcePosition.ZeroIndexedLineNumber) + 1
+					warning.Column = uint32(relation.SourcePosition.ZeroIndexedColumnPosition) + 1
+				}
+
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+
+	return warnings
+}
+
+type linter struct {
+	definitionNames *mapz.Set[string]
+	relationsByNS   map[string]map[string]*core.Relation
+
+	// referenced holds the `namespace#relation` references found in permissions and subject types.
+	referenced *mapz.Set[string]
+
+	// referencedByArrow holds the names of the relations walked to by arrows, which can
+	// be found on any of the types allowed on the arrow's left side.
+	referencedByArrow *mapz.Set[string]
+
+	// subjectTypes holds, for each `namespace#relation`, the subject types that can be found
+	// under it: object types for direct subjects and wildcards, and `namespace#relation` for
+	// subject sets.
+	subjectTypes map[string]*mapz.Set[string]
+}
+
+func (l *linter) collectReferences(namespaceName string, relation *core.Relation) {
+	for _, allowed := range relation.GetTypeInformation().GetAllowedDirectRelations() {
+		if allowed.GetRelation() != "" && allowed.GetRelation() != tuple.Ellipsis {
+			l.referenced.Add(tuple.JoinRelRef(allowed.Namespace, allowed.GetRelation()))
+		}
+	}
+
+	if relation.UsersetRewrite != nil {
+		l.collectRewriteReferences(namespaceName, relation.UsersetRewrite)
+	}
+}
+
+func (l *linter) collectRewriteReferences(namespaceName string, rewrite *core.UsersetRewrite) {
+	for _, child := range rewriteChildren(rewrite) {
+		switch child := child.ChildType.(type) {
+		case *core.SetOperation_Child_ComputedUserset:
+			l.referenced.Add(tuple.JoinRelRef(namespaceName, child.ComputedUserset.Relation))
+
+		case *core.SetOperation_Child_TupleToUserset:
+			l.referenced.Add(tuple.JoinRelRef(namespaceName, child.TupleToUserset.Tupleset.Relation))
+			l.referencedByArrow.Add(child.TupleToUserset.ComputedUserset.Relation)
+
+		case *core.SetOperation_Child_UsersetRewrite:
+			l.collectRewriteReferences(namespaceName, child.UsersetRewrite)
+		}
+	}
+}
+
+func (l *linter) lintRelation(namespaceName string, relation *core.Relation) []string {
+	var messages []string
+
+	if l.definitionNames.Has(relation.Name) {
+		messages = append(messages, fmt.Sprintf("`%s` has the same name as a definition, which can be confusing when used in arrows", relation.Name))
+	}
+
+	if relation.UsersetRewrite == nil {
+		if len(relation.GetTypeInformation().GetAllowedDirectRelations()) == 0 {
+			messages = append(messages, "relation has no allowed subject types, so no relationships can be written for it")
+		}
+
+		if !l.referenced.Has(tuple.JoinRelRef(namespaceName, relation.Name)) && !l.referencedByArrow.Has(relation.Name) {
+			messages = append(messages, "relation is not referenced by any permission or subject type")
+		}
+
+		return messages
+	}
+
+	if l.rewriteAlwaysEmpty(namespaceName, relation.UsersetRewrite, mapz.NewSet(relation.Name)) {
+		messages = append(messages, "permission always evaluates to the empty set")
+	} else if l.subjectTypes[tuple.JoinRelRef(namespaceName, relation.Name)].IsEmpty() {
+		if l.reaches(namespaceName, relation, tuple.JoinRelRef(namespaceName, relation.Name), mapz.NewSet[string]()) {
+			messages = append(messages, "permission recurses without a base case, so it can never be granted")
+		} else {
+			messages = append(messages, "permission can never be granted, as no subject type satisfies all of its intersections")
+		}
+	}
+
+	l.findNilExclusions(relation.UsersetRewrite, func() {
+		messages = append(messages, "excluding `nil` has no effect")
+	})
+
+	return messages
+}
+
+// rewriteAlwaysEmpty returns true if the rewrite can be statically determined to never
+// produce any subjects. Arrows are assumed to always be satisfiable.
+func (l *linter) rewriteAlwaysEmpty(namespaceName string, rewrite *core.UsersetRewrite, encountered *mapz.Set[string]) bool {
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		for _, child := range rw.Union.Child {
+			if !l.childAlwaysEmpty(namespaceName, child, encountered) {
+				return false
+			}
+		}
+		return true
+
+	case *core.UsersetRewrite_Intersection:
+		for _, child := range rw.Intersection.Child {
+			if l.childAlwaysEmpty(namespaceName, child, encountered) {
+				return true
+			}
+		}
+		return false
+
+	case *core.UsersetRewrite_Exclusion:
+		children := rw.Exclusion.Child
+		if len(children) == 0 {
+			return true
+		}
+
+		if l.childAlwaysEmpty(namespaceName, children[0], encountered) {
+			return true
+		}
+
+		for _, excluded := range children[1:] {
+			if sameChild(children[0], excluded) {
+				return true
+			}
+		}
+		return false
+
+	default:
+		return false
+	}
+}
+
+func (l *linter) childAlwaysEmpty(namespaceName string, child *core.SetOperation_Child, encountered *mapz.Set[string]) bool {
+	switch child := child.ChildType.(type) {
+	case *core.SetOperation_Child_XNil:
+		return true
+
+	case *core.SetOperation_Child_UsersetRewrite:
+		return l.rewriteAlwaysEmpty(namespaceName, child.UsersetRewrite, encountered)
+
+	case *core.SetOperation_Child_ComputedUserset:
+		relationName := child.ComputedUserset.Relation
+		relation, ok := l.relationsByNS[namespaceName][relationName]
+		if !ok || !encountered.Add(relationName) {
+			return false
+		}
+		defer encountered.Delete(relationName)
+
+		if relation.UsersetRewrite == nil {
+			return len(relation.GetTypeInformation().GetAllowedDirectRelations()) == 0
+		}
+
+		return l.rewriteAlwaysEmpty(namespaceName, relation.UsersetRewrite, encountered)
+
+	default:
+		return false
+	}
+}
+
+// computeSubjectTypes computes the subject types of every relation and permission. As the
+// types of a permission depend on those of the relations and permissions it references, the
+// computation is repeated until no set changes; permissions that only recurse into themselves
+// therefore end up without any subject types.
+func (l *linter) computeSubjectTypes() {
+	l.subjectTypes = make(map[string]*mapz.Set[string])
+	for namespaceName, relations := range l.relationsByNS {
+		for relationName := range relations {
+			l.subjectTypes[tuple.JoinRelRef(namespaceName, relationName)] = mapz.NewSet[string]()
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for namespaceName, relations := range l.relationsByNS {
+			for relationName, relation := range relations {
+				key := tuple.JoinRelRef(namespaceName, relationName)
+				found := l.relationSubjectTypes(namespaceName, relation)
+				if found.Len() != l.subjectTypes[key].Len() {
+					l.subjectTypes[key] = found
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+func (l *linter) relationSubjectTypes(namespaceName string, relation *core.Relation) *mapz.Set[string] {
+	if relation.UsersetRewrite != nil {
+		return l.rewriteSubjectTypes(namespaceName, relation, relation.UsersetRewrite)
+	}
+
+	found := mapz.NewSet[string]()
+	for _, allowed := range relation.GetTypeInformation().GetAllowedDirectRelations() {
+		if allowed.GetPublicWildcard() != nil || allowed.GetRelation() == tuple.Ellipsis {
+			found.Add(allowed.Namespace)
+			continue
+		}
+
+		subjectSet := tuple.JoinRelRef(allowed.Namespace, allowed.GetRelation())
+		found.Add(subjectSet)
+		if subjectTypes, ok := l.subjectTypes[subjectSet]; ok {
+			found.Merge(subjectTypes)
+		}
+	}
+	return found
+}
+
+func (l *linter) rewriteSubjectTypes(namespaceName string, relation *core.Relation, rewrite *core.UsersetRewrite) *mapz.Set[string] {
+	children := rewriteChildren(rewrite)
+	if len(children) == 0 {
+		return mapz.NewSet[string]()
+	}
+
+	switch rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Intersection:
+		found := l.childSubjectTypes(namespaceName, relation, children[0])
+		for _, child := range children[1:] {
+			found = found.Intersect(l.childSubjectTypes(namespaceName, relation, child))
+		}
+		return found
+
+	case *core.UsersetRewrite_Exclusion:
+		return l.childSubjectTypes(namespaceName, relation, children[0])
+
+	default:
+		found := mapz.NewSet[string]()
+		for _, child := range children {
+			found.Merge(l.childSubjectTypes(namespaceName, relation, child))
+		}
+		return found
+	}
+}
+
+func (l *linter) childSubjectTypes(namespaceName string, relation *core.Relation, child *core.SetOperation_Child) *mapz.Set[string] {
+	found := mapz.NewSet[string]()
+	switch child := child.ChildType.(type) {
+	case *core.SetOperation_Child_XThis:
+		direct := &core.Relation{Name: relation.Name, TypeInformation: relation.TypeInformation}
+		return l.relationSubjectTypes(namespaceName, direct)
+
+	case *core.SetOperation_Child_UsersetRewrite:
+		return l.rewriteSubjectTypes(namespaceName, relation, child.UsersetRewrite)
+
+	case *core.SetOperation_Child_ComputedUserset:
+		if subjectTypes, ok := l.subjectTypes[tuple.JoinRelRef(namespaceName, child.ComputedUserset.Relation)]; ok {
+			found.Merge(subjectTypes)
+		}
+
+	case *core.SetOperation_Child_TupleToUserset:
+		tupleset, ok := l.relationsByNS[namespaceName][child.TupleToUserset.Tupleset.Relation]
+		if !ok {
+			return found
+		}
+
+		for _, allowed := range tupleset.GetTypeInformation().GetAllowedDirectRelations() {
+			if subjectTypes, ok := l.subjectTypes[tuple.JoinRelRef(allowed.Namespace, child.TupleToUserset.ComputedUserset.Relation)]; ok {
+				found.Merge(subjectTypes)
+			}
+		}
+	}
+	return found
+}
+
+// reaches returns true if the relation or permission references the relation or permission
+// with the given `namespace#relation` key, either directly or through the relations and
+// permissions it references.
+func (l *linter) reaches(namespaceName string, relation *core.Relation, target string, visited *mapz.Set[string]) bool {
+	if !visited.Add(tuple.JoinRelRef(namespaceName, relation.Name)) || relation.UsersetRewrite == nil {
+		return false
+	}
+
+	var referencesTarget func(rewrite *core.UsersetRewrite) bool
+	referencesTarget = func(rewrite *core.UsersetRewrite) bool {
+		for _, child := range rewriteChildren(rewrite) {
+			var references []string
+			switch child := child.ChildType.(type) {
+			case *core.SetOperation_Child_UsersetRewrite:
+				if referencesTarget(child.UsersetRewrite) {
+					return true
+				}
+
+			case *core.SetOperation_Child_ComputedUserset:
+				references = append(references, tuple.JoinRelRef(namespaceName, child.ComputedUserset.Relation))
+
+			case *core.SetOperation_Child_TupleToUserset:
+				tupleset := l.relationsByNS[namespaceName][child.TupleToUserset.Tupleset.Relation]
+				for _, allowed := range tupleset.GetTypeInformation().GetAllowedDirectRelations() {
+					references = append(references, tuple.JoinRelRef(allowed.Namespace, child.TupleToUserset.ComputedUserset.Relation))
+				}
+			}
+
+			for _, reference := range references {
+				if reference == target {
+					return true
+				}
+
+				referencedNamespace, referencedRelation := tuple.MustSplitRelRef(reference)
+				if referenced, ok := l.relationsByNS[referencedNamespace][referencedRelation]; ok && l.reaches(referencedNamespace, referenced, target, visited) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return referencesTarget(relation.UsersetRewrite)
+}
+
+func (l *linter) findNilExclusions(rewrite *core.UsersetRewrite, found func()) {
+	if exclusion, ok := rewrite.RewriteOperation.(*core.UsersetRewrite_Exclusion); ok {
+		for _, excluded := range exclusion.Exclusion.Child[min(1, len(exclusion.Exclusion.Child)):] {
+			if _, ok := excluded.ChildType.(*core.SetOperation_Child_XNil); ok {
+				found()
+			}
+		}
+	}
+
+	for _, child := range rewriteChildren(rewrite) {
+		if nested, ok := child.ChildType.(*core.SetOperation_Child_UsersetRewrite); ok {
+			l.findNilExclusions(nested.UsersetRewrite, found)
+		}
+	}
+}
+
+func rewriteChildren(rewrite *core.UsersetRewrite) []*core.SetOperation_Child {
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		return rw.Union.Child
+	case *core.UsersetRewrite_Intersection:
+		return rw.Intersection.Child
+	case *core.UsersetRewrite_Exclusion:
+		return rw.Exclusion.Child
+	default:
+		return nil
+	}
+}
+
+// sameChild returns true if both children are structurally equivalent, ignoring source positions.
+func sameChild(first *core.SetOperation_Child, second *core.SetOperation_Child) bool {
+	switch firstChild := first.ChildType.(type) {
+	case *core.SetOperation_Child_XThis:
+		_, ok := second.ChildType.(*core.SetOperation_Child_XThis)
+		return ok
+
+	case *core.SetOperation_Child_XNil:
+		_, ok := second.ChildType.(*core.SetOperation_Child_XNil)
+		return ok
+
+	case *core.SetOperation_Child_ComputedUserset:
+		secondChild, ok := second.ChildType.(*core.SetOperation_Child_ComputedUserset)
+		return ok && firstChild.ComputedUserset.Relation == secondChild.ComputedUserset.Relation
+
+	case *core.SetOperation_Child_TupleToUserset:
+		secondChild, ok := second.ChildType.(*core.SetOperation_Child_TupleToUserset)
+		return ok &&
+			firstChild.TupleToUserset.Tupleset.Relation == secondChild.TupleToUserset.Tupleset.Relation &&
+			firstChild.TupleToUserset.ComputedUserset.Relation == secondChild.TupleToUserset.ComputedUserset.Relation
+
+	case *core.SetOperation_Child_UsersetRewrite:
+		secondChild, ok := second.ChildType.(*core.SetOperation_Child_UsersetRewrite)
+		if !ok || fmt.Sprintf("%T", firstChild.UsersetRewrite.RewriteOperation) != fmt.Sprintf("%T", secondChild.UsersetRewrite.RewriteOperation) {
+			return false
+		}
+
+		firstChildren := rewriteChildren(firstChild.UsersetRewrite)
+		secondChildren := rewriteChildren(secondChild.UsersetRewrite)
+		if len(firstChildren) != len(secondChildren) {
+			return false
+		}
+
+		for index := range firstChildren {
+			if !sameChild(firstChildren[index], secondChildren[index]) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,183 @@
+package development
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+func TestLintSchema(t *testing.T) {
+	tcs := []struct {
+		name             string
+		schema           string
+		expectedWarnings []string
+	}{
+		{
+			"no warnings",
+			`definition user {}
+
+			definition group {
+				relation member: user | group#member
+			}
+
+			definition document {
+				relation parent: document
+				relation viewer: user | group#member
+				relation banned: user
+				permission view = (viewer - banned) + parent->view
+			}`,
+			nil,
+		},
+		{
+			"unused relation",
+			`definition user {}
+
+			definition document {
+				relation viewer: user
+				relation editor: user
+				permission view = viewer
+			}`,
+			[]string{"5:5: document#editor: relation is not referenced by any permission or subject type"},
+		},
+		{
+			"relation used by an arrow on another definition",
+			`definition user {}
+
+			definition organization {
+				relation admin: user
+			}
+
+			definition document {
+				relation org: organization
+				permission manage = org->admin
+			}`,
+			nil,
+		},
+		{
+			"nil permission",
+			`definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer
+				permission deleted = nil
+			}`,
+			[]string{"6:5: document#deleted: permission always evaluates to the empty set"},
+		},
+		{
+			"intersection with nil permission",
+			`definition user {}
+
+			definition document {
+				relation viewer: user
+				permission disabled = nil
+				permission view = viewer & disabled
+			}`,
+			[]string{
+				"5:5: document#disabled: permission always evaluates to the empty set",
+				"6:5: document#view: permission always evaluates to the empty set",
+			},
+		},
+		{
+			"self exclusion",
+			`definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer - viewer
+			}`,
+			[]string{"5:5: document#view: permission always evaluates to the empty set"},
+		},
+		{
+			"nil exclusion",
+			`definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer - nil
+			}`,
+			[]string{"5:5: document#view: excluding `nil` has no effect"},
+		},
+		{
+			"intersection over disjoint subject types",
+			`definition user {}
+
+			definition team {}
+
+			definition document {
+				relation viewer: user
+				relation owner: team
+				permission view = viewer & owner
+			}`,
+			[]string{"8:5: document#view: permission can never be granted, as no subject type satisfies all of its intersections"},
+		},
+		{
+			"intersection over subject sets",
+			`definition user {}
+
+			definition group {
+				relation member: user
+			}
+
+			definition document {
+				relation viewer: user
+				relation reader: group#member
+				permission view = viewer & reader
+			}`,
+			nil,
+		},
+		{
+			"recursion without a base case",
+			`definition user {}
+
+			definition folder {
+				relation parent: folder
+				relation viewer: user
+				permission view = parent->view & viewer
+			}`,
+			[]string{"6:5: folder#view: permission recurses without a base case, so it can never be granted"},
+		},
+		{
+			"recursion with a base case",
+			`definition user {}
+
+			definition folder {
+				relation parent: folder
+				relation viewer: user
+				relation banned: user
+				permission view = (viewer + parent->view) - banned
+			}`,
+			nil,
+		},
+		{
+			"relation shadowing a definition",
+			`definition user {}
+
+			definition document {
+				relation user: user
+				permission view = user
+			}`,
+			[]string{"4:5: document#user: `user` has the same name as a definition, which can be confusing when used in arrows"},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			compiled, err := compiler.Compile(compiler.InputSchema{
+				Source:       input.Source("schema"),
+				SchemaString: tc.schema,
+			}, compiler.AllowUnprefixedObjectType())
+			require.NoError(t, err)
+
+			var warnings []string
+			for _, warning := range LintSchema(compiled) {
+				warnings = append(warnings, warning.String())
+			}
+			require.Equal(t, tc.expectedWarnings, warnings)
+		})
+	}
+}
@@ -8,35 +8,45 @@ import (
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
 )
 
-// CompileSchema compiles a schema into its caveat and namespace definition(s), returning a developer
-// error if the schema could not be compiled. The non-developer error is returned only if an
-// internal errors occurred.
-func CompileSchema(schema string) (*compiler.CompiledSchema, *devinterface.DeveloperError, error) {
+// CompileSchema compiles a schema into its caveat and namespace definition(s), returning developer
+// errors for each issue found if the schema could not be compiled. The non-developer error is returned
+// only if an internal errors occurred.
+func CompileSchema(schema string) (*compiler.CompiledSchema, []*devinterface.DeveloperError, error) {
 	compiled, err := compiler.Compile(compiler.InputSchema{
 		Source:       input.Source("schema"),
 		SchemaString: schema,
 	}, compiler.AllowUnprefixedObjectType())
+	if err == nil {
+		return compiled, nil, nil
+	}
+
+	compileErrs := []error{err}
+	var multipleErrors compiler.MultipleErrors
+	if errors.As(err, &multipleErrors) {
+		compileErrs = multipleErrors.Errors
+	}
+
+	devErrs := make([]*devinterface.DeveloperError, 0, len(compileErrs))
+	for _, compileErr := range compileErrs {
+		var contextError compiler.ErrorWithContext
+		if !errors.As(compileErr, &contextError) {
+			return nil, nil, compileErr
+		}
 
-	var contextError compiler.ErrorWithContext
-	if errors.As(err, &contextError) {
 		line, col, lerr := contextError.SourceRange.Start().LineAndColumn()
 		if lerr != nil {
 			return nil, nil, lerr
 		}
 
-		return nil, &devinterface.DeveloperError{
+		devErrs = append(devErrs, &devinterface.DeveloperError{
 			Message: contextError.BaseCompilerError.BaseMessage,
 			Kind:    devinterface.DeveloperError_SCHEMA_ISSUE,
 			Source:  devinterface.DeveloperError_SCHEMA,
 			Line:    uint32(line) + 1, // 0-indexed in parser.
 			Column:  uint32(col) + 1,  // 0-indexed in parser.
 			Context: contextError.ErrorSourceCode,
-		}, nil
-	}
-
-	if err != nil {
-		return nil, nil, err
+		})
 	}
 
-	return compiled, nil, nil
+	return nil, devErrs, nil
 }
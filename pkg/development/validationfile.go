@@ -0,0 +1,108 @@
+package development
+
+import (
+	"context"
+	"os"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/validationfile"
+)
+
+// ValidationFileResult is the result of evaluating a validation file.
+type ValidationFileResult struct {
+	// InputErrors are the errors in the file itself, such as an invalid schema or relationship.
+	// If any are present, the assertions and expected relations were not evaluated.
+	InputErrors []*devinterface.DeveloperError
+
+	// AssertionErrors are the assertions which do not hold.
+	AssertionErrors []*devinterface.DeveloperError
+
+	// ValidationErrors are the differences between the expected relations and those computed.
+	ValidationErrors []*devinterface.DeveloperError
+
+	// UpdatedValidationYaml is the expected relations block as computed from the data, in the
+	// same form as the `validation` block of the file.
+	UpdatedValidationYaml string
+}
+
+// Passed returns true if the file is valid and all of its assertions and expected relations hold.
+func (vfr *ValidationFileResult) Passed() bool {
+	return len(vfr.InputErrors) == 0 && len(vfr.AssertionErrors) == 0 && len(vfr.ValidationErrors) == 0
+}
+
+// Errors returns all the errors found in the validation file.
+func (vfr *ValidationFileResult) Errors() []*devinterface.DeveloperError {
+	errs := make([]*devinterface.DeveloperError, 0, len(vfr.InputErrors)+len(vfr.AssertionErrors)+len(vfr.ValidationErrors))
+	errs = append(errs, vfr.InputErrors...)
+	errs = append(errs, vfr.AssertionErrors...)
+	return append(errs, vfr.ValidationErrors...)
+}
+
+// RunValidationFile reads the validation file at the given path and evaluates it. See
+// RunValidationFileContents.
+func RunValidationFile(ctx context.Context, filePath string) (*ValidationFileResult, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return RunValidationFileContents(ctx, contents)
+}
+
+// RunValidationFileContents loads the schema and relationships of the validation file into an
+// in-memory datastore, and evaluates its assertions and expected relations against them.
+// Problems with the file are reported in the result; the returned error is only set if the
+// evaluation itself failed.
+func RunValidationFileContents(ctx context.Context, contents []byte) (*ValidationFileResult, error) {
+	parsed, err := validationfile.DecodeValidationFile(contents)
+	if err != nil {
+		devErr := convertError(devinterface.DeveloperError_VALIDATION_YAML, err)
+		if serr, ok := spiceerrors.AsErrorWithSource(err); ok {
+			devErr = convertSourceError(devinterface.DeveloperError_VALIDATION_YAML, serr)
+		}
+		return &ValidationFileResult{InputErrors: []*devinterface.DeveloperError{devErr}}, nil
+	}
+
+	tuples := make([]*core.RelationTuple, 0, len(parsed.Relationships.Relationships))
+	for _, rel := range parsed.Relationships.Relationships {
+		tuples = append(tuples, tuple.MustFromRelationship[*v1.ObjectReference, *v1.SubjectReference, *v1.ContextualizedCaveat](rel))
+	}
+
+	devContext, devErrs, err := NewDevContext(ctx, &devinterface.RequestContext{
+		Schema:        parsed.Schema.Schema,
+		Relationships: tuples,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if devErrs != nil {
+		return &ValidationFileResult{InputErrors: devErrs.InputErrors}, nil
+	}
+	defer devContext.Dispose()
+
+	assertionErrors, err := RunAllAssertions(devContext, &parsed.Assertions)
+	if err != nil {
+		return nil, err
+	}
+
+	membershipSet, validationErrors, err := RunValidation(devContext, &parsed.ExpectedRelations)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedValidationYaml, err := GenerateValidation(membershipSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ValidationFileResult{
+		AssertionErrors:       assertionErrors,
+		ValidationErrors:      validationErrors,
+		UpdatedValidationYaml: updatedValidationYaml,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package development
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+)
+
+const validationFileSchema = `schema: |-
+  definition user {}
+
+  definition document {
+    relation editor: user
+    relation viewer: user
+    permission view = viewer + editor
+  }
+relationships: |-
+  document:plan#viewer@user:alice
+  document:plan#editor@user:bob
+`
+
+func TestRunValidationFileContents(t *testing.T) {
+	tcs := []struct {
+		name          string
+		contents      string
+		expectedKinds []devinterface.DeveloperError_ErrorKind
+	}{
+		{
+			"passing",
+			validationFileSchema + `assertions:
+  assertTrue:
+    - document:plan#view@user:alice
+    - document:plan#view@user:bob
+  assertFalse:
+    - document:plan#view@user:carol
+validation:
+  document:plan#view:
+    - "[user:alice] is <document:plan#viewer>"
+    - "[user:bob] is <document:plan#editor>"
+`,
+			nil,
+		},
+		{
+			"failed assertion",
+			validationFileSchema + `assertions:
+  assertTrue:
+    - document:plan#view@user:carol
+`,
+			[]devinterface.DeveloperError_ErrorKind{devinterface.DeveloperError_ASSERTION_FAILED},
+		},
+		{
+			"missing expected relation",
+			validationFileSchema + `validation:
+  document:plan#view:
+    - "[user:alice] is <document:plan#viewer>"
+    - "[user:bob] is <document:plan#editor>"
+    - "[user:carol] is <document:plan#viewer>"
+`,
+			[]devinterface.DeveloperError_ErrorKind{devinterface.DeveloperError_MISSING_EXPECTED_RELATIONSHIP},
+		},
+		{
+			"invalid schema",
+			`schema: |-
+  definition document {
+    relation viewer: user
+  }
+`,
+			[]devinterface.DeveloperError_ErrorKind{devinterface.DeveloperError_SCHEMA_ISSUE},
+		},
+		{
+			"invalid yaml",
+			`schema: [`,
+			[]devinterface.DeveloperError_ErrorKind{devinterface.DeveloperError_PARSE_ERROR},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := RunValidationFileContents(context.Background(), []byte(tc.contents))
+			require.NoError(t, err)
+			require.Equal(t, len(tc.expectedKinds) == 0, result.Passed())
+
+			kinds := make([]devinterface.DeveloperError_ErrorKind, 0, len(result.Errors()))
+			for _, devErr := range result.Errors() {
+				kinds = append(kinds, devErr.Kind)
+			}
+			require.ElementsMatch(t, tc.expectedKinds, kinds)
+		})
+	}
+}
+
+func TestRunValidationFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "validation.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(validationFileSchema), 0o600))
+
+	result, err := RunValidationFile(context.Background(), path)
+	require.NoError(t, err)
+	require.True(t, result.Passed())
+
+	_, err = RunValidationFile(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
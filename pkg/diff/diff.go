@@ -0,0 +1,215 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	caveatdiff "github.com/authzed/spicedb/pkg/diff/caveats"
+	nsdiff "github.com/authzed/spicedb/pkg/diff/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/typesystem"
+)
+
+// DiffableSchema is a schema that can be diffed.
+type DiffableSchema struct {
+	// ObjectDefinitions holds the object definitions in the schema.
+	ObjectDefinitions []*core.NamespaceDefinition
+
+	// CaveatDefinitions holds the caveat definitions in the schema.
+	CaveatDefinitions []*core.CaveatDefinition
+}
+
+// NewDiffableSchemaFromCompiledSchema returns a DiffableSchema holding the definitions found in
+// the compiled schema.
+func NewDiffableSchemaFromCompiledSchema(compiled *compiler.CompiledSchema) DiffableSchema {
+	return DiffableSchema{
+		ObjectDefinitions: compiled.ObjectDefinitions,
+		CaveatDefinitions: compiled.CaveatDefinitions,
+	}
+}
+
+// SchemaDiff holds the diff between two schemas.
+type SchemaDiff struct {
+	// AddedNamespaces are the names of the namespaces that were added.
+	AddedNamespaces []string
+
+	// RemovedNamespaces are the names of the namespaces that were removed.
+	RemovedNamespaces []string
+
+	// AddedCaveats are the names of the caveats that were added.
+	AddedCaveats []string
+
+	// RemovedCaveats are the names of the caveats that were removed.
+	RemovedCaveats []string
+
+	// ChangedNamespaces are the diffs of the namespaces found in both schemas that have
+	// at least one change, keyed by namespace name.
+	ChangedNamespaces map[string]nsdiff.Diff
+
+	// ChangedCaveats are the diffs of the caveats found in both schemas that have at least
+	// one change, keyed by caveat name.
+	ChangedCaveats map[string]caveatdiff.Diff
+}
+
+// IsEmpty returns true if the schemas are equivalent.
+func (sd *SchemaDiff) IsEmpty() bool {
+	return len(sd.AddedNamespaces) == 0 &&
+		len(sd.RemovedNamespaces) == 0 &&
+		len(sd.AddedCaveats) == 0 &&
+		len(sd.RemovedCaveats) == 0 &&
+		len(sd.ChangedNamespaces) == 0 &&
+		len(sd.ChangedCaveats) == 0
+}
+
+// BreakingChange describes a single change between two schemas that can break existing
+// relationships or callers, such as the removal of a relation, permission or allowed subject
+// type.
+type BreakingChange struct {
+	// DefinitionName is the name of the object or caveat definition that was changed.
+	DefinitionName string
+
+	// Description is a human-readable description of the change.
+	Description string
+}
+
+func (bc BreakingChange) String() string {
+	return fmt.Sprintf("%s: %s", bc.DefinitionName, bc.Description)
+}
+
+// IsBreaking returns true if the diff contains at least one breaking change.
+func (sd *SchemaDiff) IsBreaking() bool {
+	return len(sd.BreakingChanges()) > 0
+}
+
+// BreakingChanges returns the breaking changes found in the diff, sorted by definition name.
+// All other changes (additions, comment changes and changes to the implementation of
+// permissions or caveat expressions) are considered non-breaking.
+func (sd *SchemaDiff) BreakingChanges() []BreakingChange {
+	changes := make([]BreakingChange, 0)
+
+	for _, name := range sd.RemovedNamespaces {
+		changes = append(changes, BreakingChange{name, "definition removed"})
+	}
+
+	for _, name := range sd.RemovedCaveats {
+		changes = append(changes, BreakingChange{name, "caveat removed"})
+	}
+
+	for name, diff := range sd.ChangedNamespaces {
+		for _, delta := range diff.Deltas() {
+			switch delta.Type {
+			case nsdiff.RemovedRelation:
+				changes = append(changes, BreakingChange{name, fmt.Sprintf("relation `%s` removed", delta.RelationName)})
+
+			case nsdiff.RemovedPermission:
+				changes = append(changes, BreakingChange{name, fmt.Sprintf("permission `%s` removed", delta.RelationName)})
+
+			case nsdiff.RelationAllowedTypeRemoved:
+				changes = append(changes, BreakingChange{name, fmt.Sprintf("allowed type `%s` removed from relation `%s`", typesystem.SourceForAllowedRelation(delta.AllowedType), delta.RelationName)})
+
+			case nsdiff.LegacyChangedRelationImpl:
+				changes = append(changes, BreakingChange{name, fmt.Sprintf("implementation of relation `%s` changed", delta.RelationName)})
+			}
+		}
+	}
+
+	for name, diff := range sd.ChangedCaveats {
+		for _, delta := range diff.Deltas() {
+			switch delta.Type {
+			case caveatdiff.RemovedParameter:
+				changes = append(changes, BreakingChange{name, fmt.Sprintf("parameter `%s` removed", delta.ParameterName)})
+
+			case caveatdiff.ParameterTypeChanged:
+				changes = append(changes, BreakingChange{name, fmt.Sprintf("type of parameter `%s` changed", delta.ParameterName)})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].DefinitionName < changes[j].DefinitionName
+	})
+	return changes
+}
+
+// DiffSchemas compares two schemas and returns the diff between them.
+func DiffSchemas(existing DiffableSchema, comparison DiffableSchema) (*SchemaDiff, error) {
+	existingNamespacesByName := make(map[string]*core.NamespaceDefinition, len(existing.ObjectDefinitions))
+	for _, nsDef := range existing.ObjectDefinitions {
+		existingNamespacesByName[nsDef.Name] = nsDef
+	}
+
+	existingCaveatsByName := make(map[string]*core.CaveatDefinition, len(existing.CaveatDefinitions))
+	for _, caveatDef := range existing.CaveatDefinitions {
+		existingCaveatsByName[caveatDef.Name] = caveatDef
+	}
+
+	sd := &SchemaDiff{
+		AddedNamespaces:   []string{},
+		RemovedNamespaces: []string{},
+		AddedCaveats:      []string{},
+		RemovedCaveats:    []string{},
+		ChangedNamespaces: map[string]nsdiff.Diff{},
+		ChangedCaveats:    map[string]caveatdiff.Diff{},
+	}
+
+	// Compare namespaces.
+	comparisonNamespaceNames := make(map[string]struct{}, len(comparison.ObjectDefinitions))
+	for _, updatedNamespaceDef := range comparison.ObjectDefinitions {
+		comparisonNamespaceNames[updatedNamespaceDef.Name] = struct{}{}
+
+		existingNamespaceDef, ok := existingNamespacesByName[updatedNamespaceDef.Name]
+		if !ok {
+			sd.AddedNamespaces = append(sd.AddedNamespaces, updatedNamespaceDef.Name)
+			continue
+		}
+
+		diff, err := nsdiff.DiffNamespaces(existingNamespaceDef, updatedNamespaceDef)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(diff.Deltas()) > 0 {
+			sd.ChangedNamespaces[updatedNamespaceDef.Name] = *diff
+		}
+	}
+
+	for _, existingNamespaceDef := range existing.ObjectDefinitions {
+		if _, ok := comparisonNamespaceNames[existingNamespaceDef.Name]; !ok {
+			sd.RemovedNamespaces = append(sd.RemovedNamespaces, existingNamespaceDef.Name)
+		}
+	}
+
+	// Compare caveats.
+	comparisonCaveatNames := make(map[string]struct{}, len(comparison.CaveatDefinitions))
+	for _, updatedCaveatDef := range comparison.CaveatDefinitions {
+		comparisonCaveatNames[updatedCaveatDef.Name] = struct{}{}
+
+		existingCaveatDef, ok := existingCaveatsByName[updatedCaveatDef.Name]
+		if !ok {
+			sd.AddedCaveats = append(sd.AddedCaveats, updatedCaveatDef.Name)
+			continue
+		}
+
+		diff, err := caveatdiff.DiffCaveats(existingCaveatDef, updatedCaveatDef)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(diff.Deltas()) > 0 {
+			sd.ChangedCaveats[updatedCaveatDef.Name] = *diff
+		}
+	}
+
+	for _, existingCaveatDef := range existing.CaveatDefinitions {
+		if _, ok := comparisonCaveatNames[existingCaveatDef.Name]; !ok {
+			sd.RemovedCaveats = append(sd.RemovedCaveats, existingCaveatDef.Name)
+		}
+	}
+
+	sort.Strings(sd.AddedNamespaces)
+	sort.Strings(sd.RemovedNamespaces)
+	sort.Strings(sd.AddedCaveats)
+	sort.Strings(sd.RemovedCaveats)
+	return sd, nil
+}
@@ -0,0 +1,192 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	testCases := []struct {
+		name                      string
+		existingSchema            string
+		comparisonSchema          string
+		expectedAddedNamespaces   []string
+		expectedRemovedNamespaces []string
+		expectedAddedCaveats      []string
+		expectedRemovedCaveats    []string
+		expectedChangedNamespaces []string
+		expectedChangedCaveats    []string
+		expectedBreakingChanges   []string
+	}{
+		{
+			name:             "no changes",
+			existingSchema:   `definition user {}`,
+			comparisonSchema: `definition user {}`,
+		},
+		{
+			name:                    "added namespace",
+			existingSchema:          `definition user {}`,
+			comparisonSchema:        `definition user {} definition document {}`,
+			expectedAddedNamespaces: []string{"document"},
+		},
+		{
+			name:                      "removed namespace",
+			existingSchema:            `definition user {} definition document {}`,
+			comparisonSchema:          `definition user {}`,
+			expectedRemovedNamespaces: []string{"document"},
+			expectedBreakingChanges:   []string{"document: definition removed"},
+		},
+		{
+			name:           "added relation",
+			existingSchema: `definition user {} definition document {}`,
+			comparisonSchema: `definition user {}
+
+			definition document {
+				relation viewer: user
+			}`,
+			expectedChangedNamespaces: []string{"document"},
+		},
+		{
+			name: "removed relation and permission",
+			existingSchema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				permission view = viewer
+			}`,
+			comparisonSchema:          `definition user {} definition document {}`,
+			expectedChangedNamespaces: []string{"document"},
+			expectedBreakingChanges: []string{
+				"document: relation `viewer` removed",
+				"document: permission `view` removed",
+			},
+		},
+		{
+			name: "changed permission implementation",
+			existingSchema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation editor: user
+				permission view = viewer
+			}`,
+			comparisonSchema: `definition user {}
+
+			definition document {
+				relation viewer: user
+				relation editor: user
+				permission view = viewer + editor
+			}`,
+			expectedChangedNamespaces: []string{"document"},
+		},
+		{
+			name: "removed allowed type",
+			existingSchema: `definition user {}
+
+			definition team {
+				relation member: user
+			}
+
+			definition document {
+				relation viewer: user | team#member
+			}`,
+			comparisonSchema: `definition user {}
+
+			definition team {
+				relation member: user
+			}
+
+			definition document {
+				relation viewer: user
+			}`,
+			expectedChangedNamespaces: []string{"document"},
+			expectedBreakingChanges:   []string{"document: allowed type `team#member` removed from relation `viewer`"},
+		},
+		{
+			name:                 "added caveat",
+			existingSchema:       `definition user {}`,
+			comparisonSchema:     `caveat somecaveat(someparam int) { someparam == 42 } definition user {}`,
+			expectedAddedCaveats: []string{"somecaveat"},
+		},
+		{
+			name:                    "removed caveat",
+			existingSchema:          `caveat somecaveat(someparam int) { someparam == 42 } definition user {}`,
+			comparisonSchema:        `definition user {}`,
+			expectedRemovedCaveats:  []string{"somecaveat"},
+			expectedBreakingChanges: []string{"somecaveat: caveat removed"},
+		},
+		{
+			name:                    "changed caveat parameter type",
+			existingSchema:          `caveat somecaveat(someparam int) { someparam == 42 } definition user {}`,
+			comparisonSchema:        `caveat somecaveat(someparam uint) { someparam == 42 } definition user {}`,
+			expectedChangedCaveats:  []string{"somecaveat"},
+			expectedBreakingChanges: []string{"somecaveat: type of parameter `someparam` changed"},
+		},
+		{
+			name:                   "changed caveat expression",
+			existingSchema:         `caveat somecaveat(someparam int) { someparam == 42 } definition user {}`,
+			comparisonSchema:       `caveat somecaveat(someparam int) { someparam == 43 } definition user {}`,
+			expectedChangedCaveats: []string{"somecaveat"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			existing := compileSchema(t, tc.existingSchema)
+			comparison := compileSchema(t, tc.comparisonSchema)
+
+			diff, err := DiffSchemas(existing, comparison)
+			require.NoError(t, err)
+
+			require.Equal(t, orEmpty(tc.expectedAddedNamespaces), diff.AddedNamespaces)
+			require.Equal(t, orEmpty(tc.expectedRemovedNamespaces), diff.RemovedNamespaces)
+			require.Equal(t, orEmpty(tc.expectedAddedCaveats), diff.AddedCaveats)
+			require.Equal(t, orEmpty(tc.expectedRemovedCaveats), diff.RemovedCaveats)
+
+			changedNamespaces := []string{}
+			for name := range diff.ChangedNamespaces {
+				changedNamespaces = append(changedNamespaces, name)
+			}
+			require.ElementsMatch(t, orEmpty(tc.expectedChangedNamespaces), changedNamespaces)
+
+			changedCaveats := []string{}
+			for name := range diff.ChangedCaveats {
+				changedCaveats = append(changedCaveats, name)
+			}
+			require.ElementsMatch(t, orEmpty(tc.expectedChangedCaveats), changedCaveats)
+
+			breakingChanges := []string{}
+			for _, change := range diff.BreakingChanges() {
+				breakingChanges = append(breakingChanges, change.String())
+			}
+			require.ElementsMatch(t, orEmpty(tc.expectedBreakingChanges), breakingChanges)
+			require.Equal(t, len(tc.expectedBreakingChanges) > 0, diff.IsBreaking())
+
+			isEmpty := len(tc.expectedAddedNamespaces) == 0 && len(tc.expectedRemovedNamespaces) == 0 &&
+				len(tc.expectedAddedCaveats) == 0 && len(tc.expectedRemovedCaveats) == 0 &&
+				len(tc.expectedChangedNamespaces) == 0 && len(tc.expectedChangedCaveats) == 0
+			require.Equal(t, isEmpty, diff.IsEmpty())
+		})
+	}
+}
+
+func compileSchema(t *testing.T, schema string) DiffableSchema {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+	return NewDiffableSchemaFromCompiledSchema(compiled)
+}
+
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
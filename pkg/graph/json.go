@@ -0,0 +1,21 @@
+package graph
+
+import (
+	"github.com/authzed/spicedb/pkg/canonicaljson"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// MarshalTreeJSON returns the canonical JSON encoding of an expand tree, as defined by the
+// canonicaljson package.
+func MarshalTreeJSON(tree *core.RelationTupleTreeNode) ([]byte, error) {
+	return canonicaljson.Marshal(tree)
+}
+
+// UnmarshalTreeJSON decodes an expand tree from its canonical JSON encoding and validates it.
+func UnmarshalTreeJSON(data []byte) (*core.RelationTupleTreeNode, error) {
+	tree := &core.RelationTupleTreeNode{}
+	if err := canonicaljson.Unmarshal(data, tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
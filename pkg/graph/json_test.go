@@ -0,0 +1,35 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/testutil"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestTreeJSONRoundTrip(t *testing.T) {
+	require := require.New(t)
+
+	tree := Union(
+		tuple.ParseONR("document:foo#viewer"),
+		Leaf(tuple.ParseONR("document:foo#reader"), &core.DirectSubject{Subject: tuple.ParseSubjectONR("user:tom")}),
+		Leaf(tuple.ParseONR("document:foo#owner"), &core.DirectSubject{Subject: tuple.ParseSubjectONR("group:eng#member")}),
+	)
+
+	encoded, err := MarshalTreeJSON(tree)
+	require.NoError(err)
+
+	reencoded, err := MarshalTreeJSON(tree)
+	require.NoError(err)
+	require.Equal(string(encoded), string(reencoded))
+
+	decoded, err := UnmarshalTreeJSON(encoded)
+	require.NoError(err)
+	testutil.RequireProtoEqual(t, tree, decoded, "JSON round trip mismatch")
+
+	_, err = UnmarshalTreeJSON([]byte(`{"leafNode":`))
+	require.Error(err)
+}
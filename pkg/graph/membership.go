@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/authzed/spicedb/internal/developmentmembership"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Member is a subject found within an expansion tree.
+type Member struct {
+	// Subject is the subject found. Wildcard subjects (`user:*`) have an object ID of `*`.
+	Subject *core.ObjectAndRelation
+
+	// ExcludedSubjects are the subjects excluded from a wildcard subject, sorted by their string
+	// form. Subjects which are only excluded under a caveat are included.
+	ExcludedSubjects []*core.ObjectAndRelation
+
+	// IsConditional is true if the subject is only a member when a caveat is satisfied.
+	IsConditional bool
+}
+
+// MemberSet is the set of subjects found within an expansion tree.
+type MemberSet struct {
+	subjects *developmentmembership.TrackingSubjectSet
+}
+
+// MembersOf reduces an expansion tree into the set of subjects found within it, applying the
+// union, intersection and exclusion operations of each intermediate node, with the same semantics
+// as the developer API:
+//   - Union: the wildcard is included alongside any concrete subjects.
+//   - Intersection: a concrete subject is kept if the other branch contains it or a wildcard of
+//     the same type; a wildcard is only kept if every branch contains it.
+//   - Exclusion: a concrete subject is removed if the excluded branch contains it or a wildcard
+//     of the same type; concrete subjects excluded from a wildcard are recorded as exceptions of
+//     the wildcard.
+//
+// NOTE: The expansion tree *should* be the fully recursive expansion; non-terminal subjects found
+// in the tree are returned as-is.
+func MembersOf(treeNode *core.RelationTupleTreeNode) (*MemberSet, error) {
+	if treeNode == nil {
+		return nil, fmt.Errorf("missing expansion tree")
+	}
+
+	subjects, err := developmentmembership.AccessibleExpansionSubjects(treeNode)
+	if err != nil {
+		return nil, err
+	}
+	return &MemberSet{subjects}, nil
+}
+
+// Members returns the members of the set, sorted by the string form of their subject.
+func (ms *MemberSet) Members() []Member {
+	found := ms.subjects.ToSlice()
+	members := make([]Member, 0, len(found))
+	for _, fs := range found {
+		members = append(members, newMember(fs))
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return tuple.StringONR(members[i].Subject) < tuple.StringONR(members[j].Subject)
+	})
+	return members
+}
+
+// Get returns the member with exactly the given subject, if any. A concrete subject which is only
+// a member via a wildcard is not returned.
+func (ms *MemberSet) Get(subject *core.ObjectAndRelation) (Member, bool) {
+	fs, ok := ms.subjects.Get(subject)
+	if !ok {
+		return Member{}, false
+	}
+	return newMember(fs), true
+}
+
+// IsMember returns true if the subject is a member of the set, either directly or via a wildcard of
+// its type from which it is not excluded. Conditional members are considered members.
+func (ms *MemberSet) IsMember(subject *core.ObjectAndRelation) bool {
+	if ms.subjects.Contains(subject) {
+		return true
+	}
+
+	if subject.ObjectId == tuple.PublicWildcard {
+		return false
+	}
+
+	wildcard, ok := ms.subjects.Get(tuple.ObjectAndRelation(subject.Namespace, tuple.PublicWildcard, subject.Relation))
+	if !ok {
+		return false
+	}
+
+	for _, excluded := range wildcard.GetExcludedSubjects() {
+		if excluded.GetSubjectId() == subject.ObjectId {
+			return false
+		}
+	}
+	return true
+}
+
+func newMember(fs developmentmembership.FoundSubject) Member {
+	member := Member{
+		Subject:       fs.Subject(),
+		IsConditional: fs.GetCaveatExpression() != nil,
+	}
+	for _, excluded := range fs.GetExcludedSubjects() {
+		member.ExcludedSubjects = append(member.ExcludedSubjects, excluded.Subject())
+	}
+	sortONRs(member.ExcludedSubjects)
+	return member
+}
+
+func (ms *MemberSet) addFrom(other *MemberSet) error {
+	return ms.subjects.AddFrom(other.subjects)
+}
+
+func sortONRs(onrs []*core.ObjectAndRelation) {
+	sort.Slice(onrs, func(i, j int) bool {
+		return tuple.StringONR(onrs[i]) < tuple.StringONR(onrs[j])
+	})
+}
@@ -0,0 +1,172 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func ds(subject string) *core.DirectSubject {
+	return &core.DirectSubject{
+		Subject: tuple.ParseSubjectONR(subject),
+	}
+}
+
+func TestMembersOf(t *testing.T) {
+	start := tuple.ParseONR("document:first#view")
+
+	testCases := []struct {
+		name     string
+		tree     *core.RelationTupleTreeNode
+		expected []string
+	}{
+		{
+			"empty leaf",
+			Leaf(start),
+			[]string{},
+		},
+		{
+			"leaf",
+			Leaf(start, ds("user:tom"), ds("user:sarah"), ds("group:eng#member")),
+			[]string{"group:eng#member", "user:sarah", "user:tom"},
+		},
+		{
+			"union",
+			Union(start,
+				Leaf(start, ds("user:tom")),
+				Leaf(start, ds("user:sarah"), ds("user:tom")),
+			),
+			[]string{"user:sarah", "user:tom"},
+		},
+		{
+			"union with wildcard",
+			Union(start,
+				Leaf(start, ds("user:tom")),
+				Leaf(start, ds("user:*")),
+			),
+			[]string{"user:*", "user:tom"},
+		},
+		{
+			"intersection",
+			Intersection(start,
+				Leaf(start, ds("user:tom"), ds("user:sarah")),
+				Leaf(start, ds("user:sarah"), ds("user:fred")),
+			),
+			[]string{"user:sarah"},
+		},
+		{
+			"intersection with wildcard",
+			Intersection(start,
+				Leaf(start, ds("user:*")),
+				Leaf(start, ds("user:sarah"), ds("team:fred")),
+			),
+			[]string{"user:sarah"},
+		},
+		{
+			"intersection of wildcards",
+			Intersection(start,
+				Leaf(start, ds("user:*"), ds("user:tom")),
+				Leaf(start, ds("user:*")),
+			),
+			[]string{"user:*", "user:tom"},
+		},
+		{
+			"exclusion",
+			Exclusion(start,
+				Leaf(start, ds("user:tom"), ds("user:sarah")),
+				Leaf(start, ds("user:sarah")),
+			),
+			[]string{"user:tom"},
+		},
+		{
+			"exclusion of wildcard",
+			Exclusion(start,
+				Leaf(start, ds("user:tom"), ds("team:sarah")),
+				Leaf(start, ds("user:*")),
+			),
+			[]string{"team:sarah"},
+		},
+		{
+			"exclusion from wildcard",
+			Exclusion(start,
+				Leaf(start, ds("user:*")),
+				Leaf(start, ds("user:tom"), ds("user:sarah")),
+			),
+			[]string{"user:* - {user:sarah, user:tom}"},
+		},
+		{
+			"nested",
+			Exclusion(start,
+				Union(start,
+					Leaf(start, ds("user:tom")),
+					Intersection(start,
+						Leaf(start, ds("user:sarah"), ds("user:fred")),
+						Leaf(start, ds("user:*")),
+					),
+				),
+				Leaf(start, ds("user:fred")),
+			),
+			[]string{"user:sarah", "user:tom"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			members, err := MembersOf(tc.tree)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, memberStrings(members))
+		})
+	}
+}
+
+func TestIsMember(t *testing.T) {
+	start := tuple.ParseONR("document:first#view")
+
+	members, err := MembersOf(Union(start,
+		Leaf(start, ds("team:eng")),
+		Exclusion(start,
+			Leaf(start, ds("user:*")),
+			Leaf(start, ds("user:tom")),
+		),
+	))
+	require.NoError(t, err)
+
+	require.True(t, members.IsMember(tuple.ParseSubjectONR("team:eng")))
+	require.False(t, members.IsMember(tuple.ParseSubjectONR("team:sales")))
+	require.True(t, members.IsMember(tuple.ParseSubjectONR("user:sarah")))
+	require.True(t, members.IsMember(tuple.ParseSubjectONR("user:*")))
+	require.False(t, members.IsMember(tuple.ParseSubjectONR("user:tom")))
+
+	_, ok := members.Get(tuple.ParseSubjectONR("user:sarah"))
+	require.False(t, ok)
+}
+
+func TestMembersOfInvalidTree(t *testing.T) {
+	start := tuple.ParseONR("document:first#view")
+
+	_, err := MembersOf(nil)
+	require.Error(t, err)
+
+	_, err = MembersOf(Intersection(start))
+	require.Error(t, err)
+
+	_, err = MembersOf(Exclusion(start))
+	require.Error(t, err)
+}
+
+func memberStrings(members *MemberSet) []string {
+	strs := []string{}
+	for _, member := range members.Members() {
+		str := tuple.StringONR(member.Subject)
+		if len(member.ExcludedSubjects) > 0 {
+			str += " - {" + strings.Join(tuple.StringsONRs(member.ExcludedSubjects), ", ") + "}"
+		}
+		strs = append(strs, str)
+	}
+	return strs
+}
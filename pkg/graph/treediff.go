@@ -0,0 +1,164 @@
+package graph
+
+import (
+	"github.com/authzed/spicedb/internal/developmentmembership"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// MembershipChange holds the members gained and lost between two expansions of the same
+// object and relation.
+type MembershipChange struct {
+	// Gained are the members found in the new expansion but not the old.
+	Gained []*core.ObjectAndRelation
+
+	// Lost are the members found in the old expansion but not the new.
+	Lost []*core.ObjectAndRelation
+}
+
+// IsEmpty returns true if no members were gained or lost.
+func (mc MembershipChange) IsEmpty() bool {
+	return len(mc.Gained) == 0 && len(mc.Lost) == 0
+}
+
+// TreeDiff is the semantic difference between two expansion trees.
+type TreeDiff struct {
+	// Root is the change in membership of the root of the trees.
+	Root MembershipChange
+
+	// Branches is the change in membership of each branch of the trees, keyed by the string form
+	// of the object and relation expanded by the branch. Branches whose membership did not change
+	// are not included.
+	Branches map[string]MembershipChange
+}
+
+// IsEmpty returns true if the trees have the same membership in every branch.
+func (td TreeDiff) IsEmpty() bool {
+	return td.Root.IsEmpty() && len(td.Branches) == 0
+}
+
+// DiffTrees computes the members gained and lost between the existing and updated expansion
+// trees, at the root as well as for every branch that expands an object and relation. Branches
+// are matched between the trees by the object and relation they expand, so a branch found in
+// only one tree reports all of its members as gained or lost.
+//
+// Membership is computed via MembersOf, so structural differences that do not change the set of
+// members (such as reordered children) are not reported. A subject newly excluded from a wildcard
+// found in both trees is reported as lost, and a subject no longer excluded from it as gained.
+func DiffTrees(existing, updated *core.RelationTupleTreeNode) (TreeDiff, error) {
+	existingRoot, err := MembersOf(existing)
+	if err != nil {
+		return TreeDiff{}, err
+	}
+
+	updatedRoot, err := MembersOf(updated)
+	if err != nil {
+		return TreeDiff{}, err
+	}
+
+	existingBranches := map[string]*MemberSet{}
+	if err := collectBranchMembers(existing, existingBranches); err != nil {
+		return TreeDiff{}, err
+	}
+
+	updatedBranches := map[string]*MemberSet{}
+	if err := collectBranchMembers(updated, updatedBranches); err != nil {
+		return TreeDiff{}, err
+	}
+
+	branches := map[string]MembershipChange{}
+	for onrString, existingMembers := range existingBranches {
+		updatedMembers, ok := updatedBranches[onrString]
+		if !ok {
+			updatedMembers = emptyMemberSet()
+		}
+
+		change := membershipChange(existingMembers, updatedMembers)
+		if !change.IsEmpty() {
+			branches[onrString] = change
+		}
+	}
+
+	for onrString, updatedMembers := range updatedBranches {
+		if _, ok := existingBranches[onrString]; ok {
+			continue
+		}
+
+		change := membershipChange(emptyMemberSet(), updatedMembers)
+		if !change.IsEmpty() {
+			branches[onrString] = change
+		}
+	}
+
+	return TreeDiff{
+		Root:     membershipChange(existingRoot, updatedRoot),
+		Branches: branches,
+	}, nil
+}
+
+func collectBranchMembers(treeNode *core.RelationTupleTreeNode, branches map[string]*MemberSet) error {
+	if treeNode.Expanded != nil {
+		members, err := MembersOf(treeNode)
+		if err != nil {
+			return err
+		}
+
+		onrString := tuple.StringONR(treeNode.Expanded)
+		if found, ok := branches[onrString]; ok {
+			if err := found.addFrom(members); err != nil {
+				return err
+			}
+		} else {
+			branches[onrString] = members
+		}
+	}
+
+	if intermediate, ok := treeNode.NodeType.(*core.RelationTupleTreeNode_IntermediateNode); ok {
+		for _, child := range intermediate.IntermediateNode.ChildNodes {
+			if err := collectBranchMembers(child, branches); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func emptyMemberSet() *MemberSet {
+	return &MemberSet{developmentmembership.NewTrackingSubjectSet()}
+}
+
+func membershipChange(existing, updated *MemberSet) MembershipChange {
+	gained := tuple.NewONRSet()
+	lost := tuple.NewONRSet()
+
+	for _, member := range updated.Members() {
+		existingMember, ok := existing.Get(member.Subject)
+		if !ok {
+			gained.Add(member.Subject)
+			continue
+		}
+
+		existingExcluded := tuple.NewONRSet(existingMember.ExcludedSubjects...)
+		updatedExcluded := tuple.NewONRSet(member.ExcludedSubjects...)
+		gained.UpdateFrom(existingExcluded.Subtract(updatedExcluded))
+		lost.UpdateFrom(updatedExcluded.Subtract(existingExcluded))
+	}
+
+	for _, member := range existing.Members() {
+		if _, ok := updated.Get(member.Subject); !ok {
+			lost.Add(member.Subject)
+		}
+	}
+
+	return MembershipChange{
+		Gained: sortedONRs(gained),
+		Lost:   sortedONRs(lost),
+	}
+}
+
+func sortedONRs(set *tuple.ONRSet) []*core.ObjectAndRelation {
+	onrs := set.AsSlice()
+	sortONRs(onrs)
+	return onrs
+}
@@ -0,0 +1,107 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestDiffTrees(t *testing.T) {
+	view := tuple.ParseONR("document:first#view")
+	viewer := tuple.ParseONR("document:first#viewer")
+	editor := tuple.ParseONR("document:first#editor")
+
+	testCases := []struct {
+		name             string
+		existing         *core.RelationTupleTreeNode
+		updated          *core.RelationTupleTreeNode
+		expectedGained   []string
+		expectedLost     []string
+		expectedBranches map[string][2][]string
+	}{
+		{
+			"no changes",
+			Union(view, Leaf(viewer, ds("user:tom")), Leaf(editor, ds("user:sarah"))),
+			Union(view, Leaf(viewer, ds("user:tom")), Leaf(editor, ds("user:sarah"))),
+			[]string{},
+			[]string{},
+			map[string][2][]string{},
+		},
+		{
+			"reordered children",
+			Union(view, Leaf(viewer, ds("user:tom")), Leaf(editor, ds("user:sarah"))),
+			Union(view, Leaf(editor, ds("user:sarah")), Leaf(viewer, ds("user:tom"))),
+			[]string{},
+			[]string{},
+			map[string][2][]string{},
+		},
+		{
+			"member gained in branch",
+			Union(view, Leaf(viewer, ds("user:tom")), Leaf(editor, ds("user:sarah"))),
+			Union(view, Leaf(viewer, ds("user:tom"), ds("user:fred")), Leaf(editor, ds("user:sarah"))),
+			[]string{"user:fred"},
+			[]string{},
+			map[string][2][]string{
+				"document:first#view":   {{"user:fred"}, {}},
+				"document:first#viewer": {{"user:fred"}, {}},
+			},
+		},
+		{
+			"member moved between branches",
+			Union(view, Leaf(viewer, ds("user:tom")), Leaf(editor, ds("user:sarah"))),
+			Union(view, Leaf(viewer, ds("user:tom"), ds("user:sarah")), Leaf(editor)),
+			[]string{},
+			[]string{},
+			map[string][2][]string{
+				"document:first#viewer": {{"user:sarah"}, {}},
+				"document:first#editor": {{}, {"user:sarah"}},
+			},
+		},
+		{
+			"branch removed",
+			Exclusion(view, Leaf(viewer, ds("user:tom"), ds("user:sarah")), Leaf(editor, ds("user:sarah"))),
+			Union(view, Leaf(viewer, ds("user:tom"), ds("user:sarah"))),
+			[]string{"user:sarah"},
+			[]string{},
+			map[string][2][]string{
+				"document:first#view":   {{"user:sarah"}, {}},
+				"document:first#editor": {{}, {"user:sarah"}},
+			},
+		},
+		{
+			"subject excluded from wildcard",
+			Exclusion(view, Leaf(viewer, ds("user:*")), Leaf(editor, ds("user:tom"))),
+			Exclusion(view, Leaf(viewer, ds("user:*")), Leaf(editor, ds("user:sarah"))),
+			[]string{"user:tom"},
+			[]string{"user:sarah"},
+			map[string][2][]string{
+				"document:first#view":   {{"user:tom"}, {"user:sarah"}},
+				"document:first#editor": {{"user:sarah"}, {"user:tom"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := DiffTrees(tc.existing, tc.updated)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expectedGained, tuple.StringsONRs(diff.Root.Gained))
+			require.Equal(t, tc.expectedLost, tuple.StringsONRs(diff.Root.Lost))
+
+			require.Equal(t, len(tc.expectedBranches), len(diff.Branches))
+			for onrString, expected := range tc.expectedBranches {
+				change, ok := diff.Branches[onrString]
+				require.True(t, ok, "missing branch %s", onrString)
+				require.Equal(t, expected[0], tuple.StringsONRs(change.Gained))
+				require.Equal(t, expected[1], tuple.StringsONRs(change.Lost))
+			}
+
+			require.Equal(t, len(tc.expectedBranches) == 0, diff.IsEmpty())
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package namespace
+
+import (
+	"fmt"
+	"regexp"
+
+	"google.golang.org/protobuf/encoding/prototext"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	iv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
+)
+
+// zanzibarDirectRelationSuffix is appended to the name of a relation whose rewrite includes
+// `_this`, to name the relation holding its direct relationships.
+const zanzibarDirectRelationSuffix = "_direct"
+
+// zanzibarObjectRef matches the `$TUPLE_USERSET_OBJECT` and `$TUPLE_OBJECT` references of the
+// Zanzibar paper, which are written without the `$` in the text format.
+var zanzibarObjectRef = regexp.MustCompile(`\$(TUPLE_USERSET_OBJECT|TUPLE_OBJECT)\b`)
+
+// ParseZanzibarConfig parses a namespace config in the textual style of the Zanzibar paper, e.g.
+//
+//	name: "doc"
+//	relation { name: "owner" }
+//	relation {
+//	  name: "viewer"
+//	  userset_rewrite {
+//	    union {
+//	      child { _this {} }
+//	      child { computed_userset { relation: "owner" } }
+//	    }
+//	  }
+//	}
+//
+// into a namespace definition.
+//
+// Relations without a rewrite, or whose rewrite is only `_this`, become relations. Other
+// relations become permissions; as permissions cannot hold relationships, a permission whose
+// rewrite includes `_this` is given a relation named with a `_direct` suffix, which stands in
+// for `_this`.
+//
+// Zanzibar configs do not declare the types of subjects, so the relations have no type
+// information; it must be added, e.g. with AllowedRelation, before relationships can be written.
+func ParseZanzibarConfig(config string) (*core.NamespaceDefinition, error) {
+	nsDef := &core.NamespaceDefinition{}
+	if err := prototext.Unmarshal([]byte(zanzibarObjectRef.ReplaceAllString(config, "$1")), nsDef); err != nil {
+		return nil, fmt.Errorf("invalid namespace config: %w", err)
+	}
+
+	existing := make(map[string]struct{}, len(nsDef.Relation))
+	for _, relation := range nsDef.Relation {
+		existing[relation.Name] = struct{}{}
+	}
+
+	relations := make([]*core.Relation, 0, len(nsDef.Relation))
+	for _, relation := range nsDef.Relation {
+		if relation.UsersetRewrite == nil || isOnlyThis(relation.UsersetRewrite) {
+			relation.UsersetRewrite = nil
+			if err := SetRelationKind(relation, iv1.RelationMetadata_RELATION); err != nil {
+				return nil, err
+			}
+			relations = append(relations, relation)
+			continue
+		}
+
+		direct := relation.Name + zanzibarDirectRelationSuffix
+		if replaceThis(relation.UsersetRewrite, direct) {
+			if _, ok := existing[direct]; ok {
+				return nil, fmt.Errorf("relation `%s` uses _this, but `%s` is already defined", relation.Name, direct)
+			}
+
+			directRelation := &core.Relation{Name: direct}
+			if err := SetRelationKind(directRelation, iv1.RelationMetadata_RELATION); err != nil {
+				return nil, err
+			}
+			relations = append(relations, directRelation)
+		}
+
+		if err := SetRelationKind(relation, iv1.RelationMetadata_PERMISSION); err != nil {
+			return nil, err
+		}
+		relations = append(relations, relation)
+	}
+	nsDef.Relation = relations
+
+	if err := nsDef.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid namespace config: %w", err)
+	}
+	return nsDef, nil
+}
+
+// isOnlyThis returns whether the rewrite is a set operation whose single child is `_this`.
+func isOnlyThis(rewrite *core.UsersetRewrite) bool {
+	operation := setOperationOf(rewrite)
+	if operation == nil || len(operation.Child) != 1 {
+		return false
+	}
+	_, ok := operation.Child[0].ChildType.(*core.SetOperation_Child_XThis)
+	return ok
+}
+
+// replaceThis replaces each `_this` in the rewrite by the given relation, returning whether any
+// was found. The computed usersets of tuple-to-usersets are always resolved on the objects of the
+// tupleset, as in the Zanzibar paper.
+func replaceThis(rewrite *core.UsersetRewrite, relation string) bool {
+	operation := setOperationOf(rewrite)
+	if operation == nil {
+		return false
+	}
+
+	found := false
+	for index, child := range operation.Child {
+		switch child := child.ChildType.(type) {
+		case *core.SetOperation_Child_XThis:
+			operation.Child[index] = ComputedUserset(relation)
+			found = true
+
+		case *core.SetOperation_Child_UsersetRewrite:
+			if replaceThis(child.UsersetRewrite, relation) {
+				found = true
+			}
+
+		case *core.SetOperation_Child_TupleToUserset:
+			if child.TupleToUserset.ComputedUserset != nil {
+				child.TupleToUserset.ComputedUserset.Object = core.ComputedUserset_TUPLE_USERSET_OBJECT
+			}
+		}
+	}
+	return found
+}
+
+func setOperationOf(rewrite *core.UsersetRewrite) *core.SetOperation {
+	switch operation := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		return operation.Union
+	case *core.UsersetRewrite_Intersection:
+		return operation.Intersection
+	case *core.UsersetRewrite_Exclusion:
+		return operation.Exclusion
+	default:
+		return nil
+	}
+}
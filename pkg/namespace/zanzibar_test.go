@@ -0,0 +1,122 @@
+package namespace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	iv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
+)
+
+// paperConfig is the namespace config of figure 1 of the Zanzibar paper.
+const paperConfig = `
+name: "doc"
+
+relation { name: "owner" }
+
+relation {
+  name: "editor"
+  userset_rewrite {
+    union {
+      child { _this {} }
+      child { computed_userset { relation: "owner" } }
+} } }
+
+relation {
+  name: "viewer"
+  userset_rewrite {
+    union {
+      child { _this {} }
+      child { computed_userset { relation: "editor" } }
+      child { tuple_to_userset {
+        tupleset { relation: "parent" }
+        computed_userset {
+          object: $TUPLE_USERSET_OBJECT  # parent folder
+          relation: "viewer"
+      } } }
+} } }
+`
+
+func TestParseZanzibarConfig(t *testing.T) {
+	nsDef, err := ParseZanzibarConfig(paperConfig)
+	require.NoError(t, err)
+	require.Equal(t, "doc", nsDef.Name)
+
+	var names []string
+	kinds := map[string]iv1.RelationMetadata_RelationKind{}
+	for _, relation := range nsDef.Relation {
+		names = append(names, relation.Name)
+		kinds[relation.Name] = GetRelationKind(relation)
+	}
+	require.Equal(t, []string{"owner", "editor_direct", "editor", "viewer_direct", "viewer"}, names)
+	require.Equal(t, map[string]iv1.RelationMetadata_RelationKind{
+		"owner":         iv1.RelationMetadata_RELATION,
+		"editor_direct": iv1.RelationMetadata_RELATION,
+		"editor":        iv1.RelationMetadata_PERMISSION,
+		"viewer_direct": iv1.RelationMetadata_RELATION,
+		"viewer":        iv1.RelationMetadata_PERMISSION,
+	}, kinds)
+
+	require.Nil(t, nsDef.Relation[0].UsersetRewrite)
+
+	viewer := nsDef.Relation[4].UsersetRewrite.GetUnion().Child
+	require.Equal(t, "viewer_direct", viewer[0].GetComputedUserset().Relation)
+	require.Equal(t, "editor", viewer[1].GetComputedUserset().Relation)
+	require.Equal(t, "parent", viewer[2].GetTupleToUserset().Tupleset.Relation)
+	require.Equal(t, "viewer", viewer[2].GetTupleToUserset().ComputedUserset.Relation)
+	require.Equal(t, core.ComputedUserset_TUPLE_USERSET_OBJECT, viewer[2].GetTupleToUserset().ComputedUserset.Object)
+}
+
+func TestParseZanzibarConfigOnlyThis(t *testing.T) {
+	nsDef, err := ParseZanzibarConfig(`
+name: "group"
+relation {
+  name: "member"
+  userset_rewrite { union { child { _this {} } } }
+}
+relation {
+  name: "banned_member"
+  userset_rewrite {
+    exclusion {
+      child { computed_userset { relation: "member" } }
+      child { userset_rewrite { union { child { _this {} } } } }
+    }
+  }
+}
+`)
+	require.NoError(t, err)
+	require.Len(t, nsDef.Relation, 3)
+	require.Nil(t, nsDef.Relation[0].UsersetRewrite)
+	require.Equal(t, "banned_member_direct", nsDef.Relation[1].Name)
+
+	nested := nsDef.Relation[2].UsersetRewrite.GetExclusion().Child[1].GetUsersetRewrite().GetUnion().Child[0]
+	require.Equal(t, "banned_member_direct", nested.GetComputedUserset().Relation)
+}
+
+func TestParseZanzibarConfigErrors(t *testing.T) {
+	tcs := []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{"invalid syntax", `name: "doc" relation {`, "invalid namespace config"},
+		{"unknown field", `name: "doc" relation { name: "owner" kind: "relation" }`, "invalid namespace config"},
+		{"invalid name", `name: "Doc"`, "invalid namespace config"},
+		{
+			"direct relation already defined",
+			`name: "doc"
+			relation { name: "viewer_direct" }
+			relation { name: "viewer" userset_rewrite { union { child { _this {} } child { computed_userset { relation: "viewer_direct" } } } } }`,
+			"relation `viewer` uses _this, but `viewer_direct` is already defined",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseZanzibarConfig(tc.config)
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package openfga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/authzed/spicedb/internal/services/shared"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/typesystem"
+)
+
+// directRelationSuffix is appended to the name of an OpenFGA relation which both relates users
+// directly and is rewritten, to name the relation holding its tuples.
+const directRelationSuffix = "_direct"
+
+var (
+	typeNameRegex     = regexp.MustCompile(`^([a-z][a-z0-9_]{1,61}[a-z0-9]/)*[a-z][a-z0-9_]{1,62}[a-z0-9]$`)
+	relationNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{1,62}[a-z0-9]$`)
+)
+
+var conditionParamTypes = map[string]string{
+	"TYPE_NAME_ANY":       "any",
+	"TYPE_NAME_BOOL":      "bool",
+	"TYPE_NAME_STRING":    "string",
+	"TYPE_NAME_INT":       "int",
+	"TYPE_NAME_UINT":      "uint",
+	"TYPE_NAME_DOUBLE":    "double",
+	"TYPE_NAME_DURATION":  "duration",
+	"TYPE_NAME_TIMESTAMP": "timestamp",
+	"TYPE_NAME_IPADDRESS": "ipaddress",
+	"TYPE_NAME_LIST":      "list",
+	"TYPE_NAME_MAP":       "map",
+}
+
+// Warning reports a construct of an OpenFGA model which was converted, but not one for one.
+type Warning struct {
+	// Type is the OpenFGA type of the construct.
+	Type string
+
+	// Relation is the OpenFGA relation of the construct, if any.
+	Relation string
+
+	// Message describes how the construct was converted.
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Relation == "" {
+		return fmt.Sprintf("type `%s`: %s", w.Type, w.Message)
+	}
+	return fmt.Sprintf("relation `%s#%s`: %s", w.Type, w.Relation, w.Message)
+}
+
+// Conversion is an OpenFGA authorization model converted into a schema.
+type Conversion struct {
+	// Schema is the converted schema.
+	Schema string
+
+	// Warnings are the constructs of the model which were not converted one for one.
+	Warnings []Warning
+
+	compiled  *compiler.CompiledSchema
+	validated *shared.ValidatedSchemaChanges
+
+	// typeSystems and caveats are the definitions of the schema, against which the converted
+	// relationships are validated.
+	typeSystems map[string]*typesystem.TypeSystem
+	caveats     map[string]*core.CaveatDefinition
+
+	// tupleRelations maps each OpenFGA relation accepting tuples, by type, to the relation
+	// holding them in the schema.
+	tupleRelations map[string]map[string]string
+}
+
+// Convert converts an OpenFGA authorization model into a schema, which is compiled and validated.
+//
+// Types become object definitions and conditions become caveats. Relations which only relate
+// users directly become relations, and rewritten relations become permissions. A relation which
+// does both is split in two: a relation named with a `_direct` suffix, holding its tuples, and a
+// permission using it in place of the direct relationship; this is reported as a warning.
+//
+// Constructs without an equivalent, such as names which are not valid identifiers, are returned
+// as errors.
+func Convert(ctx context.Context, model *Model) (*Conversion, error) {
+	c := &converter{conversion: &Conversion{tupleRelations: map[string]map[string]string{}}}
+
+	var schema strings.Builder
+	for _, name := range sortedKeys(model.Conditions) {
+		c.writeCaveat(&schema, name, model.Conditions[name])
+	}
+	for _, typeDef := range model.TypeDefinitions {
+		c.writeDefinition(&schema, typeDef)
+	}
+	if len(c.errs) > 0 {
+		return nil, errors.Join(c.errs...)
+	}
+	c.conversion.Schema = schema.String()
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("openfga"),
+		SchemaString: c.conversion.Schema,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return nil, fmt.Errorf("converted schema does not compile: %w", err)
+	}
+
+	validated, err := shared.ValidateSchemaChanges(ctx, compiled, false)
+	if err != nil {
+		return nil, fmt.Errorf("converted schema is invalid: %w", err)
+	}
+	c.conversion.compiled = compiled
+	c.conversion.validated = validated
+
+	c.conversion.typeSystems = make(map[string]*typesystem.TypeSystem, len(compiled.ObjectDefinitions))
+	for _, def := range compiled.ObjectDefinitions {
+		ts, err := typesystem.NewNamespaceTypeSystem(def, typesystem.ResolverForSchema(*compiled))
+		if err != nil {
+			return nil, err
+		}
+		c.conversion.typeSystems[def.Name] = ts
+	}
+
+	c.conversion.caveats = make(map[string]*core.CaveatDefinition, len(compiled.CaveatDefinitions))
+	for _, caveat := range compiled.CaveatDefinitions {
+		c.conversion.caveats[caveat.Name] = caveat
+	}
+	return c.conversion, nil
+}
+
+type converter struct {
+	conversion *Conversion
+	errs       []error
+}
+
+func (c *converter) errorf(format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf(format, args...))
+}
+
+func (c *converter) warn(typeName, relation, format string, args ...any) {
+	c.conversion.Warnings = append(c.conversion.Warnings, Warning{
+		Type:     typeName,
+		Relation: relation,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *converter) writeCaveat(schema *strings.Builder, name string, condition Condition) {
+	if !typeNameRegex.MatchString(name) {
+		c.errorf("condition `%s`: name is not a valid caveat name", name)
+		return
+	}
+
+	params := make([]string, 0, len(condition.Parameters))
+	for _, paramName := range sortedKeys(condition.Parameters) {
+		paramType, err := conditionParamType(condition.Parameters[paramName])
+		if err != nil {
+			c.errorf("condition `%s`: parameter `%s`: %w", name, paramName, err)
+			continue
+		}
+		params = append(params, paramName+" "+paramType)
+	}
+
+	fmt.Fprintf(schema, "caveat %s(%s) {\n\t%s\n}\n\n", name, strings.Join(params, ", "), condition.Expression)
+}
+
+func conditionParamType(paramType ConditionParamType) (string, error) {
+	keyword, ok := conditionParamTypes[paramType.TypeName]
+	if !ok {
+		return "", fmt.Errorf("unsupported type %s", paramType.TypeName)
+	}
+
+	if keyword != "list" && keyword != "map" {
+		return keyword, nil
+	}
+	if len(paramType.GenericTypes) != 1 {
+		return "", fmt.Errorf("type %s requires a single generic type", paramType.TypeName)
+	}
+	generic, err := conditionParamType(paramType.GenericTypes[0])
+	if err != nil {
+		return "", err
+	}
+	return keyword + "<" + generic + ">", nil
+}
+
+func (c *converter) writeDefinition(schema *strings.Builder, typeDef TypeDefinition) {
+	if !typeNameRegex.MatchString(typeDef.Type) {
+		c.errorf("type `%s`: name is not a valid object definition name", typeDef.Type)
+		return
+	}
+
+	var relations, permissions []string
+	tupleRelations := map[string]string{}
+	for _, name := range sortedKeys(typeDef.Relations) {
+		if !relationNameRegex.MatchString(name) {
+			c.errorf("relation `%s#%s`: name is not a valid relation name", typeDef.Type, name)
+			continue
+		}
+
+		userset := typeDef.Relations[name]
+		if userset != nil && userset.This != nil {
+			allowedTypes := c.allowedTypes(typeDef, name)
+			relations = append(relations, fmt.Sprintf("\trelation %s: %s\n", name, allowedTypes))
+			tupleRelations[name] = name
+			continue
+		}
+
+		direct := ""
+		if hasThis(userset) {
+			direct = name + directRelationSuffix
+			if _, ok := typeDef.Relations[direct]; ok {
+				c.errorf("relation `%s#%s`: relates users directly and is rewritten, but `%s` is already defined", typeDef.Type, name, direct)
+				continue
+			}
+
+			allowedTypes := c.allowedTypes(typeDef, name)
+			relations = append(relations, fmt.Sprintf("\trelation %s: %s\n", direct, allowedTypes))
+			tupleRelations[name] = direct
+			c.warn(typeDef.Type, name, "relates users directly and is rewritten, so its tuples are stored in relation `%s`", direct)
+		}
+
+		expression, err := c.expression(userset, direct, false)
+		if err != nil {
+			c.errorf("relation `%s#%s`: %w", typeDef.Type, name, err)
+			continue
+		}
+		permissions = append(permissions, fmt.Sprintf("\tpermission %s = %s\n", name, expression))
+	}
+	c.conversion.tupleRelations[typeDef.Type] = tupleRelations
+
+	fmt.Fprintf(schema, "definition %s {\n", typeDef.Type)
+	for _, relation := range relations {
+		schema.WriteString(relation)
+	}
+	if len(relations) > 0 && len(permissions) > 0 {
+		schema.WriteString("\n")
+	}
+	for _, permission := range permissions {
+		schema.WriteString(permission)
+	}
+	schema.WriteString("}\n\n")
+}
+
+// allowedTypes returns the type annotation of a relation, from its type restrictions.
+func (c *converter) allowedTypes(typeDef TypeDefinition, relation string) string {
+	var restrictions []RelationReference
+	if typeDef.Metadata != nil {
+		restrictions = typeDef.Metadata.Relations[relation].DirectlyRelatedUserTypes
+	}
+	if len(restrictions) == 0 {
+		c.errorf("relation `%s#%s`: relates users directly but has no type restrictions", typeDef.Type, relation)
+		return ""
+	}
+
+	allowed := make([]string, 0, len(restrictions))
+	for _, restriction := range restrictions {
+		annotation := restriction.Type
+		switch {
+		case restriction.Wildcard != nil:
+			annotation += ":*"
+		case restriction.Relation != "":
+			annotation += "#" + restriction.Relation
+		}
+		if restriction.Condition != "" {
+			annotation += " with " + restriction.Condition
+		}
+		allowed = append(allowed, annotation)
+	}
+	return strings.Join(allowed, " | ")
+}
+
+// expression returns the permission expression of a userset, where direct is the relation
+// standing for the users related directly.
+func (c *converter) expression(userset *Userset, direct string, nested bool) (string, error) {
+	switch {
+	case userset == nil:
+		return "", errors.New("missing userset")
+
+	case userset.This != nil:
+		return direct, nil
+
+	case userset.ComputedUserset != nil:
+		return userset.ComputedUserset.Relation, nil
+
+	case userset.TupleToUserset != nil:
+		return userset.TupleToUserset.Tupleset.Relation + "->" + userset.TupleToUserset.ComputedUserset.Relation, nil
+
+	case userset.Union != nil:
+		return c.join(userset.Union.Child, " + ", direct, nested)
+
+	case userset.Intersection != nil:
+		return c.join(userset.Intersection.Child, " & ", direct, nested)
+
+	case userset.Difference != nil:
+		return c.join([]*Userset{userset.Difference.Base, userset.Difference.Subtract}, " - ", direct, nested)
+
+	default:
+		return "", errors.New("unsupported userset")
+	}
+}
+
+func (c *converter) join(children []*Userset, operator string, direct string, nested bool) (string, error) {
+	if len(children) == 0 {
+		return "", errors.New("empty set operation")
+	}
+
+	expressions := make([]string, 0, len(children))
+	for _, child := range children {
+		expression, err := c.expression(child, direct, true)
+		if err != nil {
+			return "", err
+		}
+		expressions = append(expressions, expression)
+	}
+
+	joined := strings.Join(expressions, operator)
+	if nested && len(expressions) > 1 {
+		return "(" + joined + ")", nil
+	}
+	return joined, nil
+}
+
+// hasThis returns whether a userset relates users directly anywhere within it.
+func hasThis(userset *Userset) bool {
+	switch {
+	case userset == nil:
+		return false
+	case userset.This != nil:
+		return true
+	case userset.Union != nil:
+		return anyHasThis(userset.Union.Child)
+	case userset.Intersection != nil:
+		return anyHasThis(userset.Intersection.Child)
+	case userset.Difference != nil:
+		return hasThis(userset.Difference.Base) || hasThis(userset.Difference.Subtract)
+	default:
+		return false
+	}
+}
+
+func anyHasThis(usersets []*Userset) bool {
+	for _, userset := range usersets {
+		if hasThis(userset) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
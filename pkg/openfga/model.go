@@ -0,0 +1,120 @@
+// Package openfga converts OpenFGA authorization models and tuples into schemas and
+// relationships, and imports them into a datastore, to ease migrating off OpenFGA.
+//
+// Only the JSON representation of models with type restrictions, i.e. of schema version 1.1
+// and later, is supported; models written in the OpenFGA DSL can be converted to JSON with the
+// `fga model transform` command of the OpenFGA CLI.
+package openfga
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Model is an OpenFGA authorization model, in the JSON representation of the OpenFGA API.
+type Model struct {
+	SchemaVersion   string               `json:"schema_version"`
+	TypeDefinitions []TypeDefinition     `json:"type_definitions"`
+	Conditions      map[string]Condition `json:"conditions,omitempty"`
+}
+
+// TypeDefinition is an OpenFGA type and its relations.
+type TypeDefinition struct {
+	Type      string              `json:"type"`
+	Relations map[string]*Userset `json:"relations,omitempty"`
+	Metadata  *TypeMetadata       `json:"metadata,omitempty"`
+}
+
+// TypeMetadata holds the type restrictions of the relations of a type.
+type TypeMetadata struct {
+	Relations map[string]RelationMetadata `json:"relations,omitempty"`
+}
+
+// RelationMetadata holds the types of users which may be directly related by a relation.
+type RelationMetadata struct {
+	DirectlyRelatedUserTypes []RelationReference `json:"directly_related_user_types,omitempty"`
+}
+
+// RelationReference is a type restriction of a relation: a type, a wildcard of a type, or a
+// relation of a type, optionally along with a condition.
+type RelationReference struct {
+	Type      string    `json:"type"`
+	Relation  string    `json:"relation,omitempty"`
+	Wildcard  *struct{} `json:"wildcard,omitempty"`
+	Condition string    `json:"condition,omitempty"`
+}
+
+// Userset is the rewrite of an OpenFGA relation. Exactly one of its fields is set.
+type Userset struct {
+	This            *struct{}       `json:"this,omitempty"`
+	ComputedUserset *ObjectRelation `json:"computedUserset,omitempty"`
+	TupleToUserset  *TupleToUserset `json:"tupleToUserset,omitempty"`
+	Union           *Usersets       `json:"union,omitempty"`
+	Intersection    *Usersets       `json:"intersection,omitempty"`
+	Difference      *DifferenceOf   `json:"difference,omitempty"`
+}
+
+// ObjectRelation references a relation of the object.
+type ObjectRelation struct {
+	Relation string `json:"relation"`
+}
+
+// TupleToUserset references a relation of the objects related by the tupleset relation.
+type TupleToUserset struct {
+	Tupleset        ObjectRelation `json:"tupleset"`
+	ComputedUserset ObjectRelation `json:"computedUserset"`
+}
+
+// Usersets are the children of a union or an intersection.
+type Usersets struct {
+	Child []*Userset `json:"child"`
+}
+
+// DifferenceOf is the users of the base which are not in the subtracted userset.
+type DifferenceOf struct {
+	Base     *Userset `json:"base"`
+	Subtract *Userset `json:"subtract"`
+}
+
+// Condition is an OpenFGA condition: a CEL expression over typed parameters.
+type Condition struct {
+	Name       string                        `json:"name"`
+	Expression string                        `json:"expression"`
+	Parameters map[string]ConditionParamType `json:"parameters,omitempty"`
+}
+
+// ConditionParamType is the type of a parameter of a condition.
+type ConditionParamType struct {
+	TypeName     string               `json:"type_name"`
+	GenericTypes []ConditionParamType `json:"generic_types,omitempty"`
+}
+
+// ParseModel reads the JSON representation of an OpenFGA authorization model, as returned by
+// the OpenFGA API, optionally wrapped in an `authorization_model` object.
+func ParseModel(r io.Reader) (*Model, error) {
+	var wrapper struct {
+		Model
+		AuthorizationModel *Model `json:"authorization_model"`
+	}
+	if err := json.NewDecoder(r).Decode(&wrapper); err != nil {
+		return nil, fmt.Errorf("invalid authorization model: %w", err)
+	}
+
+	model := &wrapper.Model
+	if wrapper.AuthorizationModel != nil {
+		model = wrapper.AuthorizationModel
+	}
+
+	switch model.SchemaVersion {
+	case "":
+		return nil, errors.New("invalid authorization model: missing schema_version")
+	case "1.0":
+		return nil, errors.New("authorization models of schema version 1.0 have no type restrictions and are not supported")
+	}
+	if len(model.TypeDefinitions) == 0 {
+		return nil, errors.New("invalid authorization model: no type definitions")
+	}
+	return model, nil
+}
@@ -0,0 +1,281 @@
+package openfga
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testModel = `{
+  "id": "01HVMMBCMGZNT3SED4Z17ECXCA",
+  "schema_version": "1.1",
+  "type_definitions": [
+    {"type": "user"},
+    {
+      "type": "group",
+      "relations": {"member": {"this": {}}},
+      "metadata": {"relations": {"member": {"directly_related_user_types": [{"type": "user"}, {"type": "group", "relation": "member"}]}}}
+    },
+    {
+      "type": "folder",
+      "relations": {"viewer": {"this": {}}},
+      "metadata": {"relations": {"viewer": {"directly_related_user_types": [{"type": "user"}, {"type": "user", "wildcard": {}}]}}}
+    },
+    {
+      "type": "document",
+      "relations": {
+        "parent": {"this": {}},
+        "owner": {"this": {}},
+        "blocked": {"this": {}},
+        "editor": {"union": {"child": [{"this": {}}, {"computedUserset": {"relation": "owner"}}]}},
+        "viewer": {"difference": {
+          "base": {"union": {"child": [
+            {"computedUserset": {"relation": "editor"}},
+            {"tupleToUserset": {"tupleset": {"relation": "parent"}, "computedUserset": {"relation": "viewer"}}}
+          ]}},
+          "subtract": {"computedUserset": {"relation": "blocked"}}
+        }}
+      },
+      "metadata": {"relations": {
+        "parent": {"directly_related_user_types": [{"type": "folder"}]},
+        "owner": {"directly_related_user_types": [{"type": "user"}, {"type": "user", "condition": "non_expired"}]},
+        "blocked": {"directly_related_user_types": [{"type": "user"}]},
+        "editor": {"directly_related_user_types": [{"type": "group", "relation": "member"}]},
+        "viewer": {}
+      }}
+    }
+  ],
+  "conditions": {
+    "non_expired": {
+      "name": "non_expired",
+      "expression": "current_time < expiration",
+      "parameters": {
+        "current_time": {"type_name": "TYPE_NAME_TIMESTAMP"},
+        "expiration": {"type_name": "TYPE_NAME_TIMESTAMP"}
+      }
+    }
+  }
+}`
+
+const expectedSchema = `caveat non_expired(current_time timestamp, expiration timestamp) {
+	current_time < expiration
+}
+
+definition user {
+}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition folder {
+	relation viewer: user | user:*
+}
+
+definition document {
+	relation blocked: user
+	relation editor_direct: group#member
+	relation owner: user | user with non_expired
+	relation parent: folder
+
+	permission editor = editor_direct + owner
+	permission viewer = (editor + parent->viewer) - blocked
+}
+
+`
+
+const testTuples = `[
+  {"user": "user:anne", "relation": "member", "object": "group:eng"},
+  {"user": "user:*", "relation": "viewer", "object": "folder:shared"},
+  {"user": "folder:shared", "relation": "parent", "object": "document:readme"},
+  {"user": "group:eng#member", "relation": "editor", "object": "document:readme"},
+  {"user": "user:bob", "relation": "owner", "object": "document:readme", "condition": {"name": "non_expired", "context": {"expiration": "2030-01-01T00:00:00Z"}}}
+]`
+
+func convertTestModel(t *testing.T) *Conversion {
+	model, err := ParseModel(strings.NewReader(testModel))
+	require.NoError(t, err)
+
+	conversion, err := Convert(context.Background(), model)
+	require.NoError(t, err)
+	return conversion
+}
+
+func TestConvert(t *testing.T) {
+	conversion := convertTestModel(t)
+	require.Equal(t, expectedSchema, conversion.Schema)
+	require.Equal(t, []Warning{{
+		Type:     "document",
+		Relation: "editor",
+		Message:  "relates users directly and is rewritten, so its tuples are stored in relation `editor_direct`",
+	}}, conversion.Warnings)
+}
+
+func TestConvertWrappedModel(t *testing.T) {
+	model, err := ParseModel(strings.NewReader(`{"authorization_model": ` + testModel + `}`))
+	require.NoError(t, err)
+	require.Len(t, model.TypeDefinitions, 4)
+}
+
+func TestConvertErrors(t *testing.T) {
+	tcs := []struct {
+		name          string
+		model         string
+		expectedError string
+	}{
+		{
+			"schema version 1.0",
+			`{"schema_version": "1.0", "type_definitions": [{"type": "user"}]}`,
+			"schema version 1.0",
+		},
+		{
+			"no type definitions",
+			`{"schema_version": "1.1", "type_definitions": []}`,
+			"no type definitions",
+		},
+		{
+			"invalid type name",
+			`{"schema_version": "1.1", "type_definitions": [{"type": "User"}]}`,
+			"type `User`: name is not a valid object definition name",
+		},
+		{
+			"invalid relation name",
+			`{"schema_version": "1.1", "type_definitions": [{"type": "user"}, {"type": "doc", "relations": {"can-view": {"this": {}}}, "metadata": {"relations": {"can-view": {"directly_related_user_types": [{"type": "user"}]}}}}]}`,
+			"relation `doc#can-view`: name is not a valid relation name",
+		},
+		{
+			"no type restrictions",
+			`{"schema_version": "1.1", "type_definitions": [{"type": "user"}, {"type": "doc", "relations": {"viewer": {"this": {}}}}]}`,
+			"relation `doc#viewer`: relates users directly but has no type restrictions",
+		},
+		{
+			"direct relation already defined",
+			`{"schema_version": "1.1", "type_definitions": [{"type": "user"}, {"type": "doc", "relations": {
+				"viewer": {"union": {"child": [{"this": {}}, {"computedUserset": {"relation": "viewer_direct"}}]}},
+				"viewer_direct": {"this": {}}
+			}, "metadata": {"relations": {"viewer": {"directly_related_user_types": [{"type": "user"}]}, "viewer_direct": {"directly_related_user_types": [{"type": "user"}]}}}}]}`,
+			"but `viewer_direct` is already defined",
+		},
+		{
+			"unsupported condition parameter",
+			`{"schema_version": "1.1", "type_definitions": [{"type": "user"}], "conditions": {"cond": {"name": "cond", "expression": "true", "parameters": {"p": {"type_name": "TYPE_NAME_UNSPECIFIED"}}}}}`,
+			"condition `cond`: parameter `p`: unsupported type TYPE_NAME_UNSPECIFIED",
+		},
+		{
+			"unknown relation",
+			`{"schema_version": "1.1", "type_definitions": [{"type": "user"}, {"type": "doc", "relations": {"viewer": {"computedUserset": {"relation": "editor"}}}}]}`,
+			"converted schema is invalid",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			model, err := ParseModel(strings.NewReader(tc.model))
+			if err == nil {
+				_, err = Convert(context.Background(), model)
+			}
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestRelationship(t *testing.T) {
+	conversion := convertTestModel(t)
+	tuples, err := ParseTuples(strings.NewReader(testTuples))
+	require.NoError(t, err)
+
+	var converted []string
+	for _, tpl := range tuples {
+		rel, err := conversion.Relationship(tpl)
+		require.NoError(t, err)
+		converted = append(converted, tuple.MustString(rel))
+	}
+	require.Equal(t, []string{
+		"group:eng#member@user:anne",
+		"folder:shared#viewer@user:*",
+		"document:readme#parent@folder:shared",
+		"document:readme#editor_direct@group:eng#member",
+		`document:readme#owner@user:bob[non_expired:{"expiration":"2030-01-01T00:00:00Z"}]`,
+	}, converted)
+
+	tcs := []struct {
+		tuple         Tuple
+		expectedError string
+	}{
+		{Tuple{User: "user:anne", Relation: "viewer", Object: "document:readme"}, "does not relate users directly"},
+		{Tuple{User: "user:anne", Relation: "owner", Object: "readme"}, "invalid object"},
+		{Tuple{User: "anne", Relation: "owner", Object: "document:readme"}, "invalid user"},
+		{Tuple{User: "group:eng", Relation: "owner", Object: "document:readme"}, "subjects of type `group` are not allowed"},
+	}
+	for _, tc := range tcs {
+		_, err := conversion.Relationship(tc.tuple)
+		require.ErrorContains(t, err, tc.expectedError)
+	}
+}
+
+func TestParseReadResponse(t *testing.T) {
+	tuples, err := ParseTuples(strings.NewReader(`{"tuples": [{"key": {"user": "user:anne", "relation": "member", "object": "group:eng"}, "timestamp": "2024-01-01T00:00:00Z"}]}`))
+	require.NoError(t, err)
+	require.Equal(t, []Tuple{{User: "user:anne", Relation: "member", Object: "group:eng"}}, tuples)
+
+	_, err = ParseTuples(strings.NewReader(`not json`))
+	require.ErrorContains(t, err, "invalid tuples")
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	conversion := convertTestModel(t)
+	tuples, err := ParseTuples(strings.NewReader(testTuples))
+	require.NoError(t, err)
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	revision, stats, err := Import(ctx, ds, conversion, tuples)
+	require.NoError(t, err)
+	require.Equal(t, Stats{Caveats: 1, Namespaces: 4, Relationships: 5}, stats)
+
+	reader := ds.SnapshotReader(revision)
+	var relationships []string
+	for _, namespace := range []string{"document", "folder", "group"} {
+		iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: namespace})
+		require.NoError(t, err)
+		for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+			relationships = append(relationships, tuple.MustString(tpl))
+		}
+		require.NoError(t, iter.Err())
+		iter.Close()
+	}
+	sort.Strings(relationships)
+	require.Len(t, relationships, 5)
+	require.Contains(t, relationships, "document:readme#editor_direct@group:eng#member")
+
+	_, _, err = Import(ctx, ds, conversion, nil)
+	require.ErrorIs(t, err, ErrDatastoreNotEmpty)
+}
+
+func TestImportInvalidTuple(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	_, _, err = Import(context.Background(), ds, convertTestModel(t), []Tuple{
+		{User: "user:anne", Relation: "member", Object: "group:eng"},
+		{User: "user:anne", Relation: "viewer", Object: "document:readme"},
+	})
+	require.ErrorContains(t, err, "does not relate users directly")
+
+	revision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	namespaces, err := ds.SnapshotReader(revision).ListAllNamespaces(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, namespaces)
+}
@@ -0,0 +1,207 @@
+package openfga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// ErrDatastoreNotEmpty is returned by Import when the target datastore already contains a schema.
+var ErrDatastoreNotEmpty = errors.New("OpenFGA models can only be imported into an empty datastore")
+
+// Tuple is an OpenFGA relationship tuple.
+type Tuple struct {
+	// User is the user of the tuple: `type:id`, `type:*` or `type:id#relation`.
+	User string `json:"user"`
+
+	// Relation is the relation of the object.
+	Relation string `json:"relation"`
+
+	// Object is the object of the tuple: `type:id`.
+	Object string `json:"object"`
+
+	// Condition is the condition of the tuple, if any.
+	Condition *TupleCondition `json:"condition,omitempty"`
+}
+
+// TupleCondition is a condition of a tuple, along with the context it is evaluated with.
+type TupleCondition struct {
+	Name    string         `json:"name"`
+	Context map[string]any `json:"context,omitempty"`
+}
+
+func (t Tuple) String() string {
+	return t.Object + "#" + t.Relation + "@" + t.User
+}
+
+// ParseTuples reads OpenFGA tuples, either as a JSON array of tuples, as written by the OpenFGA
+// CLI, or as the response of the Read API of OpenFGA, in which each tuple is under a `key`.
+func ParseTuples(r io.Reader) ([]Tuple, error) {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	var tuples []Tuple
+	if err := json.Unmarshal(contents, &tuples); err == nil {
+		return tuples, nil
+	}
+
+	var response struct {
+		Tuples []struct {
+			Key Tuple `json:"key"`
+		} `json:"tuples"`
+	}
+	if err := json.Unmarshal(contents, &response); err != nil {
+		return nil, fmt.Errorf("invalid tuples: %w", err)
+	}
+
+	tuples = make([]Tuple, 0, len(response.Tuples))
+	for _, tpl := range response.Tuples {
+		tuples = append(tuples, tpl.Key)
+	}
+	return tuples, nil
+}
+
+// Relationship converts an OpenFGA tuple into a relationship of the converted schema.
+func (c *Conversion) Relationship(t Tuple) (*core.RelationTuple, error) {
+	objectType, objectID, ok := strings.Cut(t.Object, ":")
+	if !ok {
+		return nil, fmt.Errorf("tuple `%s`: invalid object", t)
+	}
+
+	relation, ok := c.tupleRelations[objectType][t.Relation]
+	if !ok {
+		return nil, fmt.Errorf("tuple `%s`: relation `%s#%s` does not relate users directly", t, objectType, t.Relation)
+	}
+
+	user, userRelation, hasRelation := strings.Cut(t.User, "#")
+	userType, userID, ok := strings.Cut(user, ":")
+	if !ok {
+		return nil, fmt.Errorf("tuple `%s`: invalid user", t)
+	}
+	if !hasRelation {
+		userRelation = tuple.Ellipsis
+	}
+
+	rel := &core.RelationTuple{
+		ResourceAndRelation: &core.ObjectAndRelation{
+			Namespace: objectType,
+			ObjectId:  objectID,
+			Relation:  relation,
+		},
+		Subject: &core.ObjectAndRelation{
+			Namespace: userType,
+			ObjectId:  userID,
+			Relation:  userRelation,
+		},
+	}
+
+	if t.Condition != nil {
+		caveatContext, err := structpb.NewStruct(t.Condition.Context)
+		if err != nil {
+			return nil, fmt.Errorf("tuple `%s`: invalid condition context: %w", t, err)
+		}
+		rel.Caveat = &core.ContextualizedCaveat{CaveatName: t.Condition.Name, Context: caveatContext}
+	}
+
+	if err := rel.Validate(); err != nil {
+		return nil, fmt.Errorf("tuple `%s`: %w", t, err)
+	}
+	if err := relationships.ValidateOneRelationship(c.typeSystems, c.caveats, rel, relationships.ValidateRelationshipForCreateOrTouch); err != nil {
+		return nil, fmt.Errorf("tuple `%s`: %w", t, err)
+	}
+	return rel, nil
+}
+
+// Stats counts what was imported.
+type Stats struct {
+	Caveats       uint64
+	Namespaces    uint64
+	Relationships uint64
+}
+
+// Import writes the converted schema and the relationships of the OpenFGA tuples into the
+// datastore, which must not contain a schema, in a single transaction.
+func Import(ctx context.Context, ds datastore.Datastore, conversion *Conversion, tuples []Tuple) (datastore.Revision, Stats, error) {
+	var stats Stats
+	revision, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		// Reset the counts, in case the transaction is retried.
+		stats = Stats{}
+
+		if err := requireEmpty(ctx, rwt); err != nil {
+			return err
+		}
+
+		if _, err := shared.ApplySchemaChanges(ctx, rwt, conversion.validated); err != nil {
+			return fmt.Errorf("failed to write schema: %w", err)
+		}
+		stats.Caveats = uint64(len(conversion.compiled.CaveatDefinitions))
+		stats.Namespaces = uint64(len(conversion.compiled.ObjectDefinitions))
+
+		source := &tupleSource{conversion: conversion, tuples: tuples}
+		loaded, err := rwt.BulkLoad(ctx, source)
+		stats.Relationships = loaded
+		if err != nil {
+			return err
+		}
+		return source.err
+	}, options.WithDisableRetries(true))
+	if err != nil {
+		return nil, stats, err
+	}
+
+	return revision, stats, nil
+}
+
+func requireEmpty(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+	namespaces, err := rwt.ListAllNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	caveats, err := rwt.ListAllCaveats(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(namespaces) > 0 || len(caveats) > 0 {
+		return ErrDatastoreNotEmpty
+	}
+	return nil
+}
+
+// tupleSource converts OpenFGA tuples into relationships for bulk loading.
+type tupleSource struct {
+	conversion *Conversion
+	tuples     []Tuple
+	err        error
+}
+
+func (ts *tupleSource) Next(_ context.Context) (*core.RelationTuple, error) {
+	if len(ts.tuples) == 0 {
+		return nil, nil
+	}
+
+	tpl := ts.tuples[0]
+	ts.tuples = ts.tuples[1:]
+
+	rel, err := ts.conversion.Relationship(tpl)
+	if err != nil {
+		ts.err = err
+		return nil, err
+	}
+	return rel, nil
+}
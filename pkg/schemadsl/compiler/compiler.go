@@ -40,8 +40,11 @@ type CompiledSchema struct {
 	// order in which they were found.
 	OrderedDefinitions []SchemaDefinition
 
-	rootNode *dslNode
-	mapper   input.PositionMapper
+	rootNodes []*dslNode
+	mapper    input.PositionMapper
+
+	// definitionSources holds the source of the input schema in which each definition was found.
+	definitionSources map[string]input.Source
 }
 
 // SourcePositionToRunePosition converts a source position to a rune position.
@@ -49,6 +52,13 @@ func (cs CompiledSchema) SourcePositionToRunePosition(source input.Source, posit
 	return cs.mapper.LineAndColToRunePosition(position.LineNumber, position.ColumnPosition, source)
 }
 
+// DefinitionSource returns the source of the input schema in which the named object or caveat
+// definition was found.
+func (cs CompiledSchema) DefinitionSource(name string) (input.Source, bool) {
+	source, ok := cs.definitionSources[name]
+	return source, ok
+}
+
 type config struct {
 	skipValidation   bool
 	objectTypePrefix *string
@@ -85,8 +95,11 @@ func Compile(schema InputSchema, prefix ObjectPrefixOption, opts ...Option) (*Co
 	root := parser.Parse(createAstNode, schema.Source, schema.SchemaString).(*dslNode)
 	errs := root.FindAll(dslshape.NodeTypeError)
 	if len(errs) > 0 {
-		err := errorNodeToError(errs[0], mapper)
-		return nil, err
+		parseErrs := make([]error, 0, len(errs))
+		for _, errNode := range errs {
+			parseErrs = append(parseErrs, errorNodeToError(errNode, mapper))
+		}
+		return nil, combineErrors(parseErrs)
 	}
 
 	compiled, err := translate(translationContext{
@@ -96,17 +109,85 @@ func Compile(schema InputSchema, prefix ObjectPrefixOption, opts ...Option) (*Co
 		skipValidate:     cfg.skipValidation,
 	}, root)
 	if err != nil {
-		var errorWithNode errorWithNode
-		if errors.As(err, &errorWithNode) {
-			err = toContextError(errorWithNode.error.Error(), errorWithNode.errorSourceCode, errorWithNode.node, mapper)
+		translationErrs := []error{err}
+		var multipleErrors MultipleErrors
+		if errors.As(err, &multipleErrors) {
+			translationErrs = multipleErrors.Errors
 		}
 
-		return nil, err
+		contextErrs := make([]error, 0, len(translationErrs))
+		for _, translationErr := range translationErrs {
+			var errorWithNode errorWithNode
+			if errors.As(translationErr, &errorWithNode) {
+				translationErr = toContextError(errorWithNode.error.Error(), errorWithNode.errorSourceCode, errorWithNode.node, mapper)
+			}
+			contextErrs = append(contextErrs, translationErr)
+		}
+
+		return nil, combineErrors(contextErrs)
+	}
+
+	compiled.definitionSources = make(map[string]input.Source, len(compiled.OrderedDefinitions))
+	for _, definition := range compiled.OrderedDefinitions {
+		compiled.definitionSources[definition.GetName()] = schema.Source
 	}
 
 	return compiled, nil
 }
 
+// CompileMultiple compiles the given input schemas, such as a schema split across multiple files,
+// into a single set of definitions. Definitions can reference those found in any of the inputs,
+// but each definition name must be unique across all of them.
+func CompileMultiple(schemas []InputSchema, prefix ObjectPrefixOption, opts ...Option) (*CompiledSchema, error) {
+	if len(schemas) == 0 {
+		return nil, errors.New("no input schemas given")
+	}
+
+	mapper := multiSourcePositionMapper{}
+	merged := &CompiledSchema{mapper: mapper, definitionSources: make(map[string]input.Source)}
+
+	for _, schema := range schemas {
+		if _, ok := mapper[schema.Source]; ok {
+			return nil, fmt.Errorf("found duplicate input source `%s`", schema.Source)
+		}
+
+		compiled, err := Compile(schema, prefix, opts...)
+		if err != nil {
+			return nil, err
+		}
+		mapper[schema.Source] = compiled.mapper
+
+		for index, definition := range compiled.OrderedDefinitions {
+			name := definition.GetName()
+			if existingSource, ok := merged.definitionSources[name]; ok {
+				return nil, duplicateDefinitionError(compiled, index, existingSource)
+			}
+			merged.definitionSources[name] = schema.Source
+		}
+
+		merged.ObjectDefinitions = append(merged.ObjectDefinitions, compiled.ObjectDefinitions...)
+		merged.CaveatDefinitions = append(merged.CaveatDefinitions, compiled.CaveatDefinitions...)
+		merged.OrderedDefinitions = append(merged.OrderedDefinitions, compiled.OrderedDefinitions...)
+		merged.rootNodes = append(merged.rootNodes, compiled.rootNodes...)
+	}
+
+	return merged, nil
+}
+
+func duplicateDefinitionError(compiled *CompiledSchema, index int, existingSource input.Source) error {
+	name := compiled.OrderedDefinitions[index].GetName()
+	errMessage := fmt.Sprintf("found name reused between multiple definitions and/or caveats: %s (previously defined in `%s`)", name, existingSource)
+
+	// Each top-level node of a compiled schema is a definition, in the same order as the
+	// compiled definitions.
+	definitionNodes := compiled.rootNodes[0].GetChildren()
+	if index >= len(definitionNodes) {
+		return errors.New(errMessage)
+	}
+
+	return toContextError(errMessage, name, definitionNodes[index], compiled.mapper)
+}
+
 func errorNodeToError(node *dslNode, mapper input.PositionMapper) error {
 	if node.GetType() != dslshape.NodeTypeError {
 		return fmt.Errorf("given none error node")
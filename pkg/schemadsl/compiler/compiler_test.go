@@ -11,6 +11,7 @@ import (
 	caveattypes "github.com/authzed/spicedb/pkg/caveats/types"
 	"github.com/authzed/spicedb/pkg/namespace"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/dslshape"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
 	"github.com/authzed/spicedb/pkg/testutil"
 )
@@ -1035,3 +1036,125 @@ func TestSuperLargeCaveatCompile(t *testing.T) {
 	require.Equal(t, 29, len(compiled.ObjectDefinitions))
 	require.Equal(t, 1, len(compiled.CaveatDefinitions))
 }
+
+func TestCompileMultiple(t *testing.T) {
+	compiled, err := CompileMultiple([]InputSchema{
+		{"users.zed", `definition user {}`},
+		{"documents.zed", `caveat somecaveat(someparam int) { someparam == 42 }
+
+		definition document {
+			relation viewer: user with somecaveat
+		}`},
+	}, AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(compiled.OrderedDefinitions))
+	for _, def := range compiled.OrderedDefinitions {
+		names = append(names, def.GetName())
+	}
+	require.Equal(t, []string{"user", "somecaveat", "document"}, names)
+	require.Len(t, compiled.ObjectDefinitions, 2)
+	require.Len(t, compiled.CaveatDefinitions, 1)
+
+	source, ok := compiled.DefinitionSource("user")
+	require.True(t, ok)
+	require.Equal(t, input.Source("users.zed"), source)
+
+	source, ok = compiled.DefinitionSource("somecaveat")
+	require.True(t, ok)
+	require.Equal(t, input.Source("documents.zed"), source)
+
+	_, ok = compiled.DefinitionSource("unknown")
+	require.False(t, ok)
+
+	// Positions in every source should be resolvable.
+	chain, err := PositionToAstNodeChain(compiled, "documents.zed", input.Position{LineNumber: 3, ColumnPosition: 13})
+	require.NoError(t, err)
+	require.NotNil(t, chain)
+	require.True(t, chain.HasHeadType(dslshape.NodeTypeRelation))
+
+	_, err = compiled.SourcePositionToRunePosition("users.zed", input.Position{LineNumber: 0, ColumnPosition: 5})
+	require.NoError(t, err)
+}
+
+func TestCompileMultipleErrors(t *testing.T) {
+	_, err := CompileMultiple(nil, AllowUnprefixedObjectType())
+	require.ErrorContains(t, err, "no input schemas given")
+
+	_, err = CompileMultiple([]InputSchema{
+		{"first.zed", `definition user {}`},
+		{"first.zed", `definition document {}`},
+	}, AllowUnprefixedObjectType())
+	require.ErrorContains(t, err, "found duplicate input source `first.zed`")
+
+	_, err = CompileMultiple([]InputSchema{
+		{"first.zed", `definition user {}`},
+		{"second.zed", `definition document {}
+
+		definition user {}`},
+	}, AllowUnprefixedObjectType())
+	require.EqualError(t, err, "parse error in `second.zed`, line 3, column 3: found name reused between multiple definitions and/or caveats: user (previously defined in `first.zed`)")
+
+	var errWithContext ErrorWithContext
+	require.ErrorAs(t, err, &errWithContext)
+	require.Equal(t, input.Source("second.zed"), errWithContext.Source)
+
+	_, err = CompileMultiple([]InputSchema{
+		{"first.zed", `definition user {}`},
+		{"second.zed", `definition document {`},
+	}, AllowUnprefixedObjectType())
+	require.ErrorContains(t, err, "parse error in `second.zed`")
+}
+
+func TestCompileReportsAllErrors(t *testing.T) {
+	_, err := Compile(InputSchema{"parse errors", `definition user {
+		relation foo: 
+	}
+
+	definition document {
+		permission view = 
+	}
+
+	definition folder {}`}, AllowUnprefixedObjectType())
+	require.Error(t, err)
+
+	var multipleErrors MultipleErrors
+	require.ErrorAs(t, err, &multipleErrors)
+
+	var lines []int
+	for _, err := range multipleErrors.Errors {
+		var errWithContext ErrorWithContext
+		require.ErrorAs(t, err, &errWithContext)
+
+		line, _, lerr := errWithContext.SourceRange.Start().LineAndColumn()
+		require.NoError(t, lerr)
+		lines = append(lines, line)
+	}
+	require.Contains(t, lines, 2)
+	require.Contains(t, lines, 6)
+
+	_, err = Compile(InputSchema{"translation errors", `definition user {}
+
+	caveat somecaveat(someparam unknowntype) {
+		someparam
+	}
+
+	definition document {
+		relation viewer: user
+	}
+
+	definition user {}`}, AllowUnprefixedObjectType())
+	require.ErrorAs(t, err, &multipleErrors)
+	require.Len(t, multipleErrors.Errors, 2)
+	require.ErrorContains(t, multipleErrors.Errors[0], "line 3, column 2")
+	require.EqualError(t, multipleErrors.Errors[1], "parse error in `translation errors`, line 11, column 2: found name reused between multiple definitions and/or caveats: user")
+
+	var errWithContext ErrorWithContext
+	require.ErrorAs(t, err, &errWithContext)
+	require.Contains(t, errWithContext.BaseMessage, "invalid type for caveat parameter `someparam` on caveat `somecaveat`")
+
+	_, err = Compile(InputSchema{"single error", `definition user {}
+
+	definition user {}`}, AllowUnprefixedObjectType())
+	require.EqualError(t, err, "parse error in `single error`, line 3, column 2: found name reused between multiple definitions and/or caveats: user")
+}
@@ -55,32 +55,36 @@ func (nc *NodeChain) String() string {
 
 // PositionToAstNodeChain returns the AST node, and its parents (if any), found at the given position in the source, if any.
 func PositionToAstNodeChain(schema *CompiledSchema, source input.Source, position input.Position) (*NodeChain, error) {
-	rootSource, err := schema.rootNode.GetString(dslshape.NodePredicateSource)
-	if err != nil {
-		return nil, err
-	}
+	for _, rootNode := range schema.rootNodes {
+		rootSource, err := rootNode.GetString(dslshape.NodePredicateSource)
+		if err != nil {
+			return nil, err
+		}
 
-	if rootSource != string(source) {
-		return nil, nil
-	}
+		if rootSource != string(source) {
+			continue
+		}
 
-	// Map the position to a file rune.
-	runePosition, err := schema.mapper.LineAndColToRunePosition(position.LineNumber, position.ColumnPosition, source)
-	if err != nil {
-		return nil, err
-	}
+		// Map the position to a file rune.
+		runePosition, err := schema.mapper.LineAndColToRunePosition(position.LineNumber, position.ColumnPosition, source)
+		if err != nil {
+			return nil, err
+		}
 
-	// Find the node at the rune position.
-	found, err := runePositionToAstNodeChain(schema.rootNode, runePosition)
-	if err != nil {
-		return nil, err
-	}
+		// Find the node at the rune position.
+		found, err := runePositionToAstNodeChain(rootNode, runePosition)
+		if err != nil {
+			return nil, err
+		}
 
-	if found == nil {
-		return nil, nil
+		if found == nil {
+			return nil, nil
+		}
+
+		return &NodeChain{nodes: found, runePosition: runePosition}, nil
 	}
 
-	return &NodeChain{nodes: found, runePosition: runePosition}, nil
+	return nil, nil
 }
 
 func runePositionToAstNodeChain(node *dslNode, runePosition int) ([]DSLNode, error) {
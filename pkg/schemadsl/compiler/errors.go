@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
 )
@@ -51,3 +52,34 @@ func (ewc ErrorWithContext) DetailsMetadata() map[string]string {
 		"source_code":           ewc.ErrorSourceCode,
 	}
 }
+
+// MultipleErrors defines an error which holds all of the errors found when compiling a schema,
+// in the order in which they were found. It is only returned when more than one error was found.
+type MultipleErrors struct {
+	Errors []error
+}
+
+func (me MultipleErrors) Error() string {
+	messages := make([]string, 0, len(me.Errors))
+	for _, err := range me.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (me MultipleErrors) Unwrap() []error {
+	return me.Errors
+}
+
+// combineErrors returns nil if no errors are given, the error itself if only one is given and
+// a MultipleErrors otherwise.
+func combineErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return MultipleErrors{Errors: errs}
+	}
+}
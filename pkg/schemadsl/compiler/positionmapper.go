@@ -1,6 +1,7 @@
 package compiler
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
@@ -30,3 +31,39 @@ func (pm *positionMapper) TextForLine(lineNumber int, _ input.Source) (string, e
 	lines := strings.Split(pm.schema.SchemaString, "\n")
 	return lines[lineNumber], nil
 }
+
+// multiSourcePositionMapper is a position mapper over multiple input schemas, dispatching to the
+// position mapper for the source requested.
+type multiSourcePositionMapper map[input.Source]input.PositionMapper
+
+func (mpm multiSourcePositionMapper) mapperForSource(source input.Source) (input.PositionMapper, error) {
+	mapper, ok := mpm[source]
+	if !ok {
+		return nil, fmt.Errorf("unknown source `%s`", source)
+	}
+	return mapper, nil
+}
+
+func (mpm multiSourcePositionMapper) RunePositionToLineAndCol(runePosition int, source input.Source) (int, int, error) {
+	mapper, err := mpm.mapperForSource(source)
+	if err != nil {
+		return 0, 0, err
+	}
+	return mapper.RunePositionToLineAndCol(runePosition, source)
+}
+
+func (mpm multiSourcePositionMapper) LineAndColToRunePosition(lineNumber int, colPosition int, source input.Source) (int, error) {
+	mapper, err := mpm.mapperForSource(source)
+	if err != nil {
+		return 0, err
+	}
+	return mapper.LineAndColToRunePosition(lineNumber, colPosition, source)
+}
+
+func (mpm multiSourcePositionMapper) TextForLine(lineNumber int, source input.Source) (string, error) {
+	mapper, err := mpm.mapperForSource(source)
+	if err != nil {
+		return "", err
+	}
+	return mapper.TextForLine(lineNumber, source)
+}
@@ -49,6 +49,9 @@ func translate(tctx translationContext, root *dslNode) (*CompiledSchema, error)
 
 	names := mapz.NewSet[string]()
 
+	// Errors are collected per definition, so that all definitions with errors are reported
+	// rather than only the first.
+	var errs []error
 	for _, definitionNode := range root.GetChildren() {
 		var definition SchemaDefinition
 
@@ -56,7 +59,8 @@ func translate(tctx translationContext, root *dslNode) (*CompiledSchema, error)
 		case dslshape.NodeTypeCaveatDefinition:
 			def, err := translateCaveatDefinition(tctx, definitionNode)
 			if err != nil {
-				return nil, err
+				errs = append(errs, err)
+				continue
 			}
 
 			definition = def
@@ -65,7 +69,8 @@ func translate(tctx translationContext, root *dslNode) (*CompiledSchema, error)
 		case dslshape.NodeTypeDefinition:
 			def, err := translateObjectDefinition(tctx, definitionNode)
 			if err != nil {
-				return nil, err
+				errs = append(errs, err)
+				continue
 			}
 
 			definition = def
@@ -73,17 +78,22 @@ func translate(tctx translationContext, root *dslNode) (*CompiledSchema, error)
 		}
 
 		if !names.Add(definition.GetName()) {
-			return nil, definitionNode.ErrorWithSourcef(definition.GetName(), "found name reused between multiple definitions and/or caveats: %s", definition.GetName())
+			errs = append(errs, definitionNode.ErrorWithSourcef(definition.GetName(), "found name reused between multiple definitions and/or caveats: %s", definition.GetName()))
+			continue
 		}
 
 		orderedDefinitions = append(orderedDefinitions, definition)
 	}
 
+	if len(errs) > 0 {
+		return nil, combineErrors(errs)
+	}
+
 	return &CompiledSchema{
 		CaveatDefinitions:  caveatDefinitions,
 		ObjectDefinitions:  objectDefinitions,
 		OrderedDefinitions: orderedDefinitions,
-		rootNode:           root,
+		rootNodes:          []*dslNode{root},
 		mapper:             tctx.mapper,
 	}, nil
 }
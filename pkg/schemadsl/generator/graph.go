@@ -0,0 +1,333 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/graph"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// GraphFormat is a format in which a schema graph can be generated.
+type GraphFormat string
+
+const (
+	// GraphFormatDOT is the Graphviz DOT language.
+	GraphFormatDOT GraphFormat = "dot"
+
+	// GraphFormatMermaid is a Mermaid flowchart.
+	GraphFormatMermaid GraphFormat = "mermaid"
+)
+
+// GraphFormats are the supported graph formats.
+var GraphFormats = []GraphFormat{GraphFormatDOT, GraphFormatMermaid}
+
+// GenerateGraph generates a graph of the given object definitions in the given format.
+//
+// Each object type is a node, grouped with a node for each of its relations and permissions.
+// Relations have edges to their allowed subject types, and permissions have edges to the
+// relations and permissions they are computed from. An arrow is drawn as an edge to its
+// tupleset relation, labeled with the arrow, and as dashed edges to the relations or permissions
+// it walks on each allowed type of the tupleset.
+func GenerateGraph(definitions []*core.NamespaceDefinition, format GraphFormat) (string, error) {
+	g, err := buildSchemaGraph(definitions)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case GraphFormatDOT:
+		return g.dot(), nil
+	case GraphFormatMermaid:
+		return g.mermaid(), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q", format)
+	}
+}
+
+type graphNodeKind int
+
+const (
+	graphNodeType graphNodeKind = iota
+	graphNodeRelation
+	graphNodePermission
+)
+
+type graphNode struct {
+	id         string
+	name       string
+	label      string
+	kind       graphNodeKind
+	definition string
+}
+
+type graphEdge struct {
+	from   string
+	to     string
+	label  string
+	dashed bool
+}
+
+type schemaGraph struct {
+	definitions []string
+	nodes       []*graphNode
+	nodesByName map[string]*graphNode
+	edges       []graphEdge
+	seenEdges   map[graphEdge]struct{}
+}
+
+func buildSchemaGraph(definitions []*core.NamespaceDefinition) (*schemaGraph, error) {
+	g := &schemaGraph{
+		nodesByName: make(map[string]*graphNode),
+		seenEdges:   make(map[graphEdge]struct{}),
+	}
+
+	relationsByDefinition := make(map[string]map[string]*core.Relation, len(definitions))
+	for _, def := range definitions {
+		g.definitions = append(g.definitions, def.Name)
+		g.node(def.Name, def.Name, graphNodeType, def.Name)
+
+		relations := make(map[string]*core.Relation, len(def.Relation))
+		for _, relation := range def.Relation {
+			relations[relation.Name] = relation
+
+			hasThis, err := graph.HasThis(relation.UsersetRewrite)
+			if err != nil {
+				return nil, err
+			}
+
+			kind := graphNodeRelation
+			if relation.UsersetRewrite != nil && !hasThis {
+				kind = graphNodePermission
+			}
+			g.node(relationNodeName(def.Name, relation.Name), relation.Name, kind, def.Name)
+		}
+		relationsByDefinition[def.Name] = relations
+	}
+
+	for _, def := range definitions {
+		for _, relation := range def.Relation {
+			from := relationNodeName(def.Name, relation.Name)
+
+			for _, allowed := range relation.GetTypeInformation().GetAllowedDirectRelations() {
+				to := allowed.Namespace
+				var labels []string
+				if allowed.GetPublicWildcard() != nil {
+					labels = append(labels, "*")
+				} else if allowed.GetRelation() != "" && allowed.GetRelation() != Ellipsis {
+					to = relationNodeName(allowed.Namespace, allowed.GetRelation())
+				}
+				if allowed.GetRequiredCaveat() != nil {
+					labels = append(labels, "with "+allowed.RequiredCaveat.CaveatName)
+				}
+				g.edge(from, to, strings.Join(labels, " "), false)
+			}
+
+			if relation.UsersetRewrite != nil {
+				g.addRewriteEdges(def.Name, from, relation.UsersetRewrite, "", relationsByDefinition)
+			}
+		}
+	}
+
+	return g, nil
+}
+
+func relationNodeName(definition, relation string) string {
+	return definition + "#" + relation
+}
+
+// node returns the node with the given name, adding it if it does not exist. Nodes referenced
+// by edges but not defined in the schema have no definition.
+func (g *schemaGraph) node(name, label string, kind graphNodeKind, definition string) *graphNode {
+	if node, ok := g.nodesByName[name]; ok {
+		return node
+	}
+
+	node := &graphNode{
+		id:         "n" + strconv.Itoa(len(g.nodes)),
+		name:       name,
+		label:      label,
+		kind:       kind,
+		definition: definition,
+	}
+	g.nodes = append(g.nodes, node)
+	g.nodesByName[name] = node
+	return node
+}
+
+func (g *schemaGraph) edge(from, to, label string, dashed bool) {
+	if _, ok := g.nodesByName[to]; !ok {
+		g.node(to, to, graphNodeType, "")
+	}
+
+	edge := graphEdge{from: from, to: to, label: label, dashed: dashed}
+	if _, ok := g.seenEdges[edge]; ok {
+		return
+	}
+	g.seenEdges[edge] = struct{}{}
+	g.edges = append(g.edges, edge)
+}
+
+// addRewriteEdges adds the edges of a permission to the relations its rewrite is computed from.
+// Edges are labeled with the operation applied to them, if any: & for intersections and - for
+// exclusions. Operations nested within another inherit the label of the operation containing them.
+func (g *schemaGraph) addRewriteEdges(definition, from string, rewrite *core.UsersetRewrite, inheritedLabel string, relationsByDefinition map[string]map[string]*core.Relation) {
+	var setOp *core.SetOperation
+	operationLabel := func(int) string { return "" }
+	switch rw := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		setOp = rw.Union
+	case *core.UsersetRewrite_Intersection:
+		setOp = rw.Intersection
+		operationLabel = func(int) string { return "&" }
+	case *core.UsersetRewrite_Exclusion:
+		setOp = rw.Exclusion
+		operationLabel = func(index int) string {
+			if index == 0 {
+				return ""
+			}
+			return "-"
+		}
+	default:
+		return
+	}
+
+	label := func(index int) string {
+		if inheritedLabel != "" {
+			return inheritedLabel
+		}
+		return operationLabel(index)
+	}
+
+	for index, setOpChild := range setOp.Child {
+		switch child := setOpChild.ChildType.(type) {
+		case *core.SetOperation_Child_UsersetRewrite:
+			g.addRewriteEdges(definition, from, child.UsersetRewrite, label(index), relationsByDefinition)
+
+		case *core.SetOperation_Child_ComputedUserset:
+			g.edge(from, relationNodeName(definition, child.ComputedUserset.Relation), label(index), false)
+
+		case *core.SetOperation_Child_TupleToUserset:
+			tupleset := child.TupleToUserset.Tupleset.Relation
+			computed := child.TupleToUserset.ComputedUserset.Relation
+			arrowLabel := strings.TrimSpace(label(index) + " ->" + computed)
+			g.edge(from, relationNodeName(definition, tupleset), arrowLabel, false)
+
+			tuplesetRelation, ok := relationsByDefinition[definition][tupleset]
+			if !ok {
+				continue
+			}
+			for _, allowed := range tuplesetRelation.GetTypeInformation().GetAllowedDirectRelations() {
+				if _, ok := relationsByDefinition[allowed.Namespace][computed]; ok {
+					g.edge(from, relationNodeName(allowed.Namespace, computed), label(index), true)
+				}
+			}
+		}
+	}
+}
+
+func (g *schemaGraph) nodesOfDefinition(definition string) []*graphNode {
+	var nodes []*graphNode
+	for _, node := range g.nodes {
+		if node.definition == definition {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func (g *schemaGraph) dot() string {
+	var sb strings.Builder
+	sb.WriteString("digraph schema {\n")
+	sb.WriteString("\trankdir=LR\n")
+
+	writeNode := func(indent string, node *graphNode) {
+		attributes := fmt.Sprintf("label=%s", strconv.Quote(node.label))
+		switch node.kind {
+		case graphNodeType:
+			attributes += " shape=box style=bold"
+		case graphNodeRelation:
+			attributes += " shape=ellipse"
+		case graphNodePermission:
+			attributes += " shape=hexagon"
+		}
+		fmt.Fprintf(&sb, "%s%s [%s]\n", indent, strconv.Quote(node.name), attributes)
+	}
+
+	for _, definition := range g.definitions {
+		fmt.Fprintf(&sb, "\tsubgraph %s {\n", strconv.Quote("cluster_"+definition))
+		for _, node := range g.nodesOfDefinition(definition) {
+			writeNode("\t\t", node)
+		}
+		sb.WriteString("\t}\n")
+	}
+	for _, node := range g.nodesOfDefinition("") {
+		writeNode("\t", node)
+	}
+
+	for _, edge := range g.edges {
+		var attributes []string
+		if edge.label != "" {
+			attributes = append(attributes, "label="+strconv.Quote(edge.label))
+		}
+		if edge.dashed {
+			attributes = append(attributes, "style=dashed")
+		}
+
+		fmt.Fprintf(&sb, "\t%s -> %s", strconv.Quote(edge.from), strconv.Quote(edge.to))
+		if len(attributes) > 0 {
+			fmt.Fprintf(&sb, " [%s]", strings.Join(attributes, " "))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (g *schemaGraph) mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	writeNode := func(indent string, node *graphNode) {
+		label := mermaidQuote(node.label)
+		switch node.kind {
+		case graphNodeType:
+			fmt.Fprintf(&sb, "%s%s[%s]\n", indent, node.id, label)
+		case graphNodeRelation:
+			fmt.Fprintf(&sb, "%s%s(%s)\n", indent, node.id, label)
+		case graphNodePermission:
+			fmt.Fprintf(&sb, "%s%s{{%s}}\n", indent, node.id, label)
+		}
+	}
+
+	for index, definition := range g.definitions {
+		fmt.Fprintf(&sb, "\tsubgraph d%d [%s]\n", index, mermaidQuote(definition))
+		for _, node := range g.nodesOfDefinition(definition) {
+			writeNode("\t\t", node)
+		}
+		sb.WriteString("\tend\n")
+	}
+	for _, node := range g.nodesOfDefinition("") {
+		writeNode("\t", node)
+	}
+
+	for _, edge := range g.edges {
+		arrow := "-->"
+		if edge.dashed {
+			arrow = "-.->"
+		}
+		if edge.label != "" {
+			arrow += "|" + mermaidQuote(edge.label) + "|"
+		}
+		fmt.Fprintf(&sb, "\t%s %s %s\n", g.nodesByName[edge.from].id, arrow, g.nodesByName[edge.to].id)
+	}
+
+	return sb.String()
+}
+
+// mermaidQuote quotes a label for Mermaid, which does not support escaping quotes within them.
+func mermaidQuote(label string) string {
+	return `"` + strings.ReplaceAll(label, `"`, "#quot;") + `"`
+}
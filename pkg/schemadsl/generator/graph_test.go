@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+const graphTestSchema = `definition user {}
+
+definition folder {
+	relation parent: folder
+	relation viewer: user | user:*
+	permission view = viewer + parent->view
+}
+
+definition document {
+	relation parent: folder
+	relation banned: user
+	relation viewer: user | group#member
+	permission view = (viewer + parent->view) - banned
+}`
+
+func TestGenerateGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   GraphFormat
+		expected string
+	}{
+		{
+			"dot",
+			GraphFormatDOT,
+			`digraph schema {
+	rankdir=LR
+	subgraph "cluster_user" {
+		"user" [label="user" shape=box style=bold]
+	}
+	subgraph "cluster_folder" {
+		"folder" [label="folder" shape=box style=bold]
+		"folder#parent" [label="parent" shape=ellipse]
+		"folder#viewer" [label="viewer" shape=ellipse]
+		"folder#view" [label="view" shape=hexagon]
+	}
+	subgraph "cluster_document" {
+		"document" [label="document" shape=box style=bold]
+		"document#parent" [label="parent" shape=ellipse]
+		"document#banned" [label="banned" shape=ellipse]
+		"document#viewer" [label="viewer" shape=ellipse]
+		"document#view" [label="view" shape=hexagon]
+	}
+	"group#member" [label="group#member" shape=box style=bold]
+	"folder#parent" -> "folder"
+	"folder#viewer" -> "user"
+	"folder#viewer" -> "user" [label="*"]
+	"folder#view" -> "folder#viewer"
+	"folder#view" -> "folder#parent" [label="->view"]
+	"folder#view" -> "folder#view" [style=dashed]
+	"document#parent" -> "folder"
+	"document#banned" -> "user"
+	"document#viewer" -> "user"
+	"document#viewer" -> "group#member"
+	"document#view" -> "document#viewer"
+	"document#view" -> "document#parent" [label="->view"]
+	"document#view" -> "folder#view" [style=dashed]
+	"document#view" -> "document#banned" [label="-"]
+}
+`,
+		},
+		{
+			"mermaid",
+			GraphFormatMermaid,
+			`flowchart LR
+	subgraph d0 ["user"]
+		n0["user"]
+	end
+	subgraph d1 ["folder"]
+		n1["folder"]
+		n2("parent")
+		n3("viewer")
+		n4{{"view"}}
+	end
+	subgraph d2 ["document"]
+		n5["document"]
+		n6("parent")
+		n7("banned")
+		n8("viewer")
+		n9{{"view"}}
+	end
+	n10["group#member"]
+	n2 --> n1
+	n3 --> n0
+	n3 -->|"*"| n0
+	n4 --> n3
+	n4 -->|"->view"| n2
+	n4 -.-> n4
+	n6 --> n1
+	n7 --> n0
+	n8 --> n0
+	n8 --> n10
+	n9 --> n8
+	n9 -->|"->view"| n6
+	n9 -.-> n4
+	n9 -->|"-"| n7
+`,
+		},
+	}
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("graph"),
+		SchemaString: graphTestSchema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			generated, err := GenerateGraph(compiled.ObjectDefinitions, test.format)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, generated)
+		})
+	}
+
+	_, err = GenerateGraph(compiled.ObjectDefinitions, "svg")
+	require.ErrorContains(t, err, `unknown graph format "svg"`)
+}
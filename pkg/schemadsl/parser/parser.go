@@ -64,13 +64,34 @@ Loop:
 
 		default:
 			p.emitErrorf("Unexpected token at root level: %v", p.currentToken.Kind)
-			break Loop
+
+			// Skip to the next definition or caveat and continue parsing, so that the errors
+			// found within it are reported as well.
+			if !p.skipToNextTopLevelDefinition() {
+				break Loop
+			}
 		}
 	}
 
 	return rootNode
 }
 
+// skipToNextTopLevelDefinition skips tokens until the start of the next definition or caveat,
+// returning false if the end of the input or a lexer error was reached instead.
+func (p *sourceParser) skipToNextTopLevelDefinition() bool {
+	for {
+		if p.isToken(lexer.TokenTypeEOF, lexer.TokenTypeError) {
+			return false
+		}
+
+		if p.isKeyword("definition") || p.isKeyword("caveat") {
+			return true
+		}
+
+		p.consumeToken()
+	}
+}
+
 // consumeCaveat attempts to consume a single caveat definition.
 // ```caveat somecaveat(param1 type, param2 type) { ... }```
 func (p *sourceParser) consumeCaveat() AstNode {
@@ -0,0 +1,605 @@
+package schemautil
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	"github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// DefaultRenameBatchSize is the default number of relationships rewritten per transaction when
+// renaming a definition or relation.
+const DefaultRenameBatchSize uint64 = 1000
+
+// RenamePhase is a phase of a rename operation.
+type RenamePhase string
+
+const (
+	// RenamePhaseExpand is the phase in which the new name is added to the schema alongside the
+	// old one, with every reference to the old name also referencing the new one.
+	RenamePhaseExpand RenamePhase = "expand"
+
+	// RenamePhaseMigrate is the phase in which relationships are rewritten from the old name to
+	// the new one.
+	RenamePhaseMigrate RenamePhase = "migrate"
+
+	// RenamePhaseContract is the phase in which the old name is removed from the schema.
+	RenamePhaseContract RenamePhase = "contract"
+)
+
+// RenameProgress reports the progress of a rename operation.
+type RenameProgress struct {
+	// Phase is the phase being executed.
+	Phase RenamePhase
+
+	// RelationshipsMigrated is the number of relationships rewritten so far.
+	RelationshipsMigrated uint64
+}
+
+// RenameOptions are the options for a rename operation.
+type RenameOptions struct {
+	// BatchSize is the maximum number of relationships rewritten per transaction. If zero,
+	// DefaultRenameBatchSize is used.
+	BatchSize uint64
+
+	// OnProgress, if not nil, is invoked at the start of every phase and after every batch of
+	// relationships has been rewritten.
+	OnProgress func(RenameProgress)
+}
+
+// RenameObjectDefinition renames the object definition with the given name, rewriting every
+// relationship whose resource or subject is of that type.
+//
+// The rename runs in three phases, so that the schema is consistent with the stored
+// relationships throughout: the new definition is first added as a copy of the old one and
+// allowed wherever the old one is, then relationships are rewritten in batched transactions,
+// and finally the old definition is removed. Relationships referencing the old definition
+// should not be written while the rename is in progress, as the final phase fails if any remain.
+// The final phase also fails, leaving the expanded schema in place, if the schema was changed
+// after the first phase.
+func RenameObjectDefinition(ctx context.Context, ds datastore.Datastore, oldName string, newName string, opts RenameOptions) error {
+	return runRename(ctx, ds, definitionRenamer{oldName: oldName, newName: newName}, opts)
+}
+
+// RenameRelation renames the relation or permission with the given name on the object definition,
+// rewriting every relationship that references it, either as the resource relation or as the
+// relation of a subject set. Permissions and arrows referencing it are updated to use the new
+// name. The rename runs in the same phases as RenameObjectDefinition.
+func RenameRelation(ctx context.Context, ds datastore.Datastore, definitionName string, oldName string, newName string, opts RenameOptions) error {
+	return runRename(ctx, ds, relationRenamer{definitionName: definitionName, oldName: oldName, newName: newName}, opts)
+}
+
+// renamer defines the schema and relationship changes made for a rename.
+type renamer interface {
+	// expanded returns the definitions with the new name added alongside the old one.
+	expanded(defs []*core.NamespaceDefinition) ([]*core.NamespaceDefinition, error)
+
+	// renamed returns the definitions with the old name replaced by the new one. It is given the
+	// definitions as they were before the expand phase.
+	renamed(defs []*core.NamespaceDefinition) ([]*core.NamespaceDefinition, error)
+
+	// resourceFilter matches the relationships whose resource must be rewritten.
+	resourceFilter() datastore.RelationshipsFilter
+
+	// subjectsFilter matches the relationships whose subject must be rewritten.
+	subjectsFilter() datastore.SubjectsFilter
+
+	// rewriteResource rewrites the resource of the relationship in place.
+	rewriteResource(rel *core.RelationTuple)
+
+	// rewriteSubject rewrites the subject of the relationship in place.
+	rewriteSubject(rel *core.RelationTuple)
+}
+
+func runRename(ctx context.Context, ds datastore.Datastore, r renamer, opts RenameOptions) error {
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultRenameBatchSize
+	}
+
+	progress := RenameProgress{Phase: RenamePhaseExpand}
+	report := func() {
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+	}
+
+	// The definitions read when expanding are kept, as the contracted schema is computed from the
+	// schema as it was before the rename.
+	var original []*core.NamespaceDefinition
+	var expandedNames []string
+	report()
+	expandRevision, err := writeDefinitions(ctx, ds, func(defs []datastore.RevisionedNamespace) ([]*core.NamespaceDefinition, error) {
+		original = datastore.DefinitionsOf(defs)
+		expanded, err := r.expanded(original)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, def := range expanded {
+			expandedNames = append(expandedNames, def.Name)
+		}
+		return expanded, nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add new name to schema: %w", err)
+	}
+
+	progress.Phase = RenamePhaseMigrate
+	report()
+	for {
+		count, err := migrateBatch(ctx, ds, r, opts.BatchSize)
+		if err != nil {
+			return fmt.Errorf("unable to rewrite relationships: %w", err)
+		}
+
+		if count == 0 {
+			break
+		}
+
+		progress.RelationshipsMigrated += count
+		report()
+	}
+
+	progress.Phase = RenamePhaseContract
+	report()
+	if _, err := writeDefinitions(ctx, ds, func(defs []datastore.RevisionedNamespace) ([]*core.NamespaceDefinition, error) {
+		// The contracted schema is computed from the schema read when expanding, so writing it
+		// would revert any schema change made since.
+		if err := ensureSchemaUnchanged(defs, expandRevision, expandedNames); err != nil {
+			return nil, err
+		}
+		return r.renamed(original)
+	}); err != nil {
+		return fmt.Errorf("unable to remove old name from schema: %w", err)
+	}
+
+	log.Ctx(ctx).Info().Uint64("relationships", progress.RelationshipsMigrated).Msg("completed rename")
+	return nil
+}
+
+// writeDefinitions replaces the object definitions in the datastore with those returned by the
+// update function, validating them in the same way as a schema write. It returns the revision at
+// which the definitions were written.
+func writeDefinitions(ctx context.Context, ds datastore.Datastore, update func([]datastore.RevisionedNamespace) ([]*core.NamespaceDefinition, error)) (datastore.Revision, error) {
+	return ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		existingObjectDefs, err := rwt.ListAllNamespaces(ctx)
+		if err != nil {
+			return err
+		}
+
+		existingCaveats, err := rwt.ListAllCaveats(ctx)
+		if err != nil {
+			return err
+		}
+
+		updated, err := update(existingObjectDefs)
+		if err != nil {
+			return err
+		}
+
+		for _, def := range updated {
+			if err := def.Validate(); err != nil {
+				return fmt.Errorf("error in object definition %s: %w", def.Name, err)
+			}
+		}
+
+		validated, err := shared.ValidateSchemaChanges(ctx, &compiler.CompiledSchema{
+			ObjectDefinitions: updated,
+			CaveatDefinitions: datastore.DefinitionsOf(existingCaveats),
+		}, false)
+		if err != nil {
+			return err
+		}
+
+		_, err = shared.ApplySchemaChanges(ctx, rwt, validated)
+		return err
+	})
+}
+
+// ensureSchemaUnchanged returns an error if any object definition was written after the expand
+// revision, or if the set of object definitions differs from the expanded one.
+func ensureSchemaUnchanged(defs []datastore.RevisionedNamespace, expandRevision datastore.Revision, expandedNames []string) error {
+	names := mapz.NewSet[string]()
+	for _, def := range defs {
+		if def.LastWrittenRevision.GreaterThan(expandRevision) {
+			return fmt.Errorf("object definition `%s` was changed while relationships were being rewritten", def.Definition.Name)
+		}
+		names.Add(def.Definition.Name)
+	}
+
+	if !names.Equal(mapz.NewSet(expandedNames...)) {
+		return fmt.Errorf("object definitions were added or removed while relationships were being rewritten")
+	}
+	return nil
+}
+
+// migrateBatch rewrites up to batchSize relationships in a single transaction, returning the
+// number rewritten. Resources are rewritten before subjects, so relationships referencing the old
+// name on both sides are fully rewritten after two batches.
+func migrateBatch(ctx context.Context, ds datastore.Datastore, r renamer, batchSize uint64) (uint64, error) {
+	var count uint64
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		rels, err := collectRelationships(rwt.QueryRelationships(ctx, r.resourceFilter(), options.WithLimit(&batchSize)))
+		if err != nil {
+			return err
+		}
+
+		rewrite := r.rewriteResource
+		if len(rels) == 0 {
+			rels, err = collectRelationships(rwt.ReverseQueryRelationships(ctx, r.subjectsFilter(), options.WithLimitForReverse(&batchSize)))
+			if err != nil {
+				return err
+			}
+			rewrite = r.rewriteSubject
+		}
+
+		mutations := make([]*core.RelationTupleUpdate, 0, len(rels)*2)
+		for _, rel := range rels {
+			updated := rel.CloneVT()
+			rewrite(updated)
+			mutations = append(mutations, tuple.Delete(rel), tuple.Touch(updated))
+		}
+
+		count = uint64(len(rels))
+		return rwt.WriteRelationships(ctx, mutations)
+	})
+	return count, err
+}
+
+func collectRelationships(it datastore.RelationshipIterator, err error) ([]*core.RelationTuple, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var rels []*core.RelationTuple
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		rels = append(rels, rel)
+	}
+	return rels, it.Err()
+}
+
+type definitionRenamer struct {
+	oldName string
+	newName string
+}
+
+func (dr definitionRenamer) expanded(defs []*core.NamespaceDefinition) ([]*core.NamespaceDefinition, error) {
+	existing, err := findRenamedDefinition(defs, dr.oldName, dr.newName)
+	if err != nil {
+		return nil, err
+	}
+
+	added := existing.CloneVT()
+	added.Name = dr.newName
+
+	updated := cloneDefinitions(append(defs, added))
+	for _, def := range updated {
+		for _, relation := range def.Relation {
+			transformAllowedRelations(relation, func(allowed *core.AllowedRelation) []*core.AllowedRelation {
+				if allowed.Namespace != dr.oldName {
+					return []*core.AllowedRelation{allowed}
+				}
+
+				renamed := allowed.CloneVT()
+				renamed.Namespace = dr.newName
+				return []*core.AllowedRelation{allowed, renamed}
+			})
+		}
+	}
+	return updated, nil
+}
+
+func (dr definitionRenamer) renamed(defs []*core.NamespaceDefinition) ([]*core.NamespaceDefinition, error) {
+	if _, err := findRenamedDefinition(defs, dr.oldName, dr.newName); err != nil {
+		return nil, err
+	}
+
+	updated := cloneDefinitions(defs)
+	for _, def := range updated {
+		if def.Name == dr.oldName {
+			def.Name = dr.newName
+		}
+
+		for _, relation := range def.Relation {
+			transformAllowedRelations(relation, func(allowed *core.AllowedRelation) []*core.AllowedRelation {
+				if allowed.Namespace == dr.oldName {
+					allowed.Namespace = dr.newName
+				}
+				return []*core.AllowedRelation{allowed}
+			})
+		}
+	}
+	return updated, nil
+}
+
+func (dr definitionRenamer) resourceFilter() datastore.RelationshipsFilter {
+	return datastore.RelationshipsFilter{OptionalResourceType: dr.oldName}
+}
+
+func (dr definitionRenamer) subjectsFilter() datastore.SubjectsFilter {
+	return datastore.SubjectsFilter{SubjectType: dr.oldName}
+}
+
+func (dr definitionRenamer) rewriteResource(rel *core.RelationTuple) {
+	rel.ResourceAndRelation.Namespace = dr.newName
+}
+
+func (dr definitionRenamer) rewriteSubject(rel *core.RelationTuple) {
+	rel.Subject.Namespace = dr.newName
+}
+
+type relationRenamer struct {
+	definitionName string
+	oldName        string
+	newName        string
+}
+
+func (rr relationRenamer) expanded(defs []*core.NamespaceDefinition) ([]*core.NamespaceDefinition, error) {
+	if _, err := rr.renamed(defs); err != nil {
+		return nil, err
+	}
+
+	updated := cloneDefinitions(defs)
+	relations := relationsByDefinition(updated)
+	for _, def := range updated {
+		if def.Name == rr.definitionName {
+			added := relations[def.Name][rr.oldName].CloneVT()
+			added.Name = rr.newName
+			def.Relation = append(def.Relation, added)
+		}
+	}
+
+	// Every reference to the old relation becomes a union of the old and new relations, so that
+	// permissions have the same members while relationships are being rewritten.
+	for _, def := range updated {
+		for _, relation := range def.Relation {
+			transformAllowedRelations(relation, func(allowed *core.AllowedRelation) []*core.AllowedRelation {
+				if allowed.Namespace != rr.definitionName || allowed.GetRelation() != rr.oldName {
+					return []*core.AllowedRelation{allowed}
+				}
+
+				renamed := allowed.CloneVT()
+				renamed.RelationOrWildcard = &core.AllowedRelation_Relation{Relation: rr.newName}
+				return []*core.AllowedRelation{allowed, renamed}
+			})
+
+			transformRewrite(relation.UsersetRewrite, func(child *core.SetOperation_Child) *core.SetOperation_Child {
+				switch typed := child.ChildType.(type) {
+				case *core.SetOperation_Child_ComputedUserset:
+					if def.Name != rr.definitionName || typed.ComputedUserset.Relation != rr.oldName {
+						return child
+					}
+					return namespace.Rewrite(namespace.Union(child, namespace.ComputedUserset(rr.newName)))
+
+				case *core.SetOperation_Child_TupleToUserset:
+					tuplesets := []string{typed.TupleToUserset.Tupleset.Relation}
+					if def.Name == rr.definitionName && typed.TupleToUserset.Tupleset.Relation == rr.oldName {
+						tuplesets = append(tuplesets, rr.newName)
+					}
+
+					targets := []string{typed.TupleToUserset.ComputedUserset.Relation}
+					if rr.arrowWalksToRenamed(relations[def.Name], typed.TupleToUserset) {
+						targets = append(targets, rr.newName)
+					}
+
+					if len(tuplesets) == 1 && len(targets) == 1 {
+						return child
+					}
+
+					var arrows []*core.SetOperation_Child
+					for _, tupleset := range tuplesets {
+						for _, target := range targets {
+							arrows = append(arrows, namespace.TupleToUserset(tupleset, target))
+						}
+					}
+					return namespace.Rewrite(namespace.Union(arrows[0], arrows[1:]...))
+
+				default:
+					return child
+				}
+			})
+		}
+	}
+	return updated, nil
+}
+
+func (rr relationRenamer) renamed(defs []*core.NamespaceDefinition) ([]*core.NamespaceDefinition, error) {
+	updated := cloneDefinitions(defs)
+	relations := relationsByDefinition(updated)
+
+	definition, ok := relations[rr.definitionName]
+	if !ok {
+		return nil, fmt.Errorf("object definition `%s` not found", rr.definitionName)
+	}
+
+	if _, ok := definition[rr.oldName]; !ok {
+		return nil, fmt.Errorf("relation/permission `%s` not found under object definition `%s`", rr.oldName, rr.definitionName)
+	}
+
+	if _, ok := definition[rr.newName]; ok {
+		return nil, fmt.Errorf("relation/permission `%s` already exists under object definition `%s`", rr.newName, rr.definitionName)
+	}
+
+	var err error
+	for _, def := range updated {
+		for _, relation := range def.Relation {
+			if def.Name == rr.definitionName && relation.Name == rr.oldName {
+				relation.Name = rr.newName
+			}
+
+			transformAllowedRelations(relation, func(allowed *core.AllowedRelation) []*core.AllowedRelation {
+				if allowed.Namespace == rr.definitionName && allowed.GetRelation() == rr.oldName {
+					allowed.RelationOrWildcard = &core.AllowedRelation_Relation{Relation: rr.newName}
+				}
+				return []*core.AllowedRelation{allowed}
+			})
+
+			transformRewrite(relation.UsersetRewrite, func(child *core.SetOperation_Child) *core.SetOperation_Child {
+				switch typed := child.ChildType.(type) {
+				case *core.SetOperation_Child_ComputedUserset:
+					if def.Name == rr.definitionName && typed.ComputedUserset.Relation == rr.oldName {
+						typed.ComputedUserset.Relation = rr.newName
+					}
+
+				case *core.SetOperation_Child_TupleToUserset:
+					walksToRenamed := rr.arrowWalksToRenamed(relations[def.Name], typed.TupleToUserset)
+					if walksToRenamed && rr.arrowWalksToOthers(relations, relations[def.Name], typed.TupleToUserset) {
+						err = fmt.Errorf("cannot rename relation/permission `%s`, as arrow `%s->%s` under `%s#%s` also walks to it on other object definitions",
+							rr.oldName, typed.TupleToUserset.Tupleset.Relation, typed.TupleToUserset.ComputedUserset.Relation, def.Name, relation.Name)
+					}
+
+					if def.Name == rr.definitionName && typed.TupleToUserset.Tupleset.Relation == rr.oldName {
+						typed.TupleToUserset.Tupleset.Relation = rr.newName
+					}
+
+					if walksToRenamed {
+						typed.TupleToUserset.ComputedUserset.Relation = rr.newName
+					}
+				}
+				return child
+			})
+		}
+	}
+	return updated, err
+}
+
+// arrowWalksToRenamed returns true if the arrow walks to the relation being renamed.
+func (rr relationRenamer) arrowWalksToRenamed(relations map[string]*core.Relation, ttu *core.TupleToUserset) bool {
+	if ttu.ComputedUserset.Relation != rr.oldName {
+		return false
+	}
+
+	for _, allowed := range relations[ttu.Tupleset.Relation].GetTypeInformation().GetAllowedDirectRelations() {
+		if allowed.Namespace == rr.definitionName {
+			return true
+		}
+	}
+	return false
+}
+
+// arrowWalksToOthers returns true if the arrow also walks to a relation with the old name on
+// an object definition other than the one being changed.
+func (rr relationRenamer) arrowWalksToOthers(relationsByDef map[string]map[string]*core.Relation, relations map[string]*core.Relation, ttu *core.TupleToUserset) bool {
+	for _, allowed := range relations[ttu.Tupleset.Relation].GetTypeInformation().GetAllowedDirectRelations() {
+		if allowed.Namespace == rr.definitionName {
+			continue
+		}
+
+		if _, ok := relationsByDef[allowed.Namespace][rr.oldName]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (rr relationRenamer) resourceFilter() datastore.RelationshipsFilter {
+	return datastore.RelationshipsFilter{
+		OptionalResourceType:     rr.definitionName,
+		OptionalResourceRelation: rr.oldName,
+	}
+}
+
+func (rr relationRenamer) subjectsFilter() datastore.SubjectsFilter {
+	return datastore.SubjectsFilter{
+		SubjectType: rr.definitionName,
+		RelationFilter: datastore.SubjectRelationFilter{
+			NonEllipsisRelation: rr.oldName,
+		},
+	}
+}
+
+func (rr relationRenamer) rewriteResource(rel *core.RelationTuple) {
+	rel.ResourceAndRelation.Relation = rr.newName
+}
+
+func (rr relationRenamer) rewriteSubject(rel *core.RelationTuple) {
+	rel.Subject.Relation = rr.newName
+}
+
+func findRenamedDefinition(defs []*core.NamespaceDefinition, oldName string, newName string) (*core.NamespaceDefinition, error) {
+	var found *core.NamespaceDefinition
+	for _, def := range defs {
+		switch def.Name {
+		case oldName:
+			found = def
+		case newName:
+			return nil, fmt.Errorf("object definition `%s` already exists", newName)
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("object definition `%s` not found", oldName)
+	}
+	return found, nil
+}
+
+// cloneDefinitions clones the definitions, clearing the aliasing and cache key annotations, as
+// they are recomputed for the changed definitions when written.
+func cloneDefinitions(defs []*core.NamespaceDefinition) []*core.NamespaceDefinition {
+	cloned := make([]*core.NamespaceDefinition, 0, len(defs))
+	for _, def := range defs {
+		clone := def.CloneVT()
+		for _, relation := range clone.Relation {
+			relation.AliasingRelation = ""
+			relation.CanonicalCacheKey = ""
+		}
+		cloned = append(cloned, clone)
+	}
+	return cloned
+}
+
+func relationsByDefinition(defs []*core.NamespaceDefinition) map[string]map[string]*core.Relation {
+	relations := make(map[string]map[string]*core.Relation, len(defs))
+	for _, def := range defs {
+		relations[def.Name] = make(map[string]*core.Relation, len(def.Relation))
+		for _, relation := range def.Relation {
+			relations[def.Name][relation.Name] = relation
+		}
+	}
+	return relations
+}
+
+// transformAllowedRelations replaces each allowed relation of the relation with the allowed
+// relations returned by the transform function.
+func transformAllowedRelations(relation *core.Relation, transform func(*core.AllowedRelation) []*core.AllowedRelation) {
+	if relation.TypeInformation == nil {
+		return
+	}
+
+	var updated []*core.AllowedRelation
+	for _, allowed := range relation.TypeInformation.AllowedDirectRelations {
+		updated = append(updated, transform(allowed)...)
+	}
+	relation.TypeInformation.AllowedDirectRelations = updated
+}
+
+// transformRewrite replaces each leaf child of the rewrite with the child returned by the
+// transform function.
+func transformRewrite(rewrite *core.UsersetRewrite, transform func(*core.SetOperation_Child) *core.SetOperation_Child) {
+	var children []*core.SetOperation_Child
+	switch rw := rewrite.GetRewriteOperation().(type) {
+	case *core.UsersetRewrite_Union:
+		children = rw.Union.Child
+	case *core.UsersetRewrite_Intersection:
+		children = rw.Intersection.Child
+	case *core.UsersetRewrite_Exclusion:
+		children = rw.Exclusion.Child
+	}
+
+	for index, child := range children {
+		if nested := child.GetUsersetRewrite(); nested != nil {
+			transformRewrite(nested, transform)
+			continue
+		}
+		children[index] = transform(child)
+	}
+}
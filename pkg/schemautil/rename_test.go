@@ -0,0 +1,265 @@
+package schemautil
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	ns "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const renameTestSchema = `definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition document {
+	relation parent: document
+	relation reader: user | group#member
+	permission view = reader + parent->view
+}`
+
+var renameTestRelationships = []string{
+	"document:first#parent@document:root",
+	"document:first#reader@user:tom",
+	"document:root#reader@group:admins#member",
+	"group:admins#member@group:owners#member",
+	"group:owners#member@user:sarah",
+}
+
+func TestRename(t *testing.T) {
+	tcs := []struct {
+		name                  string
+		rename                func(context.Context, datastore.Datastore, RenameOptions) error
+		expectedSchema        string
+		expectedRelationships []string
+		expectedError         string
+	}{
+		{
+			"rename definition",
+			func(ctx context.Context, ds datastore.Datastore, opts RenameOptions) error {
+				return RenameObjectDefinition(ctx, ds, "group", "team", opts)
+			},
+			`definition document {
+	relation parent: document
+	relation reader: user | team#member
+	permission view = reader + parent->view
+}
+
+definition team {
+	relation member: user | team#member
+}
+
+definition user {}`,
+			[]string{
+				"document:first#parent@document:root",
+				"document:first#reader@user:tom",
+				"document:root#reader@team:admins#member",
+				"team:admins#member@team:owners#member",
+				"team:owners#member@user:sarah",
+			},
+			"",
+		},
+		{
+			"rename relation",
+			func(ctx context.Context, ds datastore.Datastore, opts RenameOptions) error {
+				return RenameRelation(ctx, ds, "group", "member", "participant", opts)
+			},
+			`definition document {
+	relation parent: document
+	relation reader: user | group#participant
+	permission view = reader + parent->view
+}
+
+definition group {
+	relation participant: user | group#participant
+}
+
+definition user {}`,
+			[]string{
+				"document:first#parent@document:root",
+				"document:first#reader@user:tom",
+				"document:root#reader@group:admins#participant",
+				"group:admins#participant@group:owners#participant",
+				"group:owners#participant@user:sarah",
+			},
+			"",
+		},
+		{
+			"rename relation used in arrows",
+			func(ctx context.Context, ds datastore.Datastore, opts RenameOptions) error {
+				return RenameRelation(ctx, ds, "document", "view", "can_view", opts)
+			},
+			`definition document {
+	relation parent: document
+	relation reader: user | group#member
+	permission can_view = reader + parent->can_view
+}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition user {}`,
+			renameTestRelationships,
+			"",
+		},
+		{
+			"rename tupleset relation",
+			func(ctx context.Context, ds datastore.Datastore, opts RenameOptions) error {
+				return RenameRelation(ctx, ds, "document", "parent", "folder", opts)
+			},
+			`definition document {
+	relation folder: document
+	relation reader: user | group#member
+	permission view = reader + folder->view
+}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition user {}`,
+			[]string{
+				"document:first#folder@document:root",
+				"document:first#reader@user:tom",
+				"document:root#reader@group:admins#member",
+				"group:admins#member@group:owners#member",
+				"group:owners#member@user:sarah",
+			},
+			"",
+		},
+		{
+			"rename to existing definition",
+			func(ctx context.Context, ds datastore.Datastore, opts RenameOptions) error {
+				return RenameObjectDefinition(ctx, ds, "group", "user", opts)
+			},
+			"",
+			nil,
+			"object definition `user` already exists",
+		},
+		{
+			"rename unknown relation",
+			func(ctx context.Context, ds datastore.Datastore, opts RenameOptions) error {
+				return RenameRelation(ctx, ds, "group", "unknown", "other", opts)
+			},
+			"",
+			nil,
+			"relation/permission `unknown` not found under object definition `group`",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+			require.NoError(err)
+
+			rels := make([]*core.RelationTuple, 0, len(renameTestRelationships))
+			for _, rel := range renameTestRelationships {
+				rels = append(rels, tuple.MustParse(rel))
+			}
+			ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, renameTestSchema, rels, require)
+
+			var phases []RenamePhase
+			err = tc.rename(ctx, ds, RenameOptions{
+				BatchSize: 2,
+				OnProgress: func(progress RenameProgress) {
+					if len(phases) == 0 || phases[len(phases)-1] != progress.Phase {
+						phases = append(phases, progress.Phase)
+					}
+				},
+			})
+			if tc.expectedError != "" {
+				require.ErrorContains(err, tc.expectedError)
+				return
+			}
+			require.NoError(err)
+			require.Equal([]RenamePhase{RenamePhaseExpand, RenamePhaseMigrate, RenamePhaseContract}, phases)
+
+			headRevision, err := ds.HeadRevision(ctx)
+			require.NoError(err)
+			reader := ds.SnapshotReader(headRevision)
+
+			namespaces, err := reader.ListAllNamespaces(ctx)
+			require.NoError(err)
+
+			definitions := make([]compiler.SchemaDefinition, 0, len(namespaces))
+			for _, nsDef := range datastore.DefinitionsOf(namespaces) {
+				definitions = append(definitions, nsDef)
+			}
+			sort.Slice(definitions, func(i, j int) bool {
+				return definitions[i].GetName() < definitions[j].GetName()
+			})
+
+			schema, _, err := generator.GenerateSchema(definitions)
+			require.NoError(err)
+			require.Equal(tc.expectedSchema, schema)
+
+			var found []string
+			for _, nsDef := range datastore.DefinitionsOf(namespaces) {
+				it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{OptionalResourceType: nsDef.Name})
+				require.NoError(err)
+
+				for rel := it.Next(); rel != nil; rel = it.Next() {
+					found = append(found, tuple.MustString(rel))
+				}
+				require.NoError(it.Err())
+				it.Close()
+			}
+			sort.Strings(found)
+			require.Equal(tc.expectedRelationships, found)
+		})
+	}
+}
+
+func TestRenameFailsWhenSchemaChanged(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, renameTestSchema, nil, require)
+
+	changed := false
+	err = RenameRelation(ctx, ds, "group", "member", "participant", RenameOptions{
+		OnProgress: func(progress RenameProgress) {
+			if progress.Phase != RenamePhaseMigrate || changed {
+				return
+			}
+			changed = true
+
+			// Change the schema while the rename is in progress.
+			_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+				return rwt.WriteNamespaces(ctx, ns.Namespace("user", ns.MustRelation("manager", nil, ns.AllowedRelation("user", "..."))))
+			})
+			require.NoError(err)
+		},
+	})
+	require.ErrorContains(err, "object definition `user` was changed while relationships were being rewritten")
+
+	headRevision, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+
+	// The concurrent change was kept, along with the expanded schema.
+	user, _, err := ds.SnapshotReader(headRevision).ReadNamespaceByName(ctx, "user")
+	require.NoError(err)
+	require.Len(user.Relation, 1)
+
+	group, _, err := ds.SnapshotReader(headRevision).ReadNamespaceByName(ctx, "group")
+	require.NoError(err)
+	require.Len(group.Relation, 2)
+}
@@ -0,0 +1,36 @@
+package tuple
+
+import (
+	"github.com/authzed/spicedb/pkg/canonicaljson"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// MarshalJSON returns the canonical JSON encoding of a relation tuple, as defined by the
+// canonicaljson package.
+func MarshalJSON(tpl *core.RelationTuple) ([]byte, error) {
+	return canonicaljson.Marshal(tpl)
+}
+
+// UnmarshalJSON decodes a relation tuple from its canonical JSON encoding and validates it.
+func UnmarshalJSON(data []byte) (*core.RelationTuple, error) {
+	tpl := &core.RelationTuple{}
+	if err := canonicaljson.Unmarshal(data, tpl); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// MarshalUpdateJSON returns the canonical JSON encoding of a relation tuple update.
+func MarshalUpdateJSON(update *core.RelationTupleUpdate) ([]byte, error) {
+	return canonicaljson.Marshal(update)
+}
+
+// UnmarshalUpdateJSON decodes a relation tuple update from its canonical JSON encoding and
+// validates it.
+func UnmarshalUpdateJSON(data []byte) (*core.RelationTupleUpdate, error) {
+	update := &core.RelationTupleUpdate{}
+	if err := canonicaljson.Unmarshal(data, update); err != nil {
+		return nil, err
+	}
+	return update, nil
+}
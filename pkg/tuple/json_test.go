@@ -0,0 +1,75 @@
+package tuple
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/testutil"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tcs := []struct {
+		tuple    string
+		expected string
+	}{
+		{
+			"document:foo#viewer@user:tom",
+			`{"resourceAndRelation":{"namespace":"document","objectId":"foo","relation":"viewer"},"subject":{"namespace":"user","objectId":"tom","relation":"..."}}`,
+		},
+		{
+			"document:foo#viewer@group:eng#member",
+			`{"resourceAndRelation":{"namespace":"document","objectId":"foo","relation":"viewer"},"subject":{"namespace":"group","objectId":"eng","relation":"member"}}`,
+		},
+		{
+			`document:foo#viewer@user:*[somecaveat:{"ip":"10.0.0.1"}]`,
+			`{"caveat":{"caveatName":"somecaveat","context":{"ip":"10.0.0.1"}},"resourceAndRelation":{"namespace":"document","objectId":"foo","relation":"viewer"},"subject":{"namespace":"user","objectId":"*","relation":"..."}}`,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.tuple, func(t *testing.T) {
+			require := require.New(t)
+			tpl := MustParse(tc.tuple)
+
+			encoded, err := MarshalJSON(tpl)
+			require.NoError(err)
+			require.Equal(tc.expected, string(encoded))
+
+			decoded, err := UnmarshalJSON(encoded)
+			require.NoError(err)
+			testutil.RequireProtoEqual(t, tpl, decoded, "JSON round trip mismatch")
+
+			update, err := MarshalUpdateJSON(Touch(tpl))
+			require.NoError(err)
+
+			decodedUpdate, err := UnmarshalUpdateJSON(update)
+			require.NoError(err)
+			testutil.RequireProtoEqual(t, Touch(tpl), decodedUpdate, "JSON round trip mismatch")
+		})
+	}
+}
+
+func TestUnmarshalJSONInvalid(t *testing.T) {
+	tcs := []struct {
+		name string
+		data string
+	}{
+		{"malformed", `{"resourceAndRelation":`},
+		{"unknown field", `{"unknown":true}`},
+		{"missing subject", `{"resourceAndRelation":{"namespace":"document","objectId":"foo","relation":"viewer"}}`},
+		{"invalid object ID", `{"resourceAndRelation":{"namespace":"document","objectId":"foo bar","relation":"viewer"},"subject":{"namespace":"user","objectId":"tom","relation":"..."}}`},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := UnmarshalJSON([]byte(tc.data))
+			require.Error(t, err)
+		})
+	}
+
+	_, err := UnmarshalUpdateJSON([]byte(`{"operation":"TOUCH"}`))
+	require.Error(t, err)
+}
@@ -8,15 +8,27 @@ import (
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
+// onrKey is the key under which an ONR is stored in an ONRSet. Keying by struct
+// avoids formatting each ONR into a string on every set operation.
+type onrKey struct {
+	namespace string
+	objectID  string
+	relation  string
+}
+
+func keyForONR(onr *core.ObjectAndRelation) onrKey {
+	return onrKey{onr.Namespace, onr.ObjectId, onr.Relation}
+}
+
 // ONRSet is a set of ObjectAndRelation's.
 type ONRSet struct {
-	onrs map[string]*core.ObjectAndRelation
+	onrs map[onrKey]*core.ObjectAndRelation
 }
 
 // NewONRSet creates a new set.
 func NewONRSet(onrs ...*core.ObjectAndRelation) *ONRSet {
 	created := &ONRSet{
-		onrs: map[string]*core.ObjectAndRelation{},
+		onrs: make(map[onrKey]*core.ObjectAndRelation, len(onrs)),
 	}
 	created.Update(onrs)
 	return created
@@ -34,18 +46,19 @@ func (ons *ONRSet) IsEmpty() bool {
 
 // Has returns true if the set contains the given ONR.
 func (ons *ONRSet) Has(onr *core.ObjectAndRelation) bool {
-	_, ok := ons.onrs[StringONR(onr)]
+	_, ok := ons.onrs[keyForONR(onr)]
 	return ok
 }
 
 // Add adds the given ONR to the set. Returns true if the object was not in the set before this
 // call and false otherwise.
 func (ons *ONRSet) Add(onr *core.ObjectAndRelation) bool {
-	if _, ok := ons.onrs[StringONR(onr)]; ok {
+	key := keyForONR(onr)
+	if _, ok := ons.onrs[key]; ok {
 		return false
 	}
 
-	ons.onrs[StringONR(onr)] = onr
+	ons.onrs[key] = onr
 	return true
 }
 
@@ -58,17 +71,27 @@ func (ons *ONRSet) Update(onrs []*core.ObjectAndRelation) {
 
 // UpdateFrom updates the set by adding the ONRs found in the other set to it.
 func (ons *ONRSet) UpdateFrom(otherSet *ONRSet) {
-	for _, onr := range otherSet.onrs {
-		ons.Add(onr)
+	for key, onr := range otherSet.onrs {
+		if _, ok := ons.onrs[key]; !ok {
+			ons.onrs[key] = onr
+		}
 	}
 }
 
 // Intersect returns an intersection between this ONR set and the other set provided.
 func (ons *ONRSet) Intersect(otherSet *ONRSet) *ONRSet {
-	updated := NewONRSet()
-	for _, onr := range ons.onrs {
-		if otherSet.Has(onr) {
-			updated.Add(onr)
+	// Iterate over the smaller of the two sets, but always return this set's ONRs.
+	smaller, larger := ons, otherSet
+	if len(larger.onrs) < len(smaller.onrs) {
+		smaller, larger = larger, smaller
+	}
+
+	updated := &ONRSet{
+		onrs: make(map[onrKey]*core.ObjectAndRelation, len(smaller.onrs)),
+	}
+	for key := range smaller.onrs {
+		if _, ok := larger.onrs[key]; ok {
+			updated.onrs[key] = ons.onrs[key]
 		}
 	}
 	return updated
@@ -76,10 +99,12 @@ func (ons *ONRSet) Intersect(otherSet *ONRSet) *ONRSet {
 
 // Subtract returns a subtraction from this ONR set of the other set provided.
 func (ons *ONRSet) Subtract(otherSet *ONRSet) *ONRSet {
-	updated := NewONRSet()
-	for _, onr := range ons.onrs {
-		if !otherSet.Has(onr) {
-			updated.Add(onr)
+	updated := &ONRSet{
+		onrs: make(map[onrKey]*core.ObjectAndRelation, len(ons.onrs)),
+	}
+	for key, onr := range ons.onrs {
+		if _, ok := otherSet.onrs[key]; !ok {
+			updated.onrs[key] = onr
 		}
 	}
 	return updated
@@ -99,9 +124,7 @@ func (ons *ONRSet) Union(otherSet *ONRSet) *ONRSet {
 	updated := &ONRSet{
 		onrs: maps.Clone(ons.onrs),
 	}
-	for _, current := range otherSet.onrs {
-		updated.Add(current)
-	}
+	updated.UpdateFrom(otherSet)
 	return updated
 }
 
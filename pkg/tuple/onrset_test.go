@@ -1,6 +1,7 @@
 package tuple
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -141,3 +142,56 @@ func TestONRSetAsSlice(t *testing.T) {
 
 	require.Equal(t, 3, len(set.AsSlice()))
 }
+
+func benchmarkONRs(count int) []*core.ObjectAndRelation {
+	onrs := make([]*core.ObjectAndRelation, 0, count)
+	for i := 0; i < count; i++ {
+		onrs = append(onrs, ObjectAndRelation("document", strconv.Itoa(i), "viewer"))
+	}
+	return onrs
+}
+
+func BenchmarkONRSetAdd(b *testing.B) {
+	onrs := benchmarkONRs(b.N)
+	set := NewONRSet()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Add(onrs[i])
+	}
+}
+
+func BenchmarkONRSetHas(b *testing.B) {
+	onrs := benchmarkONRs(b.N)
+	set := NewONRSet(onrs...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Has(onrs[i])
+	}
+}
+
+func BenchmarkONRSetIntersect(b *testing.B) {
+	first := NewONRSet(benchmarkONRs(10000)...)
+	second := NewONRSet(benchmarkONRs(100)...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		first.Intersect(second)
+	}
+}
+
+func BenchmarkONRSetSubtract(b *testing.B) {
+	first := NewONRSet(benchmarkONRs(10000)...)
+	second := NewONRSet(benchmarkONRs(5000)...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		first.Subtract(second)
+	}
+}
+
+func BenchmarkONRSetUnion(b *testing.B) {
+	first := NewONRSet(benchmarkONRs(5000)...)
+	second := NewONRSet(benchmarkONRs(10000)...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		first.Union(second)
+	}
+}
@@ -127,6 +127,31 @@ func (err ErrPermissionUsedOnLeftOfArrow) DetailsMetadata() map[string]string {
 	}
 }
 
+// ErrArrowTargetNotFound occurs when the relation or permission walked to by an arrow is not
+// defined on any of the subject types allowed on the arrow's left side.
+type ErrArrowTargetNotFound struct {
+	error
+	namespaceName        string
+	parentPermissionName string
+	tuplesetRelationName string
+	targetRelationName   string
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrArrowTargetNotFound) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("namespace", err.namespaceName).Str("permission", err.parentPermissionName).Str("tuplesetRelation", err.tuplesetRelationName).Str("targetRelation", err.targetRelationName)
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err ErrArrowTargetNotFound) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"definition_name":        err.namespaceName,
+		"permission_name":        err.parentPermissionName,
+		"tupleset_relation_name": err.tuplesetRelationName,
+		"target_relation_name":   err.targetRelationName,
+	}
+}
+
 // ErrWildcardUsedInArrow occurs when an arrow operates over a relation that contains a wildcard.
 type ErrWildcardUsedInArrow struct {
 	error
@@ -306,6 +331,18 @@ func NewPermissionUsedOnLeftOfArrowErr(nsName string, parentPermissionName strin
 	}
 }
 
+// NewArrowTargetNotFoundErr constructs an error indicating that the target of an arrow is not defined on
+// any of the subject types allowed on the arrow's left side.
+func NewArrowTargetNotFoundErr(nsName string, parentPermissionName string, tuplesetRelationName string, targetRelationName string) error {
+	return ErrArrowTargetNotFound{
+		error:                fmt.Errorf("for arrow `%s->%s` under permission `%s`: relation/permission `%s` is not defined on any of the subject types allowed on relation `%s#%s`", tuplesetRelationName, targetRelationName, parentPermissionName, targetRelationName, nsName, tuplesetRelationName),
+		namespaceName:        nsName,
+		parentPermissionName: parentPermissionName,
+		tuplesetRelationName: tuplesetRelationName,
+		targetRelationName:   targetRelationName,
+	}
+}
+
 // NewWildcardUsedInArrowErr constructs an error indicating that an arrow operated over a relation with a wildcard type.
 func NewWildcardUsedInArrowErr(nsName string, parentPermissionName string, foundRelationName string, wildcardTypeName string, wildcardRelationName string) error {
 	return ErrWildcardUsedInArrow{
@@ -3,6 +3,9 @@ package typesystem
 import (
 	"context"
 	"fmt"
+	"sort"
+
+	"golang.org/x/exp/maps"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/genutil/mapz"
@@ -593,9 +596,65 @@ func (nts *TypeSystem) Validate(ctx context.Context) (*ValidatedNamespaceTypeSys
 		}
 	}
 
+	if err := nts.validateArrowTargets(ctx); err != nil {
+		return nil, err
+	}
+
 	return &ValidatedNamespaceTypeSystem{nts}, nil
 }
 
+// validateArrowTargets ensures that the relation or permission walked to by each arrow is defined on
+// at least one of the subject types of the arrow's tupleset relation, as otherwise the arrow can
+// never be satisfied. Relations are checked in name order, so the error returned for a definition
+// with multiple such arrows is always the same.
+func (nts *TypeSystem) validateArrowTargets(ctx context.Context) error {
+	relationNames := maps.Keys(nts.relationMap)
+	sort.Strings(relationNames)
+
+	for _, relationName := range relationNames {
+		relation := nts.relationMap[relationName]
+
+		rerr, err := graph.WalkRewrite(relation.GetUsersetRewrite(), func(childOneof *core.SetOperation_Child) interface{} {
+			ttu := childOneof.GetTupleToUserset()
+			if ttu == nil || ttu.GetTupleset() == nil {
+				return nil
+			}
+
+			tuplesetRelationName := ttu.GetTupleset().GetRelation()
+			found, ok := nts.relationMap[tuplesetRelationName]
+			if !ok || len(found.GetTypeInformation().GetAllowedDirectRelations()) == 0 {
+				return nil
+			}
+
+			targetRelationName := ttu.GetComputedUserset().GetRelation()
+			for _, allowedRelation := range found.GetTypeInformation().GetAllowedDirectRelations() {
+				subjectTS, err := nts.typeSystemForNamespace(ctx, allowedRelation.GetNamespace())
+				if err != nil {
+					// Missing definitions are reported when validating the tupleset relation.
+					return nil
+				}
+
+				if subjectTS.HasRelation(targetRelationName) {
+					return nil
+				}
+			}
+
+			return NewTypeErrorWithSource(
+				NewArrowTargetNotFoundErr(nts.nsDef.Name, relation.Name, tuplesetRelationName, targetRelationName),
+				childOneof, targetRelationName,
+			)
+		})
+		if rerr != nil {
+			return asTypeError(rerr.(error))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // SourceForAllowedRelation returns the source code representation of an allowed relation.
 func SourceForAllowedRelation(allowedRelation *core.AllowedRelation) string {
 	caveatStr := ""
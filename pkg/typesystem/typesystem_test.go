@@ -170,11 +170,67 @@ func TestTypeSystem(t *testing.T) {
 					"folder",
 					ns.MustRelation("can_comment", nil, ns.AllowedRelation("user", "...")),
 					ns.MustRelation("parent", nil, ns.AllowedRelation("folder", "...")),
+					ns.MustRelation("view", ns.Union(
+						ns.TupleToUserset("parent", "view"),
+					)),
 				),
 			},
 			nil,
 			"",
 		},
+		{
+			"arrow target not found on any subject type",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("parent", nil, ns.AllowedRelation("folder", "..."), ns.AllowedRelation("organization", "...")),
+				ns.MustRelation("view", ns.Union(
+					ns.TupleToUserset("parent", "view"),
+				)),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("folder"),
+				ns.Namespace("organization", ns.MustRelation("admin", nil, ns.AllowedRelation("folder", "..."))),
+			},
+			nil,
+			"for arrow `parent->view` under permission `view`: relation/permission `view` is not defined on any of the subject types allowed on relation `document#parent`",
+		},
+		{
+			"first of multiple arrow targets not found is reported",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("parent", nil, ns.AllowedRelation("folder", "...")),
+				ns.MustRelation("view", ns.Union(
+					ns.TupleToUserset("parent", "view"),
+				)),
+				ns.MustRelation("edit", ns.Union(
+					ns.TupleToUserset("parent", "edit"),
+				)),
+				ns.MustRelation("admin", ns.Union(
+					ns.TupleToUserset("parent", "admin"),
+				)),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("folder"),
+			},
+			nil,
+			"for arrow `parent->admin` under permission `admin`: relation/permission `admin` is not defined on any of the subject types allowed on relation `document#parent`",
+		},
+		{
+			"arrow target found on some subject types",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("parent", nil, ns.AllowedRelation("folder", "..."), ns.AllowedRelation("organization", "...")),
+				ns.MustRelation("view", ns.Union(
+					ns.TupleToUserset("parent", "view"),
+				)),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("folder"),
+				ns.Namespace("organization", ns.MustRelation("view", nil, ns.AllowedRelation("folder", "..."))),
+			},
+			nil,
+			"",
+		},
 		{
 			"transitive wildcard type check",
 			ns.Namespace(
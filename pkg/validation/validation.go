@@ -0,0 +1,76 @@
+// Package validation exposes the validation applied by SpiceDB to relationships before they
+// are written, so that embedders and import tools can pre-validate data with identical semantics.
+package validation
+
+import (
+	"context"
+
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/typesystem"
+)
+
+// Rule is the rule to use when validating a relationship.
+type Rule = relationships.ValidationRelationshipRule
+
+const (
+	// ForCreateOrTouch validates a relationship that is being created or touched.
+	ForCreateOrTouch = relationships.ValidateRelationshipForCreateOrTouch
+
+	// ForDeletion validates a relationship that is being deleted. Unlike ForCreateOrTouch, the
+	// caveat may be omitted on a relationship whose subject type requires one.
+	ForDeletion = relationships.ValidateRelationshipForDeletion
+)
+
+// ValidateResourceID ensures that the given resource ID is valid. Returns an error if not.
+func ValidateResourceID(objectID string) error {
+	return tuple.ValidateResourceID(objectID)
+}
+
+// ValidateSubjectID ensures that the given subject ID is valid. Returns an error if not.
+func ValidateSubjectID(subjectID string) error {
+	return tuple.ValidateSubjectID(subjectID)
+}
+
+// ValidateRelationshipUpdates validates the given relationship updates against the schema found
+// in the datastore reader, ensuring that they can be applied.
+func ValidateRelationshipUpdates(ctx context.Context, reader datastore.Reader, updates []*core.RelationTupleUpdate) error {
+	return relationships.ValidateRelationshipUpdates(ctx, reader, updates)
+}
+
+// ValidateRelationships validates the given relationships to be created or touched against the
+// schema found in the datastore reader.
+func ValidateRelationships(ctx context.Context, reader datastore.Reader, rels []*core.RelationTuple) error {
+	return relationships.ValidateRelationshipsForCreateOrTouch(ctx, reader, rels)
+}
+
+// ValidateRelationshipsForSchema validates the given relationships against a compiled schema,
+// without requiring a datastore.
+func ValidateRelationshipsForSchema(schema *compiler.CompiledSchema, rels []*core.RelationTuple, rule Rule) error {
+	resolver := typesystem.ResolverForSchema(*schema)
+
+	namespaceMap := make(map[string]*typesystem.TypeSystem, len(schema.ObjectDefinitions))
+	for _, nsDef := range schema.ObjectDefinitions {
+		ts, err := typesystem.NewNamespaceTypeSystem(nsDef, resolver)
+		if err != nil {
+			return err
+		}
+		namespaceMap[nsDef.Name] = ts
+	}
+
+	caveatMap := make(map[string]*core.CaveatDefinition, len(schema.CaveatDefinitions))
+	for _, caveatDef := range schema.CaveatDefinitions {
+		caveatMap[caveatDef.Name] = caveatDef
+	}
+
+	for _, rel := range rels {
+		if err := relationships.ValidateOneRelationship(namespaceMap, caveatMap, rel, rule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `
+caveat somecaveat(somecondition int) {
+	somecondition == 42
+}
+
+definition user {}
+
+definition resource {
+	relation viewer: user | user with somecaveat
+	relation folder: resource
+	permission view = viewer
+}`
+
+func TestValidateRelationships(t *testing.T) {
+	tcs := []struct {
+		name          string
+		relationship  string
+		rule          Rule
+		expectedError string
+	}{
+		{"valid", "resource:foo#viewer@user:tom", ForCreateOrTouch, ""},
+		{"valid with caveat", `resource:foo#viewer@user:tom[somecaveat:{"somecondition":42}]`, ForCreateOrTouch, ""},
+		{"write to permission", "resource:foo#view@user:tom", ForCreateOrTouch, "cannot write a relationship to permission"},
+		{"wrong subject type", "resource:foo#folder@user:tom", ForCreateOrTouch, "subjects of type `user` are not allowed on relation"},
+		{"unknown resource type", "unknown:foo#viewer@user:tom", ForCreateOrTouch, "object definition `unknown` not found"},
+		{"unknown relation", "resource:foo#unknown@user:tom", ForDeletion, "relation/permission `unknown` not found"},
+		{"wrong caveat context", `resource:foo#viewer@user:tom[somecaveat:{"unknown":42}]`, ForCreateOrTouch, "unknown parameter"},
+	}
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: testSchema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, nil, require.New(t))
+	reader := ds.SnapshotReader(revision)
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			rel := tuple.MustParse(tc.relationship)
+
+			operation := core.RelationTupleUpdate_TOUCH
+			if tc.rule == ForDeletion {
+				operation = core.RelationTupleUpdate_DELETE
+			}
+
+			schemaErr := ValidateRelationshipsForSchema(compiled, []*core.RelationTuple{rel}, tc.rule)
+			datastoreErr := ValidateRelationshipUpdates(context.Background(), reader, []*core.RelationTupleUpdate{
+				{Operation: operation, Tuple: rel},
+			})
+
+			if tc.expectedError == "" {
+				require.NoError(schemaErr)
+				require.NoError(datastoreErr)
+				return
+			}
+
+			require.ErrorContains(schemaErr, tc.expectedError)
+			require.ErrorContains(datastoreErr, tc.expectedError)
+		})
+	}
+}
+
+func TestValidateIDs(t *testing.T) {
+	require.NoError(t, ValidateResourceID("some-id_1"))
+	require.Error(t, ValidateResourceID("some id"))
+	require.NoError(t, ValidateSubjectID("*"))
+	require.Error(t, ValidateSubjectID(""))
+}
@@ -2,6 +2,7 @@
 package zedtoken
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -22,6 +23,14 @@ const (
 // zedtoken argument to Decode
 var ErrNilZedToken = errors.New("zedtoken pointer was nil")
 
+// ErrMismatchedDatastore is returned as the base error when a zedtoken issued
+// by one datastore is decoded against another.
+var ErrMismatchedDatastore = errors.New("zedtoken was issued by a different datastore")
+
+// datastoreUniqueIDPrefixLength is the number of leading characters of the
+// datastore unique ID that are embedded in encoded zedtokens.
+const datastoreUniqueIDPrefixLength = 12
+
 // MustNewFromRevision generates an encoded zedtoken from an integral revision.
 func MustNewFromRevision(revision datastore.Revision) *v1.ZedToken {
 	encoded, err := NewFromRevision(revision)
@@ -32,11 +41,30 @@ func MustNewFromRevision(revision datastore.Revision) *v1.ZedToken {
 }
 
 // NewFromRevision generates an encoded zedtoken from an integral revision.
+//
+// The zedtoken is not bound to any datastore; prefer NewFromRevisionForDatastore
+// for tokens handed out to clients.
 func NewFromRevision(revision datastore.Revision) (*v1.ZedToken, error) {
+	return newFromRevision(revision, "")
+}
+
+// NewFromRevisionForDatastore generates an encoded zedtoken from a revision, bound
+// to the datastore that issued it.
+func NewFromRevisionForDatastore(ctx context.Context, revision datastore.Revision, ds uniqueIDSource) (*v1.ZedToken, error) {
+	uniqueID, err := ds.UniqueID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(errEncodeError, err)
+	}
+
+	return newFromRevision(revision, uniqueIDPrefix(uniqueID))
+}
+
+func newFromRevision(revision datastore.Revision, datastoreUniqueIDPrefix string) (*v1.ZedToken, error) {
 	toEncode := &zedtoken.DecodedZedToken{
 		VersionOneof: &zedtoken.DecodedZedToken_V1{
 			V1: &zedtoken.DecodedZedToken_V1ZedToken{
-				Revision: revision.String(),
+				Revision:                revision.String(),
+				DatastoreUniqueIdPrefix: datastoreUniqueIDPrefix,
 			},
 		},
 	}
@@ -77,7 +105,7 @@ func Decode(encoded *v1.ZedToken) (*zedtoken.DecodedZedToken, error) {
 }
 
 // DecodeRevision converts and extracts the revision from a zedtoken or legacy zookie.
-func DecodeRevision(encoded *v1.ZedToken, ds revisionDecoder) (datastore.Revision, error) {
+func DecodeRevision(encoded *v1.ZedToken, ds RevisionDecoder) (datastore.Revision, error) {
 	decoded, err := Decode(encoded)
 	if err != nil {
 		return datastore.NoRevision, err
@@ -103,6 +131,92 @@ func DecodeRevision(encoded *v1.ZedToken, ds revisionDecoder) (datastore.Revisio
 	}
 }
 
-type revisionDecoder interface {
+// DecodeRevisionForDatastore converts and extracts the revision from a zedtoken or
+// legacy zookie, returning ErrMismatchedDatastore if the zedtoken was issued by a
+// datastore other than the one given. Tokens that were not bound to a datastore
+// are accepted as-is.
+func DecodeRevisionForDatastore(ctx context.Context, encoded *v1.ZedToken, ds boundRevisionDecoder) (datastore.Revision, error) {
+	decoded, err := Decode(encoded)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+
+	if v1Token := decoded.GetV1(); v1Token != nil && v1Token.DatastoreUniqueIdPrefix != "" {
+		uniqueID, err := ds.UniqueID(ctx)
+		if err != nil {
+			return datastore.NoRevision, fmt.Errorf(errDecodeError, err)
+		}
+
+		if v1Token.DatastoreUniqueIdPrefix != uniqueIDPrefix(uniqueID) {
+			return datastore.NoRevision, fmt.Errorf(errDecodeError, ErrMismatchedDatastore)
+		}
+	}
+
+	return DecodeRevision(encoded, ds)
+}
+
+// Compare decodes two zedtokens and compares the revisions they represent,
+// returning -1 if the first is older than the second, 0 if they are at the
+// same revision and +1 if the first is newer. Returns ErrMismatchedDatastore
+// if the zedtokens were issued by different datastores.
+//
+// Clients without access to the datastore can decode the revisions with the
+// parser of its engine, found in
+// revisionparsing.ParseRevisionStringByDatastoreEngineID.
+func Compare(first, second *v1.ZedToken, ds RevisionDecoder) (int, error) {
+	firstDecoded, err := Decode(first)
+	if err != nil {
+		return 0, err
+	}
+
+	secondDecoded, err := Decode(second)
+	if err != nil {
+		return 0, err
+	}
+
+	firstPrefix := firstDecoded.GetV1().GetDatastoreUniqueIdPrefix()
+	secondPrefix := secondDecoded.GetV1().GetDatastoreUniqueIdPrefix()
+	if firstPrefix != "" && secondPrefix != "" && firstPrefix != secondPrefix {
+		return 0, fmt.Errorf(errDecodeError, ErrMismatchedDatastore)
+	}
+
+	firstRevision, err := DecodeRevision(first, ds)
+	if err != nil {
+		return 0, err
+	}
+
+	secondRevision, err := DecodeRevision(second, ds)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case firstRevision.LessThan(secondRevision):
+		return -1, nil
+	case firstRevision.GreaterThan(secondRevision):
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func uniqueIDPrefix(uniqueID string) string {
+	if len(uniqueID) > datastoreUniqueIDPrefixLength {
+		return uniqueID[:datastoreUniqueIDPrefixLength]
+	}
+	return uniqueID
+}
+
+// RevisionDecoder parses the revisions encoded in zedtokens.
+type RevisionDecoder interface {
 	RevisionFromString(string) (datastore.Revision, error)
 }
+
+type uniqueIDSource interface {
+	UniqueID(context.Context) (string, error)
+}
+
+type boundRevisionDecoder interface {
+	RevisionDecoder
+	uniqueIDSource
+}
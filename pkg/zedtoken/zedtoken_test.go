@@ -1,6 +1,7 @@
 package zedtoken
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -8,8 +9,10 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 
+	"github.com/authzed/spicedb/internal/datastore/mysql"
 	"github.com/authzed/spicedb/internal/datastore/revisions"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/revisionparsing"
 )
 
 var encodeRevisionTests = []datastore.Revision{
@@ -70,6 +73,111 @@ func TestZedTokenEncodeHLC(t *testing.T) {
 	}
 }
 
+type fakeBoundDecoder struct {
+	revisions.CommonDecoder
+	uniqueID string
+}
+
+func (f fakeBoundDecoder) UniqueID(_ context.Context) (string, error) {
+	return f.uniqueID, nil
+}
+
+func TestZedTokenDatastoreBinding(t *testing.T) {
+	rev := revisions.NewForTransactionID(42)
+	issuer := fakeBoundDecoder{revisions.CommonDecoder{Kind: revisions.TransactionID}, "1d1f1bb5-2a45-4a2c-a4ec-3e5a0b0b8ac2"}
+	other := fakeBoundDecoder{revisions.CommonDecoder{Kind: revisions.TransactionID}, "c0c6e5c4-88c9-4a07-bb1f-5a3d2f0d2d1e"}
+
+	t.Run("same datastore", func(t *testing.T) {
+		require := require.New(t)
+		encoded, err := NewFromRevisionForDatastore(context.Background(), rev, issuer)
+		require.NoError(err)
+
+		decoded, err := DecodeRevisionForDatastore(context.Background(), encoded, issuer)
+		require.NoError(err)
+		require.True(rev.Equal(decoded))
+	})
+
+	t.Run("different datastore", func(t *testing.T) {
+		require := require.New(t)
+		encoded, err := NewFromRevisionForDatastore(context.Background(), rev, issuer)
+		require.NoError(err)
+
+		_, err = DecodeRevisionForDatastore(context.Background(), encoded, other)
+		require.ErrorIs(err, ErrMismatchedDatastore)
+
+		// Decoding without a datastore binding ignores the embedded unique ID.
+		decoded, err := DecodeRevision(encoded, other)
+		require.NoError(err)
+		require.True(rev.Equal(decoded))
+	})
+
+	t.Run("unbound token", func(t *testing.T) {
+		require := require.New(t)
+		encoded, err := NewFromRevision(rev)
+		require.NoError(err)
+
+		decoded, err := DecodeRevisionForDatastore(context.Background(), encoded, other)
+		require.NoError(err)
+		require.True(rev.Equal(decoded))
+	})
+}
+
+func TestZedTokenCompare(t *testing.T) {
+	decoder := fakeBoundDecoder{revisions.CommonDecoder{Kind: revisions.TransactionID}, "1d1f1bb5-2a45-4a2c-a4ec-3e5a0b0b8ac2"}
+	other := fakeBoundDecoder{revisions.CommonDecoder{Kind: revisions.TransactionID}, "c0c6e5c4-88c9-4a07-bb1f-5a3d2f0d2d1e"}
+
+	older := MustNewFromRevision(revisions.NewForTransactionID(4))
+	newer, err := NewFromRevisionForDatastore(context.Background(), revisions.NewForTransactionID(8), decoder)
+	require.NoError(t, err)
+	fromOther, err := NewFromRevisionForDatastore(context.Background(), revisions.NewForTransactionID(8), other)
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name          string
+		first         *v1.ZedToken
+		second        *v1.ZedToken
+		expected      int
+		expectedError error
+	}{
+		{"older", older, newer, -1, nil},
+		{"newer", newer, older, 1, nil},
+		{"equal", newer, newer, 0, nil},
+		{"different datastores", newer, fromOther, 0, ErrMismatchedDatastore},
+		{"nil token", nil, newer, 0, ErrNilZedToken},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+			result, err := Compare(tc.first, tc.second, decoder)
+			if tc.expectedError != nil {
+				require.ErrorIs(err, tc.expectedError)
+				return
+			}
+
+			require.NoError(err)
+			require.Equal(tc.expected, result)
+		})
+	}
+}
+
+func TestZedTokenCompareWithEngineParser(t *testing.T) {
+	require := require.New(t)
+	parser := revisionparsing.ParseRevisionStringByDatastoreEngineID[mysql.Engine]
+
+	older := MustNewFromRevision(revisions.NewForTransactionID(4))
+	newer := MustNewFromRevision(revisions.NewForTransactionID(8))
+
+	result, err := Compare(older, newer, parser)
+	require.NoError(err)
+	require.Equal(-1, result)
+
+	result, err = Compare(newer, older, parser)
+	require.NoError(err)
+	require.Equal(1, result)
+}
+
 var decodeTests = []struct {
 	format           string
 	token            string